@@ -208,6 +208,9 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 		v1.POST("/users/verify_recovery_code", h.User.VerifyRecoveryCode)
 		v1.POST("/users/update_password_with_code", h.User.UpdatePasswordWithCode)
 
+		// Issued document verification (public, from the QR code printed on the document)
+		v1.GET("/verify/:code", h.Report.VerifyDocument)
+
 		// Protected routes (requires authentication)
 		protected := v1.Group("")
 		protected.Use(middleware.Auth(cfg.JWTSecret))
@@ -229,11 +232,14 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/cancel", h.Contract.Cancel)
 				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/reopen", h.Contract.Reopen)
 				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/capital_repayment", h.Contract.CapitalRepayment)
+				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/cession", h.Contract.Cede)
+				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/change_lot", h.Contract.ChangeLot)
 
 				// Payment approval/rejection/undo (admin only)
 				admin.POST("/payments/:payment_id/approve", h.Payment.Approve)
 				admin.POST("/payments/:payment_id/reject", h.Payment.Reject)
 				admin.POST("/payments/:payment_id/undo", h.Payment.Undo)
+				admin.POST("/payments/:payment_id/receipts/reissue", h.Receipt.Reissue)
 				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/payments/:payment_id/approve", h.Payment.ApproveByContract)
 				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/payments/:payment_id/reject", h.Payment.RejectByContract)
 
@@ -241,13 +247,79 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 				admin.POST("/projects", h.Project.Create)
 				admin.PUT("/projects/:project_id", h.Project.Update)
 				admin.DELETE("/projects/:project_id", h.Project.Delete)
+				admin.POST("/projects/:project_id/submit", h.Project.Submit)
 				admin.POST("/projects/:project_id/approve", h.Project.Approve)
+				admin.POST("/projects/:project_id/reject", h.Project.Reject)
+				admin.POST("/projects/:project_id/close", h.Project.Close)
+				admin.POST("/projects/:project_id/reopen", h.Project.Reopen)
 				admin.POST("/projects/import", h.Project.Import)
 
 				// Lot management (admin only)
 				admin.POST("/projects/:project_id/lots", h.Lot.Create)
 				admin.PUT("/projects/:project_id/lots/:lot_id", h.Lot.Update)
 				admin.DELETE("/projects/:project_id/lots/:lot_id", h.Lot.Delete)
+				admin.POST("/projects/:project_id/lots/import", h.Lot.Import)
+				admin.POST("/projects/:project_id/lots/geometry/import", h.Lot.ImportGeometry)
+				admin.PUT("/projects/:project_id/lots/:lot_id/geometry", h.Lot.UpdateGeometry)
+				admin.PUT("/projects/:project_id/geometry", h.Project.UpdateGeometry)
+
+				// Project phases and blocks (admin only)
+				admin.POST("/projects/:project_id/phases", h.Section.CreatePhase)
+				admin.PUT("/projects/:project_id/phases/:phase_id", h.Section.UpdatePhase)
+				admin.DELETE("/projects/:project_id/phases/:phase_id", h.Section.DeletePhase)
+				admin.POST("/projects/:project_id/blocks", h.Section.CreateBlock)
+				admin.PUT("/projects/:project_id/blocks/:block_id", h.Section.UpdateBlock)
+				admin.DELETE("/projects/:project_id/blocks/:block_id", h.Section.DeleteBlock)
+
+				// Price lists / bulk repricing (admin only)
+				admin.POST("/projects/:project_id/price_lists", h.PriceList.Create)
+				admin.POST("/projects/:project_id/price_lists/:price_list_id/cancel", h.PriceList.Cancel)
+
+				// Promotions (admin only)
+				admin.POST("/promotions", h.Promotion.Create)
+				admin.PUT("/promotions/:promotion_id", h.Promotion.Update)
+				admin.DELETE("/promotions/:promotion_id", h.Promotion.Delete)
+
+				// Contract approval chains
+				admin.GET("/approval_chains", h.ApprovalChain.Index)
+				admin.GET("/approval_chains/:chain_id", h.ApprovalChain.Show)
+				admin.POST("/approval_chains", h.ApprovalChain.Create)
+				admin.PUT("/approval_chains/:chain_id", h.ApprovalChain.Update)
+				admin.DELETE("/approval_chains/:chain_id", h.ApprovalChain.Delete)
+
+				// Contract time-in-status SLAs
+				admin.GET("/contract_slas", h.ContractSLA.Index)
+				admin.PUT("/contract_slas", h.ContractSLA.Set)
+				admin.DELETE("/contract_slas/:sla_id", h.ContractSLA.Delete)
+				admin.GET("/contracts/time_in_status", h.ContractSLA.TimeInStatus)
+
+				// KYC checklist gating contract approval
+				admin.GET("/kyc_requirements", h.KYC.Index)
+				admin.POST("/kyc_requirements", h.KYC.Create)
+				admin.DELETE("/kyc_requirements/:requirement_id", h.KYC.Delete)
+				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/kyc/override", h.KYC.Override)
+
+				// Contract documents soft delete (admin only)
+				admin.DELETE("/projects/:project_id/lots/:lot_id/contracts/:contract_id/documents/:document_id", h.Document.Delete)
+
+				// Contract electronic signature cancellation and completion retry (admin only)
+				admin.DELETE("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature", h.Signature.Cancel)
+				admin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature/complete", h.Signature.Complete)
+
+				// Fiscal invoicing: authorized ranges (CAI) and invoices of interest and fees
+				admin.GET("/fiscal/ranges", h.Fiscal.Ranges)
+				admin.POST("/fiscal/ranges", h.Fiscal.CreateRange)
+				admin.DELETE("/fiscal/ranges/:range_id", h.Fiscal.DeactivateRange)
+				admin.POST("/payments/:payment_id/invoice", h.Fiscal.InvoicePayment)
+
+				// Scheduled report subscriptions delivered by email
+				admin.GET("/reports/subscriptions", h.ReportSubscription.Index)
+				admin.POST("/reports/subscriptions", h.ReportSubscription.Create)
+				admin.GET("/reports/subscriptions/:subscription_id", h.ReportSubscription.Show)
+				admin.PUT("/reports/subscriptions/:subscription_id", h.ReportSubscription.Update)
+				admin.DELETE("/reports/subscriptions/:subscription_id", h.ReportSubscription.Delete)
+				admin.GET("/reports/subscriptions/:subscription_id/runs", h.ReportSubscription.Runs)
+				admin.POST("/reports/subscriptions/:subscription_id/run", h.ReportSubscription.Run)
 
 				// Job status (admin only)
 				admin.GET("/jobs/status", h.Job.Status)
@@ -278,6 +350,27 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 				sellerAdmin.GET("/contracts/stats", h.Contract.GetStats)
 				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id", h.Contract.Show)
 				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/ledger", h.Contract.Ledger)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/cessions", h.Contract.Cessions)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/lot_changes", h.Contract.LotChanges)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/status_history", h.Contract.StatusHistory)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/versions", h.Contract.TermsVersions)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/versions/diff", h.Contract.TermsDiff)
+
+				// Approval chain: submission, step decisions and each approver's queue
+				sellerAdmin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/submit", h.Contract.Submit)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/approvals", h.ApprovalChain.ContractApprovals)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/kyc", h.KYC.Checklist)
+				sellerAdmin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/approvals", h.Contract.DecideApproval)
+				sellerAdmin.GET("/contract_approvals/pending", h.ApprovalChain.Pending)
+
+				// Promise contract electronic signature of approved contracts
+				sellerAdmin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature", h.Signature.Start)
+
+				// Contract co-buyers, guarantors and legal representatives
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/parties", h.ContractParty.Index)
+				sellerAdmin.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/parties", h.ContractParty.Create)
+				sellerAdmin.PUT("/projects/:project_id/lots/:lot_id/contracts/:contract_id/parties/:party_id", h.ContractParty.Update)
+				sellerAdmin.DELETE("/projects/:project_id/lots/:lot_id/contracts/:contract_id/parties/:party_id", h.ContractParty.Delete)
 
 				// Payment viewing (seller can view all payments)
 				sellerAdmin.GET("/payments", h.Payment.Index)
@@ -292,8 +385,30 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 				sellerAdmin.GET("/projects", h.Project.Index)
 				sellerAdmin.GET("/projects/:project_id", h.Project.Show)
 				sellerAdmin.GET("/projects/:project_id/lots", h.Lot.Index)
+				sellerAdmin.GET("/projects/:project_id/site_map", h.Project.SiteMap)
+				sellerAdmin.GET("/projects/:project_id/phases", h.Section.Index)
 				sellerAdmin.GET("/projects/:project_id/lots/:lot_id", h.Lot.Show)
 
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/price_history", h.PriceList.LotHistory)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/status_history", h.Lot.StatusHistory)
+
+				// Lot holds (seller reserves a lot while preparing the contract)
+				sellerAdmin.GET("/lot_holds", h.LotHold.Index)
+				sellerAdmin.GET("/projects/:project_id/lots/:lot_id/hold", h.LotHold.Show)
+				sellerAdmin.POST("/projects/:project_id/lots/:lot_id/hold", h.LotHold.Create)
+				sellerAdmin.DELETE("/projects/:project_id/lots/:lot_id/hold", h.LotHold.Delete)
+
+				// Waitlist (customers waiting for a lot to free up)
+				sellerAdmin.GET("/projects/:project_id/waitlist", h.Waitlist.Index)
+				sellerAdmin.POST("/projects/:project_id/waitlist", h.Waitlist.Create)
+				sellerAdmin.DELETE("/projects/:project_id/waitlist/:entry_id", h.Waitlist.Delete)
+				sellerAdmin.GET("/projects/:project_id/price_lists", h.PriceList.Index)
+				sellerAdmin.GET("/projects/:project_id/price_lists/:price_list_id", h.PriceList.Show)
+
+				// Promotions viewing
+				sellerAdmin.GET("/promotions", h.Promotion.Index)
+				sellerAdmin.GET("/promotions/:promotion_id", h.Promotion.Show)
+
 				// Analytics (seller can view analytics)
 				analytics := sellerAdmin.Group("/analytics")
 				{
@@ -309,11 +424,23 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 				sellerAdmin.GET("/reports/commissions_csv", h.Report.CommissionsCSV)
 				sellerAdmin.GET("/reports/total_revenue_csv", h.Report.TotalRevenueCSV)
 				sellerAdmin.GET("/reports/overdue_payments_csv", h.Report.OverduePaymentsCSV)
+				sellerAdmin.GET("/reports/receivables_aging", h.Report.ReceivablesAging)
+				sellerAdmin.GET("/reports/receivables_aging_csv", h.Report.ReceivablesAgingCSV)
+				sellerAdmin.GET("/reports/receivables_aging_xlsx", h.Report.ReceivablesAgingXLSX)
+				sellerAdmin.GET("/reports/lot_inventory_csv", h.Section.InventoryCSV)
 				sellerAdmin.GET("/reports/user_balance_pdf", h.Report.UserBalancePDF)
 				sellerAdmin.GET("/reports/user_promise_contract_pdf", h.Report.UserPromiseContractPDF)
 				sellerAdmin.GET("/reports/user_rescission_contract_pdf", h.Report.UserRescissionContractPDF)
+				sellerAdmin.GET("/reports/contract_cession_pdf", h.Report.ContractCessionPDF)
+				sellerAdmin.GET("/reports/contract_lot_change_pdf", h.Report.ContractLotChangePDF)
 				sellerAdmin.GET("/reports/user_information_pdf", h.Report.UserInformationPDF)
 				sellerAdmin.GET("/reports/customer_record_pdf", h.Report.CustomerRecordPDF)
+
+				// Reports generated in the background: submit, poll and download
+				sellerAdmin.POST("/reports/jobs", h.ReportJob.Submit)
+				sellerAdmin.GET("/reports/jobs", h.ReportJob.Index)
+				sellerAdmin.GET("/reports/jobs/:job_id", h.ReportJob.Show)
+				sellerAdmin.GET("/reports/jobs/:job_id/download", h.ReportJob.Download)
 				sellerAdmin.GET("/dashboard/seller", h.Report.SellerDashboard)
 
 				// Audits (seller can view audit logs)
@@ -333,11 +460,33 @@ func setupRouter(h *handlers.Handlers, cfg *config.Config) *gin.Engine {
 			protected.POST("/projects/:project_id/lots/:lot_id/contracts", h.Contract.Create)
 			protected.PATCH("/projects/:project_id/lots/:lot_id/contracts/:contract_id", h.Contract.Update)
 
+			// Contract documents (admin, seller or the buyer; ownership is checked per contract)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/documents", h.Document.Index)
+			protected.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/documents", h.Document.Upload)
+			protected.PUT("/projects/:project_id/lots/:lot_id/contracts/:contract_id/documents/:document_id", h.Document.Replace)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/documents/:document_id/versions", h.Document.Versions)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/documents/:document_id/download", h.Document.Download)
+
+			// Promise contract electronic signature (the buyer signs as buyer, admins for the company)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature", h.Signature.Show)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature/document", h.Signature.Document)
+			protected.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature/otp", h.Signature.RequestOTP)
+			protected.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature/sign", h.Signature.Sign)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/signature/verify", h.Signature.Verify)
+
+			// Fiscal invoices of a contract (buyer or staff)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/invoices", h.Fiscal.Invoices)
+			protected.GET("/projects/:project_id/lots/:lot_id/contracts/:contract_id/invoices/:invoice_id/download", h.Fiscal.DownloadInvoice)
+
 			// Payment receipt upload (users can upload their own receipts)
 			protected.POST("/payments/:payment_id/upload_receipt", h.Payment.UploadReceipt)
 			protected.POST("/projects/:project_id/lots/:lot_id/contracts/:contract_id/payments/:payment_id/upload_receipt", h.Payment.UploadReceiptByContract)
 			protected.GET("/payments/:payment_id/download_receipt", h.Payment.DownloadReceipt)
 
+			// Official receipts of approved payments (buyer or staff)
+			protected.GET("/payments/:payment_id/receipts", h.Receipt.Index)
+			protected.GET("/payments/:payment_id/receipts/:receipt_id/download", h.Receipt.Download)
+
 			// Notifications (users can manage their own notifications)
 			// Static route first so "mark_all_as_read" is not matched as :notification_id
 			notifications := protected.Group("/notifications")
@@ -389,6 +538,44 @@ func scheduleJobs(worker *jobs.Worker, svcs *services.Services) {
 		return svcs.Contract.ReleaseUnpaidReservations(ctx)
 	})
 
+	// Expire lot holds every minute (the service logs only when something expired)
+	worker.ScheduleEveryImmediate(1*time.Minute, func(ctx context.Context) error {
+		return svcs.LotHold.ExpireDue(ctx)
+	})
+
+	// Apply scheduled price lists every hour
+	worker.ScheduleEveryImmediate(1*time.Hour, func(ctx context.Context) error {
+		logger.Info("[Job] Applying scheduled price lists...")
+		return svcs.PriceList.ApplyScheduled(ctx)
+	})
+
+	// Escalate contracts overdue in their status every hour
+	worker.ScheduleEveryImmediate(1*time.Hour, func(ctx context.Context) error {
+		logger.Info("[Job] Escalating contracts overdue in their status...")
+		return svcs.ContractSLA.EscalateOverdue(ctx)
+	})
+
+	// Warn admins about fiscal ranges running out of numbers or close to their deadline
+	worker.ScheduleEveryImmediate(12*time.Hour, func(ctx context.Context) error {
+		logger.Info("[Job] Checking fiscal ranges...")
+		return svcs.Fiscal.WarnRunningOut(ctx)
+	})
+
+	// Fail report jobs interrupted by the restart (the worker queue is in memory) once on startup,
+	// then delete report outputs past their retention every hour
+	if err := svcs.ReportJob.FailInterrupted(worker.Context()); err != nil {
+		logger.Error("Error failing interrupted report jobs", "error", err)
+	}
+	worker.ScheduleEveryImmediate(1*time.Hour, func(ctx context.Context) error {
+		logger.Info("[Job] Expiring report outputs...")
+		return svcs.ReportJob.ExpireOld(ctx)
+	})
+
+	// Email the report subscriptions that are due
+	worker.ScheduleEveryImmediate(1*time.Minute, func(ctx context.Context) error {
+		return svcs.ReportSubscription.RunDue(ctx)
+	})
+
 	// Daily payment reminder emails for active users with active contracts
 	worker.ScheduleEveryImmediate(24*time.Hour, func(ctx context.Context) error {
 		logger.Info("[Job] Sending daily payment reminder emails...")
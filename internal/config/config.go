@@ -31,6 +31,32 @@ type Config struct {
 
 	// General
 	AppURL string
+	APIURL string // public URL of this API, printed in the QR codes of issued documents
+
+	// Issuer name printed on issued documents and confirmed by their public verification
+	IssuerName string
+	IssuerRTN  string // tax ID printed on fiscal invoices
+
+	// PDF backend: "native" draws reports with gofpdf (templates without a native layout still use
+	// wkhtmltopdf); "wkhtmltopdf" renders every report from its HTML template
+	PDFRenderer string
+
+	// Payment receipts are numbered in one company-wide series ("company") or one series per project ("project")
+	ReceiptNumbering string
+
+	// Fiscal invoices are numbered from the authorized ranges (CAI) of this establishment and point of
+	// sale. Admins are warned when a range has FiscalWarnRemaining numbers or FiscalWarnDays days left.
+	FiscalEstablishment string
+	FiscalPointOfSale   string
+	FiscalTaxRate       int // sales tax percentage (ISV) included in taxed charges such as fees
+	FiscalWarnRemaining int
+	FiscalWarnDays      int
+
+	// Reports generated in the background are kept for download this many days. They run on
+	// ReportWorkers processors of their own; requests beyond ReportQueueSize waiting are refused.
+	ReportRetentionDays int
+	ReportWorkers       int
+	ReportQueueSize     int
 
 	// Email (Resend)
 	ResendAPIKey             string
@@ -59,6 +85,19 @@ func Load() (*Config, error) {
 		WorkerCount:              getEnvAsInt("WORKER_COUNT", 5),
 		AllowedOrigins:           getEnvAsSlice("ALLOWED_ORIGINS", []string{"*"}),
 		AppURL:                   getEnv("APP_URL", "https://fintera.securexapp.com"),
+		APIURL:                   getEnv("API_URL", "http://localhost:8080"),
+		IssuerName:               getEnv("ISSUER_NAME", "Inversiones FAMA S.A. de C.V."),
+		IssuerRTN:                getEnv("ISSUER_RTN", ""),
+		PDFRenderer:              getEnv("PDF_RENDERER", "native"),
+		ReceiptNumbering:         getEnv("RECEIPT_NUMBERING", "company"),
+		FiscalEstablishment:      getEnv("FISCAL_ESTABLISHMENT", "000"),
+		FiscalPointOfSale:        getEnv("FISCAL_POINT_OF_SALE", "001"),
+		FiscalTaxRate:            getEnvAsInt("FISCAL_TAX_RATE", 15),
+		FiscalWarnRemaining:      getEnvAsInt("FISCAL_WARN_REMAINING", 50),
+		FiscalWarnDays:           getEnvAsInt("FISCAL_WARN_DAYS", 30),
+		ReportRetentionDays:      getEnvAsInt("REPORT_RETENTION_DAYS", 7),
+		ReportWorkers:            getEnvAsInt("REPORT_WORKERS", 2),
+		ReportQueueSize:          getEnvAsInt("REPORT_QUEUE_SIZE", 20),
 		ResendAPIKey:             getEnv("RESEND_API_KEY", ""),
 		FromEmail:                getEnv("FROM_EMAIL", "noreply@fintera.app"),
 		EnableEmailNotifications: getEnvAsBool("ENABLE_EMAIL_NOTIFICATIONS", false),
@@ -78,6 +117,12 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("JWT_SECRET is required in production")
 	}
 
+	// Issued documents print API_URL in their verification QR code; the localhost default only
+	// works in development
+	if _, set := os.LookupEnv("API_URL"); !set && cfg.Environment != "development" {
+		return nil, fmt.Errorf("API_URL is required outside development")
+	}
+
 	// Set default JWT secret for development
 	if cfg.JWTSecret == "" {
 		cfg.JWTSecret = "dev-secret-change-in-production"
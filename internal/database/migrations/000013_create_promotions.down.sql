@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contract_line_items;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,38 @@
+-- Promotions: discount rules applied to lot prices when a contract is created
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(50) NOT NULL,
+    value NUMERIC(15,2) NOT NULL DEFAULT 0,
+    project_id BIGINT,
+    lot_ids TEXT,
+    financing_type VARCHAR(50),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    max_uses INTEGER,
+    uses_count INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN DEFAULT TRUE,
+    created_by_id BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_promotions_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_promotions_created_by FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_promotions_project_id ON promotions(project_id);
+CREATE INDEX IF NOT EXISTS idx_promotions_active ON promotions(active);
+
+-- Contract line items: price breakdown recorded at contract creation (base price, discounts)
+CREATE TABLE IF NOT EXISTS contract_line_items (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    promotion_id BIGINT,
+    item_type VARCHAR(50) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_line_items_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_line_items_promotion FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_contract_line_items_contract_id ON contract_line_items(contract_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_contracts_price_list_id;
+ALTER TABLE contracts DROP COLUMN IF EXISTS price_list_id;
+DROP TABLE IF EXISTS lot_price_histories;
+DROP TABLE IF EXISTS price_list_items;
+DROP TABLE IF EXISTS price_lists;
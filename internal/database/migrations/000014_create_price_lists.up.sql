@@ -0,0 +1,54 @@
+-- Price lists: versioned bulk repricing of a project's lots with an effective date
+CREATE TABLE IF NOT EXISTS price_lists (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    version INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'scheduled',
+    operation TEXT,
+    effective_from TIMESTAMP NOT NULL,
+    applied_at TIMESTAMP,
+    created_by_id BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_price_lists_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_lists_created_by FOREIGN KEY (created_by_id) REFERENCES users(id),
+    CONSTRAINT uq_price_lists_project_version UNIQUE (project_id, version)
+);
+CREATE INDEX IF NOT EXISTS idx_price_lists_project_id ON price_lists(project_id);
+CREATE INDEX IF NOT EXISTS idx_price_lists_status_effective ON price_lists(status, effective_from);
+
+CREATE TABLE IF NOT EXISTS price_list_items (
+    id BIGSERIAL PRIMARY KEY,
+    price_list_id BIGINT NOT NULL,
+    lot_id BIGINT NOT NULL,
+    price_field VARCHAR(50) NOT NULL DEFAULT 'price',
+    old_price NUMERIC(15,2) NOT NULL,
+    new_price NUMERIC(15,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_price_list_items_list FOREIGN KEY (price_list_id) REFERENCES price_lists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_list_items_lot FOREIGN KEY (lot_id) REFERENCES lots(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_price_list_items_price_list_id ON price_list_items(price_list_id);
+
+-- Lot price history: every applied price change (bulk or manual)
+CREATE TABLE IF NOT EXISTS lot_price_histories (
+    id BIGSERIAL PRIMARY KEY,
+    lot_id BIGINT NOT NULL,
+    price_list_id BIGINT,
+    price_field VARCHAR(50) NOT NULL DEFAULT 'price',
+    old_price NUMERIC(15,2),
+    new_price NUMERIC(15,2) NOT NULL,
+    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    changed_by_id BIGINT,
+    reason VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lot_price_histories_lot FOREIGN KEY (lot_id) REFERENCES lots(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_price_histories_list FOREIGN KEY (price_list_id) REFERENCES price_lists(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_lot_price_histories_lot_id ON lot_price_histories(lot_id);
+
+-- Contracts record the price list version they were sold under
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS price_list_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_contracts_price_list_id ON contracts(price_list_id);
@@ -0,0 +1,3 @@
+ALTER TABLE projects DROP COLUMN IF EXISTS geometry;
+ALTER TABLE lots DROP COLUMN IF EXISTS geometry_area;
+ALTER TABLE lots DROP COLUMN IF EXISTS geometry;
@@ -0,0 +1,4 @@
+-- Polygon geometry (GeoJSON) for lots and projects
+ALTER TABLE lots ADD COLUMN IF NOT EXISTS geometry TEXT;
+ALTER TABLE lots ADD COLUMN IF NOT EXISTS geometry_area NUMERIC(12,2);
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS geometry TEXT;
@@ -0,0 +1,16 @@
+-- Lot status transitions driven by contract and payment events
+CREATE TABLE IF NOT EXISTS lot_status_histories (
+    id BIGSERIAL PRIMARY KEY,
+    lot_id BIGINT NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    contract_id BIGINT,
+    payment_id BIGINT,
+    actor_id BIGINT,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lot_status_histories_lot FOREIGN KEY (lot_id) REFERENCES lots(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_lot_status_histories_lot_id ON lot_status_histories(lot_id);
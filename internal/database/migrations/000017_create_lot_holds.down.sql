@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS lot_holds;
+ALTER TABLE projects DROP COLUMN IF EXISTS hold_duration_minutes;
@@ -0,0 +1,25 @@
+-- Temporary seller holds on available lots
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS hold_duration_minutes INTEGER NOT NULL DEFAULT 60;
+
+CREATE TABLE IF NOT EXISTS lot_holds (
+    id BIGSERIAL PRIMARY KEY,
+    lot_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    seller_id BIGINT NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    note TEXT,
+    contract_id BIGINT,
+    released_by BIGINT,
+    released_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lot_holds_lot FOREIGN KEY (lot_id) REFERENCES lots(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_holds_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_holds_seller FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- At most one active hold per lot
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lot_holds_active_lot ON lot_holds(lot_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_lot_holds_seller_id ON lot_holds(seller_id);
+CREATE INDEX IF NOT EXISTS idx_lot_holds_status_expires_at ON lot_holds(status, expires_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS lot_waitlist_entries;
+ALTER TABLE projects DROP COLUMN IF EXISTS waitlist_hold_minutes;
@@ -0,0 +1,32 @@
+-- Customers waiting for a specific lot or any lot of a project under a price
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS waitlist_hold_minutes INTEGER NOT NULL DEFAULT 1440;
+
+CREATE TABLE IF NOT EXISTS lot_waitlist_entries (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    lot_id BIGINT,
+    max_price NUMERIC(15,2),
+    seller_id BIGINT NOT NULL,
+    customer_user_id BIGINT,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_email VARCHAR(255),
+    customer_phone VARCHAR(50),
+    note TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'waiting',
+    offered_lot_id BIGINT,
+    offered_hold_id BIGINT,
+    notified_at TIMESTAMP,
+    offer_expires_at TIMESTAMP,
+    contract_id BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lot_waitlist_entries_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_waitlist_entries_lot FOREIGN KEY (lot_id) REFERENCES lots(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_waitlist_entries_seller FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_lot_waitlist_entries_customer FOREIGN KEY (customer_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lot_waitlist_entries_project_status ON lot_waitlist_entries(project_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_lot_waitlist_entries_lot_id ON lot_waitlist_entries(lot_id);
+CREATE INDEX IF NOT EXISTS idx_lot_waitlist_entries_offered_lot_id ON lot_waitlist_entries(offered_lot_id);
+CREATE INDEX IF NOT EXISTS idx_lot_waitlist_entries_seller_id ON lot_waitlist_entries(seller_id);
@@ -0,0 +1,6 @@
+ALTER TABLE lots DROP CONSTRAINT IF EXISTS fk_lots_block;
+ALTER TABLE lots DROP CONSTRAINT IF EXISTS fk_lots_phase;
+ALTER TABLE lots DROP COLUMN IF EXISTS block_id;
+ALTER TABLE lots DROP COLUMN IF EXISTS phase_id;
+DROP TABLE IF EXISTS project_blocks;
+DROP TABLE IF EXISTS project_phases;
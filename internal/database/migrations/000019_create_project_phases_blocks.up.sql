@@ -0,0 +1,43 @@
+-- Optional Phase → Block → Lot hierarchy for large projects
+CREATE TABLE IF NOT EXISTS project_phases (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'on_sale',
+    price_per_square_unit NUMERIC(10,2),
+    delivery_date DATE,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_project_phases_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_phases_project_name ON project_phases(project_id, name);
+
+CREATE TABLE IF NOT EXISTS project_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    phase_id BIGINT,
+    name VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'on_sale',
+    price_per_square_unit NUMERIC(10,2),
+    delivery_date DATE,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_project_blocks_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_project_blocks_phase FOREIGN KEY (phase_id) REFERENCES project_phases(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_blocks_project_id ON project_blocks(project_id);
+CREATE INDEX IF NOT EXISTS idx_project_blocks_phase_id ON project_blocks(phase_id);
+
+ALTER TABLE lots ADD COLUMN IF NOT EXISTS phase_id BIGINT;
+ALTER TABLE lots ADD COLUMN IF NOT EXISTS block_id BIGINT;
+ALTER TABLE lots ADD CONSTRAINT fk_lots_phase FOREIGN KEY (phase_id) REFERENCES project_phases(id) ON DELETE SET NULL;
+ALTER TABLE lots ADD CONSTRAINT fk_lots_block FOREIGN KEY (block_id) REFERENCES project_blocks(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lots_phase_id ON lots(phase_id);
+CREATE INDEX IF NOT EXISTS idx_lots_block_id ON lots(block_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_projects_status;
+ALTER TABLE projects DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE projects DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS approved_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS approved_by;
+ALTER TABLE projects DROP COLUMN IF EXISTS submitted_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS status;
@@ -0,0 +1,12 @@
+-- Project lifecycle: draft → pending_approval → published → closed
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS status VARCHAR(30) NOT NULL DEFAULT 'draft';
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS approved_by BIGINT;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS approved_at TIMESTAMP;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+-- Projects created before the lifecycle existed were already selling
+UPDATE projects SET status = 'published', approved_at = COALESCE(approved_at, created_at) WHERE status = 'draft';
+
+CREATE INDEX IF NOT EXISTS idx_projects_status ON projects(status);
@@ -0,0 +1,19 @@
+-- Co-buyers, guarantors and legal representatives of a contract besides the applicant
+CREATE TABLE IF NOT EXISTS contract_parties (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    user_id BIGINT,
+    role VARCHAR(50) NOT NULL,
+    full_name VARCHAR(255) NOT NULL,
+    identity VARCHAR(50),
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    ownership_percentage NUMERIC(5,2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_parties_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_parties_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_parties_contract_id ON contract_parties(contract_id);
+CREATE INDEX IF NOT EXISTS idx_contract_parties_user_id ON contract_parties(user_id);
@@ -0,0 +1,22 @@
+-- Transfers of a contract from its applicant to a new buyer
+CREATE TABLE IF NOT EXISTS contract_cessions (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    from_user_id BIGINT NOT NULL,
+    to_user_id BIGINT NOT NULL,
+    actor_id BIGINT NOT NULL,
+    paid_at_cession NUMERIC(15,2) NOT NULL DEFAULT 0,
+    balance_at_cession NUMERIC(15,2) NOT NULL DEFAULT 0,
+    transfer_fee NUMERIC(15,2),
+    fee_payment_id BIGINT,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_cessions_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_cessions_from_user FOREIGN KEY (from_user_id) REFERENCES users(id),
+    CONSTRAINT fk_contract_cessions_to_user FOREIGN KEY (to_user_id) REFERENCES users(id),
+    CONSTRAINT fk_contract_cessions_fee_payment FOREIGN KEY (fee_payment_id) REFERENCES payments(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_cessions_contract_id ON contract_cessions(contract_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_contract_cessions_from_user_id ON contract_cessions(from_user_id);
+CREATE INDEX IF NOT EXISTS idx_contract_cessions_to_user_id ON contract_cessions(to_user_id);
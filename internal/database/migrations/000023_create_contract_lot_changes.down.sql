@@ -0,0 +1 @@
+DROP TABLE IF EXISTS contract_lot_changes;
@@ -0,0 +1,20 @@
+-- Moves of an approved contract to another lot of the same project
+CREATE TABLE IF NOT EXISTS contract_lot_changes (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    from_lot_id BIGINT NOT NULL,
+    to_lot_id BIGINT NOT NULL,
+    old_amount NUMERIC(15,2) NOT NULL,
+    new_amount NUMERIC(15,2) NOT NULL,
+    price_difference NUMERIC(15,2) NOT NULL,
+    ledger_entry_id BIGINT,
+    actor_id BIGINT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_lot_changes_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_lot_changes_from_lot FOREIGN KEY (from_lot_id) REFERENCES lots(id),
+    CONSTRAINT fk_contract_lot_changes_to_lot FOREIGN KEY (to_lot_id) REFERENCES lots(id),
+    CONSTRAINT fk_contract_lot_changes_ledger_entry FOREIGN KEY (ledger_entry_id) REFERENCES contract_ledger_entries(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_lot_changes_contract_id ON contract_lot_changes(contract_id, created_at);
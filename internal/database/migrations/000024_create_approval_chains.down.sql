@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_contracts_approval_chain_id;
+ALTER TABLE contracts DROP COLUMN IF EXISTS approval_steps;
+ALTER TABLE contracts DROP COLUMN IF EXISTS approval_step;
+ALTER TABLE contracts DROP COLUMN IF EXISTS approval_chain_id;
+ALTER TABLE contracts DROP COLUMN IF EXISTS submitted_at;
+
+DROP TABLE IF EXISTS contract_approvals;
+DROP TABLE IF EXISTS approval_chain_steps;
+DROP TABLE IF EXISTS approval_chains;
@@ -0,0 +1,53 @@
+-- Multi-step contract approval: chains per project and amount, their steps and the decisions taken
+CREATE TABLE IF NOT EXISTS approval_chains (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    project_id BIGINT,
+    min_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_approval_chains_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_chains_project_id ON approval_chains(project_id);
+CREATE INDEX IF NOT EXISTS idx_approval_chains_active ON approval_chains(active);
+
+CREATE TABLE IF NOT EXISTS approval_chain_steps (
+    id BIGSERIAL PRIMARY KEY,
+    chain_id BIGINT NOT NULL,
+    position INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    approver_user_id BIGINT,
+    approver_role VARCHAR(30),
+    CONSTRAINT fk_approval_chain_steps_chain FOREIGN KEY (chain_id) REFERENCES approval_chains(id) ON DELETE CASCADE,
+    CONSTRAINT fk_approval_chain_steps_user FOREIGN KEY (approver_user_id) REFERENCES users(id),
+    CONSTRAINT uq_approval_chain_steps_position UNIQUE (chain_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_chain_steps_approver_user_id ON approval_chain_steps(approver_user_id);
+
+CREATE TABLE IF NOT EXISTS contract_approvals (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    chain_id BIGINT NOT NULL,
+    step_id BIGINT NOT NULL,
+    position INTEGER NOT NULL,
+    step_name VARCHAR(255) NOT NULL,
+    approver_id BIGINT NOT NULL,
+    decision VARCHAR(20) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_approvals_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_approvals_approver FOREIGN KEY (approver_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_approvals_contract_id ON contract_approvals(contract_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_contract_approvals_approver_id ON contract_approvals(approver_id);
+
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS approval_chain_id BIGINT;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS approval_step INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS approval_steps INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_contracts_approval_chain_id ON contracts(approval_chain_id);
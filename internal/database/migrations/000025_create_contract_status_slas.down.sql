@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_contracts_status_changed_at;
+ALTER TABLE contracts DROP COLUMN IF EXISTS sla_escalated_at;
+ALTER TABLE contracts DROP COLUMN IF EXISTS status_changed_at;
+
+DROP TABLE IF EXISTS contract_status_slas;
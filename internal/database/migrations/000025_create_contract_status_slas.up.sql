@@ -0,0 +1,28 @@
+-- Time-in-status SLAs: maximum hours per contract status, per project or for all projects (project_id NULL)
+CREATE TABLE IF NOT EXISTS contract_status_slas (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT,
+    status VARCHAR(30) NOT NULL,
+    max_hours INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_status_slas_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- One rule per project and status; COALESCE so the default rule (NULL project) is unique too
+CREATE UNIQUE INDEX IF NOT EXISTS uq_contract_status_slas_project_status ON contract_status_slas(COALESCE(project_id, 0), status);
+
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS sla_escalated_at TIMESTAMP;
+
+-- Best known entry into the current status for existing contracts
+UPDATE contracts SET status_changed_at = CASE
+    WHEN status = 'approved' THEN COALESCE(approved_at, updated_at)
+    WHEN status = 'closed' THEN COALESCE(closed_at, updated_at)
+    WHEN status = 'submitted' THEN COALESCE(submitted_at, updated_at)
+    WHEN status = 'pending' THEN created_at
+    ELSE updated_at
+END
+WHERE status_changed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_contracts_status_changed_at ON contracts(status, status_changed_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contract_terms_versions;
+DROP TABLE IF EXISTS contract_status_transitions;
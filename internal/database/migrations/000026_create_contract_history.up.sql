@@ -0,0 +1,47 @@
+-- Contract status transitions written by the contract FSM
+CREATE TABLE IF NOT EXISTS contract_status_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    from_status VARCHAR(30) NOT NULL,
+    to_status VARCHAR(30) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    actor_id BIGINT,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_status_transitions_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_status_transitions_actor FOREIGN KEY (actor_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_status_transitions_contract_id ON contract_status_transitions(contract_id, created_at);
+
+-- Versioned snapshots of the financial terms of contracts
+CREATE TABLE IF NOT EXISTS contract_terms_versions (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    version INTEGER NOT NULL,
+    source VARCHAR(30) NOT NULL,
+    actor_id BIGINT,
+    reason TEXT,
+    lot_id BIGINT NOT NULL,
+    applicant_user_id BIGINT NOT NULL,
+    financing_type VARCHAR(30) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    payment_term INTEGER NOT NULL,
+    amount NUMERIC(15,2),
+    down_payment NUMERIC(15,2),
+    reserve_amount NUMERIC(15,2),
+    commission_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    max_payment_date DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_terms_versions_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_terms_versions_actor FOREIGN KEY (actor_id) REFERENCES users(id),
+    CONSTRAINT uq_contract_terms_versions_version UNIQUE (contract_id, version)
+);
+
+-- Current terms of existing contracts become their first version
+INSERT INTO contract_terms_versions (contract_id, version, source, lot_id, applicant_user_id, financing_type, currency,
+    payment_term, amount, down_payment, reserve_amount, commission_amount, max_payment_date, created_at)
+SELECT id, 1, 'backfill', lot_id, applicant_user_id, financing_type, COALESCE(currency, 'HNL'),
+    payment_term, amount, down_payment, reserve_amount, COALESCE(commission_amount, 0), max_payment_date, CURRENT_TIMESTAMP
+FROM contracts
+WHERE NOT EXISTS (SELECT 1 FROM contract_terms_versions v WHERE v.contract_id = contracts.id);
@@ -0,0 +1,26 @@
+-- Typed, versioned documents attached to contracts. Versions of the same document share
+-- lineage_id (the id of the first version); deleting is soft and keeps the files.
+CREATE TABLE IF NOT EXISTS contract_documents (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    lineage_id BIGINT,
+    version INTEGER NOT NULL DEFAULT 1,
+    document_type VARCHAR(30) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    uploaded_by_id BIGINT NOT NULL,
+    superseded_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    deleted_by_id BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_documents_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_documents_uploaded_by FOREIGN KEY (uploaded_by_id) REFERENCES users(id),
+    CONSTRAINT fk_contract_documents_deleted_by FOREIGN KEY (deleted_by_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_documents_contract_id ON contract_documents(contract_id);
+CREATE INDEX IF NOT EXISTS idx_contract_documents_lineage_id ON contract_documents(lineage_id, version);
+CREATE INDEX IF NOT EXISTS idx_contract_documents_deleted_at ON contract_documents(deleted_at);
@@ -0,0 +1,5 @@
+ALTER TABLE contracts DROP COLUMN IF EXISTS kyc_overridden_at;
+ALTER TABLE contracts DROP COLUMN IF EXISTS kyc_overridden_by_id;
+ALTER TABLE contracts DROP COLUMN IF EXISTS kyc_override_reason;
+
+DROP TABLE IF EXISTS kyc_requirements;
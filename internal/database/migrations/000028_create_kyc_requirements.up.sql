@@ -0,0 +1,28 @@
+-- KYC checklist items required before approving contracts. Requirements without a project apply
+-- to every project and without a financing type to every financing type.
+CREATE TABLE IF NOT EXISTS kyc_requirements (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT,
+    financing_type VARCHAR(30),
+    item VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_kyc_requirements_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_kyc_requirements_scope_item
+    ON kyc_requirements(COALESCE(project_id, 0), COALESCE(financing_type, ''), item);
+
+-- Default checklist: identity scan, RTN and proof of income for every contract, plus the bank
+-- pre-approval letter for bank financing
+INSERT INTO kyc_requirements (item, financing_type) VALUES
+    ('identity_scan', NULL),
+    ('rtn', NULL),
+    ('proof_of_income', NULL),
+    ('bank_preapproval', 'bank')
+ON CONFLICT DO NOTHING;
+
+-- Admin approval without a complete checklist
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS kyc_override_reason TEXT;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS kyc_overridden_by_id BIGINT REFERENCES users(id);
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS kyc_overridden_at TIMESTAMP;
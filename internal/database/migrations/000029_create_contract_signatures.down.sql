@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contract_signers;
+DROP TABLE IF EXISTS contract_signature_requests;
@@ -0,0 +1,49 @@
+-- Electronic signature of promise contracts. The unsigned PDF is hashed when the request starts;
+-- each signer's link hashes the previous link with the signer and their drawn signature, and
+-- chain_hash binds the last link to the signed PDF.
+CREATE TABLE IF NOT EXISTS contract_signature_requests (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    document_path VARCHAR(500) NOT NULL,
+    document_sha256 CHAR(64) NOT NULL,
+    signed_document_id BIGINT,
+    signed_sha256 CHAR(64),
+    chain_hash CHAR(64),
+    created_by_id BIGINT NOT NULL,
+    completed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_signature_requests_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_signature_requests_document FOREIGN KEY (signed_document_id) REFERENCES contract_documents(id),
+    CONSTRAINT fk_contract_signature_requests_created_by FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_signature_requests_contract_id ON contract_signature_requests(contract_id);
+-- At most one signature in progress per contract
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contract_signature_requests_pending ON contract_signature_requests(contract_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS contract_signers (
+    id BIGSERIAL PRIMARY KEY,
+    request_id BIGINT NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    user_id BIGINT,
+    otp_hash CHAR(64),
+    otp_expires_at TIMESTAMP,
+    otp_attempts INTEGER NOT NULL DEFAULT 0,
+    signature_path VARCHAR(500),
+    signature_sha256 CHAR(64),
+    signed_at TIMESTAMP,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    prev_hash CHAR(64),
+    hash CHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_contract_signers_request FOREIGN KEY (request_id) REFERENCES contract_signature_requests(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_signers_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT uq_contract_signers_role UNIQUE (request_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_signers_request_id ON contract_signers(request_id);
@@ -0,0 +1,19 @@
+-- Documents generated by the system, stamped with a code and a QR code linking to their public
+-- verification. figures holds the key figures the verification discloses.
+CREATE TABLE IF NOT EXISTS issued_documents (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL,
+    document_type VARCHAR(40) NOT NULL,
+    contract_id BIGINT,
+    user_id BIGINT,
+    sha256 CHAR(64) NOT NULL,
+    figures JSONB NOT NULL DEFAULT '[]',
+    issued_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_issued_documents_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE SET NULL,
+    CONSTRAINT fk_issued_documents_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issued_documents_code ON issued_documents(code);
+CREATE INDEX IF NOT EXISTS idx_issued_documents_contract_id ON issued_documents(contract_id);
+CREATE INDEX IF NOT EXISTS idx_issued_documents_user_id ON issued_documents(user_id);
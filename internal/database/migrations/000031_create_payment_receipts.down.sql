@@ -0,0 +1,3 @@
+ALTER TABLE issued_documents DROP COLUMN IF EXISTS voided_at;
+DROP TABLE IF EXISTS payment_receipts;
+DROP TABLE IF EXISTS receipt_sequences;
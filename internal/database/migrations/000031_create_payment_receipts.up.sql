@@ -0,0 +1,52 @@
+-- Numbering series of payment receipts: one for the company, or one per project. The row is
+-- locked while a receipt is numbered so numbers have no gaps.
+CREATE TABLE IF NOT EXISTS receipt_sequences (
+    id BIGSERIAL PRIMARY KEY,
+    series VARCHAR(20) NOT NULL,
+    next_number BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receipt_sequences_series ON receipt_sequences(series);
+
+-- Official receipts of approved payments. Undoing a payment voids its receipt, whose number stays
+-- used; approving it again issues a new receipt that replaces the voided one.
+CREATE TABLE IF NOT EXISTS payment_receipts (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL,
+    contract_id BIGINT NOT NULL,
+    series VARCHAR(20) NOT NULL,
+    number BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'issued',
+    principal DECIMAL(15,2) NOT NULL,
+    interest DECIMAL(15,2) NOT NULL,
+    prepayment DECIMAL(15,2) NOT NULL,
+    total DECIMAL(15,2) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    payment_date DATE NOT NULL,
+    issued_at TIMESTAMP NOT NULL,
+    issued_by_id BIGINT NOT NULL,
+    replaces_id BIGINT,
+    path VARCHAR(500),
+    sha256 CHAR(64),
+    issued_document_id BIGINT,
+    voided_at TIMESTAMP,
+    voided_by_id BIGINT,
+    void_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_receipts_payment FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_payment_receipts_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_payment_receipts_issued_by FOREIGN KEY (issued_by_id) REFERENCES users(id),
+    CONSTRAINT fk_payment_receipts_replaces FOREIGN KEY (replaces_id) REFERENCES payment_receipts(id),
+    CONSTRAINT fk_payment_receipts_issued_document FOREIGN KEY (issued_document_id) REFERENCES issued_documents(id) ON DELETE SET NULL,
+    CONSTRAINT fk_payment_receipts_voided_by FOREIGN KEY (voided_by_id) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_receipts_series_number ON payment_receipts(series, number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_receipts_issued_payment ON payment_receipts(payment_id) WHERE status = 'issued';
+CREATE INDEX IF NOT EXISTS idx_payment_receipts_contract_id ON payment_receipts(contract_id);
+
+-- Voided documents (e.g. the receipt of an undone payment) no longer verify
+ALTER TABLE issued_documents ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fiscal_invoices;
+DROP TABLE IF EXISTS fiscal_ranges;
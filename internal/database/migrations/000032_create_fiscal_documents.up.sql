@@ -0,0 +1,59 @@
+-- Invoice number ranges authorized by the tax authority for an establishment and point of sale,
+-- identified by their CAI and usable until their emission deadline. The row is locked while a
+-- number is allocated.
+CREATE TABLE IF NOT EXISTS fiscal_ranges (
+    id BIGSERIAL PRIMARY KEY,
+    cai VARCHAR(40) NOT NULL,
+    establishment CHAR(3) NOT NULL,
+    point_of_sale CHAR(3) NOT NULL,
+    document_type CHAR(2) NOT NULL DEFAULT '01',
+    range_start BIGINT NOT NULL,
+    range_end BIGINT NOT NULL,
+    next_number BIGINT NOT NULL,
+    deadline DATE NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    warned_at TIMESTAMP,
+    created_by_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_fiscal_ranges_created_by FOREIGN KEY (created_by_id) REFERENCES users(id),
+    CONSTRAINT chk_fiscal_ranges_bounds CHECK (range_start >= 1 AND range_end >= range_start AND next_number BETWEEN range_start AND range_end + 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_ranges_cai ON fiscal_ranges(cai);
+CREATE INDEX IF NOT EXISTS idx_fiscal_ranges_point_of_sale ON fiscal_ranges(establishment, point_of_sale, document_type);
+
+-- Invoices of the interest and fees collected with payments. lines holds the billed charges;
+-- amounts of taxed lines include the sales tax.
+CREATE TABLE IF NOT EXISTS fiscal_invoices (
+    id BIGSERIAL PRIMARY KEY,
+    fiscal_range_id BIGINT NOT NULL,
+    number VARCHAR(20) NOT NULL,
+    contract_id BIGINT NOT NULL,
+    payment_id BIGINT,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_rtn VARCHAR(20),
+    currency VARCHAR(10) NOT NULL,
+    lines JSONB NOT NULL DEFAULT '[]',
+    exempt DECIMAL(15,2) NOT NULL,
+    taxable DECIMAL(15,2) NOT NULL,
+    tax DECIMAL(15,2) NOT NULL,
+    total DECIMAL(15,2) NOT NULL,
+    issued_at TIMESTAMP NOT NULL,
+    issued_by_id BIGINT NOT NULL,
+    path VARCHAR(500),
+    sha256 CHAR(64),
+    issued_document_id BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_fiscal_invoices_range FOREIGN KEY (fiscal_range_id) REFERENCES fiscal_ranges(id),
+    CONSTRAINT fk_fiscal_invoices_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_fiscal_invoices_payment FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_fiscal_invoices_issued_by FOREIGN KEY (issued_by_id) REFERENCES users(id),
+    CONSTRAINT fk_fiscal_invoices_issued_document FOREIGN KEY (issued_document_id) REFERENCES issued_documents(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_invoices_number ON fiscal_invoices(number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_invoices_payment_id ON fiscal_invoices(payment_id) WHERE payment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_fiscal_invoices_contract_id ON fiscal_invoices(contract_id);
+CREATE INDEX IF NOT EXISTS idx_fiscal_invoices_fiscal_range_id ON fiscal_invoices(fiscal_range_id);
@@ -0,0 +1,25 @@
+-- Reports generated in the background. The output file is kept in storage until expires_at, then
+-- deleted and the job marked expired.
+CREATE TABLE IF NOT EXISTS report_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    report_type VARCHAR(50) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    requested_by_id BIGINT NOT NULL,
+    filename VARCHAR(255),
+    content_type VARCHAR(100),
+    path VARCHAR(500),
+    size BIGINT NOT NULL DEFAULT 0,
+    sha256 VARCHAR(64),
+    error TEXT,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_report_jobs_requested_by FOREIGN KEY (requested_by_id) REFERENCES users(id),
+    CONSTRAINT chk_report_jobs_status CHECK (status IN ('queued', 'running', 'completed', 'failed', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_jobs_requested_by ON report_jobs(requested_by_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_report_jobs_expires_at ON report_jobs(expires_at) WHERE status = 'completed';
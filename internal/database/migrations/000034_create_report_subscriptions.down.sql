@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS report_subscription_runs;
+DROP TABLE IF EXISTS report_subscriptions;
@@ -0,0 +1,37 @@
+-- Reports emailed to a list of recipients on a cron-like schedule, with the history of their runs
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    report_type VARCHAR(50) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    recipients JSONB NOT NULL DEFAULT '[]',
+    schedule VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    last_status VARCHAR(20),
+    created_by_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_report_subscriptions_created_by FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_next_run_at ON report_subscriptions(next_run_at) WHERE active = TRUE;
+
+CREATE TABLE IF NOT EXISTS report_subscription_runs (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    filename VARCHAR(255),
+    size BIGINT NOT NULL DEFAULT 0,
+    recipients JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    manual BOOLEAN NOT NULL DEFAULT FALSE,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_report_subscription_runs_subscription FOREIGN KEY (subscription_id) REFERENCES report_subscriptions(id) ON DELETE CASCADE,
+    CONSTRAINT chk_report_subscription_runs_status CHECK (status IN ('sent', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscription_runs_subscription ON report_subscription_runs(subscription_id, started_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_issued_documents_source;
+ALTER TABLE issued_documents DROP COLUMN IF EXISTS source_sha256;
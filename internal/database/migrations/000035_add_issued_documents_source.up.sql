@@ -0,0 +1,5 @@
+-- Hash of the data a document was rendered from, so downloading an unchanged document again
+-- reuses its code instead of issuing a new one
+ALTER TABLE issued_documents ADD COLUMN IF NOT EXISTS source_sha256 CHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_issued_documents_source ON issued_documents(document_type, source_sha256) WHERE voided_at IS NULL;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_fiscal_invoices_payment_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_invoices_payment_id ON fiscal_invoices(payment_id) WHERE payment_id IS NOT NULL;
+
+ALTER TABLE fiscal_invoices DROP CONSTRAINT IF EXISTS fk_fiscal_invoices_payment;
+ALTER TABLE fiscal_invoices ADD CONSTRAINT fk_fiscal_invoices_payment FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE RESTRICT;
+
+ALTER TABLE fiscal_invoices DROP CONSTRAINT IF EXISTS fk_fiscal_invoices_voided_by;
+ALTER TABLE fiscal_invoices DROP COLUMN IF EXISTS void_reason;
+ALTER TABLE fiscal_invoices DROP COLUMN IF EXISTS voided_by_id;
+ALTER TABLE fiscal_invoices DROP COLUMN IF EXISTS voided_at;
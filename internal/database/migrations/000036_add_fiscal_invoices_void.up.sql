@@ -0,0 +1,15 @@
+-- Invoices of undone payments are voided instead of deleted; their number stays used and approving
+-- the payment again issues a new invoice. Payments may be deleted (e.g. when a contract is
+-- cancelled) while their voided invoices are kept.
+ALTER TABLE fiscal_invoices ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
+ALTER TABLE fiscal_invoices ADD COLUMN IF NOT EXISTS voided_by_id BIGINT;
+ALTER TABLE fiscal_invoices ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+ALTER TABLE fiscal_invoices DROP CONSTRAINT IF EXISTS fk_fiscal_invoices_voided_by;
+ALTER TABLE fiscal_invoices ADD CONSTRAINT fk_fiscal_invoices_voided_by FOREIGN KEY (voided_by_id) REFERENCES users(id);
+
+ALTER TABLE fiscal_invoices DROP CONSTRAINT IF EXISTS fk_fiscal_invoices_payment;
+ALTER TABLE fiscal_invoices ADD CONSTRAINT fk_fiscal_invoices_payment FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE SET NULL;
+
+DROP INDEX IF EXISTS idx_fiscal_invoices_payment_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiscal_invoices_payment_id ON fiscal_invoices(payment_id) WHERE payment_id IS NOT NULL AND voided_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE contracts DROP COLUMN IF EXISTS closed_from_status;
@@ -0,0 +1,10 @@
+-- Status a contract was closed from, so reopening a signed contract keeps it signed
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS closed_from_status VARCHAR(30);
+
+-- Contracts already closed take it from their last close transition
+UPDATE contracts c SET closed_from_status = (
+    SELECT t.from_status FROM contract_status_transitions t
+    WHERE t.contract_id = c.id AND t.event = 'close'
+    ORDER BY t.created_at DESC, t.id DESC LIMIT 1
+)
+WHERE c.status = 'closed' AND c.closed_from_status IS NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS issued_document_copies;
@@ -0,0 +1,11 @@
+-- Hashes of the copies of an issued document rendered again from unchanged data (the PDF embeds its
+-- creation time). Rows are only added, so every copy handed out keeps verifying.
+CREATE TABLE IF NOT EXISTS issued_document_copies (
+    id BIGSERIAL PRIMARY KEY,
+    issued_document_id BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_issued_document_copies_document FOREIGN KEY (issued_document_id) REFERENCES issued_documents(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issued_document_copies_hash ON issued_document_copies(issued_document_id, sha256);
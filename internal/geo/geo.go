@@ -0,0 +1,378 @@
+// Package geo normalizes lot/project polygons (GeoJSON or WKT) to GeoJSON geometries and computes their area.
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadius is the WGS84 equatorial radius in meters (same constant used by turf/mapbox)
+const earthRadius = 6378137.0
+
+// Geometry types supported for lots and projects
+const (
+	TypePolygon      = "Polygon"
+	TypeMultiPolygon = "MultiPolygon"
+)
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection returns an empty feature collection
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// polygon is a list of rings (first outer, rest holes); a ring is a list of [x, y] points
+type polygon [][][2]float64
+
+// Normalize accepts a GeoJSON geometry, a GeoJSON Feature or a WKT POLYGON/MULTIPOLYGON and
+// returns the equivalent GeoJSON geometry as a compact JSON string.
+func Normalize(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("geometría vacía")
+	}
+
+	var polys []polygon
+	var err error
+	if strings.HasPrefix(input, "{") {
+		polys, err = parseGeoJSON([]byte(input))
+	} else {
+		polys, err = parseWKT(input)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := validate(polys); err != nil {
+		return "", err
+	}
+	return encode(polys)
+}
+
+// AreaSquareMeters returns the area of a normalized geometry in square meters. Coordinates are
+// treated as WGS84 longitude/latitude (RFC 7946) when they fit that range and span less than a
+// degree (any lot or subdivision does); otherwise they are assumed to be projected or local survey
+// coordinates in meters (e.g. UTM or a CAD plan).
+func AreaSquareMeters(geometry string) (float64, error) {
+	polys, err := parseGeoJSON([]byte(geometry))
+	if err != nil {
+		return 0, err
+	}
+	geographic := isGeographic(polys)
+
+	total := 0.0
+	for _, p := range polys {
+		for i, ring := range p {
+			var a float64
+			if geographic {
+				a = geodesicRingArea(ring)
+			} else {
+				a = planarRingArea(ring)
+			}
+			if i == 0 {
+				total += a
+			} else {
+				total -= a
+			}
+		}
+	}
+	return math.Round(total*100) / 100, nil
+}
+
+// squareMetersPer is how many square meters one unit of each measurement unit holds
+var squareMetersPer = map[string]float64{
+	"m2":      1,
+	"mts2":    1,
+	"vara2":   0.698896,
+	"varas2":  0.698896,
+	"v2":      0.698896,
+	"ft2":     0.092903,
+	"pie2":    0.092903,
+	"ha":      10000,
+	"mz":      6987.37,
+	"manzana": 6987.37,
+}
+
+// ConvertArea converts square meters to the given measurement unit (m2 when unknown)
+func ConvertArea(squareMeters float64, unit string) float64 {
+	factor, ok := squareMetersPer[strings.ToLower(strings.ReplaceAll(unit, " ", ""))]
+	if !ok {
+		factor = 1
+	}
+	return math.Round(squareMeters/factor*100) / 100
+}
+
+func parseGeoJSON(data []byte) ([]polygon, error) {
+	var probe struct {
+		Type        string          `json:"type"`
+		Geometry    json.RawMessage `json:"geometry"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("GeoJSON inválido: %w", err)
+	}
+
+	switch probe.Type {
+	case "Feature":
+		if len(probe.Geometry) == 0 || string(probe.Geometry) == "null" {
+			return nil, errors.New("el Feature no tiene geometría")
+		}
+		return parseGeoJSON(probe.Geometry)
+	case TypePolygon:
+		var p polygon
+		if err := json.Unmarshal(probe.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("coordenadas de Polygon inválidas: %w", err)
+		}
+		return []polygon{p}, nil
+	case TypeMultiPolygon:
+		var ps []polygon
+		if err := json.Unmarshal(probe.Coordinates, &ps); err != nil {
+			return nil, fmt.Errorf("coordenadas de MultiPolygon inválidas: %w", err)
+		}
+		return ps, nil
+	default:
+		return nil, fmt.Errorf("tipo de geometría no soportado: %q (Polygon, MultiPolygon)", probe.Type)
+	}
+}
+
+// parseWKT parses POLYGON ((x y, ...), (...)) and MULTIPOLYGON (((x y, ...)), ((...)))
+func parseWKT(input string) ([]polygon, error) {
+	upper := strings.ToUpper(input)
+	var depth int
+	var body string
+	switch {
+	case strings.HasPrefix(upper, "MULTIPOLYGON"):
+		depth, body = 3, input[len("MULTIPOLYGON"):]
+	case strings.HasPrefix(upper, "POLYGON"):
+		depth, body = 2, input[len("POLYGON"):]
+	default:
+		return nil, errors.New("WKT no soportado: use POLYGON o MULTIPOLYGON")
+	}
+	body = strings.TrimSpace(body)
+
+	p := &wktParser{s: body}
+	nested, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.s) {
+		return nil, errors.New("WKT inválido: texto sobrante")
+	}
+
+	toPolygon := func(rings []interface{}) (polygon, error) {
+		var out polygon
+		for _, r := range rings {
+			points, ok := r.([]interface{})
+			if !ok {
+				return nil, errors.New("WKT inválido: anillo mal formado")
+			}
+			var ring [][2]float64
+			for _, pt := range points {
+				xy, ok := pt.([2]float64)
+				if !ok {
+					return nil, errors.New("WKT inválido: punto mal formado")
+				}
+				ring = append(ring, xy)
+			}
+			out = append(out, ring)
+		}
+		return out, nil
+	}
+
+	if depth == 2 {
+		poly, err := toPolygon(nested)
+		if err != nil {
+			return nil, err
+		}
+		return []polygon{poly}, nil
+	}
+	var polys []polygon
+	for _, item := range nested {
+		rings, ok := item.([]interface{})
+		if !ok {
+			return nil, errors.New("WKT inválido: polígono mal formado")
+		}
+		poly, err := toPolygon(rings)
+		if err != nil {
+			return nil, err
+		}
+		polys = append(polys, poly)
+	}
+	return polys, nil
+}
+
+// wktParser reads nested parenthesized lists whose leaves are "x y" points
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *wktParser) parseList() ([]interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+		return nil, errors.New("WKT inválido: se esperaba '('")
+	}
+	p.pos++
+	var items []interface{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, errors.New("WKT inválido: falta ')'")
+		}
+		if p.s[p.pos] == '(' {
+			list, err := p.parseList()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, list)
+		} else {
+			pt, err := p.parsePoint()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, pt)
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, errors.New("WKT inválido: falta ')'")
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			p.skipSpace()
+			return items, nil
+		default:
+			return nil, fmt.Errorf("WKT inválido: carácter inesperado %q", p.s[p.pos])
+		}
+	}
+}
+
+func (p *wktParser) parsePoint() ([2]float64, error) {
+	end := strings.IndexAny(p.s[p.pos:], ",)")
+	if end < 0 {
+		return [2]float64{}, errors.New("WKT inválido: punto sin terminar")
+	}
+	fields := strings.Fields(p.s[p.pos : p.pos+end])
+	if len(fields) < 2 {
+		return [2]float64{}, errors.New("WKT inválido: el punto requiere x y")
+	}
+	x, errX := strconv.ParseFloat(fields[0], 64)
+	y, errY := strconv.ParseFloat(fields[1], 64)
+	if errX != nil || errY != nil {
+		return [2]float64{}, fmt.Errorf("WKT inválido: coordenada %q", p.s[p.pos:p.pos+end])
+	}
+	p.pos += end
+	return [2]float64{x, y}, nil
+}
+
+// validate checks rings are closed with at least 4 points and closes them if only the last point is missing
+func validate(polys []polygon) error {
+	if len(polys) == 0 {
+		return errors.New("la geometría no tiene polígonos")
+	}
+	for pi := range polys {
+		if len(polys[pi]) == 0 {
+			return errors.New("polígono sin anillos")
+		}
+		for ri := range polys[pi] {
+			ring := polys[pi][ri]
+			if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+				ring = append(ring, ring[0])
+				polys[pi][ri] = ring
+			}
+			if len(ring) < 4 {
+				return errors.New("cada anillo requiere al menos 3 vértices distintos")
+			}
+		}
+	}
+	return nil
+}
+
+func encode(polys []polygon) (string, error) {
+	var g interface{}
+	if len(polys) == 1 {
+		g = struct {
+			Type        string  `json:"type"`
+			Coordinates polygon `json:"coordinates"`
+		}{TypePolygon, polys[0]}
+	} else {
+		g = struct {
+			Type        string    `json:"type"`
+			Coordinates []polygon `json:"coordinates"`
+		}{TypeMultiPolygon, polys}
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func isGeographic(polys []polygon) bool {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range polys {
+		for _, ring := range p {
+			for _, pt := range ring {
+				if math.Abs(pt[0]) > 180 || math.Abs(pt[1]) > 90 {
+					return false
+				}
+				minX, maxX = math.Min(minX, pt[0]), math.Max(maxX, pt[0])
+				minY, maxY = math.Min(minY, pt[1]), math.Max(maxY, pt[1])
+			}
+		}
+	}
+	return maxX-minX < 1 && maxY-minY < 1
+}
+
+// planarRingArea is the shoelace formula (absolute value)
+func planarRingArea(ring [][2]float64) float64 {
+	sum := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		sum += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return math.Abs(sum) / 2
+}
+
+// geodesicRingArea approximates the area of a lon/lat ring on the sphere (absolute value)
+func geodesicRingArea(ring [][2]float64) float64 {
+	n := len(ring)
+	if n < 3 {
+		return 0
+	}
+	sum := 0.0
+	for i := 0; i < n-1; i++ {
+		lon1, lat1 := rad(ring[i][0]), rad(ring[i][1])
+		lon2, lat2 := rad(ring[i+1][0]), rad(ring[i+1][1])
+		sum += (lon2 - lon1) * (2 + math.Sin(lat1) + math.Sin(lat2))
+	}
+	return math.Abs(sum * earthRadius * earthRadius / 2)
+}
+
+func rad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
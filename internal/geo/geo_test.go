@@ -0,0 +1,44 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAndArea(t *testing.T) {
+	// Local survey coordinates in meters, ring left open
+	g, err := Normalize("POLYGON ((0 0, 10 0, 10 20, 0 20))")
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,20],[0,20],[0,0]]]}`, g)
+	area, err := AreaSquareMeters(g)
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, area)
+	assert.Equal(t, 286.17, ConvertArea(area, "vara2"))
+
+	// Polygon with a hole
+	g, err = Normalize("POLYGON ((0 0, 30 0, 30 30, 0 30, 0 0), (10 10, 20 10, 20 20, 10 20, 10 10))")
+	require.NoError(t, err)
+	area, _ = AreaSquareMeters(g)
+	assert.Equal(t, 800.0, area)
+
+	// WGS84 lon/lat Feature (~11m x 11m near Tegucigalpa)
+	g, err = Normalize(`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[-87.2,14.1],[-87.1999,14.1],[-87.1999,14.1001],[-87.2,14.1001],[-87.2,14.1]]]}}`)
+	require.NoError(t, err)
+	area, _ = AreaSquareMeters(g)
+	assert.InDelta(t, 120, area, 2)
+}
+
+func TestNormalizeRejectsInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"POINT (1 2)",
+		"POLYGON ((0 0, 1 1))",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0)",
+		`{"type":"LineString","coordinates":[[0,0],[1,1]]}`,
+	} {
+		_, err := Normalize(in)
+		assert.Error(t, err, in)
+	}
+}
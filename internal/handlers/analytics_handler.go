@@ -45,15 +45,18 @@ func (h *AnalyticsHandler) Overview(c *gin.Context) {
 }
 
 // @Summary Get Lot Distribution
-// @Description Returns availability statistics for lots
+// @Description Returns availability statistics for lots, optionally per phase or block
 // @Tags Analytics
 // @Produce json
 // @Param project_id query int false "Project ID"
+// @Param phase_id query int false "Phase ID"
+// @Param block_id query int false "Block ID"
+// @Param group_by query string false "phase or block"
 // @Security BearerAuth
 // @Router /analytics/distribution [get]
 func (h *AnalyticsHandler) Distribution(c *gin.Context) {
 	filters := h.parseFilters(c)
-	dist, err := h.analyticsSvc.GetDistribution(c.Request.Context(), filters.ProjectID)
+	dist, err := h.analyticsSvc.GetDistribution(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -115,7 +118,7 @@ func (h *AnalyticsHandler) Export(c *gin.Context) {
 		return
 	}
 
-	dist, err := h.analyticsSvc.GetDistribution(c.Request.Context(), filters.ProjectID)
+	dist, err := h.analyticsSvc.GetDistribution(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get distribution data"})
 		return
@@ -154,6 +157,22 @@ func (h *AnalyticsHandler) parseFilters(c *gin.Context) models.AnalyticsFilters
 		filters.ProjectID = &uintPid
 	}
 
+	if phaseStr := c.Query("phase_id"); phaseStr != "" {
+		if id, err := strconv.ParseUint(phaseStr, 10, 64); err == nil {
+			phaseID := uint(id)
+			filters.PhaseID = &phaseID
+		}
+	}
+	if blockStr := c.Query("block_id"); blockStr != "" {
+		if id, err := strconv.ParseUint(blockStr, 10, 64); err == nil {
+			blockID := uint(id)
+			filters.BlockID = &blockID
+		}
+	}
+	if groupBy := c.Query("group_by"); groupBy == "phase" || groupBy == "block" {
+		filters.GroupBy = groupBy
+	}
+
 	if startStr := c.Query("start_date"); startStr != "" {
 		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
 			filters.StartDate = &t
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ApprovalChainHandler struct {
+	approvalService *services.ApprovalChainService
+}
+
+func NewApprovalChainHandler(approvalService *services.ApprovalChainService) *ApprovalChainHandler {
+	return &ApprovalChainHandler{approvalService: approvalService}
+}
+
+// @Summary List Approval Chains
+// @Description Get the contract approval chains with their steps
+// @Tags Approval Chains
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /approval_chains [get]
+func (h *ApprovalChainHandler) Index(c *gin.Context) {
+	chains, err := h.approvalService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approval_chains": chains})
+}
+
+// @Summary Get Approval Chain
+// @Description Get an approval chain with its steps
+// @Tags Approval Chains
+// @Produce json
+// @Param chain_id path int true "Approval chain ID"
+// @Success 200 {object} models.ApprovalChain
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /approval_chains/{chain_id} [get]
+func (h *ApprovalChainHandler) Show(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("chain_id"), 10, 32)
+	chain, err := h.approvalService.FindByID(c.Request.Context(), uint(id))
+	if err != nil {
+		respondApprovalChainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approval_chain": chain})
+}
+
+// @Summary Create Approval Chain
+// @Description Create a contract approval chain (Admin). It applies to contracts of project_id (0 or omitted = all projects) from min_amount up; the most specific chain wins. Each step is approved by approver_user_id or by any user with approver_role admin.
+// @Tags Approval Chains
+// @Accept json
+// @Produce json
+// @Param request body services.ApprovalChainRequest true "Approval chain"
+// @Success 201 {object} models.ApprovalChain
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /approval_chains [post]
+func (h *ApprovalChainHandler) Create(c *gin.Context) {
+	var req services.ApprovalChainRequest
+	if err := BindNestedOrFlat(c, "approval_chain", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	chain, err := h.approvalService.Create(c.Request.Context(), req, middleware.GetUserID(c))
+	if err != nil {
+		respondApprovalChainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"approval_chain": chain})
+}
+
+// @Summary Update Approval Chain
+// @Description Update an approval chain (Admin). Steps are replaced only when sent, and not while contracts are going through the chain.
+// @Tags Approval Chains
+// @Accept json
+// @Produce json
+// @Param chain_id path int true "Approval chain ID"
+// @Param request body services.ApprovalChainRequest true "Approval chain"
+// @Success 200 {object} models.ApprovalChain
+// @Failure 404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /approval_chains/{chain_id} [put]
+func (h *ApprovalChainHandler) Update(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("chain_id"), 10, 32)
+	var req services.ApprovalChainRequest
+	if err := BindNestedOrFlat(c, "approval_chain", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	chain, err := h.approvalService.Update(c.Request.Context(), uint(id), req, middleware.GetUserID(c))
+	if err != nil {
+		respondApprovalChainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approval_chain": chain})
+}
+
+// @Summary Delete Approval Chain
+// @Description Delete an approval chain (Admin). Chains already assigned to contracts are deactivated instead.
+// @Tags Approval Chains
+// @Produce json
+// @Param chain_id path int true "Approval chain ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /approval_chains/{chain_id} [delete]
+func (h *ApprovalChainHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("chain_id"), 10, 32)
+	if err := h.approvalService.Delete(c.Request.Context(), uint(id), middleware.GetUserID(c)); err != nil {
+		respondApprovalChainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cadena de aprobación eliminada"})
+}
+
+// @Summary Pending Approvals
+// @Description Get the submitted contracts waiting on the current user's approval, oldest submission first
+// @Tags Approval Chains
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /contract_approvals/pending [get]
+func (h *ApprovalChainHandler) Pending(c *gin.Context) {
+	contracts, err := h.approvalService.PendingFor(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		respondApprovalChainError(c, err)
+		return
+	}
+	responses := make([]models.ContractResponse, len(contracts))
+	for i := range contracts {
+		responses[i] = contracts[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"contracts": responses})
+}
+
+// @Summary Contract Approvals
+// @Description Get the approval chain decisions taken on a contract (step, approver, decision, comment and date)
+// @Tags Approval Chains
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/approvals [get]
+func (h *ApprovalChainHandler) ContractApprovals(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	approvals, err := h.approvalService.Approvals(c.Request.Context(), uint(contractID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.ContractApprovalResponse, len(approvals))
+	for i := range approvals {
+		responses[i] = approvals[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"approvals": responses})
+}
+
+func respondApprovalChainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cadena de aprobación no encontrada"})
+	case errors.Is(err, services.ErrApprovalChainInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidApprovalChain):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ContractDocumentHandler struct {
+	documentService *services.ContractDocumentService
+}
+
+func NewContractDocumentHandler(documentService *services.ContractDocumentService) *ContractDocumentHandler {
+	return &ContractDocumentHandler{documentService: documentService}
+}
+
+// @Summary List Contract Documents
+// @Description Get the current version of each document attached to a contract (Admin, Seller or the buyer)
+// @Tags Contract Documents
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/documents [get]
+func (h *ContractDocumentHandler) Index(c *gin.Context) {
+	documents, err := h.documentService.List(c.Request.Context(), contractIDParam(c), documentActor(c))
+	if err != nil {
+		respondContractDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"documents": documentResponses(documents)})
+}
+
+// @Summary Upload Contract Document
+// @Description Attach a document (PDF, JPG or PNG, up to 10 MB) to a contract. Types: id_copy, proof_of_income, signed_promise, deed, bank_preapproval, other. Buyers may only upload id_copy, proof_of_income, bank_preapproval and other.
+// @Tags Contract Documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param document_type formData string true "Document type"
+// @Param file formData file true "Document file"
+// @Success 201 {object} models.ContractDocumentResponse
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/documents [post]
+func (h *ContractDocumentHandler) Upload(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo es requerido"})
+		return
+	}
+	document, err := h.documentService.Upload(c.Request.Context(), contractIDParam(c), c.PostForm("document_type"), header, documentActor(c))
+	if err != nil {
+		respondContractDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"document": document.ToResponse()})
+}
+
+// @Summary Replace Contract Document
+// @Description Upload a new version of a document; the previous version is kept as superseded
+// @Tags Contract Documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param document_id path int true "Document ID"
+// @Param file formData file true "Document file"
+// @Success 200 {object} models.ContractDocumentResponse
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/documents/{document_id} [put]
+func (h *ContractDocumentHandler) Replace(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo es requerido"})
+		return
+	}
+	document, err := h.documentService.Replace(c.Request.Context(), contractIDParam(c), documentIDParam(c), header, documentActor(c))
+	if err != nil {
+		respondContractDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"document": document.ToResponse()})
+}
+
+// @Summary Contract Document Versions
+// @Description Get every version of a document, oldest first
+// @Tags Contract Documents
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param document_id path int true "Document ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/documents/{document_id}/versions [get]
+func (h *ContractDocumentHandler) Versions(c *gin.Context) {
+	documents, err := h.documentService.Versions(c.Request.Context(), contractIDParam(c), documentIDParam(c), documentActor(c))
+	if err != nil {
+		respondContractDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": documentResponses(documents)})
+}
+
+// @Summary Download Contract Document
+// @Description Download the file of a document version; the X-Checksum-SHA256 header carries its checksum
+// @Tags Contract Documents
+// @Produce application/octet-stream
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param document_id path int true "Document ID"
+// @Success 200 {file} file "document"
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/documents/{document_id}/download [get]
+func (h *ContractDocumentHandler) Download(c *gin.Context) {
+	document, fullPath, err := h.documentService.Download(c.Request.Context(), contractIDParam(c), documentIDParam(c), documentActor(c))
+	if err != nil {
+		respondContractDocumentError(c, err)
+		return
+	}
+	c.Header("X-Checksum-SHA256", document.SHA256)
+	c.FileAttachment(fullPath, document.FileName)
+}
+
+// @Summary Delete Contract Document
+// @Description Soft-delete a document with all its versions (Admin). The files are kept.
+// @Tags Contract Documents
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param document_id path int true "Document ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/documents/{document_id} [delete]
+func (h *ContractDocumentHandler) Delete(c *gin.Context) {
+	if err := h.documentService.Delete(c.Request.Context(), contractIDParam(c), documentIDParam(c), documentActor(c)); err != nil {
+		respondContractDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Documento eliminado"})
+}
+
+func contractIDParam(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	return uint(id)
+}
+
+func documentIDParam(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.Param("document_id"), 10, 32)
+	return uint(id)
+}
+
+func documentActor(c *gin.Context) services.DocumentActor {
+	return services.DocumentActor{UserID: middleware.GetUserID(c), Role: middleware.GetUserRole(c)}
+}
+
+func documentResponses(documents []models.ContractDocument) []models.ContractDocumentResponse {
+	responses := make([]models.ContractDocumentResponse, len(documents))
+	for i := range documents {
+		responses[i] = documents[i].ToResponse()
+	}
+	return responses
+}
+
+func respondContractDocumentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Documento no encontrado"})
+	case errors.Is(err, services.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para este documento"})
+	case errors.Is(err, services.ErrInvalidDocument):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -3,7 +3,10 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/mail"
 	"strconv"
@@ -15,16 +18,16 @@ import (
 	"github.com/sjperalta/fintera-api/internal/models"
 	"github.com/sjperalta/fintera-api/internal/repository"
 	"github.com/sjperalta/fintera-api/internal/services"
-	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
 )
 
 type ContractHandler struct {
 	contractService *services.ContractService
-	storage         *storage.LocalStorage
+	documentService *services.ContractDocumentService
 }
 
-func NewContractHandler(contractService *services.ContractService, storage *storage.LocalStorage) *ContractHandler {
-	return &ContractHandler{contractService: contractService, storage: storage}
+func NewContractHandler(contractService *services.ContractService, documentService *services.ContractDocumentService) *ContractHandler {
+	return &ContractHandler{contractService: contractService, documentService: documentService}
 }
 
 // @Summary List Contracts
@@ -97,7 +100,7 @@ func (h *ContractHandler) GetStats(c *gin.Context) {
 }
 
 // @Summary Get Contract
-// @Description Get a contract by ID
+// @Description Get a contract by ID, with the completion status of its KYC checklist
 // @Tags Contracts
 // @Accept json
 // @Produce json
@@ -115,7 +118,11 @@ func (h *ContractHandler) Show(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Contrato no encontrado"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"contract": contract.ToResponse()})
+	resp := contract.ToResponse()
+	if resp.KYC, err = h.contractService.KYCChecklist(c.Request.Context(), contract); err != nil {
+		logger.Error(fmt.Sprintf("[ContractHandler] Failed to build KYC checklist of contract %d: %v", contract.ID, err))
+	}
+	c.JSON(http.StatusOK, gin.H{"contract": resp})
 }
 
 // @Summary Create Contract
@@ -127,7 +134,8 @@ func (h *ContractHandler) Show(c *gin.Context) {
 // @Param lot_id path int true "Lot ID"
 // @Param contract formData string true "Contract Data (JSON)"
 // @Param user formData string false "User Data (JSON)"
-// @Param documents formData file false "Documents"
+// @Param parties formData string false "Co-buyers, guarantors and legal representatives: parties[i][role|user_id|full_name|identity|phone|email|ownership_percentage]"
+// @Param documents formData file false "Documents: documents[i] with document_types[i] (id_copy, proof_of_income, bank_preapproval, ...), or documents[<type>]; untyped files are stored as other"
 // @Success 201 {object} map[string]string
 // @Security BearerAuth
 // @Router /projects/{project_id}/lots/{lot_id}/contracts [post]
@@ -173,6 +181,18 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		maxPaymentDate = &parsed
 	}
 
+	parties, err := parsePartiesForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Refuse lots that cannot be sold before creating users or storing documents
+	if err := h.contractService.CheckLotOpenForSale(c.Request.Context(), uint(lotID), &creatorID); err != nil {
+		respondLotHoldError(c, err)
+		return
+	}
+
 	// 4. Handle User (Find or Create)
 	var applicantID uint
 	if applicantUserIDStr != "" {
@@ -248,37 +268,20 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		}
 	}
 
-	// 5. Handle Document Uploads
-	var documentPaths []string
+	// 5. Collect Document Uploads
+	// The frontend sends: formData.append(`documents[${index}]`, doc) with its type in
+	// document_types[${index}], or the type as key (documents[id_copy]); they are attached as
+	// contract documents once the contract exists so they count for the KYC checklist
+	var documentFiles []*multipart.FileHeader
+	documentTypes := make(map[*multipart.FileHeader]string)
 	form, _ := c.MultipartForm()
-	files := form.File
-
-	// Iterate looking for documents[0], documents[1], etc.
-	// Since we don't know the exact keys or if they are array style "documents[]", generic iteration is harder with the map structure
-	// But the frontend sends: formData.append(`documents[${index}]`, doc);
-
-	// We can iterate the map, but order might not be preserved (not critical for paths list)
-	// We can iterate the map, but order might not be preserved (not critical for paths list)
-	for key, fileHeaders := range files {
-		// Check if key starts with "documents"
-		if len(key) >= 9 && key[:9] == "documents" {
+	for key, fileHeaders := range form.File {
+		if strings.HasPrefix(key, "documents") {
+			docType := createFormDocumentType(c, key)
 			for _, fileHeader := range fileHeaders {
-				file, err := fileHeader.Open()
-				if err != nil {
-					continue
-				}
-				defer file.Close()
-
-				if !storage.IsValidContentType(fileHeader.Header.Get("Content-Type")) {
-					continue
-				}
-
-				path, err := h.storage.Upload(file, fileHeader, "contracts")
-				if err != nil {
-					continue
-				}
-				documentPaths = append(documentPaths, path)
+				documentTypes[fileHeader] = docType
 			}
+			documentFiles = append(documentFiles, fileHeaders...)
 		}
 	}
 
@@ -295,17 +298,81 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		Note:            &note,
 		Status:          models.ContractStatusPending,
 		Currency:        "HNL",
+		Parties:         parties,
 	}
 
 	// 7. Call Service
 	if err := h.contractService.Create(c.Request.Context(), contract); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, services.ErrInvalidParty) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		respondLotHoldError(c, err)
 		return
 	}
 
+	// 8. Attach the uploaded documents (best-effort: the contract is already created)
+	actor := services.DocumentActor{UserID: creatorID, Role: middleware.GetUserRole(c)}
+	for _, fileHeader := range documentFiles {
+		if _, err := h.documentService.Upload(c.Request.Context(), contract.ID, documentTypes[fileHeader], fileHeader, actor); err != nil {
+			logger.Error(fmt.Sprintf("[ContractHandler] Failed to attach document %s to contract %d: %v", fileHeader.Filename, contract.ID, err))
+		}
+	}
+
 	c.JSON(http.StatusCreated, gin.H{"message": "Solicitud de contrato creada exitosamente", "contract_id": contract.ID})
 }
 
+// createFormDocumentType returns the document type of a documents[...] upload of the contract
+// creation form: the key itself when it names a type, else document_types[...], else other
+func createFormDocumentType(c *gin.Context, key string) string {
+	index := strings.TrimSuffix(strings.TrimPrefix(key, "documents["), "]")
+	candidates := []string{index, c.Request.FormValue(fmt.Sprintf("document_types[%s]", index))}
+	for _, candidate := range candidates {
+		if docType := strings.ToLower(strings.TrimSpace(candidate)); models.IsValidDocumentType(docType) {
+			return docType
+		}
+	}
+	return models.DocumentTypeOther
+}
+
+// parsePartiesForm reads the co-buyers, guarantors and legal representatives sent as
+// parties[0][role], parties[0][full_name], ... in the contract creation form
+func parsePartiesForm(c *gin.Context) ([]models.ContractParty, error) {
+	var parties []models.ContractParty
+	for i := 0; ; i++ {
+		field := func(name string) string {
+			return strings.TrimSpace(c.Request.FormValue(fmt.Sprintf("parties[%d][%s]", i, name)))
+		}
+		role, userIDStr, fullName := field("role"), field("user_id"), field("full_name")
+		if role == "" && userIDStr == "" && fullName == "" {
+			return parties, nil
+		}
+		party := models.ContractParty{
+			Role:     strings.ToLower(role),
+			FullName: fullName,
+			Identity: field("identity"),
+			Phone:    field("phone"),
+			Email:    field("email"),
+		}
+		if userIDStr != "" {
+			id, err := strconv.ParseUint(userIDStr, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("user_id inválido en el participante %d", i+1)
+			}
+			userID := uint(id)
+			party.UserID = &userID
+		}
+		if pct := field("ownership_percentage"); pct != "" {
+			value, err := strconv.ParseFloat(pct, 64)
+			if err != nil {
+				return nil, fmt.Errorf("porcentaje de propiedad inválido en el participante %d", i+1)
+			}
+			party.OwnershipPercentage = &value
+		}
+		parties = append(parties, party)
+	}
+}
+
 // UpdateContractRequest is the body for PATCH contract
 type UpdateContractRequest struct {
 	PaymentTerm    *int     `json:"payment_term"`
@@ -412,7 +479,7 @@ func (h *ContractHandler) Update(c *gin.Context) {
 		contract.Note = req.Note
 	}
 
-	if err := h.contractService.Update(c.Request.Context(), contract); err != nil {
+	if err := h.contractService.Update(c.Request.Context(), contract, middleware.GetUserID(c)); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
 	}
@@ -420,8 +487,79 @@ func (h *ContractHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"contract": contract.ToResponse(), "message": "Contrato actualizado"})
 }
 
+// @Summary Submit Contract
+// @Description Send a pending or rejected contract to approval. The approval chain for its project and amount is assigned and its first approvers are notified; without a chain a single admin approval is enough.
+// @Tags Contracts
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} models.ContractResponse
+// @Failure 404,409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/submit [post]
+func (h *ContractHandler) Submit(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	contract, err := h.contractService.Submit(c.Request.Context(), uint(id),
+		middleware.GetUserID(c), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contrato no encontrado"})
+		case errors.Is(err, services.ErrInvalidState):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contract": contract.ToResponse(), "message": "Contrato enviado a aprobación"})
+}
+
+// @Summary Decide Contract Approval Step
+// @Description Approve or reject the current step of the contract's approval chain as its approver. The contract creator and the approvers of earlier steps cannot approve (403). The last approval approves the contract; a rejection (comment required) rejects it.
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param request body services.ApprovalDecisionRequest true "Decision"
+// @Success 200 {object} models.ContractResponse
+// @Failure 403,404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/approvals [post]
+func (h *ContractHandler) DecideApproval(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	var req services.ApprovalDecisionRequest
+	if err := BindNestedOrFlat(c, "approval", &req); err != nil || req.Decision == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decision es requerido (approved o rejected)"})
+		return
+	}
+
+	contract, err := h.contractService.DecideApproval(c.Request.Context(), uint(id), req,
+		middleware.GetUserID(c), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		if respondKYCIncomplete(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contrato no encontrado"})
+		case errors.Is(err, services.ErrApprovalNotApprover), errors.Is(err, services.ErrApprovalSameApprover):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidState):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contract": contract.ToResponse(), "message": "Decisión registrada"})
+}
+
 // @Summary Approve Contract
-// @Description Approve a pending contract (Admin)
+// @Description Approve a pending contract (Admin). Contracts under an approval chain are approved by the chain's last step instead. Refused with 409 and the missing items ({"error", "missing"}) while the KYC checklist is incomplete, unless overridden.
 // @Tags Contracts
 // @Accept json
 // @Produce json
@@ -429,18 +567,38 @@ func (h *ContractHandler) Update(c *gin.Context) {
 // @Param project_id path int true "Project ID"
 // @Param lot_id path int true "Lot ID"
 // @Success 200 {object} models.ContractResponse
+// @Failure 409,422 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/approve [post]
 func (h *ContractHandler) Approve(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
-	contract, err := h.contractService.Approve(c.Request.Context(), uint(id))
+	actorID := middleware.GetUserID(c)
+	contract, err := h.contractService.Approve(c.Request.Context(), uint(id), &actorID)
 	if err != nil {
+		if respondKYCIncomplete(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrApprovalChainPending) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"contract": contract.ToResponse(), "message": "Contrato aprobado"})
 }
 
+// respondKYCIncomplete answers 409 with the missing checklist items when the approval was refused
+// for an incomplete KYC checklist; false for any other error
+func respondKYCIncomplete(c *gin.Context, err error) bool {
+	var incomplete *services.KYCIncompleteError
+	if !errors.As(err, &incomplete) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "missing": incomplete.Missing})
+	return true
+}
+
 type RejectContractRequest struct {
 	Reason string `json:"reason"`
 }
@@ -462,7 +620,8 @@ func (h *ContractHandler) Reject(c *gin.Context) {
 	var req RejectContractRequest
 	c.ShouldBindJSON(&req)
 
-	contract, err := h.contractService.Reject(c.Request.Context(), uint(id), req.Reason)
+	actorID := middleware.GetUserID(c)
+	contract, err := h.contractService.Reject(c.Request.Context(), uint(id), req.Reason, &actorID)
 	if err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
@@ -491,7 +650,8 @@ func (h *ContractHandler) Cancel(c *gin.Context) {
 	var req CancelContractRequest
 	c.ShouldBindJSON(&req)
 
-	contract, err := h.contractService.Cancel(c.Request.Context(), uint(id), req.Note)
+	actorID := middleware.GetUserID(c)
+	contract, err := h.contractService.Cancel(c.Request.Context(), uint(id), req.Note, &actorID)
 	if err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
@@ -563,6 +723,214 @@ func (h *ContractHandler) CapitalRepayment(c *gin.Context) {
 	})
 }
 
+// @Summary Cede Contract
+// @Description Transfer an approved contract to a new applicant (cesión de derechos). Payments and ledger stay with the contract; pending payments, reminders and notifications go to the new buyer. transfer_fee is optional and is charged as a fee payment. The previous buyer's co-buyers, guarantors and representatives are removed unless listed in keep_party_ids.
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param request body services.CessionRequest true "Cession"
+// @Success 201 {object} models.ContractCessionResponse
+// @Failure 404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/cession [post]
+func (h *ContractHandler) Cede(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	var req services.CessionRequest
+	if err := BindNestedOrFlat(c, "cession", &req); err != nil || req.NewApplicantUserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new_applicant_user_id es requerido"})
+		return
+	}
+
+	cession, err := h.contractService.Cede(c.Request.Context(), uint(contractID), req,
+		middleware.GetUserID(c), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contrato o nuevo comprador no encontrado"})
+		case errors.Is(err, services.ErrInvalidState):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Contrato cedido exitosamente", "cession": cession.ToResponse()})
+}
+
+// @Summary Contract Cessions
+// @Description Get the cession history of a contract (previous and new buyers, paid amount and balance at each cession)
+// @Tags Contracts
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/cessions [get]
+func (h *ContractHandler) Cessions(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	cessions, err := h.contractService.ListCessions(c.Request.Context(), uint(contractID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.ContractCessionResponse, len(cessions))
+	for i := range cessions {
+		responses[i] = cessions[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"cessions": responses})
+}
+
+// @Summary Change Contract Lot
+// @Description Move an approved contract to another available lot of the same project. The price difference is posted to the ledger, the pending installments are resized to the new balance and the old lot is released. The addendum is at /reports/contract_lot_change_pdf.
+// @Tags Contracts
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param request body services.LotChangeRequest true "Lot change"
+// @Success 201 {object} models.ContractLotChangeResponse
+// @Failure 404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/change_lot [post]
+func (h *ContractHandler) ChangeLot(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	var req services.LotChangeRequest
+	if err := BindNestedOrFlat(c, "lot_change", &req); err != nil || req.NewLotID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new_lot_id es requerido"})
+		return
+	}
+
+	change, err := h.contractService.ChangeLot(c.Request.Context(), uint(contractID), req,
+		middleware.GetUserID(c), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contrato o lote no encontrado"})
+		case errors.Is(err, services.ErrInvalidState), errors.Is(err, services.ErrLotChangeUnavailable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Lote del contrato cambiado exitosamente", "lot_change": change.ToResponse()})
+}
+
+// @Summary Contract Lot Changes
+// @Description Get the lot change history of a contract (old and new lot, amounts and price difference)
+// @Tags Contracts
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/lot_changes [get]
+func (h *ContractHandler) LotChanges(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	changes, err := h.contractService.ListLotChanges(c.Request.Context(), uint(contractID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.ContractLotChangeResponse, len(changes))
+	for i := range changes {
+		responses[i] = changes[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"lot_changes": responses})
+}
+
+// @Summary Contract Status History
+// @Description Get the status transitions of a contract (from, to, event, actor, reason and date), oldest first
+// @Tags Contracts
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/status_history [get]
+func (h *ContractHandler) StatusHistory(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	transitions, err := h.contractService.StatusHistory(c.Request.Context(), uint(contractID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.ContractStatusTransitionResponse, len(transitions))
+	for i := range transitions {
+		responses[i] = transitions[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"status_history": responses})
+}
+
+// @Summary Contract Terms Versions
+// @Description Get the versions of the financial terms of a contract, taken on creation and on every update or amendment (lot change, cession)
+// @Tags Contracts
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/versions [get]
+func (h *ContractHandler) TermsVersions(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	versions, err := h.contractService.TermsVersions(c.Request.Context(), uint(contractID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.ContractTermsVersionResponse, len(versions))
+	for i := range versions {
+		responses[i] = versions[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": responses})
+}
+
+// @Summary Contract Terms Diff
+// @Description Get the financial terms that changed between two versions of a contract. Without to, the latest version; without from, the version before to.
+// @Tags Contracts
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param from query int false "From version"
+// @Param to query int false "To version"
+// @Success 200 {object} services.TermsDiff
+// @Failure 400,404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/versions/diff [get]
+func (h *ContractHandler) TermsDiff(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	var versions [2]int
+	for i, param := range []string{"from", "to"} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Versión inválida: " + param})
+				return
+			}
+			versions[i] = n
+		}
+	}
+	diff, err := h.contractService.DiffTermsVersions(c.Request.Context(), uint(contractID), versions[0], versions[1])
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Versión del contrato no encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"diff": diff})
+}
+
 // @Summary Delete Rejected Contract
 // @Description Delete a rejected contract and release the lot so it can be reserved again. Only allowed when contract status is rejected.
 // @Tags Contracts
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFormDocumentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("document_types[0]", "proof_of_income"))
+	require.NoError(t, form.WriteField("document_types[1]", "passport"))
+	require.NoError(t, form.Close())
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", &body)
+	c.Request.Header.Set("Content-Type", form.FormDataContentType())
+	require.NoError(t, c.Request.ParseMultipartForm(1<<20))
+
+	assert.Equal(t, models.DocumentTypeProofOfIncome, createFormDocumentType(c, "documents[0]"))
+	assert.Equal(t, models.DocumentTypeOther, createFormDocumentType(c, "documents[1]")) // unknown type
+	assert.Equal(t, models.DocumentTypeOther, createFormDocumentType(c, "documents[2]"))
+	assert.Equal(t, models.DocumentTypeIDCopy, createFormDocumentType(c, "documents[id_copy]"))
+}
+
+func TestRespondKYCIncomplete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	assert.False(t, respondKYCIncomplete(c, services.ErrInvalidState))
+	err := &services.KYCIncompleteError{Missing: []models.KYCChecklistItem{{Item: models.KYCItemRTN, Label: "RTN"}}}
+	require.True(t, respondKYCIncomplete(c, err))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var resp struct {
+		Error   string                    `json:"error"`
+		Missing []models.KYCChecklistItem `json:"missing"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Error, "RTN")
+	require.Len(t, resp.Missing, 1)
+	assert.Equal(t, models.KYCItemRTN, resp.Missing[0].Item)
+}
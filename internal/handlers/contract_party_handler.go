@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ContractPartyHandler struct {
+	partyService *services.ContractPartyService
+}
+
+func NewContractPartyHandler(partyService *services.ContractPartyService) *ContractPartyHandler {
+	return &ContractPartyHandler{partyService: partyService}
+}
+
+// @Summary List Contract Parties
+// @Description Get the co-buyers, guarantors and legal representatives of a contract
+// @Tags Contract Parties
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/parties [get]
+func (h *ContractPartyHandler) Index(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	parties, err := h.partyService.List(c.Request.Context(), uint(contractID))
+	if err != nil {
+		respondPartyError(c, err)
+		return
+	}
+	responses := make([]models.ContractPartyResponse, len(parties))
+	for i := range parties {
+		responses[i] = parties[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"parties": responses})
+}
+
+// @Summary Add Contract Party
+// @Description Add a co-buyer, guarantor or legal representative to a contract that is not approved yet. Only co-buyers carry ownership_percentage; the applicant keeps the rest.
+// @Tags Contract Parties
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param request body services.ContractPartyRequest true "Party"
+// @Success 201 {object} models.ContractPartyResponse
+// @Failure 404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/parties [post]
+func (h *ContractPartyHandler) Create(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	var req services.ContractPartyRequest
+	if err := BindNestedOrFlat(c, "party", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	party, err := h.partyService.Add(c.Request.Context(), uint(contractID), req, middleware.GetUserID(c))
+	if err != nil {
+		respondPartyError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"party": party.ToResponse()})
+}
+
+// @Summary Update Contract Party
+// @Description Update a party of a contract that is not approved yet
+// @Tags Contract Parties
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param party_id path int true "Party ID"
+// @Param request body services.ContractPartyRequest true "Party"
+// @Success 200 {object} models.ContractPartyResponse
+// @Failure 404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/parties/{party_id} [put]
+func (h *ContractPartyHandler) Update(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	partyID, _ := strconv.ParseUint(c.Param("party_id"), 10, 32)
+	var req services.ContractPartyRequest
+	if err := BindNestedOrFlat(c, "party", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	party, err := h.partyService.Update(c.Request.Context(), uint(contractID), uint(partyID), req, middleware.GetUserID(c))
+	if err != nil {
+		respondPartyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"party": party.ToResponse()})
+}
+
+// @Summary Remove Contract Party
+// @Description Remove a party from a contract that is not approved yet; a removed co-buyer's share returns to the applicant
+// @Tags Contract Parties
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param party_id path int true "Party ID"
+// @Success 200 {object} map[string]string
+// @Failure 404,409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/parties/{party_id} [delete]
+func (h *ContractPartyHandler) Delete(c *gin.Context) {
+	contractID, _ := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	partyID, _ := strconv.ParseUint(c.Param("party_id"), 10, 32)
+	if err := h.partyService.Remove(c.Request.Context(), uint(contractID), uint(partyID), middleware.GetUserID(c)); err != nil {
+		respondPartyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Participante eliminado"})
+}
+
+func respondPartyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contrato o participante no encontrado"})
+	case errors.Is(err, services.ErrPartiesLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidParty):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ContractSignatureHandler struct {
+	signatureService *services.ContractSignatureService
+}
+
+func NewContractSignatureHandler(signatureService *services.ContractSignatureService) *ContractSignatureHandler {
+	return &ContractSignatureHandler{signatureService: signatureService}
+}
+
+// @Summary Contract Signature
+// @Description Get the latest electronic signature of the promise contract with its signers and hash chain (Admin, Seller or the buyer)
+// @Tags Contract Signature
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} models.SignatureRequestResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature [get]
+func (h *ContractSignatureHandler) Show(c *gin.Context) {
+	request, err := h.signatureService.Find(c.Request.Context(), contractIDParam(c), documentActor(c))
+	if err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"signature": request.ToResponse()})
+}
+
+// @Summary Start Contract Signature
+// @Description Generate and hash the promise contract of an approved contract and open its electronic signature by the buyer and an admin (Admin, Seller)
+// @Tags Contract Signature
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 201 {object} models.SignatureRequestResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature [post]
+func (h *ContractSignatureHandler) Start(c *gin.Context) {
+	request, err := h.signatureService.Start(c.Request.Context(), contractIDParam(c), middleware.GetUserID(c))
+	if err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"signature": request.ToResponse()})
+}
+
+// @Summary Download Unsigned Promise Contract
+// @Description Download the promise contract being signed; the X-Checksum-SHA256 header carries the hash the signatures chain from
+// @Tags Contract Signature
+// @Produce application/pdf
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {file} file "promise contract"
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature/document [get]
+func (h *ContractSignatureHandler) Document(c *gin.Context) {
+	request, fullPath, err := h.signatureService.Document(c.Request.Context(), contractIDParam(c), documentActor(c))
+	if err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.Header("X-Checksum-SHA256", request.DocumentSHA256)
+	c.FileAttachment(fullPath, fmt.Sprintf("promesa_%d.pdf", request.ContractID))
+}
+
+// @Summary Request Signature Code
+// @Description Email the current user a one-time code to confirm their signature. The buyer signs as buyer; admins sign for the company.
+// @Tags Contract Signature
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature/otp [post]
+func (h *ContractSignatureHandler) RequestOTP(c *gin.Context) {
+	if err := h.signatureService.RequestOTP(c.Request.Context(), contractIDParam(c), documentActor(c)); err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Código de firma enviado a su correo"})
+}
+
+// @Summary Sign Promise Contract
+// @Description Sign with a drawn signature (PNG as a data URL or base64, up to 1 MB) and the emailed code. Once the buyer and an admin signed, the signed PDF is stored as a signed_promise document and the contract moves to signed.
+// @Tags Contract Signature
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param request body services.SignContractRequest true "Signature"
+// @Success 200 {object} models.SignatureRequestResponse
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature/sign [post]
+func (h *ContractSignatureHandler) Sign(c *gin.Context) {
+	var req services.SignContractRequest
+	if err := BindNestedOrFlat(c, "signature", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	request, err := h.signatureService.Sign(c.Request.Context(), contractIDParam(c), req, documentActor(c), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"signature": request.ToResponse(), "message": "Firma registrada"})
+}
+
+// @Summary Verify Contract Signature
+// @Description Recompute the hash chain of the signature and re-hash its stored files
+// @Tags Contract Signature
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} services.SignatureVerification
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature/verify [get]
+func (h *ContractSignatureHandler) Verify(c *gin.Context) {
+	verification, err := h.signatureService.Verify(c.Request.Context(), contractIDParam(c), documentActor(c))
+	if err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"verification": verification})
+}
+
+// @Summary Complete Contract Signature
+// @Description Retry completing a signature every signer already signed, when generating or storing the signed promise contract failed (Admin)
+// @Tags Contract Signature
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} models.SignatureRequestResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature/complete [post]
+func (h *ContractSignatureHandler) Complete(c *gin.Context) {
+	request, err := h.signatureService.Complete(c.Request.Context(), contractIDParam(c), middleware.GetUserID(c))
+	if err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"signature": request.ToResponse(), "message": "Firma completada"})
+}
+
+// @Summary Cancel Contract Signature
+// @Description Cancel the signature in progress (Admin); signatures already made are discarded
+// @Tags Contract Signature
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/signature [delete]
+func (h *ContractSignatureHandler) Cancel(c *gin.Context) {
+	if err := h.signatureService.Cancel(c.Request.Context(), contractIDParam(c), middleware.GetUserID(c)); err != nil {
+		respondContractSignatureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Firma cancelada"})
+}
+
+func respondContractSignatureError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Firma no encontrada"})
+	case errors.Is(err, services.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para firmar este contrato"})
+	case errors.Is(err, services.ErrInvalidSignature), errors.Is(err, services.ErrInvalidOTP):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
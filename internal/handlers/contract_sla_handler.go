@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ContractSLAHandler struct {
+	slaService *services.ContractSLAService
+}
+
+func NewContractSLAHandler(slaService *services.ContractSLAService) *ContractSLAHandler {
+	return &ContractSLAHandler{slaService: slaService}
+}
+
+// @Summary List Contract SLAs
+// @Description Get the maximum hours a contract may stay in each status, per project and for all projects
+// @Tags Contract SLAs
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /contract_slas [get]
+func (h *ContractSLAHandler) Index(c *gin.Context) {
+	slas, err := h.slaService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contract_slas": slas})
+}
+
+// @Summary Set Contract SLA
+// @Description Create or update the maximum hours a contract may stay in a status (pending, submitted or rejected) before it is escalated to the admins (Admin). project_id 0 or omitted sets the default for all projects; project rules override it.
+// @Tags Contract SLAs
+// @Accept json
+// @Produce json
+// @Param request body services.ContractSLARequest true "SLA"
+// @Success 200 {object} models.ContractStatusSLA
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /contract_slas [put]
+func (h *ContractSLAHandler) Set(c *gin.Context) {
+	var req services.ContractSLARequest
+	if err := BindNestedOrFlat(c, "contract_sla", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	sla, err := h.slaService.Set(c.Request.Context(), req, middleware.GetUserID(c))
+	if err != nil {
+		respondContractSLAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contract_sla": sla})
+}
+
+// @Summary Delete Contract SLA
+// @Description Delete an SLA rule (Admin). Contracts of its project fall back to the default rule.
+// @Tags Contract SLAs
+// @Produce json
+// @Param sla_id path int true "SLA ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /contract_slas/{sla_id} [delete]
+func (h *ContractSLAHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("sla_id"), 10, 32)
+	if err := h.slaService.Delete(c.Request.Context(), uint(id), middleware.GetUserID(c)); err != nil {
+		respondContractSLAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tiempo máximo eliminado"})
+}
+
+// @Summary Contracts by Time in Status
+// @Description Get the contracts by the time spent in their current status, longest first, with their SLA and whether they are overdue. Without status, lists pending, submitted and rejected contracts.
+// @Tags Contract SLAs
+// @Produce json
+// @Param status query string false "Contract status"
+// @Param project_id query int false "Project ID"
+// @Param overdue query bool false "Only contracts over their SLA"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /contracts/time_in_status [get]
+func (h *ContractSLAHandler) TimeInStatus(c *gin.Context) {
+	query := services.TimeInStatusQuery{Status: c.Query("status")}
+	if pidStr := c.Query("project_id"); pidStr != "" {
+		pid, err := strconv.ParseUint(pidStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project_id inválido"})
+			return
+		}
+		query.ProjectID = uint(pid)
+	}
+	query.OverdueOnly, _ = strconv.ParseBool(c.Query("overdue"))
+
+	items, err := h.slaService.TimeInStatus(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contracts": items})
+}
+
+func respondContractSLAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tiempo máximo no encontrado"})
+	case errors.Is(err, services.ErrInvalidContractSLA):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type FiscalHandler struct {
+	fiscalService *services.FiscalService
+}
+
+func NewFiscalHandler(fiscalService *services.FiscalService) *FiscalHandler {
+	return &FiscalHandler{fiscalService: fiscalService}
+}
+
+// @Summary Fiscal Ranges
+// @Description List the invoice number ranges authorized by the tax authority (CAI), active ones first (Admin)
+// @Tags Fiscal
+// @Produce json
+// @Success 200 {array} models.FiscalRangeResponse
+// @Security BearerAuth
+// @Router /fiscal/ranges [get]
+func (h *FiscalHandler) Ranges(c *gin.Context) {
+	ranges, err := h.fiscalService.ListRanges(c.Request.Context())
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+	responses := make([]models.FiscalRangeResponse, len(ranges))
+	for i := range ranges {
+		responses[i] = ranges[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"ranges": responses})
+}
+
+// @Summary Create Fiscal Range
+// @Description Register an invoice number range authorized for an establishment and point of sale, with its CAI and emission deadline (Admin)
+// @Tags Fiscal
+// @Accept json
+// @Produce json
+// @Param request body services.CreateFiscalRangeRequest true "Authorized range"
+// @Success 201 {object} models.FiscalRangeResponse
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /fiscal/ranges [post]
+func (h *FiscalHandler) CreateRange(c *gin.Context) {
+	var req services.CreateFiscalRangeRequest
+	if err := BindNestedOrFlat(c, "fiscal_range", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	fiscalRange, err := h.fiscalService.CreateRange(c.Request.Context(), req, middleware.GetUserID(c))
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"range": fiscalRange.ToResponse()})
+}
+
+// @Summary Deactivate Fiscal Range
+// @Description Stop issuing invoices from a range (Admin)
+// @Tags Fiscal
+// @Produce json
+// @Param range_id path int true "Range ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /fiscal/ranges/{range_id} [delete]
+func (h *FiscalHandler) DeactivateRange(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("range_id"), 10, 32)
+	if err := h.fiscalService.DeactivateRange(c.Request.Context(), uint(id), middleware.GetUserID(c)); err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rango desactivado"})
+}
+
+// @Summary Invoice Payment
+// @Description Issue the fiscal invoice for the interest and fees of an approved payment, e.g. after issuance was refused for lack of an authorized range (Admin)
+// @Tags Fiscal
+// @Produce json
+// @Param payment_id path int true "Payment ID"
+// @Success 201 {object} models.FiscalInvoiceResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{payment_id}/invoice [post]
+func (h *FiscalHandler) InvoicePayment(c *gin.Context) {
+	invoice, err := h.fiscalService.InvoicePaymentByID(c.Request.Context(), paymentIDParam(c), middleware.GetUserID(c))
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"invoice": invoice.ToResponse(), "message": "Factura emitida"})
+}
+
+// @Summary Contract Invoices
+// @Description List the fiscal invoices of a contract, newest first (Admin, Seller or the buyer)
+// @Tags Fiscal
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {array} models.FiscalInvoiceResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/invoices [get]
+func (h *FiscalHandler) Invoices(c *gin.Context) {
+	invoices, err := h.fiscalService.ListInvoices(c.Request.Context(), contractIDParam(c), documentActor(c))
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+	responses := make([]models.FiscalInvoiceResponse, len(invoices))
+	for i := range invoices {
+		responses[i] = invoices[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"invoices": responses})
+}
+
+// @Summary Download Invoice
+// @Description Download the PDF of a fiscal invoice; the X-Checksum-SHA256 header carries the hash of the issued file
+// @Tags Fiscal
+// @Produce application/pdf
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param invoice_id path int true "Invoice ID"
+// @Success 200 {file} file "invoice"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/invoices/{invoice_id}/download [get]
+func (h *FiscalHandler) DownloadInvoice(c *gin.Context) {
+	invoiceID, _ := strconv.ParseUint(c.Param("invoice_id"), 10, 32)
+	invoice, pdf, err := h.fiscalService.Download(c.Request.Context(), contractIDParam(c), uint(invoiceID), documentActor(c))
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+	if invoice.SHA256 != nil {
+		c.Header("X-Checksum-SHA256", *invoice.SHA256)
+	}
+	c.Header("Content-Disposition", "attachment; filename=factura_"+invoice.Number+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func respondFiscalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Registro fiscal no encontrado"})
+	case errors.Is(err, services.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver estas facturas"})
+	case errors.Is(err, services.ErrInvalidFiscalRange), errors.Is(err, services.ErrNothingToInvoice):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicate), errors.Is(err, services.ErrInvalidState), errors.Is(err, services.ErrFiscalRangeUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -7,34 +7,64 @@ import (
 
 // Handlers holds all handler instances
 type Handlers struct {
-	Health       *HealthHandler
-	Auth         *AuthHandler
-	User         *UserHandler
-	Project      *ProjectHandler
-	Lot          *LotHandler
-	Contract     *ContractHandler
-	Payment      *PaymentHandler
-	Notification *NotificationHandler
-	Report       *ReportHandler
-	Audit        *AuditHandler
-	Analytics    *AnalyticsHandler
-	Job          *JobHandler
+	Health             *HealthHandler
+	Auth               *AuthHandler
+	User               *UserHandler
+	Project            *ProjectHandler
+	Lot                *LotHandler
+	Contract           *ContractHandler
+	Payment            *PaymentHandler
+	Notification       *NotificationHandler
+	Report             *ReportHandler
+	Audit              *AuditHandler
+	Analytics          *AnalyticsHandler
+	Job                *JobHandler
+	Promotion          *PromotionHandler
+	PriceList          *PriceListHandler
+	LotHold            *LotHoldHandler
+	Waitlist           *WaitlistHandler
+	Section            *ProjectSectionHandler
+	ContractParty      *ContractPartyHandler
+	ApprovalChain      *ApprovalChainHandler
+	ContractSLA        *ContractSLAHandler
+	Document           *ContractDocumentHandler
+	KYC                *KYCHandler
+	Signature          *ContractSignatureHandler
+	Receipt            *PaymentReceiptHandler
+	Fiscal             *FiscalHandler
+	ReportJob          *ReportJobHandler
+	ReportSubscription *ReportSubscriptionHandler
 }
 
 // NewHandlers creates all handler instances
 func NewHandlers(svcs *services.Services, storage *storage.LocalStorage) *Handlers {
 	return &Handlers{
-		Health:       NewHealthHandler(),
-		Auth:         NewAuthHandler(svcs.Auth),
-		User:         NewUserHandler(svcs.User, svcs.Payment),
-		Project:      NewProjectHandler(svcs.Project),
-		Lot:          NewLotHandler(svcs.Lot),
-		Contract:     NewContractHandler(svcs.Contract, storage),
-		Payment:      NewPaymentHandler(svcs.Payment, storage),
-		Notification: NewNotificationHandler(svcs.Notification),
-		Report:       NewReportHandler(svcs.Report),
-		Audit:        NewAuditHandler(svcs.Audit), // Pass AuditService
-		Analytics:    NewAnalyticsHandler(svcs.Analytics, svcs.Export),
-		Job:          NewJobHandler(svcs.Job),
+		Health:             NewHealthHandler(),
+		Auth:               NewAuthHandler(svcs.Auth),
+		User:               NewUserHandler(svcs.User, svcs.Payment),
+		Project:            NewProjectHandler(svcs.Project, svcs.Import),
+		Lot:                NewLotHandler(svcs.Lot, svcs.Import),
+		Contract:           NewContractHandler(svcs.Contract, svcs.ContractDocument),
+		Payment:            NewPaymentHandler(svcs.Payment, storage),
+		Notification:       NewNotificationHandler(svcs.Notification),
+		Report:             NewReportHandler(svcs.Report),
+		Audit:              NewAuditHandler(svcs.Audit), // Pass AuditService
+		Analytics:          NewAnalyticsHandler(svcs.Analytics, svcs.Export),
+		Job:                NewJobHandler(svcs.Job),
+		Promotion:          NewPromotionHandler(svcs.Promotion),
+		PriceList:          NewPriceListHandler(svcs.PriceList),
+		LotHold:            NewLotHoldHandler(svcs.LotHold),
+		Waitlist:           NewWaitlistHandler(svcs.Waitlist),
+		Section:            NewProjectSectionHandler(svcs.Section),
+		ContractParty:      NewContractPartyHandler(svcs.ContractParty),
+		ApprovalChain:      NewApprovalChainHandler(svcs.ApprovalChain),
+		ContractSLA:        NewContractSLAHandler(svcs.ContractSLA),
+		Document:           NewContractDocumentHandler(svcs.ContractDocument),
+		KYC:                NewKYCHandler(svcs.KYC),
+		Signature:          NewContractSignatureHandler(svcs.ContractSignature),
+		Receipt:            NewPaymentReceiptHandler(svcs.Receipt),
+		Fiscal:             NewFiscalHandler(svcs.Fiscal),
+		ReportJob:          NewReportJobHandler(svcs.ReportJob),
+		ReportSubscription: NewReportSubscriptionHandler(svcs.ReportSubscription),
 	}
 }
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type KYCHandler struct {
+	kycService *services.KYCService
+}
+
+func NewKYCHandler(kycService *services.KYCService) *KYCHandler {
+	return &KYCHandler{kycService: kycService}
+}
+
+// @Summary List KYC Requirements
+// @Description Get the KYC checklist items required before contract approval, per project and financing type
+// @Tags KYC
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /kyc_requirements [get]
+func (h *KYCHandler) Index(c *gin.Context) {
+	requirements, err := h.kycService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kyc_requirements": requirements})
+}
+
+// @Summary Create KYC Requirement
+// @Description Require an item before approving contracts (Admin). Items: identity_scan, identity_number, rtn, address, proof_of_income, bank_preapproval. project_id 0 or omitted applies to all projects; financing_type (direct, bank, cash) omitted applies to all financing types.
+// @Tags KYC
+// @Accept json
+// @Produce json
+// @Param request body services.KYCRequirementRequest true "Requirement"
+// @Success 201 {object} models.KYCRequirement
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /kyc_requirements [post]
+func (h *KYCHandler) Create(c *gin.Context) {
+	var req services.KYCRequirementRequest
+	if err := BindNestedOrFlat(c, "kyc_requirement", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	requirement, err := h.kycService.Create(c.Request.Context(), req, middleware.GetUserID(c))
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"kyc_requirement": requirement})
+}
+
+// @Summary Delete KYC Requirement
+// @Description Remove an item from the KYC checklist (Admin)
+// @Tags KYC
+// @Produce json
+// @Param requirement_id path int true "Requirement ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /kyc_requirements/{requirement_id} [delete]
+func (h *KYCHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("requirement_id"), 10, 32)
+	if err := h.kycService.Delete(c.Request.Context(), uint(id), middleware.GetUserID(c)); err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Requisito eliminado"})
+}
+
+// @Summary Contract KYC Checklist
+// @Description Get the KYC checklist of a contract: each required item and whether it is satisfied
+// @Tags KYC
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Success 200 {object} models.KYCChecklist
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/kyc [get]
+func (h *KYCHandler) Checklist(c *gin.Context) {
+	checklist, err := h.kycService.ChecklistByContract(c.Request.Context(), contractIDParam(c))
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kyc": checklist})
+}
+
+type KYCOverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+// @Summary Override Contract KYC Checklist
+// @Description Allow the approval of a pending or submitted contract with an incomplete KYC checklist (Admin). The reason is required and audited.
+// @Tags KYC
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param contract_id path int true "Contract ID"
+// @Param request body KYCOverrideRequest true "Reason"
+// @Success 200 {object} models.KYCChecklist
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/contracts/{contract_id}/kyc/override [post]
+func (h *KYCHandler) Override(c *gin.Context) {
+	var req KYCOverrideRequest
+	if err := BindNestedOrFlat(c, "kyc", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	checklist, err := h.kycService.Override(c.Request.Context(), contractIDParam(c), req.Reason, middleware.GetUserID(c))
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kyc": checklist, "message": "Lista de verificación omitida"})
+}
+
+func respondKYCError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Registro no encontrado"})
+	case errors.Is(err, services.ErrDuplicate), errors.Is(err, services.ErrInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidKYCRequirement), errors.Is(err, services.ErrKYCOverrideReason):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
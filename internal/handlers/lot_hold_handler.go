@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type LotHoldHandler struct {
+	lotHoldService *services.LotHoldService
+}
+
+func NewLotHoldHandler(lotHoldService *services.LotHoldService) *LotHoldHandler {
+	return &LotHoldHandler{lotHoldService: lotHoldService}
+}
+
+// PlaceHoldRequest is the body for holding a lot
+type PlaceHoldRequest struct {
+	Note string `json:"note"`
+}
+
+// @Summary List Lot Holds
+// @Description List lot holds (default active). Sellers only see their own holds.
+// @Tags Lot Holds
+// @Accept json
+// @Produce json
+// @Param status query string false "active, converted, expired, released or all"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /lot_holds [get]
+func (h *LotHoldHandler) Index(c *gin.Context) {
+	status := c.DefaultQuery("status", models.LotHoldStatusActive)
+	if status == "all" {
+		status = ""
+	}
+	var sellerID *uint
+	if middleware.GetUserRole(c) != models.RoleAdmin {
+		id := middleware.GetUserID(c)
+		sellerID = &id
+	}
+
+	holds, err := h.lotHoldService.List(c.Request.Context(), sellerID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.LotHoldResponse, len(holds))
+	for i := range holds {
+		responses[i] = holds[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"lot_holds": responses})
+}
+
+// @Summary Get Lot Hold
+// @Description Get the active hold of a lot
+// @Tags Lot Holds
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Success 200 {object} models.LotHoldResponse
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/hold [get]
+func (h *LotHoldHandler) Show(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	lotID, _ := strconv.ParseUint(c.Param("lot_id"), 10, 32)
+	hold, err := h.lotHoldService.FindActive(c.Request.Context(), uint(projectID), uint(lotID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "El lote no está apartado"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lot_hold": hold.ToResponse()})
+}
+
+// @Summary Hold Lot
+// @Description Place a temporary hold on an available lot while preparing the contract. The hold lasts the project's hold_duration_minutes, expires automatically and converts into the contract when the same seller creates it.
+// @Tags Lot Holds
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param request body PlaceHoldRequest false "Hold"
+// @Success 201 {object} models.LotHoldResponse
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/hold [post]
+func (h *LotHoldHandler) Create(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	lotID, _ := strconv.ParseUint(c.Param("lot_id"), 10, 32)
+	var req PlaceHoldRequest
+	if c.Request.ContentLength > 0 {
+		if err := BindNestedOrFlat(c, "lot_hold", &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+			return
+		}
+	}
+
+	hold, err := h.lotHoldService.Place(c.Request.Context(), uint(projectID), uint(lotID), middleware.GetUserID(c), req.Note)
+	if err != nil {
+		respondLotHoldError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"lot_hold": hold.ToResponse()})
+}
+
+// @Summary Release Lot Hold
+// @Description Release the active hold of a lot (hold owner or admin)
+// @Tags Lot Holds
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Success 200 {object} models.LotHoldResponse
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/hold [delete]
+func (h *LotHoldHandler) Delete(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	lotID, _ := strconv.ParseUint(c.Param("lot_id"), 10, 32)
+	isAdmin := middleware.GetUserRole(c) == models.RoleAdmin
+	hold, err := h.lotHoldService.Release(c.Request.Context(), uint(projectID), uint(lotID), middleware.GetUserID(c), isAdmin)
+	if err != nil {
+		respondLotHoldError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lot_hold": hold.ToResponse()})
+}
+
+// respondLotHoldError maps lot hold errors to HTTP statuses
+func respondLotHoldError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lote no encontrado"})
+	case errors.Is(err, services.ErrLotHeldByOther), errors.Is(err, services.ErrLotNotHoldable), errors.Is(err, services.ErrLotHoldNotActive),
+		errors.Is(err, services.ErrLotNotOnSale), errors.Is(err, services.ErrProjectNotPublished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
 	"github.com/sjperalta/fintera-api/internal/repository"
 	"github.com/sjperalta/fintera-api/internal/services"
 	"github.com/sjperalta/fintera-api/internal/storage"
@@ -358,7 +359,7 @@ func (h *PaymentHandler) Undo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de pago inválido"})
 		return
 	}
-	if err := h.paymentService.UndoPayment(c.Request.Context(), uint(id)); err != nil {
+	if err := h.paymentService.UndoPayment(c.Request.Context(), uint(id), middleware.GetUserID(c)); err != nil {
 		if strings.Contains(err.Error(), "no encontrado") || strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Pago no encontrado"})
 			return
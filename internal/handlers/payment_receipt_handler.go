@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type PaymentReceiptHandler struct {
+	receiptService *services.ReceiptService
+}
+
+func NewPaymentReceiptHandler(receiptService *services.ReceiptService) *PaymentReceiptHandler {
+	return &PaymentReceiptHandler{receiptService: receiptService}
+}
+
+// @Summary Payment Receipts
+// @Description List the receipts issued for a payment, newest first, voided ones included (Admin, Seller or the buyer)
+// @Tags Payment Receipts
+// @Produce json
+// @Param payment_id path int true "Payment ID"
+// @Success 200 {array} models.PaymentReceiptResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{payment_id}/receipts [get]
+func (h *PaymentReceiptHandler) Index(c *gin.Context) {
+	receipts, err := h.receiptService.List(c.Request.Context(), paymentIDParam(c), documentActor(c))
+	if err != nil {
+		respondPaymentReceiptError(c, err)
+		return
+	}
+	responses := make([]models.PaymentReceiptResponse, len(receipts))
+	for i := range receipts {
+		responses[i] = receipts[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"receipts": responses})
+}
+
+// @Summary Download Payment Receipt
+// @Description Download the PDF of a receipt; the X-Checksum-SHA256 header carries the hash of the issued file
+// @Tags Payment Receipts
+// @Produce application/pdf
+// @Param payment_id path int true "Payment ID"
+// @Param receipt_id path int true "Receipt ID"
+// @Success 200 {file} file "receipt"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{payment_id}/receipts/{receipt_id}/download [get]
+func (h *PaymentReceiptHandler) Download(c *gin.Context) {
+	receiptID, _ := strconv.ParseUint(c.Param("receipt_id"), 10, 32)
+	receipt, pdf, err := h.receiptService.Download(c.Request.Context(), paymentIDParam(c), uint(receiptID), documentActor(c))
+	if err != nil {
+		respondPaymentReceiptError(c, err)
+		return
+	}
+	if receipt.SHA256 != nil {
+		c.Header("X-Checksum-SHA256", *receipt.SHA256)
+	}
+	c.Header("Content-Disposition", "attachment; filename="+receipt.DisplayNumber()+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// @Summary Reissue Payment Receipt
+// @Description Void the current receipt of a paid payment and issue it a new number (Admin)
+// @Tags Payment Receipts
+// @Accept json
+// @Produce json
+// @Param payment_id path int true "Payment ID"
+// @Param request body services.ReissueReceiptRequest false "Reason"
+// @Success 201 {object} models.PaymentReceiptResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{payment_id}/receipts/reissue [post]
+func (h *PaymentReceiptHandler) Reissue(c *gin.Context) {
+	var req services.ReissueReceiptRequest
+	if c.Request.ContentLength > 0 {
+		if err := BindNestedOrFlat(c, "receipt", &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+			return
+		}
+	}
+	receipt, err := h.receiptService.Reissue(c.Request.Context(), paymentIDParam(c), middleware.GetUserID(c), req.Reason)
+	if err != nil {
+		respondPaymentReceiptError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"receipt": receipt.ToResponse(), "message": "Recibo reemitido"})
+}
+
+func paymentIDParam(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.Param("payment_id"), 10, 32)
+	return uint(id)
+}
+
+func respondPaymentReceiptError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recibo no encontrado"})
+	case errors.Is(err, services.ErrUnauthorized):
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver este recibo"})
+	case errors.Is(err, services.ErrInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type PriceListHandler struct {
+	priceListService *services.PriceListService
+}
+
+func NewPriceListHandler(priceListService *services.PriceListService) *PriceListHandler {
+	return &PriceListHandler{priceListService: priceListService}
+}
+
+// @Summary List Price Lists
+// @Description Get the price list versions of a project (newest first)
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/price_lists [get]
+func (h *PriceListHandler) Index(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	lists, err := h.priceListService.FindByProject(c.Request.Context(), uint(projectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"price_lists": lists})
+}
+
+// @Summary Get Price List
+// @Description Get a price list with its lot price changes
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param price_list_id path int true "Price List ID"
+// @Success 200 {object} models.PriceList
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/price_lists/{price_list_id} [get]
+func (h *PriceListHandler) Show(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	id, _ := strconv.ParseUint(c.Param("price_list_id"), 10, 32)
+	list, err := h.priceListService.FindByID(c.Request.Context(), uint(id))
+	if err != nil || list.ProjectID != uint(projectID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lista de precios no encontrada"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"price_list": list})
+}
+
+// @Summary Reprice Lots
+// @Description Bulk reprice a project's lots (Admin). Filters: statuses (default available), name_pattern (glob), lot_ids. Operations: percent_change (value = %), price_per_square_unit (value = price per unit, 0 = project's). round_to rounds to nearest N. With dry_run=true only the diff is returned. A future effective_from schedules the price list.
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body services.RepriceRequest true "Repricing"
+// @Success 200 {object} services.RepriceResult
+// @Success 201 {object} services.RepriceResult
+// @Security BearerAuth
+// @Router /projects/{project_id}/price_lists [post]
+func (h *PriceListHandler) Create(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	var req services.RepriceRequest
+	if err := BindNestedOrFlat(c, "price_list", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	if c.Query("dry_run") == "true" {
+		req.DryRun = true
+	}
+
+	actorID := middleware.GetUserID(c)
+	result, err := h.priceListService.Reprice(c.Request.Context(), uint(projectID), req, actorID)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if result.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, result)
+}
+
+// @Summary Cancel Price List
+// @Description Cancel a scheduled price list before its effective date (Admin)
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param price_list_id path int true "Price List ID"
+// @Success 200 {object} models.PriceList
+// @Security BearerAuth
+// @Router /projects/{project_id}/price_lists/{price_list_id}/cancel [post]
+func (h *PriceListHandler) Cancel(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	id, _ := strconv.ParseUint(c.Param("price_list_id"), 10, 32)
+	actorID := middleware.GetUserID(c)
+	list, err := h.priceListService.Cancel(c.Request.Context(), uint(projectID), uint(id), actorID)
+	if errors.Is(err, services.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lista de precios no encontrada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"price_list": list})
+}
+
+// @Summary Lot Price History
+// @Description Get the price history of a lot (bulk and manual changes)
+// @Tags Price Lists
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/price_history [get]
+func (h *PriceListHandler) LotHistory(c *gin.Context) {
+	lotID, _ := strconv.ParseUint(c.Param("lot_id"), 10, 32)
+	history, err := h.priceListService.LotHistory(c.Request.Context(), uint(lotID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"price_history": history})
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ProjectSectionHandler struct {
+	sectionService *services.ProjectSectionService
+}
+
+func NewProjectSectionHandler(sectionService *services.ProjectSectionService) *ProjectSectionHandler {
+	return &ProjectSectionHandler{sectionService: sectionService}
+}
+
+// @Summary Project Hierarchy
+// @Description Get the Phase → Block → Lot hierarchy of a project with effective price per unit, delivery date and lot counts per status
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Success 200 {object} models.ProjectHierarchy
+// @Security BearerAuth
+// @Router /projects/{project_id}/phases [get]
+func (h *ProjectSectionHandler) Index(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	tree, err := h.sectionService.Hierarchy(c.Request.Context(), uint(projectID))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Proyecto no encontrado"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"hierarchy": tree})
+}
+
+// @Summary Create Phase
+// @Description Create a phase (etapa) in a project. price_per_square_unit and delivery_date are optional defaults for its lots; status is planned, on_sale, closed or delivered.
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body services.SectionRequest true "Phase"
+// @Success 201 {object} models.ProjectPhase
+// @Failure 404,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/phases [post]
+func (h *ProjectSectionHandler) CreatePhase(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	var req services.SectionRequest
+	if err := BindNestedOrFlat(c, "phase", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	phase, err := h.sectionService.CreatePhase(c.Request.Context(), uint(projectID), req, middleware.GetUserID(c))
+	if err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"phase": phase})
+}
+
+// @Summary Update Phase
+// @Description Update a phase. Changing its price per unit reprices its available lots without an override price. Send 0 / "" to inherit the project's price / delivery date.
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param phase_id path int true "Phase ID"
+// @Param request body services.SectionRequest true "Phase"
+// @Success 200 {object} models.ProjectPhase
+// @Failure 404,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/phases/{phase_id} [put]
+func (h *ProjectSectionHandler) UpdatePhase(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	phaseID, _ := strconv.ParseUint(c.Param("phase_id"), 10, 32)
+	var req services.SectionRequest
+	if err := BindNestedOrFlat(c, "phase", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	phase, err := h.sectionService.UpdatePhase(c.Request.Context(), uint(projectID), uint(phaseID), req, middleware.GetUserID(c))
+	if err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"phase": phase})
+}
+
+// @Summary Delete Phase
+// @Description Delete a phase; its blocks and lots stay in the project without a phase
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param phase_id path int true "Phase ID"
+// @Success 200 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/phases/{phase_id} [delete]
+func (h *ProjectSectionHandler) DeletePhase(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	phaseID, _ := strconv.ParseUint(c.Param("phase_id"), 10, 32)
+	if err := h.sectionService.DeletePhase(c.Request.Context(), uint(projectID), uint(phaseID), middleware.GetUserID(c)); err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Etapa eliminada"})
+}
+
+// @Summary Create Block
+// @Description Create a block (bloque) in a project, optionally inside a phase (phase_id). Its price per unit and delivery date take precedence over the phase's.
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body services.SectionRequest true "Block"
+// @Success 201 {object} models.ProjectBlock
+// @Failure 404,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/blocks [post]
+func (h *ProjectSectionHandler) CreateBlock(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	var req services.SectionRequest
+	if err := BindNestedOrFlat(c, "block", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	block, err := h.sectionService.CreateBlock(c.Request.Context(), uint(projectID), req, middleware.GetUserID(c))
+	if err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"block": block})
+}
+
+// @Summary Update Block
+// @Description Update a block. Moving it to another phase (phase_id, 0 for none) moves its lots along; price changes reprice its available lots without an override price.
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param block_id path int true "Block ID"
+// @Param request body services.SectionRequest true "Block"
+// @Success 200 {object} models.ProjectBlock
+// @Failure 404,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/blocks/{block_id} [put]
+func (h *ProjectSectionHandler) UpdateBlock(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	blockID, _ := strconv.ParseUint(c.Param("block_id"), 10, 32)
+	var req services.SectionRequest
+	if err := BindNestedOrFlat(c, "block", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	block, err := h.sectionService.UpdateBlock(c.Request.Context(), uint(projectID), uint(blockID), req, middleware.GetUserID(c))
+	if err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"block": block})
+}
+
+// @Summary Delete Block
+// @Description Delete a block; its lots stay in their phase without a block
+// @Tags Project Sections
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param block_id path int true "Block ID"
+// @Success 200 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/blocks/{block_id} [delete]
+func (h *ProjectSectionHandler) DeleteBlock(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	blockID, _ := strconv.ParseUint(c.Param("block_id"), 10, 32)
+	if err := h.sectionService.DeleteBlock(c.Request.Context(), uint(projectID), uint(blockID), middleware.GetUserID(c)); err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bloque eliminado"})
+}
+
+func respondSectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Etapa, bloque o proyecto no encontrado"})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// @Summary Lot Inventory Report
+// @Description Download the lot inventory of a project as CSV, optionally filtered by phase/block and grouped by phase or block with subtotals
+// @Tags Reports
+// @Produce text/csv
+// @Param project_id query int true "Project ID"
+// @Param phase_id query string false "Phase ID (none = lots without phase)"
+// @Param block_id query string false "Block ID (none = lots without block)"
+// @Param group_by query string false "phase or block"
+// @Success 200 {file} file "lot_inventory.csv"
+// @Security BearerAuth
+// @Router /reports/lot_inventory_csv [get]
+func (h *ProjectSectionHandler) InventoryCSV(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Query("project_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id es requerido"})
+		return
+	}
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "phase" && groupBy != "block" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by debe ser phase o block"})
+		return
+	}
+	phaseID, blockID := c.Query("phase_id"), c.Query("block_id")
+	for _, id := range []string{phaseID, blockID} {
+		if _, err := strconv.ParseUint(id, 10, 32); id != "" && id != "none" && err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Filtro de etapa o bloque inválido"})
+			return
+		}
+	}
+
+	buf, err := h.sectionService.InventoryCSV(c.Request.Context(), uint(projectID), phaseID, blockID, groupBy)
+	if err != nil {
+		respondSectionError(c, err)
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=lot_inventory.csv")
+	c.String(http.StatusOK, buf.String())
+}
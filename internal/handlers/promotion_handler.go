@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type PromotionHandler struct {
+	promotionService *services.PromotionService
+}
+
+func NewPromotionHandler(promotionService *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+// @Summary List Promotions
+// @Description Get a paginated list of promotions
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param search_term query string false "Search term"
+// @Param project_id query int false "Filter by project"
+// @Param active query bool false "Filter by active flag"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /promotions [get]
+func (h *PromotionHandler) Index(c *gin.Context) {
+	query := repository.NewListQuery()
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.PerPage, _ = strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	query.Search = c.Query("search_term")
+	query.Filters["project_id"] = c.Query("project_id")
+	query.Filters["active"] = c.Query("active")
+
+	promotions, total, err := h.promotionService.List(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotions": promotions, "pagination": gin.H{"total": total}})
+}
+
+// @Summary Get Promotion
+// @Description Get a promotion by ID
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param promotion_id path int true "Promotion ID"
+// @Success 200 {object} models.Promotion
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /promotions/{promotion_id} [get]
+func (h *PromotionHandler) Show(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("promotion_id"), 10, 32)
+	promotion, err := h.promotionService.FindByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promoción no encontrada"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"promotion": promotion})
+}
+
+// @Summary Create Promotion
+// @Description Create a promotion rule (Admin). discount_type: percent_off, fixed_amount, waive_reservation
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param request body models.Promotion true "Promotion Data"
+// @Success 201 {object} models.Promotion
+// @Security BearerAuth
+// @Router /promotions [post]
+func (h *PromotionHandler) Create(c *gin.Context) {
+	var promotion models.Promotion
+	if err := BindNestedOrFlat(c, "promotion", &promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	actorID := middleware.GetUserID(c)
+	if err := h.promotionService.Create(c.Request.Context(), &promotion, actorID); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"promotion": promotion})
+}
+
+// @Summary Update Promotion
+// @Description Update a promotion rule (Admin)
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param promotion_id path int true "Promotion ID"
+// @Param request body models.Promotion true "Promotion Data"
+// @Success 200 {object} models.Promotion
+// @Security BearerAuth
+// @Router /promotions/{promotion_id} [put]
+func (h *PromotionHandler) Update(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("promotion_id"), 10, 32)
+	var promotion models.Promotion
+	if err := BindNestedOrFlat(c, "promotion", &promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	promotion.ID = uint(id)
+
+	actorID := middleware.GetUserID(c)
+	if err := h.promotionService.Update(c.Request.Context(), &promotion, actorID); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotion": promotion})
+}
+
+// @Summary Delete Promotion
+// @Description Delete a promotion (Admin). Promotions already used by contracts are deactivated instead.
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param promotion_id path int true "Promotion ID"
+// @Success 200 {object} map[string]string
+// @Security BearerAuth
+// @Router /promotions/{promotion_id} [delete]
+func (h *PromotionHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("promotion_id"), 10, 32)
+	actorID := middleware.GetUserID(c)
+	if err := h.promotionService.Delete(c.Request.Context(), uint(id), actorID); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promoción eliminada"})
+}
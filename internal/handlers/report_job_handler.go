@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ReportJobHandler struct {
+	reportJobService *services.ReportJobService
+}
+
+func NewReportJobHandler(reportJobService *services.ReportJobService) *ReportJobHandler {
+	return &ReportJobHandler{reportJobService: reportJobService}
+}
+
+// @Summary Submit Report Job
+// @Description Generate a report in the background. report_type is the name of a synchronous report (e.g. total_revenue_csv, user_promise_contract_pdf) and params its query parameters. Poll the job and download the output once completed; it is kept for REPORT_RETENTION_DAYS days.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param request body services.SubmitReportJobRequest true "Report request"
+// @Success 202 {object} models.ReportJobResponse
+// @Failure 422 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/jobs [post]
+func (h *ReportJobHandler) Submit(c *gin.Context) {
+	var req services.SubmitReportJobRequest
+	if err := BindNestedOrFlat(c, "report_job", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	job, err := h.reportJobService.Submit(c.Request.Context(), req, documentActor(c))
+	if err != nil {
+		respondReportJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job": job.ToResponse(), "message": "Reporte en proceso"})
+}
+
+// @Summary Report Jobs
+// @Description List the most recent report jobs of the user (every user's for admins) and the report types available
+// @Tags Reports
+// @Produce json
+// @Success 200 {array} models.ReportJobResponse
+// @Security BearerAuth
+// @Router /reports/jobs [get]
+func (h *ReportJobHandler) Index(c *gin.Context) {
+	jobs, err := h.reportJobService.List(c.Request.Context(), documentActor(c))
+	if err != nil {
+		respondReportJobError(c, err)
+		return
+	}
+	responses := make([]models.ReportJobResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = jobs[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": responses, "report_types": services.ReportTypes()})
+}
+
+// @Summary Report Job Status
+// @Description Get the status of a report job: queued, running, completed, failed or expired
+// @Tags Reports
+// @Produce json
+// @Param job_id path int true "Job ID"
+// @Success 200 {object} models.ReportJobResponse
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/jobs/{job_id} [get]
+func (h *ReportJobHandler) Show(c *gin.Context) {
+	job, err := h.reportJobService.Get(c.Request.Context(), reportJobIDParam(c), documentActor(c))
+	if err != nil {
+		respondReportJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"job": job.ToResponse()})
+}
+
+// @Summary Download Report Job
+// @Description Download the output of a completed report job; the X-Checksum-SHA256 header carries its hash
+// @Tags Reports
+// @Produce octet-stream
+// @Param job_id path int true "Job ID"
+// @Success 200 {file} file "report"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/jobs/{job_id}/download [get]
+func (h *ReportJobHandler) Download(c *gin.Context) {
+	job, fullPath, err := h.reportJobService.Download(c.Request.Context(), reportJobIDParam(c), documentActor(c))
+	if err != nil {
+		respondReportJobError(c, err)
+		return
+	}
+	if job.SHA256 != nil {
+		c.Header("X-Checksum-SHA256", *job.SHA256)
+	}
+	c.Header("Content-Type", job.ContentType)
+	c.FileAttachment(fullPath, job.Filename)
+}
+
+func reportJobIDParam(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.Param("job_id"), 10, 32)
+	return uint(id)
+}
+
+func respondReportJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reporte no encontrado"})
+	case errors.Is(err, services.ErrInvalidReportJob):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportQueueFull):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
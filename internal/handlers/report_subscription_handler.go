@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type ReportSubscriptionHandler struct {
+	subscriptionService *services.ReportSubscriptionService
+}
+
+func NewReportSubscriptionHandler(subscriptionService *services.ReportSubscriptionService) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{subscriptionService: subscriptionService}
+}
+
+// @Summary Report Subscriptions
+// @Description List the scheduled report subscriptions (Admin)
+// @Tags Reports
+// @Produce json
+// @Success 200 {array} models.ReportSubscription
+// @Security BearerAuth
+// @Router /reports/subscriptions [get]
+func (h *ReportSubscriptionHandler) Index(c *gin.Context) {
+	subs, err := h.subscriptionService.List(c.Request.Context())
+	if err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs, "report_types": services.ReportTypes()})
+}
+
+// @Summary Get Report Subscription
+// @Description Get a scheduled report subscription (Admin)
+// @Tags Reports
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {object} models.ReportSubscription
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/subscriptions/{subscription_id} [get]
+func (h *ReportSubscriptionHandler) Show(c *gin.Context) {
+	sub, err := h.subscriptionService.Get(c.Request.Context(), reportSubscriptionIDParam(c))
+	if err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscription": sub})
+}
+
+// @Summary Create Report Subscription
+// @Description Email a report to a list of recipients on a cron-like schedule (Admin). schedule is "minute hour day month weekday", e.g. "0 7 * * 1" for Mondays at 7:00 or "0 7 1 * *" for the 1st of each month; @daily, @weekly and @monthly are accepted. params.period (previous_day, previous_week, previous_month, month_to_date) sets start_date and end_date on each run.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param request body services.ReportSubscriptionRequest true "Subscription"
+// @Success 201 {object} models.ReportSubscription
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/subscriptions [post]
+func (h *ReportSubscriptionHandler) Create(c *gin.Context) {
+	var req services.ReportSubscriptionRequest
+	if err := BindNestedOrFlat(c, "subscription", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	sub, err := h.subscriptionService.Create(c.Request.Context(), req, middleware.GetUserID(c))
+	if err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub})
+}
+
+// @Summary Update Report Subscription
+// @Description Replace a scheduled report subscription and reschedule its next run (Admin). Send active=false to pause it.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Param request body services.ReportSubscriptionRequest true "Subscription"
+// @Success 200 {object} models.ReportSubscription
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/subscriptions/{subscription_id} [put]
+func (h *ReportSubscriptionHandler) Update(c *gin.Context) {
+	var req services.ReportSubscriptionRequest
+	if err := BindNestedOrFlat(c, "subscription", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+	sub, err := h.subscriptionService.Update(c.Request.Context(), reportSubscriptionIDParam(c), req)
+	if err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscription": sub})
+}
+
+// @Summary Delete Report Subscription
+// @Description Delete a scheduled report subscription and its run history (Admin)
+// @Tags Reports
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/subscriptions/{subscription_id} [delete]
+func (h *ReportSubscriptionHandler) Delete(c *gin.Context) {
+	if err := h.subscriptionService.Delete(c.Request.Context(), reportSubscriptionIDParam(c)); err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Suscripción eliminada"})
+}
+
+// @Summary Report Subscription Runs
+// @Description List the most recent runs of a scheduled report subscription (Admin)
+// @Tags Reports
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {array} models.ReportSubscriptionRun
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/subscriptions/{subscription_id}/runs [get]
+func (h *ReportSubscriptionHandler) Runs(c *gin.Context) {
+	runs, err := h.subscriptionService.Runs(c.Request.Context(), reportSubscriptionIDParam(c))
+	if err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// @Summary Run Report Subscription
+// @Description Send a scheduled report now, without changing its schedule (Admin)
+// @Tags Reports
+// @Produce json
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {object} models.ReportSubscriptionRun
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/subscriptions/{subscription_id}/run [post]
+func (h *ReportSubscriptionHandler) Run(c *gin.Context) {
+	run, err := h.subscriptionService.RunNow(c.Request.Context(), reportSubscriptionIDParam(c))
+	if err != nil {
+		respondReportSubscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func reportSubscriptionIDParam(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.Param("subscription_id"), 10, 32)
+	return uint(id)
+}
+
+func respondReportSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suscripción no encontrada"})
+	case errors.Is(err, services.ErrInvalidReportSubscription):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -16,10 +17,11 @@ import (
 
 type ProjectHandler struct {
 	projectService *services.ProjectService
+	importService  *services.ImportService
 }
 
-func NewProjectHandler(projectService *services.ProjectService) *ProjectHandler {
-	return &ProjectHandler{projectService: projectService}
+func NewProjectHandler(projectService *services.ProjectService, importService *services.ImportService) *ProjectHandler {
+	return &ProjectHandler{projectService: projectService, importService: importService}
 }
 
 func (h *ProjectHandler) Index(c *gin.Context) {
@@ -31,6 +33,7 @@ func (h *ProjectHandler) Index(c *gin.Context) {
 	// @Param page query int false "Page number" default(1)
 	// @Param per_page query int false "Items per page" default(20)
 	// @Param search_term query string false "Search term"
+	// @Param status query string false "draft, pending_approval, published or closed (admins; sellers only see published)"
 	// @Success 200 {object} map[string]interface{}
 	// @Security BearerAuth
 	// @Router /projects [get]
@@ -41,6 +44,11 @@ func (h *ProjectHandler) Index(c *gin.Context) {
 	if guid := c.Query("guid"); guid != "" {
 		query.Filters["guid"] = guid
 	}
+	// Sellers only see projects open for sale; admins may filter by status (comma-separated)
+	query.Filters["status"] = c.Query("status")
+	if middleware.GetUserRole(c) != models.RoleAdmin {
+		query.Filters["status"] = models.ProjectStatusPublished
+	}
 
 	// Parse sort parameter (format: field-direction, e.g. name-asc)
 	if sort := c.Query("sort"); sort != "" && sort != "No Sort" {
@@ -66,7 +74,7 @@ func (h *ProjectHandler) Index(c *gin.Context) {
 }
 
 // @Summary Get Project
-// @Description Get a project by ID
+// @Description Get a project by ID. Sellers see published projects and, read-only, closed ones.
 // @Tags Projects
 // @Accept json
 // @Produce json
@@ -78,7 +86,7 @@ func (h *ProjectHandler) Index(c *gin.Context) {
 func (h *ProjectHandler) Show(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
 	project, err := h.projectService.FindByID(c.Request.Context(), uint(id))
-	if err != nil {
+	if err != nil || (!project.IsVisibleToSellers() && middleware.GetUserRole(c) != models.RoleAdmin) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Proyecto no encontrado"})
 		return
 	}
@@ -86,7 +94,7 @@ func (h *ProjectHandler) Show(c *gin.Context) {
 }
 
 // @Summary Create Project
-// @Description Create a new project
+// @Description Create a new project. Projects start as drafts; submit and approve them to publish for sale.
 // @Tags Projects
 // @Accept json
 // @Produce json
@@ -104,6 +112,10 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.projectService.Create(c.Request.Context(), &project, actorID); err != nil {
+		if errors.Is(err, services.ErrProjectStatusManualChange) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -134,6 +146,10 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.projectService.Update(c.Request.Context(), &project, actorID); err != nil {
+		if errors.Is(err, services.ErrProjectStatusManualChange) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -160,28 +176,169 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Proyecto eliminado"})
 }
 
+// ProjectTransitionRequest carries the optional reason of a project lifecycle transition
+type ProjectTransitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// @Summary Submit Project
+// @Description Send a draft project for approval
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Success 200 {object} models.ProjectResponse
+// @Failure 404,409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/submit [post]
+func (h *ProjectHandler) Submit(c *gin.Context) {
+	h.transition(c, func(id, actorID uint, _ string) (*models.Project, error) {
+		return h.projectService.Submit(c.Request.Context(), id, actorID)
+	})
+}
+
 // @Summary Approve Project
-// @Description Approve a project (Admin)
+// @Description Approve a project pending approval and publish it; sellers can then hold lots and create contracts
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Param project_id path int true "Project ID"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} models.ProjectResponse
+// @Failure 404,409 {object} map[string]string
 // @Security BearerAuth
 // @Router /projects/{project_id}/approve [post]
 func (h *ProjectHandler) Approve(c *gin.Context) {
+	h.transition(c, func(id, actorID uint, _ string) (*models.Project, error) {
+		return h.projectService.Approve(c.Request.Context(), id, actorID)
+	})
+}
+
+// @Summary Reject Project
+// @Description Send a project pending approval back to draft (reason required)
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body ProjectTransitionRequest true "Reason"
+// @Success 200 {object} models.ProjectResponse
+// @Failure 404,409,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/reject [post]
+func (h *ProjectHandler) Reject(c *gin.Context) {
+	h.transition(c, func(id, actorID uint, reason string) (*models.Project, error) {
+		return h.projectService.Reject(c.Request.Context(), id, actorID, reason)
+	})
+}
+
+// @Summary Close Project
+// @Description Close the sales of a published project; existing contracts continue
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body ProjectTransitionRequest false "Reason"
+// @Success 200 {object} models.ProjectResponse
+// @Failure 404,409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/close [post]
+func (h *ProjectHandler) Close(c *gin.Context) {
+	h.transition(c, func(id, actorID uint, reason string) (*models.Project, error) {
+		return h.projectService.Close(c.Request.Context(), id, actorID, reason)
+	})
+}
+
+// @Summary Reopen Project
+// @Description Publish a closed project again
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Success 200 {object} models.ProjectResponse
+// @Failure 404,409 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/reopen [post]
+func (h *ProjectHandler) Reopen(c *gin.Context) {
+	h.transition(c, func(id, actorID uint, _ string) (*models.Project, error) {
+		return h.projectService.Reopen(c.Request.Context(), id, actorID)
+	})
+}
+
+func (h *ProjectHandler) transition(c *gin.Context, fire func(id, actorID uint, reason string) (*models.Project, error)) {
 	id, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
-	// For now, just a placeholder update status
-	// Ideally service has Approve method
-	_, err := h.projectService.FindByID(c.Request.Context(), uint(id))
+	var req ProjectTransitionRequest
+	if c.Request.ContentLength > 0 {
+		if err := BindNestedOrFlat(c, "project", &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+			return
+		}
+	}
+
+	project, err := fire(uint(id), middleware.GetUserID(c), req.Reason)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Proyecto no encontrado"})
+		case errors.Is(err, services.ErrInvalidState):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrProjectReasonRequired):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
-	// project.Status = "approved"
-	// h.projectService.Update(c, project)
+	c.JSON(http.StatusOK, gin.H{"project": project.ToResponse()})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Proyecto aprobado (Placeholder)"})
+// GeometryRequest sets a polygon as a GeoJSON object or a GeoJSON/WKT string (null or "" clears it)
+type GeometryRequest struct {
+	Geometry models.Geometry `json:"geometry"`
+}
+
+// @Summary Project Site Map
+// @Description Get a GeoJSON FeatureCollection with the project boundary and the polygons of its lots (properties: kind, lot_id, name, status, price, effective_price, area, measurement_unit, registration_number)
+// @Tags Projects
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Success 200 {object} geo.FeatureCollection
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/site_map [get]
+func (h *ProjectHandler) SiteMap(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	fc, err := h.projectService.SiteMap(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proyecto no encontrado"})
+		return
+	}
+	c.JSON(http.StatusOK, fc)
+}
+
+// @Summary Set Project Geometry
+// @Description Set the project boundary polygon (GeoJSON or WKT POLYGON/MULTIPOLYGON)
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body GeometryRequest true "Geometry"
+// @Success 200 {object} models.ProjectResponse
+// @Security BearerAuth
+// @Router /projects/{project_id}/geometry [put]
+func (h *ProjectHandler) UpdateGeometry(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	var req GeometryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	actorID := middleware.GetUserID(c)
+	project, err := h.projectService.SetGeometry(c.Request.Context(), uint(id), string(req.Geometry), actorID)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"project": project.ToResponse()})
 }
 
 type ImportProjectRequest struct {
@@ -189,39 +346,83 @@ type ImportProjectRequest struct {
 }
 
 // @Summary Import Projects
-// @Description Bulk import projects
+// @Description Bulk import projects. With a multipart CSV/XLSX file (column project plus lot columns), projects are matched by name (created when missing, using project_description, project_address, price_per_square_unit, interest_rate) and lots are upserted by registration_number. dry_run=true only validates. A JSON body with projects is still accepted; per-project errors are reported.
 // @Tags Projects
+// @Accept multipart/form-data
 // @Accept json
 // @Produce json
-// @Param request body ImportProjectRequest true "Projects Data"
-// @Success 200 {object} map[string]string
+// @Param file formData file false "CSV or XLSX file"
+// @Param dry_run query bool false "Validate only"
+// @Param request body ImportProjectRequest false "Projects Data"
+// @Success 200 {object} services.ImportResult
+// @Failure 422 {object} services.ImportResult
 // @Security BearerAuth
 // @Router /projects/import [post]
 func (h *ProjectHandler) Import(c *gin.Context) {
+	actorID := middleware.GetUserID(c)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo requerido"})
+			return
+		}
+		defer file.Close()
+
+		result, err := h.importService.ImportProjects(c.Request.Context(), file, header.Filename, c.Query("dry_run") == "true", actorID)
+		respondImport(c, result, err)
+		return
+	}
+
 	var req ImportProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	actorID := middleware.GetUserID(c)
-	for _, p := range req.Projects {
+	imported := 0
+	var importErrors []gin.H
+	for i := range req.Projects {
+		p := req.Projects[i]
 		if err := h.projectService.Create(c.Request.Context(), &p, actorID); err != nil {
-			// Continue on error for now or break?
-			// c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			// return
+			importErrors = append(importErrors, gin.H{"index": i, "name": p.Name, "error": err.Error()})
+			continue
 		}
+		imported++
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Importados %d proyectos", len(req.Projects))})
+	status := http.StatusOK
+	if imported == 0 && len(importErrors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, gin.H{
+		"message":  fmt.Sprintf("Importados %d de %d proyectos", imported, len(req.Projects)),
+		"imported": imported,
+		"failed":   len(importErrors),
+		"errors":   importErrors,
+	})
+}
+
+// respondImport writes an import report: 422 when the file could not be read or has invalid rows
+func respondImport(c *gin.Context, result *services.ImportResult, err error) {
+	if err != nil {
+		if result == nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "import": result})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"import": result})
 }
 
 type LotHandler struct {
-	lotService *services.LotService
+	lotService    *services.LotService
+	importService *services.ImportService
 }
 
-func NewLotHandler(lotService *services.LotService) *LotHandler {
-	return &LotHandler{lotService: lotService}
+func NewLotHandler(lotService *services.LotService, importService *services.ImportService) *LotHandler {
+	return &LotHandler{lotService: lotService, importService: importService}
 }
 
 // @Summary List Lots
@@ -234,6 +435,9 @@ func NewLotHandler(lotService *services.LotService) *LotHandler {
 // @Param per_page query int false "Items per page" default(20)
 // @Param search_term query string false "Search term"
 // @Param status query string false "Filter by status"
+// @Param phase_id query string false "Filter by phase ID (none = lots without phase)"
+// @Param block_id query string false "Filter by block ID (none = lots without block)"
+// @Param group_by query string false "phase or block: sort the page by hierarchy and add groups"
 // @Success 200 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /projects/{project_id}/lots [get]
@@ -244,6 +448,20 @@ func (h *LotHandler) Index(c *gin.Context) {
 	query.PerPage, _ = strconv.Atoi(c.DefaultQuery("per_page", "20"))
 	query.Search = c.Query("search_term")
 	query.Filters["status"] = c.Query("status")
+	query.Filters["phase_id"] = c.Query("phase_id")
+	query.Filters["block_id"] = c.Query("block_id")
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "phase" && groupBy != "block" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by debe ser phase o block"})
+		return
+	}
+	query.Filters["group_by"] = groupBy
+	for _, id := range []string{query.Filters["phase_id"], query.Filters["block_id"]} {
+		if _, err := strconv.ParseUint(id, 10, 32); id != "" && id != "none" && err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Filtro de etapa o bloque inválido"})
+			return
+		}
+	}
 
 	lots, total, err := h.lotService.List(c.Request.Context(), uint(projectID), query)
 	if err != nil {
@@ -263,7 +481,7 @@ func (h *LotHandler) Index(c *gin.Context) {
 			totalPages = 1
 		}
 	}
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"lots": responses,
 		"pagination": gin.H{
 			"page":        query.Page,
@@ -272,7 +490,11 @@ func (h *LotHandler) Index(c *gin.Context) {
 			"total_pages": totalPages,
 			"total_items": total,
 		},
-	})
+	}
+	if groupBy != "" {
+		resp["groups"] = models.GroupLots(lots, groupBy)
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // @Summary Get Lot
@@ -317,6 +539,10 @@ func (h *LotHandler) Create(c *gin.Context) {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.lotService.Create(c.Request.Context(), &lot, actorID); err != nil {
+		if errors.Is(err, services.ErrLotStatusManualChange) || errors.Is(err, services.ErrSectionNotInProject) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -325,7 +551,7 @@ func (h *LotHandler) Create(c *gin.Context) {
 }
 
 // @Summary Update Lot
-// @Description Update an existing lot
+// @Description Update an existing lot. The status is read-only: it follows contract and payment events (422 if changed)
 // @Tags Lots
 // @Accept json
 // @Produce json
@@ -347,6 +573,10 @@ func (h *LotHandler) Update(c *gin.Context) {
 
 	actorID := middleware.GetUserID(c)
 	if err := h.lotService.Update(c.Request.Context(), &lot, actorID); err != nil {
+		if errors.Is(err, services.ErrLotStatusManualChange) || errors.Is(err, services.ErrSectionNotInProject) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -373,6 +603,114 @@ func (h *LotHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Lote eliminado"})
 }
 
+// @Summary Import Lots
+// @Description Bulk import lots into a project from a CSV/XLSX file. Columns: name, registration_number, length, width, area, price, override_price, measurement_unit, address, note, north, south, east, west (Spanish headers accepted). Lots are upserted by registration_number; empty price is computed from the project price per unit. dry_run=true returns the per-row report without writing.
+// @Tags Lots
+// @Accept multipart/form-data
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Validate only"
+// @Success 200 {object} services.ImportResult
+// @Failure 422 {object} services.ImportResult
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/import [post]
+func (h *LotHandler) Import(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo requerido"})
+		return
+	}
+	defer file.Close()
+
+	actorID := middleware.GetUserID(c)
+	result, err := h.importService.ImportLots(c.Request.Context(), uint(projectID), file, header.Filename, c.Query("dry_run") == "true", actorID)
+	respondImport(c, result, err)
+}
+
+// @Summary Lot Status History
+// @Description Get the status transitions of a lot (event, contract, payment, actor), newest first
+// @Tags Lots
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/status_history [get]
+func (h *LotHandler) StatusHistory(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("lot_id"), 10, 32)
+	history, err := h.lotService.StatusHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status_history": history})
+}
+
+// @Summary Set Lot Geometry
+// @Description Set a lot polygon (GeoJSON or WKT POLYGON/MULTIPOLYGON); the polygon area is used when there is no override area
+// @Tags Lots
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param lot_id path int true "Lot ID"
+// @Param request body GeometryRequest true "Geometry"
+// @Success 200 {object} models.LotResponse
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/{lot_id}/geometry [put]
+func (h *LotHandler) UpdateGeometry(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	id, _ := strconv.ParseUint(c.Param("lot_id"), 10, 32)
+	var req GeometryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	actorID := middleware.GetUserID(c)
+	lot, err := h.lotService.SetGeometry(c.Request.Context(), uint(projectID), uint(id), string(req.Geometry), actorID)
+	if errors.Is(err, services.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lote no encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lot": lot.ToResponse()})
+}
+
+// @Summary Import Lot Geometries
+// @Description Bulk load lot polygons from a GeoJSON FeatureCollection (.geojson/.json) or a CSV/XLSX with a geometry column (GeoJSON or WKT). Lots are matched by registration_number (default) or name; a feature with properties.kind=project sets the project boundary. dry_run=true only validates.
+// @Tags Lots
+// @Accept multipart/form-data
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param file formData file true "Geometry file"
+// @Param match_by query string false "registration_number or name"
+// @Param dry_run query bool false "Validate only"
+// @Success 200 {object} services.ImportResult
+// @Failure 422 {object} services.ImportResult
+// @Security BearerAuth
+// @Router /projects/{project_id}/lots/geometry/import [post]
+func (h *LotHandler) ImportGeometry(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Archivo requerido"})
+		return
+	}
+	defer file.Close()
+
+	actorID := middleware.GetUserID(c)
+	result, err := h.importService.ImportGeometries(c.Request.Context(), uint(projectID), file, header.Filename,
+		c.Query("match_by"), c.Query("dry_run") == "true", actorID)
+	respondImport(c, result, err)
+}
+
 type NotificationHandler struct {
 	notificationService *services.NotificationService
 }
@@ -553,14 +891,28 @@ func (h *ReportHandler) Commissions(c *gin.Context) {
 }
 
 // @Summary Revenue Report
-// @Description Download total revenue report as CSV
+// @Description Download total revenue report as CSV, optionally for a project, phase or block
 // @Tags Reports
 // @Produce text/csv
+// @Param project_id query int false "Project ID"
+// @Param phase_id query int false "Phase ID"
+// @Param block_id query int false "Block ID"
 // @Success 200 {file} file "revenue.csv"
 // @Security BearerAuth
 // @Router /reports/total_revenue_csv [get]
 func (h *ReportHandler) TotalRevenueCSV(c *gin.Context) {
-	buf, err := h.reportService.GenerateRevenueCSV(c.Request.Context())
+	filters := services.RevenueReportFilters{
+		ProjectID: c.Query("project_id"),
+		PhaseID:   c.Query("phase_id"),
+		BlockID:   c.Query("block_id"),
+	}
+	for _, id := range []string{filters.ProjectID, filters.PhaseID, filters.BlockID} {
+		if _, err := strconv.ParseUint(id, 10, 32); id != "" && err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Filtro de proyecto, etapa o bloque inválido"})
+			return
+		}
+	}
+	buf, err := h.reportService.GenerateRevenueCSV(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -588,6 +940,104 @@ func (h *ReportHandler) OverduePaymentsCSV(c *gin.Context) {
 	c.String(http.StatusOK, buf.String())
 }
 
+// agingFilters reads the receivables aging filters; sellers only see the contracts they created
+func agingFilters(c *gin.Context) (services.AgingReportFilters, error) {
+	params := map[string]string{}
+	for _, key := range []string{"as_of", "buckets", "group_by", "project_id", "seller_id"} {
+		params[key] = c.Query(key)
+	}
+	filters, err := services.ParseAgingReportFilters(params, time.Now())
+	if err != nil {
+		return filters, err
+	}
+	if middleware.GetUserRole(c) != models.RoleAdmin {
+		filters.SellerID = middleware.GetUserID(c)
+	}
+	return filters, nil
+}
+
+func respondAgingError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidAgingReport) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// @Summary Receivables Aging Report
+// @Description Outstanding principal and interest as of a date, computed from the ledger history and bucketed by days past due, per contract, customer, project or seller with totals and percentages
+// @Tags Reports
+// @Produce json
+// @Param as_of query string false "As-of date (YYYY-MM-DD), today by default"
+// @Param buckets query string false "Upper bounds of the buckets in days" default(30,60,90,180)
+// @Param group_by query string false "contract, customer, project or seller" default(contract)
+// @Param project_id query int false "Project ID"
+// @Param seller_id query int false "Seller ID (admins only)"
+// @Success 200 {object} services.AgingReport
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/receivables_aging [get]
+func (h *ReportHandler) ReceivablesAging(c *gin.Context) {
+	filters, err := agingFilters(c)
+	if err != nil {
+		respondAgingError(c, err)
+		return
+	}
+	report, err := h.reportService.GenerateAgingReport(c.Request.Context(), filters)
+	if err != nil {
+		respondAgingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"aging": report})
+}
+
+// @Summary Receivables Aging CSV
+// @Description Download the receivables aging report as CSV; takes the same parameters as /reports/receivables_aging
+// @Tags Reports
+// @Produce text/csv
+// @Success 200 {file} file "receivables_aging.csv"
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/receivables_aging_csv [get]
+func (h *ReportHandler) ReceivablesAgingCSV(c *gin.Context) {
+	filters, err := agingFilters(c)
+	if err != nil {
+		respondAgingError(c, err)
+		return
+	}
+	buf, err := h.reportService.GenerateAgingReportCSV(c.Request.Context(), filters)
+	if err != nil {
+		respondAgingError(c, err)
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=receivables_aging.csv")
+	c.String(http.StatusOK, buf.String())
+}
+
+// @Summary Receivables Aging XLSX
+// @Description Download the receivables aging report as a workbook with a summary by bucket and a sheet per contract, customer, project and seller; takes the same parameters as /reports/receivables_aging except group_by
+// @Tags Reports
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {file} file "receivables_aging.xlsx"
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /reports/receivables_aging_xlsx [get]
+func (h *ReportHandler) ReceivablesAgingXLSX(c *gin.Context) {
+	filters, err := agingFilters(c)
+	if err != nil {
+		respondAgingError(c, err)
+		return
+	}
+	buf, err := h.reportService.GenerateAgingReportXLSX(c.Request.Context(), filters)
+	if err != nil {
+		respondAgingError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename=receivables_aging.xlsx")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+}
+
 // @Summary User Balance PDF
 // @Description Download user balance statement as PDF
 // @Tags Reports
@@ -669,6 +1119,58 @@ func (h *ReportHandler) UserRescissionContractPDF(c *gin.Context) {
 	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
 }
 
+// @Summary Contract Cession PDF
+// @Description Download the cession of rights document of a contract cession
+// @Tags Reports
+// @Produce application/pdf
+// @Param cession_id query int true "Cession ID"
+// @Success 200 {file} file "cession.pdf"
+// @Security BearerAuth
+// @Router /reports/contract_cession_pdf [get]
+func (h *ReportHandler) ContractCessionPDF(c *gin.Context) {
+	cessionID, _ := strconv.ParseUint(c.Query("cession_id"), 10, 32)
+	if cessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cession_id is required"})
+		return
+	}
+
+	buf, err := h.reportService.GenerateCessionPDF(c.Request.Context(), uint(cessionID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=cession_%d.pdf", cessionID))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// @Summary Contract Lot Change Addendum PDF
+// @Description Download the contract addendum of a lot change
+// @Tags Reports
+// @Produce application/pdf
+// @Param lot_change_id query int true "Lot change ID"
+// @Success 200 {file} file "lot_change.pdf"
+// @Security BearerAuth
+// @Router /reports/contract_lot_change_pdf [get]
+func (h *ReportHandler) ContractLotChangePDF(c *gin.Context) {
+	changeID, _ := strconv.ParseUint(c.Query("lot_change_id"), 10, 32)
+	if changeID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lot_change_id is required"})
+		return
+	}
+
+	buf, err := h.reportService.GenerateLotChangeAddendumPDF(c.Request.Context(), uint(changeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=lot_change_%d.pdf", changeID))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
 // @Summary User Information PDF
 // @Description Download user information sheet as PDF
 // @Tags Reports
@@ -722,6 +1224,28 @@ func (h *ReportHandler) CustomerRecordPDF(c *gin.Context) {
 	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
 }
 
+// @Summary Verify Issued Document
+// @Description Confirm a document issued by the system (balance statement, contracts, addenda) from the code printed on it: issuer, issue date and key figures. Public; pass sha256 to check a copy of the file.
+// @Tags Reports
+// @Produce json
+// @Param code path string true "Document code"
+// @Param sha256 query string false "SHA-256 of the file to check"
+// @Success 200 {object} services.DocumentVerification
+// @Failure 404 {object} map[string]interface{}
+// @Router /verify/{code} [get]
+func (h *ReportHandler) VerifyDocument(c *gin.Context) {
+	verification, err := h.reportService.VerifyDocument(c.Request.Context(), c.Param("code"), c.Query("sha256"))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "Documento no encontrado"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
 // @Summary Seller Dashboard Stats
 // @Description Get statistics for the seller dashboard
 // @Tags Reports
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sjperalta/fintera-api/internal/middleware"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/services"
+)
+
+type WaitlistHandler struct {
+	waitlistService *services.WaitlistService
+}
+
+func NewWaitlistHandler(waitlistService *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{waitlistService: waitlistService}
+}
+
+// @Summary List Waitlist
+// @Description Get the waitlist of a project in queue order. Sellers only see the customers they registered.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param status query string false "waiting, notified, fulfilled, lapsed or cancelled"
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /projects/{project_id}/waitlist [get]
+func (h *WaitlistHandler) Index(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	var sellerID *uint
+	if middleware.GetUserRole(c) != models.RoleAdmin {
+		id := middleware.GetUserID(c)
+		sellerID = &id
+	}
+
+	entries, err := h.waitlistService.List(c.Request.Context(), uint(projectID), sellerID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responses := make([]models.LotWaitlistEntryResponse, len(entries))
+	for i := range entries {
+		responses[i] = entries[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"waitlist": responses})
+}
+
+// @Summary Join Waitlist
+// @Description Register an interested customer on a taken lot (lot_id) or on any lot of the project up to max_price. When a matching lot frees up, the first entry is notified and its seller gets a priority hold (project waitlist_hold_minutes).
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param request body services.WaitlistEntryRequest true "Waitlist entry"
+// @Success 201 {object} models.LotWaitlistEntryResponse
+// @Failure 404,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/waitlist [post]
+func (h *WaitlistHandler) Create(c *gin.Context) {
+	projectID, _ := strconv.ParseUint(c.Param("project_id"), 10, 32)
+	var req services.WaitlistEntryRequest
+	if err := BindNestedOrFlat(c, "waitlist_entry", &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format: " + err.Error()})
+		return
+	}
+
+	entry, err := h.waitlistService.Register(c.Request.Context(), uint(projectID), req, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Proyecto no encontrado"})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"waitlist_entry": entry.ToResponse()})
+}
+
+// @Summary Leave Waitlist
+// @Description Cancel a waiting entry (its seller or an admin)
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param project_id path int true "Project ID"
+// @Param entry_id path int true "Waitlist entry ID"
+// @Success 200 {object} models.LotWaitlistEntryResponse
+// @Failure 403,404,422 {object} map[string]string
+// @Security BearerAuth
+// @Router /projects/{project_id}/waitlist/{entry_id} [delete]
+func (h *WaitlistHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("entry_id"), 10, 32)
+	isAdmin := middleware.GetUserRole(c) == models.RoleAdmin
+	entry, err := h.waitlistService.Cancel(c.Request.Context(), uint(id), middleware.GetUserID(c), isAdmin)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Entrada de lista de espera no encontrada"})
+		case errors.Is(err, services.ErrUnauthorized):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"waitlist_entry": entry.ToResponse()})
+}
@@ -253,3 +253,51 @@ func (w *Worker) trackJobFailure() {
 	// So CompletedJobs effectively means "Finished Jobs" (success or fail).
 	// If we want Success count, we can derive it: Completed - Failed.
 }
+
+// Queue is a bounded queue of jobs run by its own processors, so long jobs don't hold the shared
+// worker pool and a full queue refuses new jobs instead of running them on the caller
+type Queue struct {
+	name string
+	jobs chan Job
+}
+
+// NewQueue starts a queue of the given capacity processed by n goroutines, stopped on Shutdown
+func (w *Worker) NewQueue(name string, n, capacity int) *Queue {
+	q := &Queue{name: name, jobs: make(chan Job, capacity)}
+	for i := 0; i < n; i++ {
+		w.wg.Add(1)
+		go w.processQueue(q, i)
+	}
+	return q
+}
+
+// TryEnqueue adds a job to the queue; returns false when the queue is full
+func (q *Queue) TryEnqueue(job Job) bool {
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// processQueue handles the jobs of a dedicated queue
+func (w *Worker) processQueue(q *Queue, processorID int) {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case job := <-q.jobs:
+			w.trackJobStart()
+			start := time.Now()
+			if err := job(w.ctx); err != nil {
+				logger.Error(fmt.Sprintf("[Queue %s %d] Job error: %v", q.name, processorID, err))
+				w.trackJobFailure()
+			} else {
+				logger.Info(fmt.Sprintf("[Queue %s %d] Job completed in %v", q.name, processorID, time.Since(start)))
+			}
+			w.trackJobEnd()
+		}
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 type AnalyticsFilters struct {
 	ProjectID        *uint
+	PhaseID          *uint
+	BlockID          *uint
+	GroupBy          string // "phase" or "block" (lot distribution only)
 	StartDate        *time.Time
 	EndDate          *time.Time
 	RevenueTimeframe string
@@ -20,6 +23,12 @@ func (f AnalyticsFilters) HasDateFilter() bool {
 	return f.StartDate != nil || f.EndDate != nil || f.Year != nil
 }
 
+// HasSectionFilter returns true when the results are filtered or grouped by phase/block; those
+// are not cached.
+func (f AnalyticsFilters) HasSectionFilter() bool {
+	return f.PhaseID != nil || f.BlockID != nil || f.GroupBy != ""
+}
+
 // AnalyticsCache represents a cached analytics result
 type AnalyticsCache struct {
 	ID        uint            `gorm:"primaryKey" json:"id"`
@@ -64,10 +73,52 @@ type LotDistribution struct {
 	FullyPaid           int     `json:"fully_paid"`
 	Reserved            int     `json:"reserved"`
 	Available           int     `json:"available"`
+	Held                int     `json:"held"`
 	FinancedPercentage  float64 `json:"financed_percentage"`
 	FullyPaidPercentage float64 `json:"fully_paid_percentage"`
 	ReservedPercentage  float64 `json:"reserved_percentage"`
 	AvailablePercentage float64 `json:"available_percentage"`
+	HeldPercentage      float64 `json:"held_percentage"`
+
+	// Groups is the distribution per phase or block when grouped
+	Groups []LotDistributionGroup `json:"groups,omitempty"`
+}
+
+// LotDistributionGroup is the lot distribution of one phase or block (nil ID = lots without one)
+type LotDistributionGroup struct {
+	ID           *uint           `json:"id"`
+	Name         string          `json:"name"`
+	Distribution LotDistribution `json:"distribution"`
+}
+
+// Add counts lots with the given status
+func (d *LotDistribution) Add(status string, count int) {
+	d.TotalLots += count
+	switch status {
+	case LotStatusAvailable:
+		d.Available += count
+	case LotStatusHeld:
+		d.Held += count
+	case LotStatusReserved:
+		d.Reserved += count
+	case LotStatusFinanced:
+		d.Financed += count
+	case LotStatusFullyPaid:
+		d.FullyPaid += count
+	}
+}
+
+// CalculatePercentages fills the percentages from the counts
+func (d *LotDistribution) CalculatePercentages() {
+	if d.TotalLots == 0 {
+		return
+	}
+	total := float64(d.TotalLots)
+	d.AvailablePercentage = (float64(d.Available) / total) * 100
+	d.ReservedPercentage = (float64(d.Reserved) / total) * 100
+	d.FinancedPercentage = (float64(d.Financed) / total) * 100
+	d.FullyPaidPercentage = (float64(d.FullyPaid) / total) * 100
+	d.HeldPercentage = (float64(d.Held) / total) * 100
 }
 
 // ProjectPerformance represents performance metrics for a project
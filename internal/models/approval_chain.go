@@ -0,0 +1,156 @@
+package models
+
+import (
+	"time"
+)
+
+// ApprovalChain is the ordered list of approvals a contract needs before it is approved, e.g.
+// sales manager then finance, plus a director for large amounts. A chain applies to one project
+// (or all projects when ProjectID is nil) from MinAmount up; the most specific chain wins.
+type ApprovalChain struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	ProjectID *uint     `gorm:"index" json:"project_id"`                                 // nil = all projects
+	MinAmount float64   `gorm:"type:decimal(15,2);not null;default:0" json:"min_amount"` // applies to contracts from this amount
+	Active    bool      `gorm:"default:true;index" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Associations
+	Steps   []ApprovalChainStep `gorm:"foreignKey:ChainID" json:"steps"`
+	Project *Project            `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
+
+// TableName specifies the table name for ApprovalChain
+func (ApprovalChain) TableName() string {
+	return "approval_chains"
+}
+
+// ApprovalChainStep is one approval of a chain, given by a specific user or by any user with a role
+type ApprovalChainStep struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	ChainID        uint    `gorm:"not null;index" json:"chain_id"`
+	Position       int     `gorm:"not null" json:"position"` // 1-based order in the chain
+	Name           string  `gorm:"not null" json:"name"`     // e.g. "Gerente de ventas", "Finanzas"
+	ApproverUserID *uint   `json:"approver_user_id"`
+	ApproverRole   *string `json:"approver_role"`
+
+	// Associations
+	ApproverUser *User `gorm:"foreignKey:ApproverUserID" json:"approver_user,omitempty"`
+}
+
+// TableName specifies the table name for ApprovalChainStep
+func (ApprovalChainStep) TableName() string {
+	return "approval_chain_steps"
+}
+
+// CanBeApprovedBy returns true if the user is the step's approver or has its role
+func (s *ApprovalChainStep) CanBeApprovedBy(user *User) bool {
+	if s.ApproverUserID != nil {
+		return *s.ApproverUserID == user.ID
+	}
+	return s.ApproverRole != nil && *s.ApproverRole == user.Role
+}
+
+// Applies returns true if the chain covers contracts of the project for the amount
+func (c *ApprovalChain) Applies(projectID uint, amount float64) bool {
+	if !c.Active || len(c.Steps) == 0 {
+		return false
+	}
+	if c.ProjectID != nil && *c.ProjectID != projectID {
+		return false
+	}
+	return amount >= c.MinAmount
+}
+
+// StepAt returns the step at the 1-based position, or nil past the end of the chain
+func (c *ApprovalChain) StepAt(position int) *ApprovalChainStep {
+	for i := range c.Steps {
+		if c.Steps[i].Position == position {
+			return &c.Steps[i]
+		}
+	}
+	return nil
+}
+
+// SelectApprovalChain returns the chain for a contract: project chains before global ones and,
+// among those, the highest threshold the amount reaches. Nil when no chain applies.
+func SelectApprovalChain(chains []ApprovalChain, projectID uint, amount float64) *ApprovalChain {
+	var selected *ApprovalChain
+	for i := range chains {
+		c := &chains[i]
+		if !c.Applies(projectID, amount) {
+			continue
+		}
+		if selected == nil {
+			selected = c
+			continue
+		}
+		if (c.ProjectID != nil) != (selected.ProjectID != nil) {
+			if c.ProjectID != nil {
+				selected = c
+			}
+			continue
+		}
+		if c.MinAmount > selected.MinAmount {
+			selected = c
+		}
+	}
+	return selected
+}
+
+// ContractApproval records one decision of an approval chain on a contract
+type ContractApproval struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ContractID uint      `gorm:"not null;index" json:"contract_id"`
+	ChainID    uint      `gorm:"not null" json:"chain_id"`
+	StepID     uint      `gorm:"not null" json:"step_id"`
+	Position   int       `gorm:"not null" json:"position"`
+	StepName   string    `gorm:"not null" json:"step_name"`
+	ApproverID uint      `gorm:"not null;index" json:"approver_id"`
+	Decision   string    `gorm:"not null" json:"decision"`
+	Comment    *string   `gorm:"type:text" json:"comment"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+
+	// Associations
+	Approver User `gorm:"foreignKey:ApproverID" json:"-"`
+}
+
+// TableName specifies the table name for ContractApproval
+func (ContractApproval) TableName() string {
+	return "contract_approvals"
+}
+
+// Approval decision constants
+const (
+	ApprovalDecisionApproved = "approved"
+	ApprovalDecisionRejected = "rejected"
+)
+
+// ContractApprovalResponse is the JSON response format for contract approvals
+type ContractApprovalResponse struct {
+	ID           uint      `json:"id"`
+	ContractID   uint      `json:"contract_id"`
+	Position     int       `json:"position"`
+	StepName     string    `json:"step_name"`
+	ApproverID   uint      `json:"approver_id"`
+	ApproverName string    `json:"approver_name"`
+	Decision     string    `json:"decision"`
+	Comment      *string   `json:"comment"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ToResponse converts ContractApproval to ContractApprovalResponse
+func (a *ContractApproval) ToResponse() ContractApprovalResponse {
+	return ContractApprovalResponse{
+		ID:           a.ID,
+		ContractID:   a.ContractID,
+		Position:     a.Position,
+		StepName:     a.StepName,
+		ApproverID:   a.ApproverID,
+		ApproverName: a.Approver.FullName,
+		Decision:     a.Decision,
+		Comment:      a.Comment,
+		CreatedAt:    a.CreatedAt,
+	}
+}
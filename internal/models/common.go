@@ -26,18 +26,26 @@ func (Notification) TableName() string {
 
 // Notification type constants
 const (
-	NotificationTypeContractApproved  = "contract_approved"
-	NotificationTypeContractRejected  = "contract_rejected"
-	NotificationTypeContractCancelled = "contract_cancelled"
-	NotificationTypeContractClosed    = "contract_closed"
-	NotificationTypePaymentSubmitted  = "payment_submitted"
-	NotificationTypePaymentApproved   = "payment_approved"
-	NotificationTypePaymentRejected   = "payment_rejected"
-	NotificationTypePaymentOverdue    = "payment_overdue"
-	NotificationTypeSystem            = "system"
-	NotificationTypeLotReserved       = "lot_reserved"
-	NotificationTypeNewUser           = "create_new_user"
-	NotificationTypeSystemError       = "system_error"
+	NotificationTypeContractApproved     = "contract_approved"
+	NotificationTypeContractRejected     = "contract_rejected"
+	NotificationTypeContractCancelled    = "contract_cancelled"
+	NotificationTypeContractClosed       = "contract_closed"
+	NotificationTypePaymentSubmitted     = "payment_submitted"
+	NotificationTypePaymentApproved      = "payment_approved"
+	NotificationTypePaymentRejected      = "payment_rejected"
+	NotificationTypePaymentOverdue       = "payment_overdue"
+	NotificationTypeSystem               = "system"
+	NotificationTypeLotReserved          = "lot_reserved"
+	NotificationTypeNewUser              = "create_new_user"
+	NotificationTypeSystemError          = "system_error"
+	NotificationTypeLotHoldExpired       = "lot_hold_expired"
+	NotificationTypeWaitlistLotAvailable = "waitlist_lot_available"
+	NotificationTypeContractSubmitted    = "contract_submitted"
+	NotificationTypeApprovalPending      = "contract_approval_pending"
+	NotificationTypeContractSLA          = "contract_sla_overdue"
+	NotificationTypeFiscalRange          = "fiscal_range_alert"
+	NotificationTypeReportReady          = "report_ready"
+	NotificationTypeReportSubscription   = "report_subscription_failed"
 )
 
 // IsRead returns true if notification has been read
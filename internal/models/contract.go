@@ -17,20 +17,31 @@ type Contract struct {
 	Amount          *float64 `gorm:"type:decimal" json:"amount"`
 	Balance         *float64 `gorm:"type:decimal" json:"balance"`
 	// Commission
-	CommissionAmount float64    `json:"commission_amount" gorm:"type:decimal(15,2);default:0"`
-	DownPayment      *float64   `gorm:"type:decimal" json:"down_payment"`
-	ReserveAmount    *float64   `gorm:"type:decimal" json:"reserve_amount"`
-	MaxPaymentDate   *time.Time `gorm:"type:date" json:"max_payment_date"` // for bank/cash: date by which customer will pay the rest
-	Currency         string     `gorm:"default:HNL;not null" json:"currency"`
-	ApprovedAt       *time.Time `gorm:"index" json:"approved_at"`
-	Active           bool       `gorm:"default:false;index" json:"active"`
-	Note             *string    `gorm:"type:text" json:"note"`
-	RejectionReason  *string    `gorm:"type:text" json:"rejection_reason"`
-	TotalPaid        float64    `gorm:"-" json:"total_paid"`             // Transient field for list view
-	DocumentPaths    *string    `gorm:"type:text" json:"document_paths"` // JSON string of document paths
-	ClosedAt         *time.Time `json:"closed_at"`
-	CreatedAt        time.Time  `gorm:"index" json:"created_at"` // index for list sort and date filters
-	UpdatedAt        time.Time  `json:"updated_at"`
+	CommissionAmount  float64    `json:"commission_amount" gorm:"type:decimal(15,2);default:0"`
+	DownPayment       *float64   `gorm:"type:decimal" json:"down_payment"`
+	ReserveAmount     *float64   `gorm:"type:decimal" json:"reserve_amount"`
+	MaxPaymentDate    *time.Time `gorm:"type:date" json:"max_payment_date"` // for bank/cash: date by which customer will pay the rest
+	Currency          string     `gorm:"default:HNL;not null" json:"currency"`
+	ApprovedAt        *time.Time `gorm:"index" json:"approved_at"`
+	Active            bool       `gorm:"default:false;index" json:"active"`
+	Note              *string    `gorm:"type:text" json:"note"`
+	RejectionReason   *string    `gorm:"type:text" json:"rejection_reason"`
+	TotalPaid         float64    `gorm:"-" json:"total_paid"`             // Transient field for list view
+	DocumentPaths     *string    `gorm:"type:text" json:"document_paths"` // JSON string of document paths
+	ClosedAt          *time.Time `json:"closed_at"`
+	ClosedFromStatus  *string    `json:"closed_from_status"`         // status the contract was closed from (approved or signed); reopen returns to it
+	PriceListID       *uint      `gorm:"index" json:"price_list_id"` // price list version in effect when the contract was created
+	SubmittedAt       *time.Time `json:"submitted_at"`
+	StatusChangedAt   *time.Time `gorm:"index" json:"status_changed_at"`                                  // when the contract entered its current status
+	SLAEscalatedAt    *time.Time `json:"sla_escalated_at"`                                                // SLA escalation of the current status, if any
+	ApprovalChainID   *uint      `gorm:"index" json:"approval_chain_id"`                                  // chain assigned on submission; nil = single admin approval
+	ApprovalStep      int        `gorm:"not null;default:0" json:"approval_step"`                         // chain steps approved so far
+	ApprovalSteps     int        `gorm:"not null;default:0" json:"approval_steps"`                        // steps of the assigned chain
+	KYCOverrideReason *string    `gorm:"column:kyc_override_reason;type:text" json:"kyc_override_reason"` // admin approval without a complete KYC checklist
+	KYCOverriddenByID *uint      `gorm:"column:kyc_overridden_by_id" json:"kyc_overridden_by_id"`
+	KYCOverriddenAt   *time.Time `gorm:"column:kyc_overridden_at" json:"kyc_overridden_at"`
+	CreatedAt         time.Time  `gorm:"index" json:"created_at"` // index for list sort and date filters
+	UpdatedAt         time.Time  `json:"updated_at"`
 
 	// Associations
 	Lot           Lot                   `gorm:"foreignKey:LotID" json:"lot,omitempty"`
@@ -38,6 +49,9 @@ type Contract struct {
 	ApplicantUser User                  `gorm:"foreignKey:ApplicantUserID" json:"applicant_user,omitempty"`
 	Payments      []Payment             `gorm:"foreignKey:ContractID" json:"payments,omitempty"`
 	LedgerEntries []ContractLedgerEntry `gorm:"foreignKey:ContractID" json:"ledger_entries,omitempty"`
+	LineItems     []ContractLineItem    `gorm:"foreignKey:ContractID" json:"line_items,omitempty"`
+	PriceList     *PriceList            `gorm:"foreignKey:PriceListID" json:"price_list,omitempty"`
+	Parties       []ContractParty       `gorm:"foreignKey:ContractID" json:"parties,omitempty"`
 }
 
 // TableName specifies the table name for Contract
@@ -68,13 +82,22 @@ func (c *Contract) MaySubmit() bool {
 	return c.Status == ContractStatusPending || c.Status == ContractStatusRejected
 }
 
-// MayApprove returns true if contract can be approved
+// MayApprove returns true if contract can be approved; contracts under an approval chain only
+// once every step has approved
 func (c *Contract) MayApprove() bool {
+	if c.ApprovalChainID != nil && !c.ApprovalChainComplete() {
+		return false
+	}
 	return c.Status == ContractStatusPending ||
 		c.Status == ContractStatusSubmitted ||
 		c.Status == ContractStatusRejected
 }
 
+// ApprovalChainComplete returns true when no approval chain step is left
+func (c *Contract) ApprovalChainComplete() bool {
+	return c.ApprovalStep >= c.ApprovalSteps
+}
+
 // MayReject returns true if contract can be rejected
 func (c *Contract) MayReject() bool {
 	return c.Status == ContractStatusPending || c.Status == ContractStatusSubmitted
@@ -87,9 +110,43 @@ func (c *Contract) MayCancel() bool {
 		c.Status == ContractStatusRejected
 }
 
+// MayChangeParties returns true while co-buyers, guarantors and representatives can still change
+func (c *Contract) MayChangeParties() bool {
+	return c.Status == ContractStatusPending ||
+		c.Status == ContractStatusSubmitted ||
+		c.Status == ContractStatusRejected
+}
+
+// IsOwnedBy returns true if the user is the applicant or a registered co-buyer of the contract
+func (c *Contract) IsOwnedBy(userID uint) bool {
+	if c.ApplicantUserID == userID {
+		return true
+	}
+	for _, party := range c.Parties {
+		if party.Role == PartyRoleCoBuyer && party.UserID != nil && *party.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ContractInForceStatuses are the statuses of approved contracts being paid: signed once the
+// promise contract is signed electronically
+var ContractInForceStatuses = []string{ContractStatusApproved, ContractStatusSigned}
+
+// InForce returns true for approved contracts, whether or not their promise is signed
+func (c *Contract) InForce() bool {
+	return c.Status == ContractStatusApproved || c.Status == ContractStatusSigned
+}
+
+// MaySign returns true if the promise contract can be signed
+func (c *Contract) MaySign() bool {
+	return c.Status == ContractStatusApproved
+}
+
 // MayClose returns true if contract can be closed
 func (c *Contract) MayClose() bool {
-	if c.Status != ContractStatusApproved {
+	if !c.InForce() {
 		return false
 	}
 	if c.Balance == nil {
@@ -154,6 +211,8 @@ type ContractResponse struct {
 	ApplicantPhone         string                        `json:"applicant_phone"`
 	ApplicantIdentity      string                        `json:"applicant_identity"`
 	ApplicantCreditScore   int                           `json:"applicant_credit_score"`
+	ApplicantOwnership     float64                       `json:"applicant_ownership_percentage"`
+	Parties                []ContractPartyResponse       `json:"parties"`
 	CreatedBy              string                        `json:"created_by"`
 	Amount                 *float64                      `json:"amount"`
 	PaymentTerm            int                           `json:"payment_term"`
@@ -173,6 +232,17 @@ type ContractResponse struct {
 	Note                   *string                       `json:"note"`
 	PaymentSchedule        []PaymentResponse             `json:"payment_schedule"`
 	LedgerEntries          []ContractLedgerEntryResponse `json:"ledger_entries"`
+	LineItems              []ContractLineItemResponse    `json:"line_items"`
+	PriceListID            *uint                         `json:"price_list_id"`
+	PriceListVersion       *int                          `json:"price_list_version"`
+	SubmittedAt            *time.Time                    `json:"submitted_at"`
+	StatusChangedAt        *time.Time                    `json:"status_changed_at"`
+	ApprovalChainID        *uint                         `json:"approval_chain_id"`
+	ApprovalStep           int                           `json:"approval_step"`
+	ApprovalSteps          int                           `json:"approval_steps"`
+	KYCOverrideReason      *string                       `json:"kyc_override_reason"`
+	KYCOverriddenAt        *time.Time                    `json:"kyc_overridden_at"`
+	KYC                    *KYCChecklist                 `json:"kyc,omitempty"` // set on the contract detail
 }
 
 // ToResponse converts Contract to ContractResponse
@@ -193,6 +263,13 @@ func (c *Contract) ToResponse() ContractResponse {
 		RejectionReason:   c.RejectionReason,
 		CancellationNotes: c.Note,
 		ApprovedAt:        c.ApprovedAt,
+		SubmittedAt:       c.SubmittedAt,
+		StatusChangedAt:   c.StatusChangedAt,
+		ApprovalChainID:   c.ApprovalChainID,
+		ApprovalStep:      c.ApprovalStep,
+		ApprovalSteps:     c.ApprovalSteps,
+		KYCOverrideReason: c.KYCOverrideReason,
+		KYCOverriddenAt:   c.KYCOverriddenAt,
 		CreatedAt:         c.CreatedAt,
 		UpdatedAt:         c.UpdatedAt,
 		Note:              c.Note,
@@ -218,6 +295,13 @@ func (c *Contract) ToResponse() ContractResponse {
 	resp.ApplicantPhone = c.ApplicantUser.Phone
 	resp.ApplicantIdentity = maskIdentity(c.ApplicantUser.Identity)
 	resp.ApplicantCreditScore = c.ApplicantUser.CreditScore
+	resp.ApplicantOwnership = c.ApplicantOwnershipPercentage()
+
+	// Add co-buyers, guarantors and legal representatives
+	resp.Parties = []ContractPartyResponse{}
+	for _, party := range WithOwnership(c.Parties) {
+		resp.Parties = append(resp.Parties, party.ToResponse())
+	}
 
 	// Add creator info
 	if c.Creator != nil {
@@ -260,6 +344,17 @@ func (c *Contract) ToResponse() ContractResponse {
 		resp.LedgerEntries = append(resp.LedgerEntries, entry.ToResponse())
 	}
 
+	// Add price list version
+	resp.PriceListID = c.PriceListID
+	if c.PriceList != nil {
+		resp.PriceListVersion = &c.PriceList.Version
+	}
+
+	// Add price breakdown
+	for _, item := range c.LineItems {
+		resp.LineItems = append(resp.LineItems, item.ToResponse())
+	}
+
 	return resp
 }
 
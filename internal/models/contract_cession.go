@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+)
+
+// ContractCession records the transfer (cesión de derechos) of a contract from its applicant to a
+// new buyer. The contract keeps its payments and ledger; the cession keeps who held it before,
+// what had been paid and the balance at the moment of the transfer.
+type ContractCession struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ContractID       uint      `gorm:"not null;index" json:"contract_id"`
+	FromUserID       uint      `gorm:"not null;index" json:"from_user_id"`
+	ToUserID         uint      `gorm:"not null;index" json:"to_user_id"`
+	ActorID          uint      `gorm:"not null" json:"actor_id"`
+	PaidAtCession    float64   `gorm:"type:decimal(15,2);not null;default:0" json:"paid_at_cession"`
+	BalanceAtCession float64   `gorm:"type:decimal(15,2);not null;default:0" json:"balance_at_cession"`
+	TransferFee      *float64  `gorm:"type:decimal(15,2)" json:"transfer_fee"`
+	FeePaymentID     *uint     `json:"fee_payment_id"`
+	Note             *string   `gorm:"type:text" json:"note"`
+	CreatedAt        time.Time `gorm:"index" json:"created_at"`
+
+	// Associations
+	Contract *Contract `gorm:"foreignKey:ContractID" json:"-"`
+	FromUser User      `gorm:"foreignKey:FromUserID" json:"-"`
+	ToUser   User      `gorm:"foreignKey:ToUserID" json:"-"`
+	Actor    User      `gorm:"foreignKey:ActorID" json:"-"`
+}
+
+// TableName specifies the table name for ContractCession
+func (ContractCession) TableName() string {
+	return "contract_cessions"
+}
+
+// ContractCessionResponse is the JSON response format for contract cessions
+type ContractCessionResponse struct {
+	ID               uint      `json:"id"`
+	ContractID       uint      `json:"contract_id"`
+	FromUserID       uint      `json:"from_user_id"`
+	FromUserName     string    `json:"from_user_name"`
+	ToUserID         uint      `json:"to_user_id"`
+	ToUserName       string    `json:"to_user_name"`
+	ActorID          uint      `json:"actor_id"`
+	ActorName        string    `json:"actor_name"`
+	PaidAtCession    float64   `json:"paid_at_cession"`
+	BalanceAtCession float64   `json:"balance_at_cession"`
+	TransferFee      *float64  `json:"transfer_fee"`
+	FeePaymentID     *uint     `json:"fee_payment_id"`
+	Note             *string   `json:"note"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ToResponse converts ContractCession to ContractCessionResponse
+func (c *ContractCession) ToResponse() ContractCessionResponse {
+	return ContractCessionResponse{
+		ID:               c.ID,
+		ContractID:       c.ContractID,
+		FromUserID:       c.FromUserID,
+		FromUserName:     c.FromUser.FullName,
+		ToUserID:         c.ToUserID,
+		ToUserName:       c.ToUser.FullName,
+		ActorID:          c.ActorID,
+		ActorName:        c.Actor.FullName,
+		PaidAtCession:    c.PaidAtCession,
+		BalanceAtCession: c.BalanceAtCession,
+		TransferFee:      c.TransferFee,
+		FeePaymentID:     c.FeePaymentID,
+		Note:             c.Note,
+		CreatedAt:        c.CreatedAt,
+	}
+}
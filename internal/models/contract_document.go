@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+)
+
+// Contract document type constants
+const (
+	DocumentTypeIDCopy          = "id_copy"
+	DocumentTypeProofOfIncome   = "proof_of_income"
+	DocumentTypeSignedPromise   = "signed_promise"
+	DocumentTypeDeed            = "deed"
+	DocumentTypeBankPreapproval = "bank_preapproval"
+	DocumentTypeOther           = "other"
+)
+
+// DocumentTypes are the valid contract document types
+var DocumentTypes = []string{DocumentTypeIDCopy, DocumentTypeProofOfIncome, DocumentTypeSignedPromise, DocumentTypeDeed, DocumentTypeBankPreapproval, DocumentTypeOther}
+
+// IsValidDocumentType returns true if the document type is known
+func IsValidDocumentType(docType string) bool {
+	for _, t := range DocumentTypes {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomerDocumentType returns true for the document types the buyer may upload themselves;
+// signed promises and deeds are only attached by admins and sellers
+func CustomerDocumentType(docType string) bool {
+	switch docType {
+	case DocumentTypeIDCopy, DocumentTypeProofOfIncome, DocumentTypeBankPreapproval, DocumentTypeOther:
+		return true
+	}
+	return false
+}
+
+// ContractDocument is a file attached to a contract. Replacing a document adds a new version with
+// the same LineageID (the ID of the first version) and marks the previous one as superseded;
+// deleting is soft and keeps the files.
+type ContractDocument struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ContractID   uint       `gorm:"not null;index" json:"contract_id"`
+	LineageID    uint       `gorm:"index" json:"lineage_id"`
+	Version      int        `gorm:"not null;default:1" json:"version"`
+	DocumentType string     `gorm:"not null" json:"document_type"`
+	FileName     string     `gorm:"not null" json:"file_name"`
+	ContentType  string     `gorm:"not null" json:"content_type"`
+	Size         int64      `gorm:"not null" json:"size"`
+	Path         string     `gorm:"not null" json:"-"`
+	SHA256       string     `gorm:"column:sha256;not null" json:"sha256"`
+	UploadedByID uint       `gorm:"not null" json:"uploaded_by_id"`
+	SupersededAt *time.Time `json:"superseded_at"`
+	DeletedAt    *time.Time `gorm:"index" json:"deleted_at"`
+	DeletedByID  *uint      `json:"deleted_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	// Associations
+	UploadedBy User `gorm:"foreignKey:UploadedByID" json:"-"`
+}
+
+// TableName specifies the table name for ContractDocument
+func (ContractDocument) TableName() string {
+	return "contract_documents"
+}
+
+// IsCurrent returns true for the live version of a document
+func (d *ContractDocument) IsCurrent() bool {
+	return d.SupersededAt == nil && d.DeletedAt == nil
+}
+
+// ContractDocumentResponse is the API response for a contract document
+type ContractDocumentResponse struct {
+	ID             uint       `json:"id"`
+	ContractID     uint       `json:"contract_id"`
+	LineageID      uint       `json:"lineage_id"`
+	Version        int        `json:"version"`
+	DocumentType   string     `json:"document_type"`
+	FileName       string     `json:"file_name"`
+	ContentType    string     `json:"content_type"`
+	Size           int64      `json:"size"`
+	SHA256         string     `json:"sha256"`
+	UploadedByID   uint       `json:"uploaded_by_id"`
+	UploadedByName string     `json:"uploaded_by_name"`
+	Current        bool       `json:"current"`
+	SupersededAt   *time.Time `json:"superseded_at"`
+	DeletedAt      *time.Time `json:"deleted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ToResponse converts ContractDocument to ContractDocumentResponse
+func (d *ContractDocument) ToResponse() ContractDocumentResponse {
+	return ContractDocumentResponse{
+		ID:             d.ID,
+		ContractID:     d.ContractID,
+		LineageID:      d.LineageID,
+		Version:        d.Version,
+		DocumentType:   d.DocumentType,
+		FileName:       d.FileName,
+		ContentType:    d.ContentType,
+		Size:           d.Size,
+		SHA256:         d.SHA256,
+		UploadedByID:   d.UploadedByID,
+		UploadedByName: d.UploadedBy.FullName,
+		Current:        d.IsCurrent(),
+		SupersededAt:   d.SupersededAt,
+		DeletedAt:      d.DeletedAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"time"
+)
+
+// ContractStatusTransition records a contract status transition made by the contract FSM
+type ContractStatusTransition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ContractID uint      `gorm:"not null;index" json:"contract_id"`
+	FromStatus string    `gorm:"not null" json:"from_status"`
+	ToStatus   string    `gorm:"not null" json:"to_status"`
+	Event      string    `gorm:"not null" json:"event"`
+	ActorID    *uint     `json:"actor_id"` // nil = system (scheduled jobs, automatic closing)
+	Reason     *string   `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Associations
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+// TableName specifies the table name for ContractStatusTransition
+func (ContractStatusTransition) TableName() string {
+	return "contract_status_transitions"
+}
+
+// ContractStatusTransitionResponse is the API response for a contract status transition
+type ContractStatusTransitionResponse struct {
+	ID         uint      `json:"id"`
+	ContractID uint      `json:"contract_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Event      string    `json:"event"`
+	ActorID    *uint     `json:"actor_id"`
+	ActorName  string    `json:"actor_name"`
+	Reason     *string   `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ToResponse converts ContractStatusTransition to ContractStatusTransitionResponse
+func (t *ContractStatusTransition) ToResponse() ContractStatusTransitionResponse {
+	resp := ContractStatusTransitionResponse{
+		ID:         t.ID,
+		ContractID: t.ContractID,
+		FromStatus: t.FromStatus,
+		ToStatus:   t.ToStatus,
+		Event:      t.Event,
+		ActorID:    t.ActorID,
+		Reason:     t.Reason,
+		CreatedAt:  t.CreatedAt,
+	}
+	if t.Actor != nil {
+		resp.ActorName = t.Actor.FullName
+	}
+	return resp
+}
+
+// Terms version sources
+const (
+	TermsSourceCreate    = "create"
+	TermsSourceUpdate    = "update"
+	TermsSourceLotChange = "lot_change"
+	TermsSourceCession   = "cession"
+	TermsSourceBackfill  = "backfill" // snapshot of contracts created before versioning
+)
+
+// ContractTermsVersion is a snapshot of the financial terms of a contract, taken when the contract
+// is created and on every update or amendment. Versions are numbered from 1 per contract.
+type ContractTermsVersion struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	ContractID       uint       `gorm:"not null;index" json:"contract_id"`
+	Version          int        `gorm:"not null" json:"version"`
+	Source           string     `gorm:"not null" json:"source"`
+	ActorID          *uint      `json:"actor_id"`
+	Reason           *string    `gorm:"type:text" json:"reason"`
+	LotID            uint       `gorm:"not null" json:"lot_id"`
+	ApplicantUserID  uint       `gorm:"not null" json:"applicant_user_id"`
+	FinancingType    string     `gorm:"not null" json:"financing_type"`
+	Currency         string     `gorm:"not null" json:"currency"`
+	PaymentTerm      int        `gorm:"not null" json:"payment_term"`
+	Amount           *float64   `gorm:"type:decimal(15,2)" json:"amount"`
+	DownPayment      *float64   `gorm:"type:decimal(15,2)" json:"down_payment"`
+	ReserveAmount    *float64   `gorm:"type:decimal(15,2)" json:"reserve_amount"`
+	CommissionAmount float64    `gorm:"type:decimal(15,2);default:0" json:"commission_amount"`
+	MaxPaymentDate   *time.Time `gorm:"type:date" json:"max_payment_date"`
+	CreatedAt        time.Time  `json:"created_at"`
+
+	// Associations
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+// TableName specifies the table name for ContractTermsVersion
+func (ContractTermsVersion) TableName() string {
+	return "contract_terms_versions"
+}
+
+// ContractTermsVersionResponse is the API response for a contract terms version
+type ContractTermsVersionResponse struct {
+	ID               uint       `json:"id"`
+	ContractID       uint       `json:"contract_id"`
+	Version          int        `json:"version"`
+	Source           string     `json:"source"`
+	ActorID          *uint      `json:"actor_id"`
+	ActorName        string     `json:"actor_name"`
+	Reason           *string    `json:"reason"`
+	LotID            uint       `json:"lot_id"`
+	ApplicantUserID  uint       `json:"applicant_user_id"`
+	FinancingType    string     `json:"financing_type"`
+	Currency         string     `json:"currency"`
+	PaymentTerm      int        `json:"payment_term"`
+	Amount           *float64   `json:"amount"`
+	DownPayment      *float64   `json:"down_payment"`
+	ReserveAmount    *float64   `json:"reserve_amount"`
+	CommissionAmount float64    `json:"commission_amount"`
+	MaxPaymentDate   *time.Time `json:"max_payment_date"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ToResponse converts ContractTermsVersion to ContractTermsVersionResponse
+func (v *ContractTermsVersion) ToResponse() ContractTermsVersionResponse {
+	resp := ContractTermsVersionResponse{
+		ID:               v.ID,
+		ContractID:       v.ContractID,
+		Version:          v.Version,
+		Source:           v.Source,
+		ActorID:          v.ActorID,
+		Reason:           v.Reason,
+		LotID:            v.LotID,
+		ApplicantUserID:  v.ApplicantUserID,
+		FinancingType:    v.FinancingType,
+		Currency:         v.Currency,
+		PaymentTerm:      v.PaymentTerm,
+		Amount:           v.Amount,
+		DownPayment:      v.DownPayment,
+		ReserveAmount:    v.ReserveAmount,
+		CommissionAmount: v.CommissionAmount,
+		MaxPaymentDate:   v.MaxPaymentDate,
+		CreatedAt:        v.CreatedAt,
+	}
+	if v.Actor != nil {
+		resp.ActorName = v.Actor.FullName
+	}
+	return resp
+}
+
+// TermsSnapshot returns the current financial terms of the contract as an unnumbered version
+func (c *Contract) TermsSnapshot() ContractTermsVersion {
+	return ContractTermsVersion{
+		ContractID:       c.ID,
+		LotID:            c.LotID,
+		ApplicantUserID:  c.ApplicantUserID,
+		FinancingType:    c.FinancingType,
+		Currency:         c.Currency,
+		PaymentTerm:      c.PaymentTerm,
+		Amount:           c.Amount,
+		DownPayment:      c.DownPayment,
+		ReserveAmount:    c.ReserveAmount,
+		CommissionAmount: c.CommissionAmount,
+		MaxPaymentDate:   c.MaxPaymentDate,
+	}
+}
+
+// TermChange is a financial term that differs between two versions
+type TermChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffTerms returns the terms that changed from one version to another
+func DiffTerms(from, to *ContractTermsVersion) []TermChange {
+	changes := []TermChange{}
+	add := func(field string, a, b interface{}, equal bool) {
+		if !equal {
+			changes = append(changes, TermChange{Field: field, From: a, To: b})
+		}
+	}
+	add("lot_id", from.LotID, to.LotID, from.LotID == to.LotID)
+	add("applicant_user_id", from.ApplicantUserID, to.ApplicantUserID, from.ApplicantUserID == to.ApplicantUserID)
+	add("financing_type", from.FinancingType, to.FinancingType, from.FinancingType == to.FinancingType)
+	add("currency", from.Currency, to.Currency, from.Currency == to.Currency)
+	add("payment_term", from.PaymentTerm, to.PaymentTerm, from.PaymentTerm == to.PaymentTerm)
+	add("amount", from.Amount, to.Amount, equalAmounts(from.Amount, to.Amount))
+	add("down_payment", from.DownPayment, to.DownPayment, equalAmounts(from.DownPayment, to.DownPayment))
+	add("reserve_amount", from.ReserveAmount, to.ReserveAmount, equalAmounts(from.ReserveAmount, to.ReserveAmount))
+	add("commission_amount", from.CommissionAmount, to.CommissionAmount, equalAmounts(&from.CommissionAmount, &to.CommissionAmount))
+	add("max_payment_date", from.MaxPaymentDate, to.MaxPaymentDate, equalDates(from.MaxPaymentDate, to.MaxPaymentDate))
+	return changes
+}
+
+// equalAmounts compares optional amounts to the cent
+func equalAmounts(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	diff := *a - *b
+	return diff < 0.005 && diff > -0.005
+}
+
+// equalDates compares optional dates by calendar day
+func equalDates(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
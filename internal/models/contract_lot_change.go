@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// ContractLotChange records moving an approved contract to another lot of the same project. The
+// price difference is posted to the ledger as an adjustment and the remaining schedule is
+// resized to the new balance.
+type ContractLotChange struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ContractID      uint      `gorm:"not null;index" json:"contract_id"`
+	FromLotID       uint      `gorm:"not null" json:"from_lot_id"`
+	ToLotID         uint      `gorm:"not null" json:"to_lot_id"`
+	OldAmount       float64   `gorm:"type:decimal(15,2);not null" json:"old_amount"`
+	NewAmount       float64   `gorm:"type:decimal(15,2);not null" json:"new_amount"`
+	PriceDifference float64   `gorm:"type:decimal(15,2);not null" json:"price_difference"` // positive when the new lot is more expensive
+	LedgerEntryID   *uint     `json:"ledger_entry_id"`
+	ActorID         uint      `gorm:"not null" json:"actor_id"`
+	Reason          *string   `gorm:"type:text" json:"reason"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+
+	// Associations
+	FromLot Lot  `gorm:"foreignKey:FromLotID" json:"-"`
+	ToLot   Lot  `gorm:"foreignKey:ToLotID" json:"-"`
+	Actor   User `gorm:"foreignKey:ActorID" json:"-"`
+}
+
+// TableName specifies the table name for ContractLotChange
+func (ContractLotChange) TableName() string {
+	return "contract_lot_changes"
+}
+
+// ContractLotChangeResponse is the JSON response format for contract lot changes
+type ContractLotChangeResponse struct {
+	ID              uint      `json:"id"`
+	ContractID      uint      `json:"contract_id"`
+	FromLotID       uint      `json:"from_lot_id"`
+	FromLotName     string    `json:"from_lot_name"`
+	ToLotID         uint      `json:"to_lot_id"`
+	ToLotName       string    `json:"to_lot_name"`
+	OldAmount       float64   `json:"old_amount"`
+	NewAmount       float64   `json:"new_amount"`
+	PriceDifference float64   `json:"price_difference"`
+	ActorName       string    `json:"actor_name"`
+	Reason          *string   `json:"reason"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ToResponse converts ContractLotChange to ContractLotChangeResponse
+func (c *ContractLotChange) ToResponse() ContractLotChangeResponse {
+	return ContractLotChangeResponse{
+		ID:              c.ID,
+		ContractID:      c.ContractID,
+		FromLotID:       c.FromLotID,
+		FromLotName:     c.FromLot.Name,
+		ToLotID:         c.ToLotID,
+		ToLotName:       c.ToLot.Name,
+		OldAmount:       c.OldAmount,
+		NewAmount:       c.NewAmount,
+		PriceDifference: c.PriceDifference,
+		ActorName:       c.Actor.FullName,
+		Reason:          c.Reason,
+		CreatedAt:       c.CreatedAt,
+	}
+}
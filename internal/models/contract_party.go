@@ -0,0 +1,211 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ContractParty is an additional party of a contract besides the applicant: a co-buyer who shares
+// ownership of the lot, a guarantor (fiador) who answers for the debt, or a legal representative
+// who signs on behalf of the applicant. Parties may be registered users or plain data.
+type ContractParty struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	ContractID          uint      `gorm:"not null;index" json:"contract_id"`
+	UserID              *uint     `gorm:"index" json:"user_id"`
+	Role                string    `gorm:"not null" json:"role"`
+	FullName            string    `gorm:"not null" json:"full_name"`
+	Identity            string    `json:"identity"`
+	Phone               string    `json:"phone"`
+	Email               string    `json:"email"`
+	OwnershipPercentage *float64  `gorm:"type:decimal(5,2)" json:"ownership_percentage"` // co-buyers only
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+
+	// Associations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for ContractParty
+func (ContractParty) TableName() string {
+	return "contract_parties"
+}
+
+// Contract party role constants
+const (
+	PartyRoleCoBuyer             = "co_buyer"
+	PartyRoleGuarantor           = "guarantor"
+	PartyRoleLegalRepresentative = "legal_representative"
+)
+
+// PartyRoleLabels are the Spanish names of the party roles used in documents and notifications
+var PartyRoleLabels = map[string]string{
+	PartyRoleCoBuyer:             "Co-comprador",
+	PartyRoleGuarantor:           "Fiador",
+	PartyRoleLegalRepresentative: "Representante legal",
+}
+
+// RoleLabel returns the Spanish name of the party's role
+func (p *ContractParty) RoleLabel() string {
+	if label, ok := PartyRoleLabels[p.Role]; ok {
+		return label
+	}
+	return p.Role
+}
+
+// ContactEmail returns the party's email, falling back to its user's
+func (p *ContractParty) ContactEmail() string {
+	if p.Email != "" {
+		return p.Email
+	}
+	if p.User != nil {
+		return p.User.Email
+	}
+	return ""
+}
+
+// PartiesWithRole returns the contract parties with the given role
+func (c *Contract) PartiesWithRole(role string) []ContractParty {
+	var parties []ContractParty
+	for _, p := range c.Parties {
+		if p.Role == role {
+			parties = append(parties, p)
+		}
+	}
+	return parties
+}
+
+// ApplicantOwnershipPercentage returns the applicant's share of the lot: what the co-buyers do not own
+func (c *Contract) ApplicantOwnershipPercentage() float64 {
+	owned := 0.0
+	for _, p := range WithOwnership(c.Parties) {
+		if p.Role != PartyRoleCoBuyer {
+			continue
+		}
+		if p.OwnershipPercentage != nil {
+			owned += *p.OwnershipPercentage
+		}
+	}
+	return math.Round((100-owned)*100) / 100
+}
+
+// BuyerNames returns the applicant and co-buyer names joined for documents and notifications
+func (c *Contract) BuyerNames() string {
+	names := []string{c.ApplicantUser.FullName}
+	for _, p := range c.PartiesWithRole(PartyRoleCoBuyer) {
+		names = append(names, p.FullName)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ValidateContractParties checks the roles and names of the parties and the co-buyers'
+// ownership. Either no co-buyer has a percentage (equal split, see WithOwnership) or every
+// co-buyer has one and together they leave a share to the applicant.
+func ValidateContractParties(parties []ContractParty) error {
+	var coBuyers []*ContractParty
+	withPercentage := 0
+	total := 0.0
+	for i := range parties {
+		p := &parties[i]
+		p.FullName = strings.TrimSpace(p.FullName)
+		if _, ok := PartyRoleLabels[p.Role]; !ok {
+			return fmt.Errorf("rol de participante inválido: %s", p.Role)
+		}
+		if p.FullName == "" {
+			return errors.New("el nombre del participante es requerido")
+		}
+		if p.Role != PartyRoleCoBuyer {
+			if p.OwnershipPercentage != nil && *p.OwnershipPercentage != 0 {
+				return errors.New("solo los co-compradores pueden tener porcentaje de propiedad")
+			}
+			p.OwnershipPercentage = nil
+			continue
+		}
+		coBuyers = append(coBuyers, p)
+		if p.OwnershipPercentage != nil {
+			if *p.OwnershipPercentage <= 0 {
+				return errors.New("el porcentaje de propiedad debe ser mayor a cero")
+			}
+			withPercentage++
+			total += *p.OwnershipPercentage
+		}
+	}
+
+	if withPercentage == 0 {
+		return nil
+	}
+	if withPercentage != len(coBuyers) {
+		return errors.New("indique el porcentaje de propiedad de todos los co-compradores")
+	}
+	if total >= 100 {
+		return errors.New("los porcentajes de los co-compradores deben sumar menos de 100; el resto corresponde al solicitante")
+	}
+	return nil
+}
+
+// WithOwnership returns a copy of the parties with the implicit co-buyer shares filled in. When no
+// co-buyer has a percentage the lot is split equally between the applicant and the co-buyers (the
+// applicant keeps the rounding remainder); those shares are stored as NULL and derived here so they
+// follow the co-buyers added or removed later.
+func WithOwnership(parties []ContractParty) []ContractParty {
+	resolved := append([]ContractParty(nil), parties...)
+	coBuyers := 0
+	for _, p := range resolved {
+		if p.Role != PartyRoleCoBuyer {
+			continue
+		}
+		if p.OwnershipPercentage != nil {
+			return resolved
+		}
+		coBuyers++
+	}
+	if coBuyers == 0 {
+		return resolved
+	}
+	share := math.Floor(100/float64(coBuyers+1)*100) / 100
+	for i := range resolved {
+		if resolved[i].Role == PartyRoleCoBuyer {
+			s := share
+			resolved[i].OwnershipPercentage = &s
+		}
+	}
+	return resolved
+}
+
+// ContractPartyResponse is the JSON response format for contract parties
+type ContractPartyResponse struct {
+	ID                  uint     `json:"id"`
+	ContractID          uint     `json:"contract_id"`
+	UserID              *uint    `json:"user_id"`
+	Role                string   `json:"role"`
+	RoleLabel           string   `json:"role_label"`
+	FullName            string   `json:"full_name"`
+	Identity            string   `json:"identity"`
+	Phone               string   `json:"phone"`
+	Email               string   `json:"email"`
+	OwnershipPercentage *float64 `json:"ownership_percentage"`
+	CreditScore         *int     `json:"credit_score,omitempty"`
+}
+
+// ToResponse converts ContractParty to ContractPartyResponse
+func (p *ContractParty) ToResponse() ContractPartyResponse {
+	resp := ContractPartyResponse{
+		ID:                  p.ID,
+		ContractID:          p.ContractID,
+		UserID:              p.UserID,
+		Role:                p.Role,
+		RoleLabel:           p.RoleLabel(),
+		FullName:            p.FullName,
+		Identity:            maskIdentity(p.Identity),
+		Phone:               p.Phone,
+		Email:               p.ContactEmail(),
+		OwnershipPercentage: p.OwnershipPercentage,
+	}
+	if p.User != nil {
+		score := p.User.CreditScore
+		resp.CreditScore = &score
+	}
+	return resp
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signature request status constants
+const (
+	SignatureStatusPending   = "pending"
+	SignatureStatusCompleted = "completed"
+	SignatureStatusCancelled = "cancelled"
+)
+
+// Signer role constants: the buyer and an admin signing for the company
+const (
+	SignerRoleBuyer = "buyer"
+	SignerRoleAdmin = "admin"
+)
+
+// SignatureRequest is the electronic signature of the promise contract of a contract. The unsigned
+// PDF is hashed when the request starts; every signature extends a hash chain from that hash, and
+// the signed PDF closes it.
+type SignatureRequest struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	ContractID       uint       `gorm:"not null;index" json:"contract_id"`
+	Status           string     `gorm:"not null;default:pending" json:"status"`
+	DocumentPath     string     `gorm:"not null" json:"-"`
+	DocumentSHA256   string     `gorm:"column:document_sha256;not null" json:"document_sha256"`
+	SignedDocumentID *uint      `json:"signed_document_id"` // ContractDocument of type signed_promise
+	SignedSHA256     *string    `gorm:"column:signed_sha256" json:"signed_sha256"`
+	ChainHash        *string    `json:"chain_hash"` // last link of the hash chain, set on completion
+	CreatedByID      uint       `gorm:"not null" json:"created_by_id"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	CancelledAt      *time.Time `json:"cancelled_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Associations
+	Signers []ContractSigner `gorm:"foreignKey:RequestID" json:"signers"`
+}
+
+// TableName specifies the table name for SignatureRequest
+func (SignatureRequest) TableName() string {
+	return "contract_signature_requests"
+}
+
+// Signer returns the signer with the role, if any
+func (r *SignatureRequest) Signer(role string) *ContractSigner {
+	for i := range r.Signers {
+		if r.Signers[i].Role == role {
+			return &r.Signers[i]
+		}
+	}
+	return nil
+}
+
+// AllSigned returns true once every signer signed
+func (r *SignatureRequest) AllSigned() bool {
+	for _, s := range r.Signers {
+		if s.SignedAt == nil {
+			return false
+		}
+	}
+	return len(r.Signers) > 0
+}
+
+// LastHash returns the head of the hash chain so far: the hash of the last signature, or the hash
+// of the unsigned document
+func (r *SignatureRequest) LastHash() string {
+	last := r.DocumentSHA256
+	var lastAt time.Time
+	for _, s := range r.Signers {
+		if s.Hash != nil && s.SignedAt != nil && !s.SignedAt.Before(lastAt) {
+			last, lastAt = *s.Hash, *s.SignedAt
+		}
+	}
+	return last
+}
+
+// ContractSigner is a party signing a promise contract: a drawn signature confirmed with a one-time
+// code sent by email. The admin signer is whichever admin signs first.
+type ContractSigner struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	RequestID       uint       `gorm:"not null;index" json:"request_id"`
+	Role            string     `gorm:"not null" json:"role"`
+	UserID          *uint      `json:"user_id"`
+	OTPHash         *string    `gorm:"column:otp_hash" json:"-"`
+	OTPExpiresAt    *time.Time `gorm:"column:otp_expires_at" json:"-"`
+	OTPAttempts     int        `gorm:"column:otp_attempts;not null;default:0" json:"-"`
+	SignaturePath   *string    `json:"-"`
+	SignatureSHA256 *string    `gorm:"column:signature_sha256" json:"signature_sha256"`
+	SignedAt        *time.Time `json:"signed_at"`
+	IPAddress       *string    `json:"ip_address"`
+	UserAgent       *string    `json:"user_agent"`
+	PrevHash        *string    `json:"prev_hash"`
+	Hash            *string    `json:"hash"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Associations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for ContractSigner
+func (ContractSigner) TableName() string {
+	return "contract_signers"
+}
+
+// SignatureLinkHash returns the hash chain link of a signature: the previous link, the signer and
+// the signed image, so changing any of them (or their order) breaks every later link
+func SignatureLinkHash(prevHash, role string, userID uint, signatureSHA256 string, signedAt time.Time) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		prevHash, role, fmt.Sprint(userID), signatureSHA256, signedAt.UTC().Format(time.RFC3339Nano),
+	}, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// SignatureChainHash returns the last link of the chain, binding the signed document to the signatures
+func SignatureChainHash(lastSignatureHash, signedSHA256 string) string {
+	sum := sha256.Sum256([]byte(lastSignatureHash + "|" + signedSHA256))
+	return hex.EncodeToString(sum[:])
+}
+
+// SignatureRequestResponse is the API response for a signature request
+type SignatureRequestResponse struct {
+	ID               uint                     `json:"id"`
+	ContractID       uint                     `json:"contract_id"`
+	Status           string                   `json:"status"`
+	DocumentSHA256   string                   `json:"document_sha256"`
+	SignedDocumentID *uint                    `json:"signed_document_id"`
+	SignedSHA256     *string                  `json:"signed_sha256"`
+	ChainHash        *string                  `json:"chain_hash"`
+	CompletedAt      *time.Time               `json:"completed_at"`
+	CancelledAt      *time.Time               `json:"cancelled_at"`
+	CreatedAt        time.Time                `json:"created_at"`
+	Signers          []ContractSignerResponse `json:"signers"`
+}
+
+// ContractSignerResponse is the API response for a signer
+type ContractSignerResponse struct {
+	Role            string     `json:"role"`
+	UserID          *uint      `json:"user_id"`
+	UserName        string     `json:"user_name"`
+	Signed          bool       `json:"signed"`
+	SignedAt        *time.Time `json:"signed_at"`
+	SignatureSHA256 *string    `json:"signature_sha256"`
+	PrevHash        *string    `json:"prev_hash"`
+	Hash            *string    `json:"hash"`
+}
+
+// ToResponse converts SignatureRequest to SignatureRequestResponse
+func (r *SignatureRequest) ToResponse() SignatureRequestResponse {
+	resp := SignatureRequestResponse{
+		ID:               r.ID,
+		ContractID:       r.ContractID,
+		Status:           r.Status,
+		DocumentSHA256:   r.DocumentSHA256,
+		SignedDocumentID: r.SignedDocumentID,
+		SignedSHA256:     r.SignedSHA256,
+		ChainHash:        r.ChainHash,
+		CompletedAt:      r.CompletedAt,
+		CancelledAt:      r.CancelledAt,
+		CreatedAt:        r.CreatedAt,
+		Signers:          make([]ContractSignerResponse, len(r.Signers)),
+	}
+	for i, s := range r.Signers {
+		signer := ContractSignerResponse{
+			Role:            s.Role,
+			UserID:          s.UserID,
+			Signed:          s.SignedAt != nil,
+			SignedAt:        s.SignedAt,
+			SignatureSHA256: s.SignatureSHA256,
+			PrevHash:        s.PrevHash,
+			Hash:            s.Hash,
+		}
+		if s.User != nil {
+			signer.UserName = s.User.FullName
+		}
+		resp.Signers[i] = signer
+	}
+	return resp
+}
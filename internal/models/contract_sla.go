@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// ContractStatusSLA is the maximum time a contract may stay in a status before it is escalated
+// to the admins. Rules with a project override the default rule (ProjectID nil) for that status.
+type ContractStatusSLA struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProjectID *uint     `gorm:"index" json:"project_id"` // nil = all projects
+	Status    string    `gorm:"not null" json:"status"`
+	MaxHours  int       `gorm:"not null" json:"max_hours"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Associations
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
+
+// TableName specifies the table name for ContractStatusSLA
+func (ContractStatusSLA) TableName() string {
+	return "contract_status_slas"
+}
+
+// SLAStatuses are the contract statuses that wait on someone and can carry an SLA
+var SLAStatuses = []string{ContractStatusPending, ContractStatusSubmitted, ContractStatusRejected}
+
+// IsSLAStatus returns true if the status can carry an SLA
+func IsSLAStatus(status string) bool {
+	for _, s := range SLAStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// SLAFor returns the rule for a status of the project: the project's own rule or the default one
+func SLAFor(rules []ContractStatusSLA, projectID uint, status string) *ContractStatusSLA {
+	var fallback *ContractStatusSLA
+	for i := range rules {
+		r := &rules[i]
+		if r.Status != status {
+			continue
+		}
+		if r.ProjectID != nil && *r.ProjectID == projectID {
+			return r
+		}
+		if r.ProjectID == nil {
+			fallback = r
+		}
+	}
+	return fallback
+}
+
+// StatusSince returns when the contract entered its current status
+func (c *Contract) StatusSince() time.Time {
+	if c.StatusChangedAt != nil {
+		return *c.StatusChangedAt
+	}
+	return c.CreatedAt
+}
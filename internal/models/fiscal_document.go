@@ -0,0 +1,193 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FiscalDocumentInvoice is the fiscal document type code of invoices (factura)
+const FiscalDocumentInvoice = "01"
+
+// Fiscal invoice line concepts
+const (
+	FiscalConceptInterest = "interest"
+	FiscalConceptFee      = "fee"
+)
+
+// FiscalRange is a range of fiscal document numbers authorized by the tax authority for one
+// establishment and point of sale, identified by its authorization code (CAI) and usable until its
+// emission deadline. Numbers are allocated in order; the row is locked while one is taken.
+type FiscalRange struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CAI           string     `gorm:"column:cai;uniqueIndex;not null" json:"cai"`
+	Establishment string     `gorm:"not null" json:"establishment"`
+	PointOfSale   string     `gorm:"not null" json:"point_of_sale"`
+	DocumentType  string     `gorm:"not null;default:01" json:"document_type"`
+	RangeStart    int64      `gorm:"not null" json:"range_start"`
+	RangeEnd      int64      `gorm:"not null" json:"range_end"`
+	NextNumber    int64      `gorm:"not null" json:"next_number"`
+	Deadline      time.Time  `gorm:"type:date;not null" json:"deadline"` // fecha límite de emisión
+	Active        bool       `gorm:"not null;default:true" json:"active"`
+	WarnedAt      *time.Time `json:"warned_at"` // last time admins were warned the range runs out
+	CreatedByID   uint       `gorm:"not null" json:"created_by_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for FiscalRange
+func (FiscalRange) TableName() string {
+	return "fiscal_ranges"
+}
+
+// FormatNumber returns a fiscal number of the range, e.g. 000-001-01-00000123
+func (r *FiscalRange) FormatNumber(number int64) string {
+	return fmt.Sprintf("%s-%s-%s-%08d", r.Establishment, r.PointOfSale, r.DocumentType, number)
+}
+
+// Remaining returns how many numbers of the range are left
+func (r *FiscalRange) Remaining() int64 {
+	if r.NextNumber > r.RangeEnd {
+		return 0
+	}
+	return r.RangeEnd - r.NextNumber + 1
+}
+
+// Expired reports whether the emission deadline has passed; the deadline day itself is usable
+func (r *FiscalRange) Expired(now time.Time) bool {
+	y, m, d := r.Deadline.Date()
+	return !now.Before(time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()))
+}
+
+// FiscalRangeResponse is the API response for a fiscal range
+type FiscalRangeResponse struct {
+	ID            uint       `json:"id"`
+	CAI           string     `json:"cai"`
+	Establishment string     `json:"establishment"`
+	PointOfSale   string     `json:"point_of_sale"`
+	DocumentType  string     `json:"document_type"`
+	RangeFrom     string     `json:"range_from"`
+	RangeTo       string     `json:"range_to"`
+	NextNumber    string     `json:"next_number"`
+	Remaining     int64      `json:"remaining"`
+	Deadline      time.Time  `json:"deadline"`
+	Expired       bool       `json:"expired"`
+	Active        bool       `json:"active"`
+	WarnedAt      *time.Time `json:"warned_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ToResponse converts FiscalRange to FiscalRangeResponse
+func (r *FiscalRange) ToResponse() FiscalRangeResponse {
+	return FiscalRangeResponse{
+		ID:            r.ID,
+		CAI:           r.CAI,
+		Establishment: r.Establishment,
+		PointOfSale:   r.PointOfSale,
+		DocumentType:  r.DocumentType,
+		RangeFrom:     r.FormatNumber(r.RangeStart),
+		RangeTo:       r.FormatNumber(r.RangeEnd),
+		NextNumber:    r.FormatNumber(r.NextNumber),
+		Remaining:     r.Remaining(),
+		Deadline:      r.Deadline,
+		Expired:       r.Expired(time.Now()),
+		Active:        r.Active,
+		WarnedAt:      r.WarnedAt,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// FiscalInvoiceLine is a charge billed on a fiscal invoice
+type FiscalInvoiceLine struct {
+	Concept     string  `json:"concept"` // interest or fee
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Taxed       bool    `json:"taxed"`
+}
+
+// FiscalInvoice is an invoice numbered within an authorized fiscal range for the interest and fees
+// collected with a payment. Amounts of taxed lines include the sales tax.
+type FiscalInvoice struct {
+	ID               uint            `gorm:"primaryKey" json:"id"`
+	FiscalRangeID    uint            `gorm:"not null;index" json:"fiscal_range_id"`
+	Number           string          `gorm:"uniqueIndex;not null" json:"number"`
+	ContractID       uint            `gorm:"not null;index" json:"contract_id"`
+	PaymentID        *uint           `gorm:"index" json:"payment_id"`
+	CustomerName     string          `gorm:"not null" json:"customer_name"`
+	CustomerRTN      string          `gorm:"column:customer_rtn" json:"customer_rtn"`
+	Currency         string          `gorm:"not null" json:"currency"`
+	Lines            json.RawMessage `gorm:"type:jsonb;not null" json:"lines"`
+	Exempt           float64         `gorm:"type:decimal(15,2);not null" json:"exempt"`
+	Taxable          float64         `gorm:"type:decimal(15,2);not null" json:"taxable"`
+	Tax              float64         `gorm:"type:decimal(15,2);not null" json:"tax"`
+	Total            float64         `gorm:"type:decimal(15,2);not null" json:"total"`
+	IssuedAt         time.Time       `gorm:"not null" json:"issued_at"`
+	IssuedByID       uint            `gorm:"not null" json:"issued_by_id"`
+	Path             *string         `json:"-"`
+	SHA256           *string         `gorm:"column:sha256" json:"sha256"`
+	IssuedDocumentID *uint           `json:"issued_document_id"`
+	VoidedAt         *time.Time      `json:"voided_at"` // e.g. the payment was undone
+	VoidedByID       *uint           `json:"voided_by_id"`
+	VoidReason       *string         `json:"void_reason"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+
+	// Relationships
+	FiscalRange FiscalRange `gorm:"foreignKey:FiscalRangeID" json:"-"`
+}
+
+// TableName specifies the table name for FiscalInvoice
+func (FiscalInvoice) TableName() string {
+	return "fiscal_invoices"
+}
+
+// InvoiceLines decodes the lines of the invoice
+func (i *FiscalInvoice) InvoiceLines() []FiscalInvoiceLine {
+	var lines []FiscalInvoiceLine
+	if len(i.Lines) > 0 {
+		_ = json.Unmarshal(i.Lines, &lines)
+	}
+	return lines
+}
+
+// FiscalInvoiceResponse is the API response for a fiscal invoice
+type FiscalInvoiceResponse struct {
+	ID           uint                `json:"id"`
+	Number       string              `json:"number"`
+	CAI          string              `json:"cai,omitempty"`
+	ContractID   uint                `json:"contract_id"`
+	PaymentID    *uint               `json:"payment_id"`
+	CustomerName string              `json:"customer_name"`
+	CustomerRTN  string              `json:"customer_rtn"`
+	Currency     string              `json:"currency"`
+	Lines        []FiscalInvoiceLine `json:"lines"`
+	Exempt       float64             `json:"exempt"`
+	Taxable      float64             `json:"taxable"`
+	Tax          float64             `json:"tax"`
+	Total        float64             `json:"total"`
+	IssuedAt     time.Time           `json:"issued_at"`
+	SHA256       *string             `json:"sha256"`
+	HasDocument  bool                `json:"has_document"`
+}
+
+// ToResponse converts FiscalInvoice to FiscalInvoiceResponse
+func (i *FiscalInvoice) ToResponse() FiscalInvoiceResponse {
+	return FiscalInvoiceResponse{
+		ID:           i.ID,
+		Number:       i.Number,
+		CAI:          i.FiscalRange.CAI,
+		ContractID:   i.ContractID,
+		PaymentID:    i.PaymentID,
+		CustomerName: i.CustomerName,
+		CustomerRTN:  i.CustomerRTN,
+		Currency:     i.Currency,
+		Lines:        i.InvoiceLines(),
+		Exempt:       i.Exempt,
+		Taxable:      i.Taxable,
+		Tax:          i.Tax,
+		Total:        i.Total,
+		IssuedAt:     i.IssuedAt,
+		SHA256:       i.SHA256,
+		HasDocument:  i.Path != nil,
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// Geometry is a polygon stored as text. Normalized values are GeoJSON geometries; on input it
+// accepts a GeoJSON object or a string (GeoJSON or WKT), which services normalize before saving.
+type Geometry string
+
+// MarshalJSON emits the stored GeoJSON as an object (null when empty)
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	if g == "" {
+		return []byte("null"), nil
+	}
+	if json.Valid([]byte(g)) {
+		return []byte(g), nil
+	}
+	return json.Marshal(string(g))
+}
+
+// UnmarshalJSON accepts a GeoJSON object or a GeoJSON/WKT string
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*g = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*g = Geometry(s)
+		return nil
+	}
+	*g = Geometry(data)
+	return nil
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Issued document type constants
+const (
+	IssuedDocumentBalanceStatement = "balance_statement"
+	IssuedDocumentPromiseContract  = "promise_contract"
+	IssuedDocumentSignedPromise    = "signed_promise_contract"
+	IssuedDocumentCustomerRecord   = "customer_record"
+	IssuedDocumentRescission       = "rescission_contract"
+	IssuedDocumentCession          = "cession_contract"
+	IssuedDocumentLotChange        = "lot_change_addendum"
+	IssuedDocumentPaymentReceipt   = "payment_receipt"
+	IssuedDocumentFiscalInvoice    = "fiscal_invoice"
+)
+
+// IssuedDocumentLabel returns the Spanish name of an issued document type
+func IssuedDocumentLabel(documentType string) string {
+	switch documentType {
+	case IssuedDocumentBalanceStatement:
+		return "Estado de cuenta"
+	case IssuedDocumentPromiseContract:
+		return "Promesa de compra-venta"
+	case IssuedDocumentSignedPromise:
+		return "Promesa de compra-venta firmada"
+	case IssuedDocumentCustomerRecord:
+		return "Hoja de cliente"
+	case IssuedDocumentRescission:
+		return "Contrato de rescisión"
+	case IssuedDocumentCession:
+		return "Cesión de derechos"
+	case IssuedDocumentLotChange:
+		return "Adenda de cambio de lote"
+	case IssuedDocumentPaymentReceipt:
+		return "Recibo de pago"
+	case IssuedDocumentFiscalInvoice:
+		return "Factura"
+	default:
+		return documentType
+	}
+}
+
+// DocumentFigure is a key figure of an issued document, shown by its public verification
+type DocumentFigure struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// IssuedDocument is the record of a generated PDF, stamped with its Code and a QR code linking to
+// the public verification. Figures holds the only data the verification discloses. SHA256 is the
+// hash of the first copy; Copies holds the hashes of the copies rendered again from the same data.
+type IssuedDocument struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	Code         string          `gorm:"uniqueIndex;not null" json:"code"`
+	DocumentType string          `gorm:"not null" json:"document_type"`
+	ContractID   *uint           `gorm:"index" json:"contract_id"`
+	UserID       *uint           `gorm:"index" json:"user_id"`
+	SHA256       string          `gorm:"column:sha256;not null" json:"sha256"`
+	SourceSHA256 *string         `gorm:"column:source_sha256" json:"-"` // hash of the data it was rendered from
+	Figures      json.RawMessage `gorm:"type:jsonb;not null" json:"figures"`
+	IssuedAt     time.Time       `gorm:"not null" json:"issued_at"`
+	VoidedAt     *time.Time      `json:"voided_at"` // e.g. a receipt voided when its payment was undone
+	CreatedAt    time.Time       `json:"created_at"`
+
+	Copies []IssuedDocumentCopy `gorm:"foreignKey:IssuedDocumentID" json:"-"`
+}
+
+// TableName specifies the table name for IssuedDocument
+func (IssuedDocument) TableName() string {
+	return "issued_documents"
+}
+
+// IssuedDocumentCopy is the hash of a copy of an issued document rendered again from unchanged
+// data. Copies are only added, so every copy handed out keeps verifying.
+type IssuedDocumentCopy struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	IssuedDocumentID uint      `gorm:"index;not null" json:"issued_document_id"`
+	SHA256           string    `gorm:"column:sha256;not null" json:"sha256"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for IssuedDocumentCopy
+func (IssuedDocumentCopy) TableName() string {
+	return "issued_document_copies"
+}
+
+// MatchesHash reports whether sha256 is the hash of any copy of the document
+func (d *IssuedDocument) MatchesHash(sha256 string) bool {
+	if sha256 == d.SHA256 {
+		return true
+	}
+	for _, c := range d.Copies {
+		if sha256 == c.SHA256 {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyFigures decodes the key figures of the document
+func (d *IssuedDocument) KeyFigures() []DocumentFigure {
+	var figures []DocumentFigure
+	if len(d.Figures) > 0 {
+		_ = json.Unmarshal(d.Figures, &figures)
+	}
+	return figures
+}
+
+// MaskName keeps the initial of each word of a name, so the public verification of a document
+// identifies its holder without disclosing it: "Juan Pérez" → "J*** P****"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		runes := []rune(w)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
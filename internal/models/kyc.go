@@ -0,0 +1,170 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// KYC checklist item constants: documents attached to the contract or data of the applicant
+const (
+	KYCItemIdentityScan    = "identity_scan"
+	KYCItemIdentityNumber  = "identity_number"
+	KYCItemRTN             = "rtn"
+	KYCItemAddress         = "address"
+	KYCItemProofOfIncome   = "proof_of_income"
+	KYCItemBankPreapproval = "bank_preapproval"
+)
+
+// KYCItems are the valid checklist items
+var KYCItems = []string{KYCItemIdentityScan, KYCItemIdentityNumber, KYCItemRTN, KYCItemAddress, KYCItemProofOfIncome, KYCItemBankPreapproval}
+
+// IsKYCItem returns true if the checklist item is known
+func IsKYCItem(item string) bool {
+	for _, i := range KYCItems {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// KYCDocumentType returns the contract document type that satisfies the item, or "" for the items
+// checked against the applicant's data
+func KYCDocumentType(item string) string {
+	switch item {
+	case KYCItemIdentityScan:
+		return DocumentTypeIDCopy
+	case KYCItemProofOfIncome:
+		return DocumentTypeProofOfIncome
+	case KYCItemBankPreapproval:
+		return DocumentTypeBankPreapproval
+	default:
+		return ""
+	}
+}
+
+// KYCItemLabel returns the name of the item shown to users
+func KYCItemLabel(item string) string {
+	switch item {
+	case KYCItemIdentityScan:
+		return "Copia de identidad"
+	case KYCItemIdentityNumber:
+		return "Número de identidad"
+	case KYCItemRTN:
+		return "RTN"
+	case KYCItemAddress:
+		return "Dirección"
+	case KYCItemProofOfIncome:
+		return "Constancia de ingresos"
+	case KYCItemBankPreapproval:
+		return "Carta de preaprobación bancaria"
+	default:
+		return item
+	}
+}
+
+// KYCRequirement is an item contracts must satisfy before approval. Requirements without a project
+// apply to every project and without a financing type to every financing type.
+type KYCRequirement struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ProjectID     *uint     `gorm:"index" json:"project_id"` // nil = all projects
+	FinancingType *string   `json:"financing_type"`          // nil = all financing types
+	Item          string    `gorm:"not null" json:"item"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Associations
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
+
+// TableName specifies the table name for KYCRequirement
+func (KYCRequirement) TableName() string {
+	return "kyc_requirements"
+}
+
+// Applies returns true if the requirement covers contracts of the project and financing type
+func (r *KYCRequirement) Applies(projectID uint, financingType string) bool {
+	if r.ProjectID != nil && *r.ProjectID != projectID {
+		return false
+	}
+	return r.FinancingType == nil || *r.FinancingType == financingType
+}
+
+// KYCChecklistItem is a required item and whether the contract satisfies it
+type KYCChecklistItem struct {
+	Item      string `json:"item"`
+	Label     string `json:"label"`
+	Satisfied bool   `json:"satisfied"`
+}
+
+// KYCChecklist is the completion status of the requirements of a contract
+type KYCChecklist struct {
+	ContractID     uint               `json:"contract_id"`
+	Items          []KYCChecklistItem `json:"items"`
+	Complete       bool               `json:"complete"`
+	Overridden     bool               `json:"overridden"`
+	OverrideReason *string            `json:"override_reason,omitempty"`
+	OverriddenByID *uint              `json:"overridden_by_id,omitempty"`
+	OverriddenAt   *time.Time         `json:"overridden_at,omitempty"`
+}
+
+// Missing returns the items the contract does not satisfy
+func (l *KYCChecklist) Missing() []KYCChecklistItem {
+	var missing []KYCChecklistItem
+	for _, item := range l.Items {
+		if !item.Satisfied {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+// BuildKYCChecklist checks the contract, its applicant (ApplicantUser must be loaded) and its current
+// documents against the requirements that apply to it
+func BuildKYCChecklist(contract *Contract, requirements []KYCRequirement, documents []ContractDocument) KYCChecklist {
+	uploaded := make(map[string]bool)
+	for _, d := range documents {
+		if d.IsCurrent() {
+			uploaded[d.DocumentType] = true
+		}
+	}
+
+	checklist := KYCChecklist{
+		ContractID:     contract.ID,
+		Items:          []KYCChecklistItem{},
+		OverrideReason: contract.KYCOverrideReason,
+		OverriddenByID: contract.KYCOverriddenByID,
+		OverriddenAt:   contract.KYCOverriddenAt,
+		Overridden:     contract.KYCOverriddenAt != nil,
+	}
+	seen := make(map[string]bool)
+	for i := range requirements {
+		r := &requirements[i]
+		if seen[r.Item] || !r.Applies(contract.Lot.ProjectID, contract.FinancingType) {
+			continue
+		}
+		seen[r.Item] = true
+		satisfied := false
+		if docType := KYCDocumentType(r.Item); docType != "" {
+			satisfied = uploaded[docType]
+		} else {
+			satisfied = applicantHas(&contract.ApplicantUser, r.Item)
+		}
+		checklist.Items = append(checklist.Items, KYCChecklistItem{Item: r.Item, Label: KYCItemLabel(r.Item), Satisfied: satisfied})
+	}
+	checklist.Complete = len(checklist.Missing()) == 0
+	return checklist
+}
+
+func applicantHas(user *User, item string) bool {
+	switch item {
+	case KYCItemIdentityNumber:
+		return strings.TrimSpace(user.Identity) != ""
+	case KYCItemRTN:
+		return strings.TrimSpace(user.RTN) != ""
+	case KYCItemAddress:
+		return user.Address != nil && strings.TrimSpace(*user.Address) != ""
+	default:
+		return false
+	}
+}
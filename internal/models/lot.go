@@ -8,6 +8,8 @@ import (
 type Lot struct {
 	ID                 uint      `gorm:"primaryKey" json:"id"`
 	ProjectID          uint      `gorm:"not null;index" json:"project_id"`
+	PhaseID            *uint     `gorm:"index" json:"phase_id"`
+	BlockID            *uint     `gorm:"index" json:"block_id"`
 	Name               string    `gorm:"not null" json:"name"`
 	Status             string    `gorm:"default:available;index" json:"status"`
 	Length             float64   `gorm:"type:decimal(10,2);not null" json:"length"`
@@ -23,12 +25,18 @@ type Lot struct {
 	South              *string   `gorm:"type:text" json:"south"`
 	East               *string   `gorm:"type:text" json:"east"`
 	West               *string   `gorm:"type:text" json:"west"`
+	Geometry           *Geometry `gorm:"type:text" json:"geometry"`               // GeoJSON polygon
+	GeometryArea       *float64  `gorm:"type:decimal(12,2)" json:"geometry_area"` // area of Geometry in the lot's measurement unit
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 
 	// Associations
-	Project   Project    `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
-	Contracts []Contract `gorm:"foreignKey:LotID" json:"contracts,omitempty"`
+	Project   Project       `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	Phase     *ProjectPhase `gorm:"foreignKey:PhaseID" json:"-"`
+	Block     *ProjectBlock `gorm:"foreignKey:BlockID" json:"-"`
+	Contracts []Contract    `gorm:"foreignKey:LotID" json:"contracts,omitempty"`
+	// ActiveHold is only loaded where preloaded with the active status condition
+	ActiveHold *LotHold `gorm:"foreignKey:LotID" json:"-"`
 }
 
 // TableName specifies the table name for Lot
@@ -39,20 +47,58 @@ func (Lot) TableName() string {
 // Lot status constants
 const (
 	LotStatusAvailable = "available"
+	LotStatusHeld      = "held" // temporarily held by a seller (LotHold)
 	LotStatusReserved  = "reserved"
 	LotStatusFinanced  = "financed"
 	LotStatusFullyPaid = "fully_paid"
 	LotStatusActive    = "active"
 )
 
-// Area calculates the lot area
+// Area calculates the lot area: override, then polygon area, then length x width
 func (l *Lot) Area() float64 {
 	if l.OverrideArea != nil && *l.OverrideArea > 0 {
 		return *l.OverrideArea
 	}
+	if l.GeometryArea != nil && *l.GeometryArea > 0 {
+		return *l.GeometryArea
+	}
 	return l.Length * l.Width
 }
 
+// PricePerSquareUnit returns the default price per square unit of the lot: its block's, then its
+// phase's, then the project's (associations must be loaded)
+func (l *Lot) PricePerSquareUnit() float64 {
+	if l.Block != nil && l.Block.PricePerSquareUnit != nil {
+		return *l.Block.PricePerSquareUnit
+	}
+	if l.Phase != nil && l.Phase.PricePerSquareUnit != nil {
+		return *l.Phase.PricePerSquareUnit
+	}
+	return l.Project.PricePerSquareUnit
+}
+
+// DeliveryDate returns the delivery date of the lot: its block's, then its phase's, then the project's
+func (l *Lot) DeliveryDate() *string {
+	if l.Block != nil && l.Block.DeliveryDate != nil {
+		return l.Block.DeliveryDate
+	}
+	if l.Phase != nil && l.Phase.DeliveryDate != nil {
+		return l.Phase.DeliveryDate
+	}
+	return l.Project.DeliveryDate
+}
+
+// IsOnSale returns false when the lot's phase or block is not released for sale
+func (l *Lot) IsOnSale() bool {
+	if l.Phase != nil && l.Phase.Status != SectionStatusOnSale {
+		return false
+	}
+	if l.Block != nil && l.Block.Status != SectionStatusOnSale {
+		return false
+	}
+	return true
+}
+
 // EffectivePrice returns the override price if set, otherwise the base price
 func (l *Lot) EffectivePrice() float64 {
 	if l.OverridePrice != nil && *l.OverridePrice > 0 {
@@ -68,29 +114,40 @@ func (l *Lot) IsAvailable() bool {
 
 // LotResponse is the JSON response format for lots
 type LotResponse struct {
-	ID                    uint    `json:"id"`
-	ProjectID             uint    `json:"project_id"`
-	ProjectName           string  `json:"project_name"`
-	Name                  string  `json:"name"`
-	Status                string  `json:"status"`
-	Length                float64 `json:"length"`
-	Width                 float64 `json:"width"`
-	Area                  float64 `json:"area"`
-	Price                 float64 `json:"price"`
-	EffectivePrice        float64 `json:"effective_price"`
-	Address               *string `json:"address"`
-	MeasurementUnit       *string `json:"measurement_unit"`
-	RegistrationNumber    *string `json:"registration_number"`
-	Note                  *string `json:"note"`
-	North                 *string `json:"north"`
-	South                 *string `json:"south"`
-	East                  *string `json:"east"`
-	West                  *string `json:"west"`
-	ReservedBy            string  `json:"reserved_by,omitempty"`
-	ReservedByUserID      uint    `json:"reserved_by_user_id,omitempty"`
-	ContractCreatedBy     string  `json:"contract_created_by,omitempty"`
-	ContractCreatedUserID uint    `json:"contract_created_user_id,omitempty"`
-	ContractID            uint    `json:"contract_id,omitempty"`
+	ID                    uint       `json:"id"`
+	ProjectID             uint       `json:"project_id"`
+	ProjectName           string     `json:"project_name"`
+	PhaseID               *uint      `json:"phase_id"`
+	PhaseName             string     `json:"phase_name,omitempty"`
+	BlockID               *uint      `json:"block_id"`
+	BlockName             string     `json:"block_name,omitempty"`
+	DeliveryDate          *string    `json:"delivery_date"`
+	Name                  string     `json:"name"`
+	Status                string     `json:"status"`
+	Length                float64    `json:"length"`
+	Width                 float64    `json:"width"`
+	Area                  float64    `json:"area"`
+	Price                 float64    `json:"price"`
+	EffectivePrice        float64    `json:"effective_price"`
+	Address               *string    `json:"address"`
+	MeasurementUnit       *string    `json:"measurement_unit"`
+	RegistrationNumber    *string    `json:"registration_number"`
+	Note                  *string    `json:"note"`
+	North                 *string    `json:"north"`
+	South                 *string    `json:"south"`
+	East                  *string    `json:"east"`
+	West                  *string    `json:"west"`
+	Geometry              *Geometry  `json:"geometry,omitempty"`
+	GeometryArea          *float64   `json:"geometry_area,omitempty"`
+	ReservedBy            string     `json:"reserved_by,omitempty"`
+	ReservedByUserID      uint       `json:"reserved_by_user_id,omitempty"`
+	ContractCreatedBy     string     `json:"contract_created_by,omitempty"`
+	ContractCreatedUserID uint       `json:"contract_created_user_id,omitempty"`
+	ContractID            uint       `json:"contract_id,omitempty"`
+	HoldID                uint       `json:"hold_id,omitempty"`
+	HeldBy                string     `json:"held_by,omitempty"`
+	HeldByUserID          uint       `json:"held_by_user_id,omitempty"`
+	HoldExpiresAt         *time.Time `json:"hold_expires_at,omitempty"`
 }
 
 // ToResponse converts Lot to LotResponse
@@ -99,6 +156,9 @@ func (l *Lot) ToResponse() LotResponse {
 		ID:                 l.ID,
 		ProjectID:          l.ProjectID,
 		ProjectName:        l.Project.Name,
+		PhaseID:            l.PhaseID,
+		BlockID:            l.BlockID,
+		DeliveryDate:       l.DeliveryDate(),
 		Name:               l.Name,
 		Status:             l.Status,
 		Length:             l.Length,
@@ -114,6 +174,8 @@ func (l *Lot) ToResponse() LotResponse {
 		South:              l.South,
 		East:               l.East,
 		West:               l.West,
+		Geometry:           l.Geometry,
+		GeometryArea:       l.GeometryArea,
 	}
 
 	// Find active contract for reservation info
@@ -135,5 +197,20 @@ func (l *Lot) ToResponse() LotResponse {
 		}
 	}
 
+	if l.Phase != nil {
+		resp.PhaseName = l.Phase.Name
+	}
+	if l.Block != nil {
+		resp.BlockName = l.Block.Name
+	}
+
+	if l.Status == LotStatusHeld && l.ActiveHold != nil {
+		resp.HoldID = l.ActiveHold.ID
+		resp.HeldBy = l.ActiveHold.Seller.FullName
+		resp.HeldByUserID = l.ActiveHold.SellerID
+		expiresAt := l.ActiveHold.ExpiresAt
+		resp.HoldExpiresAt = &expiresAt
+	}
+
 	return resp
 }
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+)
+
+// LotHold is a short-lived hold a seller places on an available lot while preparing a contract
+type LotHold struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	LotID      uint       `gorm:"not null;index" json:"lot_id"`
+	ProjectID  uint       `gorm:"not null;index" json:"project_id"`
+	SellerID   uint       `gorm:"not null;index" json:"seller_id"`
+	Status     string     `gorm:"not null;default:active;index" json:"status"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	Note       *string    `gorm:"type:text" json:"note"`
+	ContractID *uint      `json:"contract_id"`
+	ReleasedBy *uint      `json:"released_by"`
+	ReleasedAt *time.Time `json:"released_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Associations
+	Lot    Lot  `gorm:"foreignKey:LotID" json:"-"`
+	Seller User `gorm:"foreignKey:SellerID" json:"-"`
+}
+
+// TableName specifies the table name for LotHold
+func (LotHold) TableName() string {
+	return "lot_holds"
+}
+
+// Lot hold status constants
+const (
+	LotHoldStatusActive    = "active"
+	LotHoldStatusConverted = "converted" // turned into a contract
+	LotHoldStatusExpired   = "expired"
+	LotHoldStatusReleased  = "released"
+)
+
+// DefaultLotHoldMinutes is the hold duration for projects without one configured
+const DefaultLotHoldMinutes = 60
+
+// IsExpired returns true if the hold is past its expiry time
+func (h *LotHold) IsExpired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
+
+// LotHoldResponse is the JSON response format for lot holds
+type LotHoldResponse struct {
+	ID         uint       `json:"id"`
+	LotID      uint       `json:"lot_id"`
+	LotName    string     `json:"lot_name"`
+	ProjectID  uint       `json:"project_id"`
+	SellerID   uint       `json:"seller_id"`
+	SellerName string     `json:"seller_name"`
+	Status     string     `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Note       *string    `json:"note"`
+	ContractID *uint      `json:"contract_id"`
+	ReleasedAt *time.Time `json:"released_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToResponse converts LotHold to LotHoldResponse
+func (h *LotHold) ToResponse() LotHoldResponse {
+	return LotHoldResponse{
+		ID:         h.ID,
+		LotID:      h.LotID,
+		LotName:    h.Lot.Name,
+		ProjectID:  h.ProjectID,
+		SellerID:   h.SellerID,
+		SellerName: h.Seller.FullName,
+		Status:     h.Status,
+		ExpiresAt:  h.ExpiresAt,
+		Note:       h.Note,
+		ContractID: h.ContractID,
+		ReleasedAt: h.ReleasedAt,
+		CreatedAt:  h.CreatedAt,
+	}
+}
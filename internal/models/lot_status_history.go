@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// LotStatusHistory records a lot status transition and the domain event that caused it
+type LotStatusHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	LotID      uint      `gorm:"not null;index" json:"lot_id"`
+	FromStatus string    `gorm:"not null" json:"from_status"`
+	ToStatus   string    `gorm:"not null" json:"to_status"`
+	Event      string    `gorm:"not null" json:"event"`
+	ContractID *uint     `json:"contract_id"`
+	PaymentID  *uint     `json:"payment_id"`
+	ActorID    *uint     `json:"actor_id"`
+	Reason     *string   `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for LotStatusHistory
+func (LotStatusHistory) TableName() string {
+	return "lot_status_histories"
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+)
+
+// LotWaitlistEntry registers a customer interested in a specific (taken) lot or in any lot of a
+// project under a maximum price. When a matching lot frees up, the first waiting entry is notified
+// and its seller gets a priority hold on the lot.
+type LotWaitlistEntry struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ProjectID      uint       `gorm:"not null;index" json:"project_id"`
+	LotID          *uint      `gorm:"index" json:"lot_id"`                 // nil = any lot of the project
+	MaxPrice       *float64   `gorm:"type:decimal(15,2)" json:"max_price"` // only for any-lot entries
+	SellerID       uint       `gorm:"not null;index" json:"seller_id"`     // seller who registered the customer
+	CustomerUserID *uint      `json:"customer_user_id"`                    // existing customer, if any
+	CustomerName   string     `gorm:"not null" json:"customer_name"`
+	CustomerEmail  *string    `json:"customer_email"`
+	CustomerPhone  *string    `json:"customer_phone"`
+	Note           *string    `gorm:"type:text" json:"note"`
+	Status         string     `gorm:"not null;default:waiting;index" json:"status"`
+	OfferedLotID   *uint      `gorm:"index" json:"offered_lot_id"`
+	OfferedHoldID  *uint      `json:"offered_hold_id"`
+	NotifiedAt     *time.Time `json:"notified_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	ContractID     *uint      `json:"contract_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Associations
+	Project    Project `gorm:"foreignKey:ProjectID" json:"-"`
+	Lot        *Lot    `gorm:"foreignKey:LotID" json:"-"`
+	OfferedLot *Lot    `gorm:"foreignKey:OfferedLotID" json:"-"`
+	Seller     User    `gorm:"foreignKey:SellerID" json:"-"`
+}
+
+// TableName specifies the table name for LotWaitlistEntry
+func (LotWaitlistEntry) TableName() string {
+	return "lot_waitlist_entries"
+}
+
+// Waitlist entry status constants
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusNotified  = "notified"  // a lot was offered with a priority hold
+	WaitlistStatusFulfilled = "fulfilled" // the priority hold turned into a contract
+	WaitlistStatusLapsed    = "lapsed"    // the priority hold expired or was released
+	WaitlistStatusCancelled = "cancelled"
+)
+
+// DefaultWaitlistHoldMinutes is the priority hold window for projects without one configured
+const DefaultWaitlistHoldMinutes = 1440
+
+// Matches returns true if the entry is interested in the lot
+func (e *LotWaitlistEntry) Matches(lot *Lot) bool {
+	if e.ProjectID != lot.ProjectID {
+		return false
+	}
+	if e.LotID != nil {
+		return *e.LotID == lot.ID
+	}
+	return e.MaxPrice == nil || lot.EffectivePrice() <= *e.MaxPrice
+}
+
+// LotWaitlistEntryResponse is the JSON response format for waitlist entries
+type LotWaitlistEntryResponse struct {
+	ID             uint       `json:"id"`
+	ProjectID      uint       `json:"project_id"`
+	ProjectName    string     `json:"project_name"`
+	LotID          *uint      `json:"lot_id"`
+	LotName        string     `json:"lot_name,omitempty"`
+	MaxPrice       *float64   `json:"max_price"`
+	SellerID       uint       `json:"seller_id"`
+	SellerName     string     `json:"seller_name"`
+	CustomerUserID *uint      `json:"customer_user_id"`
+	CustomerName   string     `json:"customer_name"`
+	CustomerEmail  *string    `json:"customer_email"`
+	CustomerPhone  *string    `json:"customer_phone"`
+	Note           *string    `json:"note"`
+	Status         string     `json:"status"`
+	OfferedLotID   *uint      `json:"offered_lot_id"`
+	OfferedLotName string     `json:"offered_lot_name,omitempty"`
+	OfferedHoldID  *uint      `json:"offered_hold_id"`
+	NotifiedAt     *time.Time `json:"notified_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	ContractID     *uint      `json:"contract_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ToResponse converts LotWaitlistEntry to LotWaitlistEntryResponse
+func (e *LotWaitlistEntry) ToResponse() LotWaitlistEntryResponse {
+	resp := LotWaitlistEntryResponse{
+		ID:             e.ID,
+		ProjectID:      e.ProjectID,
+		ProjectName:    e.Project.Name,
+		LotID:          e.LotID,
+		MaxPrice:       e.MaxPrice,
+		SellerID:       e.SellerID,
+		SellerName:     e.Seller.FullName,
+		CustomerUserID: e.CustomerUserID,
+		CustomerName:   e.CustomerName,
+		CustomerEmail:  e.CustomerEmail,
+		CustomerPhone:  e.CustomerPhone,
+		Note:           e.Note,
+		Status:         e.Status,
+		OfferedLotID:   e.OfferedLotID,
+		OfferedHoldID:  e.OfferedHoldID,
+		NotifiedAt:     e.NotifiedAt,
+		OfferExpiresAt: e.OfferExpiresAt,
+		ContractID:     e.ContractID,
+		CreatedAt:      e.CreatedAt,
+	}
+	if e.Lot != nil {
+		resp.LotName = e.Lot.Name
+	}
+	if e.OfferedLot != nil {
+		resp.OfferedLotName = e.OfferedLot.Name
+	}
+	return resp
+}
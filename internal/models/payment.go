@@ -54,6 +54,7 @@ const (
 	PaymentTypeFull             = "full"
 	PaymentTypeAdvance          = "advance"
 	PaymentTypeCapitalRepayment = "capital_repayment"
+	PaymentTypeFee              = "fee" // administrative charge, e.g. contract cession fee
 )
 
 // MaySubmit returns true if payment can transition to submitted
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Payment receipt status constants
+const (
+	ReceiptStatusIssued = "issued"
+	ReceiptStatusVoid   = "void"
+)
+
+// ReceiptSeriesCompany is the receipt series when receipts are numbered company-wide
+const ReceiptSeriesCompany = "REC"
+
+// ReceiptSeriesForProject returns the receipt series of a project when receipts are numbered per project
+func ReceiptSeriesForProject(projectID uint) string {
+	return fmt.Sprintf("P%d", projectID)
+}
+
+// ReceiptSequence is the next receipt number of a series. It is locked while a receipt is issued so
+// numbers are sequential and gap-free.
+type ReceiptSequence struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Series     string    `gorm:"uniqueIndex;not null" json:"series"`
+	NextNumber int64     `gorm:"not null;default:1" json:"next_number"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for ReceiptSequence
+func (ReceiptSequence) TableName() string {
+	return "receipt_sequences"
+}
+
+// PaymentReceipt is the official numbered receipt of an approved payment. Receipts are never
+// deleted: undoing the payment voids the receipt, and approving it again issues a new number that
+// replaces it.
+type PaymentReceipt struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	PaymentID        uint       `gorm:"not null;index" json:"payment_id"`
+	ContractID       uint       `gorm:"not null;index" json:"contract_id"`
+	Series           string     `gorm:"not null" json:"series"`
+	Number           int64      `gorm:"not null" json:"number"`
+	Status           string     `gorm:"not null;default:issued" json:"status"`
+	Principal        float64    `gorm:"type:decimal(15,2);not null" json:"principal"`
+	Interest         float64    `gorm:"type:decimal(15,2);not null" json:"interest"`
+	Prepayment       float64    `gorm:"type:decimal(15,2);not null" json:"prepayment"`
+	Total            float64    `gorm:"type:decimal(15,2);not null" json:"total"`
+	Currency         string     `gorm:"not null" json:"currency"`
+	PaymentDate      time.Time  `gorm:"type:date;not null" json:"payment_date"`
+	IssuedAt         time.Time  `gorm:"not null" json:"issued_at"`
+	IssuedByID       uint       `gorm:"not null" json:"issued_by_id"`
+	ReplacesID       *uint      `json:"replaces_id"` // voided receipt this one reissues
+	Path             *string    `json:"-"`
+	SHA256           *string    `gorm:"column:sha256" json:"sha256"`
+	IssuedDocumentID *uint      `json:"issued_document_id"`
+	VoidedAt         *time.Time `json:"voided_at"`
+	VoidedByID       *uint      `json:"voided_by_id"`
+	VoidReason       *string    `json:"void_reason"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for PaymentReceipt
+func (PaymentReceipt) TableName() string {
+	return "payment_receipts"
+}
+
+// DisplayNumber returns the printed receipt number, e.g. REC-00000012
+func (r *PaymentReceipt) DisplayNumber() string {
+	return fmt.Sprintf("%s-%08d", r.Series, r.Number)
+}
+
+// PaymentReceiptResponse is the API response for a payment receipt
+type PaymentReceiptResponse struct {
+	ID          uint       `json:"id"`
+	PaymentID   uint       `json:"payment_id"`
+	ContractID  uint       `json:"contract_id"`
+	Number      string     `json:"number"`
+	Status      string     `json:"status"`
+	Principal   float64    `json:"principal"`
+	Interest    float64    `json:"interest"`
+	Prepayment  float64    `json:"prepayment"`
+	Total       float64    `json:"total"`
+	Currency    string     `json:"currency"`
+	PaymentDate time.Time  `json:"payment_date"`
+	IssuedAt    time.Time  `json:"issued_at"`
+	ReplacesID  *uint      `json:"replaces_id"`
+	SHA256      *string    `json:"sha256"`
+	VoidedAt    *time.Time `json:"voided_at"`
+	VoidReason  *string    `json:"void_reason"`
+	HasDocument bool       `json:"has_document"`
+}
+
+// ToResponse converts PaymentReceipt to PaymentReceiptResponse
+func (r *PaymentReceipt) ToResponse() PaymentReceiptResponse {
+	return PaymentReceiptResponse{
+		ID:          r.ID,
+		PaymentID:   r.PaymentID,
+		ContractID:  r.ContractID,
+		Number:      r.DisplayNumber(),
+		Status:      r.Status,
+		Principal:   r.Principal,
+		Interest:    r.Interest,
+		Prepayment:  r.Prepayment,
+		Total:       r.Total,
+		Currency:    r.Currency,
+		PaymentDate: r.PaymentDate,
+		IssuedAt:    r.IssuedAt,
+		ReplacesID:  r.ReplacesID,
+		SHA256:      r.SHA256,
+		VoidedAt:    r.VoidedAt,
+		VoidReason:  r.VoidReason,
+		HasDocument: r.Path != nil,
+	}
+}
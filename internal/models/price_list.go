@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+)
+
+// PriceList is a versioned bulk repricing of a project's lots, effective from a date
+type PriceList struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	ProjectID     uint       `gorm:"not null;index" json:"project_id"`
+	Version       int        `gorm:"not null" json:"version"`
+	Name          string     `gorm:"not null" json:"name"`
+	Status        string     `gorm:"not null;default:scheduled" json:"status"`
+	Operation     *string    `gorm:"type:text" json:"operation"` // JSON of the repricing request
+	EffectiveFrom time.Time  `gorm:"not null" json:"effective_from"`
+	AppliedAt     *time.Time `json:"applied_at"`
+	CreatedByID   *uint      `json:"created_by_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Associations
+	Items []PriceListItem `gorm:"foreignKey:PriceListID" json:"items,omitempty"`
+}
+
+// TableName specifies the table name for PriceList
+func (PriceList) TableName() string {
+	return "price_lists"
+}
+
+// Price list status constants
+const (
+	PriceListStatusScheduled = "scheduled"
+	PriceListStatusApplied   = "applied"
+	PriceListStatusCancelled = "cancelled"
+)
+
+// Lot price fields a price list item can change
+const (
+	PriceFieldPrice         = "price"
+	PriceFieldOverridePrice = "override_price"
+)
+
+// PriceListItem is the planned price change of one lot within a price list
+type PriceListItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PriceListID uint      `gorm:"not null;index" json:"price_list_id"`
+	LotID       uint      `gorm:"not null" json:"lot_id"`
+	PriceField  string    `gorm:"not null;default:price" json:"price_field"`
+	OldPrice    float64   `gorm:"type:decimal(15,2);not null" json:"old_price"`
+	NewPrice    float64   `gorm:"type:decimal(15,2);not null" json:"new_price"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Associations
+	Lot *Lot `gorm:"foreignKey:LotID" json:"-"`
+}
+
+// TableName specifies the table name for PriceListItem
+func (PriceListItem) TableName() string {
+	return "price_list_items"
+}
+
+// LotPriceHistory records an applied lot price change
+type LotPriceHistory struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	LotID         uint      `gorm:"not null;index" json:"lot_id"`
+	PriceListID   *uint     `json:"price_list_id"`
+	PriceField    string    `gorm:"not null;default:price" json:"price_field"`
+	OldPrice      *float64  `gorm:"type:decimal(15,2)" json:"old_price"`
+	NewPrice      float64   `gorm:"type:decimal(15,2);not null" json:"new_price"`
+	EffectiveFrom time.Time `gorm:"not null" json:"effective_from"`
+	ChangedByID   *uint     `json:"changed_by_id"`
+	Reason        *string   `json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for LotPriceHistory
+func (LotPriceHistory) TableName() string {
+	return "lot_price_histories"
+}
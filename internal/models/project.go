@@ -6,23 +6,33 @@ import (
 
 // Project represents a real estate project
 type Project struct {
-	ID                   uint      `gorm:"primaryKey" json:"id"`
-	Name                 string    `gorm:"not null" json:"name"`
-	Description          string    `gorm:"type:text;not null" json:"description"`
-	ProjectType          string    `gorm:"default:residential" json:"project_type"`
-	Address              string    `gorm:"not null" json:"address"`
-	LotCount             int       `gorm:"not null" json:"lot_count"`
-	PricePerSquareUnit   float64   `gorm:"type:decimal(10,2);not null" json:"price_per_square_unit"`
-	InterestRate         float64   `gorm:"type:decimal(5,2);not null" json:"interest_rate"`
-	GUID                 string    `gorm:"column:guid;not null" json:"guid"`
-	CommissionRate       float64   `gorm:"type:decimal(5,2);default:0" json:"commission_rate"`
-	CommissionRateDirect float64   `gorm:"type:decimal(5,2);default:4" json:"commission_rate_direct"`
-	CommissionRateBank   float64   `gorm:"type:decimal(5,2);default:6" json:"commission_rate_bank"`
-	CommissionRateCash   float64   `gorm:"type:decimal(5,2);default:7" json:"commission_rate_cash"`
-	MeasurementUnit      string    `gorm:"default:m2" json:"measurement_unit"`
-	DeliveryDate         *string   `gorm:"type:date" json:"delivery_date"`
-	CreatedAt            time.Time `json:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at"`
+	ID                   uint       `gorm:"primaryKey" json:"id"`
+	Name                 string     `gorm:"not null" json:"name"`
+	Description          string     `gorm:"type:text;not null" json:"description"`
+	ProjectType          string     `gorm:"default:residential" json:"project_type"`
+	Address              string     `gorm:"not null" json:"address"`
+	LotCount             int        `gorm:"not null" json:"lot_count"`
+	PricePerSquareUnit   float64    `gorm:"type:decimal(10,2);not null" json:"price_per_square_unit"`
+	InterestRate         float64    `gorm:"type:decimal(5,2);not null" json:"interest_rate"`
+	GUID                 string     `gorm:"column:guid;not null" json:"guid"`
+	CommissionRate       float64    `gorm:"type:decimal(5,2);default:0" json:"commission_rate"`
+	CommissionRateDirect float64    `gorm:"type:decimal(5,2);default:4" json:"commission_rate_direct"`
+	CommissionRateBank   float64    `gorm:"type:decimal(5,2);default:6" json:"commission_rate_bank"`
+	CommissionRateCash   float64    `gorm:"type:decimal(5,2);default:7" json:"commission_rate_cash"`
+	MeasurementUnit      string     `gorm:"default:m2" json:"measurement_unit"`
+	DeliveryDate         *string    `gorm:"type:date" json:"delivery_date"`
+	Geometry             *Geometry  `gorm:"type:text" json:"geometry"` // GeoJSON boundary of the subdivision
+	HoldDurationMinutes  int        `gorm:"default:60" json:"hold_duration_minutes"`
+	HoldDurationHours    *int       `gorm:"-" json:"hold_duration_hours,omitempty"`    // input only; converted to minutes
+	WaitlistHoldMinutes  int        `gorm:"default:1440" json:"waitlist_hold_minutes"` // priority hold window for waitlist entries
+	Status               string     `gorm:"not null;default:draft;index" json:"status"`
+	SubmittedAt          *time.Time `json:"submitted_at"`
+	ApprovedBy           *uint      `json:"approved_by"`
+	ApprovedAt           *time.Time `json:"approved_at"`
+	ClosedAt             *time.Time `json:"closed_at"`
+	StatusReason         *string    `gorm:"type:text" json:"status_reason"` // rejection or closing reason
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
 
 	// Associations
 	Lots []Lot `gorm:"foreignKey:ProjectID" json:"lots,omitempty"`
@@ -33,36 +43,82 @@ func (Project) TableName() string {
 	return "projects"
 }
 
+// Project status constants
+const (
+	ProjectStatusDraft           = "draft"            // being set up, not visible to sellers
+	ProjectStatusPendingApproval = "pending_approval" // submitted for approval
+	ProjectStatusPublished       = "published"        // approved and open for sale
+	ProjectStatusClosed          = "closed"           // sales closed; existing contracts continue
+)
+
+// IsPublished returns true when the project is approved and open for sale
+func (p *Project) IsPublished() bool {
+	return p.Status == ProjectStatusPublished
+}
+
+// IsVisibleToSellers returns true when sellers may view the project: published, or closed
+// (read-only, its contracts continue)
+func (p *Project) IsVisibleToSellers() bool {
+	return p.Status == ProjectStatusPublished || p.Status == ProjectStatusClosed
+}
+
+// HoldDuration returns the lot hold duration in minutes (DefaultLotHoldMinutes when not configured)
+func (p *Project) HoldDuration() int {
+	if p.HoldDurationMinutes > 0 {
+		return p.HoldDurationMinutes
+	}
+	return DefaultLotHoldMinutes
+}
+
+// WaitlistHoldDuration returns the waitlist priority hold window in minutes
+func (p *Project) WaitlistHoldDuration() int {
+	if p.WaitlistHoldMinutes > 0 {
+		return p.WaitlistHoldMinutes
+	}
+	return DefaultWaitlistHoldMinutes
+}
+
 // ProjectResponse is the JSON response format for projects
 type ProjectResponse struct {
-	ID                   uint      `json:"id"`
-	GUID                 string    `json:"guid"`
-	Name                 string    `json:"name"`
-	Description          string    `json:"description"`
-	ProjectType          string    `json:"project_type"`
-	Address              string    `json:"address"`
-	LotCount             int       `json:"lot_count"`
-	PricePerSquareUnit   float64   `json:"price_per_square_unit"`
-	InterestRate         float64   `json:"interest_rate"`
-	CommissionRate       float64   `json:"commission_rate"`
-	CommissionRateDirect float64   `json:"commission_rate_direct"`
-	CommissionRateBank   float64   `json:"commission_rate_bank"`
-	CommissionRateCash   float64   `json:"commission_rate_cash"`
-	MeasurementUnit      string    `json:"measurement_unit"`
-	DeliveryDate         *string   `json:"delivery_date"`
-	AvailableLots        int       `json:"available_lots"`
-	ReservedLots         int       `json:"reserved_lots"`
-	SoldLots             int       `json:"sold_lots"`
-	CreatedAt            time.Time `json:"created_at"`
+	ID                   uint       `json:"id"`
+	GUID                 string     `json:"guid"`
+	Name                 string     `json:"name"`
+	Description          string     `json:"description"`
+	ProjectType          string     `json:"project_type"`
+	Address              string     `json:"address"`
+	LotCount             int        `json:"lot_count"`
+	PricePerSquareUnit   float64    `json:"price_per_square_unit"`
+	InterestRate         float64    `json:"interest_rate"`
+	CommissionRate       float64    `json:"commission_rate"`
+	CommissionRateDirect float64    `json:"commission_rate_direct"`
+	CommissionRateBank   float64    `json:"commission_rate_bank"`
+	CommissionRateCash   float64    `json:"commission_rate_cash"`
+	MeasurementUnit      string     `json:"measurement_unit"`
+	DeliveryDate         *string    `json:"delivery_date"`
+	Geometry             *Geometry  `json:"geometry,omitempty"`
+	HoldDurationMinutes  int        `json:"hold_duration_minutes"`
+	WaitlistHoldMinutes  int        `json:"waitlist_hold_minutes"`
+	Status               string     `json:"status"`
+	SubmittedAt          *time.Time `json:"submitted_at"`
+	ApprovedAt           *time.Time `json:"approved_at"`
+	ClosedAt             *time.Time `json:"closed_at"`
+	StatusReason         *string    `json:"status_reason,omitempty"`
+	AvailableLots        int        `json:"available_lots"`
+	HeldLots             int        `json:"held_lots"`
+	ReservedLots         int        `json:"reserved_lots"`
+	SoldLots             int        `json:"sold_lots"`
+	CreatedAt            time.Time  `json:"created_at"`
 }
 
 // ToResponse converts Project to ProjectResponse
 func (p *Project) ToResponse() ProjectResponse {
-	var available, reserved, sold int
+	var available, held, reserved, sold int
 	for _, lot := range p.Lots {
 		switch lot.Status {
 		case LotStatusAvailable:
 			available++
+		case LotStatusHeld:
+			held++
 		case LotStatusReserved:
 			reserved++
 		case LotStatusFinanced, LotStatusFullyPaid:
@@ -86,7 +142,16 @@ func (p *Project) ToResponse() ProjectResponse {
 		CommissionRateCash:   p.CommissionRateCash,
 		MeasurementUnit:      p.MeasurementUnit,
 		DeliveryDate:         p.DeliveryDate,
+		Geometry:             p.Geometry,
+		HoldDurationMinutes:  p.HoldDuration(),
+		WaitlistHoldMinutes:  p.WaitlistHoldDuration(),
+		Status:               p.Status,
+		SubmittedAt:          p.SubmittedAt,
+		ApprovedAt:           p.ApprovedAt,
+		ClosedAt:             p.ClosedAt,
+		StatusReason:         p.StatusReason,
 		AvailableLots:        available,
+		HeldLots:             held,
 		ReservedLots:         reserved,
 		SoldLots:             sold,
 		CreatedAt:            p.CreatedAt,
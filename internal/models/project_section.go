@@ -0,0 +1,164 @@
+package models
+
+import (
+	"time"
+)
+
+// ProjectPhase is a stage ("Etapa") of a large project. Phases group blocks and lots and can set
+// their own price per square unit and delivery date.
+type ProjectPhase struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ProjectID          uint      `gorm:"not null;index" json:"project_id"`
+	Name               string    `gorm:"not null" json:"name"`
+	Position           int       `gorm:"default:0" json:"position"`
+	Status             string    `gorm:"not null;default:on_sale" json:"status"`
+	PricePerSquareUnit *float64  `gorm:"type:decimal(10,2)" json:"price_per_square_unit"` // nil = project's
+	DeliveryDate       *string   `gorm:"type:date" json:"delivery_date"`                  // nil = project's
+	Note               *string   `gorm:"type:text" json:"note"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+
+	// Associations
+	Blocks []ProjectBlock `gorm:"foreignKey:PhaseID" json:"blocks,omitempty"`
+}
+
+// TableName specifies the table name for ProjectPhase
+func (ProjectPhase) TableName() string {
+	return "project_phases"
+}
+
+// ProjectBlock is a block ("Bloque") of lots, optionally inside a phase. Blocks can set their own
+// price per square unit and delivery date, which take precedence over the phase's.
+type ProjectBlock struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ProjectID          uint      `gorm:"not null;index" json:"project_id"`
+	PhaseID            *uint     `gorm:"index" json:"phase_id"`
+	Name               string    `gorm:"not null" json:"name"`
+	Position           int       `gorm:"default:0" json:"position"`
+	Status             string    `gorm:"not null;default:on_sale" json:"status"`
+	PricePerSquareUnit *float64  `gorm:"type:decimal(10,2)" json:"price_per_square_unit"` // nil = phase's or project's
+	DeliveryDate       *string   `gorm:"type:date" json:"delivery_date"`                  // nil = phase's or project's
+	Note               *string   `gorm:"type:text" json:"note"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+
+	// Associations
+	Phase *ProjectPhase `gorm:"foreignKey:PhaseID" json:"-"`
+}
+
+// TableName specifies the table name for ProjectBlock
+func (ProjectBlock) TableName() string {
+	return "project_blocks"
+}
+
+// Phase/block status constants
+const (
+	SectionStatusPlanned   = "planned"   // not yet released for sale
+	SectionStatusOnSale    = "on_sale"   // lots can be held and contracted
+	SectionStatusClosed    = "closed"    // sales closed
+	SectionStatusDelivered = "delivered" // lots handed over
+)
+
+// IsValidSectionStatus returns true for a known phase/block status
+func IsValidSectionStatus(status string) bool {
+	switch status {
+	case SectionStatusPlanned, SectionStatusOnSale, SectionStatusClosed, SectionStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// SectionLotCounts counts the lots of a phase or block by status
+type SectionLotCounts struct {
+	Total     int `json:"total"`
+	Available int `json:"available"`
+	Held      int `json:"held"`
+	Reserved  int `json:"reserved"`
+	Financed  int `json:"financed"`
+	FullyPaid int `json:"fully_paid"`
+}
+
+// Add counts a lot status
+func (c *SectionLotCounts) Add(status string, n int) {
+	c.Total += n
+	switch status {
+	case LotStatusAvailable, LotStatusActive:
+		c.Available += n
+	case LotStatusHeld:
+		c.Held += n
+	case LotStatusReserved:
+		c.Reserved += n
+	case LotStatusFinanced:
+		c.Financed += n
+	case LotStatusFullyPaid:
+		c.FullyPaid += n
+	}
+}
+
+// ProjectBlockNode is a block in the project hierarchy with its effective defaults and lot counts
+type ProjectBlockNode struct {
+	ProjectBlock
+	EffectivePricePerSquareUnit float64          `json:"effective_price_per_square_unit"`
+	EffectiveDeliveryDate       *string          `json:"effective_delivery_date"`
+	Lots                        SectionLotCounts `json:"lots"`
+}
+
+// ProjectPhaseNode is a phase in the project hierarchy with its blocks and lot counts
+type ProjectPhaseNode struct {
+	ProjectPhase
+	EffectivePricePerSquareUnit float64            `json:"effective_price_per_square_unit"`
+	EffectiveDeliveryDate       *string            `json:"effective_delivery_date"`
+	Lots                        SectionLotCounts   `json:"lots"`
+	BlockNodes                  []ProjectBlockNode `json:"blocks"`
+}
+
+// ProjectHierarchy is the Phase → Block → Lot tree of a project. Blocks without a phase and lots
+// without a phase or block are listed apart.
+type ProjectHierarchy struct {
+	ProjectID      uint               `json:"project_id"`
+	Phases         []ProjectPhaseNode `json:"phases"`
+	UnphasedBlocks []ProjectBlockNode `json:"unphased_blocks"`
+	UnassignedLots SectionLotCounts   `json:"unassigned_lots"`
+	Lots           SectionLotCounts   `json:"lots"`
+}
+
+// LotGroup is a page of lots grouped by phase or block (nil ID = lots without one)
+type LotGroup struct {
+	ID     *uint            `json:"id"`
+	Name   string           `json:"name"`
+	Counts SectionLotCounts `json:"counts"`
+	Lots   []LotResponse    `json:"lots"`
+}
+
+// GroupLots groups lots by "phase" or "block" keeping their order; lots must be sorted by group
+func GroupLots(lots []Lot, by string) []LotGroup {
+	groups := []LotGroup{}
+	for i := range lots {
+		l := &lots[i]
+		id, name := l.PhaseID, ""
+		if l.Phase != nil {
+			name = l.Phase.Name
+		}
+		if by == "block" {
+			id, name = l.BlockID, ""
+			if l.Block != nil {
+				name = l.Block.Name
+			}
+		}
+		n := len(groups)
+		if n == 0 || !sameSection(groups[n-1].ID, id) {
+			groups = append(groups, LotGroup{ID: id, Name: name, Lots: []LotResponse{}})
+			n++
+		}
+		groups[n-1].Counts.Add(l.Status, 1)
+		groups[n-1].Lots = append(groups[n-1].Lots, l.ToResponse())
+	}
+	return groups
+}
+
+func sameSection(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+)
+
+// Promotion represents a discount rule applied to lot prices when a contract is created
+type Promotion struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Name          string     `gorm:"not null" json:"name"`
+	Description   *string    `gorm:"type:text" json:"description"`
+	DiscountType  string     `gorm:"not null" json:"discount_type"`
+	Value         float64    `gorm:"type:decimal(15,2);not null;default:0" json:"value"` // percent for percent_off, amount for fixed_amount
+	ProjectID     *uint      `gorm:"index" json:"project_id"`                            // nil = all projects
+	LotIDs        []uint     `gorm:"serializer:json;type:text" json:"lot_ids"`           // empty = all lots in scope
+	FinancingType *string    `json:"financing_type"`                                     // nil = any financing type
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	MaxUses       *int       `json:"max_uses"` // nil = unlimited
+	UsesCount     int        `gorm:"not null;default:0" json:"uses_count"`
+	Active        bool       `gorm:"default:true;index" json:"active"`
+	CreatedByID   *uint      `json:"created_by_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Associations
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
+
+// TableName specifies the table name for Promotion
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// Discount type constants
+const (
+	DiscountTypePercentOff       = "percent_off"       // Percentage off the lot price
+	DiscountTypeFixedAmount      = "fixed_amount"      // Fixed amount off the lot price
+	DiscountTypeWaiveReservation = "waive_reservation" // Reservation fee discounted from the lot price
+)
+
+// IsValidDiscountType returns true if the discount type is supported
+func IsValidDiscountType(t string) bool {
+	switch t {
+	case DiscountTypePercentOff, DiscountTypeFixedAmount, DiscountTypeWaiveReservation:
+		return true
+	}
+	return false
+}
+
+// IsEligible returns true if the promotion applies to the given lot and financing type at the given time
+func (p *Promotion) IsEligible(lot *Lot, financingType string, at time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && at.After(*p.EndsAt) {
+		return false
+	}
+	if p.MaxUses != nil && p.UsesCount >= *p.MaxUses {
+		return false
+	}
+	if p.ProjectID != nil && *p.ProjectID != lot.ProjectID {
+		return false
+	}
+	if p.FinancingType != nil && *p.FinancingType != "" && *p.FinancingType != financingType {
+		return false
+	}
+	if len(p.LotIDs) > 0 {
+		found := false
+		for _, id := range p.LotIDs {
+			if id == lot.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// DiscountFor returns the discount amount (positive) for the given base price and reserve amount.
+// The discount never exceeds the base price.
+func (p *Promotion) DiscountFor(basePrice, reserveAmount float64) float64 {
+	var discount float64
+	switch p.DiscountType {
+	case DiscountTypePercentOff:
+		discount = basePrice * (p.Value / 100)
+	case DiscountTypeFixedAmount:
+		discount = p.Value
+	case DiscountTypeWaiveReservation:
+		discount = reserveAmount
+	}
+	if discount < 0 {
+		return 0
+	}
+	if discount > basePrice {
+		return basePrice
+	}
+	return discount
+}
+
+// ContractLineItem is a line of the price breakdown recorded when a contract is created
+type ContractLineItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ContractID  uint      `gorm:"not null;index" json:"contract_id"`
+	PromotionID *uint     `json:"promotion_id,omitempty"`
+	ItemType    string    `gorm:"not null" json:"item_type"`
+	Description string    `gorm:"not null" json:"description"`
+	Amount      float64   `gorm:"type:decimal(15,2);not null" json:"amount"` // Negative for discounts
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for ContractLineItem
+func (ContractLineItem) TableName() string {
+	return "contract_line_items"
+}
+
+// Line item type constants
+const (
+	LineItemTypeBasePrice = "base_price"
+	LineItemTypeDiscount  = "discount"
+	LineItemTypeLotChange = "lot_change" // price difference after moving the contract to another lot
+)
+
+// ContractLineItemResponse is the JSON response format for contract line items
+type ContractLineItemResponse struct {
+	ID          uint    `json:"id"`
+	PromotionID *uint   `json:"promotion_id,omitempty"`
+	ItemType    string  `json:"item_type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// ToResponse converts ContractLineItem to ContractLineItemResponse
+func (i *ContractLineItem) ToResponse() ContractLineItemResponse {
+	return ContractLineItemResponse{
+		ID:          i.ID,
+		PromotionID: i.PromotionID,
+		ItemType:    i.ItemType,
+		Description: i.Description,
+		Amount:      i.Amount,
+	}
+}
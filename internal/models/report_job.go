@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Report job status constants
+const (
+	ReportJobQueued    = "queued"
+	ReportJobRunning   = "running"
+	ReportJobCompleted = "completed"
+	ReportJobFailed    = "failed"
+	ReportJobExpired   = "expired"
+)
+
+// ReportJob is a report generated in the background at a user's request. The output is stored
+// until ExpiresAt, after which the file is deleted and the job is marked expired.
+type ReportJob struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	ReportType    string          `gorm:"not null" json:"report_type"`
+	Params        json.RawMessage `gorm:"type:jsonb;not null" json:"params"`
+	Status        string          `gorm:"not null;default:queued;index" json:"status"`
+	RequestedByID uint            `gorm:"not null;index" json:"requested_by_id"`
+	Filename      string          `json:"filename"`
+	ContentType   string          `json:"content_type"`
+	Path          *string         `json:"-"`
+	Size          int64           `json:"size"`
+	SHA256        *string         `gorm:"column:sha256" json:"sha256"`
+	Error         *string         `json:"error"`
+	StartedAt     *time.Time      `json:"started_at"`
+	CompletedAt   *time.Time      `json:"completed_at"`
+	ExpiresAt     *time.Time      `json:"expires_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name for ReportJob
+func (ReportJob) TableName() string {
+	return "report_jobs"
+}
+
+// ReportJobResponse is the API response for a report job
+type ReportJobResponse struct {
+	ID            uint            `json:"id"`
+	ReportType    string          `json:"report_type"`
+	Params        json.RawMessage `json:"params"`
+	Status        string          `json:"status"`
+	RequestedByID uint            `json:"requested_by_id"`
+	Filename      string          `json:"filename,omitempty"`
+	ContentType   string          `json:"content_type,omitempty"`
+	Size          int64           `json:"size"`
+	SHA256        *string         `json:"sha256"`
+	Error         *string         `json:"error"`
+	Downloadable  bool            `json:"downloadable"`
+	StartedAt     *time.Time      `json:"started_at"`
+	CompletedAt   *time.Time      `json:"completed_at"`
+	ExpiresAt     *time.Time      `json:"expires_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ToResponse converts ReportJob to ReportJobResponse
+func (j *ReportJob) ToResponse() ReportJobResponse {
+	return ReportJobResponse{
+		ID:            j.ID,
+		ReportType:    j.ReportType,
+		Params:        j.Params,
+		Status:        j.Status,
+		RequestedByID: j.RequestedByID,
+		Filename:      j.Filename,
+		ContentType:   j.ContentType,
+		Size:          j.Size,
+		SHA256:        j.SHA256,
+		Error:         j.Error,
+		Downloadable:  j.Status == ReportJobCompleted && j.Path != nil,
+		StartedAt:     j.StartedAt,
+		CompletedAt:   j.CompletedAt,
+		ExpiresAt:     j.ExpiresAt,
+		CreatedAt:     j.CreatedAt,
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// Report subscription run status constants
+const (
+	ReportRunSent   = "sent"
+	ReportRunFailed = "failed"
+)
+
+// ReportSubscription emails a report to a list of recipients on a cron-like schedule, e.g.
+// "0 7 * * 1" for Mondays at 7:00 or "0 7 1 * *" for the 1st of each month.
+type ReportSubscription struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	Name        string            `gorm:"not null" json:"name"`
+	ReportType  string            `gorm:"not null" json:"report_type"`
+	Params      map[string]string `gorm:"serializer:json;type:jsonb" json:"params"`
+	Recipients  []string          `gorm:"serializer:json;type:jsonb" json:"recipients"`
+	Schedule    string            `gorm:"not null" json:"schedule"`
+	Active      bool              `gorm:"default:true;index" json:"active"`
+	NextRunAt   *time.Time        `gorm:"index" json:"next_run_at"`
+	LastRunAt   *time.Time        `json:"last_run_at"`
+	LastStatus  *string           `json:"last_status"`
+	CreatedByID uint              `gorm:"not null" json:"created_by_id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for ReportSubscription
+func (ReportSubscription) TableName() string {
+	return "report_subscriptions"
+}
+
+// ReportSubscriptionRun records one delivery of a report subscription
+type ReportSubscriptionRun struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint       `gorm:"not null;index" json:"subscription_id"`
+	Status         string     `gorm:"not null" json:"status"`
+	Filename       string     `json:"filename"`
+	Size           int64      `json:"size"`
+	Recipients     []string   `gorm:"serializer:json;type:jsonb" json:"recipients"`
+	Error          *string    `json:"error"`
+	Manual         bool       `gorm:"not null;default:false" json:"manual"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for ReportSubscriptionRun
+func (ReportSubscriptionRun) TableName() string {
+	return "report_subscription_runs"
+}
@@ -23,7 +23,8 @@ type AnalyticsRepository interface {
 	GetAveragePayment(ctx context.Context, projectID *uint, startDate, endDate *time.Time) (float64, error)
 	GetOccupancyRate(ctx context.Context, projectID *uint, endDate *time.Time) (float64, error)
 	GetRevenueTrend(ctx context.Context, projectID *uint, timeframe string, year *int) ([]models.RevenueTrendPoint, error)
-	GetLotDistribution(ctx context.Context, projectID *uint) (*models.LotDistribution, error)
+	GetLotDistribution(ctx context.Context, filters models.AnalyticsFilters) (*models.LotDistribution, error)
+	GetLotDistributionGroups(ctx context.Context, filters models.AnalyticsFilters, groupBy string) ([]models.LotDistributionGroup, error)
 	GetProjectPerformance(ctx context.Context, filters models.AnalyticsFilters) ([]models.ProjectPerformance, error)
 	GetSellerPerformance(ctx context.Context, filters models.AnalyticsFilters) ([]models.SellerPerformance, error)
 }
@@ -131,7 +132,7 @@ func (r *analyticsRepository) GetTotalRevenue(ctx context.Context, projectID *ui
 func (r *analyticsRepository) GetActiveContractsCount(ctx context.Context, projectID *uint, startDate, endDate *time.Time) (int, error) {
 	var count int64
 	query := r.db.WithContext(ctx).Model(&models.Contract{}).
-		Where("contracts.status = ?", models.ContractStatusApproved).
+		Where("contracts.status IN ?", models.ContractInForceStatuses).
 		Where("contracts.active = ?", true)
 
 	if projectID != nil {
@@ -190,7 +191,7 @@ func (r *analyticsRepository) GetOccupancyRate(ctx context.Context, projectID *u
 		subq := r.db.WithContext(ctx).Model(&models.Contract{}).
 			Select("DISTINCT contracts.lot_id").
 			Joins("INNER JOIN lots ON lots.id = contracts.lot_id").
-			Where("contracts.status IN ?", []string{models.ContractStatusApproved, models.ContractStatusClosed}).
+			Where("contracts.status IN ?", []string{models.ContractStatusApproved, models.ContractStatusSigned, models.ContractStatusClosed}).
 			Where("contracts.approved_at IS NOT NULL AND contracts.approved_at <= ?", *endDate).
 			Where("(contracts.closed_at IS NULL OR contracts.closed_at > ?)", *endDate)
 		if projectID != nil {
@@ -311,47 +312,106 @@ func (r *analyticsRepository) GetRevenueTrend(ctx context.Context, projectID *ui
 	return points, nil
 }
 
-func (r *analyticsRepository) GetLotDistribution(ctx context.Context, projectID *uint) (*models.LotDistribution, error) {
-	var dist models.LotDistribution
-
+// lotDistributionQuery scopes the lots by project, phase and block
+func (r *analyticsRepository) lotDistributionQuery(ctx context.Context, filters models.AnalyticsFilters) *gorm.DB {
 	query := r.db.WithContext(ctx).Model(&models.Lot{})
-	if projectID != nil {
-		query = query.Where("project_id = ?", *projectID)
+	if filters.ProjectID != nil {
+		query = query.Where("project_id = ?", *filters.ProjectID)
+	}
+	if filters.PhaseID != nil {
+		query = query.Where("phase_id = ?", *filters.PhaseID)
 	}
+	if filters.BlockID != nil {
+		query = query.Where("block_id = ?", *filters.BlockID)
+	}
+	return query
+}
+
+func (r *analyticsRepository) GetLotDistribution(ctx context.Context, filters models.AnalyticsFilters) (*models.LotDistribution, error) {
+	var dist models.LotDistribution
 
 	var results []struct {
 		Status string
 		Count  int
 	}
 
-	err := query.Select("status, COUNT(*) as count").Group("status").Scan(&results).Error
+	err := r.lotDistributionQuery(ctx, filters).Select("status, COUNT(*) as count").Group("status").Scan(&results).Error
 	if err != nil {
 		return nil, err
 	}
 
 	for _, res := range results {
-		dist.TotalLots += res.Count
-		switch res.Status {
-		case models.LotStatusAvailable:
-			dist.Available = res.Count
-		case models.LotStatusReserved:
-			dist.Reserved = res.Count
-		case models.LotStatusFinanced:
-			dist.Financed = res.Count
-		case models.LotStatusFullyPaid:
-			dist.FullyPaid = res.Count
-		}
+		dist.Add(res.Status, res.Count)
 	}
+	dist.CalculatePercentages()
+
+	return &dist, nil
+}
 
-	// Calculate percentages
-	if dist.TotalLots > 0 {
-		dist.AvailablePercentage = (float64(dist.Available) / float64(dist.TotalLots)) * 100
-		dist.ReservedPercentage = (float64(dist.Reserved) / float64(dist.TotalLots)) * 100
-		dist.FinancedPercentage = (float64(dist.Financed) / float64(dist.TotalLots)) * 100
-		dist.FullyPaidPercentage = (float64(dist.FullyPaid) / float64(dist.TotalLots)) * 100
+// GetLotDistributionGroups returns the lot distribution per phase or block, in hierarchy order,
+// with the lots without one last
+func (r *analyticsRepository) GetLotDistributionGroups(ctx context.Context, filters models.AnalyticsFilters, groupBy string) ([]models.LotDistributionGroup, error) {
+	column, table := "phase_id", "project_phases"
+	if groupBy == "block" {
+		column, table = "block_id", "project_blocks"
 	}
 
-	return &dist, nil
+	var results []struct {
+		SectionID *uint
+		Status    string
+		Count     int
+	}
+	err := r.lotDistributionQuery(ctx, filters).
+		Select(column + " AS section_id, status, COUNT(*) AS count").
+		Group(column + ", status").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []struct {
+		ID   uint
+		Name string
+	}
+	ids := []uint{}
+	for _, res := range results {
+		if res.SectionID != nil {
+			ids = append(ids, *res.SectionID)
+		}
+	}
+	if len(ids) > 0 {
+		if err := r.db.WithContext(ctx).Table(table).Select("id, name").Where("id IN ?", ids).
+			Order("position ASC, name ASC").Scan(&sections).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	groups := make([]models.LotDistributionGroup, 0, len(sections)+1)
+	index := map[uint]int{}
+	for _, sec := range sections {
+		id := sec.ID
+		index[id] = len(groups)
+		groups = append(groups, models.LotDistributionGroup{ID: &id, Name: sec.Name})
+	}
+	unassigned := -1
+	for _, res := range results {
+		i, ok := -1, false
+		if res.SectionID != nil {
+			i, ok = index[*res.SectionID]
+		}
+		if !ok {
+			if unassigned < 0 {
+				unassigned = len(groups)
+				groups = append(groups, models.LotDistributionGroup{})
+			}
+			i = unassigned
+		}
+		groups[i].Distribution.Add(res.Status, res.Count)
+	}
+	for i := range groups {
+		groups[i].Distribution.CalculatePercentages()
+	}
+	return groups, nil
 }
 
 func (r *analyticsRepository) GetProjectPerformance(ctx context.Context, filters models.AnalyticsFilters) ([]models.ProjectPerformance, error) {
@@ -408,7 +468,7 @@ func (r *analyticsRepository) GetSellerPerformance(ctx context.Context, filters
 		Group("users.id, users.full_name")
 
 	// Construct dynamic LEFT JOIN to preserve sellers with 0 sales
-	joinParams := []interface{}{[]string{models.ContractStatusApproved, models.ContractStatusClosed}}
+	joinParams := []interface{}{[]string{models.ContractStatusApproved, models.ContractStatusSigned, models.ContractStatusClosed}}
 	joinQuery := "LEFT JOIN contracts ON contracts.creator_id = users.id AND contracts.status IN (?)"
 
 	if filters.StartDate != nil {
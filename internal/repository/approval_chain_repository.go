@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ApprovalChainRepository defines the interface for approval chain and contract approval data access
+type ApprovalChainRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.ApprovalChain, error)
+	FindAll(ctx context.Context) ([]models.ApprovalChain, error)
+	FindActive(ctx context.Context) ([]models.ApprovalChain, error)
+	Create(ctx context.Context, chain *models.ApprovalChain) error
+	Update(ctx context.Context, chain *models.ApprovalChain, replaceSteps bool) error
+	Delete(ctx context.Context, id uint) error
+	CountInFlight(ctx context.Context, chainID uint) (int64, error)
+	CountUsed(ctx context.Context, chainID uint) (int64, error)
+
+	Submit(ctx context.Context, contractID uint, fromStatus string, chainID *uint, steps int) (bool, error)
+	RecordDecision(ctx context.Context, approval *models.ContractApproval, fromStep int) (bool, error)
+	FindApprovalsByContract(ctx context.Context, contractID uint) ([]models.ContractApproval, error)
+	FindPendingForApprover(ctx context.Context, user *models.User) ([]models.Contract, error)
+}
+
+type approvalChainRepository struct {
+	db *gorm.DB
+}
+
+// NewApprovalChainRepository creates a new approval chain repository
+func NewApprovalChainRepository(db *gorm.DB) ApprovalChainRepository {
+	return &approvalChainRepository{db: db}
+}
+
+func (r *approvalChainRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Steps.ApproverUser").Preload("Project")
+}
+
+func (r *approvalChainRepository) FindByID(ctx context.Context, id uint) (*models.ApprovalChain, error) {
+	var chain models.ApprovalChain
+	err := r.preload(r.db.WithContext(ctx)).First(&chain, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+func (r *approvalChainRepository) FindAll(ctx context.Context) ([]models.ApprovalChain, error) {
+	var chains []models.ApprovalChain
+	err := r.preload(r.db.WithContext(ctx)).Order("id ASC").Find(&chains).Error
+	return chains, err
+}
+
+// FindActive returns the active chains with their steps, for choosing the chain of a contract
+func (r *approvalChainRepository) FindActive(ctx context.Context) ([]models.ApprovalChain, error) {
+	var chains []models.ApprovalChain
+	err := r.preload(r.db.WithContext(ctx)).Where("active = ?", true).Order("id ASC").Find(&chains).Error
+	return chains, err
+}
+
+func (r *approvalChainRepository) Create(ctx context.Context, chain *models.ApprovalChain) error {
+	return r.db.WithContext(ctx).Omit("Project", "Steps.ApproverUser").Create(chain).Error
+}
+
+// Update saves the chain; with replaceSteps its steps are deleted and created again in one transaction
+func (r *approvalChainRepository) Update(ctx context.Context, chain *models.ApprovalChain, replaceSteps bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Project", "Steps").Save(chain).Error; err != nil {
+			return err
+		}
+		if !replaceSteps {
+			return nil
+		}
+		if err := tx.Where("chain_id = ?", chain.ID).Delete(&models.ApprovalChainStep{}).Error; err != nil {
+			return err
+		}
+		for i := range chain.Steps {
+			chain.Steps[i].ID = 0
+			chain.Steps[i].ChainID = chain.ID
+		}
+		if len(chain.Steps) == 0 {
+			return nil
+		}
+		return tx.Omit("ApproverUser").Create(&chain.Steps).Error
+	})
+}
+
+func (r *approvalChainRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ApprovalChain{}, id).Error
+}
+
+// CountInFlight counts the contracts still going through the chain
+func (r *approvalChainRepository) CountInFlight(ctx context.Context, chainID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Contract{}).
+		Where("approval_chain_id = ? AND status IN ?", chainID, []string{models.ContractStatusPending, models.ContractStatusSubmitted}).
+		Count(&count).Error
+	return count, err
+}
+
+// CountUsed counts the contracts ever assigned to the chain
+func (r *approvalChainRepository) CountUsed(ctx context.Context, chainID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Contract{}).Where("approval_chain_id = ?", chainID).Count(&count).Error
+	return count, err
+}
+
+// Submit moves the contract to submitted (compare-and-set on its status) and starts the approval
+// chain from its first step. Returns false when the contract status changed meanwhile.
+func (r *approvalChainRepository) Submit(ctx context.Context, contractID uint, fromStatus string, chainID *uint, steps int) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&models.Contract{}).
+		Where("id = ? AND status = ?", contractID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":            models.ContractStatusSubmitted,
+			"submitted_at":      now,
+			"status_changed_at": now,
+			"sla_escalated_at":  nil,
+			"approval_chain_id": chainID,
+			"approval_step":     0,
+			"approval_steps":    steps,
+			"rejection_reason":  nil,
+			"updated_at":        gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordDecision writes a chain decision on the contract's current step. An approval advances
+// the step with compare-and-set, so two approvers cannot both decide the same step; returns
+// false when the step was already decided.
+func (r *approvalChainRepository) RecordDecision(ctx context.Context, approval *models.ContractApproval, fromStep int) (bool, error) {
+	decided := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		nextStep := fromStep
+		if approval.Decision == models.ApprovalDecisionApproved {
+			nextStep = fromStep + 1
+		}
+		result := tx.Model(&models.Contract{}).
+			Where("id = ? AND status = ? AND approval_chain_id = ? AND approval_step = ?",
+				approval.ContractID, models.ContractStatusSubmitted, approval.ChainID, fromStep).
+			Updates(map[string]interface{}{
+				"approval_step": nextStep,
+				"updated_at":    gorm.Expr("CURRENT_TIMESTAMP"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Omit("Approver").Create(approval).Error; err != nil {
+			return err
+		}
+		decided = true
+		return nil
+	})
+	return decided, err
+}
+
+// FindApprovalsByContract returns the chain decisions on a contract, oldest first
+func (r *approvalChainRepository) FindApprovalsByContract(ctx context.Context, contractID uint) ([]models.ContractApproval, error) {
+	var approvals []models.ContractApproval
+	err := r.db.WithContext(ctx).Preload("Approver").
+		Where("contract_id = ?", contractID).
+		Order("created_at ASC, id ASC").
+		Find(&approvals).Error
+	return approvals, err
+}
+
+// FindPendingForApprover returns the submitted contracts whose current chain step the user
+// approves, by user or by role, oldest submission first
+func (r *approvalChainRepository) FindPendingForApprover(ctx context.Context, user *models.User) ([]models.Contract, error) {
+	var contracts []models.Contract
+	err := r.db.WithContext(ctx).
+		Joins("JOIN approval_chain_steps ON approval_chain_steps.chain_id = contracts.approval_chain_id AND approval_chain_steps.position = contracts.approval_step + 1").
+		Joins("Lot").
+		Joins("Lot.Project").
+		Joins("ApplicantUser").
+		Joins("Creator").
+		Where("contracts.status = ?", models.ContractStatusSubmitted).
+		Where("approval_chain_steps.approver_user_id = ? OR (approval_chain_steps.approver_user_id IS NULL AND approval_chain_steps.approver_role = ?)", user.ID, user.Role).
+		Order("contracts.submitted_at ASC, contracts.id ASC").
+		Find(&contracts).Error
+	return contracts, err
+}
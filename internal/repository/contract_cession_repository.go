@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ContractCessionRepository defines the interface for contract cession data access
+type ContractCessionRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.ContractCession, error)
+	FindByContract(ctx context.Context, contractID uint) ([]models.ContractCession, error)
+	Cede(ctx context.Context, cession *models.ContractCession, removedPartyIDs []uint, feePayment *models.Payment, feeEntry *models.ContractLedgerEntry) (bool, error)
+}
+
+type contractCessionRepository struct {
+	db *gorm.DB
+}
+
+// NewContractCessionRepository creates a new contract cession repository
+func NewContractCessionRepository(db *gorm.DB) ContractCessionRepository {
+	return &contractCessionRepository{db: db}
+}
+
+func (r *contractCessionRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("FromUser").Preload("ToUser").Preload("Actor")
+}
+
+func (r *contractCessionRepository) FindByID(ctx context.Context, id uint) (*models.ContractCession, error) {
+	var cession models.ContractCession
+	err := r.preload(r.db.WithContext(ctx)).First(&cession, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cession, nil
+}
+
+// FindByContract returns the cessions of a contract, oldest first
+func (r *contractCessionRepository) FindByContract(ctx context.Context, contractID uint) ([]models.ContractCession, error) {
+	var cessions []models.ContractCession
+	err := r.preload(r.db.WithContext(ctx)).
+		Where("contract_id = ?", contractID).
+		Order("created_at ASC, id ASC").
+		Find(&cessions).Error
+	return cessions, err
+}
+
+// Cede moves an approved contract from cession.FromUserID to cession.ToUserID (compare-and-set on
+// the applicant), removes the previous buyer's parties not carried over, charges the optional fee
+// and records the cession in one transaction. Pending payments get their reminder marks cleared so
+// the new buyer receives the reminders.
+// Returns false when the contract is no longer approved or changed hands meanwhile.
+func (r *contractCessionRepository) Cede(ctx context.Context, cession *models.ContractCession, removedPartyIDs []uint, feePayment *models.Payment, feeEntry *models.ContractLedgerEntry) (bool, error) {
+	moved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Contract{}).
+			Where("id = ? AND applicant_user_id = ? AND status IN ?", cession.ContractID, cession.FromUserID, models.ContractInForceStatuses).
+			Updates(map[string]interface{}{"applicant_user_id": cession.ToUserID, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.Payment{}).
+			Where("contract_id = ? AND status IN ?", cession.ContractID, []string{models.PaymentStatusPending, models.PaymentStatusSubmitted}).
+			Updates(map[string]interface{}{"overdue_reminder_sent_at": nil, "upcoming_reminder_sent_at": nil}).Error; err != nil {
+			return err
+		}
+
+		if len(removedPartyIDs) > 0 {
+			if err := tx.Where("contract_id = ? AND id IN ?", cession.ContractID, removedPartyIDs).
+				Delete(&models.ContractParty{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if feePayment != nil {
+			if err := tx.Omit("Contract", "ApprovedByUser").Create(feePayment).Error; err != nil {
+				return err
+			}
+			cession.FeePaymentID = &feePayment.ID
+			if feeEntry != nil {
+				feeEntry.PaymentID = &feePayment.ID
+				if err := tx.Omit("Contract", "Payment").Create(feeEntry).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.Contract{}).Where("id = ?", cession.ContractID).
+					Update("balance", gorm.Expr("COALESCE(balance, 0) + ?", feeEntry.Amount)).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Omit("Contract", "FromUser", "ToUser", "Actor").Create(cession).Error; err != nil {
+			return err
+		}
+		moved = true
+		return nil
+	})
+	return moved, err
+}
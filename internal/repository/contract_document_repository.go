@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ContractDocumentRepository defines the interface for contract document data access
+type ContractDocumentRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.ContractDocument, error)
+	FindCurrentByContract(ctx context.Context, contractID uint) ([]models.ContractDocument, error)
+	FindVersions(ctx context.Context, lineageID uint) ([]models.ContractDocument, error)
+	Create(ctx context.Context, document *models.ContractDocument) error
+	Replace(ctx context.Context, current *models.ContractDocument, next *models.ContractDocument) (bool, error)
+	SoftDelete(ctx context.Context, lineageID uint, deletedByID uint) error
+}
+
+type contractDocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewContractDocumentRepository creates a new contract document repository
+func NewContractDocumentRepository(db *gorm.DB) ContractDocumentRepository {
+	return &contractDocumentRepository{db: db}
+}
+
+func (r *contractDocumentRepository) FindByID(ctx context.Context, id uint) (*models.ContractDocument, error) {
+	var document models.ContractDocument
+	if err := r.db.WithContext(ctx).Preload("UploadedBy").First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// FindCurrentByContract returns the live version of each document of the contract, by type and upload
+func (r *contractDocumentRepository) FindCurrentByContract(ctx context.Context, contractID uint) ([]models.ContractDocument, error) {
+	var documents []models.ContractDocument
+	err := r.db.WithContext(ctx).Preload("UploadedBy").
+		Where("contract_id = ? AND superseded_at IS NULL AND deleted_at IS NULL", contractID).
+		Order("document_type ASC, created_at ASC").
+		Find(&documents).Error
+	return documents, err
+}
+
+// FindVersions returns every version of a document, oldest first
+func (r *contractDocumentRepository) FindVersions(ctx context.Context, lineageID uint) ([]models.ContractDocument, error) {
+	var documents []models.ContractDocument
+	err := r.db.WithContext(ctx).Preload("UploadedBy").
+		Where("lineage_id = ?", lineageID).
+		Order("version ASC").
+		Find(&documents).Error
+	return documents, err
+}
+
+// Create stores the first version of a document; its lineage is its own ID
+func (r *contractDocumentRepository) Create(ctx context.Context, document *models.ContractDocument) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("UploadedBy").Create(document).Error; err != nil {
+			return err
+		}
+		document.LineageID = document.ID
+		return tx.Model(document).UpdateColumn("lineage_id", document.ID).Error
+	})
+}
+
+// Replace supersedes the current version (compare-and-set, so two replacements cannot both win)
+// and stores next as the following version. Returns false when current was replaced or deleted meanwhile.
+func (r *contractDocumentRepository) Replace(ctx context.Context, current *models.ContractDocument, next *models.ContractDocument) (bool, error) {
+	replaced := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ContractDocument{}).
+			Where("id = ? AND superseded_at IS NULL AND deleted_at IS NULL", current.ID).
+			UpdateColumn("superseded_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		next.LineageID = current.LineageID
+		next.Version = current.Version + 1
+		if err := tx.Omit("UploadedBy").Create(next).Error; err != nil {
+			return err
+		}
+		replaced = true
+		return nil
+	})
+	return replaced, err
+}
+
+// SoftDelete marks every version of a document deleted; the files are kept
+func (r *contractDocumentRepository) SoftDelete(ctx context.Context, lineageID uint, deletedByID uint) error {
+	return r.db.WithContext(ctx).Model(&models.ContractDocument{}).
+		Where("lineage_id = ? AND deleted_at IS NULL", lineageID).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "deleted_by_id": deletedByID}).Error
+}
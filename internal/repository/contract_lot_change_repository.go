@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ContractLotChangeSet is everything written when a contract moves to another lot
+type ContractLotChangeSet struct {
+	Change            *models.ContractLotChange
+	Commission        float64
+	LedgerEntry       *models.ContractLedgerEntry // price difference adjustment; nil when the prices match
+	LineItem          *models.ContractLineItem
+	ResizedPayments   []models.Payment // pending payments with their new amount and description
+	DroppedPaymentIDs []uint           // pending payments no longer needed after the change
+	AddedPayment      *models.Payment  // new payment when nothing pending could absorb the difference
+}
+
+// ContractLotChangeRepository defines the interface for contract lot change data access
+type ContractLotChangeRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.ContractLotChange, error)
+	FindByContract(ctx context.Context, contractID uint) ([]models.ContractLotChange, error)
+	Apply(ctx context.Context, set *ContractLotChangeSet) (bool, error)
+}
+
+type contractLotChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewContractLotChangeRepository creates a new contract lot change repository
+func NewContractLotChangeRepository(db *gorm.DB) ContractLotChangeRepository {
+	return &contractLotChangeRepository{db: db}
+}
+
+func (r *contractLotChangeRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("FromLot.Project").Preload("ToLot.Project").Preload("Actor")
+}
+
+func (r *contractLotChangeRepository) FindByID(ctx context.Context, id uint) (*models.ContractLotChange, error) {
+	var change models.ContractLotChange
+	err := r.preload(r.db.WithContext(ctx)).First(&change, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// FindByContract returns the lot changes of a contract, oldest first
+func (r *contractLotChangeRepository) FindByContract(ctx context.Context, contractID uint) ([]models.ContractLotChange, error) {
+	var changes []models.ContractLotChange
+	err := r.preload(r.db.WithContext(ctx)).
+		Where("contract_id = ?", contractID).
+		Order("created_at ASC, id ASC").
+		Find(&changes).Error
+	return changes, err
+}
+
+// Apply moves the contract to the new lot (compare-and-set on its current lot and approved status)
+// and writes the adjustment, line item, resized schedule and change record in one transaction.
+// Returns false when the contract is no longer approved on the old lot.
+func (r *contractLotChangeRepository) Apply(ctx context.Context, set *ContractLotChangeSet) (bool, error) {
+	change := set.Change
+	moved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fields := map[string]interface{}{
+			"lot_id":            change.ToLotID,
+			"amount":            change.NewAmount,
+			"commission_amount": set.Commission,
+			"updated_at":        gorm.Expr("CURRENT_TIMESTAMP"),
+		}
+		if set.LedgerEntry != nil {
+			fields["balance"] = gorm.Expr("COALESCE(balance, 0) + ?", set.LedgerEntry.Amount)
+		}
+		result := tx.Model(&models.Contract{}).
+			Where("id = ? AND lot_id = ? AND status IN ?", change.ContractID, change.FromLotID, models.ContractInForceStatuses).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if set.LedgerEntry != nil {
+			if err := tx.Omit("Contract", "Payment").Create(set.LedgerEntry).Error; err != nil {
+				return err
+			}
+			change.LedgerEntryID = &set.LedgerEntry.ID
+		}
+		if set.LineItem != nil {
+			if err := tx.Create(set.LineItem).Error; err != nil {
+				return err
+			}
+		}
+		for _, p := range set.ResizedPayments {
+			if err := tx.Model(&models.Payment{}).
+				Where("id = ? AND status = ?", p.ID, models.PaymentStatusPending).
+				Updates(map[string]interface{}{"amount": p.Amount, "description": p.Description}).Error; err != nil {
+				return err
+			}
+		}
+		if len(set.DroppedPaymentIDs) > 0 {
+			if err := tx.Where("id IN ? AND status = ?", set.DroppedPaymentIDs, models.PaymentStatusPending).
+				Delete(&models.Payment{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if set.AddedPayment != nil {
+			if err := tx.Omit("Contract", "ApprovedByUser").Create(set.AddedPayment).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Omit("FromLot", "ToLot", "Actor").Create(change).Error; err != nil {
+			return err
+		}
+		moved = true
+		return nil
+	})
+	return moved, err
+}
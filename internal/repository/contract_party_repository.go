@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ContractPartyRepository defines the interface for contract party data access
+type ContractPartyRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.ContractParty, error)
+	FindByContract(ctx context.Context, contractID uint) ([]models.ContractParty, error)
+	FindByUser(ctx context.Context, userID uint) ([]models.ContractParty, error)
+	Create(ctx context.Context, party *models.ContractParty) error
+	Update(ctx context.Context, party *models.ContractParty) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type contractPartyRepository struct {
+	db *gorm.DB
+}
+
+// NewContractPartyRepository creates a new contract party repository
+func NewContractPartyRepository(db *gorm.DB) ContractPartyRepository {
+	return &contractPartyRepository{db: db}
+}
+
+func (r *contractPartyRepository) FindByID(ctx context.Context, id uint) (*models.ContractParty, error) {
+	var party models.ContractParty
+	err := r.db.WithContext(ctx).Preload("User").First(&party, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &party, nil
+}
+
+func (r *contractPartyRepository) FindByContract(ctx context.Context, contractID uint) ([]models.ContractParty, error) {
+	var parties []models.ContractParty
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("contract_id = ?", contractID).
+		Order("id ASC").
+		Find(&parties).Error
+	return parties, err
+}
+
+// FindByUser returns the parties a registered user is in across all contracts (used by credit scoring)
+func (r *contractPartyRepository) FindByUser(ctx context.Context, userID uint) ([]models.ContractParty, error) {
+	var parties []models.ContractParty
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Find(&parties).Error
+	return parties, err
+}
+
+func (r *contractPartyRepository) Create(ctx context.Context, party *models.ContractParty) error {
+	return r.db.WithContext(ctx).Omit("User").Create(party).Error
+}
+
+func (r *contractPartyRepository) Update(ctx context.Context, party *models.ContractParty) error {
+	return r.db.WithContext(ctx).Omit("User").Save(party).Error
+}
+
+func (r *contractPartyRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ContractParty{}, id).Error
+}
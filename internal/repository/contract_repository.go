@@ -8,6 +8,7 @@ import (
 
 	"github.com/sjperalta/fintera-api/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ContractRepository defines the interface for contract data access
@@ -24,6 +25,11 @@ type ContractRepository interface {
 	FindPendingReservations(ctx context.Context, olderThan int) ([]models.Contract, error)
 	GetStats(ctx context.Context) (*ContractStats, error)
 	HasActiveContracts(ctx context.Context, userID uint) (bool, error)
+
+	CreateStatusTransition(ctx context.Context, transition *models.ContractStatusTransition) error
+	FindStatusTransitions(ctx context.Context, contractID uint) ([]models.ContractStatusTransition, error)
+	CreateTermsVersion(ctx context.Context, version *models.ContractTermsVersion) error
+	FindTermsVersions(ctx context.Context, contractID uint) ([]models.ContractTermsVersion, error)
 }
 
 // ContractQuery extends ListQuery with contract-specific filters
@@ -56,19 +62,27 @@ func (r *contractRepository) FindByID(ctx context.Context, id uint) (*models.Con
 
 func (r *contractRepository) FindByIDWithDetails(ctx context.Context, id uint) (*models.Contract, error) {
 	var contract models.Contract
-	// Load contract + Lot, Project, ApplicantUser, Creator in one query via Joins (avoids 4 separate Preload round-trips).
-	// Payments and LedgerEntries are one-to-many so we keep 2 Preloads (3 queries total instead of 6).
+	// Load contract + Lot, Project, ApplicantUser, Creator, PriceList in one query via Joins (avoids 5 separate Preload round-trips).
+	// Payments, LedgerEntries, LineItems and Parties are one-to-many so we keep them as Preloads.
 	err := r.db.WithContext(ctx).
 		Joins("Lot").
 		Joins("Lot.Project").
 		Joins("ApplicantUser").
 		Joins("Creator").
+		Joins("PriceList").
 		Preload("Payments", func(db *gorm.DB) *gorm.DB {
 			return db.Order("due_date ASC")
 		}).
 		Preload("LedgerEntries", func(db *gorm.DB) *gorm.DB {
 			return db.Order("entry_date ASC")
 		}).
+		Preload("LineItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Parties", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Parties.User").
 		First(&contract, id).Error
 	if err != nil {
 		return nil, err
@@ -284,8 +298,8 @@ func (r *contractRepository) GetStats(ctx context.Context) (*ContractStats, erro
 		switch status {
 		case models.ContractStatusPending:
 			stats.Pending = count
-		case models.ContractStatusApproved:
-			stats.Approved = count
+		case models.ContractStatusApproved, models.ContractStatusSigned:
+			stats.Approved += count
 		case models.ContractStatusRejected:
 			stats.Rejected = count
 		}
@@ -322,11 +336,55 @@ func (r *contractRepository) HasActiveContracts(ctx context.Context, userID uint
 	err := r.db.WithContext(ctx).
 		Model(&models.Contract{}).
 		Where("applicant_user_id = ?", userID).
-		Where("active = ? OR status IN ?", true, []string{models.ContractStatusApproved, models.ContractStatusSigned}).
+		Where("active = ? OR status IN ?", true, models.ContractInForceStatuses).
 		Count(&count).Error
 	return count > 0, err
 }
 
+func (r *contractRepository) CreateStatusTransition(ctx context.Context, transition *models.ContractStatusTransition) error {
+	return r.db.WithContext(ctx).Omit("Actor").Create(transition).Error
+}
+
+// FindStatusTransitions returns the status history of a contract, oldest first
+func (r *contractRepository) FindStatusTransitions(ctx context.Context, contractID uint) ([]models.ContractStatusTransition, error) {
+	var transitions []models.ContractStatusTransition
+	err := r.db.WithContext(ctx).Preload("Actor").
+		Where("contract_id = ?", contractID).
+		Order("created_at ASC, id ASC").
+		Find(&transitions).Error
+	return transitions, err
+}
+
+// CreateTermsVersion stores a terms snapshot as the contract's next version. The contract row is
+// locked while numbering so concurrent amendments get consecutive versions.
+func (r *contractRepository) CreateTermsVersion(ctx context.Context, version *models.ContractTermsVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var contract models.Contract
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&contract, version.ContractID).Error; err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&models.ContractTermsVersion{}).
+			Where("contract_id = ?", version.ContractID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		version.Version = last + 1
+		return tx.Omit("Actor").Create(version).Error
+	})
+}
+
+// FindTermsVersions returns the terms versions of a contract, oldest first
+func (r *contractRepository) FindTermsVersions(ctx context.Context, contractID uint) ([]models.ContractTermsVersion, error) {
+	var versions []models.ContractTermsVersion
+	err := r.db.WithContext(ctx).Preload("Actor").
+		Where("contract_id = ?", contractID).
+		Order("version ASC").
+		Find(&versions).Error
+	return versions, err
+}
+
 // PaymentStats holds monthly payment statistics
 type PaymentStats struct {
 	PendingThisMonth   float64 `json:"pending_this_month"`
@@ -444,6 +502,13 @@ func (r *paymentRepository) List(ctx context.Context, query *ListQuery) ([]model
 		db = db.Where("payments.payment_date <= ? OR (payments.payment_date IS NULL AND payments.due_date <= ?)", endDate, endDate)
 	}
 
+	// Project / phase / block of the contract's lot
+	for _, f := range []string{"project_id", "phase_id", "block_id"} {
+		if val := query.Filters[f]; val != "" {
+			db = db.Where("payments.contract_id IN (SELECT contracts.id FROM contracts JOIN lots ON lots.id = contracts.lot_id WHERE lots."+f+" = ?)", val)
+		}
+	}
+
 	// Apply search filter if provided (case-insensitive across multiple fields)
 	if search := query.Filters["search_term"]; search != "" {
 		term := "%" + search + "%"
@@ -497,6 +562,8 @@ func (r *paymentRepository) List(ctx context.Context, query *ListQuery) ([]model
 	err := db.
 		Select("payments.*"). // Ensure we select only payment fields, especially when joining
 		Preload("Contract.Lot.Project").
+		Preload("Contract.Lot.Phase").
+		Preload("Contract.Lot.Block").
 		Preload("Contract.ApplicantUser").
 		Preload("ApprovedByUser").
 		Find(&payments).Error
@@ -510,19 +577,20 @@ func (r *paymentRepository) FindOverdue(ctx context.Context) ([]models.Payment,
 		Where("payments.status = ? AND payments.due_date < CURRENT_DATE", models.PaymentStatusPending).
 		Preload("Contract.Lot.Project").
 		Preload("Contract.ApplicantUser").
+		Preload("Contract.Parties").
 		Order("due_date ASC").
 		Find(&payments).Error
 	return payments, err
 }
 
-// FindOverdueForActiveContracts returns overdue payments only for active contracts (approved, active=true)
+// FindOverdueForActiveContracts returns overdue payments only for active contracts (approved or signed, active=true)
 // and active users (status=active, not discarded). Excludes payments that had a reminder sent in the last 7 days
 // to avoid spamming. Preloads Contract.Lot and Contract.ApplicantUser for email templates.
 func (r *paymentRepository) FindOverdueForActiveContracts(ctx context.Context) ([]models.Payment, error) {
 	var payments []models.Payment
 	err := r.db.WithContext(ctx).
-		Joins("JOIN contracts ON contracts.id = payments.contract_id AND contracts.status = ? AND contracts.active = ?",
-			models.ContractStatusApproved, true).
+		Joins("JOIN contracts ON contracts.id = payments.contract_id AND contracts.status IN ? AND contracts.active = ?",
+			models.ContractInForceStatuses, true).
 		Joins("JOIN users ON users.id = contracts.applicant_user_id AND users.status = ? AND users.discarded_at IS NULL",
 			models.StatusActive).
 		Where("payments.status = ? AND payments.due_date < CURRENT_DATE", models.PaymentStatusPending).
@@ -539,8 +607,8 @@ func (r *paymentRepository) FindOverdueForActiveContracts(ctx context.Context) (
 func (r *paymentRepository) FindPaymentsDueTomorrowForActiveContracts(ctx context.Context) ([]models.Payment, error) {
 	var payments []models.Payment
 	err := r.db.WithContext(ctx).
-		Joins("JOIN contracts ON contracts.id = payments.contract_id AND contracts.status = ? AND contracts.active = ?",
-			models.ContractStatusApproved, true).
+		Joins("JOIN contracts ON contracts.id = payments.contract_id AND contracts.status IN ? AND contracts.active = ?",
+			models.ContractInForceStatuses, true).
 		Joins("JOIN users ON users.id = contracts.applicant_user_id AND users.status = ? AND users.discarded_at IS NULL",
 			models.StatusActive).
 		Where("payments.status = ? AND payments.due_date = CURRENT_DATE + INTERVAL '1 day'", models.PaymentStatusPending).
@@ -685,6 +753,7 @@ type ProjectRepository interface {
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, query *ListQuery) ([]models.Project, int64, error)
 	FindAll(ctx context.Context) ([]models.Project, error)
+	UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error)
 }
 
 type projectRepository struct {
@@ -712,6 +781,19 @@ func (r *projectRepository) Update(ctx context.Context, project *models.Project)
 	return r.db.WithContext(ctx).Save(project).Error
 }
 
+// UpdateStatus changes the project status only if it still has fromStatus (compare-and-set), so
+// concurrent approvals cannot both win. Returns false when the project changed meanwhile.
+func (r *projectRepository) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": toStatus, "updated_at": time.Now()}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := r.db.WithContext(ctx).Model(&models.Project{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *projectRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.Project{}, id).Error
 }
@@ -730,6 +812,9 @@ func (r *projectRepository) List(ctx context.Context, query *ListQuery) ([]model
 	if val, ok := query.Filters["guid"]; ok && val != "" {
 		db = db.Where("guid = ?", val)
 	}
+	if val, ok := query.Filters["status"]; ok && val != "" {
+		db = db.Where("status IN ?", strings.Split(val, ","))
+	}
 
 	db.Count(&total)
 
@@ -765,6 +850,15 @@ type LotRepository interface {
 	Update(ctx context.Context, lot *models.Lot) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, projectID uint, query *ListQuery) ([]models.Lot, int64, error)
+	UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error)
+	CreateStatusHistory(ctx context.Context, entry *models.LotStatusHistory) error
+	FindStatusHistory(ctx context.Context, lotID uint) ([]models.LotStatusHistory, error)
+	CreateHold(ctx context.Context, hold *models.LotHold) error
+	FindHoldByID(ctx context.Context, id uint) (*models.LotHold, error)
+	FindActiveHold(ctx context.Context, lotID uint) (*models.LotHold, error)
+	ListHolds(ctx context.Context, sellerID *uint, status string) ([]models.LotHold, error)
+	FindExpiredHolds(ctx context.Context, at time.Time) ([]models.LotHold, error)
+	UpdateHoldStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error)
 }
 
 type lotRepository struct {
@@ -777,7 +871,7 @@ func NewLotRepository(db *gorm.DB) LotRepository {
 
 func (r *lotRepository) FindByID(ctx context.Context, id uint) (*models.Lot, error) {
 	var lot models.Lot
-	err := r.db.WithContext(ctx).Preload("Project").First(&lot, id).Error
+	err := r.db.WithContext(ctx).Preload("Project").Preload("Phase").Preload("Block").First(&lot, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -787,6 +881,8 @@ func (r *lotRepository) FindByID(ctx context.Context, id uint) (*models.Lot, err
 func (r *lotRepository) FindByProject(ctx context.Context, projectID uint) ([]models.Lot, error) {
 	var lots []models.Lot
 	err := r.db.WithContext(ctx).
+		Preload("Phase").
+		Preload("Block").
 		Where("project_id = ?", projectID).
 		Order("name ASC").
 		Find(&lots).Error
@@ -794,11 +890,13 @@ func (r *lotRepository) FindByProject(ctx context.Context, projectID uint) ([]mo
 }
 
 func (r *lotRepository) Create(ctx context.Context, lot *models.Lot) error {
-	return r.db.WithContext(ctx).Create(lot).Error
+	return r.db.WithContext(ctx).Omit("Phase", "Block").Create(lot).Error
 }
 
+// Update saves the lot except its status, which only changes through UpdateStatus (lot FSM).
+// Phase and block are set by their IDs only.
 func (r *lotRepository) Update(ctx context.Context, lot *models.Lot) error {
-	return r.db.WithContext(ctx).Save(lot).Error
+	return r.db.WithContext(ctx).Omit("status", "Phase", "Block").Save(lot).Error
 }
 
 func (r *lotRepository) Delete(ctx context.Context, id uint) error {
@@ -819,9 +917,28 @@ func (r *lotRepository) List(ctx context.Context, projectID uint, query *ListQue
 	if query.Filters["status"] != "" {
 		db = db.Where("lots.status = ?", query.Filters["status"])
 	}
+	if phaseID := query.Filters["phase_id"]; phaseID == "none" {
+		db = db.Where("lots.phase_id IS NULL")
+	} else if phaseID != "" {
+		db = db.Where("lots.phase_id = ?", phaseID)
+	}
+	if blockID := query.Filters["block_id"]; blockID == "none" {
+		db = db.Where("lots.block_id IS NULL")
+	} else if blockID != "" {
+		db = db.Where("lots.block_id = ?", blockID)
+	}
 
 	db.Count(&total)
 
+	if query.Filters["group_by"] != "" {
+		// Keep the lots of each phase/block together, in hierarchy order
+		db = db.Order("(SELECT position FROM project_phases WHERE project_phases.id = lots.phase_id) ASC NULLS LAST").
+			Order("lots.phase_id ASC NULLS LAST")
+		if query.Filters["group_by"] == "block" {
+			db = db.Order("(SELECT position FROM project_blocks WHERE project_blocks.id = lots.block_id) ASC NULLS LAST").
+				Order("lots.block_id ASC NULLS LAST")
+		}
+	}
 	if query.SortBy != "" {
 		order := query.SortBy
 		if query.SortDir == "desc" {
@@ -837,15 +954,106 @@ func (r *lotRepository) List(ctx context.Context, projectID uint, query *ListQue
 	}
 
 	err := db.Preload("Project").
+		Preload("Phase").
+		Preload("Block").
 		Preload("Contracts", func(db *gorm.DB) *gorm.DB {
 			return db.Order("created_at DESC")
 		}).
 		Preload("Contracts.ApplicantUser").
 		Preload("Contracts.Creator").
+		Preload("ActiveHold", "status = ?", models.LotHoldStatusActive).
+		Preload("ActiveHold.Seller").
 		Find(&lots).Error
 	return lots, total, err
 }
 
+// UpdateStatus changes the lot status only if it still has fromStatus (compare-and-set), so
+// concurrent events cannot overwrite each other. Returns false when the lot changed meanwhile.
+func (r *lotRepository) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Lot{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(map[string]interface{}{"status": toStatus, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *lotRepository) CreateStatusHistory(ctx context.Context, entry *models.LotStatusHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *lotRepository) FindStatusHistory(ctx context.Context, lotID uint) ([]models.LotStatusHistory, error) {
+	var entries []models.LotStatusHistory
+	err := r.db.WithContext(ctx).
+		Where("lot_id = ?", lotID).
+		Order("created_at DESC, id DESC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *lotRepository) CreateHold(ctx context.Context, hold *models.LotHold) error {
+	return r.db.WithContext(ctx).Create(hold).Error
+}
+
+func (r *lotRepository) FindHoldByID(ctx context.Context, id uint) (*models.LotHold, error) {
+	var hold models.LotHold
+	err := r.db.WithContext(ctx).Preload("Lot").Preload("Seller").First(&hold, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindActiveHold returns the active hold of a lot (at most one, enforced by a partial unique index)
+func (r *lotRepository) FindActiveHold(ctx context.Context, lotID uint) (*models.LotHold, error) {
+	var hold models.LotHold
+	err := r.db.WithContext(ctx).
+		Preload("Lot").
+		Preload("Seller").
+		Where("lot_id = ? AND status = ?", lotID, models.LotHoldStatusActive).
+		First(&hold).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// ListHolds returns holds, optionally of one seller and/or status, newest first
+func (r *lotRepository) ListHolds(ctx context.Context, sellerID *uint, status string) ([]models.LotHold, error) {
+	var holds []models.LotHold
+	db := r.db.WithContext(ctx).Preload("Lot").Preload("Seller")
+	if sellerID != nil {
+		db = db.Where("seller_id = ?", *sellerID)
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Order("created_at DESC").Find(&holds).Error
+	return holds, err
+}
+
+// FindExpiredHolds returns active holds whose expiry time has passed
+func (r *lotRepository) FindExpiredHolds(ctx context.Context, at time.Time) ([]models.LotHold, error) {
+	var holds []models.LotHold
+	err := r.db.WithContext(ctx).
+		Preload("Lot").
+		Where("status = ? AND expires_at <= ?", models.LotHoldStatusActive, at).
+		Order("expires_at ASC").
+		Find(&holds).Error
+	return holds, err
+}
+
+// UpdateHoldStatus changes the hold status only if it still has fromStatus (compare-and-set), so
+// expiry, release and contract conversion cannot both win. Returns false when the hold changed meanwhile.
+func (r *lotRepository) UpdateHoldStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": toStatus, "updated_at": time.Now()}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := r.db.WithContext(ctx).Model(&models.LotHold{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
 // NotificationRepository defines the interface for notification data access
 type NotificationRepository interface {
 	FindByID(ctx context.Context, id uint) (*models.Notification, error)
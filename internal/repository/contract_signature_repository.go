@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContractSignatureRepository defines the interface for promise contract signature data access
+type ContractSignatureRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.SignatureRequest, error)
+	FindLatestByContract(ctx context.Context, contractID uint) (*models.SignatureRequest, error)
+	Create(ctx context.Context, request *models.SignatureRequest) error
+	SetOTP(ctx context.Context, signerID uint, userID uint, otpHash string, expiresAt time.Time) (bool, error)
+	IncrementOTPAttempts(ctx context.Context, signerID uint) error
+	Sign(ctx context.Context, signer *models.ContractSigner) (bool, error)
+	Complete(ctx context.Context, request *models.SignatureRequest, contract *models.Contract, fromStatus string) (bool, error)
+	Cancel(ctx context.Context, requestID uint, at time.Time) (bool, error)
+}
+
+// errSignatureChanged rolls back a completion whose request or contract changed meanwhile
+var errSignatureChanged = errors.New("signature request or contract changed")
+
+type contractSignatureRepository struct {
+	db *gorm.DB
+}
+
+// NewContractSignatureRepository creates a new contract signature repository
+func NewContractSignatureRepository(db *gorm.DB) ContractSignatureRepository {
+	return &contractSignatureRepository{db: db}
+}
+
+func (r *contractSignatureRepository) FindByID(ctx context.Context, id uint) (*models.SignatureRequest, error) {
+	var request models.SignatureRequest
+	err := r.db.WithContext(ctx).
+		Preload("Signers", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Signers.User").
+		First(&request, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindLatestByContract returns the most recent signature request of the contract, cancelled or not
+func (r *contractSignatureRepository) FindLatestByContract(ctx context.Context, contractID uint) (*models.SignatureRequest, error) {
+	var request models.SignatureRequest
+	err := r.db.WithContext(ctx).
+		Preload("Signers", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Signers.User").
+		Where("contract_id = ?", contractID).
+		Order("created_at DESC, id DESC").
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// Create stores a signature request with its signers
+func (r *contractSignatureRepository) Create(ctx context.Context, request *models.SignatureRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		signers := request.Signers
+		if err := tx.Omit("Signers").Create(request).Error; err != nil {
+			return err
+		}
+		for i := range signers {
+			signers[i].RequestID = request.ID
+			if err := tx.Omit("User").Create(&signers[i]).Error; err != nil {
+				return err
+			}
+		}
+		request.Signers = signers
+		return nil
+	})
+}
+
+// SetOTP stores a new one-time code for an unsigned signer and resets its attempts. The signer is
+// claimed by the user when it has none yet (the admin slot); returns false when it is signed or
+// claimed by someone else.
+func (r *contractSignatureRepository) SetOTP(ctx context.Context, signerID uint, userID uint, otpHash string, expiresAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ContractSigner{}).
+		Where("id = ? AND signed_at IS NULL AND (user_id IS NULL OR user_id = ?)", signerID, userID).
+		Updates(map[string]interface{}{
+			"user_id":        userID,
+			"otp_hash":       otpHash,
+			"otp_expires_at": expiresAt,
+			"otp_attempts":   0,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *contractSignatureRepository) IncrementOTPAttempts(ctx context.Context, signerID uint) error {
+	return r.db.WithContext(ctx).Model(&models.ContractSigner{}).
+		Where("id = ?", signerID).
+		UpdateColumn("otp_attempts", gorm.Expr("otp_attempts + 1")).Error
+}
+
+// Sign stores the signature of an unsigned signer. The request row is locked so signatures are
+// chained one at a time: it returns false when the signer already signed or when another signature
+// moved the head of the chain away from signer.PrevHash.
+func (r *contractSignatureRepository) Sign(ctx context.Context, signer *models.ContractSigner) (bool, error) {
+	signed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var request models.SignatureRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Signers").
+			Where("status = ?", models.SignatureStatusPending).
+			First(&request, signer.RequestID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if signer.PrevHash == nil || request.LastHash() != *signer.PrevHash {
+			return nil
+		}
+		result := tx.Model(&models.ContractSigner{}).
+			Where("id = ? AND signed_at IS NULL", signer.ID).
+			Updates(map[string]interface{}{
+				"signature_path":   signer.SignaturePath,
+				"signature_sha256": signer.SignatureSHA256,
+				"signed_at":        signer.SignedAt,
+				"ip_address":       signer.IPAddress,
+				"user_agent":       signer.UserAgent,
+				"prev_hash":        signer.PrevHash,
+				"hash":             signer.Hash,
+				"otp_hash":         nil,
+				"otp_expires_at":   nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		signed = result.RowsAffected > 0
+		return nil
+	})
+	return signed, err
+}
+
+// Complete closes a pending request with its signed document and chain hash
+// Complete closes a pending request and moves its contract from fromStatus to the contract's new
+// status in one transaction; false (nothing written) when either changed meanwhile
+func (r *contractSignatureRepository) Complete(ctx context.Context, request *models.SignatureRequest, contract *models.Contract, fromStatus string) (bool, error) {
+	completed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SignatureRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.SignatureStatusPending).
+			Updates(map[string]interface{}{
+				"status":             models.SignatureStatusCompleted,
+				"signed_document_id": request.SignedDocumentID,
+				"signed_sha256":      request.SignedSHA256,
+				"chain_hash":         request.ChainHash,
+				"completed_at":       request.CompletedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSignatureChanged
+		}
+		result = tx.Model(&models.Contract{}).
+			Where("id = ? AND status = ?", contract.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":            contract.Status,
+				"status_changed_at": contract.StatusChangedAt,
+				"sla_escalated_at":  nil,
+				"updated_at":        time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSignatureChanged
+		}
+		completed = true
+		return nil
+	})
+	if errors.Is(err, errSignatureChanged) {
+		return false, nil
+	}
+	return completed, err
+}
+
+func (r *contractSignatureRepository) Cancel(ctx context.Context, requestID uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.SignatureRequest{}).
+		Where("id = ? AND status = ?", requestID, models.SignatureStatusPending).
+		Updates(map[string]interface{}{
+			"status":       models.SignatureStatusCancelled,
+			"cancelled_at": at,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ContractSLAQuery filters the contracts listed by time in status
+type ContractSLAQuery struct {
+	Statuses  []string
+	ProjectID uint // 0 = all projects
+}
+
+// ContractSLARepository defines the interface for contract status SLA data access
+type ContractSLARepository interface {
+	FindAll(ctx context.Context) ([]models.ContractStatusSLA, error)
+	FindByID(ctx context.Context, id uint) (*models.ContractStatusSLA, error)
+	FindRule(ctx context.Context, projectID *uint, status string) (*models.ContractStatusSLA, error)
+	Save(ctx context.Context, sla *models.ContractStatusSLA) error
+	Delete(ctx context.Context, id uint) error
+
+	FindContractsInStatus(ctx context.Context, query ContractSLAQuery) ([]models.Contract, error)
+	MarkEscalated(ctx context.Context, contractID uint, status string, at time.Time) (bool, error)
+}
+
+type contractSLARepository struct {
+	db *gorm.DB
+}
+
+// NewContractSLARepository creates a new contract status SLA repository
+func NewContractSLARepository(db *gorm.DB) ContractSLARepository {
+	return &contractSLARepository{db: db}
+}
+
+func (r *contractSLARepository) FindAll(ctx context.Context) ([]models.ContractStatusSLA, error) {
+	var slas []models.ContractStatusSLA
+	err := r.db.WithContext(ctx).Preload("Project").
+		Order("project_id ASC NULLS FIRST, status ASC").
+		Find(&slas).Error
+	return slas, err
+}
+
+func (r *contractSLARepository) FindByID(ctx context.Context, id uint) (*models.ContractStatusSLA, error) {
+	var sla models.ContractStatusSLA
+	if err := r.db.WithContext(ctx).Preload("Project").First(&sla, id).Error; err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
+
+// FindRule returns the rule of the project (nil = default rule) for the status
+func (r *contractSLARepository) FindRule(ctx context.Context, projectID *uint, status string) (*models.ContractStatusSLA, error) {
+	var sla models.ContractStatusSLA
+	db := r.db.WithContext(ctx).Where("status = ?", status)
+	if projectID == nil {
+		db = db.Where("project_id IS NULL")
+	} else {
+		db = db.Where("project_id = ?", *projectID)
+	}
+	if err := db.First(&sla).Error; err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
+
+func (r *contractSLARepository) Save(ctx context.Context, sla *models.ContractStatusSLA) error {
+	return r.db.WithContext(ctx).Omit("Project").Save(sla).Error
+}
+
+func (r *contractSLARepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ContractStatusSLA{}, id).Error
+}
+
+// FindContractsInStatus returns the contracts in the statuses, longest in status first
+func (r *contractSLARepository) FindContractsInStatus(ctx context.Context, query ContractSLAQuery) ([]models.Contract, error) {
+	var contracts []models.Contract
+	db := r.db.WithContext(ctx).Model(&models.Contract{}).
+		Preload("Lot.Project").
+		Preload("ApplicantUser").
+		Preload("Creator").
+		Where("contracts.status IN ?", query.Statuses)
+	if query.ProjectID > 0 {
+		db = db.Joins("JOIN lots ON lots.id = contracts.lot_id").Where("lots.project_id = ?", query.ProjectID)
+	}
+	err := db.Order("COALESCE(contracts.status_changed_at, contracts.created_at) ASC").Find(&contracts).Error
+	return contracts, err
+}
+
+// MarkEscalated records the SLA escalation of the contract's current status. It only succeeds
+// while the contract is still in the status and not yet escalated, so each entry into a status
+// is escalated once. Returns false otherwise.
+func (r *contractSLARepository) MarkEscalated(ctx context.Context, contractID uint, status string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Contract{}).
+		Where("id = ? AND status = ? AND sla_escalated_at IS NULL", contractID, status).
+		UpdateColumn("sla_escalated_at", at)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FiscalRepository defines the interface for fiscal range and invoice data access
+type FiscalRepository interface {
+	CreateRange(ctx context.Context, fiscalRange *models.FiscalRange) error
+	FindRange(ctx context.Context, id uint) (*models.FiscalRange, error)
+	ListRanges(ctx context.Context) ([]models.FiscalRange, error)
+	FindRangesByPointOfSale(ctx context.Context, establishment, pointOfSale, documentType string) ([]models.FiscalRange, error)
+	Deactivate(ctx context.Context, id uint) (bool, error)
+	MarkWarned(ctx context.Context, id uint, at time.Time) error
+	Allocate(ctx context.Context, invoice *models.FiscalInvoice, establishment, pointOfSale string, now time.Time) (*models.FiscalRange, error)
+	FindInvoice(ctx context.Context, id uint) (*models.FiscalInvoice, error)
+	FindInvoiceByPayment(ctx context.Context, paymentID uint) (*models.FiscalInvoice, error)
+	ListInvoices(ctx context.Context, contractID uint) ([]models.FiscalInvoice, error)
+	SetInvoiceDocument(ctx context.Context, id uint, path, sha256 string, issuedDocumentID *uint) error
+	VoidInvoice(ctx context.Context, id uint, voidedByID *uint, reason string, at time.Time) (bool, error)
+}
+
+type fiscalRepository struct {
+	db *gorm.DB
+}
+
+// NewFiscalRepository creates a new fiscal repository
+func NewFiscalRepository(db *gorm.DB) FiscalRepository {
+	return &fiscalRepository{db: db}
+}
+
+func (r *fiscalRepository) CreateRange(ctx context.Context, fiscalRange *models.FiscalRange) error {
+	return r.db.WithContext(ctx).Create(fiscalRange).Error
+}
+
+func (r *fiscalRepository) FindRange(ctx context.Context, id uint) (*models.FiscalRange, error) {
+	var fiscalRange models.FiscalRange
+	if err := r.db.WithContext(ctx).First(&fiscalRange, id).Error; err != nil {
+		return nil, err
+	}
+	return &fiscalRange, nil
+}
+
+func (r *fiscalRepository) ListRanges(ctx context.Context) ([]models.FiscalRange, error) {
+	var ranges []models.FiscalRange
+	err := r.db.WithContext(ctx).
+		Order("active DESC, establishment, point_of_sale, deadline DESC, id DESC").
+		Find(&ranges).Error
+	return ranges, err
+}
+
+func (r *fiscalRepository) FindRangesByPointOfSale(ctx context.Context, establishment, pointOfSale, documentType string) ([]models.FiscalRange, error) {
+	var ranges []models.FiscalRange
+	err := r.db.WithContext(ctx).
+		Where("establishment = ? AND point_of_sale = ? AND document_type = ?", establishment, pointOfSale, documentType).
+		Order("deadline, id").
+		Find(&ranges).Error
+	return ranges, err
+}
+
+func (r *fiscalRepository) Deactivate(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.FiscalRange{}).
+		Where("id = ? AND active", id).
+		UpdateColumn("active", false)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *fiscalRepository) MarkWarned(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.FiscalRange{}).
+		Where("id = ?", id).
+		UpdateColumn("warned_at", at).Error
+}
+
+// Allocate takes the next number of the usable range of the point of sale that expires first and
+// stores the invoice with it. The range row is locked and advanced in the same transaction as the
+// insert, so numbers are allocated once and in order. It returns gorm.ErrRecordNotFound when no
+// active range has numbers left before its deadline.
+func (r *fiscalRepository) Allocate(ctx context.Context, invoice *models.FiscalInvoice, establishment, pointOfSale string, now time.Time) (*models.FiscalRange, error) {
+	var fiscalRange models.FiscalRange
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("establishment = ? AND point_of_sale = ? AND document_type = ?", establishment, pointOfSale, models.FiscalDocumentInvoice).
+			Where("active AND next_number <= range_end AND deadline >= ?", now.Format("2006-01-02")).
+			Order("deadline, id").
+			First(&fiscalRange).Error; err != nil {
+			return err
+		}
+		invoice.FiscalRangeID = fiscalRange.ID
+		invoice.Number = fiscalRange.FormatNumber(fiscalRange.NextNumber)
+		fiscalRange.NextNumber++
+		if err := tx.Model(&fiscalRange).UpdateColumn("next_number", fiscalRange.NextNumber).Error; err != nil {
+			return err
+		}
+		return tx.Omit("FiscalRange").Create(invoice).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	invoice.FiscalRange = fiscalRange
+	return &fiscalRange, nil
+}
+
+func (r *fiscalRepository) FindInvoice(ctx context.Context, id uint) (*models.FiscalInvoice, error) {
+	var invoice models.FiscalInvoice
+	if err := r.db.WithContext(ctx).Preload("FiscalRange").First(&invoice, id).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// FindInvoiceByPayment returns the invoice of a payment that is not voided
+func (r *fiscalRepository) FindInvoiceByPayment(ctx context.Context, paymentID uint) (*models.FiscalInvoice, error) {
+	var invoice models.FiscalInvoice
+	if err := r.db.WithContext(ctx).Preload("FiscalRange").Where("payment_id = ? AND voided_at IS NULL", paymentID).First(&invoice).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// ListInvoices returns the invoices of a contract, newest first
+func (r *fiscalRepository) ListInvoices(ctx context.Context, contractID uint) ([]models.FiscalInvoice, error) {
+	var invoices []models.FiscalInvoice
+	err := r.db.WithContext(ctx).Preload("FiscalRange").
+		Where("contract_id = ?", contractID).
+		Order("issued_at DESC, id DESC").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// SetInvoiceDocument stores the generated PDF of an invoice
+func (r *fiscalRepository) SetInvoiceDocument(ctx context.Context, id uint, path, sha256 string, issuedDocumentID *uint) error {
+	return r.db.WithContext(ctx).Model(&models.FiscalInvoice{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"path":               path,
+			"sha256":             sha256,
+			"issued_document_id": issuedDocumentID,
+		}).Error
+}
+
+// VoidInvoice voids an invoice; returns false when it was already void
+func (r *fiscalRepository) VoidInvoice(ctx context.Context, id uint, voidedByID *uint, reason string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.FiscalInvoice{}).
+		Where("id = ? AND voided_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"voided_at":    at,
+			"voided_by_id": voidedByID,
+			"void_reason":  reason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ImportBatch is the validated set of writes of a lot import
+type ImportBatch struct {
+	Projects []ImportProject
+}
+
+// ImportProject holds the writes of one project of the import
+type ImportProject struct {
+	Project *models.Project
+	IsNew   bool
+	Lots    []ImportLot
+}
+
+// ImportLot is one lot to create (ID 0) or update, with its file row and price history
+type ImportLot struct {
+	Row     int
+	Lot     *models.Lot
+	History []models.LotPriceHistory
+}
+
+// ImportRowError is returned when writing a row fails; the whole import is rolled back
+type ImportRowError struct {
+	Row int
+	Err error
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *ImportRowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportRepository defines the data access of lot and project imports
+type ImportRepository interface {
+	Apply(ctx context.Context, batch *ImportBatch) error
+}
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository creates a new import repository
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+// Apply writes the whole import in one transaction: new projects, lot creates and updates
+// (status is never changed), their price history and the lot count of projects that got new lots
+func (r *importRepository) Apply(ctx context.Context, batch *ImportBatch) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, p := range batch.Projects {
+			if p.IsNew {
+				if err := tx.Omit("Lots").Create(p.Project).Error; err != nil {
+					return fmt.Errorf("failed to create project %s: %w", p.Project.Name, err)
+				}
+			}
+			created := false
+			for _, item := range p.Lots {
+				var err error
+				if item.Lot.ID == 0 {
+					item.Lot.ProjectID = p.Project.ID
+					err = tx.Omit("Phase", "Block").Create(item.Lot).Error
+					created = true
+				} else {
+					err = tx.Omit("status", "Phase", "Block").Save(item.Lot).Error
+				}
+				if err != nil {
+					return &ImportRowError{Row: item.Row, Err: err}
+				}
+				for i := range item.History {
+					item.History[i].LotID = item.Lot.ID
+					if err := tx.Create(&item.History[i]).Error; err != nil {
+						return &ImportRowError{Row: item.Row, Err: err}
+					}
+				}
+			}
+			if created {
+				if err := tx.Model(&models.Project{}).Where("id = ?", p.Project.ID).
+					Updates(map[string]interface{}{
+						"lot_count":  tx.Model(&models.Lot{}).Select("COUNT(*)").Where("project_id = ?", p.Project.ID),
+						"updated_at": time.Now(),
+					}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
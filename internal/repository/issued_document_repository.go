@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IssuedDocumentRepository defines the interface for issued document data access
+type IssuedDocumentRepository interface {
+	Create(ctx context.Context, document *models.IssuedDocument) error
+	FindByCode(ctx context.Context, code string) (*models.IssuedDocument, error)
+	FindBySource(ctx context.Context, documentType, sourceSHA256 string) (*models.IssuedDocument, error)
+	AddCopy(ctx context.Context, id uint, sha256 string) error
+	Void(ctx context.Context, id uint, at time.Time) error
+}
+
+type issuedDocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewIssuedDocumentRepository creates a new issued document repository
+func NewIssuedDocumentRepository(db *gorm.DB) IssuedDocumentRepository {
+	return &issuedDocumentRepository{db: db}
+}
+
+func (r *issuedDocumentRepository) Create(ctx context.Context, document *models.IssuedDocument) error {
+	return r.db.WithContext(ctx).Create(document).Error
+}
+
+func (r *issuedDocumentRepository) FindByCode(ctx context.Context, code string) (*models.IssuedDocument, error) {
+	var document models.IssuedDocument
+	if err := r.db.WithContext(ctx).Preload("Copies").Where("code = ?", code).First(&document).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// FindBySource returns the latest document of the type not voided that was rendered from the same data
+func (r *issuedDocumentRepository) FindBySource(ctx context.Context, documentType, sourceSHA256 string) (*models.IssuedDocument, error) {
+	var document models.IssuedDocument
+	err := r.db.WithContext(ctx).
+		Where("document_type = ? AND source_sha256 = ? AND voided_at IS NULL", documentType, sourceSHA256).
+		Order("issued_at DESC, id DESC").
+		First(&document).Error
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// AddCopy records the hash of another copy of the document; a hash already recorded is kept once
+func (r *issuedDocumentRepository) AddCopy(ctx context.Context, id uint, sha256 string) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "issued_document_id"}, {Name: "sha256"}}, DoNothing: true}).
+		Create(&models.IssuedDocumentCopy{IssuedDocumentID: id, SHA256: sha256}).Error
+}
+
+func (r *issuedDocumentRepository) Void(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.IssuedDocument{}).
+		Where("id = ? AND voided_at IS NULL", id).
+		UpdateColumn("voided_at", at).Error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// KYCRepository defines the interface for KYC checklist data access
+type KYCRepository interface {
+	FindAll(ctx context.Context) ([]models.KYCRequirement, error)
+	FindByID(ctx context.Context, id uint) (*models.KYCRequirement, error)
+	Create(ctx context.Context, requirement *models.KYCRequirement) error
+	Delete(ctx context.Context, id uint) error
+
+	SetOverride(ctx context.Context, contractID uint, reason string, actorID uint, at time.Time) error
+}
+
+type kycRepository struct {
+	db *gorm.DB
+}
+
+// NewKYCRepository creates a new KYC checklist repository
+func NewKYCRepository(db *gorm.DB) KYCRepository {
+	return &kycRepository{db: db}
+}
+
+func (r *kycRepository) FindAll(ctx context.Context) ([]models.KYCRequirement, error) {
+	var requirements []models.KYCRequirement
+	err := r.db.WithContext(ctx).Preload("Project").
+		Order("project_id ASC NULLS FIRST, financing_type ASC NULLS FIRST, id ASC").
+		Find(&requirements).Error
+	return requirements, err
+}
+
+func (r *kycRepository) FindByID(ctx context.Context, id uint) (*models.KYCRequirement, error) {
+	var requirement models.KYCRequirement
+	if err := r.db.WithContext(ctx).Preload("Project").First(&requirement, id).Error; err != nil {
+		return nil, err
+	}
+	return &requirement, nil
+}
+
+func (r *kycRepository) Create(ctx context.Context, requirement *models.KYCRequirement) error {
+	return r.db.WithContext(ctx).Omit("Project").Create(requirement).Error
+}
+
+func (r *kycRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.KYCRequirement{}, id).Error
+}
+
+// SetOverride records the admin's decision to approve the contract without a complete checklist
+func (r *kycRepository) SetOverride(ctx context.Context, contractID uint, reason string, actorID uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Contract{}).
+		Where("id = ?", contractID).
+		UpdateColumns(map[string]interface{}{
+			"kyc_override_reason":  reason,
+			"kyc_overridden_by_id": actorID,
+			"kyc_overridden_at":    at,
+		}).Error
+}
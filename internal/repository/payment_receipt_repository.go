@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentReceiptRepository defines the interface for payment receipt data access
+type PaymentReceiptRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.PaymentReceipt, error)
+	FindByPayment(ctx context.Context, paymentID uint) ([]models.PaymentReceipt, error)
+	Issue(ctx context.Context, receipt *models.PaymentReceipt) error
+	SetDocument(ctx context.Context, id uint, path, sha256 string, issuedDocumentID *uint) (bool, error)
+	Void(ctx context.Context, id uint, voidedByID *uint, reason string, at time.Time) (bool, error)
+}
+
+type paymentReceiptRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentReceiptRepository creates a new payment receipt repository
+func NewPaymentReceiptRepository(db *gorm.DB) PaymentReceiptRepository {
+	return &paymentReceiptRepository{db: db}
+}
+
+func (r *paymentReceiptRepository) FindByID(ctx context.Context, id uint) (*models.PaymentReceipt, error) {
+	var receipt models.PaymentReceipt
+	if err := r.db.WithContext(ctx).First(&receipt, id).Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// FindByPayment returns every receipt of the payment, voided ones included, newest first
+func (r *paymentReceiptRepository) FindByPayment(ctx context.Context, paymentID uint) ([]models.PaymentReceipt, error) {
+	var receipts []models.PaymentReceipt
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("issued_at DESC, id DESC").
+		Find(&receipts).Error
+	return receipts, err
+}
+
+// Issue numbers and stores a receipt. The sequence row of its series is locked and advanced in the
+// same transaction as the insert, so numbers are sequential and a failed insert leaves no gap.
+func (r *paymentReceiptRepository) Issue(ctx context.Context, receipt *models.PaymentReceipt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sequence := models.ReceiptSequence{Series: receipt.Series, NextNumber: 1}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "series"}}, DoNothing: true}).
+			Create(&sequence).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("series = ?", receipt.Series).
+			First(&sequence).Error; err != nil {
+			return err
+		}
+		receipt.Number = sequence.NextNumber
+		if err := tx.Model(&sequence).UpdateColumn("next_number", sequence.NextNumber+1).Error; err != nil {
+			return err
+		}
+		return tx.Create(receipt).Error
+	})
+}
+
+// SetDocument stores the generated PDF of an issued receipt that has none yet; returns false when
+// another render stored one first or the receipt was voided meanwhile
+func (r *paymentReceiptRepository) SetDocument(ctx context.Context, id uint, path, sha256 string, issuedDocumentID *uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.PaymentReceipt{}).
+		Where("id = ? AND status = ? AND path IS NULL", id, models.ReceiptStatusIssued).
+		Updates(map[string]interface{}{
+			"path":               path,
+			"sha256":             sha256,
+			"issued_document_id": issuedDocumentID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Void voids an issued receipt; returns false when it was already void
+func (r *paymentReceiptRepository) Void(ctx context.Context, id uint, voidedByID *uint, reason string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.PaymentReceipt{}).
+		Where("id = ? AND status = ?", id, models.ReceiptStatusIssued).
+		Updates(map[string]interface{}{
+			"status":       models.ReceiptStatusVoid,
+			"voided_at":    at,
+			"voided_by_id": voidedByID,
+			"void_reason":  reason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// PriceListRepository defines the interface for price list and lot price history data access
+type PriceListRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.PriceList, error)
+	FindByProject(ctx context.Context, projectID uint) ([]models.PriceList, error)
+	FindCurrentForProject(ctx context.Context, projectID uint, at time.Time) (*models.PriceList, error)
+	FindDueScheduled(ctx context.Context, at time.Time) ([]models.PriceList, error)
+	NextVersion(ctx context.Context, projectID uint) (int, error)
+	Create(ctx context.Context, list *models.PriceList) error
+	Update(ctx context.Context, list *models.PriceList) error
+	Apply(ctx context.Context, list *models.PriceList, actorID *uint) error
+	CreateHistory(ctx context.Context, entry *models.LotPriceHistory) error
+	FindHistoryByLot(ctx context.Context, lotID uint) ([]models.LotPriceHistory, error)
+}
+
+type priceListRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceListRepository creates a new price list repository
+func NewPriceListRepository(db *gorm.DB) PriceListRepository {
+	return &priceListRepository{db: db}
+}
+
+func (r *priceListRepository) FindByID(ctx context.Context, id uint) (*models.PriceList, error) {
+	var list models.PriceList
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("lot_id ASC")
+		}).
+		First(&list, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *priceListRepository) FindByProject(ctx context.Context, projectID uint) ([]models.PriceList, error) {
+	var lists []models.PriceList
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("version DESC").
+		Find(&lists).Error
+	return lists, err
+}
+
+// FindCurrentForProject returns the latest applied price list in effect at the given time
+func (r *priceListRepository) FindCurrentForProject(ctx context.Context, projectID uint, at time.Time) (*models.PriceList, error) {
+	var list models.PriceList
+	err := r.db.WithContext(ctx).
+		Where("project_id = ? AND status = ? AND effective_from <= ?", projectID, models.PriceListStatusApplied, at).
+		Order("effective_from DESC, version DESC").
+		First(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// FindDueScheduled returns scheduled price lists whose effective date has been reached
+func (r *priceListRepository) FindDueScheduled(ctx context.Context, at time.Time) ([]models.PriceList, error) {
+	var lists []models.PriceList
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND effective_from <= ?", models.PriceListStatusScheduled, at).
+		Preload("Items").
+		Order("effective_from ASC, version ASC").
+		Find(&lists).Error
+	return lists, err
+}
+
+func (r *priceListRepository) NextVersion(ctx context.Context, projectID uint) (int, error) {
+	var result struct {
+		Version int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.PriceList{}).
+		Select("COALESCE(MAX(version), 0) AS version").
+		Where("project_id = ?", projectID).
+		Scan(&result).Error
+	return result.Version + 1, err
+}
+
+func (r *priceListRepository) Create(ctx context.Context, list *models.PriceList) error {
+	return r.db.WithContext(ctx).Create(list).Error
+}
+
+func (r *priceListRepository) Update(ctx context.Context, list *models.PriceList) error {
+	return r.db.WithContext(ctx).Omit("Items").Save(list).Error
+}
+
+// Apply writes the price list items to the lots, records price history and marks the list applied,
+// all in one transaction.
+func (r *priceListRepository) Apply(ctx context.Context, list *models.PriceList, actorID *uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		reason := fmt.Sprintf("Lista de precios v%d: %s", list.Version, list.Name)
+		for _, item := range list.Items {
+			column := models.PriceFieldPrice
+			if item.PriceField == models.PriceFieldOverridePrice {
+				column = models.PriceFieldOverridePrice
+			}
+			if err := tx.Model(&models.Lot{}).Where("id = ?", item.LotID).
+				Updates(map[string]interface{}{column: item.NewPrice, "updated_at": now}).Error; err != nil {
+				return err
+			}
+			oldPrice := item.OldPrice
+			listID := list.ID
+			history := models.LotPriceHistory{
+				LotID:         item.LotID,
+				PriceListID:   &listID,
+				PriceField:    column,
+				OldPrice:      &oldPrice,
+				NewPrice:      item.NewPrice,
+				EffectiveFrom: list.EffectiveFrom,
+				ChangedByID:   actorID,
+				Reason:        &reason,
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.PriceList{}).Where("id = ?", list.ID).
+			Updates(map[string]interface{}{"status": models.PriceListStatusApplied, "applied_at": now}).Error
+	})
+}
+
+func (r *priceListRepository) CreateHistory(ctx context.Context, entry *models.LotPriceHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *priceListRepository) FindHistoryByLot(ctx context.Context, lotID uint) ([]models.LotPriceHistory, error) {
+	var entries []models.LotPriceHistory
+	err := r.db.WithContext(ctx).
+		Where("lot_id = ?", lotID).
+		Order("effective_from DESC, id DESC").
+		Find(&entries).Error
+	return entries, err
+}
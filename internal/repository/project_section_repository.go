@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// SectionLotCount is the number of lots of a project per phase, block and status
+type SectionLotCount struct {
+	PhaseID *uint
+	BlockID *uint
+	Status  string
+	Count   int
+}
+
+// ProjectSectionRepository defines the interface for project phase and block data access
+type ProjectSectionRepository interface {
+	FindPhaseByID(ctx context.Context, id uint) (*models.ProjectPhase, error)
+	FindPhasesByProject(ctx context.Context, projectID uint) ([]models.ProjectPhase, error)
+	FindPhaseByName(ctx context.Context, projectID uint, name string) (*models.ProjectPhase, error)
+	CreatePhase(ctx context.Context, phase *models.ProjectPhase) error
+	UpdatePhase(ctx context.Context, phase *models.ProjectPhase) error
+	DeletePhase(ctx context.Context, id uint) error
+	FindBlockByID(ctx context.Context, id uint) (*models.ProjectBlock, error)
+	FindBlocksByProject(ctx context.Context, projectID uint) ([]models.ProjectBlock, error)
+	FindBlockByName(ctx context.Context, projectID uint, phaseID *uint, name string) (*models.ProjectBlock, error)
+	CreateBlock(ctx context.Context, block *models.ProjectBlock) error
+	UpdateBlock(ctx context.Context, block *models.ProjectBlock) error
+	DeleteBlock(ctx context.Context, id uint) error
+	CountLots(ctx context.Context, projectID uint) ([]SectionLotCount, error)
+}
+
+type projectSectionRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectSectionRepository creates a new project phase/block repository
+func NewProjectSectionRepository(db *gorm.DB) ProjectSectionRepository {
+	return &projectSectionRepository{db: db}
+}
+
+func (r *projectSectionRepository) FindPhaseByID(ctx context.Context, id uint) (*models.ProjectPhase, error) {
+	var phase models.ProjectPhase
+	err := r.db.WithContext(ctx).First(&phase, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &phase, nil
+}
+
+func (r *projectSectionRepository) FindPhasesByProject(ctx context.Context, projectID uint) ([]models.ProjectPhase, error) {
+	var phases []models.ProjectPhase
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("position ASC, name ASC").
+		Find(&phases).Error
+	return phases, err
+}
+
+func (r *projectSectionRepository) FindPhaseByName(ctx context.Context, projectID uint, name string) (*models.ProjectPhase, error) {
+	var phase models.ProjectPhase
+	err := r.db.WithContext(ctx).
+		Where("project_id = ? AND LOWER(name) = LOWER(?)", projectID, name).
+		First(&phase).Error
+	if err != nil {
+		return nil, err
+	}
+	return &phase, nil
+}
+
+func (r *projectSectionRepository) CreatePhase(ctx context.Context, phase *models.ProjectPhase) error {
+	return r.db.WithContext(ctx).Create(phase).Error
+}
+
+func (r *projectSectionRepository) UpdatePhase(ctx context.Context, phase *models.ProjectPhase) error {
+	return r.db.WithContext(ctx).Omit("Blocks").Save(phase).Error
+}
+
+// DeletePhase deletes a phase; its blocks and lots stay in the project without a phase
+func (r *projectSectionRepository) DeletePhase(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ProjectBlock{}).Where("phase_id = ?", id).Update("phase_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Lot{}).Where("phase_id = ?", id).Update("phase_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ProjectPhase{}, id).Error
+	})
+}
+
+func (r *projectSectionRepository) FindBlockByID(ctx context.Context, id uint) (*models.ProjectBlock, error) {
+	var block models.ProjectBlock
+	err := r.db.WithContext(ctx).Preload("Phase").First(&block, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *projectSectionRepository) FindBlocksByProject(ctx context.Context, projectID uint) ([]models.ProjectBlock, error) {
+	var blocks []models.ProjectBlock
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("position ASC, name ASC").
+		Find(&blocks).Error
+	return blocks, err
+}
+
+func (r *projectSectionRepository) FindBlockByName(ctx context.Context, projectID uint, phaseID *uint, name string) (*models.ProjectBlock, error) {
+	var block models.ProjectBlock
+	db := r.db.WithContext(ctx).Where("project_id = ? AND LOWER(name) = LOWER(?)", projectID, name)
+	if phaseID != nil {
+		db = db.Where("phase_id = ?", *phaseID)
+	} else {
+		db = db.Where("phase_id IS NULL")
+	}
+	err := db.Preload("Phase").First(&block).Error
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *projectSectionRepository) CreateBlock(ctx context.Context, block *models.ProjectBlock) error {
+	return r.db.WithContext(ctx).Omit("Phase").Create(block).Error
+}
+
+// UpdateBlock saves the block and moves its lots along when it changes phase
+func (r *projectSectionRepository) UpdateBlock(ctx context.Context, block *models.ProjectBlock) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Phase").Save(block).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Lot{}).Where("block_id = ?", block.ID).Update("phase_id", block.PhaseID).Error
+	})
+}
+
+// DeleteBlock deletes a block; its lots stay in their phase without a block
+func (r *projectSectionRepository) DeleteBlock(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Lot{}).Where("block_id = ?", id).Update("block_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ProjectBlock{}, id).Error
+	})
+}
+
+func (r *projectSectionRepository) CountLots(ctx context.Context, projectID uint) ([]SectionLotCount, error) {
+	var counts []SectionLotCount
+	err := r.db.WithContext(ctx).Model(&models.Lot{}).
+		Select("phase_id, block_id, status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).
+		Group("phase_id, block_id, status").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// PromotionRepository defines the interface for promotion data access
+type PromotionRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Promotion, error)
+	Create(ctx context.Context, promotion *models.Promotion) error
+	Update(ctx context.Context, promotion *models.Promotion) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, query *ListQuery) ([]models.Promotion, int64, error)
+	FindActiveForProject(ctx context.Context, projectID uint, at time.Time) ([]models.Promotion, error)
+	IncrementUses(ctx context.Context, id uint) (bool, error)
+	DecrementUses(ctx context.Context, id uint) error
+}
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) FindByID(ctx context.Context, id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.db.WithContext(ctx).First(&promotion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Create(promotion).Error
+}
+
+func (r *promotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	// uses_count is only changed atomically via IncrementUses/DecrementUses
+	return r.db.WithContext(ctx).Omit("uses_count").Save(promotion).Error
+}
+
+func (r *promotionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Promotion{}, id).Error
+}
+
+func (r *promotionRepository) List(ctx context.Context, query *ListQuery) ([]models.Promotion, int64, error) {
+	var promotions []models.Promotion
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.Promotion{})
+
+	if query.Search != "" {
+		search := "%" + query.Search + "%"
+		db = db.Where("name ILIKE ? OR description ILIKE ?", search, search)
+	}
+	if val, ok := query.Filters["project_id"]; ok && val != "" {
+		db = db.Where("project_id = ?", val)
+	}
+	if val, ok := query.Filters["active"]; ok && val != "" {
+		db = db.Where("active = ?", val == "true")
+	}
+
+	db.Count(&total)
+
+	if query.PerPage > 0 {
+		db = db.Offset((query.Page - 1) * query.PerPage).Limit(query.PerPage)
+	}
+
+	err := db.Order("created_at DESC").Find(&promotions).Error
+	return promotions, total, err
+}
+
+// FindActiveForProject returns active promotions in their date window that apply to the project
+// (project-specific or global). Lot, financing type and usage limits are checked by the caller.
+func (r *promotionRepository) FindActiveForProject(ctx context.Context, projectID uint, at time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.db.WithContext(ctx).
+		Where("active = ?", true).
+		Where("project_id IS NULL OR project_id = ?", projectID).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("ends_at IS NULL OR ends_at >= ?", at).
+		Order("id ASC").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+// IncrementUses atomically consumes one use of the promotion.
+// Returns false when the usage limit has already been reached.
+func (r *promotionRepository) IncrementUses(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Promotion{}).
+		Where("id = ? AND (max_uses IS NULL OR uses_count < max_uses)", id).
+		UpdateColumn("uses_count", gorm.Expr("uses_count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DecrementUses gives back one use of the promotion (never below zero)
+func (r *promotionRepository) DecrementUses(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Promotion{}).
+		Where("id = ? AND uses_count > 0", id).
+		UpdateColumn("uses_count", gorm.Expr("uses_count - 1")).Error
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReceivablesQuery selects the receivables of the aging report as of a date
+type ReceivablesQuery struct {
+	AsOf      time.Time // end of the as-of day
+	ProjectID uint      // 0 = all projects
+	SellerID  uint      // 0 = all sellers
+}
+
+// LedgerPaymentTotal is the sum of the ledger entries of one type posted for a payment
+type LedgerPaymentTotal struct {
+	PaymentID uint
+	EntryType string
+	Amount    float64
+}
+
+// receivablesLedgerBatch bounds the payment IDs per ledger query
+const receivablesLedgerBatch = 5000
+
+// ReceivablesRepository defines the data access of the receivables aging report
+type ReceivablesRepository interface {
+	FindDuePayments(ctx context.Context, query ReceivablesQuery) ([]models.Payment, error)
+	SumLedgerByPayment(ctx context.Context, paymentIDs []uint, until time.Time) ([]LedgerPaymentTotal, error)
+}
+
+type receivablesRepository struct {
+	db *gorm.DB
+}
+
+// NewReceivablesRepository creates a new receivables repository
+func NewReceivablesRepository(db *gorm.DB) ReceivablesRepository {
+	return &receivablesRepository{db: db}
+}
+
+// FindDuePayments returns the scheduled payments of contracts in force, or closed since, that were due
+// by the as-of date and not yet paid on it. Payments paid before the as-of date are left out; the
+// ledger decides how much of the others was outstanding.
+func (r *receivablesRepository) FindDuePayments(ctx context.Context, query ReceivablesQuery) ([]models.Payment, error) {
+	var payments []models.Payment
+	db := r.db.WithContext(ctx).
+		Joins("JOIN contracts ON contracts.id = payments.contract_id AND contracts.status IN ?",
+			[]string{models.ContractStatusApproved, models.ContractStatusSigned, models.ContractStatusClosed}).
+		Where("payments.due_date <= ? AND payments.created_at <= ?", query.AsOf, query.AsOf).
+		Where("payments.status <> ?", models.PaymentStatusReadjustment).
+		Where("NOT (payments.status = ? AND (payments.approved_at IS NULL OR payments.approved_at <= ?))",
+			models.PaymentStatusPaid, query.AsOf)
+	if query.ProjectID != 0 {
+		db = db.Joins("JOIN lots ON lots.id = contracts.lot_id AND lots.project_id = ?", query.ProjectID)
+	}
+	if query.SellerID != 0 {
+		db = db.Where("contracts.creator_id = ?", query.SellerID)
+	}
+	err := db.
+		Preload("Contract.Lot.Project").
+		Preload("Contract.ApplicantUser").
+		Preload("Contract.Creator").
+		Order("payments.contract_id ASC, payments.due_date ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// SumLedgerByPayment totals the ledger entries posted for the payments until the given time, by
+// payment and entry type
+func (r *receivablesRepository) SumLedgerByPayment(ctx context.Context, paymentIDs []uint, until time.Time) ([]LedgerPaymentTotal, error) {
+	var totals []LedgerPaymentTotal
+	for start := 0; start < len(paymentIDs); start += receivablesLedgerBatch {
+		end := start + receivablesLedgerBatch
+		if end > len(paymentIDs) {
+			end = len(paymentIDs)
+		}
+		var batch []LedgerPaymentTotal
+		err := r.db.WithContext(ctx).
+			Model(&models.ContractLedgerEntry{}).
+			Select("payment_id, entry_type, COALESCE(SUM(amount), 0) AS amount").
+			Where("payment_id IN ? AND entry_date <= ?", paymentIDs[start:end], until).
+			Group("payment_id, entry_type").
+			Scan(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, batch...)
+	}
+	return totals, nil
+}
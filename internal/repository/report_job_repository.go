@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReportJobRepository defines the interface for background report job data access
+type ReportJobRepository interface {
+	Create(ctx context.Context, job *models.ReportJob) error
+	FindByID(ctx context.Context, id uint) (*models.ReportJob, error)
+	List(ctx context.Context, requestedByID uint, limit int) ([]models.ReportJob, error)
+	Start(ctx context.Context, id uint, at time.Time) (bool, error)
+	Complete(ctx context.Context, job *models.ReportJob) error
+	Fail(ctx context.Context, id uint, message string, at time.Time) error
+	FindExpired(ctx context.Context, now time.Time) ([]models.ReportJob, error)
+	MarkExpired(ctx context.Context, id uint) error
+	FailUnfinished(ctx context.Context, message string, at time.Time) (int64, error)
+}
+
+type reportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewReportJobRepository creates a new report job repository
+func NewReportJobRepository(db *gorm.DB) ReportJobRepository {
+	return &reportJobRepository{db: db}
+}
+
+func (r *reportJobRepository) Create(ctx context.Context, job *models.ReportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *reportJobRepository) FindByID(ctx context.Context, id uint) (*models.ReportJob, error) {
+	var job models.ReportJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List returns the most recent jobs, only those requested by requestedByID when it is not zero
+func (r *reportJobRepository) List(ctx context.Context, requestedByID uint, limit int) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	query := r.db.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit)
+	if requestedByID != 0 {
+		query = query.Where("requested_by_id = ?", requestedByID)
+	}
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// Start moves a queued job to running; it returns false when the job was not queued
+func (r *reportJobRepository) Start(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ReportJob{}).
+		Where("id = ? AND status = ?", id, models.ReportJobQueued).
+		Updates(map[string]interface{}{"status": models.ReportJobRunning, "started_at": at})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Complete stores the output of a finished job
+func (r *reportJobRepository) Complete(ctx context.Context, job *models.ReportJob) error {
+	return r.db.WithContext(ctx).Model(&models.ReportJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":       models.ReportJobCompleted,
+			"filename":     job.Filename,
+			"content_type": job.ContentType,
+			"path":         job.Path,
+			"size":         job.Size,
+			"sha256":       job.SHA256,
+			"completed_at": job.CompletedAt,
+			"expires_at":   job.ExpiresAt,
+		}).Error
+}
+
+func (r *reportJobRepository) Fail(ctx context.Context, id uint, message string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ReportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.ReportJobFailed, "error": message, "completed_at": at}).Error
+}
+
+// FindExpired returns the completed jobs whose output is past its retention
+func (r *reportJobRepository) FindExpired(ctx context.Context, now time.Time) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", models.ReportJobCompleted, now).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *reportJobRepository) MarkExpired(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.ReportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.ReportJobExpired, "path": nil}).Error
+}
+
+// FailUnfinished fails the jobs left queued or running, e.g. by a restart of the server
+func (r *reportJobRepository) FailUnfinished(ctx context.Context, message string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ReportJob{}).
+		Where("status IN ?", []string{models.ReportJobQueued, models.ReportJobRunning}).
+		Updates(map[string]interface{}{"status": models.ReportJobFailed, "error": message, "completed_at": at})
+	return result.RowsAffected, result.Error
+}
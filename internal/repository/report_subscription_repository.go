@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReportSubscriptionRepository defines the interface for scheduled report subscription data access
+type ReportSubscriptionRepository interface {
+	Create(ctx context.Context, sub *models.ReportSubscription) error
+	FindByID(ctx context.Context, id uint) (*models.ReportSubscription, error)
+	List(ctx context.Context) ([]models.ReportSubscription, error)
+	Update(ctx context.Context, sub *models.ReportSubscription) error
+	Delete(ctx context.Context, id uint) error
+	FindDue(ctx context.Context, now time.Time) ([]models.ReportSubscription, error)
+	Claim(ctx context.Context, id uint, dueAt, nextRunAt time.Time) (bool, error)
+	RecordRun(ctx context.Context, run *models.ReportSubscriptionRun) error
+	ListRuns(ctx context.Context, subscriptionID uint, limit int) ([]models.ReportSubscriptionRun, error)
+}
+
+type reportSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewReportSubscriptionRepository creates a new report subscription repository
+func NewReportSubscriptionRepository(db *gorm.DB) ReportSubscriptionRepository {
+	return &reportSubscriptionRepository{db: db}
+}
+
+func (r *reportSubscriptionRepository) Create(ctx context.Context, sub *models.ReportSubscription) error {
+	return r.db.WithContext(ctx).Create(sub).Error
+}
+
+func (r *reportSubscriptionRepository) FindByID(ctx context.Context, id uint) (*models.ReportSubscription, error) {
+	var sub models.ReportSubscription
+	if err := r.db.WithContext(ctx).First(&sub, id).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *reportSubscriptionRepository) List(ctx context.Context) ([]models.ReportSubscription, error) {
+	var subs []models.ReportSubscription
+	err := r.db.WithContext(ctx).Order("name ASC, id ASC").Find(&subs).Error
+	return subs, err
+}
+
+func (r *reportSubscriptionRepository) Update(ctx context.Context, sub *models.ReportSubscription) error {
+	return r.db.WithContext(ctx).Save(sub).Error
+}
+
+func (r *reportSubscriptionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ReportSubscription{}, id).Error
+}
+
+// FindDue returns the active subscriptions whose next run is at or before now
+func (r *reportSubscriptionRepository) FindDue(ctx context.Context, now time.Time) ([]models.ReportSubscription, error) {
+	var subs []models.ReportSubscription
+	err := r.db.WithContext(ctx).
+		Where("active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Find(&subs).Error
+	return subs, err
+}
+
+// Claim moves the next run of a due subscription forward; it returns false when another run
+// already claimed it
+func (r *reportSubscriptionRepository) Claim(ctx context.Context, id uint, dueAt, nextRunAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ReportSubscription{}).
+		Where("id = ? AND next_run_at = ?", id, dueAt).
+		Update("next_run_at", nextRunAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordRun stores a run and updates the last run of its subscription
+func (r *reportSubscriptionRepository) RecordRun(ctx context.Context, run *models.ReportSubscriptionRun) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ReportSubscription{}).
+			Where("id = ?", run.SubscriptionID).
+			Updates(map[string]interface{}{"last_run_at": run.StartedAt, "last_status": run.Status}).Error
+	})
+}
+
+// ListRuns returns the most recent runs of a subscription
+func (r *reportSubscriptionRepository) ListRuns(ctx context.Context, subscriptionID uint, limit int) ([]models.ReportSubscriptionRun, error) {
+	var runs []models.ReportSubscriptionRun
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("started_at DESC, id DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
@@ -6,28 +6,66 @@ import (
 
 // Repositories holds all repository instances
 type Repositories struct {
-	User         UserRepository
-	Project      ProjectRepository
-	Lot          LotRepository
-	Contract     ContractRepository
-	Payment      PaymentRepository
-	Notification NotificationRepository
-	RefreshToken RefreshTokenRepository
-	Ledger       LedgerRepository
-	Analytics    AnalyticsRepository
+	User               UserRepository
+	Project            ProjectRepository
+	Lot                LotRepository
+	Contract           ContractRepository
+	Payment            PaymentRepository
+	Notification       NotificationRepository
+	RefreshToken       RefreshTokenRepository
+	Ledger             LedgerRepository
+	Analytics          AnalyticsRepository
+	Promotion          PromotionRepository
+	PriceList          PriceListRepository
+	Waitlist           WaitlistRepository
+	Section            ProjectSectionRepository
+	ContractParty      ContractPartyRepository
+	Cession            ContractCessionRepository
+	LotChange          ContractLotChangeRepository
+	ApprovalChain      ApprovalChainRepository
+	ContractSLA        ContractSLARepository
+	ContractDocument   ContractDocumentRepository
+	KYC                KYCRepository
+	ContractSignature  ContractSignatureRepository
+	IssuedDocument     IssuedDocumentRepository
+	PaymentReceipt     PaymentReceiptRepository
+	Fiscal             FiscalRepository
+	ReportJob          ReportJobRepository
+	ReportSubscription ReportSubscriptionRepository
+	Receivables        ReceivablesRepository
+	Import             ImportRepository
 }
 
 // NewRepositories creates all repository instances
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
-		User:         NewUserRepository(db),
-		Project:      NewProjectRepository(db),
-		Lot:          NewLotRepository(db),
-		Contract:     NewContractRepository(db),
-		Payment:      NewPaymentRepository(db),
-		Notification: NewNotificationRepository(db),
-		RefreshToken: NewRefreshTokenRepository(db),
-		Ledger:       NewLedgerRepository(db),
-		Analytics:    NewAnalyticsRepository(db),
+		User:               NewUserRepository(db),
+		Project:            NewProjectRepository(db),
+		Lot:                NewLotRepository(db),
+		Contract:           NewContractRepository(db),
+		Payment:            NewPaymentRepository(db),
+		Notification:       NewNotificationRepository(db),
+		RefreshToken:       NewRefreshTokenRepository(db),
+		Ledger:             NewLedgerRepository(db),
+		Analytics:          NewAnalyticsRepository(db),
+		Promotion:          NewPromotionRepository(db),
+		PriceList:          NewPriceListRepository(db),
+		Waitlist:           NewWaitlistRepository(db),
+		Section:            NewProjectSectionRepository(db),
+		ContractParty:      NewContractPartyRepository(db),
+		Cession:            NewContractCessionRepository(db),
+		LotChange:          NewContractLotChangeRepository(db),
+		ApprovalChain:      NewApprovalChainRepository(db),
+		ContractSLA:        NewContractSLARepository(db),
+		ContractDocument:   NewContractDocumentRepository(db),
+		KYC:                NewKYCRepository(db),
+		ContractSignature:  NewContractSignatureRepository(db),
+		IssuedDocument:     NewIssuedDocumentRepository(db),
+		PaymentReceipt:     NewPaymentReceiptRepository(db),
+		Fiscal:             NewFiscalRepository(db),
+		ReportJob:          NewReportJobRepository(db),
+		ReportSubscription: NewReportSubscriptionRepository(db),
+		Receivables:        NewReceivablesRepository(db),
+		Import:             NewImportRepository(db),
 	}
 }
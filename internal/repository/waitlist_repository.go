@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// WaitlistRepository defines the interface for lot waitlist data access
+type WaitlistRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.LotWaitlistEntry, error)
+	List(ctx context.Context, projectID uint, sellerID *uint, status string) ([]models.LotWaitlistEntry, error)
+	FindWaitingForLot(ctx context.Context, lot *models.Lot) ([]models.LotWaitlistEntry, error)
+	FindOfferedForLot(ctx context.Context, lotID uint) ([]models.LotWaitlistEntry, error)
+	Create(ctx context.Context, entry *models.LotWaitlistEntry) error
+	UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error)
+}
+
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWaitlistRepository creates a new waitlist repository
+func NewWaitlistRepository(db *gorm.DB) WaitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (r *waitlistRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Project").Preload("Lot").Preload("OfferedLot").Preload("Seller")
+}
+
+func (r *waitlistRepository) FindByID(ctx context.Context, id uint) (*models.LotWaitlistEntry, error) {
+	var entry models.LotWaitlistEntry
+	err := r.preload(r.db.WithContext(ctx)).First(&entry, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns the entries of a project in queue order, optionally of one seller and/or status
+func (r *waitlistRepository) List(ctx context.Context, projectID uint, sellerID *uint, status string) ([]models.LotWaitlistEntry, error) {
+	var entries []models.LotWaitlistEntry
+	db := r.preload(r.db.WithContext(ctx)).Where("project_id = ?", projectID)
+	if sellerID != nil {
+		db = db.Where("seller_id = ?", *sellerID)
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Order("created_at ASC, id ASC").Find(&entries).Error
+	return entries, err
+}
+
+// FindWaitingForLot returns the waiting entries interested in the lot (that lot, or any lot of
+// its project within the entry's max price) in queue order
+func (r *waitlistRepository) FindWaitingForLot(ctx context.Context, lot *models.Lot) ([]models.LotWaitlistEntry, error) {
+	var entries []models.LotWaitlistEntry
+	err := r.preload(r.db.WithContext(ctx)).
+		Where("project_id = ? AND status = ?", lot.ProjectID, models.WaitlistStatusWaiting).
+		Where("lot_id = ? OR (lot_id IS NULL AND (max_price IS NULL OR max_price >= ?))", lot.ID, lot.EffectivePrice()).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// FindOfferedForLot returns the entries currently holding a priority offer on the lot
+func (r *waitlistRepository) FindOfferedForLot(ctx context.Context, lotID uint) ([]models.LotWaitlistEntry, error) {
+	var entries []models.LotWaitlistEntry
+	err := r.preload(r.db.WithContext(ctx)).
+		Where("offered_lot_id = ? AND status = ?", lotID, models.WaitlistStatusNotified).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, entry *models.LotWaitlistEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// UpdateStatus changes the entry status only if it still has fromStatus (compare-and-set), so an
+// entry is never offered two lots at once. Returns false when the entry changed meanwhile.
+func (r *waitlistRepository) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": toStatus, "updated_at": time.Now()}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := r.db.WithContext(ctx).Model(&models.LotWaitlistEntry{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
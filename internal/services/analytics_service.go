@@ -175,25 +175,40 @@ func (s *AnalyticsService) computeOverview(ctx context.Context, filters models.A
 	}, nil
 }
 
-func (s *AnalyticsService) GetDistribution(ctx context.Context, projectID *uint) (*models.LotDistribution, error) {
+// GetDistribution returns the lot distribution of a project (or all projects), optionally
+// filtered by phase/block and grouped per phase or block. Only unfiltered results are cached.
+func (s *AnalyticsService) GetDistribution(ctx context.Context, filters models.AnalyticsFilters) (*models.LotDistribution, error) {
 	cacheKey := "analytics_distribution"
+	projectID := filters.ProjectID
 
 	// Check cache
-	cached, err := s.analyticsRepo.GetCache(ctx, cacheKey, projectID)
-	if err == nil && cached != nil {
-		var dist models.LotDistribution
-		if err := json.Unmarshal(cached.Data, &dist); err == nil {
-			return &dist, nil
+	if !filters.HasSectionFilter() {
+		cached, err := s.analyticsRepo.GetCache(ctx, cacheKey, projectID)
+		if err == nil && cached != nil {
+			var dist models.LotDistribution
+			if err := json.Unmarshal(cached.Data, &dist); err == nil {
+				return &dist, nil
+			}
 		}
 	}
 
-	dist, err := s.analyticsRepo.GetLotDistribution(ctx, projectID)
+	dist, err := s.analyticsRepo.GetLotDistribution(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
 
+	if filters.GroupBy != "" {
+		groups, err := s.analyticsRepo.GetLotDistributionGroups(ctx, filters, filters.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		dist.Groups = groups
+	}
+
 	// Set cache (15 min TTL)
-	_ = s.analyticsRepo.SetCache(ctx, cacheKey, projectID, dist, 15*time.Minute)
+	if !filters.HasSectionFilter() {
+		_ = s.analyticsRepo.SetCache(ctx, cacheKey, projectID, dist, 15*time.Minute)
+	}
 
 	return dist, nil
 }
@@ -265,14 +280,14 @@ func (s *AnalyticsService) RefreshCache(ctx context.Context) error {
 
 	// Refresh global stats
 	_, _ = s.GetOverview(ctx, models.AnalyticsFilters{})
-	_, _ = s.GetDistribution(ctx, nil)
+	_, _ = s.GetDistribution(ctx, models.AnalyticsFilters{})
 	_, _ = s.GetPerformance(ctx, models.AnalyticsFilters{})
 
 	// Refresh each project stats
 	for _, p := range projects {
 		pid := p.ID
 		_, _ = s.GetOverview(ctx, models.AnalyticsFilters{ProjectID: &pid})
-		_, _ = s.GetDistribution(ctx, &pid)
+		_, _ = s.GetDistribution(ctx, models.AnalyticsFilters{ProjectID: &pid})
 	}
 
 	// Clean up old cache
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Approval chain errors
+var (
+	ErrInvalidApprovalChain  = errors.New("cadena de aprobación inválida")
+	ErrApprovalChainInUse    = errors.New("la cadena tiene contratos en aprobación; sus pasos no se pueden modificar")
+	ErrApprovalChainPending  = errors.New("el contrato requiere la cadena de aprobación")
+	ErrApprovalNotApprover   = errors.New("no es el aprobador del paso actual del contrato")
+	ErrApprovalCommentNeeded = errors.New("el comentario es requerido para rechazar")
+	ErrApprovalSameApprover  = errors.New("el creador del contrato o quien aprobó un paso anterior no puede aprobar este paso")
+)
+
+// ApprovalStepRequest is one step of an approval chain: a specific user or any user with the role
+type ApprovalStepRequest struct {
+	Name           string  `json:"name"`
+	ApproverUserID *uint   `json:"approver_user_id"`
+	ApproverRole   *string `json:"approver_role"`
+}
+
+// ApprovalChainRequest is the input for creating or updating an approval chain. On update, nil
+// fields keep their value and steps are only replaced when sent.
+type ApprovalChainRequest struct {
+	Name      *string               `json:"name"`
+	ProjectID *uint                 `json:"project_id"` // 0 = all projects
+	MinAmount *float64              `json:"min_amount"`
+	Active    *bool                 `json:"active"`
+	Steps     []ApprovalStepRequest `json:"steps"`
+}
+
+// ApprovalChainService manages the approval chains contracts go through before approval
+type ApprovalChainService struct {
+	repo            repository.ApprovalChainRepository
+	userRepo        repository.UserRepository
+	notificationSvc *NotificationService
+	auditSvc        *AuditService
+}
+
+func NewApprovalChainService(repo repository.ApprovalChainRepository, userRepo repository.UserRepository, notificationSvc *NotificationService, auditSvc *AuditService) *ApprovalChainService {
+	return &ApprovalChainService{
+		repo:            repo,
+		userRepo:        userRepo,
+		notificationSvc: notificationSvc,
+		auditSvc:        auditSvc,
+	}
+}
+
+// List returns every approval chain with its steps
+func (s *ApprovalChainService) List(ctx context.Context) ([]models.ApprovalChain, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// FindByID returns an approval chain with its steps
+func (s *ApprovalChainService) FindByID(ctx context.Context, id uint) (*models.ApprovalChain, error) {
+	chain, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return chain, err
+}
+
+// Create creates an approval chain
+func (s *ApprovalChainService) Create(ctx context.Context, req ApprovalChainRequest, actorID uint) (*models.ApprovalChain, error) {
+	chain := &models.ApprovalChain{Active: true}
+	if req.Steps == nil {
+		return nil, fmt.Errorf("%w: agregue al menos un paso", ErrInvalidApprovalChain)
+	}
+	if err := s.apply(ctx, chain, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, chain); err != nil {
+		return nil, fmt.Errorf("failed to create approval chain: %w", err)
+	}
+	s.audit(ctx, actorID, "CREATE", chain.ID, fmt.Sprintf("Cadena de aprobación creada: %s (%s)", chain.Name, stepNames(chain)))
+	return s.repo.FindByID(ctx, chain.ID)
+}
+
+// Update changes an approval chain. Steps cannot change while contracts are going through the chain.
+func (s *ApprovalChainService) Update(ctx context.Context, id uint, req ApprovalChainRequest, actorID uint) (*models.ApprovalChain, error) {
+	chain, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	replaceSteps := req.Steps != nil
+	if replaceSteps {
+		inFlight, err := s.repo.CountInFlight(ctx, chain.ID)
+		if err != nil {
+			return nil, err
+		}
+		if inFlight > 0 {
+			return nil, ErrApprovalChainInUse
+		}
+	}
+	chain.Project = nil
+	if err := s.apply(ctx, chain, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, chain, replaceSteps); err != nil {
+		return nil, fmt.Errorf("failed to update approval chain: %w", err)
+	}
+	s.audit(ctx, actorID, "UPDATE", chain.ID, fmt.Sprintf("Cadena de aprobación actualizada: %s (%s)", chain.Name, stepNames(chain)))
+	return s.repo.FindByID(ctx, chain.ID)
+}
+
+// Delete removes an approval chain; chains already assigned to contracts are deactivated instead
+func (s *ApprovalChainService) Delete(ctx context.Context, id uint, actorID uint) error {
+	chain, err := s.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	used, err := s.repo.CountUsed(ctx, chain.ID)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		chain.Active = false
+		chain.Project = nil
+		if err := s.repo.Update(ctx, chain, false); err != nil {
+			return err
+		}
+		s.audit(ctx, actorID, "DEACTIVATE", chain.ID, fmt.Sprintf("Cadena de aprobación desactivada (asignada a %d contratos): %s", used, chain.Name))
+		return nil
+	}
+	if err := s.repo.Delete(ctx, chain.ID); err != nil {
+		return err
+	}
+	s.audit(ctx, actorID, "DELETE", chain.ID, fmt.Sprintf("Cadena de aprobación eliminada: %s", chain.Name))
+	return nil
+}
+
+// Resolve returns the chain a contract of the project for the amount goes through, or nil
+// when it only needs the single admin approval
+func (s *ApprovalChainService) Resolve(ctx context.Context, projectID uint, amount float64) (*models.ApprovalChain, error) {
+	chains, err := s.repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.SelectApprovalChain(chains, projectID, amount), nil
+}
+
+// Start moves the contract from its status to submitted and assigns the chain (nil for the single
+// admin approval) from its first step. Returns false when the contract status changed meanwhile.
+func (s *ApprovalChainService) Start(ctx context.Context, contractID uint, fromStatus string, chain *models.ApprovalChain) (bool, error) {
+	if chain == nil {
+		return s.repo.Submit(ctx, contractID, fromStatus, nil, 0)
+	}
+	return s.repo.Submit(ctx, contractID, fromStatus, &chain.ID, len(chain.Steps))
+}
+
+// Record writes a decision on the contract's current chain step; false when the step was already decided
+func (s *ApprovalChainService) Record(ctx context.Context, approval *models.ContractApproval, fromStep int) (bool, error) {
+	return s.repo.RecordDecision(ctx, approval, fromStep)
+}
+
+// PendingFor returns the contracts waiting on the user's approval
+func (s *ApprovalChainService) PendingFor(ctx context.Context, userID uint) ([]models.Contract, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.repo.FindPendingForApprover(ctx, user)
+}
+
+// Approvals returns the chain decisions taken on a contract
+func (s *ApprovalChainService) Approvals(ctx context.Context, contractID uint) ([]models.ContractApproval, error) {
+	return s.repo.FindApprovalsByContract(ctx, contractID)
+}
+
+// NotifyApprovers tells the approvers of a step that a contract is waiting on them
+func (s *ApprovalChainService) NotifyApprovers(ctx context.Context, step *models.ApprovalChainStep, contract *models.Contract) error {
+	title := "Contrato pendiente de aprobación"
+	msg := fmt.Sprintf("El contrato #%d del lote %s (%s) de %s espera tu aprobación como %s",
+		contract.ID, contract.Lot.Name, contract.Lot.Project.Name, contract.ApplicantUser.FullName, step.Name)
+	if step.ApproverUserID != nil {
+		return s.notificationSvc.NotifyUser(ctx, *step.ApproverUserID, title, msg, models.NotificationTypeApprovalPending)
+	}
+	return s.notificationSvc.NotifyAdmins(ctx, title, msg, models.NotificationTypeApprovalPending)
+}
+
+// apply copies the request into the chain and validates it; steps are numbered in the order sent
+func (s *ApprovalChainService) apply(ctx context.Context, chain *models.ApprovalChain, req ApprovalChainRequest) error {
+	if req.Name != nil {
+		chain.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.ProjectID != nil {
+		chain.ProjectID = req.ProjectID
+		if *req.ProjectID == 0 {
+			chain.ProjectID = nil
+		}
+	}
+	if req.MinAmount != nil {
+		chain.MinAmount = *req.MinAmount
+	}
+	if req.Active != nil {
+		chain.Active = *req.Active
+	}
+	if chain.Name == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrInvalidApprovalChain)
+	}
+	if chain.MinAmount < 0 {
+		return fmt.Errorf("%w: el monto mínimo no puede ser negativo", ErrInvalidApprovalChain)
+	}
+	if req.Steps == nil {
+		return nil
+	}
+	if len(req.Steps) == 0 {
+		return fmt.Errorf("%w: agregue al menos un paso", ErrInvalidApprovalChain)
+	}
+
+	steps := make([]models.ApprovalChainStep, len(req.Steps))
+	for i, r := range req.Steps {
+		step := models.ApprovalChainStep{ChainID: chain.ID, Position: i + 1, Name: strings.TrimSpace(r.Name)}
+		if step.Name == "" {
+			return fmt.Errorf("%w: el paso %d necesita un nombre", ErrInvalidApprovalChain, i+1)
+		}
+		switch {
+		case r.ApproverUserID != nil && *r.ApproverUserID > 0:
+			user, err := s.userRepo.FindByID(ctx, *r.ApproverUserID)
+			if err != nil {
+				return fmt.Errorf("%w: el aprobador del paso %d no existe", ErrInvalidApprovalChain, i+1)
+			}
+			if user.Role != models.RoleAdmin && user.Role != models.RoleSeller {
+				return fmt.Errorf("%w: el aprobador del paso %d debe ser administrador o vendedor", ErrInvalidApprovalChain, i+1)
+			}
+			step.ApproverUserID = &user.ID
+		case r.ApproverRole != nil && *r.ApproverRole == models.RoleAdmin:
+			role := models.RoleAdmin
+			step.ApproverRole = &role
+		default:
+			return fmt.Errorf("%w: el paso %d necesita un aprobador (approver_user_id o approver_role admin)", ErrInvalidApprovalChain, i+1)
+		}
+		steps[i] = step
+	}
+	chain.Steps = steps
+	return nil
+}
+
+func (s *ApprovalChainService) audit(ctx context.Context, userID uint, action string, id uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "ApprovalChain", id, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ApprovalChainService] Failed to audit %s for chain %d: %v", action, id, err))
+	}
+}
+
+func stepNames(chain *models.ApprovalChain) string {
+	names := make([]string, len(chain.Steps))
+	for i, step := range chain.Steps {
+		names[i] = step.Name
+	}
+	return strings.Join(names, " → ")
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockApprovalChainRepository struct {
+	repository.ApprovalChainRepository
+	chains    []models.ApprovalChain
+	decisions []models.ContractApproval
+}
+
+func (m *mockApprovalChainRepository) FindByID(ctx context.Context, id uint) (*models.ApprovalChain, error) {
+	for i := range m.chains {
+		if m.chains[i].ID == id {
+			return &m.chains[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *mockApprovalChainRepository) FindActive(ctx context.Context) ([]models.ApprovalChain, error) {
+	return m.chains, nil
+}
+
+func (m *mockApprovalChainRepository) RecordDecision(ctx context.Context, approval *models.ContractApproval, fromStep int) (bool, error) {
+	m.decisions = append(m.decisions, *approval)
+	return true, nil
+}
+
+func (m *mockApprovalChainRepository) FindApprovalsByContract(ctx context.Context, contractID uint) ([]models.ContractApproval, error) {
+	return m.decisions, nil
+}
+
+func TestSelectApprovalChain(t *testing.T) {
+	u := func(v uint) *uint { return &v }
+	steps := []models.ApprovalChainStep{{Position: 1, Name: "Gerente de ventas"}}
+	chains := []models.ApprovalChain{
+		{ID: 1, Name: "General", Active: true, Steps: steps},
+		{ID: 2, Name: "General grandes", MinAmount: 1000000, Active: true, Steps: steps},
+		{ID: 3, Name: "Proyecto 7", ProjectID: u(7), Active: true, Steps: steps},
+		{ID: 4, Name: "Proyecto 7 grandes", ProjectID: u(7), MinAmount: 1000000, Active: false, Steps: steps},
+		{ID: 5, Name: "Sin pasos", ProjectID: u(8), Active: true},
+	}
+	id := func(projectID uint, amount float64) uint {
+		if c := models.SelectApprovalChain(chains, projectID, amount); c != nil {
+			return c.ID
+		}
+		return 0
+	}
+
+	assert.Equal(t, uint(1), id(1, 500000))
+	assert.Equal(t, uint(2), id(1, 1500000))
+	assert.Equal(t, uint(3), id(7, 1500000)) // project chain wins; the inactive one is ignored
+	assert.Equal(t, uint(1), id(8, 500000))  // chains without steps never apply
+	assert.Nil(t, models.SelectApprovalChain(chains[2:3], 1, 500000))
+}
+
+func TestContractService_DecideApproval(t *testing.T) {
+	admin, director := models.RoleAdmin, uint(30)
+	chain := models.ApprovalChain{ID: 1, Name: "Grandes", Active: true, Steps: []models.ApprovalChainStep{
+		{ID: 11, Position: 1, Name: "Finanzas", ApproverRole: &admin},
+		{ID: 12, Position: 2, Name: "Director", ApproverUserID: &director},
+	}}
+	amount := 1500000.0
+	contract := &models.Contract{ID: 5, Status: models.ContractStatusSubmitted, Amount: &amount,
+		ApprovalChainID: &chain.ID, ApprovalSteps: 2}
+	users := map[uint]*models.User{
+		10: {ID: 10, FullName: "Admin", Role: models.RoleAdmin},
+		20: {ID: 20, FullName: "Vendedor", Role: models.RoleSeller},
+		30: {ID: 30, FullName: "Director", Role: models.RoleSeller},
+	}
+	repo := &mockApprovalChainRepository{chains: []models.ApprovalChain{chain}}
+	svc := &ContractService{
+		repo: &mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+			c := *contract
+			return &c, nil
+		}},
+		userRepo: &mockUserRepo{mockFindByID: func(ctx context.Context, id uint) (*models.User, error) {
+			return users[id], nil
+		}},
+		approvalSvc: NewApprovalChainService(repo, nil, nil, nil),
+	}
+	ctx := context.Background()
+	decide := func(actorID uint, decision, comment string) error {
+		_, err := svc.DecideApproval(ctx, 5, ApprovalDecisionRequest{Decision: decision, Comment: comment}, actorID, "", "")
+		return err
+	}
+
+	assert.ErrorIs(t, decide(10, "maybe", ""), ErrInvalidApprovalChain)
+	assert.ErrorIs(t, decide(10, "rejected", " "), ErrApprovalCommentNeeded)
+	assert.ErrorIs(t, decide(20, "approved", ""), ErrApprovalNotApprover) // sellers are not the finance step
+	assert.ErrorIs(t, decide(30, "approved", ""), ErrApprovalNotApprover) // the director approves step 2, not step 1
+	require.Empty(t, repo.decisions)
+
+	// Once finance approved, only the director can take the last step
+	contract.ApprovalStep = 1
+	assert.ErrorIs(t, decide(10, "approved", ""), ErrApprovalNotApprover)
+	contract.ApprovalStep = 2
+	assert.ErrorIs(t, decide(30, "approved", ""), ErrInvalidState) // chain already complete
+	require.Empty(t, repo.decisions)
+	contract.ApprovalStep = 0
+
+	// The admin one-click approval is refused while chain steps are left
+	_, err := svc.Approve(ctx, 5, nil)
+	assert.ErrorIs(t, err, ErrApprovalChainPending)
+	assert.Contains(t, err.Error(), "Finanzas")
+
+	// Not yet submitted but a chain applies: it must go through submission
+	contract.ApprovalChainID, contract.ApprovalSteps, contract.Status = nil, 0, models.ContractStatusPending
+	_, err = svc.Approve(ctx, 5, nil)
+	assert.ErrorIs(t, err, ErrApprovalChainPending)
+}
+
+func TestContractService_DecideApprovalSegregation(t *testing.T) {
+	admin, creator := models.RoleAdmin, uint(11)
+	chain := models.ApprovalChain{ID: 1, Name: "Doble firma", Active: true, Steps: []models.ApprovalChainStep{
+		{ID: 11, Position: 1, Name: "Finanzas", ApproverRole: &admin},
+		{ID: 12, Position: 2, Name: "Gerencia", ApproverRole: &admin},
+	}}
+	contract := &models.Contract{ID: 5, Status: models.ContractStatusSubmitted, CreatorID: &creator,
+		ApprovalChainID: &chain.ID, ApprovalSteps: 2}
+	users := map[uint]*models.User{
+		10: {ID: 10, FullName: "Admin", Role: models.RoleAdmin},
+		11: {ID: 11, FullName: "Admin creador", Role: models.RoleAdmin},
+	}
+	repo := &mockApprovalChainRepository{chains: []models.ApprovalChain{chain}}
+	svc := &ContractService{
+		repo: &mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+			c := *contract
+			return &c, nil
+		}},
+		userRepo: &mockUserRepo{mockFindByID: func(ctx context.Context, id uint) (*models.User, error) {
+			return users[id], nil
+		}},
+		approvalSvc: NewApprovalChainService(repo, nil, nil, nil),
+	}
+	decide := func(actorID uint) error {
+		_, err := svc.DecideApproval(context.Background(), 5, ApprovalDecisionRequest{Decision: "approved"}, actorID, "", "")
+		return err
+	}
+
+	// The creator cannot approve any step of their own contract
+	assert.ErrorIs(t, decide(11), ErrApprovalSameApprover)
+
+	// Whoever approved finance cannot also approve the last step
+	repo.decisions = []models.ContractApproval{{ContractID: 5, ChainID: 1, Position: 1, StepName: "Finanzas", ApproverID: 10, Decision: models.ApprovalDecisionApproved}}
+	contract.ApprovalStep = 1
+	err := decide(10)
+	assert.ErrorIs(t, err, ErrApprovalSameApprover)
+	assert.Contains(t, err.Error(), "Finanzas")
+	require.Len(t, repo.decisions, 1)
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"gorm.io/gorm"
+)
+
+// ApprovalDecisionRequest is the input for approving or rejecting the current step of a contract's approval chain
+type ApprovalDecisionRequest struct {
+	Decision string `json:"decision" binding:"required"` // approved or rejected
+	Comment  string `json:"comment"`                     // required to reject
+}
+
+// Submit sends a pending or rejected contract to approval. The approval chain for its project
+// and amount is assigned and its first approvers notified; without a chain the admins are
+// notified and a single admin approval is enough.
+func (s *ContractService) Submit(ctx context.Context, id uint, actorID uint, ip, userAgent string) (*models.Contract, error) {
+	contract, err := s.repo.FindByIDWithDetails(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	from := contract.Status
+	fsm := statemachine.NewContractFSM(contract)
+	if err := fsm.Submit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	amount := 0.0
+	if contract.Amount != nil {
+		amount = *contract.Amount
+	}
+	chain, err := s.approvalSvc.Resolve(ctx, contract.Lot.ProjectID, amount)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.approvalSvc.Start(ctx, contract.ID, from, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit contract: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: el contrato cambió mientras se enviaba a aprobación", ErrInvalidState)
+	}
+	s.recordTransition(ctx, fsm, contract.ID, &actorID, "")
+	contract.ApprovalChainID, contract.ApprovalStep, contract.ApprovalSteps = nil, 0, 0
+	if chain != nil {
+		contract.ApprovalChainID, contract.ApprovalSteps = &chain.ID, len(chain.Steps)
+	}
+	contract.RejectionReason = nil
+
+	contractForNotice := contract
+	s.worker.EnqueueAsync(func(ctx context.Context) error {
+		if chain != nil {
+			return s.approvalSvc.NotifyApprovers(ctx, chain.StepAt(1), contractForNotice)
+		}
+		return s.notificationSvc.NotifyAdmins(ctx, "Contrato enviado a aprobación",
+			fmt.Sprintf("El contrato #%d del lote %s (%s) de %s fue enviado a aprobación",
+				contractForNotice.ID, contractForNotice.Lot.Name, contractForNotice.Lot.Project.Name, contractForNotice.ApplicantUser.FullName),
+			models.NotificationTypeContractSubmitted)
+	})
+
+	details := fmt.Sprintf("Contrato enviado a aprobación. Lote: %s", contract.Lot.Name)
+	if chain != nil {
+		details += fmt.Sprintf(". Cadena: %s (%s)", chain.Name, stepNames(chain))
+	}
+	s.auditSvc.Log(ctx, actorID, "SUBMIT", "Contract", contract.ID, details, ip, userAgent)
+	return contract, nil
+}
+
+// DecideApproval records the actor's decision on the current step of the contract's approval
+// chain. An approval moves to the next step (notifying its approvers) and the last one approves
+// the contract; a rejection rejects the contract, which must be submitted again. The contract
+// creator and whoever approved an earlier step cannot approve.
+func (s *ContractService) DecideApproval(ctx context.Context, id uint, req ApprovalDecisionRequest, actorID uint, ip, userAgent string) (*models.Contract, error) {
+	decision := strings.ToLower(strings.TrimSpace(req.Decision))
+	comment := strings.TrimSpace(req.Comment)
+	if decision != models.ApprovalDecisionApproved && decision != models.ApprovalDecisionRejected {
+		return nil, fmt.Errorf("%w: la decisión debe ser approved o rejected", ErrInvalidApprovalChain)
+	}
+	if decision == models.ApprovalDecisionRejected && comment == "" {
+		return nil, ErrApprovalCommentNeeded
+	}
+
+	contract, err := s.repo.FindByIDWithDetails(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if contract.Status != models.ContractStatusSubmitted || contract.ApprovalChainID == nil || contract.ApprovalChainComplete() {
+		return nil, fmt.Errorf("%w: el contrato no está esperando aprobaciones de su cadena", ErrInvalidState)
+	}
+	chain, err := s.approvalSvc.FindByID(ctx, *contract.ApprovalChainID)
+	if err != nil {
+		return nil, err
+	}
+	step := chain.StepAt(contract.ApprovalStep + 1)
+	if step == nil {
+		return nil, fmt.Errorf("%w: la cadena %s ya no tiene el paso %d", ErrInvalidState, chain.Name, contract.ApprovalStep+1)
+	}
+	actor, err := s.userRepo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !step.CanBeApprovedBy(actor) {
+		return nil, fmt.Errorf("%w (%s)", ErrApprovalNotApprover, step.Name)
+	}
+	if decision == models.ApprovalDecisionApproved {
+		if err := s.checkSegregation(ctx, contract, actor.ID); err != nil {
+			return nil, err
+		}
+	}
+	// The last step approves the contract: refuse it before recording while the KYC checklist is missing items
+	if decision == models.ApprovalDecisionApproved && contract.ApprovalStep+1 >= contract.ApprovalSteps {
+		if err := s.checkKYC(ctx, contract); err != nil {
+			return nil, err
+		}
+	}
+
+	approval := &models.ContractApproval{
+		ContractID: contract.ID,
+		ChainID:    chain.ID,
+		StepID:     step.ID,
+		Position:   step.Position,
+		StepName:   step.Name,
+		ApproverID: actor.ID,
+		Decision:   decision,
+	}
+	if comment != "" {
+		approval.Comment = &comment
+	}
+	decided, err := s.approvalSvc.Record(ctx, approval, contract.ApprovalStep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+	if !decided {
+		return nil, fmt.Errorf("%w: el paso %s ya fue decidido", ErrInvalidState, step.Name)
+	}
+
+	verb := "aprobado"
+	if decision == models.ApprovalDecisionRejected {
+		verb = "rechazado"
+	}
+	details := fmt.Sprintf("Paso %d de %d (%s) %s por %s", step.Position, len(chain.Steps), step.Name, verb, actor.FullName)
+	if comment != "" {
+		details += ". Comentario: " + comment
+	}
+	s.auditSvc.Log(ctx, actorID, "APPROVAL_STEP", "Contract", contract.ID, details, ip, userAgent)
+
+	if decision == models.ApprovalDecisionRejected {
+		return s.Reject(ctx, contract.ID, fmt.Sprintf("%s: %s", step.Name, comment), &actorID)
+	}
+	contract.ApprovalStep++
+	if contract.ApprovalChainComplete() {
+		return s.Approve(ctx, contract.ID, &actorID)
+	}
+
+	next := chain.StepAt(contract.ApprovalStep + 1)
+	s.worker.EnqueueAsync(func(ctx context.Context) error {
+		return s.approvalSvc.NotifyApprovers(ctx, next, contract)
+	})
+	return contract, nil
+}
+
+// checkSegregation refuses an approval by the contract creator or by the approver of an earlier
+// step of the current submission (the latest decision of each step already passed)
+func (s *ContractService) checkSegregation(ctx context.Context, contract *models.Contract, actorID uint) error {
+	if contract.CreatorID != nil && *contract.CreatorID == actorID {
+		return fmt.Errorf("%w: usted creó el contrato", ErrApprovalSameApprover)
+	}
+	approvals, err := s.approvalSvc.Approvals(ctx, contract.ID)
+	if err != nil {
+		return err
+	}
+	latest := make(map[int]models.ContractApproval)
+	for _, a := range approvals {
+		if a.ChainID == *contract.ApprovalChainID && a.Position <= contract.ApprovalStep {
+			latest[a.Position] = a
+		}
+	}
+	for _, a := range latest {
+		if a.ApproverID == actorID && a.Decision == models.ApprovalDecisionApproved {
+			return fmt.Errorf("%w: usted aprobó el paso %s", ErrApprovalSameApprover, a.StepName)
+		}
+	}
+	return nil
+}
+
+// checkApprovalChain refuses the direct approval of a contract that still needs its approval
+// chain: contracts with a chain step left, or not yet submitted while a chain applies to them
+func (s *ContractService) checkApprovalChain(ctx context.Context, contract *models.Contract) error {
+	if s.approvalSvc == nil {
+		return nil
+	}
+	if contract.ApprovalChainID != nil {
+		if contract.ApprovalChainComplete() {
+			return nil
+		}
+		chain, err := s.approvalSvc.FindByID(ctx, *contract.ApprovalChainID)
+		if err != nil {
+			return err
+		}
+		if step := chain.StepAt(contract.ApprovalStep + 1); step != nil {
+			return fmt.Errorf("%w: falta la aprobación de %s (paso %d de %d)", ErrApprovalChainPending, step.Name, step.Position, contract.ApprovalSteps)
+		}
+		return fmt.Errorf("%w: %s", ErrApprovalChainPending, chain.Name)
+	}
+
+	amount := 0.0
+	if contract.Amount != nil {
+		amount = *contract.Amount
+	}
+	chain, err := s.approvalSvc.Resolve(ctx, contract.Lot.ProjectID, amount)
+	if err != nil {
+		return err
+	}
+	if chain != nil {
+		return fmt.Errorf("%w %s; envíe el contrato a aprobación", ErrApprovalChainPending, chain.Name)
+	}
+	return nil
+}
+
+// checkKYC refuses the approval of a contract missing items of its KYC checklist, unless overridden
+func (s *ContractService) checkKYC(ctx context.Context, contract *models.Contract) error {
+	if s.kycSvc == nil {
+		return nil
+	}
+	return s.kycSvc.Check(ctx, contract)
+}
+
+// KYCChecklist returns the KYC checklist completion status of a contract loaded with its details
+func (s *ContractService) KYCChecklist(ctx context.Context, contract *models.Contract) (*models.KYCChecklist, error) {
+	if s.kycSvc == nil {
+		return nil, nil
+	}
+	return s.kycSvc.Checklist(ctx, contract)
+}
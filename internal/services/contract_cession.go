@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// Contract cession errors
+var (
+	ErrCessionSameBuyer     = errors.New("el nuevo comprador debe ser distinto del titular actual")
+	ErrCessionBuyerInactive = errors.New("el nuevo comprador no está activo")
+	ErrCessionBuyerIsParty  = errors.New("el nuevo comprador ya es participante del contrato; retírelo antes de la cesión")
+	ErrCessionInvalidFee    = errors.New("el cargo por cesión no puede ser negativo")
+	ErrCessionUnknownParty  = errors.New("el participante a conservar no pertenece al contrato")
+)
+
+// CessionRequest is the input for transferring a contract to a new buyer
+type CessionRequest struct {
+	NewApplicantUserID uint    `json:"new_applicant_user_id" binding:"required"`
+	TransferFee        float64 `json:"transfer_fee"` // optional; charged to the contract as a fee payment
+	Note               string  `json:"note"`
+	KeepPartyIDs       []uint  `json:"keep_party_ids"` // parties carried over to the new buyer; the rest are removed
+}
+
+// Cede transfers an approved contract to a new applicant. Payments, ledger and paid history stay
+// with the contract; pending payments, reminders and notifications follow the new buyer from now
+// on. The co-buyers, guarantors and representatives of the previous buyer are removed unless
+// listed in KeepPartyIDs. An optional transfer fee is charged as a pending fee payment with its
+// ledger debit.
+func (s *ContractService) Cede(ctx context.Context, id uint, req CessionRequest, actorID uint, ip, userAgent string) (*models.ContractCession, error) {
+	if req.TransferFee < 0 {
+		return nil, ErrCessionInvalidFee
+	}
+	contract, err := s.repo.FindByIDWithDetails(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !contract.InForce() {
+		return nil, fmt.Errorf("%w: solo se pueden ceder contratos aprobados (estado actual: %s)", ErrInvalidState, contract.Status)
+	}
+	if req.NewApplicantUserID == contract.ApplicantUserID {
+		return nil, ErrCessionSameBuyer
+	}
+	keep := make(map[uint]bool, len(req.KeepPartyIDs))
+	for _, id := range req.KeepPartyIDs {
+		keep[id] = true
+	}
+	var removed []models.ContractParty
+	for _, party := range contract.Parties {
+		if !keep[party.ID] {
+			removed = append(removed, party)
+			continue
+		}
+		delete(keep, party.ID)
+		if party.UserID != nil && *party.UserID == req.NewApplicantUserID {
+			return nil, ErrCessionBuyerIsParty
+		}
+	}
+	if len(keep) > 0 {
+		return nil, ErrCessionUnknownParty
+	}
+	newBuyer, err := s.userRepo.FindByID(ctx, req.NewApplicantUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !newBuyer.IsActive() {
+		return nil, ErrCessionBuyerInactive
+	}
+
+	paid := 0.0
+	for _, p := range contract.Payments {
+		if p.Status == models.PaymentStatusPaid && p.PaidAmount != nil {
+			paid += *p.PaidAmount
+		}
+	}
+	balance := contract.CalculateBalance()
+	if contract.Balance != nil {
+		balance = *contract.Balance
+	}
+
+	cession := &models.ContractCession{
+		ContractID:       contract.ID,
+		FromUserID:       contract.ApplicantUserID,
+		ToUserID:         newBuyer.ID,
+		ActorID:          actorID,
+		PaidAtCession:    paid,
+		BalanceAtCession: balance,
+	}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		cession.Note = &note
+	}
+
+	var feePayment *models.Payment
+	var feeEntry *models.ContractLedgerEntry
+	if req.TransferFee > 0 {
+		fee := req.TransferFee
+		now := time.Now()
+		desc := fmt.Sprintf("Cargo por cesión de contrato a %s", newBuyer.FullName)
+		cession.TransferFee = &fee
+		feePayment = &models.Payment{
+			ContractID:  contract.ID,
+			Amount:      fee,
+			DueDate:     now,
+			Status:      models.PaymentStatusPending,
+			PaymentType: models.PaymentTypeFee,
+			Description: &desc,
+		}
+		feeEntry = &models.ContractLedgerEntry{
+			ContractID:  contract.ID,
+			Amount:      -fee, // Negative: increases the debt
+			Description: desc,
+			EntryType:   models.EntryTypeFee,
+			EntryDate:   now,
+		}
+	}
+
+	removedIDs := make([]uint, len(removed))
+	for i, party := range removed {
+		removedIDs[i] = party.ID
+	}
+	moved, err := s.cessionRepo.Cede(ctx, cession, removedIDs, feePayment, feeEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cede contract: %w", err)
+	}
+	if !moved {
+		return nil, fmt.Errorf("%w: el contrato cambió mientras se procesaba la cesión", ErrInvalidState)
+	}
+
+	previousBuyer := contract.ApplicantUser
+	lotName, projectName := contract.Lot.Name, contract.Lot.Project.Name
+	s.worker.EnqueueAsync(func(ctx context.Context) error {
+		if err := s.notificationSvc.NotifyUser(ctx, previousBuyer.ID,
+			"Contrato cedido",
+			fmt.Sprintf("Tu contrato del lote %s (%s) fue cedido a %s", lotName, projectName, newBuyer.FullName),
+			models.NotificationTypeContractApproved); err != nil {
+			return err
+		}
+		return s.notificationSvc.NotifyUser(ctx, newBuyer.ID,
+			"Contrato cedido a tu nombre",
+			fmt.Sprintf("El contrato del lote %s (%s) fue cedido a tu nombre. Los pagos pendientes ahora son tuyos.", lotName, projectName),
+			models.NotificationTypeContractApproved)
+	})
+
+	// Audit the contract and both buyers
+	details := fmt.Sprintf("Contrato #%d (lote %s, %s) cedido de %s (ID %d) a %s (ID %d). Pagado: %.2f. Saldo: %.2f",
+		contract.ID, lotName, projectName, previousBuyer.FullName, previousBuyer.ID, newBuyer.FullName, newBuyer.ID, paid, balance)
+	if cession.TransferFee != nil {
+		details += fmt.Sprintf(". Cargo por cesión: %.2f", *cession.TransferFee)
+	}
+	if len(removed) > 0 {
+		names := make([]string, len(removed))
+		for i, party := range removed {
+			names[i] = fmt.Sprintf("%s %s", party.RoleLabel(), party.FullName)
+		}
+		details += ". Participantes retirados: " + strings.Join(names, ", ")
+	}
+	if cession.Note != nil {
+		details += ". Nota: " + *cession.Note
+	}
+	s.auditSvc.Log(ctx, actorID, "CESSION", "Contract", contract.ID, details, ip, userAgent)
+	s.snapshotTerms(ctx, contract.ID, models.TermsSourceCession, &actorID, fmt.Sprintf("Cesión a %s", newBuyer.FullName))
+	s.auditSvc.Log(ctx, actorID, "CESSION_OUT", "User", previousBuyer.ID,
+		fmt.Sprintf("Cedió el contrato #%d a %s (ID %d)", contract.ID, newBuyer.FullName, newBuyer.ID), ip, userAgent)
+	s.auditSvc.Log(ctx, actorID, "CESSION_IN", "User", newBuyer.ID,
+		fmt.Sprintf("Recibió el contrato #%d de %s (ID %d)", contract.ID, previousBuyer.FullName, previousBuyer.ID), ip, userAgent)
+
+	cession.FromUser, cession.ToUser = previousBuyer, *newBuyer
+	return cession, nil
+}
+
+// ListCessions returns the cession history of a contract, oldest first
+func (s *ContractService) ListCessions(ctx context.Context, contractID uint) ([]models.ContractCession, error) {
+	return s.cessionRepo.FindByContract(ctx, contractID)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCessionRepository struct {
+	repository.ContractCessionRepository
+	moved      bool
+	cession    *models.ContractCession
+	removed    []uint
+	feePayment *models.Payment
+	feeEntry   *models.ContractLedgerEntry
+}
+
+func (m *mockCessionRepository) Cede(ctx context.Context, cession *models.ContractCession, removedPartyIDs []uint, feePayment *models.Payment, feeEntry *models.ContractLedgerEntry) (bool, error) {
+	m.cession, m.removed, m.feePayment, m.feeEntry = cession, removedPartyIDs, feePayment, feeEntry
+	return m.moved, nil
+}
+
+func TestContractService_Cede_Guards(t *testing.T) {
+	u := func(v uint) *uint { return &v }
+	paid, balance := 25000.0, -75000.0
+	contract := &models.Contract{
+		ID: 1, ApplicantUserID: 10, Status: models.ContractStatusApproved, Balance: &balance,
+		Payments: []models.Payment{{Status: models.PaymentStatusPaid, PaidAmount: &paid}},
+		Parties: []models.ContractParty{
+			{ID: 5, UserID: u(30), Role: models.PartyRoleCoBuyer, FullName: "Co Compradora"},
+			{ID: 6, Role: models.PartyRoleGuarantor, FullName: "Fiador Anterior"},
+		},
+	}
+	users := map[uint]*models.User{
+		20: {ID: 20, FullName: "Nuevo Comprador", Status: models.StatusActive},
+		21: {ID: 21, FullName: "Inactivo", Status: models.StatusInactive},
+	}
+	cessions := &mockCessionRepository{}
+	svc := &ContractService{
+		repo: &mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+			return contract, nil
+		}},
+		userRepo: &mockUserRepo{mockFindByID: func(ctx context.Context, id uint) (*models.User, error) {
+			return users[id], nil
+		}},
+		cessionRepo: cessions,
+	}
+	ctx := context.Background()
+	cede := func(to uint, fee float64, keep ...uint) error {
+		_, err := svc.Cede(ctx, 1, CessionRequest{NewApplicantUserID: to, TransferFee: fee, KeepPartyIDs: keep}, 1, "", "")
+		return err
+	}
+
+	assert.ErrorIs(t, cede(10, 0), ErrCessionSameBuyer)
+	assert.ErrorIs(t, cede(30, 0, 5), ErrCessionBuyerIsParty)
+	assert.ErrorIs(t, cede(20, 0, 99), ErrCessionUnknownParty)
+	assert.ErrorIs(t, cede(21, 0), ErrCessionBuyerInactive)
+	assert.ErrorIs(t, cede(20, -5), ErrCessionInvalidFee)
+
+	// The contract changed hands meanwhile: the compare-and-set did not move it
+	assert.ErrorIs(t, cede(20, 1500), ErrInvalidState)
+	require.NotNil(t, cessions.cession)
+	assert.Equal(t, uint(10), cessions.cession.FromUserID)
+	assert.Equal(t, uint(20), cessions.cession.ToUserID)
+	assert.Equal(t, 25000.0, cessions.cession.PaidAtCession)
+	assert.Equal(t, -75000.0, cessions.cession.BalanceAtCession)
+	require.NotNil(t, cessions.feePayment)
+	assert.Equal(t, models.PaymentTypeFee, cessions.feePayment.PaymentType)
+	assert.Equal(t, -1500.0, cessions.feeEntry.Amount)
+
+	// The previous buyer's parties go away with the cession unless carried over
+	assert.Equal(t, []uint{5, 6}, cessions.removed)
+	assert.ErrorIs(t, cede(20, 0, 6), ErrInvalidState)
+	assert.Equal(t, []uint{5}, cessions.removed)
+
+	contract.Status = models.ContractStatusPending
+	assert.ErrorIs(t, cede(20, 0), ErrInvalidState)
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidDocument is returned for unknown document types and files that cannot be stored
+var ErrInvalidDocument = errors.New("documento inválido")
+
+// DocumentActor is the user acting on contract documents
+type DocumentActor struct {
+	UserID uint
+	Role   string
+}
+
+// isStaff returns true for admins and sellers, who manage the documents of every contract
+func (a DocumentActor) isStaff() bool {
+	return a.Role == models.RoleAdmin || a.Role == models.RoleSeller
+}
+
+// ContractDocumentService manages the typed, versioned documents attached to contracts
+type ContractDocumentService struct {
+	repo         repository.ContractDocumentRepository
+	contractRepo repository.ContractRepository
+	storage      *storage.LocalStorage
+	auditSvc     *AuditService
+}
+
+func NewContractDocumentService(repo repository.ContractDocumentRepository, contractRepo repository.ContractRepository, storage *storage.LocalStorage, auditSvc *AuditService) *ContractDocumentService {
+	return &ContractDocumentService{
+		repo:         repo,
+		contractRepo: contractRepo,
+		storage:      storage,
+		auditSvc:     auditSvc,
+	}
+}
+
+// List returns the current version of each document of the contract
+func (s *ContractDocumentService) List(ctx context.Context, contractID uint, actor DocumentActor) ([]models.ContractDocument, error) {
+	if _, err := s.authorize(ctx, contractID, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.FindCurrentByContract(ctx, contractID)
+}
+
+// Versions returns every version of a document, oldest first
+func (s *ContractDocumentService) Versions(ctx context.Context, contractID, documentID uint, actor DocumentActor) ([]models.ContractDocument, error) {
+	document, err := s.find(ctx, contractID, documentID, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindVersions(ctx, document.LineageID)
+}
+
+// Download returns a document and the full path of its file
+func (s *ContractDocumentService) Download(ctx context.Context, contractID, documentID uint, actor DocumentActor) (*models.ContractDocument, string, error) {
+	document, err := s.find(ctx, contractID, documentID, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	fullPath, err := s.storage.SafeFullPath(document.Path)
+	if err != nil || !s.storage.Exists(document.Path) {
+		return nil, "", ErrNotFound
+	}
+	return document, fullPath, nil
+}
+
+// Upload attaches a new document to the contract. Buyers may only upload their own ID copy,
+// proof of income and other documents; signed promises and deeds come from admins and sellers.
+func (s *ContractDocumentService) Upload(ctx context.Context, contractID uint, docType string, header *multipart.FileHeader, actor DocumentActor) (*models.ContractDocument, error) {
+	docType = strings.ToLower(strings.TrimSpace(docType))
+	if !models.IsValidDocumentType(docType) {
+		return nil, fmt.Errorf("%w: el tipo debe ser uno de %s", ErrInvalidDocument, strings.Join(models.DocumentTypes, ", "))
+	}
+	if _, err := s.authorize(ctx, contractID, actor); err != nil {
+		return nil, err
+	}
+	if !actor.isStaff() && !models.CustomerDocumentType(docType) {
+		return nil, ErrUnauthorized
+	}
+
+	document, err := s.store(contractID, docType, header, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, document); err != nil {
+		s.removeFile(document.Path)
+		return nil, fmt.Errorf("failed to create document: %w", err)
+	}
+	s.audit(ctx, actor.UserID, "DOCUMENT_UPLOAD", contractID,
+		fmt.Sprintf("Documento %s agregado: %s (SHA-256 %s)", document.DocumentType, document.FileName, document.SHA256))
+	return s.repo.FindByID(ctx, document.ID)
+}
+
+// Replace uploads a new version of a document; the previous version is kept as superseded
+func (s *ContractDocumentService) Replace(ctx context.Context, contractID, documentID uint, header *multipart.FileHeader, actor DocumentActor) (*models.ContractDocument, error) {
+	current, err := s.find(ctx, contractID, documentID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !current.IsCurrent() {
+		return nil, fmt.Errorf("%w: solo se puede reemplazar la versión vigente del documento", ErrInvalidState)
+	}
+	if !actor.isStaff() && !models.CustomerDocumentType(current.DocumentType) {
+		return nil, ErrUnauthorized
+	}
+
+	next, err := s.store(contractID, current.DocumentType, header, actor)
+	if err != nil {
+		return nil, err
+	}
+	replaced, err := s.repo.Replace(ctx, current, next)
+	if err != nil || !replaced {
+		s.removeFile(next.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replace document: %w", err)
+		}
+		return nil, fmt.Errorf("%w: el documento cambió mientras se reemplazaba", ErrInvalidState)
+	}
+	s.audit(ctx, actor.UserID, "DOCUMENT_REPLACE", contractID,
+		fmt.Sprintf("Documento %s reemplazado (versión %d): %s (SHA-256 %s)", next.DocumentType, next.Version, next.FileName, next.SHA256))
+	return s.repo.FindByID(ctx, next.ID)
+}
+
+// Delete soft-deletes a document with all its versions (admins only); the files are kept
+func (s *ContractDocumentService) Delete(ctx context.Context, contractID, documentID uint, actor DocumentActor) error {
+	if actor.Role != models.RoleAdmin {
+		return ErrUnauthorized
+	}
+	document, err := s.find(ctx, contractID, documentID, actor)
+	if err != nil {
+		return err
+	}
+	if document.DeletedAt != nil {
+		return nil
+	}
+	if err := s.repo.SoftDelete(ctx, document.LineageID, actor.UserID); err != nil {
+		return err
+	}
+	s.audit(ctx, actor.UserID, "DOCUMENT_DELETE", contractID,
+		fmt.Sprintf("Documento %s eliminado: %s", document.DocumentType, document.FileName))
+	return nil
+}
+
+// authorize loads the contract and checks the actor can see its documents: admins and sellers,
+// or the buyer (applicant or co-buyer)
+func (s *ContractDocumentService) authorize(ctx context.Context, contractID uint, actor DocumentActor) (*models.Contract, error) {
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !actor.isStaff() && !contract.IsOwnedBy(actor.UserID) {
+		return nil, ErrUnauthorized
+	}
+	return contract, nil
+}
+
+// find returns a document of the contract the actor can see; deleted documents only to admins
+func (s *ContractDocumentService) find(ctx context.Context, contractID, documentID uint, actor DocumentActor) (*models.ContractDocument, error) {
+	if _, err := s.authorize(ctx, contractID, actor); err != nil {
+		return nil, err
+	}
+	document, err := s.repo.FindByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if document.ContractID != contractID || (document.DeletedAt != nil && actor.Role != models.RoleAdmin) {
+		return nil, ErrNotFound
+	}
+	return document, nil
+}
+
+// store validates the uploaded file and saves it with its checksum
+func (s *ContractDocumentService) store(contractID uint, docType string, header *multipart.FileHeader, actor DocumentActor) (*models.ContractDocument, error) {
+	if header == nil {
+		return nil, fmt.Errorf("%w: el archivo es requerido", ErrInvalidDocument)
+	}
+	contentType := header.Header.Get("Content-Type")
+	if !storage.IsValidContentType(contentType) {
+		return nil, fmt.Errorf("%w: solo se permiten archivos PDF, JPG o PNG", ErrInvalidDocument)
+	}
+	if header.Size > storage.MaxFileSize() {
+		return nil, fmt.Errorf("%w: el archivo excede el tamaño máximo de 10 MB", ErrInvalidDocument)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: no se pudo leer el archivo", ErrInvalidDocument)
+	}
+	defer file.Close()
+
+	path, checksum, size, err := s.storage.UploadHashed(file, header, fmt.Sprintf("contracts/%d/documents", contractID))
+	if err != nil {
+		return nil, err
+	}
+	return &models.ContractDocument{
+		ContractID:   contractID,
+		Version:      1,
+		DocumentType: docType,
+		FileName:     filepath.Base(header.Filename),
+		ContentType:  contentType,
+		Size:         size,
+		Path:         path,
+		SHA256:       checksum,
+		UploadedByID: actor.UserID,
+	}, nil
+}
+
+func (s *ContractDocumentService) removeFile(path string) {
+	if err := s.storage.Delete(path); err != nil {
+		logger.Error(fmt.Sprintf("[ContractDocumentService] Failed to remove orphan file %s: %v", path, err))
+	}
+}
+
+func (s *ContractDocumentService) audit(ctx context.Context, userID uint, action string, contractID uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "Contract", contractID, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ContractDocumentService] Failed to audit %s for contract %d: %v", action, contractID, err))
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockContractDocumentRepository struct {
+	repository.ContractDocumentRepository
+	documents map[uint]*models.ContractDocument
+}
+
+func (m *mockContractDocumentRepository) FindByID(ctx context.Context, id uint) (*models.ContractDocument, error) {
+	if document, ok := m.documents[id]; ok {
+		return document, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (m *mockContractDocumentRepository) FindCurrentByContract(ctx context.Context, contractID uint) ([]models.ContractDocument, error) {
+	var documents []models.ContractDocument
+	for _, document := range m.documents {
+		if document.ContractID == contractID && document.IsCurrent() {
+			documents = append(documents, *document)
+		}
+	}
+	return documents, nil
+}
+
+func TestContractDocumentAccess(t *testing.T) {
+	ctx := context.Background()
+	coBuyerID := uint(8)
+	deletedAt := time.Now()
+	contract := &models.Contract{
+		ID:              4,
+		ApplicantUserID: 7,
+		Parties:         []models.ContractParty{{Role: models.PartyRoleCoBuyer, UserID: &coBuyerID}},
+	}
+	svc := NewContractDocumentService(
+		&mockContractDocumentRepository{documents: map[uint]*models.ContractDocument{
+			1: {ID: 1, ContractID: 4, LineageID: 1, DocumentType: models.DocumentTypeIDCopy},
+			2: {ID: 2, ContractID: 4, LineageID: 2, DocumentType: models.DocumentTypeDeed, DeletedAt: &deletedAt},
+			3: {ID: 3, ContractID: 5, LineageID: 3, DocumentType: models.DocumentTypeOther},
+		}},
+		&mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+			return contract, nil
+		}},
+		nil, nil)
+
+	buyer := DocumentActor{UserID: 7, Role: models.RoleUser}
+	coBuyer := DocumentActor{UserID: coBuyerID, Role: models.RoleUser}
+	stranger := DocumentActor{UserID: 9, Role: models.RoleUser}
+	admin := DocumentActor{UserID: 1, Role: models.RoleAdmin}
+
+	documents, err := svc.List(ctx, 4, coBuyer)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+
+	_, err = svc.List(ctx, 4, stranger)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = svc.Versions(ctx, 4, 3, admin) // belongs to another contract
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.find(ctx, 4, 2, buyer) // deleted documents are only visible to admins
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.find(ctx, 4, 2, admin)
+	assert.NoError(t, err)
+
+	_, err = svc.Upload(ctx, 4, models.DocumentTypeDeed, nil, buyer)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = svc.Upload(ctx, 4, "passport", nil, admin)
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+	_, err = svc.Upload(ctx, 4, models.DocumentTypeIDCopy, nil, buyer) // allowed, but no file
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+
+	assert.ErrorIs(t, svc.Delete(ctx, 4, 1, DocumentActor{UserID: 2, Role: models.RoleSeller}), ErrUnauthorized)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// TermsDiff is the change in financial terms between two versions of a contract
+type TermsDiff struct {
+	ContractID uint                                `json:"contract_id"`
+	From       models.ContractTermsVersionResponse `json:"from"`
+	To         models.ContractTermsVersionResponse `json:"to"`
+	Changes    []models.TermChange                 `json:"changes"`
+}
+
+// StatusHistory returns the status transitions of a contract, oldest first
+func (s *ContractService) StatusHistory(ctx context.Context, contractID uint) ([]models.ContractStatusTransition, error) {
+	return s.repo.FindStatusTransitions(ctx, contractID)
+}
+
+// TermsVersions returns the financial terms versions of a contract, oldest first
+func (s *ContractService) TermsVersions(ctx context.Context, contractID uint) ([]models.ContractTermsVersion, error) {
+	return s.repo.FindTermsVersions(ctx, contractID)
+}
+
+// DiffTermsVersions compares two terms versions of a contract. Without to, the latest version is
+// used; without from, the version before to.
+func (s *ContractService) DiffTermsVersions(ctx context.Context, contractID uint, from, to int) (*TermsDiff, error) {
+	versions, err := s.repo.FindTermsVersions(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	if to == 0 {
+		to = versions[len(versions)-1].Version
+	}
+	if from == 0 {
+		from = to - 1
+		if from < 1 {
+			from = 1
+		}
+	}
+
+	var fromVersion, toVersion *models.ContractTermsVersion
+	for i := range versions {
+		if versions[i].Version == from {
+			fromVersion = &versions[i]
+		}
+		if versions[i].Version == to {
+			toVersion = &versions[i]
+		}
+	}
+	if fromVersion == nil || toVersion == nil {
+		return nil, ErrNotFound
+	}
+	return &TermsDiff{
+		ContractID: contractID,
+		From:       fromVersion.ToResponse(),
+		To:         toVersion.ToResponse(),
+		Changes:    models.DiffTerms(fromVersion, toVersion),
+	}, nil
+}
+
+// recordTransition writes the status transition the contract FSM made, logging instead of failing
+// since the new status is already persisted
+func (s *ContractService) recordTransition(ctx context.Context, fsm *statemachine.ContractFSM, contractID uint, actorID *uint, reason string) {
+	if err := fsm.Record(ctx, s.repo, actorID, reason); err != nil {
+		logger.Error(fmt.Sprintf("[ContractService] Failed to record status transition of contract %d: %v", contractID, err))
+	}
+}
+
+// snapshotTerms stores the current financial terms of the contract as a new version, unless they
+// are the same as the latest version. Failures are logged: the change itself already happened.
+func (s *ContractService) snapshotTerms(ctx context.Context, contractID uint, source string, actorID *uint, reason string) {
+	if err := s.createTermsVersion(ctx, contractID, source, actorID, reason); err != nil {
+		logger.Error(fmt.Sprintf("[ContractService] Failed to version terms of contract %d: %v", contractID, err))
+	}
+}
+
+func (s *ContractService) createTermsVersion(ctx context.Context, contractID uint, source string, actorID *uint, reason string) error {
+	contract, err := s.repo.FindByID(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	version := contract.TermsSnapshot()
+	versions, err := s.repo.FindTermsVersions(ctx, contractID)
+	if err != nil {
+		return err
+	}
+	if n := len(versions); n > 0 && len(models.DiffTerms(&versions[n-1], &version)) == 0 {
+		return nil
+	}
+	version.Source = source
+	version.ActorID = actorID
+	if reason != "" {
+		version.Reason = &reason
+	}
+	return s.repo.CreateTermsVersion(ctx, &version)
+}
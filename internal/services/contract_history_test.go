@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transitionRecorder struct {
+	transitions []models.ContractStatusTransition
+}
+
+func (r *transitionRecorder) CreateStatusTransition(ctx context.Context, transition *models.ContractStatusTransition) error {
+	r.transitions = append(r.transitions, *transition)
+	return nil
+}
+
+func TestContractFSMRecord(t *testing.T) {
+	ctx := context.Background()
+	recorder := &transitionRecorder{}
+	contract := &models.Contract{ID: 9, Status: models.ContractStatusPending}
+	actorID := uint(3)
+
+	fsm := statemachine.NewContractFSM(contract)
+	require.NoError(t, fsm.Record(ctx, recorder, &actorID, "")) // nothing to record yet
+	require.Empty(t, recorder.transitions)
+
+	require.NoError(t, fsm.Reject(ctx))
+	require.NoError(t, fsm.Record(ctx, recorder, &actorID, "Documentos incompletos"))
+	require.Len(t, recorder.transitions, 1)
+	got := recorder.transitions[0]
+	assert.Equal(t, uint(9), got.ContractID)
+	assert.Equal(t, models.ContractStatusPending, got.FromStatus)
+	assert.Equal(t, models.ContractStatusRejected, got.ToStatus)
+	assert.Equal(t, "reject", got.Event)
+	assert.Equal(t, &actorID, got.ActorID)
+	require.NotNil(t, got.Reason)
+	assert.Equal(t, "Documentos incompletos", *got.Reason)
+}
+
+func TestDiffTerms(t *testing.T) {
+	amount, newAmount, reserve := 100000.0, 120000.0, 5000.0
+	date := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	sameDay := date.Add(6 * time.Hour)
+	v1 := &models.ContractTermsVersion{Version: 1, LotID: 1, PaymentTerm: 60, Amount: &amount, ReserveAmount: &reserve, MaxPaymentDate: &date}
+	v2 := *v1
+	v2.Version = 2
+	v2.MaxPaymentDate = &sameDay
+
+	assert.Empty(t, models.DiffTerms(v1, &v2)) // same calendar day
+
+	v2.LotID, v2.Amount, v2.PaymentTerm, v2.ReserveAmount = 2, &newAmount, 48, nil
+	changes := models.DiffTerms(v1, &v2)
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+	assert.Equal(t, []string{"lot_id", "payment_term", "amount", "reserve_amount"}, fields)
+	assert.Equal(t, &amount, changes[2].From)
+	assert.Equal(t, &newAmount, changes[2].To)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Contract lot change errors
+var (
+	ErrLotChangeSameLot      = errors.New("el contrato ya está asignado a ese lote")
+	ErrLotChangeOtherProject = errors.New("el nuevo lote debe pertenecer al mismo proyecto del contrato")
+	ErrLotChangeUnavailable  = errors.New("el nuevo lote no está disponible")
+	ErrLotChangeOverpaid     = errors.New("el cliente ya pagó más que el nuevo monto del contrato; registre primero la devolución")
+)
+
+// LotChangeRequest is the input for moving a contract to another lot
+type LotChangeRequest struct {
+	NewLotID uint   `json:"new_lot_id" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+// ChangeLot moves an approved contract to another available lot of the same project. The new lot
+// is reserved (and financed if the old one was), the price difference is posted to the ledger as
+// an adjustment and a line item, the pending schedule is resized to the new balance and the old
+// lot is released, which notifies its waitlist. A cheaper lot that leaves the customer with
+// more paid than the new amount is refused; one that exactly covers it settles the contract.
+func (s *ContractService) ChangeLot(ctx context.Context, id uint, req LotChangeRequest, actorID uint, ip, userAgent string) (*models.ContractLotChange, error) {
+	contract, err := s.repo.FindByIDWithDetails(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !contract.InForce() {
+		return nil, fmt.Errorf("%w: solo se puede cambiar el lote de contratos aprobados (estado actual: %s)", ErrInvalidState, contract.Status)
+	}
+	if req.NewLotID == contract.LotID {
+		return nil, ErrLotChangeSameLot
+	}
+	newLot, err := s.lotRepo.FindByID(ctx, req.NewLotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if newLot.ProjectID != contract.Lot.ProjectID {
+		return nil, ErrLotChangeOtherProject
+	}
+	if !newLot.IsAvailable() {
+		return nil, ErrLotChangeUnavailable
+	}
+	if err := s.checkLotOpenForSale(ctx, newLot, &actorID); err != nil {
+		return nil, err
+	}
+
+	oldAmount := 0.0
+	if contract.Amount != nil {
+		oldAmount = *contract.Amount
+	}
+	difference := math.Round((newLot.EffectivePrice()-contractLotPrice(contract))*100) / 100
+	newAmount := oldAmount + difference
+	if newAmount <= 0 {
+		return nil, fmt.Errorf("%w: el monto del contrato quedaría en cero o negativo", ErrInvalidState)
+	}
+
+	oldLot := contract.Lot
+	change := &models.ContractLotChange{
+		ContractID:      contract.ID,
+		FromLotID:       oldLot.ID,
+		ToLotID:         newLot.ID,
+		OldAmount:       oldAmount,
+		NewAmount:       newAmount,
+		PriceDifference: difference,
+		ActorID:         actorID,
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		change.Reason = &reason
+	}
+
+	// Commission follows the new amount under the same project rates
+	priced := *contract
+	priced.Amount = &newAmount
+	priced.Lot = *newLot
+	set := &repository.ContractLotChangeSet{
+		Change:     change,
+		Commission: priced.CalculateCommission(),
+		LineItem: &models.ContractLineItem{
+			ContractID:  contract.ID,
+			ItemType:    models.LineItemTypeLotChange,
+			Description: fmt.Sprintf("Cambio de lote %s a %s", oldLot.Name, newLot.Name),
+			Amount:      difference,
+		},
+	}
+	settled := false
+	if difference != 0 {
+		set.LedgerEntry = &models.ContractLedgerEntry{
+			ContractID:  contract.ID,
+			Amount:      -difference, // Negative when the new lot is more expensive: increases the debt
+			Description: fmt.Sprintf("Diferencia de precio por cambio de lote %s a %s", oldLot.Name, newLot.Name),
+			EntryType:   models.EntryTypeAdjustment,
+			EntryDate:   time.Now(),
+		}
+		resize := s.paymentSchedule.ResizeRemaining(contract, difference)
+		if resize.Excess > 0 {
+			return nil, fmt.Errorf("%w (excedente %.2f)", ErrLotChangeOverpaid, resize.Excess)
+		}
+		settled = len(resize.Dropped) > 0 && len(resize.Resized) == 0 && resize.Added == nil
+		set.ResizedPayments, set.DroppedPaymentIDs, set.AddedPayment = resize.Resized, resize.Dropped, resize.Added
+	}
+
+	// Reserve the new lot first so nobody else takes it while the contract moves
+	contractID := contract.ID
+	lotContext := LotEventContext{ContractID: &contractID, ActorID: &actorID, Reason: "Cambio de lote del contrato"}
+	if _, err := s.lotStatusSvc.Fire(ctx, newLot.ID, statemachine.LotEventReserve, lotContext); err != nil {
+		return nil, ErrLotChangeUnavailable
+	}
+	if oldLot.Status == models.LotStatusFinanced {
+		if _, err := s.lotStatusSvc.Fire(ctx, newLot.ID, statemachine.LotEventFinance, lotContext); err != nil {
+			logger.Error(fmt.Sprintf("[ContractService] Failed to finance lot %d (contract %d): %v", newLot.ID, contract.ID, err))
+		}
+	}
+	releaseNewLot := func() {
+		if _, err := s.lotStatusSvc.Fire(ctx, newLot.ID, statemachine.LotEventRelease, lotContext); err != nil {
+			logger.Error(fmt.Sprintf("[ContractService] Failed to release lot %d after failed lot change: %v", newLot.ID, err))
+		}
+	}
+
+	moved, err := s.lotChangeRepo.Apply(ctx, set)
+	if err != nil {
+		releaseNewLot()
+		return nil, fmt.Errorf("failed to change contract lot: %w", err)
+	}
+	if !moved {
+		releaseNewLot()
+		return nil, fmt.Errorf("%w: el contrato cambió mientras se procesaba el cambio de lote", ErrInvalidState)
+	}
+
+	// The old lot goes back on sale (and to its waitlist)
+	s.lotStatusSvc.FireForContract(ctx, contract, statemachine.LotEventRelease, &actorID,
+		fmt.Sprintf("Contrato #%d trasladado al lote %s", contract.ID, newLot.Name))
+
+	// Nothing left to pay: close the contract (refused while submitted payments keep a balance)
+	if settled {
+		if _, err := s.Close(ctx, contract.ID, &actorID); err != nil {
+			logger.Warn(fmt.Sprintf("[ContractService] Contract %d not closed after lot change: %v", contract.ID, err))
+		}
+	}
+
+	applicantID := contract.ApplicantUserID
+	msg := fmt.Sprintf("Tu contrato fue trasladado del lote %s al lote %s (%s).", oldLot.Name, newLot.Name, oldLot.Project.Name)
+	if difference != 0 {
+		msg += fmt.Sprintf(" Nuevo monto del contrato: %.2f; tus cuotas pendientes fueron ajustadas.", newAmount)
+	}
+	s.worker.EnqueueAsync(func(ctx context.Context) error {
+		return s.notificationSvc.NotifyUser(ctx, applicantID, "Cambio de lote", msg, models.NotificationTypeContractApproved)
+	})
+
+	details := fmt.Sprintf("Contrato #%d trasladado del lote %s (ID %d) al lote %s (ID %d). Monto: %.2f -> %.2f (diferencia %.2f)",
+		contract.ID, oldLot.Name, oldLot.ID, newLot.Name, newLot.ID, oldAmount, newAmount, difference)
+	if change.Reason != nil {
+		details += ". Motivo: " + *change.Reason
+	}
+	s.auditSvc.Log(ctx, actorID, "LOT_CHANGE", "Contract", contract.ID, details, ip, userAgent)
+	s.snapshotTerms(ctx, contract.ID, models.TermsSourceLotChange, &actorID, strings.TrimSpace(req.Reason))
+
+	change.FromLot, change.ToLot = oldLot, *newLot
+	return change, nil
+}
+
+// ListLotChanges returns the lot change history of a contract, oldest first
+func (s *ContractService) ListLotChanges(ctx context.Context, contractID uint) ([]models.ContractLotChange, error) {
+	return s.lotChangeRepo.FindByContract(ctx, contractID)
+}
+
+// contractLotPrice is the lot price the contract currently carries: its base price plus earlier
+// lot change differences. Contracts without a price breakdown fall back to their amount.
+func contractLotPrice(contract *models.Contract) float64 {
+	price, found := 0.0, false
+	for _, item := range contract.LineItems {
+		if item.ItemType == models.LineItemTypeBasePrice || item.ItemType == models.LineItemTypeLotChange {
+			price += item.Amount
+			found = true
+		}
+	}
+	if !found && contract.Amount != nil {
+		return *contract.Amount
+	}
+	return price
+}
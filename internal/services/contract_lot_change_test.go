@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentScheduleService_ResizeRemaining(t *testing.T) {
+	svc := NewPaymentScheduleService()
+	due := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	installment := func(id uint, amount float64, month int, status string) models.Payment {
+		return models.Payment{ID: id, Amount: amount, DueDate: due.AddDate(0, month, 0), Status: status, PaymentType: models.PaymentTypeInstallment}
+	}
+	contract := &models.Contract{Payments: []models.Payment{
+		{ID: 1, Amount: 5000, Status: models.PaymentStatusPaid, PaymentType: models.PaymentTypeDownPayment},
+		installment(2, 1000, 0, models.PaymentStatusPaid),
+		installment(3, 1000, 1, models.PaymentStatusSubmitted),
+		installment(5, 1000, 3, models.PaymentStatusPending),
+		installment(4, 1000, 2, models.PaymentStatusPending),
+		installment(6, 1000, 4, models.PaymentStatusPending),
+	}}
+
+	// More expensive lot: 3000 + 1001 over the three pending installments, first takes the remainder
+	resize := svc.ResizeRemaining(contract, 1001)
+	require.Len(t, resize.Resized, 3)
+	assert.Equal(t, uint(4), resize.Resized[0].ID)
+	assert.Equal(t, 1335.0, resize.Resized[0].Amount)
+	assert.Equal(t, 1333.0, resize.Resized[1].Amount)
+	assert.Equal(t, 1333.0, resize.Resized[2].Amount)
+	assert.Equal(t, due.AddDate(0, 2, 0), resize.Resized[0].DueDate)
+	assert.Empty(t, resize.Dropped)
+
+	// Cheaper lot covering exactly the remainder: every pending installment goes away
+	resize = svc.ResizeRemaining(contract, -3000)
+	assert.Empty(t, resize.Resized)
+	assert.ElementsMatch(t, []uint{4, 5, 6}, resize.Dropped)
+	assert.Zero(t, resize.Excess)
+
+	// Cheaper than what is left: the part already paid is reported instead of lost
+	resize = svc.ResizeRemaining(contract, -3500)
+	assert.Empty(t, resize.Resized)
+	assert.Equal(t, 500.0, resize.Excess)
+
+	// Remainder smaller than the number of installments: trailing ones are dropped
+	resize = svc.ResizeRemaining(contract, -2998.5)
+	require.Len(t, resize.Resized, 1)
+	assert.Equal(t, 1.5, resize.Resized[0].Amount)
+	assert.Equal(t, []uint{5, 6}, resize.Dropped)
+
+	// Bank/cash contracts adjust the pending full payment; without one a new payment is added
+	bank := &models.Contract{ID: 9, Payments: []models.Payment{
+		{ID: 7, Amount: 2000, Status: models.PaymentStatusPaid, PaymentType: models.PaymentTypeReservation},
+		{ID: 8, Amount: 98000, Status: models.PaymentStatusPending, PaymentType: models.PaymentTypeFull},
+	}}
+	resize = svc.ResizeRemaining(bank, 12000)
+	require.Len(t, resize.Resized, 1)
+	assert.Equal(t, 110000.0, resize.Resized[0].Amount)
+
+	bank.Payments[1].Status = models.PaymentStatusPaid
+	resize = svc.ResizeRemaining(bank, 12000)
+	require.NotNil(t, resize.Added)
+	assert.Equal(t, 12000.0, resize.Added.Amount)
+	assert.Equal(t, uint(9), resize.Added.ContractID)
+
+	resize = svc.ResizeRemaining(bank, -1500)
+	assert.Nil(t, resize.Added)
+	assert.Equal(t, 1500.0, resize.Excess)
+}
+
+func TestContractLotPrice(t *testing.T) {
+	amount := 90000.0
+	contract := &models.Contract{Amount: &amount}
+	assert.Equal(t, 90000.0, contractLotPrice(contract))
+
+	contract.LineItems = []models.ContractLineItem{
+		{ItemType: models.LineItemTypeBasePrice, Amount: 100000},
+		{ItemType: models.LineItemTypeDiscount, Amount: -10000},
+		{ItemType: models.LineItemTypeLotChange, Amount: 15000},
+	}
+	assert.Equal(t, 115000.0, contractLotPrice(contract))
+}
+
+func TestChangeLot_RefusesOverpaidContract(t *testing.T) {
+	amount, paid := 20000.0, 17000.0
+	contract := &models.Contract{
+		ID: 1, LotID: 2, Amount: &amount, Status: models.ContractStatusApproved,
+		Lot:       models.Lot{ID: 2, ProjectID: 3, Name: "Lote 2"},
+		LineItems: []models.ContractLineItem{{ItemType: models.LineItemTypeBasePrice, Amount: 20000}},
+		Payments: []models.Payment{
+			{ID: 1, Amount: 17000, PaidAmount: &paid, Status: models.PaymentStatusPaid, PaymentType: models.PaymentTypeDownPayment},
+			{ID: 2, Amount: 3000, Status: models.PaymentStatusPending, PaymentType: models.PaymentTypeInstallment},
+		},
+	}
+	lots := &mockLotStatusRepository{lot: models.Lot{
+		ID: 5, ProjectID: 3, Name: "Lote 5", Price: 16000, Status: models.LotStatusAvailable,
+		Project: models.Project{Status: models.ProjectStatusPublished},
+	}}
+	svc := &ContractService{
+		repo: &mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+			return contract, nil
+		}},
+		lotRepo:         lots,
+		paymentSchedule: NewPaymentScheduleService(),
+	}
+
+	// 17000 already paid for a lot that now costs 16000: nothing is moved
+	_, err := svc.ChangeLot(context.Background(), 1, LotChangeRequest{NewLotID: 5}, 1, "", "")
+	assert.ErrorIs(t, err, ErrLotChangeOverpaid)
+	assert.Equal(t, models.LotStatusAvailable, lots.lot.Status)
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Contract party errors
+var (
+	ErrPartiesLocked = errors.New("los participantes solo se pueden modificar mientras el contrato no esté aprobado")
+	ErrInvalidParty  = errors.New("participante del contrato inválido")
+)
+
+// ContractPartyRequest is the input for adding or updating a contract party. With user_id the
+// name, identity, phone and email default to the registered user's.
+type ContractPartyRequest struct {
+	UserID              *uint    `json:"user_id"`
+	Role                string   `json:"role"`
+	FullName            string   `json:"full_name"`
+	Identity            string   `json:"identity"`
+	Phone               string   `json:"phone"`
+	Email               string   `json:"email"`
+	OwnershipPercentage *float64 `json:"ownership_percentage"` // co-buyers only; omitted by all = equal split
+}
+
+// ContractPartyService manages the co-buyers, guarantors and legal representatives of a contract
+type ContractPartyService struct {
+	repo         repository.ContractPartyRepository
+	contractRepo repository.ContractRepository
+	userRepo     repository.UserRepository
+	auditSvc     *AuditService
+}
+
+func NewContractPartyService(repo repository.ContractPartyRepository, contractRepo repository.ContractRepository, userRepo repository.UserRepository, auditSvc *AuditService) *ContractPartyService {
+	return &ContractPartyService{
+		repo:         repo,
+		contractRepo: contractRepo,
+		userRepo:     userRepo,
+		auditSvc:     auditSvc,
+	}
+}
+
+// List returns the parties of a contract
+func (s *ContractPartyService) List(ctx context.Context, contractID uint) ([]models.ContractParty, error) {
+	if _, err := s.findContract(ctx, contractID); err != nil {
+		return nil, err
+	}
+	parties, err := s.repo.FindByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	return models.WithOwnership(parties), nil
+}
+
+// Add adds a party to a contract that is not approved yet
+func (s *ContractPartyService) Add(ctx context.Context, contractID uint, req ContractPartyRequest, actorID uint) (*models.ContractParty, error) {
+	contract, parties, err := s.editableContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	party := models.ContractParty{ContractID: contract.ID}
+	applyPartyRequest(&party, req)
+	parties = append(parties, party)
+	if err := resolveContractParties(ctx, s.userRepo, contract.ApplicantUserID, parties); err != nil {
+		return nil, err
+	}
+	party = parties[len(parties)-1]
+
+	if err := s.repo.Create(ctx, &party); err != nil {
+		return nil, fmt.Errorf("failed to create contract party: %w", err)
+	}
+	parties[len(parties)-1].ID = party.ID
+	party = models.WithOwnership(parties)[len(parties)-1]
+	s.audit(ctx, actorID, "CREATE", party.ID, fmt.Sprintf("%s %s agregado al contrato #%d%s", party.RoleLabel(), party.FullName, contract.ID, ownershipDetail(&party)))
+	return &party, nil
+}
+
+// Update changes a party of a contract that is not approved yet
+func (s *ContractPartyService) Update(ctx context.Context, contractID, partyID uint, req ContractPartyRequest, actorID uint) (*models.ContractParty, error) {
+	contract, parties, err := s.editableContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	idx := partyIndex(parties, partyID)
+	if idx < 0 {
+		return nil, ErrNotFound
+	}
+
+	applyPartyRequest(&parties[idx], req)
+	if err := resolveContractParties(ctx, s.userRepo, contract.ApplicantUserID, parties); err != nil {
+		return nil, err
+	}
+	party := parties[idx]
+
+	if err := s.repo.Update(ctx, &party); err != nil {
+		return nil, fmt.Errorf("failed to update contract party: %w", err)
+	}
+	party = models.WithOwnership(parties)[idx]
+	s.audit(ctx, actorID, "UPDATE", party.ID, fmt.Sprintf("%s %s del contrato #%d actualizado%s", party.RoleLabel(), party.FullName, contract.ID, ownershipDetail(&party)))
+	return &party, nil
+}
+
+// Remove removes a party from a contract that is not approved yet; the share of a removed
+// co-buyer goes back to the applicant
+func (s *ContractPartyService) Remove(ctx context.Context, contractID, partyID uint, actorID uint) error {
+	contract, parties, err := s.editableContract(ctx, contractID)
+	if err != nil {
+		return err
+	}
+	idx := partyIndex(parties, partyID)
+	if idx < 0 {
+		return ErrNotFound
+	}
+	party := parties[idx]
+
+	if err := s.repo.Delete(ctx, party.ID); err != nil {
+		return fmt.Errorf("failed to delete contract party: %w", err)
+	}
+	s.audit(ctx, actorID, "DELETE", party.ID, fmt.Sprintf("%s %s eliminado del contrato #%d", party.RoleLabel(), party.FullName, contract.ID))
+	return nil
+}
+
+// editableContract loads a contract whose parties may still change, with its current parties
+func (s *ContractPartyService) editableContract(ctx context.Context, contractID uint) (*models.Contract, []models.ContractParty, error) {
+	contract, err := s.findContract(ctx, contractID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !contract.MayChangeParties() {
+		return nil, nil, ErrPartiesLocked
+	}
+	parties, err := s.repo.FindByContract(ctx, contract.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contract, parties, nil
+}
+
+func (s *ContractPartyService) findContract(ctx context.Context, contractID uint) (*models.Contract, error) {
+	contract, err := s.contractRepo.FindByID(ctx, contractID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return contract, err
+}
+
+func (s *ContractPartyService) audit(ctx context.Context, userID uint, action string, id uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "ContractParty", id, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ContractPartyService] Failed to audit %s for party %d: %v", action, id, err))
+	}
+}
+
+func applyPartyRequest(party *models.ContractParty, req ContractPartyRequest) {
+	if req.UserID != nil {
+		party.UserID = req.UserID
+		if *req.UserID == 0 {
+			party.UserID = nil
+		}
+		party.User = nil
+	}
+	if role := strings.TrimSpace(strings.ToLower(req.Role)); role != "" {
+		party.Role = role
+		if role != models.PartyRoleCoBuyer {
+			party.OwnershipPercentage = nil
+		}
+	}
+	if req.FullName != "" {
+		party.FullName = req.FullName
+	}
+	if req.Identity != "" {
+		party.Identity = strings.TrimSpace(req.Identity)
+	}
+	if req.Phone != "" {
+		party.Phone = strings.TrimSpace(req.Phone)
+	}
+	if req.Email != "" {
+		party.Email = strings.TrimSpace(req.Email)
+	}
+	if req.OwnershipPercentage != nil {
+		party.OwnershipPercentage = req.OwnershipPercentage
+	}
+}
+
+// resolveContractParties fills the data of parties linked to a registered user and validates
+// the parties of a contract (roles, names and co-buyer ownership)
+func resolveContractParties(ctx context.Context, userRepo repository.UserRepository, applicantID uint, parties []models.ContractParty) error {
+	for i := range parties {
+		p := &parties[i]
+		if p.UserID == nil {
+			continue
+		}
+		if *p.UserID == applicantID {
+			return fmt.Errorf("%w: el solicitante no puede ser también participante", ErrInvalidParty)
+		}
+		if p.User == nil {
+			user, err := userRepo.FindByID(ctx, *p.UserID)
+			if err != nil {
+				return fmt.Errorf("%w: usuario no encontrado", ErrInvalidParty)
+			}
+			p.User = user
+		}
+		if strings.TrimSpace(p.FullName) == "" {
+			p.FullName = p.User.FullName
+		}
+		if p.Identity == "" {
+			p.Identity = p.User.Identity
+		}
+		if p.Phone == "" {
+			p.Phone = p.User.Phone
+		}
+	}
+	if err := models.ValidateContractParties(parties); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidParty, err)
+	}
+	return nil
+}
+
+func partyIndex(parties []models.ContractParty, id uint) int {
+	for i := range parties {
+		if parties[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func ownershipDetail(party *models.ContractParty) string {
+	if party.OwnershipPercentage == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%.2f%% de propiedad)", *party.OwnershipPercentage)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPartyRepository struct {
+	repository.ContractPartyRepository
+	parties []models.ContractParty
+}
+
+func (m *mockPartyRepository) FindByUser(ctx context.Context, userID uint) ([]models.ContractParty, error) {
+	var parties []models.ContractParty
+	for _, p := range m.parties {
+		if p.UserID != nil && *p.UserID == userID {
+			parties = append(parties, p)
+		}
+	}
+	return parties, nil
+}
+
+type mockCreditContractRepository struct {
+	repository.ContractRepository
+	contracts []models.Contract
+}
+
+func (m *mockCreditContractRepository) FindByUser(ctx context.Context, userID uint) ([]models.Contract, error) {
+	var contracts []models.Contract
+	for _, c := range m.contracts {
+		if c.ApplicantUserID == userID {
+			contracts = append(contracts, c)
+		}
+	}
+	return contracts, nil
+}
+
+func (m *mockCreditContractRepository) FindByID(ctx context.Context, id uint) (*models.Contract, error) {
+	for i := range m.contracts {
+		if m.contracts[i].ID == id {
+			return &m.contracts[i], nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+type mockCreditPaymentRepository struct {
+	repository.PaymentRepository
+	payments map[uint][]models.Payment
+}
+
+func (m *mockCreditPaymentRepository) FindByContract(ctx context.Context, contractID uint) ([]models.Payment, error) {
+	return m.payments[contractID], nil
+}
+
+func TestValidateContractParties(t *testing.T) {
+	pct := func(v float64) *float64 { return &v }
+
+	// Without percentages the lot is split equally; the applicant keeps the rounding remainder
+	parties := []models.ContractParty{
+		{Role: models.PartyRoleCoBuyer, FullName: "Ana"},
+		{Role: models.PartyRoleCoBuyer, FullName: "Luis"},
+		{Role: models.PartyRoleGuarantor, FullName: "Marta"},
+	}
+	require.NoError(t, models.ValidateContractParties(parties))
+	assert.Nil(t, parties[0].OwnershipPercentage) // implicit shares are not stored
+	resolved := models.WithOwnership(parties)
+	assert.Equal(t, 33.33, *resolved[0].OwnershipPercentage)
+	assert.Equal(t, 33.33, *resolved[1].OwnershipPercentage)
+	assert.Nil(t, resolved[2].OwnershipPercentage)
+	contract := models.Contract{Parties: parties}
+	assert.Equal(t, 33.34, contract.ApplicantOwnershipPercentage())
+
+	assert.Error(t, models.ValidateContractParties([]models.ContractParty{
+		{Role: models.PartyRoleCoBuyer, FullName: "Ana", OwnershipPercentage: pct(60)},
+		{Role: models.PartyRoleCoBuyer, FullName: "Luis", OwnershipPercentage: pct(40)},
+	}), "co-buyers must leave a share to the applicant")
+	assert.Error(t, models.ValidateContractParties([]models.ContractParty{
+		{Role: models.PartyRoleCoBuyer, FullName: "Ana", OwnershipPercentage: pct(30)},
+		{Role: models.PartyRoleCoBuyer, FullName: "Luis"},
+	}), "all co-buyers need a percentage once one has it")
+	assert.Error(t, models.ValidateContractParties([]models.ContractParty{
+		{Role: models.PartyRoleGuarantor, FullName: "Marta", OwnershipPercentage: pct(10)},
+	}), "guarantors do not own the lot")
+	assert.Error(t, models.ValidateContractParties([]models.ContractParty{{Role: "witness", FullName: "Pedro"}}))
+}
+
+func (m *mockPartyRepository) FindByContract(ctx context.Context, contractID uint) ([]models.ContractParty, error) {
+	var parties []models.ContractParty
+	for _, p := range m.parties {
+		if p.ContractID == contractID {
+			parties = append(parties, p)
+		}
+	}
+	return parties, nil
+}
+
+func (m *mockPartyRepository) Create(ctx context.Context, party *models.ContractParty) error {
+	party.ID = uint(len(m.parties) + 1)
+	m.parties = append(m.parties, *party)
+	return nil
+}
+
+func TestContractPartyService_AddCoBuyersWithoutPercentages(t *testing.T) {
+	parties := &mockPartyRepository{}
+	contracts := &mockCreditContractRepository{contracts: []models.Contract{
+		{ID: 1, ApplicantUserID: 10, Status: models.ContractStatusPending},
+	}}
+	svc := NewContractPartyService(parties, contracts, nil, nil)
+	ctx := context.Background()
+
+	first, err := svc.Add(ctx, 1, ContractPartyRequest{Role: models.PartyRoleCoBuyer, FullName: "Ana"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, *first.OwnershipPercentage)
+
+	// The second co-buyer re-splits the lot instead of failing on the first one's stored share
+	second, err := svc.Add(ctx, 1, ContractPartyRequest{Role: models.PartyRoleCoBuyer, FullName: "Luis"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 33.33, *second.OwnershipPercentage)
+	assert.Nil(t, parties.parties[0].OwnershipPercentage)
+	assert.Nil(t, parties.parties[1].OwnershipPercentage)
+
+	listed, err := svc.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, 33.33, *listed[0].OwnershipPercentage)
+	contract := models.Contract{Parties: parties.parties}
+	assert.Equal(t, 33.34, contract.ApplicantOwnershipPercentage())
+}
+
+func TestCreditScore_ContractParties(t *testing.T) {
+	u := func(v uint) *uint { return &v }
+	due := time.Now().AddDate(0, -2, 0)
+	onTime, late := due, due.AddDate(0, 0, 10)
+
+	contracts := &mockCreditContractRepository{contracts: []models.Contract{
+		{ID: 1, ApplicantUserID: 10, Status: models.ContractStatusClosed},
+		{ID: 2, ApplicantUserID: 11, Status: models.ContractStatusApproved},
+		{ID: 3, ApplicantUserID: 12, Status: models.ContractStatusApproved},
+	}}
+	payments := &mockCreditPaymentRepository{payments: map[uint][]models.Payment{
+		1: {{Status: models.PaymentStatusPaid, DueDate: due, PaymentDate: &onTime}},
+		2: {{Status: models.PaymentStatusPaid, DueDate: due, PaymentDate: &late}},
+		3: {{Status: models.PaymentStatusPaid, DueDate: due, PaymentDate: &late}},
+	}}
+	parties := &mockPartyRepository{parties: []models.ContractParty{
+		{ContractID: 1, UserID: u(20), Role: models.PartyRoleCoBuyer},
+		{ContractID: 1, UserID: u(21), Role: models.PartyRoleGuarantor},
+		{ContractID: 2, UserID: u(21), Role: models.PartyRoleGuarantor},
+		{ContractID: 3, UserID: u(22), Role: models.PartyRoleLegalRepresentative},
+	}}
+	svc := NewCreditScoreService(nil, contracts, payments, parties)
+	ctx := context.Background()
+
+	// Co-buyer: on-time payment and closed contract count fully
+	assert.Equal(t, 555, svc.calculateCreditScore(ctx, 20))
+	// Guarantor: only the late payment of contract 2 counts
+	assert.Equal(t, 495, svc.calculateCreditScore(ctx, 21))
+	// Legal representative: the contract is not theirs
+	assert.Equal(t, 500, svc.calculateCreditScore(ctx, 22))
+}
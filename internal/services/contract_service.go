@@ -22,8 +22,16 @@ type ContractService struct {
 	notificationSvc *NotificationService
 	emailSvc        *EmailService
 	auditSvc        *AuditService
+	promotionSvc    *PromotionService
+	priceListSvc    *PriceListService
+	lotStatusSvc    *LotStatusService
+	lotHoldSvc      *LotHoldService
 	worker          *jobs.Worker
 	paymentSchedule *PaymentScheduleService
+	cessionRepo     repository.ContractCessionRepository
+	lotChangeRepo   repository.ContractLotChangeRepository
+	approvalSvc     *ApprovalChainService
+	kycSvc          *KYCService
 }
 
 func NewContractService(
@@ -35,6 +43,14 @@ func NewContractService(
 	notificationSvc *NotificationService,
 	emailSvc *EmailService,
 	auditSvc *AuditService,
+	promotionSvc *PromotionService,
+	priceListSvc *PriceListService,
+	lotStatusSvc *LotStatusService,
+	lotHoldSvc *LotHoldService,
+	cessionRepo repository.ContractCessionRepository,
+	lotChangeRepo repository.ContractLotChangeRepository,
+	approvalSvc *ApprovalChainService,
+	kycSvc *KYCService,
 	worker *jobs.Worker,
 ) *ContractService {
 	return &ContractService{
@@ -46,8 +62,16 @@ func NewContractService(
 		notificationSvc: notificationSvc,
 		emailSvc:        emailSvc,
 		auditSvc:        auditSvc,
+		promotionSvc:    promotionSvc,
+		priceListSvc:    priceListSvc,
+		lotStatusSvc:    lotStatusSvc,
+		lotHoldSvc:      lotHoldSvc,
 		worker:          worker,
 		paymentSchedule: NewPaymentScheduleService(),
+		cessionRepo:     cessionRepo,
+		lotChangeRepo:   lotChangeRepo,
+		approvalSvc:     approvalSvc,
+		kycSvc:          kycSvc,
 	}
 }
 
@@ -86,11 +110,46 @@ func (s *ContractService) Create(ctx context.Context, contract *models.Contract)
 		contract.GUID = uuid.New().String()
 	}
 
-	if !lot.IsAvailable() {
+	// Sellers can only sell lots of published projects, in phases/blocks released for sale
+	if err := s.checkLotOpenForSale(ctx, lot, contract.CreatorID); err != nil {
+		return err
+	}
+
+	// Co-buyers, guarantors and legal representatives sent with the request
+	if err := resolveContractParties(ctx, s.userRepo, contract.ApplicantUserID, contract.Parties); err != nil {
+		return err
+	}
+	for i := range contract.Parties {
+		contract.Parties[i].User = nil
+	}
+
+	// A held lot converts into its seller's contract; other sellers cannot take it
+	hold, err := s.lotHoldSvc.ClaimForContract(ctx, lot, contract.CreatorID)
+	if err != nil {
+		return err
+	}
+	if hold == nil && !lot.IsAvailable() {
 		return errors.New("el lote no está disponible")
 	}
+	restoreHold := func() {
+		if hold != nil {
+			s.lotHoldSvc.RestoreClaim(ctx, hold)
+		}
+	}
+
+	// Record the price list version the lot is sold under
+	if current := s.priceListSvc.CurrentForProject(ctx, lot.ProjectID); current != nil {
+		contract.PriceListID = &current.ID
+	}
 
-	// Calculate Commission Amount
+	// Price the contract: base price (Amount or Lot EffectivePrice) minus eligible promotions.
+	// The breakdown is stored as contract line items.
+	if err := s.promotionSvc.ApplyToContract(ctx, contract, lot); err != nil {
+		restoreHold()
+		return err
+	}
+
+	// Calculate Commission Amount (on the discounted amount)
 	if contract.CommissionAmount == 0 && contract.Amount != nil {
 		// Ensure temporary link to lot/project for calculation is set if not already
 		if contract.Lot.ID == 0 {
@@ -99,27 +158,36 @@ func (s *ContractService) Create(ctx context.Context, contract *models.Contract)
 		contract.CommissionAmount = contract.CalculateCommission()
 	}
 
-	// Create contract
-	// Calculate balance: Amount - Reserve - DownPayment
-	// Assuming contract.Amount is already set or derived from lot price
-	// If not set, use Lot EffectivePrice
-	if contract.Amount == nil || *contract.Amount == 0 {
-		price := lot.EffectivePrice()
-		contract.Amount = &price
-	}
-
 	// Initial balance is the full amount (as debt)
 	// It will be reduced as payments (including reserve and down payment) are approved
 	balance := -(*contract.Amount)
 	contract.Balance = &balance
 
 	if err := s.repo.Create(ctx, contract); err != nil {
+		s.promotionSvc.ReleaseUses(ctx, contract.LineItems)
+		restoreHold()
 		return err
 	}
 
-	// Update lot status to reserved
-	lot.Status = models.LotStatusReserved
-	s.lotRepo.Update(ctx, lot)
+	// Reserve the lot (compare-and-set on its status): if another contract reserved it meanwhile,
+	// roll this one back
+	contractID := contract.ID
+	reserveEvent := statemachine.LotEventReserve
+	if hold != nil {
+		reserveEvent = statemachine.LotEventConvertHold
+	}
+	reserved, err := s.lotStatusSvc.Fire(ctx, lot.ID, reserveEvent, LotEventContext{ContractID: &contractID, ActorID: contract.CreatorID, Reason: "Contrato creado"})
+	if err != nil {
+		_ = s.repo.Delete(ctx, contract.ID)
+		s.promotionSvc.ReleaseUses(ctx, contract.LineItems)
+		restoreHold()
+		return errors.New("el lote no está disponible")
+	}
+	lot.Status = reserved.Status
+	s.snapshotTerms(ctx, contract.ID, models.TermsSourceCreate, contract.CreatorID, "")
+	if hold != nil {
+		s.lotHoldSvc.LinkContract(ctx, hold, contract.ID)
+	}
 
 	// Retrieve applicant for notification
 	applicant, err := s.userRepo.FindByID(ctx, contract.ApplicantUserID)
@@ -149,14 +217,55 @@ func (s *ContractService) Create(ctx context.Context, contract *models.Contract)
 	})
 
 	// Audit log
-	s.auditSvc.Log(ctx, contract.ApplicantUserID, "CREATE", "Contract", contract.ID,
-		fmt.Sprintf("Solicitud de contrato creada para el lote %s del proyecto %s. Precio: %.2f", lot.Name, lot.Project.Name, *contract.Amount), "", "")
+	details := fmt.Sprintf("Solicitud de contrato creada para el lote %s del proyecto %s. Precio: %.2f", lot.Name, lot.Project.Name, *contract.Amount)
+	for _, item := range contract.LineItems {
+		if item.ItemType == models.LineItemTypeDiscount {
+			details += fmt.Sprintf(". Promoción %s: %.2f", item.Description, item.Amount)
+		}
+	}
+	for _, party := range contract.Parties {
+		details += fmt.Sprintf(". %s: %s%s", party.RoleLabel(), party.FullName, ownershipDetail(&party))
+	}
+	s.auditSvc.Log(ctx, contract.ApplicantUserID, "CREATE", "Contract", contract.ID, details, "", "")
 
 	return nil
 }
 
-func (s *ContractService) Update(ctx context.Context, contract *models.Contract) error {
-	return s.repo.Update(ctx, contract)
+// Update saves the contract and versions its financial terms when they changed
+func (s *ContractService) Update(ctx context.Context, contract *models.Contract, actorID uint) error {
+	if err := s.repo.Update(ctx, contract); err != nil {
+		return err
+	}
+	s.snapshotTerms(ctx, contract.ID, models.TermsSourceUpdate, &actorID, "")
+	return nil
+}
+
+// CheckLotOpenForSale fails when the creator cannot sell the lot: sellers only on published
+// projects, and nobody on a phase or block not on sale. Lets handlers refuse before doing work.
+func (s *ContractService) CheckLotOpenForSale(ctx context.Context, lotID uint, creatorID *uint) error {
+	lot, err := s.lotRepo.FindByID(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	return s.checkLotOpenForSale(ctx, lot, creatorID)
+}
+
+func (s *ContractService) checkLotOpenForSale(ctx context.Context, lot *models.Lot, creatorID *uint) error {
+	if !lot.Project.IsPublished() {
+		isAdmin := false
+		if creatorID != nil {
+			if creator, err := s.userRepo.FindByID(ctx, *creatorID); err == nil {
+				isAdmin = creator.Role == models.RoleAdmin
+			}
+		}
+		if !isAdmin {
+			return ErrProjectNotPublished
+		}
+	}
+	if !lot.IsOnSale() {
+		return ErrLotNotOnSale
+	}
+	return nil
 }
 
 func (s *ContractService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
@@ -188,13 +297,22 @@ func (s *ContractService) CreateUser(ctx context.Context, user *models.User, pas
 	return nil
 }
 
-// Approve approves a contract
-func (s *ContractService) Approve(ctx context.Context, id uint) (*models.Contract, error) {
+// Approve approves a contract; actorID is nil when the system approves
+func (s *ContractService) Approve(ctx context.Context, id uint, actorID *uint) (*models.Contract, error) {
 	contract, err := s.repo.FindByIDWithDetails(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Contracts under an approval chain are approved by its last step
+	if err := s.checkApprovalChain(ctx, contract); err != nil {
+		return nil, err
+	}
+	// The KYC checklist must be complete unless an admin overrode it
+	if err := s.checkKYC(ctx, contract); err != nil {
+		return nil, err
+	}
+
 	// Use FSM to validate and transition state
 	fsm := statemachine.NewContractFSM(contract)
 	if err := fsm.Approve(ctx); err != nil {
@@ -210,6 +328,7 @@ func (s *ContractService) Approve(ctx context.Context, id uint) (*models.Contrac
 	if err := s.repo.Update(ctx, contract); err != nil {
 		return nil, err
 	}
+	s.recordTransition(ctx, fsm, contract.ID, actorID, "")
 
 	// Create initial ledger entry (contract amount as debit)
 	if contract.Amount != nil {
@@ -238,11 +357,10 @@ func (s *ContractService) Approve(ctx context.Context, id uint) (*models.Contrac
 		}
 	}
 
-	// Update lot status to reserved (it moves to financed when first payment is made)
-	lot, _ := s.lotRepo.FindByID(ctx, contract.LotID)
-	if lot != nil {
-		lot.Status = models.LotStatusReserved
-		s.lotRepo.Update(ctx, lot)
+	// The lot stays reserved (it moves to financed when the first payment is approved); lots of
+	// contracts created before the lot FSM may still be available
+	if contract.Lot.Status != models.LotStatusReserved {
+		s.lotStatusSvc.FireForContract(ctx, contract, statemachine.LotEventReserve, nil, "Contrato aprobado")
 	}
 
 	// Find the "main" payment for the email highlight (Installment or Full Balance)
@@ -302,7 +420,7 @@ func (s *ContractService) Approve(ctx context.Context, id uint) (*models.Contrac
 	return contract, nil
 }
 
-func (s *ContractService) Reject(ctx context.Context, id uint, reason string) (*models.Contract, error) {
+func (s *ContractService) Reject(ctx context.Context, id uint, reason string, actorID *uint) (*models.Contract, error) {
 	contract, err := s.repo.FindByIDWithDetails(ctx, id)
 	if err != nil {
 		return nil, err
@@ -319,13 +437,10 @@ func (s *ContractService) Reject(ctx context.Context, id uint, reason string) (*
 	if err := s.repo.Update(ctx, contract); err != nil {
 		return nil, err
 	}
+	s.recordTransition(ctx, fsm, contract.ID, actorID, reason)
 
 	// Release lot
-	lot, _ := s.lotRepo.FindByID(ctx, contract.LotID)
-	if lot != nil {
-		lot.Status = models.LotStatusAvailable
-		s.lotRepo.Update(ctx, lot)
-	}
+	s.lotStatusSvc.FireForContract(ctx, contract, statemachine.LotEventRelease, nil, "Contrato rechazado: "+reason)
 
 	// Notify contract owner (in-app + email) with rejection reason
 	notifyTitle := "Contrato rechazado"
@@ -349,7 +464,7 @@ func (s *ContractService) Reject(ctx context.Context, id uint, reason string) (*
 	return contract, nil
 }
 
-// DeleteRejected deletes a rejected contract and releases the lot so it can be reserved again. Only allowed when status is rejected.
+// DeleteRejected deletes a rejected contract. Only allowed when status is rejected; its lot was released on rejection.
 func (s *ContractService) DeleteRejected(ctx context.Context, id uint) error {
 	contract, err := s.repo.FindByIDWithDetails(ctx, id)
 	if err != nil {
@@ -368,19 +483,14 @@ func (s *ContractService) DeleteRejected(ctx context.Context, id uint) error {
 		return fmt.Errorf("failed to delete contract: %w", err)
 	}
 
-	// Release lot so it can be reserved again
-	lot, _ := s.lotRepo.FindByID(ctx, contract.LotID)
-	if lot != nil {
-		lot.Status = models.LotStatusAvailable
-		s.lotRepo.Update(ctx, lot)
-	}
+	// The lot was released when the contract was rejected; it may be reserved by another contract now
+	s.promotionSvc.ReleaseUses(ctx, contract.LineItems)
 
-	s.auditSvc.Log(ctx, contract.ApplicantUserID, "DELETE", "Contract", contract.ID,
-		fmt.Sprintf("Contrato rechazado eliminado. Lote %d liberado para nueva reserva", contract.LotID), "", "")
+	s.auditSvc.Log(ctx, contract.ApplicantUserID, "DELETE", "Contract", contract.ID, "Contrato rechazado eliminado", "", "")
 	return nil
 }
 
-func (s *ContractService) Cancel(ctx context.Context, id uint, note string) (*models.Contract, error) {
+func (s *ContractService) Cancel(ctx context.Context, id uint, note string, actorID *uint) (*models.Contract, error) {
 	contract, err := s.repo.FindByIDWithDetails(ctx, id)
 	if err != nil {
 		return nil, err
@@ -398,6 +508,7 @@ func (s *ContractService) Cancel(ctx context.Context, id uint, note string) (*mo
 	if err := s.repo.Update(ctx, contract); err != nil {
 		return nil, err
 	}
+	s.recordTransition(ctx, fsm, contract.ID, actorID, note)
 
 	// Delete pending/submitted payments
 	for _, payment := range contract.Payments {
@@ -410,11 +521,10 @@ func (s *ContractService) Cancel(ctx context.Context, id uint, note string) (*mo
 	s.ledgerRepo.DeleteByContractID(ctx, contract.ID)
 
 	// Release lot
-	lot, _ := s.lotRepo.FindByID(ctx, contract.LotID)
-	if lot != nil {
-		lot.Status = models.LotStatusAvailable
-		s.lotRepo.Update(ctx, lot)
-	}
+	s.lotStatusSvc.FireForContract(ctx, contract, statemachine.LotEventRelease, nil, "Contrato cancelado: "+note)
+
+	// Give back the promotion uses consumed when the contract was created
+	s.promotionSvc.ReleaseUses(ctx, contract.LineItems)
 
 	// Audit log
 	s.auditSvc.Log(ctx, contract.ApplicantUserID, "CANCEL", "Contract", contract.ID,
@@ -423,8 +533,8 @@ func (s *ContractService) Cancel(ctx context.Context, id uint, note string) (*mo
 	return contract, nil
 }
 
-// Close closes a contract when balance is paid off
-func (s *ContractService) Close(ctx context.Context, id uint) (*models.Contract, error) {
+// Close closes a contract when balance is paid off; actorID is nil when closed automatically
+func (s *ContractService) Close(ctx context.Context, id uint, actorID *uint) (*models.Contract, error) {
 	contract, err := s.repo.FindByIDWithDetails(ctx, id)
 	if err != nil {
 		return nil, err
@@ -453,13 +563,10 @@ func (s *ContractService) Close(ctx context.Context, id uint) (*models.Contract,
 	if err := s.repo.Update(ctx, contract); err != nil {
 		return nil, err
 	}
+	s.recordTransition(ctx, fsm, contract.ID, actorID, "")
 
-	// Update lot status to fully paid
-	lot, _ := s.lotRepo.FindByID(ctx, contract.LotID)
-	if lot != nil {
-		lot.Status = models.LotStatusFullyPaid
-		s.lotRepo.Update(ctx, lot)
-	}
+	// Lot is fully paid
+	s.lotStatusSvc.FireForContract(ctx, contract, statemachine.LotEventPayOff, nil, "Contrato cerrado")
 
 	// Notify user
 	s.worker.EnqueueAsync(func(ctx context.Context) error {
@@ -490,7 +597,7 @@ func (s *ContractService) CapitalRepayment(ctx context.Context, id uint, amount
 	}
 
 	// Check contract is approved
-	if contract.Status != models.ContractStatusApproved {
+	if !contract.InForce() {
 		return fmt.Errorf("can only apply capital repayment to approved contracts")
 	}
 
@@ -586,7 +693,7 @@ func (s *ContractService) CapitalRepayment(ctx context.Context, id uint, amount
 
 			// Auto-close if balance >= 0
 			if balance >= 0 {
-				s.Close(ctx, contract.ID)
+				s.Close(ctx, contract.ID, nil)
 			}
 		}
 		return nil
@@ -608,7 +715,7 @@ func (s *ContractService) ReleaseUnpaidReservations(ctx context.Context) error {
 	}
 
 	for _, contract := range contracts {
-		s.Cancel(ctx, contract.ID, "Reservación no pagada a tiempo")
+		s.Cancel(ctx, contract.ID, "Reservación no pagada a tiempo", nil)
 	}
 
 	return nil
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunAuditService returns an audit service whose writes are built but never sent to a database
+func dryRunAuditService(t *testing.T) *AuditService {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return NewAuditService(db)
+}
+
+func TestDeleteRejected_KeepsLotOfAnotherContract(t *testing.T) {
+	rejected, current := uint(7), uint(8)
+	lots := &mockLotStatusRepository{lot: models.Lot{ID: 3, Status: models.LotStatusAvailable}}
+	lotStatusSvc := NewLotStatusService(lots)
+	ctx := context.Background()
+	_, err := lotStatusSvc.Fire(ctx, 3, statemachine.LotEventReserve, LotEventContext{ContractID: &rejected})
+	require.NoError(t, err)
+	_, err = lotStatusSvc.Fire(ctx, 3, statemachine.LotEventRelease, LotEventContext{ContractID: &rejected})
+	require.NoError(t, err)
+	_, err = lotStatusSvc.Fire(ctx, 3, statemachine.LotEventReserve, LotEventContext{ContractID: &current})
+	require.NoError(t, err)
+
+	svc := &ContractService{
+		repo: &mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+			return &models.Contract{ID: rejected, LotID: 3, Status: models.ContractStatusRejected}, nil
+		}},
+		paymentRepo:  &mockPaymentRepository{},
+		ledgerRepo:   &mockLedgerRepository{},
+		auditSvc:     dryRunAuditService(t),
+		lotStatusSvc: lotStatusSvc,
+	}
+
+	require.NoError(t, svc.DeleteRejected(ctx, rejected))
+	assert.Equal(t, models.LotStatusReserved, lots.lot.Status)
+	assert.Len(t, lots.history, 3)
+}
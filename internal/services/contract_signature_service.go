@@ -0,0 +1,618 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Contract signature errors
+var (
+	ErrInvalidSignature = errors.New("firma inválida")
+	ErrInvalidOTP       = errors.New("código de firma inválido o vencido")
+)
+
+const (
+	signatureOTPMinutes     = 10
+	signatureOTPMaxAttempts = 5
+	signatureMaxImageSize   = 1 << 20 // 1 MB
+)
+
+var pngMagic = []byte("\x89PNG\r\n\x1a\n")
+
+// SignContractRequest is a drawn signature (PNG, as a data URL or plain base64) with the one-time
+// code sent to the signer
+type SignContractRequest struct {
+	OTP       string `json:"otp" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// SignatureCheck is one verification of a signed promise contract
+type SignatureCheck struct {
+	Name  string `json:"name"`
+	Valid bool   `json:"valid"`
+}
+
+// SignatureVerification is the result of re-computing the hash chain of a signed promise contract
+// and re-hashing its stored files
+type SignatureVerification struct {
+	RequestID uint             `json:"request_id"`
+	Valid     bool             `json:"valid"`
+	ChainHash *string          `json:"chain_hash"`
+	Checks    []SignatureCheck `json:"checks"`
+}
+
+// ContractSignatureService runs the electronic signature of promise contracts: the buyer and an
+// admin draw their signature and confirm it with a code sent by email; once both signed, the signed
+// PDF is stored as the contract's signed_promise document and the contract moves to signed.
+type ContractSignatureService struct {
+	repo         repository.ContractSignatureRepository
+	contractRepo repository.ContractRepository
+	documentRepo repository.ContractDocumentRepository
+	userRepo     repository.UserRepository
+	reportSvc    *ReportService
+	emailSvc     *EmailService
+	auditSvc     *AuditService
+	storage      *storage.LocalStorage
+}
+
+func NewContractSignatureService(
+	repo repository.ContractSignatureRepository,
+	contractRepo repository.ContractRepository,
+	documentRepo repository.ContractDocumentRepository,
+	userRepo repository.UserRepository,
+	reportSvc *ReportService,
+	emailSvc *EmailService,
+	auditSvc *AuditService,
+	storage *storage.LocalStorage,
+) *ContractSignatureService {
+	return &ContractSignatureService{
+		repo:         repo,
+		contractRepo: contractRepo,
+		documentRepo: documentRepo,
+		userRepo:     userRepo,
+		reportSvc:    reportSvc,
+		emailSvc:     emailSvc,
+		auditSvc:     auditSvc,
+		storage:      storage,
+	}
+}
+
+// Start generates the promise contract of an approved contract, hashes it and opens its signature
+// by the buyer and an admin
+func (s *ContractSignatureService) Start(ctx context.Context, contractID uint, actorID uint) (*models.SignatureRequest, error) {
+	contract, err := s.loadContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.MaySign() {
+		return nil, fmt.Errorf("%w: solo se puede firmar la promesa de contratos aprobados (estado actual: %s)", ErrInvalidState, contract.Status)
+	}
+	latest, err := s.repo.FindLatestByContract(ctx, contractID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.Status == models.SignatureStatusPending {
+		return nil, fmt.Errorf("%w: el contrato ya tiene una firma en curso", ErrInvalidState)
+	}
+
+	pdf, err := s.reportSvc.GenerateContractPDF(ctx, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate promise contract: %w", err)
+	}
+	path, err := s.storage.UploadFromBytes(pdf.Bytes(), "promesa.pdf", signatureDir(contractID))
+	if err != nil {
+		return nil, err
+	}
+	buyerID := contract.ApplicantUserID
+	request := &models.SignatureRequest{
+		ContractID:     contractID,
+		Status:         models.SignatureStatusPending,
+		DocumentPath:   path,
+		DocumentSHA256: sha256Hex(pdf.Bytes()),
+		CreatedByID:    actorID,
+		Signers: []models.ContractSigner{
+			{Role: models.SignerRoleBuyer, UserID: &buyerID},
+			{Role: models.SignerRoleAdmin},
+		},
+	}
+	if err := s.repo.Create(ctx, request); err != nil {
+		s.removeFile(path)
+		return nil, fmt.Errorf("failed to create signature request: %w", err)
+	}
+	s.audit(ctx, actorID, "SIGNATURE_START", contractID,
+		fmt.Sprintf("Firma electrónica de la promesa iniciada (SHA-256 %s)", request.DocumentSHA256))
+	return s.repo.FindByID(ctx, request.ID)
+}
+
+// Find returns the latest signature request of the contract
+func (s *ContractSignatureService) Find(ctx context.Context, contractID uint, actor DocumentActor) (*models.SignatureRequest, error) {
+	if _, err := s.authorize(ctx, contractID, actor); err != nil {
+		return nil, err
+	}
+	return s.latest(ctx, contractID)
+}
+
+// Document returns the latest signature request and the full path of the unsigned promise contract
+// it signs, for the signers to read before signing
+func (s *ContractSignatureService) Document(ctx context.Context, contractID uint, actor DocumentActor) (*models.SignatureRequest, string, error) {
+	request, err := s.Find(ctx, contractID, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	fullPath, err := s.storage.SafeFullPath(request.DocumentPath)
+	if err != nil || !s.storage.Exists(request.DocumentPath) {
+		return nil, "", ErrNotFound
+	}
+	return request, fullPath, nil
+}
+
+// RequestOTP emails the actor a one-time code to confirm their signature: the buyer signs as buyer
+// and admins as the company
+func (s *ContractSignatureService) RequestOTP(ctx context.Context, contractID uint, actor DocumentActor) error {
+	contract, request, signer, err := s.pendingSigner(ctx, contractID, actor)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(ctx, actor.UserID)
+	if err != nil {
+		return err
+	}
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate signature code: %w", err)
+	}
+	ok, err := s.repo.SetOTP(ctx, signer.ID, actor.UserID, sha256Hex([]byte(code)), time.Now().Add(signatureOTPMinutes*time.Minute))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: la firma ya fue registrada por otro usuario", ErrInvalidState)
+	}
+	if err := s.emailSvc.SendSignatureOTP(ctx, user, contract, code, signatureOTPMinutes); err != nil {
+		return fmt.Errorf("failed to send signature code: %w", err)
+	}
+	s.audit(ctx, actor.UserID, "SIGNATURE_OTP", contractID,
+		fmt.Sprintf("Código de firma enviado a %s (%s) para la solicitud %d", user.Email, signer.Role, request.ID))
+	return nil
+}
+
+// Sign records the actor's drawn signature once the one-time code checks out, extending the hash
+// chain. The last signature completes the request: the signed PDF is generated and stored, and the
+// contract moves to signed. When that fails the signature stays recorded and Complete retries it.
+func (s *ContractSignatureService) Sign(ctx context.Context, contractID uint, req SignContractRequest, actor DocumentActor, ip, userAgent string) (*models.SignatureRequest, error) {
+	image, err := decodeSignatureImage(req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	_, request, signer, err := s.pendingSigner(ctx, contractID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOTP(ctx, signer, actor.UserID, req.OTP); err != nil {
+		return nil, err
+	}
+
+	path, err := s.storage.UploadFromBytes(image, "firma.png", signatureDir(contractID))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	imageHash := sha256Hex(image)
+	prevHash := request.LastHash()
+	hash := models.SignatureLinkHash(prevHash, signer.Role, actor.UserID, imageHash, now)
+	signer.SignaturePath = &path
+	signer.SignatureSHA256 = &imageHash
+	signer.SignedAt = &now
+	signer.PrevHash = &prevHash
+	signer.Hash = &hash
+	if ip != "" {
+		signer.IPAddress = &ip
+	}
+	if userAgent != "" {
+		signer.UserAgent = &userAgent
+	}
+	signed, err := s.repo.Sign(ctx, signer)
+	if err != nil || !signed {
+		s.removeFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign: %w", err)
+		}
+		return nil, fmt.Errorf("%w: la firma cambió mientras se firmaba; intente de nuevo", ErrInvalidState)
+	}
+	s.audit(ctx, actor.UserID, "CONTRACT_SIGN", contractID,
+		fmt.Sprintf("Promesa firmada electrónicamente (%s) desde %s. Eslabón %s", signer.Role, ip, hash))
+
+	request, err = s.repo.FindByID(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if request.AllSigned() {
+		if err := s.complete(ctx, request, actor.UserID); err != nil {
+			return nil, err
+		}
+		return s.repo.FindByID(ctx, request.ID)
+	}
+	return request, nil
+}
+
+// Cancel cancels a pending signature request; signatures already made are discarded
+func (s *ContractSignatureService) Cancel(ctx context.Context, contractID uint, actorID uint) error {
+	request, err := s.latest(ctx, contractID)
+	if err != nil {
+		return err
+	}
+	cancelled, err := s.repo.Cancel(ctx, request.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("%w: la firma no está en curso", ErrInvalidState)
+	}
+	s.audit(ctx, actorID, "SIGNATURE_CANCEL", contractID, fmt.Sprintf("Firma electrónica %d cancelada", request.ID))
+	return nil
+}
+
+// Verify recomputes the hash chain of the latest signature request of the contract and re-hashes
+// its stored files
+func (s *ContractSignatureService) Verify(ctx context.Context, contractID uint, actor DocumentActor) (*SignatureVerification, error) {
+	request, err := s.Find(ctx, contractID, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.verify(ctx, request), nil
+}
+
+func (s *ContractSignatureService) verify(ctx context.Context, request *models.SignatureRequest) *SignatureVerification {
+	result := &SignatureVerification{RequestID: request.ID, ChainHash: request.ChainHash, Valid: true}
+	check := func(name string, valid bool) {
+		result.Checks = append(result.Checks, SignatureCheck{Name: name, Valid: valid})
+		result.Valid = result.Valid && valid
+	}
+
+	check("Documento original", s.fileHash(request.DocumentPath) == request.DocumentSHA256)
+	prev := request.DocumentSHA256
+	for _, signer := range signedInOrder(request.Signers) {
+		name := fmt.Sprintf("Firma %s", signer.Role)
+		valid := signer.UserID != nil && signer.SignaturePath != nil && signer.SignatureSHA256 != nil &&
+			signer.PrevHash != nil && signer.Hash != nil && *signer.PrevHash == prev &&
+			s.fileHash(*signer.SignaturePath) == *signer.SignatureSHA256 &&
+			models.SignatureLinkHash(prev, signer.Role, *signer.UserID, *signer.SignatureSHA256, *signer.SignedAt) == *signer.Hash
+		check(name, valid)
+		if signer.Hash != nil {
+			prev = *signer.Hash
+		}
+	}
+
+	if request.Status != models.SignatureStatusCompleted {
+		check("Firma completa", false)
+		return result
+	}
+	signedDocValid := false
+	if request.SignedDocumentID != nil && request.SignedSHA256 != nil {
+		if document, err := s.documentRepo.FindByID(ctx, *request.SignedDocumentID); err == nil {
+			signedDocValid = document.SHA256 == *request.SignedSHA256 && s.fileHash(document.Path) == *request.SignedSHA256
+		}
+	}
+	check("Documento firmado", signedDocValid)
+	check("Cadena de firmas", request.SignedSHA256 != nil && request.ChainHash != nil &&
+		models.SignatureChainHash(prev, *request.SignedSHA256) == *request.ChainHash)
+	return result
+}
+
+// Complete retries the completion of a signature every signer already signed, e.g. when
+// generating or storing the signed PDF failed on the last signature
+func (s *ContractSignatureService) Complete(ctx context.Context, contractID uint, actorID uint) (*models.SignatureRequest, error) {
+	request, err := s.latest(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.SignatureStatusPending || !request.AllSigned() {
+		return nil, fmt.Errorf("%w: la firma no está pendiente de completarse", ErrInvalidState)
+	}
+	if err := s.complete(ctx, request, actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, request.ID)
+}
+
+// complete generates the signed PDF, stores it as the contract's signed_promise document, and
+// closes the hash chain and moves the contract to signed in one write. It can be re-run while the
+// request is still pending: a new run replaces the signed_promise document of the failed one.
+func (s *ContractSignatureService) complete(ctx context.Context, request *models.SignatureRequest, actorID uint) error {
+	buyer, admin := request.Signer(models.SignerRoleBuyer), request.Signer(models.SignerRoleAdmin)
+	if buyer == nil || admin == nil {
+		return fmt.Errorf("%w: faltan firmantes", ErrInvalidState)
+	}
+	contract, err := s.loadContract(ctx, request.ContractID)
+	if err != nil {
+		return err
+	}
+	from := contract.Status
+	fsm := statemachine.NewContractFSM(contract)
+	if err := fsm.Sign(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	buyerImage, err := s.readFile(buyer.SignaturePath)
+	if err != nil {
+		return err
+	}
+	adminImage, err := s.readFile(admin.SignaturePath)
+	if err != nil {
+		return err
+	}
+
+	page := SignedContractPage{RequestID: request.ID, ContractID: request.ContractID, DocumentSHA256: request.DocumentSHA256}
+	for _, signer := range signedInOrder(request.Signers) {
+		page.Signers = append(page.Signers, signaturePageSigner(signer))
+	}
+	pdf, err := s.reportSvc.GenerateSignedContractPDF(ctx, request.ContractID, adminImage, buyerImage, page)
+	if err != nil {
+		return fmt.Errorf("failed to generate signed promise contract: %w", err)
+	}
+	document, err := s.storeSignedDocument(ctx, request.ContractID, pdf.Bytes(), *admin.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	chainHash := models.SignatureChainHash(request.LastHash(), document.SHA256)
+	request.SignedDocumentID = &document.ID
+	request.SignedSHA256 = &document.SHA256
+	request.ChainHash = &chainHash
+	request.CompletedAt = &now
+	completed, err := s.repo.Complete(ctx, request, contract, from)
+	if err != nil {
+		return fmt.Errorf("failed to complete signature: %w", err)
+	}
+	if !completed {
+		return fmt.Errorf("%w: la firma o el contrato cambiaron mientras se completaba la firma", ErrInvalidState)
+	}
+
+	if err := fsm.Record(ctx, s.contractRepo, &actorID, "Promesa firmada electrónicamente"); err != nil {
+		logger.Error(fmt.Sprintf("[ContractSignatureService] Failed to record status transition of contract %d: %v", contract.ID, err))
+	}
+	s.audit(ctx, actorID, "SIGNATURE_COMPLETE", contract.ID,
+		fmt.Sprintf("Promesa firmada por todas las partes (SHA-256 %s, cadena %s)", document.SHA256, chainHash))
+	return nil
+}
+
+// storeSignedDocument saves the signed PDF as the contract's signed_promise document, as a new
+// version when one already exists
+func (s *ContractSignatureService) storeSignedDocument(ctx context.Context, contractID uint, pdf []byte, uploadedByID uint) (*models.ContractDocument, error) {
+	path, err := s.storage.UploadFromBytes(pdf, "promesa_firmada.pdf", fmt.Sprintf("contracts/%d/documents", contractID))
+	if err != nil {
+		return nil, err
+	}
+	document := &models.ContractDocument{
+		ContractID:   contractID,
+		Version:      1,
+		DocumentType: models.DocumentTypeSignedPromise,
+		FileName:     fmt.Sprintf("promesa_firmada_%d.pdf", contractID),
+		ContentType:  "application/pdf",
+		Size:         int64(len(pdf)),
+		Path:         path,
+		SHA256:       sha256Hex(pdf),
+		UploadedByID: uploadedByID,
+	}
+
+	current, err := s.documentRepo.FindCurrentByContract(ctx, contractID)
+	if err != nil {
+		s.removeFile(path)
+		return nil, err
+	}
+	for i := range current {
+		if current[i].DocumentType != models.DocumentTypeSignedPromise {
+			continue
+		}
+		replaced, err := s.documentRepo.Replace(ctx, &current[i], document)
+		if err != nil || !replaced {
+			s.removeFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to store signed promise contract: %w", err)
+			}
+			return nil, fmt.Errorf("%w: la promesa firmada cambió mientras se guardaba", ErrInvalidState)
+		}
+		return document, nil
+	}
+	if err := s.documentRepo.Create(ctx, document); err != nil {
+		s.removeFile(path)
+		return nil, fmt.Errorf("failed to store signed promise contract: %w", err)
+	}
+	return document, nil
+}
+
+// pendingSigner returns the signer the actor signs as on the contract's pending request: the
+// buyer for the applicant, the company for admins
+func (s *ContractSignatureService) pendingSigner(ctx context.Context, contractID uint, actor DocumentActor) (*models.Contract, *models.SignatureRequest, *models.ContractSigner, error) {
+	contract, err := s.loadContract(ctx, contractID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	request, err := s.latest(ctx, contractID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if request.Status != models.SignatureStatusPending {
+		return nil, nil, nil, fmt.Errorf("%w: el contrato no tiene una firma en curso", ErrInvalidState)
+	}
+
+	var signer *models.ContractSigner
+	switch {
+	case actor.Role == models.RoleAdmin:
+		signer = request.Signer(models.SignerRoleAdmin)
+	case actor.UserID == contract.ApplicantUserID:
+		signer = request.Signer(models.SignerRoleBuyer)
+	}
+	if signer == nil || (signer.UserID != nil && *signer.UserID != actor.UserID) {
+		return nil, nil, nil, ErrUnauthorized
+	}
+	if signer.SignedAt != nil {
+		return nil, nil, nil, fmt.Errorf("%w: ya firmó este contrato", ErrInvalidState)
+	}
+	return contract, request, signer, nil
+}
+
+// checkOTP compares the code with the signer's, counting failed attempts
+func (s *ContractSignatureService) checkOTP(ctx context.Context, signer *models.ContractSigner, userID uint, code string) error {
+	if signer.OTPHash == nil || signer.OTPExpiresAt == nil || signer.UserID == nil || *signer.UserID != userID ||
+		time.Now().After(*signer.OTPExpiresAt) || signer.OTPAttempts >= signatureOTPMaxAttempts {
+		return ErrInvalidOTP
+	}
+	if subtle.ConstantTimeCompare([]byte(sha256Hex([]byte(strings.TrimSpace(code)))), []byte(*signer.OTPHash)) != 1 {
+		if err := s.repo.IncrementOTPAttempts(ctx, signer.ID); err != nil {
+			logger.Error(fmt.Sprintf("[ContractSignatureService] Failed to count code attempt of signer %d: %v", signer.ID, err))
+		}
+		return ErrInvalidOTP
+	}
+	return nil
+}
+
+// authorize checks the actor can see the signature of the contract: admins and sellers, or the buyer
+func (s *ContractSignatureService) authorize(ctx context.Context, contractID uint, actor DocumentActor) (*models.Contract, error) {
+	contract, err := s.loadContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.isStaff() && !contract.IsOwnedBy(actor.UserID) {
+		return nil, ErrUnauthorized
+	}
+	return contract, nil
+}
+
+func (s *ContractSignatureService) loadContract(ctx context.Context, contractID uint) (*models.Contract, error) {
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return contract, nil
+}
+
+func (s *ContractSignatureService) latest(ctx context.Context, contractID uint) (*models.SignatureRequest, error) {
+	request, err := s.repo.FindLatestByContract(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+func (s *ContractSignatureService) readFile(path *string) ([]byte, error) {
+	if path == nil {
+		return nil, ErrNotFound
+	}
+	fullPath, err := s.storage.SafeFullPath(*path)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(fullPath)
+}
+
+// fileHash returns the SHA-256 of a stored file, or "" when it cannot be read
+func (s *ContractSignatureService) fileHash(path string) string {
+	data, err := s.readFile(&path)
+	if err != nil {
+		return ""
+	}
+	return sha256Hex(data)
+}
+
+func (s *ContractSignatureService) removeFile(path string) {
+	if err := s.storage.Delete(path); err != nil {
+		logger.Error(fmt.Sprintf("[ContractSignatureService] Failed to remove orphan file %s: %v", path, err))
+	}
+}
+
+func (s *ContractSignatureService) audit(ctx context.Context, userID uint, action string, contractID uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "Contract", contractID, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ContractSignatureService] Failed to audit %s for contract %d: %v", action, contractID, err))
+	}
+}
+
+// decodeSignatureImage decodes a drawn signature sent as a PNG data URL or plain base64
+func decodeSignatureImage(signature string) ([]byte, error) {
+	signature = strings.TrimSpace(signature)
+	if i := strings.Index(signature, ","); strings.HasPrefix(signature, "data:") && i > 0 {
+		if !strings.HasPrefix(signature, "data:image/png;base64,") {
+			return nil, fmt.Errorf("%w: la firma debe ser una imagen PNG", ErrInvalidSignature)
+		}
+		signature = signature[i+1:]
+	}
+	image, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !bytes.HasPrefix(image, pngMagic) {
+		return nil, fmt.Errorf("%w: la firma debe ser una imagen PNG", ErrInvalidSignature)
+	}
+	if len(image) > signatureMaxImageSize {
+		return nil, fmt.Errorf("%w: la imagen de la firma excede 1 MB", ErrInvalidSignature)
+	}
+	return image, nil
+}
+
+// signedInOrder returns the signers that signed, in signing order
+func signedInOrder(signers []models.ContractSigner) []models.ContractSigner {
+	var signed []models.ContractSigner
+	for _, signer := range signers {
+		if signer.SignedAt != nil {
+			signed = append(signed, signer)
+		}
+	}
+	sort.SliceStable(signed, func(i, j int) bool { return signed[i].SignedAt.Before(*signed[j].SignedAt) })
+	return signed
+}
+
+func signaturePageSigner(signer models.ContractSigner) SignedContractSigner {
+	role := "Comprador"
+	if signer.Role == models.SignerRoleAdmin {
+		role = "Por la empresa"
+	}
+	page := SignedContractSigner{Role: role, SignedAt: signer.SignedAt.Format("02/01/2006 15:04:05 MST")}
+	if signer.User != nil {
+		page.Name, page.Email = signer.User.FullName, signer.User.Email
+	}
+	if signer.IPAddress != nil {
+		page.IPAddress = *signer.IPAddress
+	}
+	if signer.SignatureSHA256 != nil {
+		page.SignatureSHA256 = *signer.SignatureSHA256
+	}
+	if signer.PrevHash != nil {
+		page.PrevHash = *signer.PrevHash
+	}
+	if signer.Hash != nil {
+		page.Hash = *signer.Hash
+	}
+	return page
+}
+
+func signatureDir(contractID uint) string {
+	return fmt.Sprintf("contracts/%d/signatures", contractID)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
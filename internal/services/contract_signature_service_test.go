@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureChain(t *testing.T) {
+	buyerID, adminID := uint(7), uint(1)
+	buyerAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	adminAt := buyerAt.Add(time.Hour)
+	request := &models.SignatureRequest{
+		DocumentSHA256: "doc",
+		Signers: []models.ContractSigner{
+			{Role: models.SignerRoleBuyer, UserID: &buyerID},
+			{Role: models.SignerRoleAdmin},
+		},
+	}
+	assert.Equal(t, "doc", request.LastHash())
+	assert.False(t, request.AllSigned())
+
+	buyerHash := models.SignatureLinkHash(request.LastHash(), models.SignerRoleBuyer, buyerID, "img1", buyerAt)
+	request.Signers[0].SignedAt, request.Signers[0].Hash = &buyerAt, &buyerHash
+	assert.Equal(t, buyerHash, request.LastHash())
+
+	adminHash := models.SignatureLinkHash(request.LastHash(), models.SignerRoleAdmin, adminID, "img2", adminAt)
+	request.Signers[1].SignedAt, request.Signers[1].Hash = &adminAt, &adminHash
+	assert.Equal(t, adminHash, request.LastHash())
+	assert.True(t, request.AllSigned())
+
+	// Any change to an earlier link changes the following ones
+	assert.NotEqual(t, adminHash, models.SignatureLinkHash(
+		models.SignatureLinkHash("doc", models.SignerRoleBuyer, buyerID, "tampered", buyerAt),
+		models.SignerRoleAdmin, adminID, "img2", adminAt))
+	assert.Equal(t, []models.ContractSigner{request.Signers[0], request.Signers[1]}, signedInOrder(request.Signers))
+}
+
+func TestDecodeSignatureImage(t *testing.T) {
+	png := append(append([]byte{}, pngMagic...), 0, 0, 0, 13)
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	image, err := decodeSignatureImage("data:image/png;base64," + encoded)
+	require.NoError(t, err)
+	assert.Equal(t, png, image)
+
+	image, err = decodeSignatureImage(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, png, image)
+
+	_, err = decodeSignatureImage("data:image/jpeg;base64," + encoded)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = decodeSignatureImage(base64.StdEncoding.EncodeToString([]byte("not a png")))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestContractSignOnlyWhenApproved(t *testing.T) {
+	ctx := context.Background()
+	for status, allowed := range map[string]bool{
+		models.ContractStatusPending:  false,
+		models.ContractStatusApproved: true,
+		models.ContractStatusSigned:   false,
+		models.ContractStatusClosed:   false,
+	} {
+		contract := &models.Contract{Status: status}
+		err := statemachine.NewContractFSM(contract).Sign(ctx)
+		if allowed {
+			assert.NoError(t, err, status)
+			assert.Equal(t, models.ContractStatusSigned, contract.Status)
+			assert.True(t, contract.InForce())
+		} else {
+			assert.Error(t, err, status)
+		}
+	}
+}
+
+func TestContractReopenKeepsSignedStatus(t *testing.T) {
+	ctx := context.Background()
+	balance := 0.0
+	for _, status := range []string{models.ContractStatusApproved, models.ContractStatusSigned} {
+		contract := &models.Contract{Status: status, Balance: &balance}
+		require.NoError(t, statemachine.NewContractFSM(contract).Close(ctx), status)
+		require.NotNil(t, contract.ClosedFromStatus)
+
+		fsm := statemachine.NewContractFSM(contract)
+		require.NoError(t, fsm.Reopen(ctx), status)
+		assert.Equal(t, status, contract.Status)
+		assert.Nil(t, contract.ClosedFromStatus)
+		assert.Equal(t, status == models.ContractStatusApproved, contract.MaySign(), status)
+	}
+}
+
+type mockSignatureRepository struct {
+	repository.ContractSignatureRepository
+	request   models.SignatureRequest
+	completed bool
+}
+
+func (m *mockSignatureRepository) FindLatestByContract(ctx context.Context, contractID uint) (*models.SignatureRequest, error) {
+	request := m.request
+	return &request, nil
+}
+
+func (m *mockSignatureRepository) Complete(ctx context.Context, request *models.SignatureRequest, contract *models.Contract, fromStatus string) (bool, error) {
+	m.completed = true
+	return true, nil
+}
+
+func TestContractSignatureService_CompleteRetry(t *testing.T) {
+	buyerID, adminID := uint(7), uint(1)
+	at := time.Now()
+	repo := &mockSignatureRepository{request: models.SignatureRequest{ID: 3, ContractID: 5, Status: models.SignatureStatusPending,
+		Signers: []models.ContractSigner{
+			{Role: models.SignerRoleBuyer, UserID: &buyerID, SignedAt: &at},
+			{Role: models.SignerRoleAdmin},
+		}}}
+	contract := &models.Contract{ID: 5, Status: models.ContractStatusApproved}
+	svc := NewContractSignatureService(repo, &mockContractRepository{mockFindByIDWithDetails: func(ctx context.Context, id uint) (*models.Contract, error) {
+		c := *contract
+		return &c, nil
+	}}, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	// Nothing to retry while a signer is missing
+	_, err := svc.Complete(ctx, 5, adminID)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	// A contract that can no longer be signed is refused before generating or writing anything
+	repo.request.Signers[1].UserID, repo.request.Signers[1].SignedAt = &adminID, &at
+	contract.Status = models.ContractStatusClosed
+	_, err = svc.Complete(ctx, 5, adminID)
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.False(t, repo.completed)
+}
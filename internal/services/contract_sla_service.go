@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidContractSLA is returned for SLA rules with an unknown status or a non-positive limit
+var ErrInvalidContractSLA = errors.New("regla de tiempo por estado inválida")
+
+// ContractSLARequest is the input for setting the SLA of a status, per project or for all projects
+type ContractSLARequest struct {
+	ProjectID *uint  `json:"project_id"` // nil or 0 = all projects
+	Status    string `json:"status" binding:"required"`
+	MaxHours  int    `json:"max_hours" binding:"required"`
+}
+
+// TimeInStatusQuery filters the time-in-status listing
+type TimeInStatusQuery struct {
+	Status      string // empty = the statuses that can carry an SLA
+	ProjectID   uint
+	OverdueOnly bool
+}
+
+// ContractTimeInStatus is a contract with the time it has spent in its current status and its SLA
+type ContractTimeInStatus struct {
+	ContractID     uint       `json:"contract_id"`
+	ProjectID      uint       `json:"project_id"`
+	ProjectName    string     `json:"project_name"`
+	LotID          uint       `json:"lot_id"`
+	LotName        string     `json:"lot_name"`
+	ApplicantName  string     `json:"applicant_name"`
+	CreatorName    string     `json:"creator_name"`
+	Status         string     `json:"status"`
+	Since          time.Time  `json:"since"`
+	HoursInStatus  float64    `json:"hours_in_status"`
+	SLAHours       *int       `json:"sla_hours"`
+	Overdue        bool       `json:"overdue"`
+	SLAEscalatedAt *time.Time `json:"sla_escalated_at"`
+}
+
+// ContractSLAService manages the time-in-status SLAs of contracts and escalates the overdue ones
+type ContractSLAService struct {
+	repo            repository.ContractSLARepository
+	userRepo        repository.UserRepository
+	notificationSvc *NotificationService
+	emailSvc        *EmailService
+	auditSvc        *AuditService
+}
+
+func NewContractSLAService(repo repository.ContractSLARepository, userRepo repository.UserRepository, notificationSvc *NotificationService, emailSvc *EmailService, auditSvc *AuditService) *ContractSLAService {
+	return &ContractSLAService{
+		repo:            repo,
+		userRepo:        userRepo,
+		notificationSvc: notificationSvc,
+		emailSvc:        emailSvc,
+		auditSvc:        auditSvc,
+	}
+}
+
+// List returns every SLA rule, default rules first
+func (s *ContractSLAService) List(ctx context.Context) ([]models.ContractStatusSLA, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// Set creates or updates the SLA of a status for a project (or the default for all projects)
+func (s *ContractSLAService) Set(ctx context.Context, req ContractSLARequest, actorID uint) (*models.ContractStatusSLA, error) {
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if !models.IsSLAStatus(status) {
+		return nil, fmt.Errorf("%w: el estado debe ser uno de %s", ErrInvalidContractSLA, strings.Join(models.SLAStatuses, ", "))
+	}
+	if req.MaxHours <= 0 {
+		return nil, fmt.Errorf("%w: las horas máximas deben ser mayores a cero", ErrInvalidContractSLA)
+	}
+	projectID := req.ProjectID
+	if projectID != nil && *projectID == 0 {
+		projectID = nil
+	}
+
+	sla, err := s.repo.FindRule(ctx, projectID, status)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		sla = &models.ContractStatusSLA{ProjectID: projectID, Status: status}
+	}
+	sla.MaxHours = req.MaxHours
+	if err := s.repo.Save(ctx, sla); err != nil {
+		return nil, fmt.Errorf("failed to save contract SLA: %w", err)
+	}
+	scope := "todos los proyectos"
+	if projectID != nil {
+		scope = fmt.Sprintf("proyecto %d", *projectID)
+	}
+	s.audit(ctx, actorID, "UPDATE", sla.ID, fmt.Sprintf("Tiempo máximo en estado %s para %s: %d horas", status, scope, sla.MaxHours))
+	return s.repo.FindByID(ctx, sla.ID)
+}
+
+// Delete removes an SLA rule; contracts of its project fall back to the default rule
+func (s *ContractSLAService) Delete(ctx context.Context, id uint, actorID uint) error {
+	sla, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := s.repo.Delete(ctx, sla.ID); err != nil {
+		return err
+	}
+	s.audit(ctx, actorID, "DELETE", sla.ID, fmt.Sprintf("Tiempo máximo en estado %s eliminado", sla.Status))
+	return nil
+}
+
+// TimeInStatus lists the contracts by the time spent in their current status, longest first
+func (s *ContractSLAService) TimeInStatus(ctx context.Context, query TimeInStatusQuery) ([]ContractTimeInStatus, error) {
+	statuses := models.SLAStatuses
+	if query.Status != "" {
+		statuses = []string{query.Status}
+	}
+	contracts, err := s.repo.FindContractsInStatus(ctx, repository.ContractSLAQuery{Statuses: statuses, ProjectID: query.ProjectID})
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := timeInStatus(contracts, rules, time.Now())
+	if query.OverdueOnly {
+		overdue := items[:0]
+		for _, item := range items {
+			if item.Overdue {
+				overdue = append(overdue, item)
+			}
+		}
+		items = overdue
+	}
+	return items, nil
+}
+
+// EscalateOverdue notifies the admins, in the app and by email, of the contracts that stayed
+// longer than their SLA in their status. Each entry into a status is escalated only once.
+func (s *ContractSLAService) EscalateOverdue(ctx context.Context) error {
+	items, err := s.TimeInStatus(ctx, TimeInStatusQuery{OverdueOnly: true})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var escalated []SLAEscalationData
+	for _, item := range items {
+		if item.SLAEscalatedAt != nil {
+			continue
+		}
+		ok, err := s.repo.MarkEscalated(ctx, item.ContractID, item.Status, now)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ContractSLAService] Failed to mark contract %d escalated: %v", item.ContractID, err))
+			continue
+		}
+		if !ok {
+			continue // status changed or escalated meanwhile
+		}
+
+		elapsed := formatElapsed(item.HoursInStatus)
+		msg := fmt.Sprintf("El contrato #%d del lote %s (%s) de %s lleva %s en estado %s (máximo %d horas)",
+			item.ContractID, item.LotName, item.ProjectName, item.ApplicantName, elapsed, contractStatusLabel(item.Status), *item.SLAHours)
+		if err := s.notificationSvc.NotifyAdmins(ctx, "Contrato fuera de tiempo", msg, models.NotificationTypeContractSLA); err != nil {
+			logger.Error(fmt.Sprintf("[ContractSLAService] Failed to notify admins of contract %d: %v", item.ContractID, err))
+		}
+		escalated = append(escalated, SLAEscalationData{
+			ContractID:  item.ContractID,
+			LotName:     item.LotName,
+			ProjectName: item.ProjectName,
+			Applicant:   item.ApplicantName,
+			Status:      contractStatusLabel(item.Status),
+			Since:       item.Since.Format("02/01/2006 15:04"),
+			Elapsed:     elapsed,
+			MaxHours:    *item.SLAHours,
+		})
+	}
+	if len(escalated) == 0 {
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("[ContractSLAService] Escalated %d overdue contracts", len(escalated)))
+	admins, err := s.userRepo.FindAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range admins {
+		if err := s.emailSvc.SendSLAEscalation(ctx, &admins[i], escalated); err != nil {
+			logger.Error(fmt.Sprintf("[ContractSLAService] Failed to email admin %d: %v", admins[i].ID, err))
+		}
+	}
+	return nil
+}
+
+func (s *ContractSLAService) audit(ctx context.Context, userID uint, action string, id uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "ContractStatusSLA", id, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ContractSLAService] Failed to audit %s for SLA %d: %v", action, id, err))
+	}
+}
+
+// timeInStatus computes the time in status of the contracts at now against the SLA rules,
+// sorted by time in status, longest first
+func timeInStatus(contracts []models.Contract, rules []models.ContractStatusSLA, now time.Time) []ContractTimeInStatus {
+	items := make([]ContractTimeInStatus, 0, len(contracts))
+	for i := range contracts {
+		c := &contracts[i]
+		since := c.StatusSince()
+		item := ContractTimeInStatus{
+			ContractID:     c.ID,
+			ProjectID:      c.Lot.ProjectID,
+			ProjectName:    c.Lot.Project.Name,
+			LotID:          c.LotID,
+			LotName:        c.Lot.Name,
+			ApplicantName:  c.ApplicantUser.FullName,
+			Status:         c.Status,
+			Since:          since,
+			HoursInStatus:  now.Sub(since).Hours(),
+			SLAEscalatedAt: c.SLAEscalatedAt,
+		}
+		if c.Creator != nil {
+			item.CreatorName = c.Creator.FullName
+		}
+		if rule := models.SLAFor(rules, c.Lot.ProjectID, c.Status); rule != nil {
+			hours := rule.MaxHours
+			item.SLAHours = &hours
+			item.Overdue = item.HoursInStatus > float64(hours)
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].HoursInStatus > items[j].HoursInStatus
+	})
+	return items
+}
+
+// formatElapsed renders hours as days and hours, e.g. "3 días 4 horas"
+func formatElapsed(hours float64) string {
+	total := int(hours)
+	days, rest := total/24, total%24
+	switch {
+	case days == 0:
+		return fmt.Sprintf("%d horas", rest)
+	case days == 1:
+		return fmt.Sprintf("1 día %d horas", rest)
+	default:
+		return fmt.Sprintf("%d días %d horas", days, rest)
+	}
+}
+
+// contractStatusLabel returns the Spanish name of a contract status
+func contractStatusLabel(status string) string {
+	switch status {
+	case models.ContractStatusPending:
+		return "pendiente"
+	case models.ContractStatusSubmitted:
+		return "enviado a aprobación"
+	case models.ContractStatusApproved:
+		return "aprobado"
+	case models.ContractStatusSigned:
+		return "firmado"
+	case models.ContractStatusRejected:
+		return "rechazado"
+	case models.ContractStatusCancelled:
+		return "cancelado"
+	case models.ContractStatusClosed:
+		return "cerrado"
+	default:
+		return status
+	}
+}
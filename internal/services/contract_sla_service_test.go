@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeInStatus(t *testing.T) {
+	u := func(v uint) *uint { return &v }
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	ago := func(hours int) *time.Time {
+		at := now.Add(-time.Duration(hours) * time.Hour)
+		return &at
+	}
+	rules := []models.ContractStatusSLA{
+		{Status: models.ContractStatusSubmitted, MaxHours: 48},
+		{Status: models.ContractStatusSubmitted, ProjectID: u(7), MaxHours: 12},
+		{Status: models.ContractStatusPending, ProjectID: u(7), MaxHours: 72},
+	}
+	lot := func(projectID uint) models.Lot {
+		return models.Lot{ProjectID: projectID, Name: "A-1", Project: models.Project{Name: "Proyecto"}}
+	}
+	contracts := []models.Contract{
+		{ID: 1, Status: models.ContractStatusSubmitted, Lot: lot(1), StatusChangedAt: ago(30)},
+		{ID: 2, Status: models.ContractStatusSubmitted, Lot: lot(7), StatusChangedAt: ago(30)},
+		{ID: 3, Status: models.ContractStatusPending, Lot: lot(1), CreatedAt: now.Add(-500 * time.Hour)},
+		{ID: 4, Status: models.ContractStatusPending, Lot: lot(7), StatusChangedAt: ago(80)},
+	}
+
+	items := timeInStatus(contracts, rules, now)
+	require.Len(t, items, 4)
+	byID := map[uint]ContractTimeInStatus{}
+	for _, item := range items {
+		byID[item.ContractID] = item
+	}
+
+	assert.Equal(t, uint(3), items[0].ContractID) // longest in status first; falls back to creation
+	assert.InDelta(t, 500, byID[3].HoursInStatus, 0.001)
+	assert.Nil(t, byID[3].SLAHours) // no pending rule outside project 7
+	assert.False(t, byID[3].Overdue)
+
+	assert.Equal(t, 48, *byID[1].SLAHours) // default rule
+	assert.False(t, byID[1].Overdue)
+	assert.Equal(t, 12, *byID[2].SLAHours) // the project rule wins
+	assert.True(t, byID[2].Overdue)
+	assert.True(t, byID[4].Overdue)
+}
+
+func TestContractFSMRestartsStatusClock(t *testing.T) {
+	escalated := time.Now().Add(-time.Hour)
+	contract := &models.Contract{Status: models.ContractStatusPending, SLAEscalatedAt: &escalated}
+	before := contract.StatusSince()
+
+	require.NoError(t, statemachine.NewContractFSM(contract).Submit(context.Background()))
+	assert.Equal(t, models.ContractStatusSubmitted, contract.Status)
+	require.NotNil(t, contract.StatusChangedAt)
+	assert.True(t, contract.StatusSince().After(before))
+	assert.Nil(t, contract.SLAEscalatedAt)
+}
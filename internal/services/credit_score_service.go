@@ -14,13 +14,15 @@ type CreditScoreService struct {
 	userRepo     repository.UserRepository
 	contractRepo repository.ContractRepository
 	paymentRepo  repository.PaymentRepository
+	partyRepo    repository.ContractPartyRepository
 }
 
-func NewCreditScoreService(userRepo repository.UserRepository, contractRepo repository.ContractRepository, paymentRepo repository.PaymentRepository) *CreditScoreService {
+func NewCreditScoreService(userRepo repository.UserRepository, contractRepo repository.ContractRepository, paymentRepo repository.PaymentRepository, partyRepo repository.ContractPartyRepository) *CreditScoreService {
 	return &CreditScoreService{
 		userRepo:     userRepo,
 		contractRepo: contractRepo,
 		paymentRepo:  paymentRepo,
+		partyRepo:    partyRepo,
 	}
 }
 
@@ -88,7 +90,9 @@ func (s *CreditScoreService) UpdateAllScores(ctx context.Context) error {
 	return nil
 }
 
-// calculateCreditScore calculates credit score based on payment history
+// calculateCreditScore calculates credit score based on payment history. Contracts where the user
+// is a co-buyer count like their own; as guarantor only the late payments and cancellation count
+// against them; as legal representative the contract is not theirs and is ignored.
 func (s *CreditScoreService) calculateCreditScore(ctx context.Context, userID uint) int {
 	baseScore := 500 // Starting score
 
@@ -98,43 +102,28 @@ func (s *CreditScoreService) calculateCreditScore(ctx context.Context, userID ui
 		return baseScore
 	}
 
+	seen := make(map[uint]bool)
 	for _, contract := range contracts {
-		// Get payments for this contract
-		payments, err := s.paymentRepo.FindByContract(ctx, contract.ID)
-		if err != nil {
-			continue
-		}
-
-		for _, payment := range payments {
-			if payment.Status == models.PaymentStatusPaid && payment.PaymentDate != nil {
-				// Calculate days late
-				daysLate := int(payment.PaymentDate.Sub(payment.DueDate).Hours() / 24)
-
-				if daysLate <= 0 {
-					// On-time payment: +5 points
-					baseScore += 5
-				} else if daysLate <= 7 {
-					// 1-7 days late: -2 points
-					baseScore -= 2
-				} else if daysLate <= 30 {
-					// 8-30 days late: -5 points
-					baseScore -= 5
-				} else {
-					// 30+ days late: -10 points
-					baseScore -= 10
-				}
-			}
-		}
+		seen[contract.ID] = true
+		baseScore += s.contractScore(ctx, &contract, false)
+	}
 
-		// Penalize cancelled contracts
-		if contract.Status == models.ContractStatusCancelled {
-			baseScore -= 20
+	// Contracts where the user is a co-buyer or guarantor
+	parties, err := s.partyRepo.FindByUser(ctx, userID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[CreditScoreService] Failed to load contract parties of user %d: %v", userID, err))
+		parties = nil
+	}
+	for _, party := range parties {
+		if seen[party.ContractID] || party.Role == models.PartyRoleLegalRepresentative {
+			continue
 		}
-
-		// Bonus for closed contracts (fully paid)
-		if contract.Status == models.ContractStatusClosed {
-			baseScore += 50
+		contract, err := s.contractRepo.FindByID(ctx, party.ContractID)
+		if err != nil {
+			continue
 		}
+		seen[contract.ID] = true
+		baseScore += s.contractScore(ctx, contract, party.Role == models.PartyRoleGuarantor)
 	}
 
 	// Ensure score stays within reasonable bounds
@@ -147,3 +136,50 @@ func (s *CreditScoreService) calculateCreditScore(ctx context.Context, userID ui
 
 	return baseScore
 }
+
+// contractScore returns the points a contract adds to (or takes from) the score. With
+// penaltiesOnly, on-time payments and closing the contract add nothing.
+func (s *CreditScoreService) contractScore(ctx context.Context, contract *models.Contract, penaltiesOnly bool) int {
+	score := 0
+
+	// Get payments for this contract
+	payments, err := s.paymentRepo.FindByContract(ctx, contract.ID)
+	if err != nil {
+		return 0
+	}
+
+	for _, payment := range payments {
+		if payment.Status == models.PaymentStatusPaid && payment.PaymentDate != nil {
+			// Calculate days late
+			daysLate := int(payment.PaymentDate.Sub(payment.DueDate).Hours() / 24)
+
+			if daysLate <= 0 {
+				// On-time payment: +5 points
+				if !penaltiesOnly {
+					score += 5
+				}
+			} else if daysLate <= 7 {
+				// 1-7 days late: -2 points
+				score -= 2
+			} else if daysLate <= 30 {
+				// 8-30 days late: -5 points
+				score -= 5
+			} else {
+				// 30+ days late: -10 points
+				score -= 10
+			}
+		}
+	}
+
+	// Penalize cancelled contracts
+	if contract.Status == models.ContractStatusCancelled {
+		score -= 20
+	}
+
+	// Bonus for closed contracts (fully paid)
+	if contract.Status == models.ContractStatusClosed && !penaltiesOnly {
+		score += 50
+	}
+
+	return score
+}
@@ -6,6 +6,8 @@ import (
 	"embed"
 	"fmt"
 	"html/template"
+	"strings"
+	"time"
 
 	"github.com/resend/resend-go/v2"
 	"github.com/sjperalta/fintera-api/internal/config"
@@ -82,6 +84,19 @@ func (s *EmailService) checkEmailPreconditions(user *models.User, operation stri
 	return true, nil
 }
 
+// coBuyerEmails returns the valid emails of the contract's co-buyers, copied on payment emails
+func (s *EmailService) coBuyerEmails(contract *models.Contract) []string {
+	var emails []string
+	for _, party := range contract.PartiesWithRole(models.PartyRoleCoBuyer) {
+		email := party.ContactEmail()
+		if email == "" || strings.EqualFold(email, contract.ApplicantUser.Email) || s.validateEmail(email) != nil {
+			continue
+		}
+		emails = append(emails, email)
+	}
+	return emails
+}
+
 func (s *EmailService) SendRecoveryCode(ctx context.Context, user *models.User, code string) error {
 	if ok, err := s.checkEmailPreconditions(user, "recovery email"); !ok {
 		return err
@@ -122,6 +137,52 @@ func (s *EmailService) SendRecoveryCode(ctx context.Context, user *models.User,
 	return nil
 }
 
+// SendSignatureOTP sends the one-time code that confirms the signature of a promise contract
+func (s *EmailService) SendSignatureOTP(ctx context.Context, user *models.User, contract *models.Contract, code string, minutes int) error {
+	if ok, err := s.checkEmailPreconditions(user, "signature code email"); !ok {
+		return err
+	}
+
+	data := struct {
+		Name        string
+		Code        string
+		Minutes     int
+		ContractID  uint
+		ProjectName string
+		LotName     string
+		AppURL      string
+	}{
+		Name:        user.FullName,
+		Code:        code,
+		Minutes:     minutes,
+		ContractID:  contract.ID,
+		ProjectName: contract.Lot.Project.Name,
+		LotName:     contract.Lot.Name,
+		AppURL:      s.config.AppURL,
+	}
+
+	body, err := s.renderTemplate("signature_otp.html", data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to render signature_otp template: %v", err))
+		return err
+	}
+
+	subject := fmt.Sprintf("Código de firma - Contrato #%d", contract.ID)
+	params := &resend.SendEmailRequest{
+		From:    s.config.FromEmail,
+		To:      []string{user.Email},
+		Subject: subject,
+		Html:    body,
+	}
+	if _, err := s.resendClient.Emails.Send(params); err != nil {
+		logger.Error(fmt.Sprintf("Failed to send email to %s: %v", user.Email, err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("📧 [Email Sent] To: %s | Subject: %s", user.Email, subject))
+	return nil
+}
+
 func (s *EmailService) SendAccountCreated(ctx context.Context, user *models.User, tempPassword string) error {
 	if ok, err := s.checkEmailPreconditions(user, "account created email"); !ok {
 		return err
@@ -338,7 +399,8 @@ func (s *EmailService) SendContractRejected(ctx context.Context, contract *model
 	return nil
 }
 
-func (s *EmailService) SendPaymentApproved(ctx context.Context, payment *models.Payment) error {
+// SendPaymentApproved notifies the buyer of an approved payment, attaching its receipt PDF when given
+func (s *EmailService) SendPaymentApproved(ctx context.Context, payment *models.Payment, receipt *models.PaymentReceipt, receiptPDF []byte) error {
 	if ok, err := s.checkEmailPreconditions(&payment.Contract.ApplicantUser, "payment approved email"); !ok {
 		return err
 	}
@@ -368,6 +430,9 @@ func (s *EmailService) SendPaymentApproved(ctx context.Context, payment *models.
 		OverpaymentAmount string
 		DueDate           string
 		ApprovedAt        string
+		Buyers            string
+		HasCoBuyers       bool
+		ReceiptNumber     string
 		AppURL            string
 	}{
 		Name:              payment.Contract.ApplicantUser.FullName,
@@ -381,8 +446,13 @@ func (s *EmailService) SendPaymentApproved(ctx context.Context, payment *models.
 		OverpaymentAmount: fmt.Sprintf("L%.2f", overpayment),
 		DueDate:           payment.DueDate.Format("02/01/2006"),
 		ApprovedAt:        payment.ApprovedAt.Format("02/01/2006"),
+		Buyers:            payment.Contract.BuyerNames(),
+		HasCoBuyers:       len(payment.Contract.PartiesWithRole(models.PartyRoleCoBuyer)) > 0,
 		AppURL:            s.config.AppURL,
 	}
+	if receipt != nil {
+		data.ReceiptNumber = receipt.DisplayNumber()
+	}
 
 	body, err := s.renderTemplate("payment_approved.html", data)
 	if err != nil {
@@ -393,9 +463,17 @@ func (s *EmailService) SendPaymentApproved(ctx context.Context, payment *models.
 	params := &resend.SendEmailRequest{
 		From:    s.config.FromEmail,
 		To:      []string{payment.Contract.ApplicantUser.Email},
+		Cc:      s.coBuyerEmails(&payment.Contract),
 		Subject: "Pago Aprobado",
 		Html:    body,
 	}
+	if receipt != nil && len(receiptPDF) > 0 {
+		params.Attachments = []*resend.Attachment{{
+			Content:     receiptPDF,
+			Filename:    receipt.DisplayNumber() + ".pdf",
+			ContentType: "application/pdf",
+		}}
+	}
 	_, err = s.resendClient.Emails.Send(params)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to send email to %s: %v", payment.Contract.ApplicantUser.Email, err))
@@ -418,6 +496,8 @@ func (s *EmailService) SendPaymentRejected(ctx context.Context, contract *models
 		Amount      string
 		DueDate     string
 		Reason      string
+		Buyers      string
+		HasCoBuyers bool
 		AppURL      string
 	}{
 		Name:        contract.ApplicantUser.FullName,
@@ -426,6 +506,8 @@ func (s *EmailService) SendPaymentRejected(ctx context.Context, contract *models
 		Amount:      fmt.Sprintf("L%.2f", amount),
 		DueDate:     dueDate,
 		Reason:      reason,
+		Buyers:      contract.BuyerNames(),
+		HasCoBuyers: len(contract.PartiesWithRole(models.PartyRoleCoBuyer)) > 0,
 		AppURL:      s.config.AppURL,
 	}
 
@@ -437,6 +519,7 @@ func (s *EmailService) SendPaymentRejected(ctx context.Context, contract *models
 	params := &resend.SendEmailRequest{
 		From:    s.config.FromEmail,
 		To:      []string{contract.ApplicantUser.Email},
+		Cc:      s.coBuyerEmails(contract),
 		Subject: "Pago Rechazado",
 		Html:    body,
 	}
@@ -502,6 +585,57 @@ func (s *EmailService) SendOverduePayments(ctx context.Context, user *models.Use
 	return nil
 }
 
+// SLAEscalationData is one contract of the SLA escalation email
+type SLAEscalationData struct {
+	ContractID  uint
+	LotName     string
+	ProjectName string
+	Applicant   string
+	Status      string
+	Since       string
+	Elapsed     string
+	MaxHours    int
+}
+
+// SendSLAEscalation sends an admin the contracts that stayed longer than their SLA in their status
+func (s *EmailService) SendSLAEscalation(ctx context.Context, user *models.User, contracts []SLAEscalationData) error {
+	if ok, err := s.checkEmailPreconditions(user, "SLA escalation email"); !ok {
+		return err
+	}
+
+	data := struct {
+		Name      string
+		Contracts []SLAEscalationData
+		AppURL    string
+	}{
+		Name:      user.FullName,
+		Contracts: contracts,
+		AppURL:    s.config.AppURL,
+	}
+
+	body, err := s.renderTemplate("sla_escalation.html", data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to render sla_escalation template: %v", err))
+		return err
+	}
+
+	subject := fmt.Sprintf("Contratos Fuera de Tiempo (%d contratos)", len(contracts))
+	params := &resend.SendEmailRequest{
+		From:    s.config.FromEmail,
+		To:      []string{user.Email},
+		Subject: subject,
+		Html:    body,
+	}
+	_, err = s.resendClient.Emails.Send(params)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send email to %s: %v", user.Email, err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("📧 [Email Sent] To: %s | Subject: %s", user.Email, subject))
+	return nil
+}
+
 // SendUpcomingPayments sends a "payment due tomorrow" reminder to the user.
 func (s *EmailService) SendUpcomingPayments(ctx context.Context, user *models.User, payments []models.Payment) error {
 	if ok, err := s.checkEmailPreconditions(user, "upcoming payment email"); !ok {
@@ -601,6 +735,127 @@ func (s *EmailService) SendReservationApproved(ctx context.Context, contract *mo
 	return nil
 }
 
+// SendWaitlistLotAvailable tells a waitlisted customer (or their seller) that a lot freed up and
+// until when it is held for them.
+func (s *EmailService) SendWaitlistLotAvailable(ctx context.Context, recipient *models.User, entry *models.LotWaitlistEntry, lot *models.Lot, holdUntil time.Time) error {
+	if ok, err := s.checkEmailPreconditions(recipient, "waitlist lot available email"); !ok {
+		return err
+	}
+
+	data := struct {
+		Name         string
+		CustomerName string
+		ProjectName  string
+		LotName      string
+		Price        string
+		HoldUntil    string
+		AppURL       string
+	}{
+		Name:         recipient.FullName,
+		CustomerName: entry.CustomerName,
+		ProjectName:  lot.Project.Name,
+		LotName:      lot.Name,
+		Price:        fmt.Sprintf("L%.2f", lot.EffectivePrice()),
+		HoldUntil:    holdUntil.Format("02/01/2006 15:04"),
+		AppURL:       s.config.AppURL,
+	}
+
+	body, err := s.renderTemplate("waitlist_lot_available.html", data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to render waitlist_lot_available template: %v", err))
+		return err
+	}
+
+	params := &resend.SendEmailRequest{
+		From:    s.config.FromEmail,
+		To:      []string{recipient.Email},
+		Subject: "Lote disponible",
+		Html:    body,
+	}
+	_, err = s.resendClient.Emails.Send(params)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send email to %s: %v", recipient.Email, err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("📧 [Email Sent] To: %s | Subject: Lote disponible", recipient.Email))
+	return nil
+}
+
+// ScheduledReport is the output of a report subscription, emailed as an attachment
+type ScheduledReport struct {
+	Name        string
+	ReportType  string
+	Schedule    string
+	Period      string
+	Filename    string
+	ContentType string
+	Content     []byte
+	GeneratedAt time.Time
+}
+
+// SendScheduledReport emails a subscription's report to its recipients. Unlike the other emails it
+// fails when notifications are disabled, so the subscription records the report as not delivered.
+func (s *EmailService) SendScheduledReport(ctx context.Context, recipients []string, report ScheduledReport) error {
+	if !s.config.EnableEmailNotifications {
+		logger.Info(fmt.Sprintf("🚫 [Email Disabled] Skipping scheduled report %q", report.Name))
+		return fmt.Errorf("email notifications are disabled")
+	}
+	if err := s.ensureEmailConfigured(); err != nil {
+		return err
+	}
+	for _, email := range recipients {
+		if err := s.validateEmail(email); err != nil {
+			return err
+		}
+	}
+
+	data := struct {
+		Name        string
+		ReportType  string
+		Schedule    string
+		Period      string
+		Filename    string
+		GeneratedAt string
+		AppURL      string
+	}{
+		Name:        report.Name,
+		ReportType:  report.ReportType,
+		Schedule:    report.Schedule,
+		Period:      report.Period,
+		Filename:    report.Filename,
+		GeneratedAt: report.GeneratedAt.Format("02/01/2006 15:04"),
+		AppURL:      s.config.AppURL,
+	}
+
+	body, err := s.renderTemplate("scheduled_report.html", data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to render scheduled_report template: %v", err))
+		return err
+	}
+
+	subject := fmt.Sprintf("%s (%s)", report.Name, report.GeneratedAt.Format("02/01/2006"))
+	params := &resend.SendEmailRequest{
+		From:    s.config.FromEmail,
+		To:      recipients,
+		Subject: subject,
+		Html:    body,
+		Attachments: []*resend.Attachment{{
+			Content:     report.Content,
+			Filename:    report.Filename,
+			ContentType: report.ContentType,
+		}},
+	}
+	_, err = s.resendClient.Emails.Send(params)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to send email to %s: %v", strings.Join(recipients, ", "), err))
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("📧 [Email Sent] To: %s | Subject: %s", strings.Join(recipients, ", "), subject))
+	return nil
+}
+
 func (s *EmailService) renderTemplate(name string, data interface{}) (string, error) {
 	tmpl, err := template.ParseFS(emailTemplates, "templates/email/"+name)
 	if err != nil {
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Fiscal document errors
+var (
+	ErrInvalidFiscalRange     = errors.New("rango fiscal inválido")
+	ErrFiscalRangeUnavailable = errors.New("no hay un rango fiscal autorizado disponible")
+	ErrNothingToInvoice       = errors.New("el pago no tiene intereses ni cargos por facturar")
+)
+
+var (
+	caiPattern         = regexp.MustCompile(`^[0-9A-F]{6}(-[0-9A-F]{6}){4}-[0-9A-F]{2}$`)
+	fiscalCodePattern  = regexp.MustCompile(`^[0-9]{3}$`)
+	fiscalMaxSequence  = int64(99999999)
+	fiscalWarnInterval = 24 * time.Hour
+)
+
+// CreateFiscalRangeRequest is a range of invoice numbers authorized by the tax authority
+type CreateFiscalRangeRequest struct {
+	CAI           string `json:"cai" binding:"required"`
+	Establishment string `json:"establishment" binding:"required"`
+	PointOfSale   string `json:"point_of_sale" binding:"required"`
+	RangeStart    int64  `json:"range_start" binding:"required"`
+	RangeEnd      int64  `json:"range_end" binding:"required"`
+	Deadline      string `json:"deadline" binding:"required"` // fecha límite de emisión, YYYY-MM-DD
+}
+
+// FiscalService manages the authorized fiscal ranges and numbers invoices from them
+type FiscalService struct {
+	repo            repository.FiscalRepository
+	paymentRepo     repository.PaymentRepository
+	contractRepo    repository.ContractRepository
+	reportSvc       *ReportService
+	notificationSvc *NotificationService
+	auditSvc        *AuditService
+	storage         *storage.LocalStorage
+	establishment   string
+	pointOfSale     string
+	taxRate         float64
+	warnRemaining   int64
+	warnDays        int
+}
+
+func NewFiscalService(
+	repo repository.FiscalRepository,
+	paymentRepo repository.PaymentRepository,
+	contractRepo repository.ContractRepository,
+	reportSvc *ReportService,
+	notificationSvc *NotificationService,
+	auditSvc *AuditService,
+	storage *storage.LocalStorage,
+	cfg *config.Config,
+) *FiscalService {
+	return &FiscalService{
+		repo:            repo,
+		paymentRepo:     paymentRepo,
+		contractRepo:    contractRepo,
+		reportSvc:       reportSvc,
+		notificationSvc: notificationSvc,
+		auditSvc:        auditSvc,
+		storage:         storage,
+		establishment:   cfg.FiscalEstablishment,
+		pointOfSale:     cfg.FiscalPointOfSale,
+		taxRate:         float64(cfg.FiscalTaxRate) / 100,
+		warnRemaining:   int64(cfg.FiscalWarnRemaining),
+		warnDays:        cfg.FiscalWarnDays,
+	}
+}
+
+// CreateRange registers an authorized range. Its numbers must not overlap another range of the
+// same point of sale.
+func (s *FiscalService) CreateRange(ctx context.Context, req CreateFiscalRangeRequest, actorID uint) (*models.FiscalRange, error) {
+	cai := strings.ToUpper(strings.TrimSpace(req.CAI))
+	if !caiPattern.MatchString(cai) {
+		return nil, fmt.Errorf("%w: el CAI debe tener el formato XXXXXX-XXXXXX-XXXXXX-XXXXXX-XXXXXX-XX", ErrInvalidFiscalRange)
+	}
+	if !fiscalCodePattern.MatchString(req.Establishment) || !fiscalCodePattern.MatchString(req.PointOfSale) {
+		return nil, fmt.Errorf("%w: el establecimiento y el punto de emisión son de 3 dígitos", ErrInvalidFiscalRange)
+	}
+	if req.RangeStart < 1 || req.RangeEnd < req.RangeStart || req.RangeEnd > fiscalMaxSequence {
+		return nil, fmt.Errorf("%w: el rango debe estar entre 1 y %d y terminar después de su inicio", ErrInvalidFiscalRange, fiscalMaxSequence)
+	}
+	deadline, err := time.Parse("2006-01-02", req.Deadline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: la fecha límite de emisión debe tener el formato AAAA-MM-DD", ErrInvalidFiscalRange)
+	}
+
+	fiscalRange := &models.FiscalRange{
+		CAI:           cai,
+		Establishment: req.Establishment,
+		PointOfSale:   req.PointOfSale,
+		DocumentType:  models.FiscalDocumentInvoice,
+		RangeStart:    req.RangeStart,
+		RangeEnd:      req.RangeEnd,
+		NextNumber:    req.RangeStart,
+		Deadline:      deadline,
+		Active:        true,
+		CreatedByID:   actorID,
+	}
+	if fiscalRange.Expired(time.Now()) {
+		return nil, fmt.Errorf("%w: la fecha límite de emisión ya pasó", ErrInvalidFiscalRange)
+	}
+	existing, err := s.repo.ListRanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if r.CAI == cai {
+			return nil, fmt.Errorf("%w: el CAI ya está registrado", ErrDuplicate)
+		}
+		if r.Establishment == fiscalRange.Establishment && r.PointOfSale == fiscalRange.PointOfSale &&
+			r.DocumentType == fiscalRange.DocumentType && r.RangeStart <= fiscalRange.RangeEnd && r.RangeEnd >= fiscalRange.RangeStart {
+			return nil, fmt.Errorf("%w: el rango se superpone con el rango con CAI %s", ErrDuplicate, r.CAI)
+		}
+	}
+	if err := s.repo.CreateRange(ctx, fiscalRange); err != nil {
+		return nil, fmt.Errorf("failed to create fiscal range: %w", err)
+	}
+	s.audit(ctx, actorID, "FISCAL_RANGE_CREATE", "FiscalRange", fiscalRange.ID,
+		fmt.Sprintf("Rango %s al %s (CAI %s) autorizado hasta %s", fiscalRange.FormatNumber(fiscalRange.RangeStart),
+			fiscalRange.FormatNumber(fiscalRange.RangeEnd), fiscalRange.CAI, deadline.Format("02/01/2006")))
+	return fiscalRange, nil
+}
+
+// ListRanges returns every fiscal range, active ones first
+func (s *FiscalService) ListRanges(ctx context.Context) ([]models.FiscalRange, error) {
+	return s.repo.ListRanges(ctx)
+}
+
+// DeactivateRange stops issuing from a range, e.g. when its authorization is revoked
+func (s *FiscalService) DeactivateRange(ctx context.Context, id uint, actorID uint) error {
+	fiscalRange, err := s.repo.FindRange(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	deactivated, err := s.repo.Deactivate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deactivated {
+		return fmt.Errorf("%w: el rango ya está inactivo", ErrInvalidState)
+	}
+	s.audit(ctx, actorID, "FISCAL_RANGE_DEACTIVATE", "FiscalRange", id, fmt.Sprintf("Rango con CAI %s desactivado", fiscalRange.CAI))
+	return nil
+}
+
+// fiscalInvoiceLines returns the charges of an approved payment that are invoiced: the interest it
+// covers, exempt, and the amount of fee payments, taxed
+func fiscalInvoiceLines(payment *models.Payment) []models.FiscalInvoiceLine {
+	breakdown := paymentReceiptBreakdown(payment)
+	var lines []models.FiscalInvoiceLine
+	if breakdown.Interest > 0 {
+		lines = append(lines, models.FiscalInvoiceLine{
+			Concept:     models.FiscalConceptInterest,
+			Description: fmt.Sprintf("Intereses por mora - %s", getPaymentTypeDescription(payment.PaymentType)),
+			Amount:      roundCents(breakdown.Interest),
+		})
+	}
+	if payment.PaymentType == models.PaymentTypeFee && breakdown.Principal > 0 {
+		description := getPaymentTypeDescription(payment.PaymentType)
+		if payment.Description != nil && *payment.Description != "" {
+			description = *payment.Description
+		}
+		lines = append(lines, models.FiscalInvoiceLine{
+			Concept:     models.FiscalConceptFee,
+			Description: description,
+			Amount:      roundCents(breakdown.Principal),
+			Taxed:       true,
+		})
+	}
+	return lines
+}
+
+// fiscalTotals splits the lines into exempt and taxable amounts. Taxed lines include the tax.
+func fiscalTotals(lines []models.FiscalInvoiceLine, taxRate float64) (exempt, taxable, tax, total float64) {
+	taxedTotal := 0.0
+	for _, line := range lines {
+		if line.Taxed {
+			taxedTotal += line.Amount
+		} else {
+			exempt += line.Amount
+		}
+	}
+	taxable = roundCents(taxedTotal / (1 + taxRate))
+	tax = roundCents(taxedTotal - taxable)
+	exempt = roundCents(exempt)
+	return exempt, taxable, tax, roundCents(exempt + taxable + tax)
+}
+
+// InvoicePayment numbers an invoice for the interest and fees collected with an approved payment
+// (loaded with its contract and buyer). Issuance is refused, and admins notified, when the point of
+// sale has no range with numbers left before its deadline.
+func (s *FiscalService) InvoicePayment(ctx context.Context, payment *models.Payment, actorID uint) (*models.FiscalInvoice, error) {
+	if payment.Status != models.PaymentStatusPaid {
+		return nil, fmt.Errorf("%w: solo se facturan pagos aprobados", ErrInvalidState)
+	}
+	lines := fiscalInvoiceLines(payment)
+	if len(lines) == 0 {
+		return nil, ErrNothingToInvoice
+	}
+	if existing, err := s.repo.FindInvoiceByPayment(ctx, payment.ID); err == nil {
+		return nil, fmt.Errorf("%w: el pago ya tiene la factura %s", ErrInvalidState, existing.Number)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+	exempt, taxable, tax, total := fiscalTotals(lines, s.taxRate)
+	currency := payment.Contract.Currency
+	if currency == "" {
+		currency = "HNL"
+	}
+	paymentID := payment.ID
+	now := time.Now()
+	invoice := &models.FiscalInvoice{
+		ContractID:   payment.ContractID,
+		PaymentID:    &paymentID,
+		CustomerName: payment.Contract.ApplicantUser.FullName,
+		CustomerRTN:  payment.Contract.ApplicantUser.RTN,
+		Currency:     currency,
+		Lines:        encoded,
+		Exempt:       exempt,
+		Taxable:      taxable,
+		Tax:          tax,
+		Total:        total,
+		IssuedAt:     now,
+		IssuedByID:   actorID,
+	}
+	fiscalRange, err := s.repo.Allocate(ctx, invoice, s.establishment, s.pointOfSale, now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, s.refuse(ctx, payment.ID, now)
+		}
+		return nil, fmt.Errorf("failed to allocate invoice number: %w", err)
+	}
+	s.audit(ctx, actorID, "FISCAL_INVOICE_ISSUE", "Payment", payment.ID,
+		fmt.Sprintf("Factura %s (CAI %s) emitida por %.2f para el contrato #%d", invoice.Number, fiscalRange.CAI, total, payment.ContractID))
+	s.warnIfRunningOut(ctx, fiscalRange, now)
+	return invoice, nil
+}
+
+// InvoicePaymentByID issues the invoice of an approved payment, e.g. once a new range was loaded
+// after issuance was refused
+func (s *FiscalService) InvoicePaymentByID(ctx context.Context, paymentID uint, actorID uint) (*models.FiscalInvoice, error) {
+	payment, err := s.paymentRepo.FindByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	invoice, err := s.InvoicePayment(ctx, payment, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Render(ctx, invoice); err != nil {
+		logger.Error(fmt.Sprintf("[FiscalService] %v", err))
+	}
+	return invoice, nil
+}
+
+// VoidPaymentInvoice voids the current invoice of a payment, if any, e.g. when the payment is
+// undone. The number stays used; approving the payment again issues a new invoice.
+func (s *FiscalService) VoidPaymentInvoice(ctx context.Context, paymentID uint, actorID uint, reason string) (*models.FiscalInvoice, error) {
+	invoice, err := s.repo.FindInvoiceByPayment(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	now := time.Now()
+	voided, err := s.repo.VoidInvoice(ctx, invoice.ID, &actorID, reason, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to void invoice %s: %w", invoice.Number, err)
+	}
+	if !voided {
+		return nil, nil
+	}
+	// Re-read it: a render may have stored its issued document since it was loaded
+	if current, err := s.repo.FindInvoice(ctx, invoice.ID); err == nil {
+		invoice = current
+	} else {
+		invoice.VoidedAt, invoice.VoidedByID, invoice.VoidReason = &now, &actorID, &reason
+	}
+	if invoice.IssuedDocumentID != nil && s.reportSvc != nil {
+		if err := s.reportSvc.VoidIssuedDocument(ctx, *invoice.IssuedDocumentID); err != nil {
+			logger.Error(fmt.Sprintf("[FiscalService] Failed to void issued document of invoice %s: %v", invoice.Number, err))
+		}
+	}
+	s.audit(ctx, actorID, "FISCAL_INVOICE_VOID", "Payment", paymentID,
+		fmt.Sprintf("Factura %s anulada. Motivo: %s", invoice.Number, reason))
+	return invoice, nil
+}
+
+// refuse explains why no number could be allocated and tells the admins
+func (s *FiscalService) refuse(ctx context.Context, paymentID uint, now time.Time) error {
+	reason := fmt.Sprintf("no hay rangos autorizados para el punto de emisión %s-%s", s.establishment, s.pointOfSale)
+	if ranges, err := s.repo.FindRangesByPointOfSale(ctx, s.establishment, s.pointOfSale, models.FiscalDocumentInvoice); err == nil {
+		for i := range ranges {
+			if !ranges[i].Active {
+				continue
+			}
+			if ranges[i].Expired(now) {
+				reason = fmt.Sprintf("el rango con CAI %s venció el %s", ranges[i].CAI, ranges[i].Deadline.Format("02/01/2006"))
+			} else if ranges[i].Remaining() == 0 {
+				reason = fmt.Sprintf("el rango con CAI %s está agotado", ranges[i].CAI)
+			}
+		}
+	}
+	if s.notificationSvc != nil {
+		if err := s.notificationSvc.NotifyAdmins(ctx, "Facturación detenida",
+			fmt.Sprintf("No se pudo facturar el pago #%d: %s. Registre un nuevo rango autorizado y emita la factura del pago.", paymentID, reason),
+			models.NotificationTypeFiscalRange); err != nil {
+			logger.Error(fmt.Sprintf("[FiscalService] Failed to notify admins: %v", err))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrFiscalRangeUnavailable, reason)
+}
+
+// rangeWarning returns why admins should be warned about a range, or "" when it is healthy
+func (s *FiscalService) rangeWarning(fiscalRange *models.FiscalRange, now time.Time) string {
+	switch {
+	case fiscalRange.Expired(now):
+		return fmt.Sprintf("El rango con CAI %s venció el %s", fiscalRange.CAI, fiscalRange.Deadline.Format("02/01/2006"))
+	case fiscalRange.Remaining() == 0:
+		return fmt.Sprintf("El rango con CAI %s está agotado", fiscalRange.CAI)
+	case fiscalRange.Remaining() <= s.warnRemaining:
+		return fmt.Sprintf("Quedan %d facturas en el rango con CAI %s (hasta %s)",
+			fiscalRange.Remaining(), fiscalRange.CAI, fiscalRange.FormatNumber(fiscalRange.RangeEnd))
+	case fiscalRange.Deadline.Before(now.AddDate(0, 0, s.warnDays)):
+		return fmt.Sprintf("El rango con CAI %s vence el %s", fiscalRange.CAI, fiscalRange.Deadline.Format("02/01/2006"))
+	}
+	return ""
+}
+
+// warnIfRunningOut tells the admins a range is running out, at most once a day per range
+func (s *FiscalService) warnIfRunningOut(ctx context.Context, fiscalRange *models.FiscalRange, now time.Time) {
+	if !fiscalRange.Active || (fiscalRange.WarnedAt != nil && now.Sub(*fiscalRange.WarnedAt) < fiscalWarnInterval) {
+		return
+	}
+	warning := s.rangeWarning(fiscalRange, now)
+	if warning == "" || s.notificationSvc == nil {
+		return
+	}
+	if err := s.notificationSvc.NotifyAdmins(ctx, "Rango de facturación por agotarse",
+		warning+". Solicite un nuevo rango autorizado a tiempo.", models.NotificationTypeFiscalRange); err != nil {
+		logger.Error(fmt.Sprintf("[FiscalService] Failed to notify admins: %v", err))
+		return
+	}
+	if err := s.repo.MarkWarned(ctx, fiscalRange.ID, now); err != nil {
+		logger.Error(fmt.Sprintf("[FiscalService] Failed to mark range %d warned: %v", fiscalRange.ID, err))
+	}
+}
+
+// WarnRunningOut warns the admins about active ranges close to exhaustion or to their deadline
+func (s *FiscalService) WarnRunningOut(ctx context.Context) error {
+	ranges, err := s.repo.ListRanges(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range ranges {
+		s.warnIfRunningOut(ctx, &ranges[i], now)
+	}
+	return nil
+}
+
+// Render returns the PDF of an invoice, generating and storing it the first time. Voided invoices
+// that were never rendered are not generated anymore.
+func (s *FiscalService) Render(ctx context.Context, invoice *models.FiscalInvoice) ([]byte, error) {
+	if invoice.Path != nil {
+		fullPath, err := s.storage.SafeFullPath(*invoice.Path)
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(fullPath)
+	}
+	if invoice.VoidedAt != nil {
+		return nil, fmt.Errorf("%w: la factura %s fue anulada", ErrInvalidState, invoice.Number)
+	}
+
+	pdf, document, err := s.reportSvc.GenerateInvoicePDF(ctx, invoice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice %s: %w", invoice.Number, err)
+	}
+	path, err := s.storage.UploadFromBytes(pdf.Bytes(), invoice.Number+".pdf", fmt.Sprintf("invoices/%d", invoice.ContractID))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256Hex(pdf.Bytes())
+	var documentID *uint
+	if document != nil {
+		documentID = &document.ID
+	}
+	if err := s.repo.SetInvoiceDocument(ctx, invoice.ID, path, hash, documentID); err != nil {
+		return nil, fmt.Errorf("failed to store invoice %s: %w", invoice.Number, err)
+	}
+	invoice.Path, invoice.SHA256, invoice.IssuedDocumentID = &path, &hash, documentID
+	return pdf.Bytes(), nil
+}
+
+// ListInvoices returns the invoices of a contract
+func (s *FiscalService) ListInvoices(ctx context.Context, contractID uint, actor DocumentActor) ([]models.FiscalInvoice, error) {
+	if err := s.authorize(ctx, contractID, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvoices(ctx, contractID)
+}
+
+// Download returns an invoice of a contract with its PDF
+func (s *FiscalService) Download(ctx context.Context, contractID, invoiceID uint, actor DocumentActor) (*models.FiscalInvoice, []byte, error) {
+	if err := s.authorize(ctx, contractID, actor); err != nil {
+		return nil, nil, err
+	}
+	invoice, err := s.repo.FindInvoice(ctx, invoiceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if invoice.ContractID != contractID {
+		return nil, nil, ErrNotFound
+	}
+	pdf, err := s.Render(ctx, invoice)
+	if err != nil {
+		return nil, nil, err
+	}
+	return invoice, pdf, nil
+}
+
+// authorize checks the actor can see the invoices of the contract: admins and sellers, or the buyer
+// (applicant or co-buyer)
+func (s *FiscalService) authorize(ctx context.Context, contractID uint, actor DocumentActor) error {
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !actor.isStaff() && !contract.IsOwnedBy(actor.UserID) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func (s *FiscalService) audit(ctx context.Context, userID uint, action, entity string, entityID uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, entity, entityID, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[FiscalService] Failed to audit %s for %s %d: %v", action, entity, entityID, err))
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockFiscalRepository struct {
+	ranges   []*models.FiscalRange
+	invoices []*models.FiscalInvoice
+}
+
+func (m *mockFiscalRepository) CreateRange(ctx context.Context, fiscalRange *models.FiscalRange) error {
+	fiscalRange.ID = uint(len(m.ranges) + 1)
+	m.ranges = append(m.ranges, fiscalRange)
+	return nil
+}
+
+func (m *mockFiscalRepository) FindRange(ctx context.Context, id uint) (*models.FiscalRange, error) {
+	for _, r := range m.ranges {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockFiscalRepository) ListRanges(ctx context.Context) ([]models.FiscalRange, error) {
+	ranges := make([]models.FiscalRange, len(m.ranges))
+	for i, r := range m.ranges {
+		ranges[i] = *r
+	}
+	return ranges, nil
+}
+
+func (m *mockFiscalRepository) FindRangesByPointOfSale(ctx context.Context, establishment, pointOfSale, documentType string) ([]models.FiscalRange, error) {
+	return m.ListRanges(ctx)
+}
+
+func (m *mockFiscalRepository) Deactivate(ctx context.Context, id uint) (bool, error) {
+	r, err := m.FindRange(ctx, id)
+	if err != nil || !r.Active {
+		return false, err
+	}
+	r.Active = false
+	return true, nil
+}
+
+func (m *mockFiscalRepository) MarkWarned(ctx context.Context, id uint, at time.Time) error {
+	r, err := m.FindRange(ctx, id)
+	if err == nil {
+		r.WarnedAt = &at
+	}
+	return err
+}
+
+func (m *mockFiscalRepository) Allocate(ctx context.Context, invoice *models.FiscalInvoice, establishment, pointOfSale string, now time.Time) (*models.FiscalRange, error) {
+	for _, r := range m.ranges {
+		if r.Active && r.Remaining() > 0 && !r.Expired(now) {
+			invoice.FiscalRangeID = r.ID
+			invoice.Number = r.FormatNumber(r.NextNumber)
+			r.NextNumber++
+			invoice.ID = uint(len(m.invoices) + 1)
+			invoice.FiscalRange = *r
+			m.invoices = append(m.invoices, invoice)
+			return r, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockFiscalRepository) FindInvoice(ctx context.Context, id uint) (*models.FiscalInvoice, error) {
+	for _, invoice := range m.invoices {
+		if invoice.ID == id {
+			return invoice, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockFiscalRepository) FindInvoiceByPayment(ctx context.Context, paymentID uint) (*models.FiscalInvoice, error) {
+	for _, invoice := range m.invoices {
+		if invoice.PaymentID != nil && *invoice.PaymentID == paymentID && invoice.VoidedAt == nil {
+			return invoice, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockFiscalRepository) ListInvoices(ctx context.Context, contractID uint) ([]models.FiscalInvoice, error) {
+	return nil, nil
+}
+
+func (m *mockFiscalRepository) SetInvoiceDocument(ctx context.Context, id uint, path, sha256 string, issuedDocumentID *uint) error {
+	return nil
+}
+
+func (m *mockFiscalRepository) VoidInvoice(ctx context.Context, id uint, voidedByID *uint, reason string, at time.Time) (bool, error) {
+	invoice, err := m.FindInvoice(ctx, id)
+	if err != nil || invoice.VoidedAt != nil {
+		return false, err
+	}
+	invoice.VoidedAt, invoice.VoidedByID, invoice.VoidReason = &at, voidedByID, &reason
+	return true, nil
+}
+
+func newTestFiscalService(repo *mockFiscalRepository) *FiscalService {
+	return NewFiscalService(repo, nil, nil, nil, nil, nil, nil, &config.Config{
+		FiscalEstablishment: "000", FiscalPointOfSale: "001", FiscalTaxRate: 15, FiscalWarnRemaining: 2, FiscalWarnDays: 30,
+	})
+}
+
+func TestFiscalInvoiceTotals(t *testing.T) {
+	interest := 80.0
+	paid := 1230.0
+	desc := "Cargo por cesión de contrato"
+	payment := &models.Payment{Amount: 1150, InterestAmount: &interest, PaidAmount: &paid, PaymentType: models.PaymentTypeFee, Description: &desc}
+
+	lines := fiscalInvoiceLines(payment)
+	require.Len(t, lines, 2)
+	assert.Equal(t, models.FiscalConceptInterest, lines[0].Concept)
+	assert.False(t, lines[0].Taxed)
+	assert.Equal(t, desc, lines[1].Description)
+	assert.True(t, lines[1].Taxed)
+
+	exempt, taxable, tax, total := fiscalTotals(lines, 0.15)
+	assert.Equal(t, 80.0, exempt)
+	assert.Equal(t, 1000.0, taxable)
+	assert.Equal(t, 150.0, tax)
+	assert.Equal(t, 1230.0, total)
+
+	// Installments paid on time have nothing to invoice
+	payment = &models.Payment{Amount: 1000, PaidAmount: &paid, PaymentType: models.PaymentTypeInstallment}
+	assert.Empty(t, fiscalInvoiceLines(payment))
+}
+
+func TestInvoicePaymentAllocatesUntilRangeIsExhausted(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockFiscalRepository{}
+	svc := newTestFiscalService(repo)
+
+	_, err := svc.CreateRange(ctx, CreateFiscalRangeRequest{
+		CAI: "35bd6a-0195f4-b34baa-8b7d13-37f5e8-0b", Establishment: "000", PointOfSale: "001",
+		RangeStart: 41, RangeEnd: 42, Deadline: time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+	}, 1)
+	require.NoError(t, err)
+
+	interest := 50.0
+	paid := 1050.0
+	newPayment := func(id uint) *models.Payment {
+		return &models.Payment{ID: id, ContractID: 9, Amount: 1000, InterestAmount: &interest, PaidAmount: &paid, Status: models.PaymentStatusPaid}
+	}
+
+	invoice, err := svc.InvoicePayment(ctx, newPayment(1), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "000-001-01-00000041", invoice.Number)
+	assert.Equal(t, 50.0, invoice.Total)
+
+	_, err = svc.InvoicePayment(ctx, newPayment(1), 1)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	invoice, err = svc.InvoicePayment(ctx, newPayment(2), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "000-001-01-00000042", invoice.Number)
+
+	_, err = svc.InvoicePayment(ctx, newPayment(3), 1)
+	assert.ErrorIs(t, err, ErrFiscalRangeUnavailable)
+	assert.Contains(t, err.Error(), "agotado")
+}
+
+func TestVoidPaymentInvoiceAllowsReinvoicing(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockFiscalRepository{}
+	svc := newTestFiscalService(repo)
+
+	_, err := svc.CreateRange(ctx, CreateFiscalRangeRequest{
+		CAI: "35bd6a-0195f4-b34baa-8b7d13-37f5e8-0b", Establishment: "000", PointOfSale: "001",
+		RangeStart: 1, RangeEnd: 10, Deadline: time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+	}, 1)
+	require.NoError(t, err)
+
+	interest := 50.0
+	paid := 1050.0
+	payment := &models.Payment{ID: 1, ContractID: 9, Amount: 1000, InterestAmount: &interest, PaidAmount: &paid, Status: models.PaymentStatusPaid}
+	first, err := svc.InvoicePayment(ctx, payment, 1)
+	require.NoError(t, err)
+
+	// Undoing the payment voids the invoice; its number stays used
+	voided, err := svc.VoidPaymentInvoice(ctx, payment.ID, 1, "Pago revertido")
+	require.NoError(t, err)
+	require.NotNil(t, voided)
+	assert.NotNil(t, first.VoidedAt)
+	_, err = svc.Render(ctx, first)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	voided, err = svc.VoidPaymentInvoice(ctx, payment.ID, 1, "Pago revertido")
+	require.NoError(t, err)
+	assert.Nil(t, voided)
+
+	// Approving it again issues a new number
+	second, err := svc.InvoicePayment(ctx, payment, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "000-001-01-00000002", second.Number)
+	assert.Nil(t, second.VoidedAt)
+}
+
+func TestCreateFiscalRangeValidation(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockFiscalRepository{}
+	svc := newTestFiscalService(repo)
+	deadline := time.Now().AddDate(0, 6, 0).Format("2006-01-02")
+
+	req := CreateFiscalRangeRequest{CAI: "35BD6A-0195F4-B34BAA-8B7D13-37F5E8-0B", Establishment: "000", PointOfSale: "001", RangeStart: 1, RangeEnd: 500, Deadline: deadline}
+	_, err := svc.CreateRange(ctx, req, 1)
+	require.NoError(t, err)
+
+	overlapping := req
+	overlapping.CAI = "35BD6A-0195F4-B34BAA-8B7D13-37F5E8-0C"
+	overlapping.RangeStart, overlapping.RangeEnd = 500, 900
+	_, err = svc.CreateRange(ctx, overlapping, 1)
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	invalid := req
+	invalid.CAI = "not-a-cai"
+	_, err = svc.CreateRange(ctx, invalid, 1)
+	assert.ErrorIs(t, err, ErrInvalidFiscalRange)
+
+	expired := overlapping
+	expired.RangeStart, expired.RangeEnd = 501, 900
+	expired.Deadline = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	_, err = svc.CreateRange(ctx, expired, 1)
+	assert.ErrorIs(t, err, ErrInvalidFiscalRange)
+}
+
+func TestFiscalRangeWarning(t *testing.T) {
+	svc := newTestFiscalService(&mockFiscalRepository{})
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	fiscalRange := &models.FiscalRange{CAI: "X", RangeStart: 1, RangeEnd: 100, NextNumber: 10, Deadline: now.AddDate(0, 3, 0), Active: true}
+	assert.Empty(t, svc.rangeWarning(fiscalRange, now))
+
+	fiscalRange.NextNumber = 99
+	assert.Contains(t, svc.rangeWarning(fiscalRange, now), "Quedan 2 facturas")
+
+	fiscalRange.NextNumber = 10
+	fiscalRange.Deadline = now.AddDate(0, 0, 10)
+	assert.Contains(t, svc.rangeWarning(fiscalRange, now), "vence el")
+
+	// The deadline day itself is still usable
+	fiscalRange.Deadline = time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	assert.False(t, fiscalRange.Expired(now))
+	assert.True(t, fiscalRange.Expired(now.AddDate(0, 0, 1)))
+}
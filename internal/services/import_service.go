@@ -0,0 +1,857 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrImportHasErrors is returned when a (non dry-run) import has invalid rows; nothing is written.
+var ErrImportHasErrors = errors.New("el archivo tiene filas con errores; no se importó ningún registro")
+
+// Import row actions
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+	ImportActionError     = "error"
+)
+
+// importColumnAliases maps accepted header names (normalized) to canonical column keys
+var importColumnAliases = map[string]string{
+	"project": "project", "proyecto": "project",
+	"project_description": "project_description", "descripcion_proyecto": "project_description",
+	"project_address": "project_address", "direccion_proyecto": "project_address",
+	"price_per_square_unit": "price_per_square_unit", "precio_por_unidad": "price_per_square_unit",
+	"interest_rate": "interest_rate", "tasa_interes": "interest_rate",
+	"name": "name", "lot": "name", "lote": "name", "nombre": "name",
+	"registration_number": "registration_number", "numero_registro": "registration_number", "registro": "registration_number",
+	"length": "length", "largo": "length",
+	"width": "width", "ancho": "width",
+	"area":  "area",
+	"price": "price", "precio": "price",
+	"override_price": "override_price", "precio_especial": "override_price",
+	"measurement_unit": "measurement_unit", "unidad": "measurement_unit",
+	"address": "address", "direccion": "address",
+	"note": "note", "nota": "note",
+	"north": "north", "norte": "north",
+	"south": "south", "sur": "south",
+	"east": "east", "este": "east",
+	"west": "west", "oeste": "west",
+	"geometry": "geometry", "geometria": "geometry", "wkt": "geometry", "geojson": "geometry",
+}
+
+// ImportRowResult is the validation/outcome of one file row
+type ImportRowResult struct {
+	Row                int      `json:"row"`
+	Project            string   `json:"project,omitempty"`
+	LotName            string   `json:"lot_name"`
+	RegistrationNumber string   `json:"registration_number"`
+	Action             string   `json:"action"`
+	LotID              uint     `json:"lot_id,omitempty"`
+	Errors             []string `json:"errors,omitempty"`
+}
+
+// ImportResult is the report of a lot/project import
+type ImportResult struct {
+	DryRun          bool              `json:"dry_run"`
+	FileName        string            `json:"file_name"`
+	TotalRows       int               `json:"total_rows"`
+	Created         int               `json:"created"`
+	Updated         int               `json:"updated"`
+	Unchanged       int               `json:"unchanged"`
+	Failed          int               `json:"failed"`
+	ProjectsCreated []string          `json:"projects_created,omitempty"`
+	Rows            []ImportRowResult `json:"rows"`
+}
+
+// importLotRow is a parsed lot row
+type importLotRow struct {
+	line               int
+	project            string
+	name               string
+	registrationNumber string
+	length             float64
+	width              float64
+	area               *float64
+	price              *float64
+	overridePrice      *float64
+	measurementUnit    *string
+	address            *string
+	note               *string
+	north              *string
+	south              *string
+	east               *string
+	west               *string
+	errors             []string
+
+	// project columns (project import only)
+	projectDescription string
+	projectAddress     string
+	pricePerUnit       float64
+	interestRate       float64
+}
+
+// ImportService imports lots (and projects) from CSV/XLSX files with upsert by registration number
+type ImportService struct {
+	repo        repository.ImportRepository
+	projectRepo repository.ProjectRepository
+	lotRepo     repository.LotRepository
+	auditSvc    *AuditService
+}
+
+func NewImportService(repo repository.ImportRepository, projectRepo repository.ProjectRepository, lotRepo repository.LotRepository, auditSvc *AuditService) *ImportService {
+	return &ImportService{repo: repo, projectRepo: projectRepo, lotRepo: lotRepo, auditSvc: auditSvc}
+}
+
+// importPlan is the set of validated changes for one project
+type importPlan struct {
+	project  *models.Project
+	isNew    bool
+	existing map[string]*models.Lot // by registration number
+	rows     []*importLotRow
+	results  []*ImportRowResult
+}
+
+// ImportLots imports the lots of a file into an existing project
+func (s *ImportService) ImportLots(ctx context.Context, projectID uint, r io.Reader, fileName string, dryRun bool, actorID uint) (*ImportResult, error) {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("proyecto no encontrado")
+	}
+	rows, err := readImportRows(r, fileName)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.newPlan(ctx, project, false)
+	if err != nil {
+		return nil, err
+	}
+	plan.rows = rows
+	return s.run(ctx, []*importPlan{plan}, fileName, dryRun, actorID)
+}
+
+// ImportProjects imports a file with a project column; missing projects are created with the
+// project_* columns of their first row.
+func (s *ImportService) ImportProjects(ctx context.Context, r io.Reader, fileName string, dryRun bool, actorID uint) (*ImportResult, error) {
+	rows, err := readImportRows(r, fileName)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.projectRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+	byName := make(map[string]*models.Project, len(projects))
+	for i := range projects {
+		byName[strings.ToLower(strings.TrimSpace(projects[i].Name))] = &projects[i]
+	}
+
+	var plans []*importPlan
+	planByName := map[string]*importPlan{}
+	var orphans []*importLotRow
+	for _, row := range rows {
+		key := strings.ToLower(row.project)
+		if key == "" {
+			row.errors = append(row.errors, "proyecto requerido")
+			orphans = append(orphans, row)
+			continue
+		}
+		plan, ok := planByName[key]
+		if !ok {
+			project, exists := byName[key]
+			if !exists {
+				project = &models.Project{
+					Name:               row.project,
+					Description:        row.projectDescription,
+					Address:            row.projectAddress,
+					PricePerSquareUnit: row.pricePerUnit,
+					InterestRate:       row.interestRate,
+					MeasurementUnit:    "m2",
+					Status:             models.ProjectStatusDraft,
+				}
+				if row.measurementUnit != nil {
+					project.MeasurementUnit = *row.measurementUnit
+				}
+			}
+			if plan, err = s.newPlan(ctx, project, !exists); err != nil {
+				return nil, err
+			}
+			planByName[key] = plan
+			plans = append(plans, plan)
+		}
+		plan.rows = append(plan.rows, row)
+	}
+	if len(orphans) > 0 {
+		plans = append(plans, &importPlan{rows: orphans})
+	}
+	return s.run(ctx, plans, fileName, dryRun, actorID)
+}
+
+func (s *ImportService) newPlan(ctx context.Context, project *models.Project, isNew bool) (*importPlan, error) {
+	plan := &importPlan{project: project, isNew: isNew, existing: map[string]*models.Lot{}}
+	if isNew {
+		return plan, nil
+	}
+	lots, err := s.lotRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lots: %w", err)
+	}
+	for i := range lots {
+		if lots[i].RegistrationNumber != nil && *lots[i].RegistrationNumber != "" {
+			plan.existing[strings.ToLower(*lots[i].RegistrationNumber)] = &lots[i]
+		}
+	}
+	return plan, nil
+}
+
+// run validates every row and, unless dry-run or invalid, writes the changes
+func (s *ImportService) run(ctx context.Context, plans []*importPlan, fileName string, dryRun bool, actorID uint) (*ImportResult, error) {
+	result := &ImportResult{DryRun: dryRun, FileName: fileName}
+	for _, plan := range plans {
+		if plan.isNew {
+			result.ProjectsCreated = append(result.ProjectsCreated, plan.project.Name)
+		}
+		plan.validate()
+		for _, res := range plan.results {
+			result.TotalRows++
+			switch res.Action {
+			case ImportActionCreate:
+				result.Created++
+			case ImportActionUpdate:
+				result.Updated++
+			case ImportActionUnchanged:
+				result.Unchanged++
+			default:
+				result.Failed++
+			}
+		}
+	}
+
+	collect := func() {
+		result.Rows = result.Rows[:0]
+		for _, plan := range plans {
+			for _, res := range plan.results {
+				result.Rows = append(result.Rows, *res)
+			}
+		}
+	}
+	collect()
+	if dryRun {
+		return result, nil
+	}
+	if result.Failed > 0 {
+		return result, ErrImportHasErrors
+	}
+
+	if err := s.apply(ctx, plans, fileName, actorID); err != nil {
+		collect()
+		return result, err
+	}
+	collect()
+	return result, nil
+}
+
+// validate checks each row of the plan and decides create/update/unchanged
+func (p *importPlan) validate() {
+	seen := map[string]int{}
+	for _, row := range p.rows {
+		res := &ImportRowResult{
+			Row:                row.line,
+			Project:            row.project,
+			LotName:            row.name,
+			RegistrationNumber: row.registrationNumber,
+		}
+		p.results = append(p.results, res)
+		errs := append([]string{}, row.errors...)
+
+		if p.project == nil {
+			res.Action, res.Errors = ImportActionError, errs
+			continue
+		}
+		if p.isNew && strings.TrimSpace(p.project.Address) == "" {
+			errs = append(errs, "dirección del proyecto requerida para crear el proyecto")
+		}
+		if row.registrationNumber == "" {
+			errs = append(errs, "número de registro requerido")
+		} else if first, dup := seen[strings.ToLower(row.registrationNumber)]; dup {
+			errs = append(errs, fmt.Sprintf("número de registro duplicado (fila %d)", first))
+		} else {
+			seen[strings.ToLower(row.registrationNumber)] = row.line
+		}
+		if row.name == "" {
+			errs = append(errs, "nombre del lote requerido")
+		}
+		if row.length <= 0 || row.width <= 0 {
+			errs = append(errs, "largo y ancho deben ser mayores a cero")
+		}
+		if row.price == nil && p.project.PricePerSquareUnit <= 0 {
+			errs = append(errs, "precio requerido (el proyecto no tiene precio por unidad)")
+		}
+		if row.price != nil && *row.price < 0 {
+			errs = append(errs, "el precio no puede ser negativo")
+		}
+
+		existing := p.existing[strings.ToLower(row.registrationNumber)]
+		if existing != nil && existing.Status != models.LotStatusAvailable {
+			candidate := *existing
+			applyImportRow(&candidate, row, p.project)
+			if candidate.Price != existing.Price || !floatPtrEqual(candidate.OverridePrice, existing.OverridePrice) ||
+				candidate.Area() != existing.Area() {
+				errs = append(errs, fmt.Sprintf("no se puede cambiar precio o medidas de un lote en estado %s", existing.Status))
+			}
+		}
+
+		if len(errs) > 0 {
+			res.Action, res.Errors = ImportActionError, errs
+			continue
+		}
+		switch {
+		case existing == nil:
+			res.Action = ImportActionCreate
+		default:
+			res.LotID = existing.ID
+			candidate := *existing
+			if applyImportRow(&candidate, row, p.project) {
+				res.Action = ImportActionUpdate
+			} else {
+				res.Action = ImportActionUnchanged
+			}
+		}
+	}
+}
+
+// apply writes the validated plans in one transaction, so a failing row leaves nothing
+// imported, and then records the audit trail
+func (s *ImportService) apply(ctx context.Context, plans []*importPlan, fileName string, actorID uint) error {
+	reason := fmt.Sprintf("Importación de lotes: %s", fileName)
+	batch := &repository.ImportBatch{}
+	results := map[int]*ImportRowResult{} // by file row
+	for _, plan := range plans {
+		for _, res := range plan.results {
+			results[res.Row] = res
+		}
+		project := plan.project
+		if plan.isNew {
+			project.GUID = uuid.New().String()
+		}
+		item := repository.ImportProject{Project: project, IsNew: plan.isNew}
+		for i, row := range plan.rows {
+			switch plan.results[i].Action {
+			case ImportActionCreate:
+				lot := &models.Lot{ProjectID: project.ID, Status: models.LotStatusAvailable}
+				applyImportRow(lot, row, project)
+				item.Lots = append(item.Lots, repository.ImportLot{Row: row.line, Lot: lot})
+			case ImportActionUpdate:
+				existing := plan.existing[strings.ToLower(row.registrationNumber)]
+				lot := *existing
+				applyImportRow(&lot, row, project)
+				var history []models.LotPriceHistory
+				if entry := newManualPriceHistory(lot.ID, models.PriceFieldPrice, existing.Price, lot.Price, actorID, reason); entry != nil {
+					history = append(history, *entry)
+				}
+				if entry := newManualPriceHistory(lot.ID, models.PriceFieldOverridePrice, floatOrZero(existing.OverridePrice), floatOrZero(lot.OverridePrice), actorID, reason); entry != nil {
+					history = append(history, *entry)
+				}
+				item.Lots = append(item.Lots, repository.ImportLot{Row: row.line, Lot: &lot, History: history})
+			}
+		}
+		batch.Projects = append(batch.Projects, item)
+	}
+
+	if err := s.repo.Apply(ctx, batch); err != nil {
+		var rowErr *repository.ImportRowError
+		if errors.As(err, &rowErr) {
+			if res := results[rowErr.Row]; res != nil {
+				res.Action, res.Errors = ImportActionError, []string{rowErr.Err.Error()}
+			}
+			return fmt.Errorf("failed to import lot (row %d): %w", rowErr.Row, rowErr.Err)
+		}
+		return fmt.Errorf("failed to import lots: %w", err)
+	}
+
+	for pi, plan := range plans {
+		created, updated := 0, 0
+		for _, item := range batch.Projects[pi].Lots {
+			res := results[item.Row]
+			res.LotID = item.Lot.ID
+			if res.Action == ImportActionCreate {
+				created++
+			} else {
+				updated++
+			}
+		}
+		if plan.isNew {
+			if err := s.auditSvc.Log(ctx, actorID, "CREATE", "Project", plan.project.ID,
+				fmt.Sprintf("Proyecto creado por importación (%s): %s", fileName, plan.project.Name), "", ""); err != nil {
+				return err
+			}
+		}
+		if err := s.auditSvc.Log(ctx, actorID, "IMPORT", "Project", plan.project.ID,
+			fmt.Sprintf("Importación de lotes (%s): %d creados, %d actualizados, %d sin cambios", fileName, created, updated, len(plan.rows)-created-updated), "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Geometry loader match keys
+const (
+	GeometryMatchByRegistrationNumber = "registration_number"
+	GeometryMatchByName               = "name"
+)
+
+// geometryInput is one polygon read from a geometry file
+type geometryInput struct {
+	line      int
+	key       string
+	isProject bool
+	raw       string
+	err       string
+}
+
+// ImportGeometries bulk loads lot polygons into a project from a GeoJSON FeatureCollection
+// (.geojson/.json; the match key is read from feature properties, and a feature with
+// properties.kind = "project" sets the project boundary) or a CSV/XLSX with a geometry
+// column holding GeoJSON or WKT. Lots are matched by registration number or name.
+func (s *ImportService) ImportGeometries(ctx context.Context, projectID uint, r io.Reader, fileName, matchBy string, dryRun bool, actorID uint) (*ImportResult, error) {
+	if matchBy == "" {
+		matchBy = GeometryMatchByRegistrationNumber
+	}
+	if matchBy != GeometryMatchByRegistrationNumber && matchBy != GeometryMatchByName {
+		return nil, fmt.Errorf("match_by inválido: %s (registration_number, name)", matchBy)
+	}
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("proyecto no encontrado")
+	}
+
+	var inputs []geometryInput
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".geojson", ".json":
+		inputs, err = readGeometryFeatures(r, matchBy)
+	default:
+		inputs, err = readGeometryRecords(r, fileName, matchBy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lots, err := s.lotRepo.FindByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lots: %w", err)
+	}
+	byKey := make(map[string]*models.Lot, len(lots))
+	for i := range lots {
+		key := lots[i].Name
+		if matchBy == GeometryMatchByRegistrationNumber {
+			key = ""
+			if lots[i].RegistrationNumber != nil {
+				key = *lots[i].RegistrationNumber
+			}
+		}
+		if key != "" {
+			byKey[strings.ToLower(key)] = &lots[i]
+		}
+	}
+
+	result := &ImportResult{DryRun: dryRun, FileName: fileName}
+	var projectGeometry *models.Geometry
+	updates := map[int]*models.Lot{}
+	seen := map[string]int{}
+	for i, in := range inputs {
+		res := ImportRowResult{Row: in.line}
+		var errs []string
+		if in.err != "" {
+			errs = append(errs, in.err)
+		}
+		g := models.Geometry(in.raw)
+		if in.raw == "" {
+			errs = append(errs, "geometría requerida")
+		} else if err := normalizeGeometry(&g); err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		switch {
+		case in.isProject:
+			res.Project = project.Name
+			if len(errs) == 0 {
+				projectGeometry = &g
+				res.Action = ImportActionUpdate
+				if project.Geometry != nil && *project.Geometry == g {
+					res.Action = ImportActionUnchanged
+				}
+			}
+		default:
+			if matchBy == GeometryMatchByName {
+				res.LotName = in.key
+			} else {
+				res.RegistrationNumber = in.key
+			}
+			lot := byKey[strings.ToLower(in.key)]
+			if in.key == "" {
+				errs = append(errs, fmt.Sprintf("falta %s para identificar el lote", matchBy))
+			} else if first, dup := seen[strings.ToLower(in.key)]; dup {
+				errs = append(errs, fmt.Sprintf("lote repetido (fila %d)", first))
+			} else if lot == nil {
+				errs = append(errs, "lote no encontrado en el proyecto")
+			}
+			seen[strings.ToLower(in.key)] = in.line
+			if len(errs) == 0 {
+				res.LotID, res.LotName = lot.ID, lot.Name
+				if lot.Geometry != nil && *lot.Geometry == g {
+					res.Action = ImportActionUnchanged
+				} else {
+					updated := *lot
+					updated.Geometry = &g
+					if err := applyLotGeometry(&updated, project); err != nil {
+						errs = append(errs, err.Error())
+					} else {
+						updates[i] = &updated
+						res.Action = ImportActionUpdate
+					}
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			res.Action, res.Errors = ImportActionError, errs
+		}
+		switch res.Action {
+		case ImportActionUpdate:
+			result.Updated++
+		case ImportActionUnchanged:
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+		result.TotalRows++
+		result.Rows = append(result.Rows, res)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if result.Failed > 0 {
+		return result, ErrImportHasErrors
+	}
+
+	if projectGeometry != nil && (project.Geometry == nil || *project.Geometry != *projectGeometry) {
+		project.Geometry = projectGeometry
+		project.Lots = nil
+		if err := s.projectRepo.Update(ctx, project); err != nil {
+			return result, fmt.Errorf("failed to update project geometry: %w", err)
+		}
+	}
+	for i := range inputs {
+		lot, ok := updates[i]
+		if !ok {
+			continue
+		}
+		if err := s.lotRepo.Update(ctx, lot); err != nil {
+			return result, fmt.Errorf("failed to update lot geometry (row %d): %w", inputs[i].line, err)
+		}
+	}
+
+	if err := s.auditSvc.Log(ctx, actorID, "IMPORT", "Project", project.ID,
+		fmt.Sprintf("Carga de geometrías (%s): %d actualizadas, %d sin cambios", fileName, result.Updated, result.Unchanged), "", ""); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// readGeometryFeatures reads a GeoJSON FeatureCollection; row numbers are 1-based feature positions
+func readGeometryFeatures(r io.Reader, matchBy string) ([]geometryInput, error) {
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry   json.RawMessage        `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("GeoJSON inválido: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, errors.New("se esperaba un FeatureCollection")
+	}
+	if len(fc.Features) == 0 {
+		return nil, errors.New("el archivo no tiene features para importar")
+	}
+
+	inputs := make([]geometryInput, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		in := geometryInput{line: i + 1}
+		if len(f.Geometry) > 0 && string(f.Geometry) != "null" {
+			in.raw = string(f.Geometry)
+		}
+		if kind, _ := f.Properties["kind"].(string); strings.EqualFold(kind, "project") {
+			in.isProject = true
+		} else if v, ok := f.Properties[matchBy]; ok && v != nil {
+			switch val := v.(type) {
+			case string:
+				in.key = strings.TrimSpace(val)
+			case float64:
+				in.key = strconv.FormatFloat(val, 'f', -1, 64)
+			default:
+				in.err = fmt.Sprintf("propiedad %s inválida", matchBy)
+			}
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// readGeometryRecords reads a CSV/XLSX with a geometry column (GeoJSON or WKT); a row whose
+// project column is set and has no lot key sets the project boundary.
+func readGeometryRecords(r io.Reader, fileName, matchBy string) ([]geometryInput, error) {
+	records, err := readImportRecords(r, fileName)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]geometryInput, 0, len(records))
+	for _, rec := range records {
+		in := geometryInput{line: rec.line, key: rec.values[matchBy], raw: rec.values["geometry"]}
+		if in.key == "" && rec.values["project"] != "" {
+			in.isProject = true
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// applyImportRow copies the row values onto the lot and reports whether anything changed.
+// Optional columns left empty keep the lot's current value.
+func applyImportRow(lot *models.Lot, row *importLotRow, project *models.Project) bool {
+	before := *lot
+	lot.Name = row.name
+	lot.RegistrationNumber = &row.registrationNumber
+	lot.Length = row.length
+	lot.Width = row.width
+	if row.area != nil {
+		lot.OverrideArea = row.area
+	}
+	if row.price != nil {
+		lot.Price = *row.price
+	} else if lot.ID == 0 || row.area != nil || before.Length != row.length || before.Width != row.width {
+		lot.Project = *project
+		lot.Price = roundCurrency(lot.Area() * lot.PricePerSquareUnit())
+	}
+	if row.overridePrice != nil {
+		lot.OverridePrice = row.overridePrice
+	}
+	if row.measurementUnit != nil {
+		lot.MeasurementUnit = row.measurementUnit
+	} else if lot.MeasurementUnit == nil && project.MeasurementUnit != "" {
+		mu := project.MeasurementUnit
+		lot.MeasurementUnit = &mu
+	}
+	for _, f := range []struct {
+		dst **string
+		src *string
+	}{
+		{&lot.Address, row.address}, {&lot.Note, row.note},
+		{&lot.North, row.north}, {&lot.South, row.south}, {&lot.East, row.east}, {&lot.West, row.west},
+	} {
+		if f.src != nil {
+			*f.dst = f.src
+		}
+	}
+
+	return before.Name != lot.Name ||
+		!strPtrEqual(before.RegistrationNumber, lot.RegistrationNumber) ||
+		before.Length != lot.Length || before.Width != lot.Width ||
+		!floatPtrEqual(before.OverrideArea, lot.OverrideArea) ||
+		before.Price != lot.Price ||
+		!floatPtrEqual(before.OverridePrice, lot.OverridePrice) ||
+		!strPtrEqual(before.MeasurementUnit, lot.MeasurementUnit) ||
+		!strPtrEqual(before.Address, lot.Address) || !strPtrEqual(before.Note, lot.Note) ||
+		!strPtrEqual(before.North, lot.North) || !strPtrEqual(before.South, lot.South) ||
+		!strPtrEqual(before.East, lot.East) || !strPtrEqual(before.West, lot.West)
+}
+
+// importRecord is one non-empty data row keyed by canonical column
+type importRecord struct {
+	line   int
+	values map[string]string
+}
+
+// readImportRows reads a CSV or XLSX file (by extension) into parsed rows. The first row is the header.
+func readImportRows(r io.Reader, fileName string) ([]*importLotRow, error) {
+	records, err := readImportRecords(r, fileName)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]*importLotRow, 0, len(records))
+	for _, rec := range records {
+		rows = append(rows, parseImportRow(rec.line, rec.values))
+	}
+	return rows, nil
+}
+
+// readImportRecords reads the rows of a CSV or XLSX file (first sheet), mapping headers through importColumnAliases
+func readImportRecords(r io.Reader, fileName string) ([]importRecord, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		all, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("CSV inválido: %w", err)
+		}
+		records = all
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("XLSX inválido: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("el archivo XLSX no tiene hojas")
+		}
+		all, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("XLSX inválido: %w", err)
+		}
+		records = all
+	default:
+		return nil, errors.New("formato no soportado: use .csv o .xlsx")
+	}
+
+	if len(records) < 2 {
+		return nil, errors.New("el archivo no tiene filas para importar")
+	}
+
+	columns := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		columns[i] = importColumnAliases[normalizeImportHeader(h)]
+	}
+
+	var out []importRecord
+	for i, record := range records[1:] {
+		values := map[string]string{}
+		empty := true
+		for j, v := range record {
+			if j < len(columns) && columns[j] != "" {
+				v = strings.TrimSpace(v)
+				values[columns[j]] = v
+				if v != "" {
+					empty = false
+				}
+			}
+		}
+		if empty {
+			continue
+		}
+		out = append(out, importRecord{line: i + 2, values: values})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("el archivo no tiene filas para importar")
+	}
+	return out, nil
+}
+
+func parseImportRow(line int, values map[string]string) *importLotRow {
+	row := &importLotRow{
+		line:               line,
+		project:            values["project"],
+		name:               values["name"],
+		registrationNumber: values["registration_number"],
+		projectDescription: values["project_description"],
+		projectAddress:     values["project_address"],
+		measurementUnit:    optionalString(values["measurement_unit"]),
+		address:            optionalString(values["address"]),
+		note:               optionalString(values["note"]),
+		north:              optionalString(values["north"]),
+		south:              optionalString(values["south"]),
+		east:               optionalString(values["east"]),
+		west:               optionalString(values["west"]),
+	}
+
+	number := func(key, label string) *float64 {
+		raw := values[key]
+		if raw == "" {
+			return nil
+		}
+		clean := strings.NewReplacer(",", "", " ", "", "L", "", "$", "").Replace(raw)
+		v, err := strconv.ParseFloat(clean, 64)
+		if err != nil {
+			row.errors = append(row.errors, fmt.Sprintf("%s inválido: %q", label, raw))
+			return nil
+		}
+		return &v
+	}
+	if v := number("length", "largo"); v != nil {
+		row.length = *v
+	}
+	if v := number("width", "ancho"); v != nil {
+		row.width = *v
+	}
+	row.area = number("area", "área")
+	row.price = number("price", "precio")
+	row.overridePrice = number("override_price", "precio especial")
+	if v := number("price_per_square_unit", "precio por unidad"); v != nil {
+		row.pricePerUnit = *v
+	}
+	if v := number("interest_rate", "tasa de interés"); v != nil {
+		row.interestRate = *v
+	}
+	return row
+}
+
+// normalizeImportHeader lowercases a header and folds accents/spaces so "Número Registro" matches numero_registro
+func normalizeImportHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\uFEFF")))
+	h = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n", " ", "_", "-", "_", "/", "_").Replace(h)
+	switch h {
+	case "numero_de_registro":
+		return "numero_registro"
+	case "tasa_de_interes":
+		return "tasa_interes"
+	case "precio_por_vara", "precio_por_m2":
+		return "precio_por_unidad"
+	}
+	return h
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func strPtrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func floatPtrEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func floatOrZero(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
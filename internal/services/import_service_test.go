@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+type mockImportProjectRepository struct {
+	repository.ProjectRepository
+	projects []models.Project
+}
+
+func (m *mockImportProjectRepository) FindByID(ctx context.Context, id uint) (*models.Project, error) {
+	for i := range m.projects {
+		if m.projects[i].ID == id {
+			return &m.projects[i], nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (m *mockImportProjectRepository) FindAll(ctx context.Context) ([]models.Project, error) {
+	return m.projects, nil
+}
+
+type mockImportLotRepository struct {
+	repository.LotRepository
+	lots []models.Lot
+}
+
+func (m *mockImportLotRepository) FindByProject(ctx context.Context, projectID uint) ([]models.Lot, error) {
+	var out []models.Lot
+	for _, l := range m.lots {
+		if l.ProjectID == projectID {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockImportLotRepository) FindByID(ctx context.Context, id uint) (*models.Lot, error) {
+	for i := range m.lots {
+		if m.lots[i].ID == id {
+			return &m.lots[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type mockImportRepository struct {
+	batch  *repository.ImportBatch
+	failAt int
+}
+
+func (m *mockImportRepository) Apply(ctx context.Context, batch *repository.ImportBatch) error {
+	m.batch = batch
+	for _, p := range batch.Projects {
+		for _, item := range p.Lots {
+			if item.Row == m.failAt {
+				return &repository.ImportRowError{Row: item.Row, Err: assert.AnError}
+			}
+		}
+	}
+	return nil
+}
+
+func newImportTestService() *ImportService {
+	reg1, reg2 := "R-001", "R-002"
+	north := "Calle principal"
+	projects := &mockImportProjectRepository{projects: []models.Project{
+		{ID: 1, Name: "Residencial Las Flores", PricePerSquareUnit: 100, MeasurementUnit: "m2"},
+	}}
+	mu := "m2"
+	lots := &mockImportLotRepository{lots: []models.Lot{
+		{ID: 10, ProjectID: 1, Name: "Lote 1", Status: models.LotStatusAvailable, Length: 10, Width: 20, Price: 20000, RegistrationNumber: &reg1, North: &north, MeasurementUnit: &mu},
+		{ID: 11, ProjectID: 1, Name: "Lote 2", Status: models.LotStatusFinanced, Length: 10, Width: 20, Price: 20000, RegistrationNumber: &reg2, MeasurementUnit: &mu},
+	}}
+	return NewImportService(nil, projects, lots, nil)
+}
+
+func TestImportLots_DryRunCSV(t *testing.T) {
+	svc := newImportTestService()
+	csv := strings.Join([]string{
+		"Nombre,Número de Registro,Largo,Ancho,Precio,Norte",
+		"Lote 1,R-001,10,20,,Calle principal", // unchanged
+		"Lote 2,R-002,10,20,25000,",           // financed lot: price change refused
+		"Lote 3,R-003,15,20,,Lote 4",          // new, price from project
+		"Lote 4,R-003,15,20,,",                // duplicate registration
+		"Lote 5,,abc,20,,",                    // missing registration and bad length
+	}, "\n")
+
+	result, err := svc.ImportLots(context.Background(), 1, strings.NewReader(csv), "lotes.csv", true, 1)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 5, result.TotalRows)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 3, result.Failed)
+
+	assert.Equal(t, ImportActionUnchanged, result.Rows[0].Action)
+	assert.Equal(t, uint(10), result.Rows[0].LotID)
+	assert.Equal(t, ImportActionError, result.Rows[1].Action)
+	assert.Equal(t, ImportActionCreate, result.Rows[2].Action)
+	assert.Equal(t, 5, result.Rows[3].Row) // file line (header is line 1)
+	assert.Contains(t, result.Rows[3].Errors[0], "duplicado")
+	assert.Len(t, result.Rows[4].Errors, 3)
+}
+
+func TestImportLots_RefusesWriteWithErrors(t *testing.T) {
+	svc := newImportTestService()
+	csv := "name,registration_number,length,width\nLote 9,,10,10\n"
+
+	result, err := svc.ImportLots(context.Background(), 1, strings.NewReader(csv), "lotes.csv", false, 1)
+	assert.ErrorIs(t, err, ErrImportHasErrors)
+	assert.Equal(t, 1, result.Failed)
+}
+
+func TestImportLots_RowFailureWritesNothing(t *testing.T) {
+	svc := newImportTestService()
+	imports := &mockImportRepository{failAt: 3}
+	svc.repo = imports
+	csv := strings.Join([]string{
+		"Nombre,Número de Registro,Largo,Ancho,Precio",
+		"Lote 1,R-001,10,20,22000", // price update
+		"Lote 3,R-003,15,20,",      // new lot whose insert fails
+	}, "\n")
+
+	result, err := svc.ImportLots(context.Background(), 1, strings.NewReader(csv), "lotes.csv", false, 1)
+	assert.ErrorIs(t, err, assert.AnError)
+	require.NotNil(t, imports.batch)
+	require.Len(t, imports.batch.Projects, 1)
+	lots := imports.batch.Projects[0].Lots
+	require.Len(t, lots, 2)
+	assert.Equal(t, uint(10), lots[0].Lot.ID)
+	require.Len(t, lots[0].History, 1)
+	assert.Equal(t, 22000.0, lots[0].History[0].NewPrice)
+	assert.Zero(t, lots[1].Lot.ID)
+
+	// The rows are reported as planned except the one that failed
+	assert.Equal(t, ImportActionUpdate, result.Rows[0].Action)
+	assert.Equal(t, ImportActionError, result.Rows[1].Action)
+}
+
+func TestImportProjects_XLSXDetectsNewProject(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	rows := [][]interface{}{
+		{"Proyecto", "Dirección Proyecto", "Precio por Unidad", "Lote", "Registro", "Largo", "Ancho"},
+		{"Residencial Las Flores", "", "", "Lote 1", "R-001", 12, 20},
+		{"Villa Nueva", "Km 5 carretera", 50, "A-1", "VN-1", 10, 10},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, f.SetSheetRow(sheet, cell, &row))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	svc := newImportTestService()
+	result, err := svc.ImportProjects(context.Background(), &buf, "import.xlsx", true, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Villa Nueva"}, result.ProjectsCreated)
+	assert.Equal(t, ImportActionUpdate, result.Rows[0].Action)
+	assert.Equal(t, ImportActionCreate, result.Rows[1].Action)
+}
+
+func TestReadImportRows_UnsupportedFormat(t *testing.T) {
+	_, err := readImportRows(strings.NewReader("x"), "lotes.txt")
+	assert.Error(t, err)
+}
+
+func TestImportGeometries_DryRunGeoJSON(t *testing.T) {
+	svc := newImportTestService()
+	fc := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"kind":"project"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[100,0],[100,100],[0,100],[0,0]]]}},
+		{"type":"Feature","properties":{"registration_number":"R-001"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,25],[0,25],[0,0]]]}},
+		{"type":"Feature","properties":{"registration_number":"R-404"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,25],[0,25],[0,0]]]}},
+		{"type":"Feature","properties":{"registration_number":"R-002"},"geometry":null}
+	]}`
+
+	result, err := svc.ImportGeometries(context.Background(), 1, strings.NewReader(fc), "plano.geojson", "", true, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.TotalRows)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, "Residencial Las Flores", result.Rows[0].Project)
+	assert.Equal(t, uint(10), result.Rows[1].LotID)
+	assert.Contains(t, result.Rows[2].Errors, "lote no encontrado en el proyecto")
+	assert.Contains(t, result.Rows[3].Errors, "geometría requerida")
+}
+
+func TestLotArea_UsesGeometryArea(t *testing.T) {
+	g := models.Geometry("POLYGON ((0 0, 10 0, 10 25, 0 25))")
+	lot := &models.Lot{Length: 10, Width: 20, Geometry: &g}
+	require.NoError(t, applyLotGeometry(lot, &models.Project{MeasurementUnit: "m2"}))
+	assert.Equal(t, 250.0, lot.Area())
+
+	override := 300.0
+	lot.OverrideArea = &override
+	assert.Equal(t, 300.0, lot.Area())
+}
+
+func TestLotSetGeometry_OtherProjectNotFound(t *testing.T) {
+	lots := &mockImportLotRepository{lots: []models.Lot{{ID: 10, ProjectID: 2, Name: "Lote 1"}}}
+	svc := NewLotService(lots, nil, nil, nil, nil)
+
+	_, err := svc.SetGeometry(context.Background(), 1, 10, "", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetGeometry(context.Background(), 2, 99, "", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// KYC checklist errors
+var (
+	ErrInvalidKYCRequirement = errors.New("requisito KYC inválido")
+	ErrKYCIncomplete         = errors.New("el contrato no cumple la lista de verificación KYC; faltan")
+	ErrKYCOverrideReason     = errors.New("el motivo es requerido para aprobar sin la lista de verificación completa")
+)
+
+// KYCIncompleteError is returned when a contract with missing checklist items is approved; it
+// matches ErrKYCIncomplete and lists the missing items
+type KYCIncompleteError struct {
+	Missing []models.KYCChecklistItem
+}
+
+func (e *KYCIncompleteError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrKYCIncomplete, missingLabels(e.Missing))
+}
+
+func (e *KYCIncompleteError) Unwrap() error {
+	return ErrKYCIncomplete
+}
+
+// KYCRequirementRequest is the input for adding an item to the checklist of a project and/or
+// financing type
+type KYCRequirementRequest struct {
+	ProjectID     *uint   `json:"project_id"`     // nil or 0 = all projects
+	FinancingType *string `json:"financing_type"` // nil or empty = all financing types
+	Item          string  `json:"item" binding:"required"`
+}
+
+// KYCService manages the KYC checklist contracts must satisfy before approval
+type KYCService struct {
+	repo         repository.KYCRepository
+	documentRepo repository.ContractDocumentRepository
+	contractRepo repository.ContractRepository
+	auditSvc     *AuditService
+}
+
+func NewKYCService(repo repository.KYCRepository, documentRepo repository.ContractDocumentRepository, contractRepo repository.ContractRepository, auditSvc *AuditService) *KYCService {
+	return &KYCService{
+		repo:         repo,
+		documentRepo: documentRepo,
+		contractRepo: contractRepo,
+		auditSvc:     auditSvc,
+	}
+}
+
+// List returns every checklist requirement, general requirements first
+func (s *KYCService) List(ctx context.Context) ([]models.KYCRequirement, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// Create adds an item to the checklist of a project and/or financing type
+func (s *KYCService) Create(ctx context.Context, req KYCRequirementRequest, actorID uint) (*models.KYCRequirement, error) {
+	item := strings.ToLower(strings.TrimSpace(req.Item))
+	if !models.IsKYCItem(item) {
+		return nil, fmt.Errorf("%w: el requisito debe ser uno de %s", ErrInvalidKYCRequirement, strings.Join(models.KYCItems, ", "))
+	}
+	requirement := &models.KYCRequirement{Item: item}
+	if req.ProjectID != nil && *req.ProjectID != 0 {
+		requirement.ProjectID = req.ProjectID
+	}
+	if req.FinancingType != nil {
+		financingType := strings.ToLower(strings.TrimSpace(*req.FinancingType))
+		switch financingType {
+		case "":
+		case models.FinancingTypeDirect, models.FinancingTypeBank, models.FinancingTypeCash:
+			requirement.FinancingType = &financingType
+		default:
+			return nil, fmt.Errorf("%w: el tipo de financiamiento debe ser direct, bank o cash", ErrInvalidKYCRequirement)
+		}
+	}
+
+	existing, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if r.Item == item && equalUintPtr(r.ProjectID, requirement.ProjectID) && equalStringPtr(r.FinancingType, requirement.FinancingType) {
+			return nil, fmt.Errorf("%w: el requisito ya existe", ErrDuplicate)
+		}
+	}
+
+	if err := s.repo.Create(ctx, requirement); err != nil {
+		return nil, fmt.Errorf("failed to create KYC requirement: %w", err)
+	}
+	s.audit(ctx, actorID, "CREATE", "KYCRequirement", requirement.ID, fmt.Sprintf("Requisito KYC %s agregado para %s", models.KYCItemLabel(item), requirementScope(requirement)))
+	return s.repo.FindByID(ctx, requirement.ID)
+}
+
+// Delete removes an item from the checklist
+func (s *KYCService) Delete(ctx context.Context, id uint, actorID uint) error {
+	requirement, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := s.repo.Delete(ctx, requirement.ID); err != nil {
+		return err
+	}
+	s.audit(ctx, actorID, "DELETE", "KYCRequirement", requirement.ID, fmt.Sprintf("Requisito KYC %s eliminado para %s", models.KYCItemLabel(requirement.Item), requirementScope(requirement)))
+	return nil
+}
+
+// ChecklistByContract returns the checklist completion status of a contract
+func (s *KYCService) ChecklistByContract(ctx context.Context, contractID uint) (*models.KYCChecklist, error) {
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.Checklist(ctx, contract)
+}
+
+// Checklist returns the checklist completion status of a contract loaded with its details
+func (s *KYCService) Checklist(ctx context.Context, contract *models.Contract) (*models.KYCChecklist, error) {
+	requirements, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := s.documentRepo.FindCurrentByContract(ctx, contract.ID)
+	if err != nil {
+		return nil, err
+	}
+	checklist := models.BuildKYCChecklist(contract, requirements, documents)
+	return &checklist, nil
+}
+
+// Check refuses the approval of a contract with missing checklist items, listing them, unless an
+// admin overrode the checklist
+func (s *KYCService) Check(ctx context.Context, contract *models.Contract) error {
+	checklist, err := s.Checklist(ctx, contract)
+	if err != nil {
+		return err
+	}
+	if checklist.Complete || checklist.Overridden {
+		return nil
+	}
+	return &KYCIncompleteError{Missing: checklist.Missing()}
+}
+
+// Override lets an admin approve a contract without a complete checklist, stating the reason
+func (s *KYCService) Override(ctx context.Context, contractID uint, reason string, actorID uint) (*models.KYCChecklist, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrKYCOverrideReason
+	}
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if contract.Status != models.ContractStatusPending && contract.Status != models.ContractStatusSubmitted {
+		return nil, fmt.Errorf("%w: solo se puede omitir la lista de verificación de contratos por aprobar", ErrInvalidState)
+	}
+
+	checklist, err := s.Checklist(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.repo.SetOverride(ctx, contract.ID, reason, actorID, now); err != nil {
+		return nil, fmt.Errorf("failed to override KYC checklist: %w", err)
+	}
+	s.audit(ctx, actorID, "KYC_OVERRIDE", "Contract", contract.ID,
+		fmt.Sprintf("Lista de verificación KYC omitida (faltaban: %s). Motivo: %s", missingLabels(checklist.Missing()), reason))
+
+	checklist.Overridden = true
+	checklist.OverrideReason = &reason
+	checklist.OverriddenByID = &actorID
+	checklist.OverriddenAt = &now
+	return checklist, nil
+}
+
+func (s *KYCService) audit(ctx context.Context, userID uint, action, entity string, id uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, entity, id, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[KYCService] Failed to audit %s for %s %d: %v", action, entity, id, err))
+	}
+}
+
+// missingLabels lists the names of the missing checklist items
+func missingLabels(missing []models.KYCChecklistItem) string {
+	labels := make([]string, len(missing))
+	for i, item := range missing {
+		labels[i] = item.Label
+	}
+	return strings.Join(labels, ", ")
+}
+
+// requirementScope describes the contracts a requirement applies to
+func requirementScope(r *models.KYCRequirement) string {
+	scope := "todos los proyectos"
+	if r.ProjectID != nil {
+		scope = fmt.Sprintf("el proyecto %d", *r.ProjectID)
+	}
+	if r.FinancingType != nil {
+		scope += fmt.Sprintf(" (financiamiento %s)", *r.FinancingType)
+	}
+	return scope
+}
+
+func equalUintPtr(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockKYCRepository struct {
+	repository.KYCRepository
+	requirements []models.KYCRequirement
+}
+
+func (m *mockKYCRepository) FindAll(ctx context.Context) ([]models.KYCRequirement, error) {
+	return m.requirements, nil
+}
+
+func TestKYCCheck(t *testing.T) {
+	ctx := context.Background()
+	bank, otherProject := models.FinancingTypeBank, uint(2)
+	svc := NewKYCService(
+		&mockKYCRepository{requirements: []models.KYCRequirement{
+			{Item: models.KYCItemIdentityScan},
+			{Item: models.KYCItemRTN},
+			{Item: models.KYCItemBankPreapproval, FinancingType: &bank},
+			{Item: models.KYCItemProofOfIncome, ProjectID: &otherProject},
+		}},
+		&mockContractDocumentRepository{documents: map[uint]*models.ContractDocument{
+			1: {ID: 1, ContractID: 4, DocumentType: models.DocumentTypeIDCopy},
+		}},
+		nil, nil)
+	contract := &models.Contract{
+		ID:            4,
+		FinancingType: models.FinancingTypeBank,
+		Lot:           models.Lot{ProjectID: 1},
+		ApplicantUser: models.User{RTN: "08011990123456"},
+	}
+
+	checklist, err := svc.Checklist(ctx, contract)
+	require.NoError(t, err)
+	require.Len(t, checklist.Items, 3) // proof of income only applies to project 2
+	assert.False(t, checklist.Complete)
+	assert.Equal(t, []models.KYCChecklistItem{{Item: models.KYCItemBankPreapproval, Label: "Carta de preaprobación bancaria"}}, checklist.Missing())
+
+	err = svc.Check(ctx, contract)
+	assert.ErrorIs(t, err, ErrKYCIncomplete)
+	assert.Contains(t, err.Error(), "Carta de preaprobación bancaria")
+	var incomplete *KYCIncompleteError
+	require.ErrorAs(t, err, &incomplete)
+	assert.Equal(t, models.KYCItemBankPreapproval, incomplete.Missing[0].Item)
+
+	contract.FinancingType = models.FinancingTypeDirect
+	assert.NoError(t, svc.Check(ctx, contract))
+
+	contract.FinancingType = models.FinancingTypeBank
+	now, reason := time.Now(), "Carta en trámite con el banco"
+	contract.KYCOverriddenAt, contract.KYCOverrideReason = &now, &reason
+	assert.NoError(t, svc.Check(ctx, contract))
+}
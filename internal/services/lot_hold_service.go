@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Lot hold errors
+var (
+	ErrLotNotHoldable   = errors.New("el lote no está disponible para apartar")
+	ErrLotHeldByOther   = errors.New("el lote está apartado por otro vendedor")
+	ErrLotHoldNotActive = errors.New("el apartado ya no está activo")
+)
+
+// LotHoldService manages the temporary holds sellers place on available lots while preparing a
+// contract. Holds move the lot to held through the lot FSM (compare-and-set on its status), so two
+// sellers can never hold or contract the same lot at once.
+type LotHoldService struct {
+	lotRepo         repository.LotRepository
+	lotStatusSvc    *LotStatusService
+	notificationSvc *NotificationService
+	auditSvc        *AuditService
+}
+
+func NewLotHoldService(lotRepo repository.LotRepository, lotStatusSvc *LotStatusService, notificationSvc *NotificationService, auditSvc *AuditService) *LotHoldService {
+	return &LotHoldService{lotRepo: lotRepo, lotStatusSvc: lotStatusSvc, notificationSvc: notificationSvc, auditSvc: auditSvc}
+}
+
+// Place holds an available lot of the project for the seller for the project's hold duration
+func (s *LotHoldService) Place(ctx context.Context, projectID, lotID, sellerID uint, note string) (*models.LotHold, error) {
+	return s.place(ctx, projectID, lotID, sellerID, 0, note)
+}
+
+// PlacePriority holds a lot for the given number of minutes; used for the priority window of
+// waitlist entries when a lot frees up
+func (s *LotHoldService) PlacePriority(ctx context.Context, projectID, lotID, sellerID uint, minutes int, note string) (*models.LotHold, error) {
+	return s.place(ctx, projectID, lotID, sellerID, minutes, note)
+}
+
+func (s *LotHoldService) place(ctx context.Context, projectID, lotID, sellerID uint, minutes int, note string) (*models.LotHold, error) {
+	lot, err := s.lotRepo.FindByID(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if lot.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	if !lot.IsAvailable() {
+		return nil, s.unavailableError(ctx, lot, sellerID)
+	}
+	if !lot.Project.IsPublished() {
+		return nil, ErrProjectNotPublished
+	}
+	if !lot.IsOnSale() {
+		return nil, ErrLotNotOnSale
+	}
+
+	actorID := sellerID
+	if _, err := s.lotStatusSvc.Fire(ctx, lot.ID, statemachine.LotEventHold, LotEventContext{ActorID: &actorID, Reason: "Lote apartado"}); err != nil {
+		return nil, s.unavailableError(ctx, lot, sellerID)
+	}
+
+	hold := &models.LotHold{
+		LotID:     lot.ID,
+		ProjectID: lot.ProjectID,
+		SellerID:  sellerID,
+		Status:    models.LotHoldStatusActive,
+	}
+	if minutes <= 0 {
+		minutes = lot.Project.HoldDuration()
+	}
+	hold.ExpiresAt = time.Now().Add(time.Duration(minutes) * time.Minute)
+	if note != "" {
+		hold.Note = &note
+	}
+	if err := s.lotRepo.CreateHold(ctx, hold); err != nil {
+		s.releaseLot(ctx, lot.ID, &actorID, "No se pudo registrar el apartado")
+		return nil, fmt.Errorf("failed to create lot hold: %w", err)
+	}
+	hold.Lot = *lot
+
+	s.audit(ctx, sellerID, "HOLD", hold.ID, fmt.Sprintf("Lote %s del proyecto %s apartado hasta %s", lot.Name, lot.Project.Name, hold.ExpiresAt.Format("2006-01-02 15:04")))
+	return hold, nil
+}
+
+// Release ends the active hold of a lot of the project and makes the lot available again. Only the
+// hold owner or an admin may release it.
+func (s *LotHoldService) Release(ctx context.Context, projectID, lotID, actorID uint, isAdmin bool) (*models.LotHold, error) {
+	hold, err := s.lotRepo.FindActiveHold(ctx, lotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLotHoldNotActive
+		}
+		return nil, err
+	}
+	if hold.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	if hold.SellerID != actorID && !isAdmin {
+		return nil, ErrLotHeldByOther
+	}
+
+	now := time.Now()
+	ok, err := s.lotRepo.UpdateHoldStatus(ctx, hold.ID, models.LotHoldStatusActive, models.LotHoldStatusReleased,
+		map[string]interface{}{"released_by": actorID, "released_at": now})
+	if err != nil {
+		return nil, fmt.Errorf("failed to release lot hold: %w", err)
+	}
+	if !ok {
+		return nil, ErrLotHoldNotActive
+	}
+	hold.Status = models.LotHoldStatusReleased
+	hold.ReleasedBy = &actorID
+	hold.ReleasedAt = &now
+
+	s.releaseLot(ctx, hold.LotID, &actorID, "Apartado liberado")
+	s.audit(ctx, actorID, "RELEASE_HOLD", hold.ID, fmt.Sprintf("Apartado del lote %s liberado", hold.Lot.Name))
+	return hold, nil
+}
+
+// ExpireDue expires the active holds past their expiry time, releasing their lots and notifying
+// the sellers. Holds converted into a contract meanwhile are skipped.
+func (s *LotHoldService) ExpireDue(ctx context.Context) error {
+	holds, err := s.lotRepo.FindExpiredHolds(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to find expired lot holds: %w", err)
+	}
+
+	expired := 0
+	for i := range holds {
+		hold := &holds[i]
+		ok, err := s.lotRepo.UpdateHoldStatus(ctx, hold.ID, models.LotHoldStatusActive, models.LotHoldStatusExpired, nil)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[LotHoldService] Failed to expire hold %d: %v", hold.ID, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		expired++
+
+		s.releaseLot(ctx, hold.LotID, nil, "Apartado expirado")
+		if s.notificationSvc != nil {
+			msg := fmt.Sprintf("Tu apartado del lote %s expiró y el lote está disponible nuevamente.", hold.Lot.Name)
+			if err := s.notificationSvc.NotifyUser(ctx, hold.SellerID, "Apartado expirado", msg, models.NotificationTypeLotHoldExpired); err != nil {
+				logger.Error(fmt.Sprintf("[LotHoldService] Failed to notify seller %d of expired hold %d: %v", hold.SellerID, hold.ID, err))
+			}
+		}
+		s.audit(ctx, hold.SellerID, "EXPIRE_HOLD", hold.ID, fmt.Sprintf("Apartado del lote %s expirado", hold.Lot.Name))
+	}
+
+	if expired > 0 {
+		logger.Info(fmt.Sprintf("[LotHoldService] Expired %d lot holds", expired))
+	}
+	return nil
+}
+
+// ClaimForContract converts the active hold of a held lot into the given creator's contract and
+// returns it; the lot must then be reserved with LotEventConvertHold. Returns nil when the lot
+// is not held. Fails when the lot is held by another seller or the hold expired meanwhile.
+func (s *LotHoldService) ClaimForContract(ctx context.Context, lot *models.Lot, creatorID *uint) (*models.LotHold, error) {
+	if lot.Status != models.LotStatusHeld {
+		return nil, nil
+	}
+	hold, err := s.lotRepo.FindActiveHold(ctx, lot.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLotNotHoldable
+		}
+		return nil, err
+	}
+	if creatorID == nil || hold.SellerID != *creatorID {
+		return nil, ErrLotHeldByOther
+	}
+	if hold.IsExpired(time.Now()) {
+		return nil, ErrLotHoldNotActive
+	}
+
+	ok, err := s.lotRepo.UpdateHoldStatus(ctx, hold.ID, models.LotHoldStatusActive, models.LotHoldStatusConverted, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert lot hold: %w", err)
+	}
+	if !ok {
+		return nil, ErrLotHoldNotActive
+	}
+	hold.Status = models.LotHoldStatusConverted
+	return hold, nil
+}
+
+// LinkContract records the contract a converted hold turned into
+func (s *LotHoldService) LinkContract(ctx context.Context, hold *models.LotHold, contractID uint) {
+	if _, err := s.lotRepo.UpdateHoldStatus(ctx, hold.ID, models.LotHoldStatusConverted, models.LotHoldStatusConverted,
+		map[string]interface{}{"contract_id": contractID}); err != nil {
+		logger.Error(fmt.Sprintf("[LotHoldService] Failed to link hold %d to contract %d: %v", hold.ID, contractID, err))
+		return
+	}
+	hold.ContractID = &contractID
+}
+
+// RestoreClaim puts a claimed hold back to active when the contract could not be created, so the
+// seller keeps the lot until the hold expires
+func (s *LotHoldService) RestoreClaim(ctx context.Context, hold *models.LotHold) {
+	if _, err := s.lotRepo.UpdateHoldStatus(ctx, hold.ID, models.LotHoldStatusConverted, models.LotHoldStatusActive, nil); err != nil {
+		logger.Error(fmt.Sprintf("[LotHoldService] Failed to restore hold %d: %v", hold.ID, err))
+		return
+	}
+	hold.Status = models.LotHoldStatusActive
+}
+
+// List returns holds with the given status (all when empty); sellers only see their own
+func (s *LotHoldService) List(ctx context.Context, sellerID *uint, status string) ([]models.LotHold, error) {
+	return s.lotRepo.ListHolds(ctx, sellerID, status)
+}
+
+// FindActive returns the active hold of a lot of the project
+func (s *LotHoldService) FindActive(ctx context.Context, projectID, lotID uint) (*models.LotHold, error) {
+	hold, err := s.lotRepo.FindActiveHold(ctx, lotID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hold.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	return hold, nil
+}
+
+// unavailableError tells a seller whether the lot is held by someone else or simply not available
+func (s *LotHoldService) unavailableError(ctx context.Context, lot *models.Lot, sellerID uint) error {
+	if hold, err := s.lotRepo.FindActiveHold(ctx, lot.ID); err == nil && hold.SellerID != sellerID {
+		return ErrLotHeldByOther
+	}
+	return ErrLotNotHoldable
+}
+
+func (s *LotHoldService) releaseLot(ctx context.Context, lotID uint, actorID *uint, reason string) {
+	if _, err := s.lotStatusSvc.Fire(ctx, lotID, statemachine.LotEventReleaseHold, LotEventContext{ActorID: actorID, Reason: reason}); err != nil {
+		logger.Error(fmt.Sprintf("[LotHoldService] Failed to release held lot %d: %v", lotID, err))
+	}
+}
+
+func (s *LotHoldService) audit(ctx context.Context, userID uint, action string, holdID uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "LotHold", holdID, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[LotHoldService] Failed to audit %s for hold %d: %v", action, holdID, err))
+	}
+}
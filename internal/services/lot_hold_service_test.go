@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockLotHoldRepository struct {
+	mockLotStatusRepository
+	holds []models.LotHold
+}
+
+func (m *mockLotHoldRepository) CreateHold(ctx context.Context, hold *models.LotHold) error {
+	hold.ID = uint(len(m.holds) + 1)
+	m.holds = append(m.holds, *hold)
+	return nil
+}
+
+func (m *mockLotHoldRepository) FindActiveHold(ctx context.Context, lotID uint) (*models.LotHold, error) {
+	for _, h := range m.holds {
+		if h.LotID == lotID && h.Status == models.LotHoldStatusActive {
+			return &h, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockLotHoldRepository) FindExpiredHolds(ctx context.Context, at time.Time) ([]models.LotHold, error) {
+	var out []models.LotHold
+	for _, h := range m.holds {
+		if h.Status == models.LotHoldStatusActive && !h.ExpiresAt.After(at) {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockLotHoldRepository) UpdateHoldStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error) {
+	h := &m.holds[id-1]
+	if h.Status != fromStatus {
+		return false, nil
+	}
+	h.Status = toStatus
+	return true, nil
+}
+
+func newTestLotHoldService() (*LotHoldService, *mockLotHoldRepository) {
+	repo := &mockLotHoldRepository{}
+	repo.lot = models.Lot{ID: 1, ProjectID: 3, Status: models.LotStatusAvailable, Project: models.Project{Status: models.ProjectStatusPublished, HoldDurationMinutes: 30}}
+	return NewLotHoldService(repo, NewLotStatusService(repo), nil, nil), repo
+}
+
+func TestLotHoldService_PlaceAndConvert(t *testing.T) {
+	svc, repo := newTestLotHoldService()
+	ctx := context.Background()
+	sellerA, sellerB := uint(10), uint(20)
+
+	hold, err := svc.Place(ctx, 3, 1, sellerA, "papeles en trámite")
+	require.NoError(t, err)
+	assert.Equal(t, models.LotStatusHeld, repo.lot.Status)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), hold.ExpiresAt, time.Minute)
+
+	// The lot is only found under its own project
+	_, err = svc.FindActive(ctx, 4, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.Release(ctx, 4, 1, sellerA, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+	found, err := svc.FindActive(ctx, 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, hold.ID, found.ID)
+
+	// Another seller sees the lot as held and cannot hold or contract it
+	_, err = svc.Place(ctx, 3, 1, sellerB, "")
+	assert.ErrorIs(t, err, ErrLotHeldByOther)
+	lot := repo.lot
+	_, err = svc.ClaimForContract(ctx, &lot, &sellerB)
+	assert.ErrorIs(t, err, ErrLotHeldByOther)
+
+	// The hold owner's contract converts the hold and reserves the lot
+	claimed, err := svc.ClaimForContract(ctx, &lot, &sellerA)
+	require.NoError(t, err)
+	assert.Equal(t, models.LotHoldStatusConverted, repo.holds[0].Status)
+	_, err = svc.lotStatusSvc.Fire(ctx, 1, statemachine.LotEventConvertHold, LotEventContext{})
+	require.NoError(t, err)
+	assert.Equal(t, models.LotStatusReserved, repo.lot.Status)
+
+	// A converted hold is no longer expired by the worker
+	repo.holds[0].ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, svc.ExpireDue(ctx))
+	assert.Equal(t, models.LotHoldStatusConverted, repo.holds[0].Status)
+	assert.Equal(t, models.LotStatusReserved, repo.lot.Status)
+	assert.Equal(t, hold.ID, claimed.ID)
+}
+
+func TestLotHoldService_ExpireDue(t *testing.T) {
+	svc, repo := newTestLotHoldService()
+	ctx := context.Background()
+	seller := uint(10)
+
+	_, err := svc.Place(ctx, 4, 1, seller, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.Place(ctx, 3, 1, seller, "")
+	require.NoError(t, err)
+
+	// Not yet expired
+	require.NoError(t, svc.ExpireDue(ctx))
+	assert.Equal(t, models.LotStatusHeld, repo.lot.Status)
+
+	repo.holds[0].ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, svc.ExpireDue(ctx))
+	assert.Equal(t, models.LotHoldStatusExpired, repo.holds[0].Status)
+	assert.Equal(t, models.LotStatusAvailable, repo.lot.Status)
+
+	// The lot can be held again once the hold expired
+	_, err = svc.Place(ctx, 3, 1, uint(20), "")
+	require.NoError(t, err)
+	assert.Equal(t, models.LotStatusHeld, repo.lot.Status)
+}
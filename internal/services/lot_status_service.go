@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+)
+
+// ErrLotStatusManualChange is returned when a lot status is edited directly instead of through contract/payment events
+var ErrLotStatusManualChange = errors.New("el estado del lote no se puede editar manualmente; cambia con los eventos del contrato y sus pagos")
+
+// LotEventContext identifies the domain event behind a lot transition
+type LotEventContext struct {
+	ContractID *uint
+	PaymentID  *uint
+	ActorID    *uint
+	Reason     string
+}
+
+// LotTransitionListener is called after a lot status transition is written
+type LotTransitionListener func(ctx context.Context, lot *models.Lot, from, event string, ec LotEventContext)
+
+// LotStatusService moves lots through the lot FSM and records their status history
+type LotStatusService struct {
+	lotRepo   repository.LotRepository
+	listeners []LotTransitionListener
+}
+
+func NewLotStatusService(lotRepo repository.LotRepository) *LotStatusService {
+	return &LotStatusService{lotRepo: lotRepo}
+}
+
+// OnTransition registers a listener for lot status transitions (e.g. the waitlist reacting to a
+// lot becoming available). Listeners run synchronously after the transition is recorded.
+func (s *LotStatusService) OnTransition(listener LotTransitionListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Fire applies a lot event. The status is written with compare-and-set on the previous status and
+// retried once on a concurrent change; a transition not allowed from the current status is an error.
+// Releasing or reverting a lot on behalf of a contract is refused when another contract reserved it
+// since, so an old contract can't free the lot of its current buyer.
+func (s *LotStatusService) Fire(ctx context.Context, lotID uint, event string, ec LotEventContext) (*models.Lot, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		lot, err := s.lotRepo.FindByID(ctx, lotID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load lot: %w", err)
+		}
+		from := lot.Status
+		if err := statemachine.NewLotFSM(lot).Fire(ctx, event); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+		}
+		if lot.Status == from {
+			return lot, nil
+		}
+		if err := s.checkReservedBy(ctx, lot.ID, event, ec); err != nil {
+			return nil, err
+		}
+
+		ok, err := s.lotRepo.UpdateStatus(ctx, lot.ID, from, lot.Status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update lot status: %w", err)
+		}
+		if !ok {
+			continue
+		}
+
+		entry := &models.LotStatusHistory{
+			LotID:      lot.ID,
+			FromStatus: from,
+			ToStatus:   lot.Status,
+			Event:      event,
+			ContractID: ec.ContractID,
+			PaymentID:  ec.PaymentID,
+			ActorID:    ec.ActorID,
+		}
+		if ec.Reason != "" {
+			reason := ec.Reason
+			entry.Reason = &reason
+		}
+		if err := s.lotRepo.CreateStatusHistory(ctx, entry); err != nil {
+			logger.Error(fmt.Sprintf("[LotStatusService] Failed to record status history for lot %d: %v", lot.ID, err))
+		}
+		for _, listener := range s.listeners {
+			listener(ctx, lot, from, event, ec)
+		}
+		return lot, nil
+	}
+	return nil, fmt.Errorf("%w: el lote cambió de estado concurrentemente", ErrInvalidState)
+}
+
+// checkReservedBy refuses release and revert for a contract other than the one of the lot's last
+// reservation. Lots reserved before the status history was recorded are not checked.
+func (s *LotStatusService) checkReservedBy(ctx context.Context, lotID uint, event string, ec LotEventContext) error {
+	if ec.ContractID == nil || (event != statemachine.LotEventRelease && event != statemachine.LotEventRevert) {
+		return nil
+	}
+	history, err := s.lotRepo.FindStatusHistory(ctx, lotID)
+	if err != nil {
+		return fmt.Errorf("failed to load lot status history: %w", err)
+	}
+	for _, entry := range history {
+		if entry.Event != statemachine.LotEventReserve && entry.Event != statemachine.LotEventConvertHold {
+			continue
+		}
+		if entry.ContractID != nil && *entry.ContractID != *ec.ContractID {
+			return fmt.Errorf("%w: el lote está reservado por el contrato #%d", ErrInvalidState, *entry.ContractID)
+		}
+		return nil
+	}
+	return nil
+}
+
+// FireForContract fires a lot event for the contract's lot, logging instead of failing; used
+// where the contract transition already happened and the lot must follow it.
+func (s *LotStatusService) FireForContract(ctx context.Context, contract *models.Contract, event string, actorID *uint, reason string) {
+	contractID := contract.ID
+	if _, err := s.Fire(ctx, contract.LotID, event, LotEventContext{ContractID: &contractID, ActorID: actorID, Reason: reason}); err != nil {
+		logger.Error(fmt.Sprintf("[LotStatusService] Failed to %s lot %d (contract %d): %v", event, contract.LotID, contract.ID, err))
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLotStatusRepository struct {
+	repository.LotRepository
+	lot     models.Lot
+	history []models.LotStatusHistory
+	// racer, when set, changes the stored status right before the next compare-and-set
+	racer func(lot *models.Lot)
+}
+
+func (m *mockLotStatusRepository) FindByID(ctx context.Context, id uint) (*models.Lot, error) {
+	lot := m.lot
+	return &lot, nil
+}
+
+func (m *mockLotStatusRepository) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error) {
+	if m.racer != nil {
+		m.racer(&m.lot)
+		m.racer = nil
+	}
+	if m.lot.Status != fromStatus {
+		return false, nil
+	}
+	m.lot.Status = toStatus
+	return true, nil
+}
+
+func (m *mockLotStatusRepository) CreateStatusHistory(ctx context.Context, entry *models.LotStatusHistory) error {
+	m.history = append(m.history, *entry)
+	return nil
+}
+
+func (m *mockLotStatusRepository) FindStatusHistory(ctx context.Context, lotID uint) ([]models.LotStatusHistory, error) {
+	history := make([]models.LotStatusHistory, 0, len(m.history))
+	for i := len(m.history) - 1; i >= 0; i-- {
+		history = append(history, m.history[i])
+	}
+	return history, nil
+}
+
+func TestLotStatusService_Lifecycle(t *testing.T) {
+	repo := &mockLotStatusRepository{lot: models.Lot{ID: 1, Status: models.LotStatusAvailable}}
+	svc := NewLotStatusService(repo)
+	ctx := context.Background()
+	contractID := uint(7)
+	ec := LotEventContext{ContractID: &contractID}
+
+	lot, err := svc.Fire(ctx, 1, statemachine.LotEventReserve, ec)
+	require.NoError(t, err)
+	assert.Equal(t, models.LotStatusReserved, lot.Status)
+
+	// A reserved lot cannot be reserved again
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventReserve, ec)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	// Finance is idempotent (several approved payments)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventFinance, ec)
+	require.NoError(t, err)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventFinance, ec)
+	require.NoError(t, err)
+
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventPayOff, ec)
+	require.NoError(t, err)
+
+	// A fully paid lot cannot be released
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventRelease, ec)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	require.Len(t, repo.history, 3)
+	assert.Equal(t, models.LotStatusAvailable, repo.history[0].FromStatus)
+	assert.Equal(t, statemachine.LotEventFinance, repo.history[1].Event)
+	assert.Equal(t, models.LotStatusFullyPaid, repo.history[2].ToStatus)
+	assert.Equal(t, &contractID, repo.history[2].ContractID)
+}
+
+func TestLotStatusService_ConcurrentReserve(t *testing.T) {
+	repo := &mockLotStatusRepository{lot: models.Lot{ID: 1, Status: models.LotStatusAvailable}}
+	// Another contract reserves the lot between the read and the write
+	repo.racer = func(lot *models.Lot) { lot.Status = models.LotStatusReserved }
+	svc := NewLotStatusService(repo)
+
+	_, err := svc.Fire(context.Background(), 1, statemachine.LotEventReserve, LotEventContext{})
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.Empty(t, repo.history)
+}
+
+func TestLotStatusService_ReleaseRefusedForAnotherContract(t *testing.T) {
+	repo := &mockLotStatusRepository{lot: models.Lot{ID: 1, Status: models.LotStatusAvailable}}
+	svc := NewLotStatusService(repo)
+	listened := 0
+	svc.OnTransition(func(ctx context.Context, lot *models.Lot, from, event string, ec LotEventContext) { listened++ })
+	ctx := context.Background()
+	first, second := uint(7), uint(8)
+
+	// Contract 7 is rejected, then contract 8 reserves the lot
+	_, err := svc.Fire(ctx, 1, statemachine.LotEventReserve, LotEventContext{ContractID: &first})
+	require.NoError(t, err)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventRelease, LotEventContext{ContractID: &first})
+	require.NoError(t, err)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventReserve, LotEventContext{ContractID: &second})
+	require.NoError(t, err)
+	listened = 0
+
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventRelease, LotEventContext{ContractID: &first})
+	assert.ErrorIs(t, err, ErrInvalidState)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventFinance, LotEventContext{ContractID: &second})
+	require.NoError(t, err)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventRevert, LotEventContext{ContractID: &first})
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.Equal(t, models.LotStatusFinanced, repo.lot.Status)
+	assert.Len(t, repo.history, 4)
+	assert.Equal(t, 1, listened)
+
+	// The contract holding the lot can still release it
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventRevert, LotEventContext{ContractID: &second})
+	require.NoError(t, err)
+	_, err = svc.Fire(ctx, 1, statemachine.LotEventRelease, LotEventContext{ContractID: &second})
+	require.NoError(t, err)
+	assert.Equal(t, models.LotStatusAvailable, repo.lot.Status)
+}
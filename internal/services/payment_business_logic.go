@@ -7,6 +7,7 @@ import (
 
 	"github.com/sjperalta/fintera-api/internal/models"
 	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/pkg/logger"
 )
 
 // ApprovePayment approves a payment and creates ledger entry
@@ -109,8 +110,8 @@ func (s *PaymentService) RejectPaymentWithFSM(ctx context.Context, id uint, reas
 	return nil
 }
 
-// UndoPayment undoes an approved payment and reverses the ledger entry
-func (s *PaymentService) UndoPayment(ctx context.Context, id uint) error {
+// UndoPayment undoes an approved payment, reverses the ledger entry and voids its receipt and invoice
+func (s *PaymentService) UndoPayment(ctx context.Context, id uint, actorID uint) error {
 	payment, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to load payment: %w", err)
@@ -154,6 +155,33 @@ func (s *PaymentService) UndoPayment(ctx context.Context, id uint) error {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
+	// With no approved reservation or down payment left the lot goes back from financed to reserved;
+	// other payments never financed it
+	if financesLot(payment.PaymentType) {
+		if payments, err := s.repo.FindByContract(ctx, payment.ContractID); err == nil && !hasPaidFinancingPayment(payments) {
+			if contract, err := s.contractRepo.FindByID(ctx, payment.ContractID); err == nil && contract.InForce() {
+				paymentID := payment.ID
+				if _, err := s.lotStatusSvc.Fire(ctx, contract.LotID, statemachine.LotEventRevert, LotEventContext{
+					ContractID: &contract.ID, PaymentID: &paymentID, ActorID: &actorID, Reason: fmt.Sprintf("Pago #%d revertido", payment.ID),
+				}); err != nil {
+					logger.Error(fmt.Sprintf("[PaymentService] Failed to revert lot %d: %v", contract.LotID, err))
+				}
+			}
+		}
+	}
+
+	// The receipt and invoice numbers stay used; approving the payment again issues new ones
+	if s.receiptSvc != nil {
+		if _, err := s.receiptSvc.Void(ctx, payment.ID, actorID, "Pago revertido"); err != nil {
+			logger.Error(fmt.Sprintf("[PaymentService] Failed to void receipt of payment %d: %v", payment.ID, err))
+		}
+	}
+	if s.fiscalSvc != nil {
+		if _, err := s.fiscalSvc.VoidPaymentInvoice(ctx, payment.ID, actorID, "Pago revertido"); err != nil {
+			logger.Error(fmt.Sprintf("[PaymentService] Failed to void invoice of payment %d: %v", payment.ID, err))
+		}
+	}
+
 	// Notify user
 	s.worker.EnqueueAsync(func(ctx context.Context) error {
 		contract, _ := s.contractRepo.FindByIDWithDetails(ctx, payment.ContractID)
@@ -169,6 +197,21 @@ func (s *PaymentService) UndoPayment(ctx context.Context, id uint) error {
 	return nil
 }
 
+// financesLot reports whether approving a payment of the type moves the lot to financed
+func financesLot(paymentType string) bool {
+	return paymentType == models.PaymentTypeReservation || paymentType == models.PaymentTypeDownPayment
+}
+
+// hasPaidFinancingPayment reports whether any reservation or down payment is paid
+func hasPaidFinancingPayment(payments []models.Payment) bool {
+	for _, p := range payments {
+		if p.Status == models.PaymentStatusPaid && financesLot(p.PaymentType) {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper function to get payment type description in Spanish
 func getPaymentTypeDescription(paymentType string) string {
 	switch paymentType {
@@ -182,6 +225,8 @@ func getPaymentTypeDescription(paymentType string) string {
 		return "Pago Total"
 	case models.PaymentTypeAdvance:
 		return "Anticipo"
+	case models.PaymentTypeFee:
+		return "Cargo administrativo"
 	default:
 		return "Pago"
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -149,6 +150,91 @@ func (s *PaymentScheduleService) GenerateSchedule(ctx context.Context, contract
 	return payments, nil
 }
 
+// ScheduleResize is the change to the pending schedule of a contract whose total moved
+type ScheduleResize struct {
+	Resized []models.Payment // pending payments with their new amount
+	Dropped []uint           // pending payments no longer needed
+	Added   *models.Payment  // extra payment when nothing pending can absorb an increase
+	Excess  float64          // amount already paid or submitted beyond the new total
+}
+
+// ResizeRemaining spreads a change of the contract total (delta, positive when more is owed) over
+// its pending installments, keeping their due dates: the new remainder is split with the same
+// rounding as GenerateSchedule (whole amounts, the first installment takes the difference) and
+// trailing installments are dropped when it no longer covers them. Contracts without pending
+// installments adjust their pending full payment. Reservation, down payment, fee and submitted
+// payments are never touched; when the decrease is larger than everything pending, the part
+// already paid is reported as Excess.
+func (s *PaymentScheduleService) ResizeRemaining(contract *models.Contract, delta float64) ScheduleResize {
+	var result ScheduleResize
+	if delta == 0 {
+		return result
+	}
+
+	var pending []models.Payment
+	for _, p := range contract.Payments {
+		if p.Status == models.PaymentStatusPending && p.PaymentType == models.PaymentTypeInstallment {
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) == 0 {
+		for _, p := range contract.Payments {
+			if p.Status == models.PaymentStatusPending && p.PaymentType == models.PaymentTypeFull {
+				pending = append(pending, p)
+			}
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].DueDate.Before(pending[j].DueDate) })
+
+	remaining := 0.0
+	for _, p := range pending {
+		remaining += p.Amount
+	}
+	newTotal := math.Round((remaining+delta)*100) / 100
+
+	if newTotal < 0 {
+		result.Excess = -newTotal
+	}
+	if len(pending) == 0 {
+		if newTotal > 0 {
+			result.Added = &models.Payment{
+				ContractID:  contract.ID,
+				Amount:      newTotal,
+				DueDate:     time.Now().AddDate(0, 1, 0),
+				Status:      models.PaymentStatusPending,
+				PaymentType: models.PaymentTypeFull,
+				Description: stringPtr("Saldo por ajuste de precio"),
+			}
+		}
+		return result
+	}
+	if newTotal <= 0 {
+		for _, p := range pending {
+			result.Dropped = append(result.Dropped, p.ID)
+		}
+		return result
+	}
+
+	count := len(pending)
+	if float64(count) > newTotal {
+		count = int(math.Max(1, math.Floor(newTotal)))
+	}
+	base := math.Floor(newTotal / float64(count))
+	first := math.Round((newTotal-base*float64(count-1))*100) / 100
+	for i, p := range pending {
+		if i >= count {
+			result.Dropped = append(result.Dropped, p.ID)
+			continue
+		}
+		p.Amount = base
+		if i == 0 {
+			p.Amount = first
+		}
+		result.Resized = append(result.Resized, p)
+	}
+	return result
+}
+
 // stringPtr returns a pointer to a string
 func stringPtr(s string) *string {
 	return &s
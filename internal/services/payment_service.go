@@ -2,12 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/sjperalta/fintera-api/internal/jobs"
 	"github.com/sjperalta/fintera-api/internal/models"
 	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
 	"github.com/sjperalta/fintera-api/internal/storage"
 	"github.com/sjperalta/fintera-api/pkg/logger"
 )
@@ -33,6 +35,9 @@ type PaymentService struct {
 	notificationSvc *NotificationService
 	emailSvc        *EmailService
 	auditSvc        *AuditService
+	lotStatusSvc    *LotStatusService
+	receiptSvc      *ReceiptService
+	fiscalSvc       *FiscalService
 	storage         *storage.LocalStorage
 	worker          *jobs.Worker
 }
@@ -45,6 +50,9 @@ func NewPaymentService(
 	notificationSvc *NotificationService,
 	emailSvc *EmailService,
 	auditSvc *AuditService,
+	lotStatusSvc *LotStatusService,
+	receiptSvc *ReceiptService,
+	fiscalSvc *FiscalService,
 	storage *storage.LocalStorage,
 	worker *jobs.Worker,
 ) *PaymentService {
@@ -56,6 +64,9 @@ func NewPaymentService(
 		notificationSvc: notificationSvc,
 		emailSvc:        emailSvc,
 		auditSvc:        auditSvc,
+		lotStatusSvc:    lotStatusSvc,
+		receiptSvc:      receiptSvc,
+		fiscalSvc:       fiscalSvc,
 		storage:         storage,
 		worker:          worker,
 	}
@@ -191,17 +202,37 @@ func (s *PaymentService) Approve(ctx context.Context, id uint, amount, interestA
 		}
 	}
 
+	// Issue the numbered receipt; a failure here must not undo the approval
+	var receipt *models.PaymentReceipt
+	if s.receiptSvc != nil {
+		receipt, err = s.receiptSvc.Issue(ctx, payment, paymentReceiptBreakdown(payment), actorID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[PaymentService] Failed to issue receipt for payment %d: %v", payment.ID, err))
+		} else if _, err := s.receiptSvc.Render(ctx, receipt); err != nil {
+			// Render it now so its issued document exists before an undo can void the receipt
+			logger.Error(fmt.Sprintf("[PaymentService] Failed to render receipt %s: %v", receipt.DisplayNumber(), err))
+		}
+	}
+
+	// Invoice the interest and fees collected; admins are notified when no fiscal range is available
+	if s.fiscalSvc != nil {
+		if _, err := s.fiscalSvc.InvoicePayment(ctx, payment, actorID); err != nil && !errors.Is(err, ErrNothingToInvoice) {
+			logger.Error(fmt.Sprintf("[PaymentService] Failed to invoice payment %d: %v", payment.ID, err))
+		}
+	}
+
 	// Update contract balance and Lot status
 	s.worker.EnqueueAsync(func(ctx context.Context) error {
-		// Update lot status to financed if this is a reservation payment
-		if payment.PaymentType == models.PaymentTypeReservation {
-			contract, err := s.contractRepo.FindByID(ctx, payment.ContractID)
-			if err == nil {
-				lot, err := s.lotRepo.FindByID(ctx, contract.LotID)
-				if err == nil {
-					lot.Status = models.LotStatusFinanced
-					s.lotRepo.Update(ctx, lot)
-				}
+		// The first approved reservation or down payment moves the lot to financed; fees (e.g. of a
+		// cession) don't
+		contract, err := s.contractRepo.FindByID(ctx, payment.ContractID)
+		if err == nil && contract.InForce() && financesLot(payment.PaymentType) {
+			paymentID := payment.ID
+			if _, err := s.lotStatusSvc.Fire(ctx, contract.LotID, statemachine.LotEventFinance, LotEventContext{
+				ContractID: &contract.ID, PaymentID: &paymentID, ActorID: &actorID,
+				Reason: fmt.Sprintf("Pago aprobado: %s", getPaymentTypeDescription(payment.PaymentType)),
+			}); err != nil {
+				logger.Error(fmt.Sprintf("[PaymentService] Failed to finance lot %d: %v", contract.LotID, err))
 			}
 		}
 		return s.updateContractBalance(ctx, payment.ContractID)
@@ -218,11 +249,24 @@ func (s *PaymentService) Approve(ctx context.Context, id uint, amount, interestA
 				models.NotificationTypePaymentApproved); err != nil {
 				return err
 			}
+			s.notifyParties(ctx, contract, "Pago aprobado",
+				fmt.Sprintf("Se aprobó un pago del contrato #%d (%s)", contract.ID, contract.Lot.Name),
+				models.NotificationTypePaymentApproved, models.PartyRoleCoBuyer)
 
 			// Email notification
 			// We need to ensure payment has contract loaded or passed correctly
 			payment.Contract = *contract // Attach Loaded contract
-			return s.emailSvc.SendPaymentApproved(ctx, payment)
+
+			// Attach the receipt PDF; the email still goes out without it if it cannot be rendered
+			var receiptPDF []byte
+			if receipt != nil {
+				pdf, err := s.receiptSvc.Render(ctx, receipt)
+				if err != nil {
+					logger.Error(fmt.Sprintf("[PaymentService] Failed to render receipt %s: %v", receipt.DisplayNumber(), err))
+				}
+				receiptPDF = pdf
+			}
+			return s.emailSvc.SendPaymentApproved(ctx, payment, receipt, receiptPDF)
 		}
 		return nil
 	})
@@ -271,6 +315,9 @@ func (s *PaymentService) Reject(ctx context.Context, id uint, actorID uint, reas
 			models.NotificationTypePaymentRejected); err != nil {
 			return err
 		}
+		s.notifyParties(ctx, contract, "Pago rechazado",
+			fmt.Sprintf("Se rechazó un pago del contrato #%d (%s)", contract.ID, contract.Lot.Name),
+			models.NotificationTypePaymentRejected, models.PartyRoleCoBuyer)
 		return s.emailSvc.SendPaymentRejected(ctx, contract, paymentAmount, paymentDueDate, reason)
 	})
 
@@ -305,11 +352,30 @@ func (s *PaymentService) CheckOverduePayments(ctx context.Context) error {
 			"Pago vencido",
 			"Tienes un pago vencido pendiente",
 			models.NotificationTypePaymentOverdue)
+
+		// Co-buyers share the debt and guarantors answer for it
+		s.notifyParties(ctx, &payment.Contract, "Pago vencido",
+			fmt.Sprintf("El contrato #%d (%s) tiene un pago vencido pendiente", payment.ContractID, payment.Contract.Lot.Name),
+			models.NotificationTypePaymentOverdue, models.PartyRoleCoBuyer, models.PartyRoleGuarantor)
 	}
 
 	return nil
 }
 
+// notifyParties sends an in-app notification to the registered parties of the contract with the given roles
+func (s *PaymentService) notifyParties(ctx context.Context, contract *models.Contract, title, message, notifType string, roles ...string) {
+	for _, role := range roles {
+		for _, party := range contract.PartiesWithRole(role) {
+			if party.UserID == nil {
+				continue
+			}
+			if err := s.notificationSvc.NotifyUser(ctx, *party.UserID, title, message, notifType); err != nil {
+				logger.Error(fmt.Sprintf("[PaymentService] Failed to notify party %d of contract %d: %v", party.ID, contract.ID, err))
+			}
+		}
+	}
+}
+
 // SendDailyPaymentReminderEmails sends overdue payment reminder emails to active users with active contracts.
 // Intended to run once per day. Groups overdue payments by applicant user and sends one email per user.
 func (s *PaymentService) SendDailyPaymentReminderEmails(ctx context.Context) error {
@@ -421,25 +487,37 @@ func (s *PaymentService) updateContractBalance(ctx context.Context, contractID u
 		return err
 	}
 
-	// Auto-close contract if balance is >= 0 (fully paid) and status is approved
-	if balance >= 0 && contract.Status == models.ContractStatusApproved {
+	// Auto-close contract if balance is >= 0 (fully paid) and status is approved (signed or not)
+	if balance >= 0 && contract.InForce() {
 		// Close the contract
 		now := time.Now()
+		fromStatus := contract.Status
 		contract.Status = models.ContractStatusClosed
 		contract.ClosedAt = &now
+		contract.StatusChangedAt = &now
+		contract.SLAEscalatedAt = nil
 		contract.Active = false
 
 		if err := s.contractRepo.Update(ctx, contract); err != nil {
 			return fmt.Errorf("failed to auto-close contract: %w", err)
 		}
 
-		// Update lot status to fully paid
-		lot, err := s.lotRepo.FindByID(ctx, contract.LotID)
-		if err == nil {
-			lot.Status = models.LotStatusFullyPaid
-			s.lotRepo.Update(ctx, lot)
+		// The balance check replaces the FSM close guard here; record the transition the same way
+		reason := "Contrato cerrado automáticamente (saldo pagado)"
+		if err := s.contractRepo.CreateStatusTransition(ctx, &models.ContractStatusTransition{
+			ContractID: contract.ID,
+			FromStatus: fromStatus,
+			ToStatus:   models.ContractStatusClosed,
+			Event:      "close",
+			Reason:     &reason,
+			CreatedAt:  now,
+		}); err != nil {
+			logger.Error(fmt.Sprintf("[PaymentService] Failed to record status transition of contract %d: %v", contract.ID, err))
 		}
 
+		// Lot is fully paid
+		s.lotStatusSvc.FireForContract(ctx, contract, statemachine.LotEventPayOff, nil, reason)
+
 		// Notify user about contract closure
 		s.worker.EnqueueAsync(func(ctx context.Context) error {
 			return s.notificationSvc.NotifyUser(ctx, contract.ApplicantUserID,
@@ -476,7 +554,7 @@ func (s *PaymentService) CalculateOverdueInterest(ctx context.Context) error {
 		}
 
 		// Filter: Only Approved active contracts
-		if payment.Contract.Status != models.ContractStatusApproved || !payment.Contract.Active {
+		if !payment.Contract.InForce() || !payment.Contract.Active {
 			continue
 		}
 
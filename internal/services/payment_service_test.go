@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/jobs"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUndoPaymentRepository struct {
+	repository.PaymentRepository
+	payments []models.Payment
+}
+
+func (m *mockUndoPaymentRepository) FindByID(ctx context.Context, id uint) (*models.Payment, error) {
+	for i := range m.payments {
+		if m.payments[i].ID == id {
+			payment := m.payments[i]
+			return &payment, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (m *mockUndoPaymentRepository) FindByContract(ctx context.Context, contractID uint) ([]models.Payment, error) {
+	return m.payments, nil
+}
+
+func (m *mockUndoPaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
+	for i := range m.payments {
+		if m.payments[i].ID == payment.ID {
+			m.payments[i] = *payment
+		}
+	}
+	return nil
+}
+
+type mockUndoContractRepository struct {
+	repository.ContractRepository
+	contract models.Contract
+}
+
+func (m *mockUndoContractRepository) FindByID(ctx context.Context, id uint) (*models.Contract, error) {
+	contract := m.contract
+	return &contract, nil
+}
+
+func (m *mockUndoContractRepository) FindByIDWithDetails(ctx context.Context, id uint) (*models.Contract, error) {
+	return nil, assert.AnError
+}
+
+func TestUndoPayment_RevertsLotWhenNoPaymentIsLeft(t *testing.T) {
+	paid := 5000.0
+	payments := &mockUndoPaymentRepository{payments: []models.Payment{
+		{ID: 1, ContractID: 7, Status: models.PaymentStatusPaid, PaidAmount: &paid, PaymentType: models.PaymentTypeReservation},
+		{ID: 2, ContractID: 7, Status: models.PaymentStatusPaid, PaidAmount: &paid, PaymentType: models.PaymentTypeDownPayment},
+		{ID: 3, ContractID: 7, Status: models.PaymentStatusPaid, PaidAmount: &paid, PaymentType: models.PaymentTypeFee},
+	}}
+	lots := &mockLotStatusRepository{lot: models.Lot{ID: 3, Status: models.LotStatusFinanced}}
+	svc := &PaymentService{
+		repo:         payments,
+		contractRepo: &mockUndoContractRepository{contract: models.Contract{ID: 7, LotID: 3, Status: models.ContractStatusApproved}},
+		ledgerRepo:   &mockLedgerRepository{},
+		lotStatusSvc: NewLotStatusService(lots),
+		worker:       jobs.NewWorker(0),
+	}
+	ctx := context.Background()
+
+	// Fees don't move the lot
+	require.NoError(t, svc.UndoPayment(ctx, 3, 1))
+	assert.Equal(t, models.LotStatusFinanced, lots.lot.Status)
+
+	// The approved reservation keeps the lot financed
+	require.NoError(t, svc.UndoPayment(ctx, 2, 1))
+	assert.Equal(t, models.LotStatusFinanced, lots.lot.Status)
+
+	require.NoError(t, svc.UndoPayment(ctx, 1, 1))
+	assert.Equal(t, models.LotStatusReserved, lots.lot.Status)
+	require.Len(t, lots.history, 1)
+	assert.Equal(t, statemachine.LotEventRevert, lots.history[0].Event)
+}
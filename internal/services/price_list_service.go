@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Repricing operations
+const (
+	RepriceOperationPercentChange      = "percent_change"        // +/- X% over the current effective price
+	RepriceOperationPricePerSquareUnit = "price_per_square_unit" // area * price per unit (Value or Project.PricePerSquareUnit)
+)
+
+// RepriceRequest describes a bulk repricing of a project's lots
+type RepriceRequest struct {
+	Name          string     `json:"name"`
+	Operation     string     `json:"operation"`
+	Value         float64    `json:"value"`
+	RoundTo       float64    `json:"round_to"`     // round to nearest N (0 = cents)
+	Statuses      []string   `json:"statuses"`     // default: available
+	NamePattern   string     `json:"name_pattern"` // glob on lot name, e.g. "Bloque C*"
+	LotIDs        []uint     `json:"lot_ids"`
+	PhaseID       *uint      `json:"phase_id"`       // only lots of this phase
+	BlockID       *uint      `json:"block_id"`       // only lots of this block
+	EffectiveFrom *time.Time `json:"effective_from"` // default: now
+	DryRun        bool       `json:"dry_run"`
+}
+
+// LotPriceChange is one row of a repricing diff
+type LotPriceChange struct {
+	LotID      uint    `json:"lot_id"`
+	LotName    string  `json:"lot_name"`
+	Status     string  `json:"status"`
+	PriceField string  `json:"price_field"`
+	OldPrice   float64 `json:"old_price"`
+	NewPrice   float64 `json:"new_price"`
+	Difference float64 `json:"difference"`
+}
+
+// RepriceResult is the outcome of a repricing (the diff, plus the price list when not a dry run)
+type RepriceResult struct {
+	DryRun        bool              `json:"dry_run"`
+	PriceList     *models.PriceList `json:"price_list,omitempty"`
+	Changes       []LotPriceChange  `json:"changes"`
+	LotsAffected  int               `json:"lots_affected"`
+	TotalOldValue float64           `json:"total_old_value"`
+	TotalNewValue float64           `json:"total_new_value"`
+}
+
+type PriceListService struct {
+	repo        repository.PriceListRepository
+	lotRepo     repository.LotRepository
+	projectRepo repository.ProjectRepository
+	auditSvc    *AuditService
+}
+
+func NewPriceListService(repo repository.PriceListRepository, lotRepo repository.LotRepository, projectRepo repository.ProjectRepository, auditSvc *AuditService) *PriceListService {
+	return &PriceListService{repo: repo, lotRepo: lotRepo, projectRepo: projectRepo, auditSvc: auditSvc}
+}
+
+func (s *PriceListService) FindByID(ctx context.Context, id uint) (*models.PriceList, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *PriceListService) FindByProject(ctx context.Context, projectID uint) ([]models.PriceList, error) {
+	return s.repo.FindByProject(ctx, projectID)
+}
+
+func (s *PriceListService) LotHistory(ctx context.Context, lotID uint) ([]models.LotPriceHistory, error) {
+	return s.repo.FindHistoryByLot(ctx, lotID)
+}
+
+// Reprice computes the price diff for the request. Unless DryRun is set, it stores a new price list
+// version and applies it immediately (or schedules it when EffectiveFrom is in the future).
+func (s *PriceListService) Reprice(ctx context.Context, projectID uint, req RepriceRequest, actorID uint) (*RepriceResult, error) {
+	if err := validateRepriceRequest(&req); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	lots, err := s.lotRepo.FindByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := computeLotPriceChanges(project, lots, req)
+	if err := validateLotPriceChanges(changes); err != nil {
+		return nil, err
+	}
+	result := &RepriceResult{DryRun: req.DryRun, Changes: changes, LotsAffected: len(changes)}
+	for _, ch := range changes {
+		result.TotalOldValue += ch.OldPrice
+		result.TotalNewValue += ch.NewPrice
+	}
+	if req.DryRun {
+		return result, nil
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("ningún lote coincide con los criterios o los precios no cambian")
+	}
+
+	version, err := s.repo.NextVersion(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute price list version: %w", err)
+	}
+
+	effectiveFrom := time.Now()
+	if req.EffectiveFrom != nil {
+		effectiveFrom = *req.EffectiveFrom
+	}
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("Lista de precios %s", effectiveFrom.Format("2006-01-02"))
+	}
+	opJSON, _ := json.Marshal(req)
+	op := string(opJSON)
+
+	list := &models.PriceList{
+		ProjectID:     projectID,
+		Version:       version,
+		Name:          name,
+		Status:        models.PriceListStatusScheduled,
+		Operation:     &op,
+		EffectiveFrom: effectiveFrom,
+		CreatedByID:   &actorID,
+	}
+	for _, ch := range changes {
+		list.Items = append(list.Items, models.PriceListItem{
+			LotID:      ch.LotID,
+			PriceField: ch.PriceField,
+			OldPrice:   ch.OldPrice,
+			NewPrice:   ch.NewPrice,
+		})
+	}
+	if err := s.repo.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to create price list: %w", err)
+	}
+
+	if !effectiveFrom.After(time.Now()) {
+		if err := s.repo.Apply(ctx, list, &actorID); err != nil {
+			return nil, fmt.Errorf("failed to apply price list: %w", err)
+		}
+		now := time.Now()
+		list.Status = models.PriceListStatusApplied
+		list.AppliedAt = &now
+	}
+
+	s.auditSvc.Log(ctx, actorID, "REPRICE", "Project", projectID,
+		fmt.Sprintf("Lista de precios v%d (%s): %d lotes, %s. Valor %.2f -> %.2f", list.Version, list.Status,
+			len(changes), req.Operation, result.TotalOldValue, result.TotalNewValue), "", "")
+
+	result.PriceList = list
+	return result, nil
+}
+
+// Cancel cancels a scheduled price list of the project before it takes effect
+func (s *PriceListService) Cancel(ctx context.Context, projectID, id uint, actorID uint) (*models.PriceList, error) {
+	list, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if list.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	if list.Status != models.PriceListStatusScheduled {
+		return nil, errors.New("solo se pueden cancelar listas de precios programadas")
+	}
+	list.Status = models.PriceListStatusCancelled
+	if err := s.repo.Update(ctx, list); err != nil {
+		return nil, err
+	}
+	s.auditSvc.Log(ctx, actorID, "CANCEL", "PriceList", list.ID,
+		fmt.Sprintf("Lista de precios v%d cancelada", list.Version), "", "")
+	return list, nil
+}
+
+// ApplyScheduled applies scheduled price lists whose effective date has been reached.
+// Items are recomputed against current lot prices; lots that no longer match the request
+// (e.g. sold since scheduling) are skipped.
+func (s *PriceListService) ApplyScheduled(ctx context.Context) error {
+	lists, err := s.repo.FindDueScheduled(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range lists {
+		list := &lists[i]
+		var req RepriceRequest
+		if list.Operation != nil {
+			_ = json.Unmarshal([]byte(*list.Operation), &req)
+		}
+		project, err := s.projectRepo.FindByID(ctx, list.ProjectID)
+		if err != nil {
+			logger.Error("[PriceList] project not found", "price_list_id", list.ID, "error", err)
+			continue
+		}
+		lots, err := s.lotRepo.FindByProject(ctx, list.ProjectID)
+		if err != nil {
+			return err
+		}
+		planned := make(map[uint]bool, len(list.Items))
+		for _, item := range list.Items {
+			planned[item.LotID] = true
+		}
+		list.Items = nil
+		for _, ch := range computeLotPriceChanges(project, lots, req) {
+			if !planned[ch.LotID] {
+				continue
+			}
+			if ch.NewPrice <= 0 {
+				logger.Error("[PriceList] skipping lot priced at zero or less", "price_list_id", list.ID, "lot_id", ch.LotID)
+				continue
+			}
+			list.Items = append(list.Items, models.PriceListItem{
+				PriceListID: list.ID,
+				LotID:       ch.LotID,
+				PriceField:  ch.PriceField,
+				OldPrice:    ch.OldPrice,
+				NewPrice:    ch.NewPrice,
+			})
+		}
+		if err := s.repo.Apply(ctx, list, list.CreatedByID); err != nil {
+			logger.Error("[PriceList] failed to apply scheduled price list", "price_list_id", list.ID, "error", err)
+			continue
+		}
+		actorID := uint(0)
+		if list.CreatedByID != nil {
+			actorID = *list.CreatedByID
+		}
+		s.auditSvc.Log(ctx, actorID, "APPLY", "PriceList", list.ID,
+			fmt.Sprintf("Lista de precios programada v%d aplicada a %d lotes", list.Version, len(list.Items)), "", "")
+	}
+	return nil
+}
+
+// RecordManualChange stores a lot price history entry for a single-lot edit
+func (s *PriceListService) RecordManualChange(ctx context.Context, lotID uint, field string, oldPrice, newPrice float64, actorID uint, reason string) {
+	entry := newManualPriceHistory(lotID, field, oldPrice, newPrice, actorID, reason)
+	if entry == nil {
+		return
+	}
+	if err := s.repo.CreateHistory(ctx, entry); err != nil {
+		logger.Error("[PriceList] failed to record lot price history", "lot_id", lotID, "error", err)
+	}
+}
+
+// newManualPriceHistory builds the price history entry of a manual change, or nil if the price did not change
+func newManualPriceHistory(lotID uint, field string, oldPrice, newPrice float64, actorID uint, reason string) *models.LotPriceHistory {
+	if oldPrice == newPrice {
+		return nil
+	}
+	old := oldPrice
+	entry := &models.LotPriceHistory{
+		LotID:         lotID,
+		PriceField:    field,
+		OldPrice:      &old,
+		NewPrice:      newPrice,
+		EffectiveFrom: time.Now(),
+		Reason:        &reason,
+	}
+	if actorID > 0 {
+		entry.ChangedByID = &actorID
+	}
+	return entry
+}
+
+// CurrentForProject returns the price list in effect for the project, or nil if none
+func (s *PriceListService) CurrentForProject(ctx context.Context, projectID uint) *models.PriceList {
+	list, err := s.repo.FindCurrentForProject(ctx, projectID, time.Now())
+	if err != nil {
+		return nil
+	}
+	return list
+}
+
+func validateRepriceRequest(req *RepriceRequest) error {
+	switch req.Operation {
+	case RepriceOperationPercentChange:
+		if req.Value <= -100 {
+			return errors.New("el porcentaje no puede reducir el precio a cero o menos")
+		}
+	case RepriceOperationPricePerSquareUnit:
+		if req.Value < 0 {
+			return errors.New("el precio por unidad no puede ser negativo")
+		}
+	default:
+		return fmt.Errorf("operación inválida: %s (percent_change, price_per_square_unit)", req.Operation)
+	}
+	if req.RoundTo < 0 {
+		return errors.New("el redondeo no puede ser negativo")
+	}
+	if req.NamePattern != "" {
+		if _, err := filepath.Match(req.NamePattern, ""); err != nil {
+			return fmt.Errorf("patrón de nombre inválido: %w", err)
+		}
+	}
+	if len(req.Statuses) == 0 {
+		req.Statuses = []string{models.LotStatusAvailable}
+	}
+	return nil
+}
+
+// validateLotPriceChanges rejects repricings that leave a lot at zero or a negative price
+// (e.g. a lot without area priced per square unit, or rounding a small price down)
+func validateLotPriceChanges(changes []LotPriceChange) error {
+	for _, ch := range changes {
+		if ch.NewPrice <= 0 {
+			return fmt.Errorf("el lote %s quedaría con precio %.2f; el precio debe ser mayor a cero", ch.LotName, ch.NewPrice)
+		}
+	}
+	return nil
+}
+
+// computeLotPriceChanges returns the price changes the request produces on the given lots.
+// Lots with an override price are adjusted on the override for percent changes and skipped for
+// price-per-unit repricing (same rule as ProjectService.Update).
+func computeLotPriceChanges(project *models.Project, lots []models.Lot, req RepriceRequest) []LotPriceChange {
+	statuses := make(map[string]bool, len(req.Statuses))
+	for _, st := range req.Statuses {
+		statuses[st] = true
+	}
+	if len(statuses) == 0 {
+		statuses[models.LotStatusAvailable] = true
+	}
+	var ids map[uint]bool
+	if len(req.LotIDs) > 0 {
+		ids = make(map[uint]bool, len(req.LotIDs))
+		for _, id := range req.LotIDs {
+			ids[id] = true
+		}
+	}
+	pattern := strings.ToLower(req.NamePattern)
+
+	var changes []LotPriceChange
+	for i := range lots {
+		lot := &lots[i]
+		if !statuses[lot.Status] {
+			continue
+		}
+		if ids != nil && !ids[lot.ID] {
+			continue
+		}
+		if req.PhaseID != nil && (lot.PhaseID == nil || *lot.PhaseID != *req.PhaseID) {
+			continue
+		}
+		if req.BlockID != nil && (lot.BlockID == nil || *lot.BlockID != *req.BlockID) {
+			continue
+		}
+		if pattern != "" {
+			if ok, _ := filepath.Match(pattern, strings.ToLower(lot.Name)); !ok {
+				continue
+			}
+		}
+
+		hasOverride := lot.OverridePrice != nil && *lot.OverridePrice > 0
+		field := models.PriceFieldPrice
+		oldPrice := lot.Price
+		var newPrice float64
+
+		switch req.Operation {
+		case RepriceOperationPercentChange:
+			if hasOverride {
+				field = models.PriceFieldOverridePrice
+				oldPrice = *lot.OverridePrice
+			}
+			newPrice = oldPrice * (1 + req.Value/100)
+		case RepriceOperationPricePerSquareUnit:
+			if hasOverride {
+				continue
+			}
+			ppu := req.Value
+			if ppu == 0 {
+				lot.Project = *project
+				ppu = lot.PricePerSquareUnit()
+			}
+			newPrice = lot.Area() * ppu
+		}
+
+		newPrice = roundToNearest(newPrice, req.RoundTo)
+		if newPrice == oldPrice {
+			continue
+		}
+		changes = append(changes, LotPriceChange{
+			LotID:      lot.ID,
+			LotName:    lot.Name,
+			Status:     lot.Status,
+			PriceField: field,
+			OldPrice:   oldPrice,
+			NewPrice:   newPrice,
+			Difference: roundCurrency(newPrice - oldPrice),
+		})
+	}
+	return changes
+}
+
+// roundToNearest rounds to the nearest multiple of step (cents when step is 0)
+func roundToNearest(amount, step float64) float64 {
+	if step <= 0 {
+		return roundCurrency(amount)
+	}
+	return roundCurrency(math.Round(amount/step) * step)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeLotPriceChanges_PercentWithRounding(t *testing.T) {
+	override := 60000.0
+	lots := []models.Lot{
+		{ID: 1, Name: "Lote 1", Status: models.LotStatusAvailable, Price: 50000},
+		{ID: 2, Name: "Lote 2", Status: models.LotStatusAvailable, Price: 50000, OverridePrice: &override},
+		{ID: 3, Name: "Lote 3", Status: models.LotStatusReserved, Price: 50000},
+	}
+	req := RepriceRequest{Operation: RepriceOperationPercentChange, Value: 7, RoundTo: 1000}
+	assert.NoError(t, validateRepriceRequest(&req))
+
+	changes := computeLotPriceChanges(&models.Project{}, lots, req)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, 54000.0, changes[0].NewPrice) // 53500 rounds up
+	assert.Equal(t, models.PriceFieldPrice, changes[0].PriceField)
+	assert.Equal(t, models.PriceFieldOverridePrice, changes[1].PriceField)
+	assert.Equal(t, 64000.0, changes[1].NewPrice) // 64200 rounds down
+}
+
+func TestComputeLotPriceChanges_PricePerUnitAndNamePattern(t *testing.T) {
+	override := 1.0
+	lots := []models.Lot{
+		{ID: 1, Name: "Bloque C-1", Status: models.LotStatusAvailable, Length: 10, Width: 20, Price: 1000},
+		{ID: 2, Name: "bloque c-2", Status: models.LotStatusAvailable, Length: 10, Width: 10, Price: 1000, OverridePrice: &override},
+		{ID: 3, Name: "Bloque D-1", Status: models.LotStatusAvailable, Length: 10, Width: 10, Price: 1000},
+	}
+	req := RepriceRequest{Operation: RepriceOperationPricePerSquareUnit, NamePattern: "Bloque C*"}
+	assert.NoError(t, validateRepriceRequest(&req))
+
+	changes := computeLotPriceChanges(&models.Project{PricePerSquareUnit: 25}, lots, req)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, uint(1), changes[0].LotID)
+	assert.Equal(t, 5000.0, changes[0].NewPrice)
+	assert.Equal(t, 4000.0, changes[0].Difference)
+}
+
+func TestValidateRepriceRequest(t *testing.T) {
+	assert.Error(t, validateRepriceRequest(&RepriceRequest{Operation: "double"}))
+	assert.Error(t, validateRepriceRequest(&RepriceRequest{Operation: RepriceOperationPercentChange, Value: -100}))
+	assert.Error(t, validateRepriceRequest(&RepriceRequest{Operation: RepriceOperationPercentChange, NamePattern: "["}))
+}
+
+func TestValidateLotPriceChanges_RejectsZeroPrice(t *testing.T) {
+	project := &models.Project{ID: 1}
+	lots := []models.Lot{
+		{ID: 1, Name: "Lote 1", Status: models.LotStatusAvailable, Price: 10000, Length: 10, Width: 20},
+		{ID: 2, Name: "Lote 2", Status: models.LotStatusAvailable, Price: 8000},
+	}
+	changes := computeLotPriceChanges(project, lots, RepriceRequest{Operation: RepriceOperationPricePerSquareUnit, Value: 60})
+	assert.ErrorContains(t, validateLotPriceChanges(changes), "Lote 2")
+
+	changes = computeLotPriceChanges(project, lots, RepriceRequest{Operation: RepriceOperationPercentChange, Value: -96, RoundTo: 1000}) // rounds down to 0
+	assert.Error(t, validateLotPriceChanges(changes))
+
+	changes = computeLotPriceChanges(project, lots, RepriceRequest{Operation: RepriceOperationPercentChange, Value: 5})
+	assert.NoError(t, validateLotPriceChanges(changes))
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"gorm.io/gorm"
+)
+
+// Project lifecycle errors
+var (
+	ErrProjectStatusManualChange = errors.New("el estado del proyecto no se puede editar manualmente; cambia con el flujo de aprobación")
+	ErrProjectNotPublished       = errors.New("el proyecto no está publicado para la venta")
+	ErrProjectReasonRequired     = errors.New("el motivo es requerido")
+)
+
+// projectTransitionLabels are the audit actions and Spanish descriptions of each lifecycle event
+var projectTransitionLabels = map[string][2]string{
+	statemachine.ProjectEventSubmit:  {"SUBMIT", "Proyecto enviado a aprobación"},
+	statemachine.ProjectEventApprove: {"APPROVE", "Proyecto aprobado y publicado"},
+	statemachine.ProjectEventReject:  {"REJECT", "Proyecto rechazado"},
+	statemachine.ProjectEventClose:   {"CLOSE", "Proyecto cerrado"},
+	statemachine.ProjectEventReopen:  {"REOPEN", "Proyecto reabierto"},
+}
+
+// Submit sends a draft project for approval
+func (s *ProjectService) Submit(ctx context.Context, id, actorID uint) (*models.Project, error) {
+	return s.transition(ctx, id, statemachine.ProjectEventSubmit, actorID, "")
+}
+
+// Approve approves a project pending approval and publishes it for sale
+func (s *ProjectService) Approve(ctx context.Context, id, actorID uint) (*models.Project, error) {
+	return s.transition(ctx, id, statemachine.ProjectEventApprove, actorID, "")
+}
+
+// Reject sends a project pending approval back to draft with the reason
+func (s *ProjectService) Reject(ctx context.Context, id, actorID uint, reason string) (*models.Project, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrProjectReasonRequired
+	}
+	return s.transition(ctx, id, statemachine.ProjectEventReject, actorID, reason)
+}
+
+// Close closes the sales of a published project; its existing contracts continue as usual
+func (s *ProjectService) Close(ctx context.Context, id, actorID uint, reason string) (*models.Project, error) {
+	return s.transition(ctx, id, statemachine.ProjectEventClose, actorID, reason)
+}
+
+// Reopen publishes a closed project again
+func (s *ProjectService) Reopen(ctx context.Context, id, actorID uint) (*models.Project, error) {
+	return s.transition(ctx, id, statemachine.ProjectEventReopen, actorID, "")
+}
+
+// transition fires a lifecycle event on the project and stores the new status with
+// compare-and-set, so two admins acting at once cannot both move the project
+func (s *ProjectService) transition(ctx context.Context, id uint, event string, actorID uint, reason string) (*models.Project, error) {
+	project, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	from := project.Status
+	if err := statemachine.NewProjectFSM(project).Fire(ctx, event); err != nil {
+		return nil, fmt.Errorf("%w: el proyecto está en estado %s", ErrInvalidState, from)
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{}
+	switch event {
+	case statemachine.ProjectEventSubmit:
+		project.SubmittedAt = &now
+		fields["submitted_at"] = now
+	case statemachine.ProjectEventApprove:
+		project.ApprovedBy, project.ApprovedAt = &actorID, &now
+		fields["approved_by"], fields["approved_at"] = actorID, now
+	case statemachine.ProjectEventClose:
+		project.ClosedAt = &now
+		fields["closed_at"] = now
+	case statemachine.ProjectEventReopen:
+		project.ClosedAt = nil
+		fields["closed_at"] = nil
+	}
+	project.StatusReason = nil
+	fields["status_reason"] = nil
+	if reason = strings.TrimSpace(reason); reason != "" {
+		project.StatusReason = &reason
+		fields["status_reason"] = reason
+	}
+
+	ok, err := s.repo.UpdateStatus(ctx, project.ID, from, project.Status, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project status: %w", err)
+	}
+	if !ok {
+		return nil, ErrInvalidState
+	}
+
+	label := projectTransitionLabels[event]
+	details := fmt.Sprintf("%s: %s (%s → %s)", label[1], project.Name, from, project.Status)
+	if reason != "" {
+		details += ". Motivo: " + reason
+	}
+	if err := s.auditSvc.Log(ctx, actorID, label[0], "Project", project.ID, details, "", ""); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
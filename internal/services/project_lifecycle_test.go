@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectService_TransitionGuards(t *testing.T) {
+	projects := &mockImportProjectRepository{projects: []models.Project{
+		{ID: 1, Name: "Residencial Las Flores", Status: models.ProjectStatusDraft},
+		{ID: 2, Name: "Villas del Sol", Status: models.ProjectStatusPendingApproval},
+	}}
+	svc := NewProjectService(projects, nil, nil, nil)
+	ctx := context.Background()
+
+	// A draft must be submitted before it can be approved or closed
+	_, err := svc.Approve(ctx, 1, 99)
+	assert.ErrorIs(t, err, ErrInvalidState)
+	_, err = svc.Close(ctx, 1, 99, "")
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	// Rejections need a reason
+	_, err = svc.Reject(ctx, 2, 99, "  ")
+	assert.ErrorIs(t, err, ErrProjectReasonRequired)
+	assert.Equal(t, models.ProjectStatusPendingApproval, projects.projects[1].Status)
+
+	_, err = svc.Approve(ctx, 3, 99)
+	assert.Error(t, err)
+}
+
+func TestLotHoldService_RefusesUnpublishedProject(t *testing.T) {
+	svc, repo := newTestLotHoldService()
+	repo.lot.Project.Status = models.ProjectStatusDraft
+
+	_, err := svc.Place(context.Background(), 3, 1, 10, "")
+	assert.ErrorIs(t, err, ErrProjectNotPublished)
+	assert.Equal(t, models.LotStatusAvailable, repo.lot.Status)
+}
@@ -0,0 +1,518 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Project phase/block errors
+var (
+	ErrSectionNotInProject = errors.New("la etapa o bloque no pertenece al proyecto")
+	ErrLotNotOnSale        = errors.New("la etapa o bloque del lote no está a la venta")
+)
+
+// SectionRequest is the input for creating or updating a phase or block. Nil fields are left
+// unchanged on update; a price of 0 or an empty delivery date clears the section's own value so
+// it inherits the parent's again.
+type SectionRequest struct {
+	Name               *string  `json:"name"`
+	Position           *int     `json:"position"`
+	Status             *string  `json:"status"`
+	PricePerSquareUnit *float64 `json:"price_per_square_unit"`
+	DeliveryDate       *string  `json:"delivery_date"`
+	Note               *string  `json:"note"`
+	PhaseID            *uint    `json:"phase_id"` // blocks only; 0 removes the block from its phase
+}
+
+// ProjectSectionService manages the optional Phase → Block → Lot hierarchy of large projects.
+// Phases and blocks carry their own price per square unit, delivery date and sale status, which
+// their lots inherit.
+type ProjectSectionService struct {
+	repo         repository.ProjectSectionRepository
+	projectRepo  repository.ProjectRepository
+	lotRepo      repository.LotRepository
+	priceListSvc *PriceListService
+	auditSvc     *AuditService
+}
+
+func NewProjectSectionService(repo repository.ProjectSectionRepository, projectRepo repository.ProjectRepository, lotRepo repository.LotRepository, priceListSvc *PriceListService, auditSvc *AuditService) *ProjectSectionService {
+	return &ProjectSectionService{repo: repo, projectRepo: projectRepo, lotRepo: lotRepo, priceListSvc: priceListSvc, auditSvc: auditSvc}
+}
+
+// Hierarchy returns the project's phases and blocks with their effective defaults and lot counts
+func (s *ProjectSectionService) Hierarchy(ctx context.Context, projectID uint) (*models.ProjectHierarchy, error) {
+	project, err := s.findProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	phases, err := s.repo.FindPhasesByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.repo.FindBlocksByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountLots(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	phaseCounts := map[uint]*models.SectionLotCounts{}
+	blockCounts := map[uint]*models.SectionLotCounts{}
+	tree := &models.ProjectHierarchy{ProjectID: projectID, Phases: []models.ProjectPhaseNode{}, UnphasedBlocks: []models.ProjectBlockNode{}}
+	for _, c := range counts {
+		tree.Lots.Add(c.Status, c.Count)
+		if c.PhaseID == nil && c.BlockID == nil {
+			tree.UnassignedLots.Add(c.Status, c.Count)
+		}
+		if c.PhaseID != nil {
+			if phaseCounts[*c.PhaseID] == nil {
+				phaseCounts[*c.PhaseID] = &models.SectionLotCounts{}
+			}
+			phaseCounts[*c.PhaseID].Add(c.Status, c.Count)
+		}
+		if c.BlockID != nil {
+			if blockCounts[*c.BlockID] == nil {
+				blockCounts[*c.BlockID] = &models.SectionLotCounts{}
+			}
+			blockCounts[*c.BlockID].Add(c.Status, c.Count)
+		}
+	}
+
+	phaseIndex := map[uint]int{}
+	for i := range phases {
+		p := &phases[i]
+		lot := models.Lot{Project: *project, Phase: p}
+		node := models.ProjectPhaseNode{
+			ProjectPhase:                *p,
+			EffectivePricePerSquareUnit: lot.PricePerSquareUnit(),
+			EffectiveDeliveryDate:       lot.DeliveryDate(),
+			BlockNodes:                  []models.ProjectBlockNode{},
+		}
+		if c := phaseCounts[p.ID]; c != nil {
+			node.Lots = *c
+		}
+		phaseIndex[p.ID] = len(tree.Phases)
+		tree.Phases = append(tree.Phases, node)
+	}
+	for i := range blocks {
+		b := &blocks[i]
+		lot := models.Lot{Project: *project, Block: b}
+		idx, inPhase := -1, false
+		if b.PhaseID != nil {
+			idx, inPhase = phaseIndex[*b.PhaseID]
+		}
+		if inPhase {
+			lot.Phase = &phases[idx]
+		}
+		node := models.ProjectBlockNode{
+			ProjectBlock:                *b,
+			EffectivePricePerSquareUnit: lot.PricePerSquareUnit(),
+			EffectiveDeliveryDate:       lot.DeliveryDate(),
+		}
+		if c := blockCounts[b.ID]; c != nil {
+			node.Lots = *c
+		}
+		if inPhase {
+			tree.Phases[idx].BlockNodes = append(tree.Phases[idx].BlockNodes, node)
+		} else {
+			tree.UnphasedBlocks = append(tree.UnphasedBlocks, node)
+		}
+	}
+	return tree, nil
+}
+
+// CreatePhase adds a phase to the project
+func (s *ProjectSectionService) CreatePhase(ctx context.Context, projectID uint, req SectionRequest, actorID uint) (*models.ProjectPhase, error) {
+	if _, err := s.findProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	phase := &models.ProjectPhase{ProjectID: projectID, Status: models.SectionStatusOnSale}
+	if _, err := applySectionRequest(req, &phase.Name, &phase.Position, &phase.Status, &phase.PricePerSquareUnit, &phase.DeliveryDate, &phase.Note); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindPhaseByName(ctx, projectID, phase.Name); err == nil {
+		return nil, fmt.Errorf("ya existe una etapa con el nombre %s", phase.Name)
+	}
+	if err := s.repo.CreatePhase(ctx, phase); err != nil {
+		return nil, fmt.Errorf("failed to create phase: %w", err)
+	}
+	s.audit(ctx, actorID, "CREATE", "ProjectPhase", phase.ID, fmt.Sprintf("Etapa creada: %s", phase.Name))
+	return phase, nil
+}
+
+// UpdatePhase updates a phase and reprices its available lots when its price per unit changes
+func (s *ProjectSectionService) UpdatePhase(ctx context.Context, projectID, phaseID uint, req SectionRequest, actorID uint) (*models.ProjectPhase, error) {
+	phase, err := s.findPhase(ctx, projectID, phaseID)
+	if err != nil {
+		return nil, err
+	}
+	oldName := phase.Name
+	priceChanged, err := applySectionRequest(req, &phase.Name, &phase.Position, &phase.Status, &phase.PricePerSquareUnit, &phase.DeliveryDate, &phase.Note)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(oldName, phase.Name) {
+		if _, err := s.repo.FindPhaseByName(ctx, projectID, phase.Name); err == nil {
+			return nil, fmt.Errorf("ya existe una etapa con el nombre %s", phase.Name)
+		}
+	}
+	if err := s.repo.UpdatePhase(ctx, phase); err != nil {
+		return nil, fmt.Errorf("failed to update phase: %w", err)
+	}
+	if priceChanged {
+		s.repriceLots(ctx, projectID, func(l *models.Lot) bool { return l.PhaseID != nil && *l.PhaseID == phase.ID }, actorID,
+			fmt.Sprintf("Cambio de precio por unidad de la etapa %s", phase.Name))
+	}
+	s.audit(ctx, actorID, "UPDATE", "ProjectPhase", phase.ID, fmt.Sprintf("Etapa actualizada: %s", phase.Name))
+	return phase, nil
+}
+
+// DeletePhase deletes a phase; its blocks and lots stay in the project without a phase
+func (s *ProjectSectionService) DeletePhase(ctx context.Context, projectID, phaseID uint, actorID uint) error {
+	phase, err := s.findPhase(ctx, projectID, phaseID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeletePhase(ctx, phase.ID); err != nil {
+		return fmt.Errorf("failed to delete phase: %w", err)
+	}
+	if phase.PricePerSquareUnit != nil {
+		s.repriceLots(ctx, projectID, func(l *models.Lot) bool { return l.PhaseID == nil }, actorID,
+			fmt.Sprintf("Etapa %s eliminada", phase.Name))
+	}
+	s.audit(ctx, actorID, "DELETE", "ProjectPhase", phase.ID, fmt.Sprintf("Etapa eliminada: %s", phase.Name))
+	return nil
+}
+
+// CreateBlock adds a block to the project, optionally inside one of its phases
+func (s *ProjectSectionService) CreateBlock(ctx context.Context, projectID uint, req SectionRequest, actorID uint) (*models.ProjectBlock, error) {
+	if _, err := s.findProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	block := &models.ProjectBlock{ProjectID: projectID, Status: models.SectionStatusOnSale}
+	if _, err := applySectionRequest(req, &block.Name, &block.Position, &block.Status, &block.PricePerSquareUnit, &block.DeliveryDate, &block.Note); err != nil {
+		return nil, err
+	}
+	if err := s.applyBlockPhase(ctx, block, req.PhaseID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindBlockByName(ctx, projectID, block.PhaseID, block.Name); err == nil {
+		return nil, fmt.Errorf("ya existe un bloque con el nombre %s", block.Name)
+	}
+	if err := s.repo.CreateBlock(ctx, block); err != nil {
+		return nil, fmt.Errorf("failed to create block: %w", err)
+	}
+	s.audit(ctx, actorID, "CREATE", "ProjectBlock", block.ID, fmt.Sprintf("Bloque creado: %s", block.Name))
+	return block, nil
+}
+
+// UpdateBlock updates a block, moving its lots along when it changes phase, and reprices its
+// available lots when their effective price per unit changes
+func (s *ProjectSectionService) UpdateBlock(ctx context.Context, projectID, blockID uint, req SectionRequest, actorID uint) (*models.ProjectBlock, error) {
+	block, err := s.findBlock(ctx, projectID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	oldName, oldPhaseID := block.Name, block.PhaseID
+	priceChanged, err := applySectionRequest(req, &block.Name, &block.Position, &block.Status, &block.PricePerSquareUnit, &block.DeliveryDate, &block.Note)
+	if err != nil {
+		return nil, err
+	}
+	if req.PhaseID != nil {
+		if err := s.applyBlockPhase(ctx, block, req.PhaseID); err != nil {
+			return nil, err
+		}
+	}
+	phaseChanged := !sameID(oldPhaseID, block.PhaseID)
+	if phaseChanged || !strings.EqualFold(oldName, block.Name) {
+		if other, err := s.repo.FindBlockByName(ctx, projectID, block.PhaseID, block.Name); err == nil && other.ID != block.ID {
+			return nil, fmt.Errorf("ya existe un bloque con el nombre %s", block.Name)
+		}
+	}
+	if err := s.repo.UpdateBlock(ctx, block); err != nil {
+		return nil, fmt.Errorf("failed to update block: %w", err)
+	}
+	if priceChanged || phaseChanged {
+		s.repriceLots(ctx, projectID, func(l *models.Lot) bool { return l.BlockID != nil && *l.BlockID == block.ID }, actorID,
+			fmt.Sprintf("Cambio de precio por unidad del bloque %s", block.Name))
+	}
+	s.audit(ctx, actorID, "UPDATE", "ProjectBlock", block.ID, fmt.Sprintf("Bloque actualizado: %s", block.Name))
+	return block, nil
+}
+
+// DeleteBlock deletes a block; its lots stay in their phase without a block
+func (s *ProjectSectionService) DeleteBlock(ctx context.Context, projectID, blockID uint, actorID uint) error {
+	block, err := s.findBlock(ctx, projectID, blockID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteBlock(ctx, block.ID); err != nil {
+		return fmt.Errorf("failed to delete block: %w", err)
+	}
+	if block.PricePerSquareUnit != nil {
+		s.repriceLots(ctx, projectID, func(l *models.Lot) bool { return l.BlockID == nil && sameID(l.PhaseID, block.PhaseID) }, actorID,
+			fmt.Sprintf("Bloque %s eliminado", block.Name))
+	}
+	s.audit(ctx, actorID, "DELETE", "ProjectBlock", block.ID, fmt.Sprintf("Bloque eliminado: %s", block.Name))
+	return nil
+}
+
+// lotStatusLabels are the Spanish lot status names used in reports
+var lotStatusLabels = map[string]string{
+	models.LotStatusAvailable: "Disponible",
+	models.LotStatusActive:    "Disponible",
+	models.LotStatusHeld:      "Apartado",
+	models.LotStatusReserved:  "Reservado",
+	models.LotStatusFinanced:  "Financiado",
+	models.LotStatusFullyPaid: "Pagado",
+}
+
+// InventoryCSV generates the lot inventory of a project, optionally filtered by phase/block
+// ("none" = lots without one) and grouped by phase or block with a subtotal row per group
+func (s *ProjectSectionService) InventoryCSV(ctx context.Context, projectID uint, phaseID, blockID, groupBy string) (*bytes.Buffer, error) {
+	if _, err := s.findProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	query := repository.NewListQuery()
+	query.PerPage = 0
+	query.Filters["phase_id"] = phaseID
+	query.Filters["block_id"] = blockID
+	query.Filters["group_by"] = groupBy
+	lots, _, err := s.lotRepo.List(ctx, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	header := []string{"Etapa", "Bloque", "Lote", "Estado", "Área", "Precio por Unidad", "Precio", "Fecha de Entrega"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	writeLot := func(l *models.Lot) error {
+		phase, block, delivery := "", "", ""
+		if l.Phase != nil {
+			phase = l.Phase.Name
+		}
+		if l.Block != nil {
+			block = l.Block.Name
+		}
+		if d := l.DeliveryDate(); d != nil {
+			delivery = *d
+		}
+		status := l.Status
+		if label, ok := lotStatusLabels[status]; ok {
+			status = label
+		}
+		return w.Write([]string{
+			phase, block, l.Name, status,
+			fmt.Sprintf("%.2f", l.Area()),
+			fmt.Sprintf("%.2f", l.PricePerSquareUnit()),
+			fmt.Sprintf("%.2f", l.EffectivePrice()),
+			delivery,
+		})
+	}
+
+	if groupBy == "" {
+		for i := range lots {
+			if err := writeLot(&lots[i]); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		groups := models.GroupLots(lots, groupBy)
+		next := 0
+		for _, g := range groups {
+			total := 0.0
+			for range g.Lots {
+				l := &lots[next]
+				next++
+				total += l.EffectivePrice()
+				if err := writeLot(l); err != nil {
+					return nil, err
+				}
+			}
+			name := g.Name
+			if g.ID == nil {
+				name = "Sin asignar"
+			}
+			subtotal := []string{"", "", fmt.Sprintf("Subtotal %s", name), fmt.Sprintf("%d lotes (%d disponibles)", g.Counts.Total, g.Counts.Available), "", "", fmt.Sprintf("%.2f", total), ""}
+			if err := w.Write(subtotal); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	w.Flush()
+	return b, w.Error()
+}
+
+func (s *ProjectSectionService) findProject(ctx context.Context, projectID uint) (*models.Project, error) {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return project, err
+}
+
+func (s *ProjectSectionService) findPhase(ctx context.Context, projectID, phaseID uint) (*models.ProjectPhase, error) {
+	phase, err := s.repo.FindPhaseByID(ctx, phaseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if phase.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	return phase, nil
+}
+
+func (s *ProjectSectionService) findBlock(ctx context.Context, projectID, blockID uint) (*models.ProjectBlock, error) {
+	block, err := s.repo.FindBlockByID(ctx, blockID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if block.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	return block, nil
+}
+
+// applyBlockPhase sets the block's phase, which must belong to the same project; 0 clears it
+func (s *ProjectSectionService) applyBlockPhase(ctx context.Context, block *models.ProjectBlock, phaseID *uint) error {
+	if phaseID == nil || *phaseID == 0 {
+		block.PhaseID, block.Phase = nil, nil
+		return nil
+	}
+	phase, err := s.repo.FindPhaseByID(ctx, *phaseID)
+	if err != nil || phase.ProjectID != block.ProjectID {
+		return ErrSectionNotInProject
+	}
+	id := phase.ID
+	block.PhaseID, block.Phase = &id, phase
+	return nil
+}
+
+// repriceLots recomputes the base price of the project's available lots without an override
+// price that match the filter, from their (new) effective price per square unit
+func (s *ProjectSectionService) repriceLots(ctx context.Context, projectID uint, match func(*models.Lot) bool, actorID uint, reason string) {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ProjectSectionService] Failed to load project %d for repricing: %v", projectID, err))
+		return
+	}
+	lots, err := s.lotRepo.FindByProject(ctx, projectID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ProjectSectionService] Failed to load lots of project %d for repricing: %v", projectID, err))
+		return
+	}
+	for i := range lots {
+		lot := &lots[i]
+		if lot.Status != models.LotStatusAvailable || !match(lot) {
+			continue
+		}
+		if lot.OverridePrice != nil && *lot.OverridePrice > 0 {
+			continue
+		}
+		lot.Project = *project
+		oldPrice := lot.Price
+		lot.Price = lot.Area() * lot.PricePerSquareUnit()
+		if lot.Price == oldPrice {
+			continue
+		}
+		if err := s.lotRepo.Update(ctx, lot); err != nil {
+			logger.Error(fmt.Sprintf("[ProjectSectionService] Failed to reprice lot %d: %v", lot.ID, err))
+			continue
+		}
+		s.priceListSvc.RecordManualChange(ctx, lot.ID, models.PriceFieldPrice, oldPrice, lot.Price, actorID, reason)
+	}
+}
+
+func (s *ProjectSectionService) audit(ctx context.Context, userID uint, action, entity string, id uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, entity, id, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ProjectSectionService] Failed to audit %s for %s %d: %v", action, entity, id, err))
+	}
+}
+
+// applySectionRequest validates the request and applies it to a phase's or block's fields.
+// Reports whether the price per unit changed.
+func applySectionRequest(req SectionRequest, name *string, position *int, status *string, price **float64, deliveryDate **string, note **string) (bool, error) {
+	if req.Name != nil {
+		*name = strings.TrimSpace(*req.Name)
+	}
+	if *name == "" {
+		return false, errors.New("el nombre es requerido")
+	}
+	if req.Position != nil {
+		*position = *req.Position
+	}
+	if req.Status != nil {
+		if !models.IsValidSectionStatus(*req.Status) {
+			return false, fmt.Errorf("estado inválido: %s", *req.Status)
+		}
+		*status = *req.Status
+	}
+	if req.Note != nil {
+		*note = req.Note
+	}
+
+	priceChanged := false
+	if req.PricePerSquareUnit != nil {
+		v := *req.PricePerSquareUnit
+		if v < 0 {
+			return false, errors.New("el precio por unidad no puede ser negativo")
+		}
+		old := 0.0
+		if *price != nil {
+			old = **price
+		}
+		priceChanged = old != v
+		if v == 0 {
+			*price = nil
+		} else {
+			*price = &v
+		}
+	}
+	if req.DeliveryDate != nil {
+		v := strings.TrimSpace(*req.DeliveryDate)
+		if v != "" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				return false, errors.New("formato de fecha de entrega inválido, use YYYY-MM-DD")
+			}
+		}
+		if v == "" {
+			*deliveryDate = nil
+		} else {
+			*deliveryDate = &v
+		}
+	}
+	return priceChanged, nil
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSectionRepository struct {
+	repository.ProjectSectionRepository
+	phases []models.ProjectPhase
+	blocks []models.ProjectBlock
+	counts []repository.SectionLotCount
+}
+
+func (m *mockSectionRepository) FindPhasesByProject(ctx context.Context, projectID uint) ([]models.ProjectPhase, error) {
+	return m.phases, nil
+}
+
+func (m *mockSectionRepository) FindBlocksByProject(ctx context.Context, projectID uint) ([]models.ProjectBlock, error) {
+	return m.blocks, nil
+}
+
+func (m *mockSectionRepository) CountLots(ctx context.Context, projectID uint) ([]repository.SectionLotCount, error) {
+	return m.counts, nil
+}
+
+func TestProjectSectionService_Hierarchy(t *testing.T) {
+	u := func(v uint) *uint { return &v }
+	phasePrice, blockPrice := 150.0, 180.0
+	phaseDate := "2027-06-30"
+	projects := &mockImportProjectRepository{projects: []models.Project{{ID: 1, PricePerSquareUnit: 100}}}
+	repo := &mockSectionRepository{
+		phases: []models.ProjectPhase{{ID: 1, ProjectID: 1, Name: "Etapa 1", Status: models.SectionStatusOnSale, PricePerSquareUnit: &phasePrice, DeliveryDate: &phaseDate}},
+		blocks: []models.ProjectBlock{
+			{ID: 1, ProjectID: 1, PhaseID: u(1), Name: "Bloque A", Status: models.SectionStatusOnSale},
+			{ID: 2, ProjectID: 1, PhaseID: u(1), Name: "Bloque B", Status: models.SectionStatusPlanned, PricePerSquareUnit: &blockPrice},
+			{ID: 3, ProjectID: 1, Name: "Bloque Suelto", Status: models.SectionStatusOnSale},
+		},
+		counts: []repository.SectionLotCount{
+			{PhaseID: u(1), BlockID: u(1), Status: models.LotStatusAvailable, Count: 3},
+			{PhaseID: u(1), BlockID: u(1), Status: models.LotStatusFinanced, Count: 1},
+			{PhaseID: u(1), BlockID: u(2), Status: models.LotStatusAvailable, Count: 2},
+			{BlockID: u(3), Status: models.LotStatusReserved, Count: 1},
+			{Status: models.LotStatusAvailable, Count: 4},
+		},
+	}
+	svc := NewProjectSectionService(repo, projects, nil, nil, nil)
+
+	tree, err := svc.Hierarchy(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, tree.Phases, 1)
+
+	phase := tree.Phases[0]
+	assert.Equal(t, 150.0, phase.EffectivePricePerSquareUnit)
+	assert.Equal(t, 6, phase.Lots.Total)
+	require.Len(t, phase.BlockNodes, 2)
+	// Block A inherits the phase defaults, block B sets its own price
+	assert.Equal(t, 150.0, phase.BlockNodes[0].EffectivePricePerSquareUnit)
+	assert.Equal(t, &phaseDate, phase.BlockNodes[0].EffectiveDeliveryDate)
+	assert.Equal(t, 4, phase.BlockNodes[0].Lots.Total)
+	assert.Equal(t, 1, phase.BlockNodes[0].Lots.Financed)
+	assert.Equal(t, 180.0, phase.BlockNodes[1].EffectivePricePerSquareUnit)
+
+	require.Len(t, tree.UnphasedBlocks, 1)
+	assert.Equal(t, 100.0, tree.UnphasedBlocks[0].EffectivePricePerSquareUnit)
+	assert.Equal(t, 4, tree.UnassignedLots.Total)
+	assert.Equal(t, 11, tree.Lots.Total)
+}
+
+func TestLotHoldService_RefusesLotNotOnSale(t *testing.T) {
+	svc, repo := newTestLotHoldService()
+	repo.lot.Block = &models.ProjectBlock{ID: 2, Status: models.SectionStatusPlanned}
+
+	_, err := svc.Place(context.Background(), 3, 1, 10, "")
+	assert.ErrorIs(t, err, ErrLotNotOnSale)
+	assert.Equal(t, models.LotStatusAvailable, repo.lot.Status)
+}
@@ -2,21 +2,26 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/sjperalta/fintera-api/internal/geo"
 	"github.com/sjperalta/fintera-api/internal/models"
 	"github.com/sjperalta/fintera-api/internal/repository"
+	"gorm.io/gorm"
 )
 
 type ProjectService struct {
-	repo     repository.ProjectRepository
-	lotRepo  repository.LotRepository
-	auditSvc *AuditService
+	repo         repository.ProjectRepository
+	lotRepo      repository.LotRepository
+	auditSvc     *AuditService
+	priceListSvc *PriceListService
 }
 
-func NewProjectService(repo repository.ProjectRepository, lotRepo repository.LotRepository, auditSvc *AuditService) *ProjectService {
-	return &ProjectService{repo: repo, lotRepo: lotRepo, auditSvc: auditSvc}
+func NewProjectService(repo repository.ProjectRepository, lotRepo repository.LotRepository, auditSvc *AuditService, priceListSvc *PriceListService) *ProjectService {
+	return &ProjectService{repo: repo, lotRepo: lotRepo, auditSvc: auditSvc, priceListSvc: priceListSvc}
 }
 
 func (s *ProjectService) FindByID(ctx context.Context, id uint) (*models.Project, error) {
@@ -32,6 +37,17 @@ func (s *ProjectService) Create(ctx context.Context, project *models.Project, ac
 	if project.GUID == "" {
 		project.GUID = uuid.New().String()
 	}
+	if err := normalizeGeometry(project.Geometry); err != nil {
+		return err
+	}
+	if err := normalizeHoldDurations(project, nil); err != nil {
+		return err
+	}
+	// New projects start as drafts and are published through the approval workflow
+	if project.Status != "" && project.Status != models.ProjectStatusDraft {
+		return ErrProjectStatusManualChange
+	}
+	project.Status = models.ProjectStatusDraft
 
 	// Auto-generate lots if lot count is specified
 	if project.LotCount > 0 {
@@ -65,6 +81,24 @@ func (s *ProjectService) Update(ctx context.Context, project *models.Project, ac
 	if project.GUID == "" {
 		project.GUID = existing.GUID
 	}
+	if project.Geometry == nil {
+		project.Geometry = existing.Geometry
+	} else if err := normalizeGeometry(project.Geometry); err != nil {
+		return err
+	}
+	if err := normalizeHoldDurations(project, existing); err != nil {
+		return err
+	}
+	// Status only changes through Submit/Approve/Reject/Close/Reopen
+	if project.Status != "" && project.Status != existing.Status {
+		return ErrProjectStatusManualChange
+	}
+	project.Status = existing.Status
+	project.SubmittedAt = existing.SubmittedAt
+	project.ApprovedBy = existing.ApprovedBy
+	project.ApprovedAt = existing.ApprovedAt
+	project.ClosedAt = existing.ClosedAt
+	project.StatusReason = existing.StatusReason
 
 	// Check if any of these fields changed: Unidad de Medida, Precio por Unidad, Tasa de Interés, Tasa de Comisión
 	// Check if any of these fields changed: Unidad de Medida, Precio por Unidad, Tasa de Interés, Tasas de Comisión
@@ -89,13 +123,20 @@ func (s *ProjectService) Update(ctx context.Context, project *models.Project, ac
 			}
 			if measurementUnitChanged {
 				lots[i].MeasurementUnit = &mu
+				if err := computeGeometryArea(&lots[i], mu); err != nil {
+					return err
+				}
 			}
+			oldPrice := lots[i].Price
+			lots[i].Project = *project
 			if pricePerUnitChanged && (lots[i].OverridePrice == nil || *lots[i].OverridePrice == 0) {
-				lots[i].Price = lots[i].Area() * project.PricePerSquareUnit
+				lots[i].Price = lots[i].Area() * lots[i].PricePerSquareUnit()
 			}
 			if err := s.lotRepo.Update(ctx, &lots[i]); err != nil {
 				return err
 			}
+			s.priceListSvc.RecordManualChange(ctx, lots[i].ID, models.PriceFieldPrice, oldPrice, lots[i].Price, actorID,
+				"Cambio de precio por unidad del proyecto")
 		}
 	}
 
@@ -105,6 +146,83 @@ func (s *ProjectService) Update(ctx context.Context, project *models.Project, ac
 	return s.auditSvc.Log(ctx, actorID, "UPDATE", "Project", project.ID, fmt.Sprintf("Proyecto actualizado: %s", project.Name), "", "")
 }
 
+// SetGeometry sets (or clears, when empty) the project's boundary polygon from GeoJSON or WKT
+func (s *ProjectService) SetGeometry(ctx context.Context, id uint, raw string, actorID uint) (*models.Project, error) {
+	project, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		project.Geometry = nil
+	} else {
+		g := models.Geometry(raw)
+		if err := normalizeGeometry(&g); err != nil {
+			return nil, err
+		}
+		project.Geometry = &g
+	}
+	lots := project.Lots
+	project.Lots = nil
+	if err := s.repo.Update(ctx, project); err != nil {
+		return nil, err
+	}
+	project.Lots = lots
+	if err := s.auditSvc.Log(ctx, actorID, "UPDATE", "Project", project.ID, fmt.Sprintf("Geometría del proyecto actualizada: %s", project.Name), "", ""); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// SiteMap returns a GeoJSON FeatureCollection with the project boundary (if any) and every lot
+// that has a polygon, with status and price properties for drawing the subdivision map.
+func (s *ProjectService) SiteMap(ctx context.Context, id uint) (*geo.FeatureCollection, error) {
+	project, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fc := geo.NewFeatureCollection()
+	if project.Geometry != nil {
+		fc.Features = append(fc.Features, geo.Feature{
+			Type:     "Feature",
+			ID:       fmt.Sprintf("project-%d", project.ID),
+			Geometry: json.RawMessage(*project.Geometry),
+			Properties: map[string]interface{}{
+				"kind":       "project",
+				"project_id": project.ID,
+				"name":       project.Name,
+			},
+		})
+	}
+	for i := range project.Lots {
+		lot := &project.Lots[i]
+		if lot.Geometry == nil {
+			continue
+		}
+		unit := project.MeasurementUnit
+		if lot.MeasurementUnit != nil {
+			unit = *lot.MeasurementUnit
+		}
+		fc.Features = append(fc.Features, geo.Feature{
+			Type:     "Feature",
+			ID:       lot.ID,
+			Geometry: json.RawMessage(*lot.Geometry),
+			Properties: map[string]interface{}{
+				"kind":                "lot",
+				"lot_id":              lot.ID,
+				"name":                lot.Name,
+				"status":              lot.Status,
+				"price":               lot.Price,
+				"effective_price":     lot.EffectivePrice(),
+				"area":                lot.Area(),
+				"measurement_unit":    unit,
+				"registration_number": lot.RegistrationNumber,
+			},
+		})
+	}
+	return fc, nil
+}
+
 func (s *ProjectService) Delete(ctx context.Context, id uint, actorID uint) error {
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return err
@@ -113,17 +231,28 @@ func (s *ProjectService) Delete(ctx context.Context, id uint, actorID uint) erro
 }
 
 type LotService struct {
-	repo        repository.LotRepository
-	projectRepo repository.ProjectRepository
-	auditSvc    *AuditService
+	repo         repository.LotRepository
+	projectRepo  repository.ProjectRepository
+	sectionRepo  repository.ProjectSectionRepository
+	auditSvc     *AuditService
+	priceListSvc *PriceListService
 }
 
-func NewLotService(repo repository.LotRepository, projectRepo repository.ProjectRepository, auditSvc *AuditService) *LotService {
-	return &LotService{repo: repo, projectRepo: projectRepo, auditSvc: auditSvc}
+func NewLotService(repo repository.LotRepository, projectRepo repository.ProjectRepository, sectionRepo repository.ProjectSectionRepository, auditSvc *AuditService, priceListSvc *PriceListService) *LotService {
+	return &LotService{repo: repo, projectRepo: projectRepo, sectionRepo: sectionRepo, auditSvc: auditSvc, priceListSvc: priceListSvc}
 }
 
 func (s *LotService) FindByID(ctx context.Context, id uint) (*models.Lot, error) {
-	return s.repo.FindByID(ctx, id)
+	lot, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lot.Status == models.LotStatusHeld {
+		if hold, err := s.repo.FindActiveHold(ctx, id); err == nil {
+			lot.ActiveHold = hold
+		}
+	}
+	return lot, nil
 }
 
 func (s *LotService) FindByProject(ctx context.Context, projectID uint) ([]models.Lot, error) {
@@ -135,14 +264,25 @@ func (s *LotService) List(ctx context.Context, projectID uint, query *repository
 }
 
 func (s *LotService) Create(ctx context.Context, lot *models.Lot, actorID uint) error {
-	if err := s.repo.Create(ctx, lot); err != nil {
-		return err
+	// New lots always start available; status then follows contract/payment events
+	if lot.Status != "" && lot.Status != models.LotStatusAvailable {
+		return ErrLotStatusManualChange
 	}
-	// Keep project lot_count in sync
+	lot.Status = models.LotStatusAvailable
 	project, err := s.projectRepo.FindByID(ctx, lot.ProjectID)
 	if err != nil {
 		return err
 	}
+	if err := s.assignSection(ctx, lot, nil); err != nil {
+		return err
+	}
+	if err := applyLotGeometry(lot, project); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, lot); err != nil {
+		return err
+	}
+	// Keep project lot_count in sync
 	project.LotCount++
 	if err := s.projectRepo.Update(ctx, project); err != nil {
 		return err
@@ -160,9 +300,11 @@ func (s *LotService) Update(ctx context.Context, lot *models.Lot, actorID uint)
 	if lot.ProjectID == 0 {
 		lot.ProjectID = existingLot.ProjectID
 	}
-	if lot.Status == "" {
-		lot.Status = existingLot.Status
+	// Status only changes through contract/payment events (lot FSM)
+	if lot.Status != "" && lot.Status != existingLot.Status {
+		return ErrLotStatusManualChange
 	}
+	lot.Status = existingLot.Status
 
 	// Preserve metadata fields if not provided (zero/nil)
 	if lot.Address == nil {
@@ -193,18 +335,114 @@ func (s *LotService) Update(ctx context.Context, lot *models.Lot, actorID uint)
 	// Carry over project association for ToResponse
 	lot.Project = existingLot.Project
 
-	// Recalculate base price if 0
-	if lot.Price == 0 && lot.Project.PricePerSquareUnit > 0 {
-		lot.Price = lot.Area() * lot.Project.PricePerSquareUnit
+	// Phase/block: nil keeps the current one, 0 clears it
+	if err := s.assignSection(ctx, lot, existingLot); err != nil {
+		return err
+	}
+
+	if lot.Geometry == nil {
+		lot.Geometry = existingLot.Geometry
+		lot.GeometryArea = existingLot.GeometryArea
+	} else if err := applyLotGeometry(lot, &lot.Project); err != nil {
+		return err
+	}
+
+	// Recalculate base price if 0 (block/phase/project price per unit)
+	if lot.Price == 0 && lot.PricePerSquareUnit() > 0 {
+		lot.Price = lot.Area() * lot.PricePerSquareUnit()
 	}
 
 	// Ideally we should use a PATCH approach, but for now this fixes the critical data loss
 	if err := s.repo.Update(ctx, lot); err != nil {
 		return err
 	}
+
+	// Keep price history for manual edits
+	s.priceListSvc.RecordManualChange(ctx, lot.ID, models.PriceFieldPrice, existingLot.Price, lot.Price, actorID, "Edición manual del lote")
+	oldOverride, newOverride := 0.0, 0.0
+	if existingLot.OverridePrice != nil {
+		oldOverride = *existingLot.OverridePrice
+	}
+	if lot.OverridePrice != nil {
+		newOverride = *lot.OverridePrice
+	}
+	s.priceListSvc.RecordManualChange(ctx, lot.ID, models.PriceFieldOverridePrice, oldOverride, newOverride, actorID, "Edición manual del lote")
 	return s.auditSvc.Log(ctx, actorID, "UPDATE", "Lot", lot.ID, fmt.Sprintf("Lote actualizado: %s", lot.Name), "", "")
 }
 
+// assignSection validates the lot's phase and block against its project and loads them. A block
+// determines the phase; nil keeps the existing lot's value and 0 clears it.
+func (s *LotService) assignSection(ctx context.Context, lot *models.Lot, existing *models.Lot) error {
+	if existing != nil {
+		if lot.PhaseID == nil {
+			lot.PhaseID = existing.PhaseID
+		}
+		if lot.BlockID == nil {
+			lot.BlockID = existing.BlockID
+		}
+	}
+	if lot.PhaseID != nil && *lot.PhaseID == 0 {
+		lot.PhaseID = nil
+	}
+	if lot.BlockID != nil && *lot.BlockID == 0 {
+		lot.BlockID = nil
+	}
+	lot.Phase, lot.Block = nil, nil
+
+	if lot.BlockID != nil {
+		block, err := s.sectionRepo.FindBlockByID(ctx, *lot.BlockID)
+		if err != nil || block.ProjectID != lot.ProjectID {
+			return ErrSectionNotInProject
+		}
+		lot.Block = block
+		lot.PhaseID = block.PhaseID
+	}
+	if lot.PhaseID != nil {
+		phase, err := s.sectionRepo.FindPhaseByID(ctx, *lot.PhaseID)
+		if err != nil || phase.ProjectID != lot.ProjectID {
+			return ErrSectionNotInProject
+		}
+		lot.Phase = phase
+	}
+	return nil
+}
+
+// StatusHistory returns the lot status transitions, newest first
+func (s *LotService) StatusHistory(ctx context.Context, lotID uint) ([]models.LotStatusHistory, error) {
+	return s.repo.FindStatusHistory(ctx, lotID)
+}
+
+// SetGeometry sets (or clears, when empty) a lot's polygon from GeoJSON or WKT and recomputes its
+// area. Lots of another project are not found.
+func (s *LotService) SetGeometry(ctx context.Context, projectID, id uint, raw string, actorID uint) (*models.Lot, error) {
+	lot, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if lot.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	if raw == "" {
+		lot.Geometry, lot.GeometryArea = nil, nil
+	} else {
+		g := models.Geometry(raw)
+		lot.Geometry = &g
+		if err := applyLotGeometry(lot, &lot.Project); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, lot); err != nil {
+		return nil, err
+	}
+	if err := s.auditSvc.Log(ctx, actorID, "UPDATE", "Lot", lot.ID, fmt.Sprintf("Geometría del lote actualizada: %s", lot.Name), "", ""); err != nil {
+		return nil, err
+	}
+	return lot, nil
+}
+
 func (s *LotService) Delete(ctx context.Context, id uint, actorID uint) error {
 	lot, err := s.repo.FindByID(ctx, id)
 	if err != nil {
@@ -227,3 +465,72 @@ func (s *LotService) Delete(ctx context.Context, id uint, actorID uint) error {
 	}
 	return s.auditSvc.Log(ctx, actorID, "DELETE", "Lot", id, "Lote eliminado", "", "")
 }
+
+// normalizeHoldDurations converts hold_duration_hours to minutes and keeps the existing (or
+// default) lot hold and waitlist windows when none were sent
+func normalizeHoldDurations(project, existing *models.Project) error {
+	if project.HoldDurationHours != nil {
+		project.HoldDurationMinutes = *project.HoldDurationHours * 60
+		project.HoldDurationHours = nil
+	}
+	if project.HoldDurationMinutes < 0 || project.WaitlistHoldMinutes < 0 {
+		return errors.New("la duración del apartado de lotes debe ser positiva")
+	}
+	if existing == nil {
+		existing = &models.Project{}
+	}
+	if project.HoldDurationMinutes == 0 {
+		project.HoldDurationMinutes = existing.HoldDuration()
+	}
+	if project.WaitlistHoldMinutes == 0 {
+		project.WaitlistHoldMinutes = existing.WaitlistHoldDuration()
+	}
+	return nil
+}
+
+// normalizeGeometry converts a GeoJSON/WKT geometry in place to normalized GeoJSON
+func normalizeGeometry(g *models.Geometry) error {
+	if g == nil {
+		return nil
+	}
+	normalized, err := geo.Normalize(string(*g))
+	if err != nil {
+		return err
+	}
+	*g = models.Geometry(normalized)
+	return nil
+}
+
+// applyLotGeometry normalizes the lot polygon (if any) and computes its area in the lot's unit
+func applyLotGeometry(lot *models.Lot, project *models.Project) error {
+	if lot.Geometry == nil {
+		lot.GeometryArea = nil
+		return nil
+	}
+	if err := normalizeGeometry(lot.Geometry); err != nil {
+		return err
+	}
+	unit := ""
+	if project != nil {
+		unit = project.MeasurementUnit
+	}
+	if lot.MeasurementUnit != nil {
+		unit = *lot.MeasurementUnit
+	}
+	return computeGeometryArea(lot, unit)
+}
+
+// computeGeometryArea sets GeometryArea from the (normalized) polygon in the given unit
+func computeGeometryArea(lot *models.Lot, unit string) error {
+	if lot.Geometry == nil {
+		lot.GeometryArea = nil
+		return nil
+	}
+	m2, err := geo.AreaSquareMeters(string(*lot.Geometry))
+	if err != nil {
+		return err
+	}
+	area := geo.ConvertArea(m2, unit)
+	lot.GeometryArea = &area
+	return nil
+}
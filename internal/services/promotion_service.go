@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+)
+
+type PromotionService struct {
+	repo     repository.PromotionRepository
+	auditSvc *AuditService
+}
+
+func NewPromotionService(repo repository.PromotionRepository, auditSvc *AuditService) *PromotionService {
+	return &PromotionService{repo: repo, auditSvc: auditSvc}
+}
+
+func (s *PromotionService) FindByID(ctx context.Context, id uint) (*models.Promotion, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *PromotionService) List(ctx context.Context, query *repository.ListQuery) ([]models.Promotion, int64, error) {
+	return s.repo.List(ctx, query)
+}
+
+func (s *PromotionService) Create(ctx context.Context, promotion *models.Promotion, actorID uint) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	promotion.CreatedByID = &actorID
+	promotion.UsesCount = 0
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		return err
+	}
+	return s.auditSvc.Log(ctx, actorID, "CREATE", "Promotion", promotion.ID,
+		fmt.Sprintf("Promoción creada: %s (%s %.2f)", promotion.Name, promotion.DiscountType, promotion.Value), "", "")
+}
+
+func (s *PromotionService) Update(ctx context.Context, promotion *models.Promotion, actorID uint) error {
+	existing, err := s.repo.FindByID(ctx, promotion.ID)
+	if err != nil {
+		return err
+	}
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+
+	// Usage counters are owned by contract creation, never by the API
+	promotion.UsesCount = existing.UsesCount
+	promotion.CreatedByID = existing.CreatedByID
+	promotion.CreatedAt = existing.CreatedAt
+
+	if err := s.repo.Update(ctx, promotion); err != nil {
+		return err
+	}
+	return s.auditSvc.Log(ctx, actorID, "UPDATE", "Promotion", promotion.ID,
+		fmt.Sprintf("Promoción actualizada: %s", promotion.Name), "", "")
+}
+
+func (s *PromotionService) Delete(ctx context.Context, id uint, actorID uint) error {
+	promotion, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if promotion.UsesCount > 0 {
+		// Keep promotions referenced by contracts; deactivate instead
+		promotion.Active = false
+		if err := s.repo.Update(ctx, promotion); err != nil {
+			return err
+		}
+		return s.auditSvc.Log(ctx, actorID, "DEACTIVATE", "Promotion", id,
+			fmt.Sprintf("Promoción desactivada (tiene %d usos): %s", promotion.UsesCount, promotion.Name), "", "")
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.auditSvc.Log(ctx, actorID, "DELETE", "Promotion", id,
+		fmt.Sprintf("Promoción eliminada: %s", promotion.Name), "", "")
+}
+
+// ApplyToContract prices the contract from its base amount and every eligible promotion.
+// It sets contract.Amount and records the breakdown in contract.LineItems (saved with the
+// contract). A reservation waiver discounts the reservation fee from the price; the reservation is
+// part of the price, so it is still collected. Each applied promotion consumes one use; callers
+// must call ReleaseUses if the contract is not persisted. On error the uses already consumed are
+// released.
+func (s *PromotionService) ApplyToContract(ctx context.Context, contract *models.Contract, lot *models.Lot) error {
+	basePrice := lot.EffectivePrice()
+	if contract.Amount != nil && *contract.Amount > 0 {
+		basePrice = *contract.Amount
+	}
+
+	contract.LineItems = []models.ContractLineItem{{
+		ItemType:    models.LineItemTypeBasePrice,
+		Description: fmt.Sprintf("Precio de lista - %s", lot.Name),
+		Amount:      basePrice,
+	}}
+
+	promotions, err := s.repo.FindActiveForProject(ctx, lot.ProjectID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to load promotions: %w", err)
+	}
+
+	reserveAmount := 0.0
+	if contract.ReserveAmount != nil {
+		reserveAmount = *contract.ReserveAmount
+	}
+
+	total := basePrice
+	now := time.Now()
+	for i := range promotions {
+		promo := &promotions[i]
+		if !promo.IsEligible(lot, contract.FinancingType, now) {
+			continue
+		}
+		discount := roundCurrency(promo.DiscountFor(basePrice, reserveAmount))
+		if discount > total {
+			discount = total
+		}
+		if discount <= 0 {
+			continue
+		}
+
+		ok, err := s.repo.IncrementUses(ctx, promo.ID)
+		if err != nil {
+			s.ReleaseUses(ctx, contract.LineItems)
+			return fmt.Errorf("failed to consume promotion: %w", err)
+		}
+		if !ok {
+			// Usage limit reached by a concurrent contract
+			continue
+		}
+
+		promoID := promo.ID
+		contract.LineItems = append(contract.LineItems, models.ContractLineItem{
+			PromotionID: &promoID,
+			ItemType:    models.LineItemTypeDiscount,
+			Description: promo.Name,
+			Amount:      -discount,
+		})
+		total -= discount
+	}
+
+	contract.Amount = &total
+	return nil
+}
+
+// ReleaseUses gives back the uses consumed by ApplyToContract (e.g. when contract creation fails,
+// the contract is cancelled or a rejected contract is deleted)
+func (s *PromotionService) ReleaseUses(ctx context.Context, items []models.ContractLineItem) {
+	for _, item := range items {
+		if item.PromotionID == nil {
+			continue
+		}
+		_ = s.repo.DecrementUses(ctx, *item.PromotionID)
+	}
+}
+
+func validatePromotion(p *models.Promotion) error {
+	if p.Name == "" {
+		return errors.New("el nombre de la promoción es requerido")
+	}
+	if !models.IsValidDiscountType(p.DiscountType) {
+		return fmt.Errorf("tipo de descuento inválido: %s", p.DiscountType)
+	}
+	if p.DiscountType == models.DiscountTypePercentOff && (p.Value <= 0 || p.Value > 100) {
+		return errors.New("el porcentaje de descuento debe estar entre 0 y 100")
+	}
+	if p.DiscountType == models.DiscountTypeFixedAmount && p.Value <= 0 {
+		return errors.New("el monto de descuento debe ser mayor a cero")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && p.EndsAt.Before(*p.StartsAt) {
+		return errors.New("la fecha de fin no puede ser anterior a la fecha de inicio")
+	}
+	if p.MaxUses != nil && *p.MaxUses < 1 {
+		return errors.New("el límite de usos debe ser mayor a cero")
+	}
+	return nil
+}
+
+// roundCurrency rounds an amount to 2 decimals
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
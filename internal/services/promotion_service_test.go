@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPromotionRepository struct {
+	repository.PromotionRepository
+	promotions []models.Promotion
+	uses       map[uint]int
+	failing    uint // IncrementUses fails for this promotion
+}
+
+func (m *mockPromotionRepository) FindActiveForProject(ctx context.Context, projectID uint, at time.Time) ([]models.Promotion, error) {
+	return m.promotions, nil
+}
+
+func (m *mockPromotionRepository) IncrementUses(ctx context.Context, id uint) (bool, error) {
+	if m.uses == nil {
+		m.uses = map[uint]int{}
+	}
+	if id == m.failing {
+		return false, assert.AnError
+	}
+	for _, p := range m.promotions {
+		if p.ID == id && p.MaxUses != nil && p.UsesCount+m.uses[id] >= *p.MaxUses {
+			return false, nil
+		}
+	}
+	m.uses[id]++
+	return true, nil
+}
+
+func (m *mockPromotionRepository) DecrementUses(ctx context.Context, id uint) error {
+	m.uses[id]--
+	return nil
+}
+
+func TestApplyToContract_PercentOffForCash(t *testing.T) {
+	cash := models.FinancingTypeCash
+	repo := &mockPromotionRepository{promotions: []models.Promotion{
+		{ID: 1, Name: "10% contado", DiscountType: models.DiscountTypePercentOff, Value: 10, FinancingType: &cash, Active: true},
+	}}
+	svc := NewPromotionService(repo, nil)
+
+	lot := &models.Lot{ID: 5, ProjectID: 1, Name: "Lote 5", Price: 100000}
+
+	contract := &models.Contract{FinancingType: models.FinancingTypeCash}
+	assert.NoError(t, svc.ApplyToContract(context.Background(), contract, lot))
+	assert.Equal(t, 90000.0, *contract.Amount)
+	assert.Len(t, contract.LineItems, 2)
+	assert.Equal(t, models.LineItemTypeBasePrice, contract.LineItems[0].ItemType)
+	assert.Equal(t, -10000.0, contract.LineItems[1].Amount)
+	assert.Equal(t, 1, repo.uses[1])
+
+	// Direct financing is not eligible
+	direct := &models.Contract{FinancingType: models.FinancingTypeDirect}
+	assert.NoError(t, svc.ApplyToContract(context.Background(), direct, lot))
+	assert.Equal(t, 100000.0, *direct.Amount)
+	assert.Len(t, direct.LineItems, 1)
+}
+
+func TestApplyToContract_FixedAmountOnLotsAndWaiver(t *testing.T) {
+	repo := &mockPromotionRepository{promotions: []models.Promotion{
+		{ID: 1, Name: "Bloque C", DiscountType: models.DiscountTypeFixedAmount, Value: 5000, LotIDs: []uint{7, 8}, Active: true},
+		{ID: 2, Name: "Reserva gratis", DiscountType: models.DiscountTypeWaiveReservation, Active: true},
+	}}
+	svc := NewPromotionService(repo, nil)
+
+	reserve := 2000.0
+	contract := &models.Contract{FinancingType: models.FinancingTypeDirect, ReserveAmount: &reserve}
+	lot := &models.Lot{ID: 7, ProjectID: 1, Name: "Lote 7", Price: 50000}
+
+	assert.NoError(t, svc.ApplyToContract(context.Background(), contract, lot))
+	assert.Equal(t, 43000.0, *contract.Amount)
+	assert.Equal(t, 2000.0, *contract.ReserveAmount) // the waiver is a discount; the reservation is still collected
+	assert.Len(t, contract.LineItems, 3)
+
+	// Lot outside the list only gets the waiver
+	reserve2 := 2000.0
+	other := &models.Contract{FinancingType: models.FinancingTypeDirect, ReserveAmount: &reserve2}
+	assert.NoError(t, svc.ApplyToContract(context.Background(), other, &models.Lot{ID: 9, ProjectID: 1, Price: 50000}))
+	assert.Equal(t, 48000.0, *other.Amount)
+}
+
+func TestApplyToContract_UsageLimit(t *testing.T) {
+	maxUses := 1
+	repo := &mockPromotionRepository{promotions: []models.Promotion{
+		{ID: 1, Name: "Primer cliente", DiscountType: models.DiscountTypeFixedAmount, Value: 1000, MaxUses: &maxUses, Active: true},
+	}}
+	svc := NewPromotionService(repo, nil)
+	lot := &models.Lot{ID: 1, ProjectID: 1, Price: 10000}
+
+	first := &models.Contract{}
+	assert.NoError(t, svc.ApplyToContract(context.Background(), first, lot))
+	assert.Equal(t, 9000.0, *first.Amount)
+
+	second := &models.Contract{}
+	assert.NoError(t, svc.ApplyToContract(context.Background(), second, lot))
+	assert.Equal(t, 10000.0, *second.Amount)
+}
+
+func TestApplyToContract_ReleasesUsesOnFailure(t *testing.T) {
+	exhausted := 0
+	repo := &mockPromotionRepository{promotions: []models.Promotion{
+		{ID: 1, Name: "Lanzamiento", DiscountType: models.DiscountTypeFixedAmount, Value: 1000, Active: true},
+		{ID: 2, Name: "Agotada", DiscountType: models.DiscountTypeFixedAmount, Value: 500, MaxUses: &exhausted, Active: true},
+		{ID: 3, Name: "Contado", DiscountType: models.DiscountTypePercentOff, Value: 5, Active: true},
+	}, failing: 3}
+	svc := NewPromotionService(repo, nil)
+
+	err := svc.ApplyToContract(context.Background(), &models.Contract{}, &models.Lot{ID: 1, ProjectID: 1, Price: 10000})
+	assert.Error(t, err)
+	assert.Equal(t, 0, repo.uses[1], "the use consumed before the failure is given back")
+	assert.Equal(t, 0, repo.uses[2])
+}
+
+func TestPromotionIsEligible_DateRange(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+	p := models.Promotion{Active: true, StartsAt: &start, EndsAt: &end}
+	lot := &models.Lot{ID: 1, ProjectID: 1}
+
+	assert.True(t, p.IsEligible(lot, models.FinancingTypeDirect, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, p.IsEligible(lot, models.FinancingTypeDirect, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, p.IsEligible(lot, models.FinancingTypeDirect, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)))
+}
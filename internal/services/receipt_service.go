@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ReceiptBreakdown splits the amount received for a payment
+type ReceiptBreakdown struct {
+	Principal  float64
+	Interest   float64
+	Prepayment float64
+}
+
+// Total returns the amount received
+func (b ReceiptBreakdown) Total() float64 {
+	return b.Principal + b.Interest + b.Prepayment
+}
+
+// paymentReceiptBreakdown splits the paid amount of an approved payment: interest is covered first,
+// then principal, and what exceeds the installment is a prepayment of capital
+func paymentReceiptBreakdown(payment *models.Payment) ReceiptBreakdown {
+	paid := 0.0
+	if payment.PaidAmount != nil {
+		paid = *payment.PaidAmount
+	}
+	interest := 0.0
+	if payment.InterestAmount != nil {
+		interest = math.Min(*payment.InterestAmount, paid)
+	}
+	prepayment := math.Max(0, paid-payment.Amount-interest)
+	return ReceiptBreakdown{
+		Principal:  paid - interest - prepayment,
+		Interest:   interest,
+		Prepayment: prepayment,
+	}
+}
+
+// ReissueReceiptRequest is the reason given to void a receipt and issue a new one
+type ReissueReceiptRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ReceiptService issues the official numbered receipts of approved payments
+type ReceiptService struct {
+	repo        repository.PaymentReceiptRepository
+	paymentRepo repository.PaymentRepository
+	reportSvc   *ReportService
+	auditSvc    *AuditService
+	storage     *storage.LocalStorage
+	perProject  bool
+}
+
+func NewReceiptService(
+	repo repository.PaymentReceiptRepository,
+	paymentRepo repository.PaymentRepository,
+	reportSvc *ReportService,
+	auditSvc *AuditService,
+	storage *storage.LocalStorage,
+	cfg *config.Config,
+) *ReceiptService {
+	return &ReceiptService{
+		repo:        repo,
+		paymentRepo: paymentRepo,
+		reportSvc:   reportSvc,
+		auditSvc:    auditSvc,
+		storage:     storage,
+		perProject:  cfg != nil && cfg.ReceiptNumbering == "project",
+	}
+}
+
+// Issue numbers a receipt for an approved payment (loaded with its contract and project). When the
+// payment had a voided receipt, the new one records that it replaces it.
+func (s *ReceiptService) Issue(ctx context.Context, payment *models.Payment, breakdown ReceiptBreakdown, actorID uint) (*models.PaymentReceipt, error) {
+	if payment.Status != models.PaymentStatusPaid {
+		return nil, fmt.Errorf("%w: solo se emiten recibos de pagos aprobados", ErrInvalidState)
+	}
+	receipts, err := s.repo.FindByPayment(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	var replacesID *uint
+	for i := range receipts {
+		if receipts[i].Status == models.ReceiptStatusIssued {
+			return nil, fmt.Errorf("%w: el pago ya tiene el recibo %s", ErrInvalidState, receipts[i].DisplayNumber())
+		}
+		if replacesID == nil {
+			replacesID = &receipts[i].ID
+		}
+	}
+
+	series := models.ReceiptSeriesCompany
+	if s.perProject {
+		series = models.ReceiptSeriesForProject(payment.Contract.Lot.ProjectID)
+	}
+	now := time.Now()
+	paymentDate := now
+	if payment.PaymentDate != nil {
+		paymentDate = *payment.PaymentDate
+	}
+	currency := payment.Contract.Currency
+	if currency == "" {
+		currency = "HNL"
+	}
+	receipt := &models.PaymentReceipt{
+		PaymentID:   payment.ID,
+		ContractID:  payment.ContractID,
+		Series:      series,
+		Status:      models.ReceiptStatusIssued,
+		Principal:   roundCents(breakdown.Principal),
+		Interest:    roundCents(breakdown.Interest),
+		Prepayment:  roundCents(breakdown.Prepayment),
+		Total:       roundCents(breakdown.Total()),
+		Currency:    currency,
+		PaymentDate: paymentDate,
+		IssuedAt:    now,
+		IssuedByID:  actorID,
+		ReplacesID:  replacesID,
+	}
+	if err := s.repo.Issue(ctx, receipt); err != nil {
+		return nil, fmt.Errorf("failed to issue receipt: %w", err)
+	}
+	s.audit(ctx, actorID, "RECEIPT_ISSUE", payment.ID,
+		fmt.Sprintf("Recibo %s emitido por %.2f para el contrato #%d", receipt.DisplayNumber(), receipt.Total, receipt.ContractID))
+	return receipt, nil
+}
+
+// Render returns the PDF of a receipt, generating and storing it the first time. A receipt voided
+// before its PDF was stored is not rendered anymore.
+func (s *ReceiptService) Render(ctx context.Context, receipt *models.PaymentReceipt) ([]byte, error) {
+	if receipt.Path == nil {
+		current, err := s.repo.FindByID(ctx, receipt.ID)
+		if err != nil {
+			return nil, err
+		}
+		*receipt = *current
+	}
+	if receipt.Path != nil {
+		return s.readStored(*receipt.Path)
+	}
+	if receipt.Status != models.ReceiptStatusIssued {
+		return nil, fmt.Errorf("%w: el recibo %s fue anulado", ErrInvalidState, receipt.DisplayNumber())
+	}
+
+	replacesNumber := ""
+	if receipt.ReplacesID != nil {
+		if replaced, err := s.repo.FindByID(ctx, *receipt.ReplacesID); err == nil {
+			replacesNumber = replaced.DisplayNumber()
+		}
+	}
+	pdf, document, err := s.reportSvc.GenerateReceiptPDF(ctx, receipt, replacesNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate receipt %s: %w", receipt.DisplayNumber(), err)
+	}
+	path, err := s.storage.UploadFromBytes(pdf.Bytes(), receipt.DisplayNumber()+".pdf", fmt.Sprintf("receipts/%d", receipt.ContractID))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256Hex(pdf.Bytes())
+	var documentID *uint
+	if document != nil {
+		documentID = &document.ID
+	}
+	stored, err := s.repo.SetDocument(ctx, receipt.ID, path, hash, documentID)
+	if err != nil {
+		s.removeFile(path)
+		return nil, fmt.Errorf("failed to store receipt %s: %w", receipt.DisplayNumber(), err)
+	}
+	if stored {
+		receipt.Path, receipt.SHA256, receipt.IssuedDocumentID = &path, &hash, documentID
+		return pdf.Bytes(), nil
+	}
+
+	// Another render stored its PDF first, or the receipt was voided meanwhile: keep what is stored
+	s.removeFile(path)
+	current, err := s.repo.FindByID(ctx, receipt.ID)
+	if err != nil {
+		return nil, err
+	}
+	if document != nil && (current.IssuedDocumentID == nil || *current.IssuedDocumentID != document.ID) {
+		if err := s.reportSvc.VoidIssuedDocument(ctx, document.ID); err != nil {
+			logger.Error(fmt.Sprintf("[ReceiptService] Failed to void unused issued document of receipt %s: %v", receipt.DisplayNumber(), err))
+		}
+	}
+	*receipt = *current
+	if receipt.Path == nil {
+		return nil, fmt.Errorf("%w: el recibo %s fue anulado", ErrInvalidState, receipt.DisplayNumber())
+	}
+	return s.readStored(*receipt.Path)
+}
+
+func (s *ReceiptService) readStored(path string) ([]byte, error) {
+	fullPath, err := s.storage.SafeFullPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(fullPath)
+}
+
+func (s *ReceiptService) removeFile(path string) {
+	if err := s.storage.Delete(path); err != nil {
+		logger.Error(fmt.Sprintf("[ReceiptService] Failed to remove orphan file %s: %v", path, err))
+	}
+}
+
+// Void voids the current receipt of a payment, e.g. when the payment is undone. Its number stays
+// used and its verification stops confirming it. Payments without a receipt are left alone.
+func (s *ReceiptService) Void(ctx context.Context, paymentID uint, actorID uint, reason string) (*models.PaymentReceipt, error) {
+	receipts, err := s.repo.FindByPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range receipts {
+		receipt := &receipts[i]
+		if receipt.Status != models.ReceiptStatusIssued {
+			continue
+		}
+		voided, err := s.repo.Void(ctx, receipt.ID, &actorID, reason, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to void receipt %s: %w", receipt.DisplayNumber(), err)
+		}
+		if !voided {
+			return nil, nil
+		}
+		// Re-read it: a render may have stored its issued document since it was listed
+		if current, err := s.repo.FindByID(ctx, receipt.ID); err == nil {
+			receipt = current
+		}
+		if receipt.IssuedDocumentID != nil {
+			if err := s.reportSvc.VoidIssuedDocument(ctx, *receipt.IssuedDocumentID); err != nil {
+				logger.Error(fmt.Sprintf("[ReceiptService] Failed to void issued document of receipt %s: %v", receipt.DisplayNumber(), err))
+			}
+		}
+		s.audit(ctx, actorID, "RECEIPT_VOID", paymentID, fmt.Sprintf("Recibo %s anulado. Motivo: %s", receipt.DisplayNumber(), reason))
+		return receipt, nil
+	}
+	return nil, nil
+}
+
+// Reissue voids the current receipt of a paid payment, if any, and issues a new number for it
+func (s *ReceiptService) Reissue(ctx context.Context, paymentID uint, actorID uint, reason string) (*models.PaymentReceipt, error) {
+	payment, err := s.paymentRepo.FindByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if payment.Status != models.PaymentStatusPaid {
+		return nil, fmt.Errorf("%w: solo se emiten recibos de pagos aprobados", ErrInvalidState)
+	}
+	if reason == "" {
+		reason = "Reemisión"
+	}
+
+	breakdown := paymentReceiptBreakdown(payment)
+	voided, err := s.Void(ctx, paymentID, actorID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if voided != nil {
+		// Keep the split of the receipt being replaced
+		breakdown = ReceiptBreakdown{Principal: voided.Principal, Interest: voided.Interest, Prepayment: voided.Prepayment}
+	}
+	receipt, err := s.Issue(ctx, payment, breakdown, actorID)
+	if err != nil {
+		return nil, err
+	}
+	// Render it now so its issued document exists before anything can void it
+	if _, err := s.Render(ctx, receipt); err != nil {
+		logger.Error(fmt.Sprintf("[ReceiptService] %v", err))
+	}
+	return receipt, nil
+}
+
+// List returns the receipts of a payment, voided ones included
+func (s *ReceiptService) List(ctx context.Context, paymentID uint, actor DocumentActor) ([]models.PaymentReceipt, error) {
+	if err := s.authorize(ctx, paymentID, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByPayment(ctx, paymentID)
+}
+
+// Download returns a receipt of a payment with its PDF
+func (s *ReceiptService) Download(ctx context.Context, paymentID, receiptID uint, actor DocumentActor) (*models.PaymentReceipt, []byte, error) {
+	if err := s.authorize(ctx, paymentID, actor); err != nil {
+		return nil, nil, err
+	}
+	receipt, err := s.repo.FindByID(ctx, receiptID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if receipt.PaymentID != paymentID {
+		return nil, nil, ErrNotFound
+	}
+	pdf, err := s.Render(ctx, receipt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return receipt, pdf, nil
+}
+
+// authorize checks the actor can see the receipts of the payment: admins and sellers, or the buyer
+func (s *ReceiptService) authorize(ctx context.Context, paymentID uint, actor DocumentActor) error {
+	payment, err := s.paymentRepo.FindByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !actor.isStaff() && !payment.Contract.IsOwnedBy(actor.UserID) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func (s *ReceiptService) audit(ctx context.Context, userID uint, action string, paymentID uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "Payment", paymentID, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[ReceiptService] Failed to audit %s for payment %d: %v", action, paymentID, err))
+	}
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
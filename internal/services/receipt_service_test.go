@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockPaymentReceiptRepository struct {
+	receipts []*models.PaymentReceipt
+	next     map[string]int64
+}
+
+func (m *mockPaymentReceiptRepository) FindByID(ctx context.Context, id uint) (*models.PaymentReceipt, error) {
+	for _, receipt := range m.receipts {
+		if receipt.ID == id {
+			return receipt, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockPaymentReceiptRepository) FindByPayment(ctx context.Context, paymentID uint) ([]models.PaymentReceipt, error) {
+	var receipts []models.PaymentReceipt
+	for _, receipt := range m.receipts {
+		if receipt.PaymentID == paymentID {
+			receipts = append(receipts, *receipt)
+		}
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].ID > receipts[j].ID })
+	return receipts, nil
+}
+
+func (m *mockPaymentReceiptRepository) Issue(ctx context.Context, receipt *models.PaymentReceipt) error {
+	m.next[receipt.Series]++
+	receipt.Number = m.next[receipt.Series]
+	receipt.ID = uint(len(m.receipts) + 1)
+	m.receipts = append(m.receipts, receipt)
+	return nil
+}
+
+func (m *mockPaymentReceiptRepository) SetDocument(ctx context.Context, id uint, path, sha256 string, issuedDocumentID *uint) (bool, error) {
+	receipt, err := m.FindByID(ctx, id)
+	if err != nil || receipt.Status != models.ReceiptStatusIssued || receipt.Path != nil {
+		return false, err
+	}
+	receipt.Path, receipt.SHA256, receipt.IssuedDocumentID = &path, &sha256, issuedDocumentID
+	return true, nil
+}
+
+func (m *mockPaymentReceiptRepository) Void(ctx context.Context, id uint, voidedByID *uint, reason string, at time.Time) (bool, error) {
+	receipt, err := m.FindByID(ctx, id)
+	if err != nil || receipt.Status != models.ReceiptStatusIssued {
+		return false, err
+	}
+	receipt.Status = models.ReceiptStatusVoid
+	receipt.VoidedAt, receipt.VoidedByID, receipt.VoidReason = &at, voidedByID, &reason
+	return true, nil
+}
+
+func TestPaymentReceiptBreakdown(t *testing.T) {
+	interest := 150.0
+	paid := 2650.0
+	payment := &models.Payment{Amount: 2000, InterestAmount: &interest, PaidAmount: &paid}
+	assert.Equal(t, ReceiptBreakdown{Principal: 2000, Interest: 150, Prepayment: 500}, paymentReceiptBreakdown(payment))
+
+	// A short payment covers interest first
+	paid = 1000
+	assert.Equal(t, ReceiptBreakdown{Principal: 850, Interest: 150}, paymentReceiptBreakdown(payment))
+}
+
+func TestReceiptIssueAndVoid(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockPaymentReceiptRepository{next: map[string]int64{}}
+	svc := NewReceiptService(repo, nil, nil, nil, nil, &config.Config{ReceiptNumbering: "project"})
+
+	paid := 1000.0
+	payment := &models.Payment{ID: 7, ContractID: 3, Amount: 1000, PaidAmount: &paid, Status: models.PaymentStatusPaid}
+	payment.Contract.Lot.ProjectID = 2
+	payment.Contract.Currency = "USD"
+
+	first, err := svc.Issue(ctx, payment, paymentReceiptBreakdown(payment), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "P2-00000001", first.DisplayNumber())
+	assert.Equal(t, "USD", first.Currency)
+	assert.Nil(t, first.ReplacesID)
+
+	// One issued receipt per payment
+	_, err = svc.Issue(ctx, payment, paymentReceiptBreakdown(payment), 1)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	voided, err := svc.Void(ctx, payment.ID, 1, "Pago revertido")
+	require.NoError(t, err)
+	require.NotNil(t, voided)
+	assert.Equal(t, models.ReceiptStatusVoid, first.Status)
+
+	// A receipt voided before its PDF was stored is not rendered (nor registered) anymore
+	stale := *first
+	stale.Status = models.ReceiptStatusIssued
+	_, err = svc.Render(ctx, &stale)
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.Equal(t, models.ReceiptStatusVoid, stale.Status)
+
+	// Nothing left to void
+	voided, err = svc.Void(ctx, payment.ID, 1, "Pago revertido")
+	require.NoError(t, err)
+	assert.Nil(t, voided)
+
+	second, err := svc.Issue(ctx, payment, paymentReceiptBreakdown(payment), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "P2-00000002", second.DisplayNumber())
+	require.NotNil(t, second.ReplacesID)
+	assert.Equal(t, first.ID, *second.ReplacesID)
+
+	payment.Status = models.PaymentStatusSubmitted
+	_, err = svc.Issue(ctx, payment, paymentReceiptBreakdown(payment), 1)
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrInvalidAgingReport is returned for malformed aging report filters
+var ErrInvalidAgingReport = errors.New("filtros de antigüedad de saldos inválidos")
+
+// Aging report groupings
+const (
+	AgingGroupContract = "contract"
+	AgingGroupCustomer = "customer"
+	AgingGroupProject  = "project"
+	AgingGroupSeller   = "seller"
+)
+
+// agingGroups are the groupings in the order of the XLSX sheets
+var agingGroups = []struct{ key, title string }{
+	{AgingGroupContract, "Contratos"},
+	{AgingGroupCustomer, "Clientes"},
+	{AgingGroupProject, "Proyectos"},
+	{AgingGroupSeller, "Vendedores"},
+}
+
+// DefaultAgingBuckets are the upper bounds in days past due of the default buckets:
+// 0-30, 31-60, 61-90, 91-180 and 180+
+var DefaultAgingBuckets = []int{30, 60, 90, 180}
+
+// maxAgingBuckets bounds the number of configurable bounds
+const maxAgingBuckets = 12
+
+// AgingReportFilters selects and shapes the receivables aging report. Buckets are the ascending
+// upper bounds in days past due; a last open bucket holds everything older.
+type AgingReportFilters struct {
+	AsOf      time.Time
+	Buckets   []int
+	GroupBy   string
+	ProjectID uint
+	SellerID  uint
+}
+
+// ParseAgingReportFilters reads the filters from query parameters: as_of (YYYY-MM-DD, today by
+// default), buckets (e.g. "30,60,90,180"), group_by (contract, customer, project or seller),
+// project_id and seller_id
+func ParseAgingReportFilters(params map[string]string, now time.Time) (AgingReportFilters, error) {
+	filters := AgingReportFilters{
+		AsOf:    time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		Buckets: DefaultAgingBuckets,
+		GroupBy: AgingGroupContract,
+	}
+	if v := params["as_of"]; v != "" {
+		asOf, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			return filters, fmt.Errorf("%w: as_of debe tener formato AAAA-MM-DD", ErrInvalidAgingReport)
+		}
+		filters.AsOf = asOf
+	}
+	if v := params["buckets"]; v != "" {
+		buckets, err := parseAgingBuckets(v)
+		if err != nil {
+			return filters, err
+		}
+		filters.Buckets = buckets
+	}
+	if v := params["group_by"]; v != "" {
+		valid := false
+		for _, group := range agingGroups {
+			valid = valid || group.key == v
+		}
+		if !valid {
+			return filters, fmt.Errorf("%w: group_by debe ser contract, customer, project o seller", ErrInvalidAgingReport)
+		}
+		filters.GroupBy = v
+	}
+	for key, target := range map[string]*uint{"project_id": &filters.ProjectID, "seller_id": &filters.SellerID} {
+		if v := params[key]; v != "" {
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return filters, fmt.Errorf("%w: %s inválido", ErrInvalidAgingReport, key)
+			}
+			*target = uint(id)
+		}
+	}
+	return filters, nil
+}
+
+// parseAgingBuckets parses a comma separated list of ascending upper bounds in days
+func parseAgingBuckets(value string) ([]int, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) > maxAgingBuckets {
+		return nil, fmt.Errorf("%w: máximo %d rangos", ErrInvalidAgingReport, maxAgingBuckets)
+	}
+	buckets := make([]int, 0, len(parts))
+	for _, part := range parts {
+		bound, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || bound < 0 || (len(buckets) > 0 && bound <= buckets[len(buckets)-1]) {
+			return nil, fmt.Errorf("%w: los rangos deben ser días ascendentes, p. ej. 30,60,90,180", ErrInvalidAgingReport)
+		}
+		buckets = append(buckets, bound)
+	}
+	return buckets, nil
+}
+
+// AgingBucket is a range of days past due; To is nil for the last, open bucket
+type AgingBucket struct {
+	Label string `json:"label"`
+	From  int    `json:"from"`
+	To    *int   `json:"to"`
+}
+
+func agingBuckets(bounds []int) []AgingBucket {
+	buckets := make([]AgingBucket, 0, len(bounds)+1)
+	from := 0
+	for i := range bounds {
+		to := bounds[i]
+		buckets = append(buckets, AgingBucket{Label: fmt.Sprintf("%d-%d", from, to), From: from, To: &to})
+		from = to + 1
+	}
+	last := 0
+	if len(bounds) > 0 {
+		last = bounds[len(bounds)-1]
+	}
+	return append(buckets, AgingBucket{Label: fmt.Sprintf("%d+", last), From: from})
+}
+
+// bucketIndex returns the bucket holding the given days past due
+func bucketIndex(bounds []int, days int) int {
+	for i, bound := range bounds {
+		if days <= bound {
+			return i
+		}
+	}
+	return len(bounds)
+}
+
+// AgingAmounts is the outstanding principal and interest of a bucket or row
+type AgingAmounts struct {
+	Principal  float64 `json:"principal"`
+	Interest   float64 `json:"interest"`
+	Total      float64 `json:"total"`
+	Percentage float64 `json:"percentage"` // share of the report total
+}
+
+func (a *AgingAmounts) add(principal, interest float64) {
+	a.Principal += principal
+	a.Interest += interest
+	a.Total += principal + interest
+}
+
+func (a *AgingAmounts) finish(grandTotal float64) {
+	a.Principal, a.Interest, a.Total = roundCents(a.Principal), roundCents(a.Interest), roundCents(a.Total)
+	if grandTotal > 0 {
+		a.Percentage = roundCents(a.Total / grandTotal * 100)
+	}
+}
+
+// AgingRow is the outstanding balance of a contract, customer, project or seller
+type AgingRow struct {
+	ID             uint           `json:"id"`
+	Name           string         `json:"name"`
+	Customer       string         `json:"customer,omitempty"`
+	Project        string         `json:"project,omitempty"`
+	Seller         string         `json:"seller,omitempty"`
+	Payments       int            `json:"payments"`
+	MaxDaysPastDue int            `json:"max_days_past_due"`
+	Buckets        []AgingAmounts `json:"buckets"`
+	AgingAmounts
+}
+
+// AgingReport is the receivables aging report as of a date
+type AgingReport struct {
+	AsOf    string         `json:"as_of"`
+	GroupBy string         `json:"group_by"`
+	Buckets []AgingBucket  `json:"buckets"`
+	Rows    []AgingRow     `json:"rows"`
+	Totals  []AgingAmounts `json:"totals"` // per bucket
+	AgingAmounts
+}
+
+// agingItem is the outstanding balance of one payment as of the report date
+type agingItem struct {
+	payment             *models.Payment
+	days                int
+	principal, interest float64
+}
+
+const agingNoSeller = "Sin vendedor"
+
+// agingKey returns the row of the grouping a payment belongs to
+func agingKey(p *models.Payment, groupBy string) (uint, string) {
+	contract := &p.Contract
+	switch groupBy {
+	case AgingGroupCustomer:
+		return contract.ApplicantUserID, contract.ApplicantUser.FullName
+	case AgingGroupProject:
+		return contract.Lot.ProjectID, contract.Lot.Project.Name
+	case AgingGroupSeller:
+		if contract.Creator == nil {
+			return 0, agingNoSeller
+		}
+		return contract.Creator.ID, contract.Creator.FullName
+	default:
+		return contract.ID, fmt.Sprintf("Contrato #%d · %s", contract.ID, contract.Lot.Name)
+	}
+}
+
+// GenerateAgingReport buckets the outstanding principal and interest of the payments due by the
+// as-of date by days past due. Credits are rebuilt from the ledger entries posted until the end of
+// that day and settle an installment's interest first, then its principal. The interest ledger entry
+// of a payment is updated in place as it accrues and is dated on its last update, so it can't tell
+// the interest of a past date: interest is recomputed to the as-of date instead, never above what
+// the payment accrued in total (accrual stops once it is paid or its contract leaves force).
+func (s *ReportService) GenerateAgingReport(ctx context.Context, filters AgingReportFilters) (*AgingReport, error) {
+	items, err := s.agingItems(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return buildAgingReport(items, filters), nil
+}
+
+func (s *ReportService) agingItems(ctx context.Context, filters AgingReportFilters) ([]agingItem, error) {
+	endOfDay := filters.AsOf.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	payments, err := s.receivablesRepo.FindDuePayments(ctx, repository.ReceivablesQuery{
+		AsOf:      endOfDay,
+		ProjectID: filters.ProjectID,
+		SellerID:  filters.SellerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(payments))
+	for i := range payments {
+		ids[i] = payments[i].ID
+	}
+	totals, err := s.receivablesRepo.SumLedgerByPayment(ctx, ids, endOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	// Credits are positive in the ledger
+	credits := map[uint]float64{}
+	for _, t := range totals {
+		switch t.EntryType {
+		case models.EntryTypePayment, models.EntryTypeAdjustment:
+			credits[t.PaymentID] += t.Amount
+		}
+	}
+
+	items := make([]agingItem, 0, len(payments))
+	for i := range payments {
+		p := &payments[i]
+		due := time.Date(p.DueDate.Year(), p.DueDate.Month(), p.DueDate.Day(), 0, 0, 0, 0, filters.AsOf.Location())
+		days := int(math.Round(filters.AsOf.Sub(due).Hours() / 24))
+		accrued := agingInterest(p, days)
+		paid := maxFloat(credits[p.ID], 0)
+		interestDue := maxFloat(accrued-paid, 0)
+		principalDue := maxFloat(p.Amount-maxFloat(paid-accrued, 0), 0)
+		if roundCents(principalDue+interestDue) == 0 {
+			continue
+		}
+		items = append(items, agingItem{
+			payment:   p,
+			days:      days,
+			principal: principalDue,
+			interest:  interestDue,
+		})
+	}
+	return items, nil
+}
+
+// agingInterest returns the interest a payment had accrued after the given days past due, with the
+// formula of the daily accrual (amount × days / 365 × the project rate), capped at the interest it
+// accrued in total
+func agingInterest(p *models.Payment, days int) float64 {
+	if p.InterestAmount == nil || *p.InterestAmount <= 0 || days <= 0 {
+		return 0
+	}
+	interest := p.Amount * float64(days) / 365.0 * p.Contract.Lot.Project.InterestRate / 100.0
+	return math.Min(interest, *p.InterestAmount)
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// buildAgingReport groups the items and computes the totals and percentages
+func buildAgingReport(items []agingItem, filters AgingReportFilters) *AgingReport {
+	buckets := agingBuckets(filters.Buckets)
+	report := &AgingReport{
+		AsOf:    filters.AsOf.Format("2006-01-02"),
+		GroupBy: filters.GroupBy,
+		Buckets: buckets,
+		Rows:    []AgingRow{},
+		Totals:  make([]AgingAmounts, len(buckets)),
+	}
+
+	rows := map[string]*AgingRow{}
+	for _, item := range items {
+		id, name := agingKey(item.payment, filters.GroupBy)
+		key := fmt.Sprintf("%d|%s", id, name)
+		row, ok := rows[key]
+		if !ok {
+			row = &AgingRow{ID: id, Name: name, Buckets: make([]AgingAmounts, len(buckets))}
+			if filters.GroupBy == AgingGroupContract {
+				row.Customer = item.payment.Contract.ApplicantUser.FullName
+				row.Project = item.payment.Contract.Lot.Project.Name
+				row.Seller = agingNoSeller
+				if item.payment.Contract.Creator != nil {
+					row.Seller = item.payment.Contract.Creator.FullName
+				}
+			}
+			rows[key] = row
+		}
+		bucket := bucketIndex(filters.Buckets, item.days)
+		row.Buckets[bucket].add(item.principal, item.interest)
+		row.add(item.principal, item.interest)
+		row.Payments++
+		if item.days > row.MaxDaysPastDue {
+			row.MaxDaysPastDue = item.days
+		}
+		report.Totals[bucket].add(item.principal, item.interest)
+		report.add(item.principal, item.interest)
+	}
+
+	grandTotal := report.Total
+	for _, row := range rows {
+		for i := range row.Buckets {
+			row.Buckets[i].finish(grandTotal)
+		}
+		row.finish(grandTotal)
+		report.Rows = append(report.Rows, *row)
+	}
+	for i := range report.Totals {
+		report.Totals[i].finish(grandTotal)
+	}
+	report.finish(grandTotal)
+
+	// Largest balances first
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Total != report.Rows[j].Total {
+			return report.Rows[i].Total > report.Rows[j].Total
+		}
+		return report.Rows[i].Name < report.Rows[j].Name
+	})
+	return report
+}
+
+// agingHeader returns the column titles of a report table
+func agingHeader(report *AgingReport) []string {
+	header := []string{"ID", "Nombre"}
+	if report.GroupBy == AgingGroupContract {
+		header = append(header, "Cliente", "Proyecto", "Vendedor")
+	}
+	header = append(header, "Pagos", "Días Mora Máx.")
+	for _, bucket := range report.Buckets {
+		header = append(header, bucket.Label+" días")
+	}
+	return append(header, "Capital", "Interés", "Total", "% Cartera")
+}
+
+// agingRecord returns the cells of a row; the totals row has no ID
+func agingRecord(report *AgingReport, row *AgingRow, total bool) []interface{} {
+	var record []interface{}
+	if total {
+		record = append(record, "", "TOTAL")
+		if report.GroupBy == AgingGroupContract {
+			record = append(record, "", "", "")
+		}
+		record = append(record, "", "")
+	} else {
+		record = append(record, row.ID, row.Name)
+		if report.GroupBy == AgingGroupContract {
+			record = append(record, row.Customer, row.Project, row.Seller)
+		}
+		record = append(record, row.Payments, row.MaxDaysPastDue)
+	}
+	for _, bucket := range row.Buckets {
+		record = append(record, bucket.Total)
+	}
+	return append(record, row.Principal, row.Interest, row.Total, row.Percentage)
+}
+
+// totalsRow returns the report totals as a row
+func (r *AgingReport) totalsRow() *AgingRow {
+	return &AgingRow{Buckets: r.Totals, AgingAmounts: r.AgingAmounts}
+}
+
+// GenerateAgingReportCSV writes the aging report as CSV: one line per row, the totals, and a
+// summary of the principal, interest and share of each bucket
+func (s *ReportService) GenerateAgingReportCSV(ctx context.Context, filters AgingReportFilters) (*bytes.Buffer, error) {
+	report, err := s.GenerateAgingReport(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	_ = w.Write([]string{"Antigüedad de Saldos al " + report.AsOf})
+	_ = w.Write(agingHeader(report))
+	for i := range report.Rows {
+		_ = w.Write(csvCells(agingRecord(report, &report.Rows[i], false)))
+	}
+	_ = w.Write(csvCells(agingRecord(report, report.totalsRow(), true)))
+
+	_ = w.Write([]string{""})
+	_ = w.Write([]string{"Rango", "Capital", "Interés", "Total", "% Cartera"})
+	for i, bucket := range report.Buckets {
+		t := report.Totals[i]
+		_ = w.Write(csvCells([]interface{}{bucket.Label + " días", t.Principal, t.Interest, t.Total, t.Percentage}))
+	}
+	w.Flush()
+	return b, w.Error()
+}
+
+func csvCells(values []interface{}) []string {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		switch value := v.(type) {
+		case float64:
+			cells[i] = fmt.Sprintf("%.2f", value)
+		default:
+			cells[i] = fmt.Sprint(value)
+		}
+	}
+	return cells
+}
+
+// GenerateAgingReportXLSX writes the aging report as a workbook with a summary sheet by bucket
+// and one sheet per grouping: contracts, customers, projects and sellers
+func (s *ReportService) GenerateAgingReportXLSX(ctx context.Context, filters AgingReportFilters) (*bytes.Buffer, error) {
+	items, err := s.agingItems(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	moneyStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+
+	summary := "Resumen"
+	_ = f.SetSheetName("Sheet1", summary)
+	report := buildAgingReport(items, filters)
+	_ = f.SetCellValue(summary, "A1", "Antigüedad de Saldos al "+report.AsOf)
+	_ = f.SetCellStyle(summary, "A1", "A1", titleStyle)
+	_ = f.SetSheetRow(summary, "A3", &[]interface{}{"Rango", "Capital", "Interés", "Total", "% Cartera"})
+	_ = f.SetCellStyle(summary, "A3", "E3", headerStyle)
+	for i, bucket := range report.Buckets {
+		t := report.Totals[i]
+		cell, _ := excelize.CoordinatesToCellName(1, i+4)
+		_ = f.SetSheetRow(summary, cell, &[]interface{}{bucket.Label + " días", t.Principal, t.Interest, t.Total, t.Percentage})
+	}
+	last := len(report.Buckets) + 4
+	cell, _ := excelize.CoordinatesToCellName(1, last)
+	_ = f.SetSheetRow(summary, cell, &[]interface{}{"TOTAL", report.Principal, report.Interest, report.Total, report.Percentage})
+	_ = f.SetCellStyle(summary, "B4", fmt.Sprintf("D%d", last), moneyStyle)
+	_ = f.SetColWidth(summary, "A", "E", 16)
+
+	for _, group := range agingGroups {
+		filters.GroupBy = group.key
+		report := buildAgingReport(items, filters)
+		if _, err := f.NewSheet(group.title); err != nil {
+			return nil, err
+		}
+		header := agingHeader(report)
+		headerRow := make([]interface{}, len(header))
+		for i := range header {
+			headerRow[i] = header[i]
+		}
+		lastCol, _ := excelize.ColumnNumberToName(len(header))
+		_ = f.SetSheetRow(group.title, "A1", &headerRow)
+		_ = f.SetCellStyle(group.title, "A1", lastCol+"1", headerStyle)
+		for i := range report.Rows {
+			record := agingRecord(report, &report.Rows[i], false)
+			_ = f.SetSheetRow(group.title, fmt.Sprintf("A%d", i+2), &record)
+		}
+		totalRow := len(report.Rows) + 2
+		totals := agingRecord(report, report.totalsRow(), true)
+		_ = f.SetSheetRow(group.title, fmt.Sprintf("A%d", totalRow), &totals)
+		_ = f.SetCellStyle(group.title, fmt.Sprintf("A%d", totalRow), fmt.Sprintf("%s%d", lastCol, totalRow), headerStyle)
+
+		if len(report.Rows) > 0 {
+			firstMoney, _ := excelize.ColumnNumberToName(len(header) - len(report.Buckets) - 3)
+			lastMoney, _ := excelize.ColumnNumberToName(len(header) - 1)
+			_ = f.SetCellStyle(group.title, firstMoney+"2", fmt.Sprintf("%s%d", lastMoney, totalRow-1), moneyStyle)
+		}
+		_ = f.SetColWidth(group.title, "B", "B", 32)
+		_ = f.SetPanes(group.title, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	}
+
+	return f.WriteToBuffer()
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+type mockReceivablesRepository struct {
+	payments []models.Payment
+	ledger   []models.ContractLedgerEntry
+	query    repository.ReceivablesQuery
+}
+
+func (m *mockReceivablesRepository) FindDuePayments(ctx context.Context, query repository.ReceivablesQuery) ([]models.Payment, error) {
+	m.query = query
+	return m.payments, nil
+}
+
+func (m *mockReceivablesRepository) SumLedgerByPayment(ctx context.Context, paymentIDs []uint, until time.Time) ([]repository.LedgerPaymentTotal, error) {
+	sums := map[[2]interface{}]float64{}
+	for _, e := range m.ledger {
+		if e.PaymentID != nil && !e.EntryDate.After(until) {
+			sums[[2]interface{}{*e.PaymentID, e.EntryType}] += e.Amount
+		}
+	}
+	var totals []repository.LedgerPaymentTotal
+	for key, amount := range sums {
+		totals = append(totals, repository.LedgerPaymentTotal{PaymentID: key[0].(uint), EntryType: key[1].(string), Amount: amount})
+	}
+	return totals, nil
+}
+
+func newAgingFixture() *mockReceivablesRepository {
+	seller := &models.User{ID: 9, FullName: "Vendedora Uno"}
+	project := models.Project{ID: 3, Name: "Villas del Sol", InterestRate: 36}
+	first := models.Contract{ID: 1, ApplicantUserID: 20, ApplicantUser: models.User{ID: 20, FullName: "Ana López"},
+		Lot: models.Lot{Name: "Lote 5", ProjectID: 3, Project: project}, Creator: seller}
+	second := models.Contract{ID: 2, ApplicantUserID: 21, ApplicantUser: models.User{ID: 21, FullName: "Luis Mejía"},
+		Lot: models.Lot{Name: "Lote 8", ProjectID: 3, Project: project}}
+	date := func(month time.Month, day int) time.Time { return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC) }
+	id := func(v uint) *uint { return &v }
+	amount := func(v float64) *float64 { return &v }
+
+	return &mockReceivablesRepository{
+		payments: []models.Payment{
+			{ID: 1, ContractID: 1, Contract: first, Amount: 1000, DueDate: date(10, 10)},
+			{ID: 2, ContractID: 1, Contract: first, Amount: 1000, DueDate: date(8, 1), InterestAmount: amount(50)},
+			{ID: 3, ContractID: 2, Contract: second, Amount: 500, DueDate: date(3, 1), InterestAmount: amount(100)},
+			{ID: 4, ContractID: 2, Contract: second, Amount: 200, DueDate: date(9, 1)},
+			{ID: 5, ContractID: 2, Contract: second, Amount: 200, DueDate: date(9, 15)},
+		},
+		ledger: []models.ContractLedgerEntry{
+			// Interest accrued on payment 2, last updated after the earlier as-of date, partly paid
+			{PaymentID: id(2), EntryType: models.EntryTypeInterest, Amount: -50, EntryDate: date(10, 15)},
+			{PaymentID: id(2), EntryType: models.EntryTypePayment, Amount: 30, EntryDate: date(9, 2)},
+			// Payment 3 settles its interest and part of its principal
+			{PaymentID: id(3), EntryType: models.EntryTypeInterest, Amount: -100, EntryDate: date(6, 1)},
+			{PaymentID: id(3), EntryType: models.EntryTypePayment, Amount: 300, EntryDate: date(6, 2)},
+			// Payment 4 was paid and then reversed
+			{PaymentID: id(4), EntryType: models.EntryTypePayment, Amount: 200, EntryDate: date(9, 2)},
+			{PaymentID: id(4), EntryType: models.EntryTypeAdjustment, Amount: -200, EntryDate: date(9, 3)},
+			// Payment 5 was paid on time
+			{PaymentID: id(5), EntryType: models.EntryTypePayment, Amount: 200, EntryDate: date(9, 14)},
+			// Posted after the as-of date
+			{PaymentID: id(1), EntryType: models.EntryTypePayment, Amount: 1000, EntryDate: date(10, 20)},
+		},
+	}
+}
+
+func TestParseAgingReportFilters(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 4, 0, 0, time.UTC)
+
+	filters, err := ParseAgingReportFilters(map[string]string{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), filters.AsOf)
+	assert.Equal(t, []int{30, 60, 90, 180}, filters.Buckets)
+	assert.Equal(t, AgingGroupContract, filters.GroupBy)
+
+	filters, err = ParseAgingReportFilters(map[string]string{"as_of": "2026-06-30", "buckets": "15, 45", "group_by": "seller", "project_id": "3"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-06-30", filters.AsOf.Format("2006-01-02"))
+	assert.Equal(t, []int{15, 45}, filters.Buckets)
+	assert.Equal(t, AgingGroupSeller, filters.GroupBy)
+	assert.Equal(t, uint(3), filters.ProjectID)
+
+	for _, params := range []map[string]string{
+		{"as_of": "30/06/2026"},
+		{"buckets": "60,30"},
+		{"buckets": "30,,60"},
+		{"group_by": "lot"},
+		{"seller_id": "x"},
+	} {
+		_, err := ParseAgingReportFilters(params, now)
+		assert.ErrorIs(t, err, ErrInvalidAgingReport, params)
+	}
+}
+
+func TestGenerateAgingReport(t *testing.T) {
+	repo := newAgingFixture()
+	svc := NewReportService(nil, nil, nil, nil, nil, nil, repo, nil)
+	filters := AgingReportFilters{AsOf: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), Buckets: DefaultAgingBuckets, GroupBy: AgingGroupContract}
+
+	report, err := svc.GenerateAgingReport(context.Background(), filters)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 18, 23, 59, 59, 999999999, time.UTC), repo.query.AsOf)
+	assert.Equal(t, []string{"0-30", "31-60", "61-90", "91-180", "180+"}, []string{
+		report.Buckets[0].Label, report.Buckets[1].Label, report.Buckets[2].Label, report.Buckets[3].Label, report.Buckets[4].Label,
+	})
+	assert.Nil(t, report.Buckets[4].To)
+
+	assert.Equal(t, 2500.0, report.Principal)
+	assert.Equal(t, 20.0, report.Interest)
+	assert.Equal(t, 2520.0, report.Total)
+	assert.Equal(t, 100.0, report.Percentage)
+	assert.Equal(t, AgingAmounts{Principal: 1000, Total: 1000, Percentage: 39.68}, report.Totals[0])
+	assert.Equal(t, AgingAmounts{Principal: 200, Total: 200, Percentage: 7.94}, report.Totals[1])
+	assert.Equal(t, AgingAmounts{Principal: 1000, Interest: 20, Total: 1020, Percentage: 40.48}, report.Totals[2])
+	assert.Equal(t, AgingAmounts{}, report.Totals[3])
+	assert.Equal(t, AgingAmounts{Principal: 300, Total: 300, Percentage: 11.9}, report.Totals[4])
+
+	require.Len(t, report.Rows, 2)
+	first := report.Rows[0]
+	assert.Equal(t, uint(1), first.ID)
+	assert.Equal(t, "Ana López", first.Customer)
+	assert.Equal(t, "Vendedora Uno", first.Seller)
+	assert.Equal(t, 2, first.Payments)
+	assert.Equal(t, 78, first.MaxDaysPastDue)
+	assert.Equal(t, 2020.0, first.Total)
+	assert.Equal(t, 80.16, first.Percentage)
+	second := report.Rows[1]
+	assert.Equal(t, 2, second.Payments) // payment 5 was settled
+	assert.Equal(t, 231, second.MaxDaysPastDue)
+	assert.Equal(t, 19.84, second.Percentage)
+
+	filters.GroupBy = AgingGroupSeller
+	filters.Buckets = []int{15, 45}
+	report, err = svc.GenerateAgingReport(context.Background(), filters)
+	require.NoError(t, err)
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, "Vendedora Uno", report.Rows[0].Name)
+	assert.Equal(t, agingNoSeller, report.Rows[1].Name)
+	assert.Equal(t, "45+", report.Buckets[2].Label)
+	assert.Equal(t, 1000.0, report.Totals[0].Total)
+	assert.Equal(t, 0.0, report.Totals[1].Total)
+	assert.Equal(t, 1520.0, report.Totals[2].Total)
+
+	// Earlier as-of dates only see the credits posted by then, and the interest accrued by then
+	// although its ledger entry was updated later
+	filters.AsOf = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	filters.GroupBy = AgingGroupContract
+	repo.payments = repo.payments[1:4]
+	report, err = svc.GenerateAgingReport(context.Background(), filters)
+	require.NoError(t, err)
+	assert.Equal(t, 30.58, report.Interest)    // 1000 × 31 / 365 × 36%, paid on 09/02
+	assert.Equal(t, 1490.74, report.Principal) // the 300 credit of payment 3 covers the 90.74 it accrued by then
+}
+
+func TestGenerateAgingReportCSVAndXLSX(t *testing.T) {
+	svc := NewReportService(nil, nil, nil, nil, nil, nil, newAgingFixture(), nil)
+	filters := AgingReportFilters{AsOf: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), Buckets: DefaultAgingBuckets, GroupBy: AgingGroupProject}
+
+	buf, err := svc.GenerateAgingReportCSV(context.Background(), filters)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "Antigüedad de Saldos al 2026-10-18", lines[0])
+	assert.Equal(t, "ID,Nombre,Pagos,Días Mora Máx.,0-30 días,31-60 días,61-90 días,91-180 días,180+ días,Capital,Interés,Total,% Cartera", lines[1])
+	assert.Equal(t, "3,Villas del Sol,4,231,1000.00,200.00,1020.00,0.00,300.00,2500.00,20.00,2520.00,100.00", lines[2])
+	assert.Equal(t, ",TOTAL,,,1000.00,200.00,1020.00,0.00,300.00,2500.00,20.00,2520.00,100.00", lines[3])
+	assert.Contains(t, buf.String(), "61-90 días,1000.00,20.00,1020.00,40.48")
+
+	buf, err = svc.GenerateAgingReportXLSX(context.Background(), filters)
+	require.NoError(t, err)
+	f, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"Resumen", "Contratos", "Clientes", "Proyectos", "Vendedores"}, f.GetSheetList())
+	total, err := f.GetCellValue("Resumen", "D9")
+	require.NoError(t, err)
+	assert.Equal(t, "2,520.00", total)
+	rows, err := f.GetRows("Vendedores")
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "Vendedora Uno", rows[1][1])
+	assert.Equal(t, "TOTAL", rows[3][1])
+}
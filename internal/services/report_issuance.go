@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	qrcode "github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// documentCodeAlphabet is Crockford's base32: no I, L, O or U, so codes can be typed from paper
+const documentCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// DocumentStamp is the verification block printed on issued documents
+type DocumentStamp struct {
+	Code   string
+	URL    string
+	QRCode template.URL // PNG data URI of a QR code linking to URL
+}
+
+// DocumentVerification is the public confirmation of an issued document: who issued it, when, and
+// its key figures. Voided documents (e.g. the receipt of an undone payment) are not valid.
+type DocumentVerification struct {
+	Valid         bool                    `json:"valid"`
+	VoidedAt      *time.Time              `json:"voided_at,omitempty"`
+	Code          string                  `json:"code"`
+	Issuer        string                  `json:"issuer"`
+	DocumentType  string                  `json:"document_type"`
+	DocumentLabel string                  `json:"document_label"`
+	IssuedAt      time.Time               `json:"issued_at"`
+	Figures       []models.DocumentFigure `json:"figures"`
+	SHA256        string                  `json:"sha256"`
+	HashMatches   *bool                   `json:"hash_matches,omitempty"` // set when a hash to compare was given
+}
+
+// VerifyDocument confirms a document issued by the system from the code printed on it. When sha256
+// is given, it also tells whether it is the hash of any issued copy of the file.
+func (s *ReportService) VerifyDocument(ctx context.Context, code, sha256 string) (*DocumentVerification, error) {
+	code = normalizeDocumentCode(code)
+	if code == "" {
+		return nil, ErrNotFound
+	}
+	document, err := s.issuedRepo.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	verification := &DocumentVerification{
+		Valid:         document.VoidedAt == nil,
+		VoidedAt:      document.VoidedAt,
+		Code:          document.Code,
+		Issuer:        s.issuerName,
+		DocumentType:  document.DocumentType,
+		DocumentLabel: models.IssuedDocumentLabel(document.DocumentType),
+		IssuedAt:      document.IssuedAt,
+		Figures:       document.KeyFigures(),
+		SHA256:        document.SHA256,
+	}
+	if sha256 = strings.ToLower(strings.TrimSpace(sha256)); sha256 != "" {
+		matches := document.MatchesHash(sha256)
+		verification.HashMatches = &matches
+	}
+	return verification, nil
+}
+
+// VoidIssuedDocument marks an issued document void, so its verification no longer confirms it
+func (s *ReportService) VoidIssuedDocument(ctx context.Context, id uint) error {
+	if s.issuedRepo == nil {
+		return nil
+	}
+	return s.issuedRepo.Void(ctx, id, time.Now())
+}
+
+// issuePDF renders a report stamped with a document code and a QR code linking to its public
+// verification, then records the issued document with the hash of the PDF and its key figures.
+// A document rendered again from unchanged data keeps the code it was issued with; the hash of the
+// new copy is added to the hashes of the copies issued before, which keep verifying.
+func (s *ReportService) issuePDF(ctx context.Context, document *models.IssuedDocument, figures []models.DocumentFigure, templateName string, data map[string]interface{}) (*bytes.Buffer, error) {
+	if s.issuedRepo == nil {
+		return s.generatePDF(templateName, data)
+	}
+
+	source := documentSourceHash(document, templateName, data)
+	var existing *models.IssuedDocument
+	if source != "" {
+		found, err := s.issuedRepo.FindBySource(ctx, document.DocumentType, source)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		existing = found
+	}
+	code := ""
+	if existing != nil {
+		code = existing.Code
+	} else {
+		var err error
+		if code, err = newDocumentCode(); err != nil {
+			return nil, fmt.Errorf("failed to generate document code: %w", err)
+		}
+	}
+	url := fmt.Sprintf("%s/api/v1/verify/%s", s.apiURL, code)
+	qr, err := qrcode.Encode(url, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	data["Verification"] = DocumentStamp{
+		Code:   code,
+		URL:    url,
+		QRCode: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(qr)),
+	}
+
+	pdf, err := s.generatePDF(templateName, data)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256Hex(pdf.Bytes())
+
+	if existing != nil {
+		if err := s.issuedRepo.AddCopy(ctx, existing.ID, hash); err != nil {
+			return nil, fmt.Errorf("failed to record issued document copy: %w", err)
+		}
+		existing.Copies = append(existing.Copies, models.IssuedDocumentCopy{IssuedDocumentID: existing.ID, SHA256: hash})
+		*document = *existing
+		return pdf, nil
+	}
+
+	encoded, err := json.Marshal(figures)
+	if err != nil {
+		return nil, err
+	}
+	document.Code = code
+	document.SHA256 = hash
+	document.Figures = encoded
+	document.IssuedAt = time.Now()
+	if source != "" {
+		document.SourceSHA256 = &source
+	}
+	if err := s.issuedRepo.Create(ctx, document); err != nil {
+		return nil, fmt.Errorf("failed to record issued document: %w", err)
+	}
+	return pdf, nil
+}
+
+// documentSourceHash hashes what a document is rendered from: its type, holder, template and data
+// (without the verification stamp). Returns "" when the data cannot be encoded.
+func documentSourceHash(document *models.IssuedDocument, templateName string, data map[string]interface{}) string {
+	source := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "Verification" {
+			source[k] = v
+		}
+	}
+	encoded, err := json.Marshal(map[string]interface{}{
+		"type":     document.DocumentType,
+		"contract": document.ContractID,
+		"user":     document.UserID,
+		"template": templateName,
+		"data":     source,
+	})
+	if err != nil {
+		return ""
+	}
+	return sha256Hex(encoded)
+}
+
+// contractFigures are the key figures of documents about a contract
+func (s *ReportService) contractFigures(contract *models.Contract) []models.DocumentFigure {
+	amount := 0.0
+	if contract.Amount != nil {
+		amount = *contract.Amount
+	}
+	return []models.DocumentFigure{
+		{Label: "Contrato", Value: fmt.Sprintf("#%d", contract.ID)},
+		{Label: "Titular", Value: models.MaskName(contract.ApplicantUser.FullName)},
+		{Label: "Proyecto", Value: contract.Lot.Project.Name},
+		{Label: "Lote", Value: contract.Lot.Name},
+		{Label: "Monto", Value: fmt.Sprintf("%s %s", contract.Currency, s.formatCurrency(amount))},
+	}
+}
+
+// newDocumentCode returns a random document code such as 7K3M-Q9TX-2B4P (60 bits)
+func newDocumentCode() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	var code strings.Builder
+	for i, b := range raw {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(documentCodeAlphabet[b%32])
+	}
+	return code.String(), nil
+}
+
+// normalizeDocumentCode accepts codes typed in lower case, without dashes or with the letters
+// Crockford's base32 reads as digits
+func normalizeDocumentCode(code string) string {
+	var clean []byte
+	for _, r := range strings.ToUpper(code) {
+		switch {
+		case r == 'O':
+			r = '0'
+		case r == 'I' || r == 'L':
+			r = '1'
+		case r == '-' || r == ' ':
+			continue
+		}
+		if !strings.ContainsRune(documentCodeAlphabet, r) {
+			return ""
+		}
+		clean = append(clean, byte(r))
+	}
+	if len(clean) != 12 {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%s", clean[0:4], clean[4:8], clean[8:12])
+}
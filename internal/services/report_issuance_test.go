@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockIssuedDocumentRepository struct {
+	documents map[string]*models.IssuedDocument
+}
+
+func (m *mockIssuedDocumentRepository) Create(ctx context.Context, document *models.IssuedDocument) error {
+	document.ID = uint(len(m.documents) + 1)
+	m.documents[document.Code] = document
+	return nil
+}
+
+func (m *mockIssuedDocumentRepository) FindByCode(ctx context.Context, code string) (*models.IssuedDocument, error) {
+	if document, ok := m.documents[code]; ok {
+		return document, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockIssuedDocumentRepository) FindBySource(ctx context.Context, documentType, sourceSHA256 string) (*models.IssuedDocument, error) {
+	for _, document := range m.documents {
+		if document.DocumentType == documentType && document.SourceSHA256 != nil && *document.SourceSHA256 == sourceSHA256 && document.VoidedAt == nil {
+			return document, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockIssuedDocumentRepository) AddCopy(ctx context.Context, id uint, sha256 string) error {
+	for _, document := range m.documents {
+		if document.ID == id {
+			document.Copies = append(document.Copies, models.IssuedDocumentCopy{IssuedDocumentID: id, SHA256: sha256})
+		}
+	}
+	return nil
+}
+
+func (m *mockIssuedDocumentRepository) Void(ctx context.Context, id uint, at time.Time) error {
+	for _, document := range m.documents {
+		if document.ID == id {
+			document.VoidedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestVerifyDocument(t *testing.T) {
+	ctx := context.Background()
+	figures, _ := json.Marshal([]models.DocumentFigure{{Label: "Titular", Value: models.MaskName("Juan Pérez")}})
+	issuedAt := time.Date(2026, 5, 4, 9, 30, 0, 0, time.UTC)
+	repo := &mockIssuedDocumentRepository{documents: map[string]*models.IssuedDocument{
+		"7K3M-Q9TX-2B40": {Code: "7K3M-Q9TX-2B40", DocumentType: models.IssuedDocumentBalanceStatement, SHA256: "abc", Figures: figures, IssuedAt: issuedAt},
+	}}
+	svc := NewReportService(nil, nil, nil, nil, nil, repo, nil, &config.Config{IssuerName: "Inversiones FAMA S.A. de C.V."})
+
+	// Codes typed by hand: lower case, no dashes, O for zero
+	verification, err := svc.VerifyDocument(ctx, "7k3mq9tx2b4o", "ABC")
+	require.NoError(t, err)
+	assert.True(t, verification.Valid)
+	assert.Equal(t, "Inversiones FAMA S.A. de C.V.", verification.Issuer)
+	assert.Equal(t, "Estado de cuenta", verification.DocumentLabel)
+	assert.Equal(t, issuedAt, verification.IssuedAt)
+	assert.Equal(t, []models.DocumentFigure{{Label: "Titular", Value: "J*** P****"}}, verification.Figures)
+	require.NotNil(t, verification.HashMatches)
+	assert.True(t, *verification.HashMatches)
+
+	verification, err = svc.VerifyDocument(ctx, "7K3M-Q9TX-2B40", "def")
+	require.NoError(t, err)
+	assert.False(t, *verification.HashMatches)
+
+	_, err = svc.VerifyDocument(ctx, "7K3M-Q9TX-2B41", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.VerifyDocument(ctx, "../../etc", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewDocumentCode(t *testing.T) {
+	code, err := newDocumentCode()
+	require.NoError(t, err)
+	assert.Len(t, code, 14)
+	assert.Equal(t, code, normalizeDocumentCode(code))
+}
+
+// countingRenderer renders a different PDF on every call, like a PDF stamped with its creation time
+type countingRenderer struct{ calls int }
+
+func (r *countingRenderer) Render(templateName string, data map[string]interface{}) (*bytes.Buffer, error) {
+	r.calls++
+	return bytes.NewBufferString(fmt.Sprintf("%s %v %d", templateName, data["Verification"], r.calls)), nil
+}
+
+func TestIssuePDF_ReusesDocumentOfUnchangedData(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockIssuedDocumentRepository{documents: map[string]*models.IssuedDocument{}}
+	svc := NewReportService(nil, nil, nil, nil, nil, repo, nil, &config.Config{APIURL: "https://api.example.com"})
+	svc.renderer = &countingRenderer{}
+	contractID := uint(4)
+	hashes := []string{}
+	issue := func(amount string) *models.IssuedDocument {
+		document := &models.IssuedDocument{DocumentType: models.IssuedDocumentPromiseContract, ContractID: &contractID}
+		pdf, err := svc.issuePDF(ctx, document, nil, "contract_promise.html", map[string]interface{}{"Amount": amount})
+		require.NoError(t, err)
+		hashes = append(hashes, sha256Hex(pdf.Bytes()))
+		return document
+	}
+
+	first := issue("100,000.00")
+	again := issue("100,000.00")
+	assert.Equal(t, first.Code, again.Code)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Len(t, repo.documents, 1)
+	assert.NotEqual(t, hashes[0], hashes[1])
+	assert.Equal(t, hashes[0], repo.documents[first.Code].SHA256)
+
+	// Every copy handed out keeps verifying
+	for _, hash := range hashes {
+		verification, err := svc.VerifyDocument(ctx, first.Code, hash)
+		require.NoError(t, err)
+		assert.True(t, *verification.HashMatches)
+	}
+
+	// Changed data or a voided document issue a new code
+	changed := issue("90,000.00")
+	assert.NotEqual(t, first.Code, changed.Code)
+	require.NoError(t, svc.VoidIssuedDocument(ctx, first.ID))
+	assert.NotEqual(t, first.Code, issue("100,000.00").Code)
+	assert.Len(t, repo.documents, 3)
+}
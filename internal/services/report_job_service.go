@@ -0,0 +1,455 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/jobs"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Report job errors
+var (
+	ErrInvalidReportJob = errors.New("solicitud de reporte inválida")
+	ErrReportExpired    = errors.New("el reporte expiró; solicítelo de nuevo")
+	ErrReportQueueFull  = errors.New("hay demasiados reportes en cola; intente de nuevo en unos minutos")
+)
+
+// reportJobTimeout bounds how long a single report may run on the worker
+const reportJobTimeout = 10 * time.Minute
+
+// reportJobListLimit is the number of recent jobs listed
+const reportJobListLimit = 50
+
+// SubmitReportJobRequest asks for a report to be generated in the background. Params are the
+// query parameters of the synchronous report endpoint, e.g. {"contract_id": "42"}.
+type SubmitReportJobRequest struct {
+	ReportType string            `json:"report_type"`
+	Params     map[string]string `json:"params"`
+}
+
+// reportKind is a report that can be generated in the background
+type reportKind struct {
+	ids         []string // required numeric params
+	contentType string
+	filename    func(params map[string]string) string
+	generate    func(ctx context.Context, reports *ReportService, params map[string]string, actor DocumentActor) (*bytes.Buffer, error)
+}
+
+const (
+	contentTypeCSV  = "text/csv"
+	contentTypePDF  = "application/pdf"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// reportKinds are the reports available as background jobs, keyed by the name of their
+// synchronous endpoint under /reports
+var reportKinds = map[string]reportKind{
+	"commissions_csv": {
+		contentType: contentTypeCSV,
+		filename:    func(map[string]string) string { return "commissions.csv" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateCommissionsCSV(ctx, p["start_date"], p["end_date"], actor.UserID, actor.Role != models.RoleAdmin)
+		},
+	},
+	"total_revenue_csv": {
+		contentType: contentTypeCSV,
+		filename:    func(map[string]string) string { return "revenue.csv" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateRevenueCSV(ctx, RevenueReportFilters{ProjectID: p["project_id"], PhaseID: p["phase_id"], BlockID: p["block_id"]})
+		},
+	},
+	"overdue_payments_csv": {
+		contentType: contentTypeCSV,
+		filename:    func(map[string]string) string { return "overdue.csv" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateOverduePaymentsCSV(ctx)
+		},
+	},
+	"receivables_aging_csv": {
+		contentType: contentTypeCSV,
+		filename:    func(map[string]string) string { return "receivables_aging.csv" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			filters, err := agingFiltersFor(p, actor)
+			if err != nil {
+				return nil, err
+			}
+			return reports.GenerateAgingReportCSV(ctx, filters)
+		},
+	},
+	"receivables_aging_xlsx": {
+		contentType: contentTypeXLSX,
+		filename:    func(map[string]string) string { return "receivables_aging.xlsx" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			filters, err := agingFiltersFor(p, actor)
+			if err != nil {
+				return nil, err
+			}
+			return reports.GenerateAgingReportXLSX(ctx, filters)
+		},
+	},
+	"user_balance_pdf": {
+		ids:         []string{"user_id"},
+		contentType: contentTypePDF,
+		filename:    func(p map[string]string) string { return "balance_" + p["user_id"] + ".pdf" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateUserBalancePDF(ctx, paramID(p, "user_id"))
+		},
+	},
+	"user_promise_contract_pdf": {
+		ids:         []string{"contract_id"},
+		contentType: contentTypePDF,
+		filename:    func(p map[string]string) string { return "contract_" + p["contract_id"] + ".pdf" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateContractPDF(ctx, paramID(p, "contract_id"))
+		},
+	},
+	"user_rescission_contract_pdf": {
+		ids:         []string{"contract_id"},
+		contentType: contentTypePDF,
+		filename:    func(p map[string]string) string { return "rescission_" + p["contract_id"] + ".pdf" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			refund, _ := strconv.ParseFloat(p["refund_amount"], 64)
+			penalty, _ := strconv.ParseFloat(p["penalty_amount"], 64)
+			return reports.GenerateRescissionContractPDF(ctx, paramID(p, "contract_id"), refund, penalty)
+		},
+	},
+	"contract_cession_pdf": {
+		ids:         []string{"cession_id"},
+		contentType: contentTypePDF,
+		filename:    func(p map[string]string) string { return "cession_" + p["cession_id"] + ".pdf" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateCessionPDF(ctx, paramID(p, "cession_id"))
+		},
+	},
+	"contract_lot_change_pdf": {
+		ids:         []string{"lot_change_id"},
+		contentType: contentTypePDF,
+		filename:    func(p map[string]string) string { return "lot_change_" + p["lot_change_id"] + ".pdf" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateLotChangeAddendumPDF(ctx, paramID(p, "lot_change_id"))
+		},
+	},
+	"customer_record_pdf": {
+		ids:         []string{"contract_id"},
+		contentType: contentTypePDF,
+		filename:    func(p map[string]string) string { return "customer_record_" + p["contract_id"] + ".pdf" },
+		generate: func(ctx context.Context, reports *ReportService, p map[string]string, actor DocumentActor) (*bytes.Buffer, error) {
+			return reports.GenerateCustomerRecordPDF(ctx, paramID(p, "contract_id"))
+		},
+	},
+}
+
+// ReportTypes returns the names of the reports available as background jobs
+func ReportTypes() []string {
+	types := make([]string, 0, len(reportKinds))
+	for name := range reportKinds {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// agingFiltersFor parses the aging report params; sellers only see the contracts they created
+func agingFiltersFor(params map[string]string, actor DocumentActor) (AgingReportFilters, error) {
+	filters, err := ParseAgingReportFilters(params, time.Now())
+	if err != nil {
+		return filters, err
+	}
+	if actor.Role != models.RoleAdmin {
+		filters.SellerID = actor.UserID
+	}
+	return filters, nil
+}
+
+func paramID(params map[string]string, key string) uint {
+	id, _ := strconv.ParseUint(params[key], 10, 32)
+	return uint(id)
+}
+
+// validateReportParams checks the report type exists and its params are well formed
+func validateReportParams(reportType string, params map[string]string) (reportKind, error) {
+	kind, ok := reportKinds[reportType]
+	if !ok {
+		return reportKind{}, fmt.Errorf("%w: tipo de reporte desconocido %q", ErrInvalidReportJob, reportType)
+	}
+	for _, key := range kind.ids {
+		if paramID(params, key) == 0 {
+			return reportKind{}, fmt.Errorf("%w: %s es requerido", ErrInvalidReportJob, key)
+		}
+	}
+	for _, key := range []string{"project_id", "phase_id", "block_id"} {
+		if v := params[key]; v != "" {
+			if _, err := strconv.ParseUint(v, 10, 32); err != nil {
+				return reportKind{}, fmt.Errorf("%w: %s inválido", ErrInvalidReportJob, key)
+			}
+		}
+	}
+	for _, key := range []string{"start_date", "end_date"} {
+		if v := params[key]; v != "" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				return reportKind{}, fmt.Errorf("%w: %s debe tener formato AAAA-MM-DD", ErrInvalidReportJob, key)
+			}
+		}
+	}
+	if _, err := ParseAgingReportFilters(params, time.Now()); err != nil {
+		return reportKind{}, fmt.Errorf("%w: %v", ErrInvalidReportJob, err)
+	}
+	for _, key := range []string{"refund_amount", "penalty_amount"} {
+		if v := params[key]; v != "" {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return reportKind{}, fmt.Errorf("%w: %s inválido", ErrInvalidReportJob, key)
+			}
+		}
+	}
+	return kind, nil
+}
+
+// ReportJobService generates reports on a queue of their own so large CSVs and PDFs don't run
+// against the request timeout. Outputs are stored until they expire after the retention period.
+type ReportJobService struct {
+	repo            repository.ReportJobRepository
+	reportSvc       *ReportService
+	notificationSvc *NotificationService
+	storage         *storage.LocalStorage
+	queue           *jobs.Queue
+	retention       time.Duration
+}
+
+func NewReportJobService(
+	repo repository.ReportJobRepository,
+	reportSvc *ReportService,
+	notificationSvc *NotificationService,
+	storage *storage.LocalStorage,
+	worker *jobs.Worker,
+	cfg *config.Config,
+) *ReportJobService {
+	retentionDays, workers, queueSize := 7, 2, 20
+	if cfg != nil && cfg.ReportRetentionDays > 0 {
+		retentionDays = cfg.ReportRetentionDays
+	}
+	if cfg != nil && cfg.ReportWorkers > 0 {
+		workers = cfg.ReportWorkers
+	}
+	if cfg != nil && cfg.ReportQueueSize > 0 {
+		queueSize = cfg.ReportQueueSize
+	}
+	var queue *jobs.Queue
+	if worker != nil {
+		queue = worker.NewQueue("reports", workers, queueSize)
+	}
+	return &ReportJobService{
+		repo:            repo,
+		reportSvc:       reportSvc,
+		notificationSvc: notificationSvc,
+		storage:         storage,
+		queue:           queue,
+		retention:       time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// Submit records a report request and queues it. When the queue is full the job is recorded as
+// failed and ErrReportQueueFull returned, instead of generating the report on the request.
+func (s *ReportJobService) Submit(ctx context.Context, req SubmitReportJobRequest, actor DocumentActor) (*models.ReportJob, error) {
+	if req.Params == nil {
+		req.Params = map[string]string{}
+	}
+	if _, err := validateReportParams(req.ReportType, req.Params); err != nil {
+		return nil, err
+	}
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	job := &models.ReportJob{
+		ReportType:    req.ReportType,
+		Params:        params,
+		Status:        models.ReportJobQueued,
+		RequestedByID: actor.UserID,
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create report job: %w", err)
+	}
+
+	jobID, role := job.ID, actor.Role
+	if !s.queue.TryEnqueue(func(ctx context.Context) error {
+		return s.Run(ctx, jobID, role)
+	}) {
+		if err := s.repo.Fail(ctx, job.ID, ErrReportQueueFull.Error(), time.Now()); err != nil {
+			logger.Error(fmt.Sprintf("[ReportJobService] Failed to record refusal of job %d: %v", job.ID, err))
+		}
+		return nil, ErrReportQueueFull
+	}
+	return job, nil
+}
+
+// Run generates the report of a queued job and stores its output. Failures are recorded on the job
+// and the requester is notified either way.
+func (s *ReportJobService) Run(ctx context.Context, jobID uint, role string) error {
+	started, err := s.repo.Start(ctx, jobID, time.Now())
+	if err != nil || !started {
+		return err
+	}
+	job, err := s.repo.FindByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.generate(ctx, job, DocumentActor{UserID: job.RequestedByID, Role: role}); err != nil {
+		message := err.Error()
+		if failErr := s.repo.Fail(ctx, job.ID, message, time.Now()); failErr != nil {
+			logger.Error(fmt.Sprintf("[ReportJobService] Failed to record failure of job %d: %v", job.ID, failErr))
+		}
+		s.notify(ctx, job, "Reporte fallido", fmt.Sprintf("No se pudo generar el reporte %s (#%d): %s", job.ReportType, job.ID, message))
+		return fmt.Errorf("report job %d failed: %w", job.ID, err)
+	}
+	s.notify(ctx, job, "Reporte listo", fmt.Sprintf("El reporte %s (#%d) está listo para descargar hasta el %s",
+		job.ReportType, job.ID, job.ExpiresAt.Format("02/01/2006")))
+	return nil
+}
+
+func (s *ReportJobService) generate(ctx context.Context, job *models.ReportJob, actor DocumentActor) error {
+	var params map[string]string
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	kind, err := validateReportParams(job.ReportType, params)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reportJobTimeout)
+	defer cancel()
+	buf, err := kind.generate(ctx, s.reportSvc, params, actor)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	filename := kind.filename(params)
+	path, err := s.storage.UploadFromBytes(buf.Bytes(), filename, "report_jobs")
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.retention)
+	hash := sha256Hex(buf.Bytes())
+	job.Status = models.ReportJobCompleted
+	job.Filename = filename
+	job.ContentType = kind.contentType
+	job.Path = &path
+	job.Size = int64(buf.Len())
+	job.SHA256 = &hash
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+	if err := s.repo.Complete(ctx, job); err != nil {
+		if removeErr := s.storage.Delete(path); removeErr != nil {
+			logger.Error(fmt.Sprintf("[ReportJobService] Failed to remove output of job %d: %v", job.ID, removeErr))
+		}
+		return fmt.Errorf("failed to store report: %w", err)
+	}
+	return nil
+}
+
+// Get returns a job; users see their own jobs and admins every job
+func (s *ReportJobService) Get(ctx context.Context, id uint, actor DocumentActor) (*models.ReportJob, error) {
+	job, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if actor.Role != models.RoleAdmin && job.RequestedByID != actor.UserID {
+		return nil, ErrNotFound
+	}
+	return job, nil
+}
+
+// List returns the most recent jobs of the user, or of everyone for admins
+func (s *ReportJobService) List(ctx context.Context, actor DocumentActor) ([]models.ReportJob, error) {
+	requestedBy := actor.UserID
+	if actor.Role == models.RoleAdmin {
+		requestedBy = 0
+	}
+	return s.repo.List(ctx, requestedBy, reportJobListLimit)
+}
+
+// Download returns a completed job with the full path of its output
+func (s *ReportJobService) Download(ctx context.Context, id uint, actor DocumentActor) (*models.ReportJob, string, error) {
+	job, err := s.Get(ctx, id, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case job.Status == models.ReportJobExpired,
+		job.Status == models.ReportJobCompleted && job.ExpiresAt != nil && time.Now().After(*job.ExpiresAt):
+		return nil, "", ErrReportExpired
+	case job.Status == models.ReportJobFailed:
+		return nil, "", fmt.Errorf("%w: el reporte falló", ErrInvalidState)
+	case job.Status != models.ReportJobCompleted || job.Path == nil:
+		return nil, "", fmt.Errorf("%w: el reporte aún no está listo", ErrInvalidState)
+	}
+	fullPath, err := s.storage.SafeFullPath(*job.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	return job, fullPath, nil
+}
+
+// ExpireOld deletes the outputs past their retention and marks their jobs expired
+func (s *ReportJobService) ExpireOld(ctx context.Context) error {
+	expired, err := s.repo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, job := range expired {
+		if job.Path != nil && s.storage.Exists(*job.Path) {
+			if err := s.storage.Delete(*job.Path); err != nil {
+				logger.Error(fmt.Sprintf("[ReportJobService] Failed to delete output of job %d: %v", job.ID, err))
+				continue
+			}
+		}
+		if err := s.repo.MarkExpired(ctx, job.ID); err != nil {
+			logger.Error(fmt.Sprintf("[ReportJobService] Failed to expire job %d: %v", job.ID, err))
+		}
+	}
+	if len(expired) > 0 {
+		logger.Info(fmt.Sprintf("[ReportJobService] Expired %d report(s)", len(expired)))
+	}
+	return nil
+}
+
+// FailInterrupted fails the jobs a restart left queued or running, since the worker queue is in memory
+func (s *ReportJobService) FailInterrupted(ctx context.Context) error {
+	count, err := s.repo.FailUnfinished(ctx, "Interrumpido por un reinicio del servidor; solicítelo de nuevo", time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		logger.Info(fmt.Sprintf("[ReportJobService] Failed %d report job(s) interrupted by a restart", count))
+	}
+	return nil
+}
+
+func (s *ReportJobService) notify(ctx context.Context, job *models.ReportJob, title, message string) {
+	if s.notificationSvc == nil {
+		return
+	}
+	if err := s.notificationSvc.NotifyUser(ctx, job.RequestedByID, title, message, models.NotificationTypeReportReady); err != nil {
+		logger.Error(fmt.Sprintf("[ReportJobService] Failed to notify user %d about job %d: %v", job.RequestedByID, job.ID, err))
+	}
+}
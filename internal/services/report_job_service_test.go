@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/config"
+	"github.com/sjperalta/fintera-api/internal/jobs"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/storage"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockReportJobRepository struct {
+	jobs []*models.ReportJob
+}
+
+func (m *mockReportJobRepository) Create(ctx context.Context, job *models.ReportJob) error {
+	job.ID = uint(len(m.jobs) + 1)
+	job.CreatedAt = time.Now()
+	m.jobs = append(m.jobs, job)
+	return nil
+}
+
+func (m *mockReportJobRepository) FindByID(ctx context.Context, id uint) (*models.ReportJob, error) {
+	for _, job := range m.jobs {
+		if job.ID == id {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockReportJobRepository) List(ctx context.Context, requestedByID uint, limit int) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	for _, job := range m.jobs {
+		if requestedByID == 0 || job.RequestedByID == requestedByID {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *mockReportJobRepository) Start(ctx context.Context, id uint, at time.Time) (bool, error) {
+	for _, job := range m.jobs {
+		if job.ID == id && job.Status == models.ReportJobQueued {
+			job.Status, job.StartedAt = models.ReportJobRunning, &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockReportJobRepository) Complete(ctx context.Context, job *models.ReportJob) error {
+	copied := *job
+	*m.jobs[job.ID-1] = copied
+	return nil
+}
+
+func (m *mockReportJobRepository) Fail(ctx context.Context, id uint, message string, at time.Time) error {
+	job := m.jobs[id-1]
+	job.Status, job.Error, job.CompletedAt = models.ReportJobFailed, &message, &at
+	return nil
+}
+
+func (m *mockReportJobRepository) FindExpired(ctx context.Context, now time.Time) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	for _, job := range m.jobs {
+		if job.Status == models.ReportJobCompleted && job.ExpiresAt != nil && job.ExpiresAt.Before(now) {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *mockReportJobRepository) MarkExpired(ctx context.Context, id uint) error {
+	m.jobs[id-1].Status, m.jobs[id-1].Path = models.ReportJobExpired, nil
+	return nil
+}
+
+func (m *mockReportJobRepository) FailUnfinished(ctx context.Context, message string, at time.Time) (int64, error) {
+	var count int64
+	for _, job := range m.jobs {
+		if job.Status == models.ReportJobQueued || job.Status == models.ReportJobRunning {
+			job.Status, job.Error = models.ReportJobFailed, &message
+			count++
+		}
+	}
+	return count, nil
+}
+
+func newTestReportJobService(t *testing.T, repo *mockReportJobRepository, payments *mockPaymentRepository) (*ReportJobService, *storage.LocalStorage) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	reports := NewReportService(payments, nil, nil, nil, nil, nil, nil, nil)
+	return NewReportJobService(repo, reports, nil, store, nil, &config.Config{ReportRetentionDays: 3}), store
+}
+
+func queueReportJob(t *testing.T, repo *mockReportJobRepository, reportType string, params map[string]string, userID uint) *models.ReportJob {
+	encoded, err := json.Marshal(params)
+	require.NoError(t, err)
+	job := &models.ReportJob{ReportType: reportType, Params: encoded, Status: models.ReportJobQueued, RequestedByID: userID}
+	require.NoError(t, repo.Create(context.Background(), job))
+	return job
+}
+
+func TestSubmitReportJobValidation(t *testing.T) {
+	svc, _ := newTestReportJobService(t, &mockReportJobRepository{}, &mockPaymentRepository{})
+	seller := DocumentActor{UserID: 5, Role: models.RoleSeller}
+
+	_, err := svc.Submit(context.Background(), SubmitReportJobRequest{ReportType: "everything_csv"}, seller)
+	assert.ErrorIs(t, err, ErrInvalidReportJob)
+
+	_, err = svc.Submit(context.Background(), SubmitReportJobRequest{ReportType: "customer_record_pdf"}, seller)
+	assert.ErrorIs(t, err, ErrInvalidReportJob)
+	assert.Contains(t, err.Error(), "contract_id")
+
+	_, err = svc.Submit(context.Background(), SubmitReportJobRequest{
+		ReportType: "commissions_csv", Params: map[string]string{"start_date": "01/10/2026"},
+	}, seller)
+	assert.ErrorIs(t, err, ErrInvalidReportJob)
+}
+
+func TestSubmitReportJobRefusedWhenQueueIsFull(t *testing.T) {
+	logger.Setup("test")
+	repo := &mockReportJobRepository{}
+	svc, _ := newTestReportJobService(t, repo, &mockPaymentRepository{})
+	// Nothing drains the queue, so it holds a single job
+	svc.queue = jobs.NewWorker(0).NewQueue("reports", 0, 1)
+	admin := DocumentActor{UserID: 1, Role: models.RoleAdmin}
+	req := SubmitReportJobRequest{ReportType: "overdue_payments_csv"}
+
+	job, err := svc.Submit(context.Background(), req, admin)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportJobQueued, job.Status)
+
+	_, err = svc.Submit(context.Background(), req, admin)
+	assert.ErrorIs(t, err, ErrReportQueueFull)
+	require.Len(t, repo.jobs, 2)
+	assert.Equal(t, models.ReportJobFailed, repo.jobs[1].Status)
+	assert.Equal(t, models.ReportJobQueued, repo.jobs[0].Status)
+}
+
+func TestReportJobRunDownloadAndExpire(t *testing.T) {
+	logger.Setup("test")
+	ctx := context.Background()
+	repo := &mockReportJobRepository{}
+	paid := 1500.0
+	payments := &mockPaymentRepository{mockList: func(ctx context.Context, query *repository.ListQuery) ([]models.Payment, int64, error) {
+		return []models.Payment{{ID: 1, ContractID: 7, PaymentType: models.PaymentTypeInstallment, PaidAmount: &paid}}, 1, nil
+	}}
+	svc, store := newTestReportJobService(t, repo, payments)
+	owner := DocumentActor{UserID: 5, Role: models.RoleSeller}
+
+	job := queueReportJob(t, repo, "total_revenue_csv", map[string]string{"project_id": "3"}, owner.UserID)
+	_, _, err := svc.Download(ctx, job.ID, owner)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	require.NoError(t, svc.Run(ctx, job.ID, owner.Role))
+	completed, err := svc.Get(ctx, job.ID, owner)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportJobCompleted, completed.Status)
+	assert.Equal(t, "revenue.csv", completed.Filename)
+	assert.WithinDuration(t, time.Now().Add(72*time.Hour), *completed.ExpiresAt, time.Minute)
+	assert.True(t, completed.ToResponse().Downloadable)
+
+	_, fullPath, err := svc.Download(ctx, job.ID, owner)
+	require.NoError(t, err)
+	content, err := os.ReadFile(fullPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "Pago ID,"))
+	assert.Contains(t, string(content), "1500.00")
+
+	// Other sellers don't see the job; admins do
+	_, err = svc.Get(ctx, job.ID, DocumentActor{UserID: 6, Role: models.RoleSeller})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.Get(ctx, job.ID, DocumentActor{UserID: 1, Role: models.RoleAdmin})
+	assert.NoError(t, err)
+
+	// Running it again does nothing
+	require.NoError(t, svc.Run(ctx, job.ID, owner.Role))
+
+	past := time.Now().Add(-time.Hour)
+	repo.jobs[0].ExpiresAt = &past
+	_, _, err = svc.Download(ctx, job.ID, owner)
+	assert.ErrorIs(t, err, ErrReportExpired)
+
+	path := *repo.jobs[0].Path
+	require.NoError(t, svc.ExpireOld(ctx))
+	assert.Equal(t, models.ReportJobExpired, repo.jobs[0].Status)
+	assert.False(t, store.Exists(path))
+	_, _, err = svc.Download(ctx, job.ID, owner)
+	assert.ErrorIs(t, err, ErrReportExpired)
+}
+
+func TestReportJobFailures(t *testing.T) {
+	logger.Setup("test")
+	ctx := context.Background()
+	repo := &mockReportJobRepository{}
+	svc, _ := newTestReportJobService(t, repo, &mockPaymentRepository{})
+	owner := DocumentActor{UserID: 5, Role: models.RoleSeller}
+
+	job := queueReportJob(t, repo, "no_such_report", map[string]string{}, owner.UserID)
+	assert.Error(t, svc.Run(ctx, job.ID, owner.Role))
+	assert.Equal(t, models.ReportJobFailed, repo.jobs[0].Status)
+	assert.Contains(t, *repo.jobs[0].Error, "desconocido")
+	_, _, err := svc.Download(ctx, job.ID, owner)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	queueReportJob(t, repo, "overdue_payments_csv", map[string]string{}, owner.UserID)
+	require.NoError(t, svc.FailInterrupted(ctx))
+	assert.Equal(t, models.ReportJobFailed, repo.jobs[1].Status)
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+)
+
+// nativeLayouts are the reports drawn with gofpdf, keyed by the HTML template they replace. Their
+// text follows the templates; a change to one must be made to the other, which
+// TestNativeLegalTextMatchesTemplates checks for the contracts.
+var nativeLayouts = map[string]nativeLayout{
+	"user_balance.html":        balanceLayout,
+	"contract_promise.html":    promiseContractLayout,
+	"rescission_contract.html": rescissionLayout,
+	"customer_record.html":     customerRecordLayout,
+}
+
+// companyRepresentative is the legal representative of the company who signs the contracts
+const (
+	companyRepresentative         = "RUBÉN DE JESÚS MENJIVAR AYALA"
+	companyRepresentativeIdentity = "0506-1990-01420"
+)
+
+func balanceLayout(doc *pdfDocument, data map[string]interface{}) error {
+	user, ok := data["User"].(*models.User)
+	if !ok {
+		return fmt.Errorf("missing user")
+	}
+	contracts, _ := data["Contracts"].([]balanceContract)
+
+	doc.title("Estado de Cuenta", 18)
+	doc.field("Cliente:", user.FullName)
+	doc.field("Identidad:", user.Identity)
+	doc.field("Fecha de Emisión:", dataText(data, "Date"))
+
+	for _, contract := range contracts {
+		doc.heading(fmt.Sprintf("Contrato #%d", contract.ID))
+		rows := make([][]string, len(contract.Payments))
+		for i, p := range contract.Payments {
+			rows[i] = []string{p.PaymentType, p.DueDate, p.Amount, p.PaidAmount, p.Status}
+		}
+		doc.table([]string{"Tipo de Pago", "Vencimiento", "Monto", "Pagado", "Estado"}, []float64{0.28, 0.18, 0.18, 0.18, 0.18}, rows)
+	}
+	return nil
+}
+
+func promiseContractLayout(doc *pdfDocument, data map[string]interface{}) error {
+	t := func(key string) string { return dataText(data, key) }
+	parties, _ := data["Parties"].([]contractPartyLine)
+	discounts, _ := data["Discounts"].([]discountLine)
+	client := t("ClientName")
+	currency := t("Currency")
+
+	doc.title("Documento Privado de Promesa de Compra-Venta de un bien Inmueble", 14)
+
+	doc.paragraph("Nosotros: **" + companyRepresentative + "**, mayor de edad, hondureño, soltero, Ingeniero en " +
+		"Producción Industrial, con Número de Documento Nacional de Identificación **" + companyRepresentativeIdentity +
+		"**, con domicilio en la ciudad de Puerto Cortés, departamento de Cortés, en su condición de Presidente del " +
+		"Consejo de Administración de la Sociedad Mercantil **“INVERSIONES FAMA, SOCIEDAD ANÓNIMA DE CAPITAL VARIABLE”** " +
+		"constituida mediante Instrumento Público Número 76 de fecha Ocho (08) de Febrero del año Dos Mil Veinticuatro " +
+		"(2024) ante los oficios del Notario Público Lourdes Pamela Blanco Luque y debidamente inscrita bajo número " +
+		"Setenta y ocho (78) del Tomo Sesenta (60) del año Dos Mil Veinticuatro (2024), del Registro de Comerciales " +
+		"Sociales del Instituto de la Propiedad Inmueble, Mercantil, Hipotecas y Anotaciones Preventivas de la ciudad de " +
+		"Puerto Cortés, documentos de los cuales yo el Notario **DOY FE** de haber tenido a la vista y la cual cuenta con " +
+		"las facultades necesarias para comparecer ante este tipo de actos; y **" + client + "**, mayor de edad, casado, " +
+		"hondureño, con Número de Documento Nacional de Identificación **" + t("ClientIdentity") + "**, con domicilio en **" +
+		t("ClientAddress") + "**.")
+	for _, party := range parties {
+		text := "Asimismo comparece **" + party.FullName + "**, con Número de Documento Nacional de Identificación **" +
+			party.Identity + "**, en su calidad de **" + party.Role + "**"
+		if party.Ownership != "" {
+			text += ", con un " + party.Ownership + "% de propiedad sobre el lote"
+		}
+		doc.paragraph(text + ".")
+	}
+	if hasCoBuyers, _ := data["HasCoBuyers"].(bool); hasCoBuyers {
+		doc.paragraph("A **" + client + "** le corresponde el " + t("ApplicantOwnership") + "% de propiedad sobre el lote.")
+	}
+	doc.paragraph("**HACEMOS CONSTAR:** Que hemos convenido celebrar, como al efecto celebramos, el presente **CONTRATO " +
+		"PRIVADO DE PROMESA DE VENTA DE UN BIEN INMUEBLE**, consistente en la compra por parte de **" + client +
+		"** de **UN (01)** lote de terreno ubicado en **" + t("ProjectAddress") + "**, **" + t("ProjectName") +
+		"**, Municipio de Omoa.")
+	doc.paragraph("Que se describe así: **" + t("LotName") + "** **" + t("LotAddress") + "**, con las medidas y " +
+		"colindancias siguientes:")
+	doc.paragraph("**NORTE:** Mide trece metros (" + t("LotWidth") + "m) – colinda con " + t("LotNorth") + "\n" +
+		"**SUR:** Mide trece metros (" + t("LotWidth") + "m) – colinda con " + t("LotSouth") + "\n" +
+		"**ESTE:** Mide veintidós metros (" + t("LotLength") + "m) – colinda con " + t("LotEast") + "\n" +
+		"**OESTE:** Mide veintidós metros (" + t("LotLength") + "m) – colinda con " + t("LotWest"))
+	doc.paragraph("Con extensión superficial de " + t("LotAreaM2") + " METROS CUADRADOS (" + t("LotAreaM2") + " M2) o " +
+		t("LotAreaUnit") + " " + t("MeasurementUnit") + " (" + t("LotAreaUnit") + " " + t("MeasurementUnit") +
+		"), cuya matrícula es " + t("LotRegistrationNumber") + " inscrita debidamente en el Instituto de la Propiedad. " +
+		"Dicha promesa de venta está sujeta a las cláusulas y condiciones siguientes:")
+	doc.paragraph("**PRIMERA. DERECHOS, PRECIO, RESERVACIÓN Y PAGO DE PRIMA:** El señor **" + companyRepresentative +
+		"** manifiesta que su representada es dueña y legítima poseedora del lote de terreno antes descrito, el cual " +
+		"cuenta con **DOMINIO PLENO** y está ubicado en " + t("ProjectAddress") + ", teniendo convenido con el CLIENTE " +
+		"dárselo en venta por el precio base de **" + t("AmountWords") + " (L. " + t("Amount") + ")**; monto que será " +
+		"recibido de la siguiente manera:")
+	if len(discounts) > 0 {
+		text := "**DESCUENTOS APLICADOS:** El precio de lista del lote es de " + t("ListPriceWords") + " (" + currency +
+			" " + t("ListPrice") + "), al cual se aplican los siguientes descuentos:"
+		for _, discount := range discounts {
+			text += "\n**" + discount.Description + ":** " + discount.AmountWords + " (" + currency + " " + discount.Amount + ")"
+		}
+		doc.paragraph(text)
+	}
+	doc.paragraph("**INCISO A) PAGO DE RESERVACIÓN:** En fecha " + t("Date") + ", depositó la cantidad de " +
+		t("ReserveAmountWords") + " (" + currency + " " + t("ReserveAmount") + ") a la cuenta 2120545245 de BANCO DE OCCIDENTE.")
+	if downPayment, _ := data["RawDownPayment"].(float64); downPayment > 0 {
+		doc.paragraph("**INCISO B) PAGO/COMPLEMENTO DE PRIMA:** Hasta la fecha " + t("Date") + ", se depositará la " +
+			"cantidad de " + t("DownPaymentWords") + " (" + currency + " " + t("DownPayment") + ") a la cuenta 2120545245 " +
+			"de BANCO DE OCCIDENTE.")
+	}
+	if t("FinancingType") == models.FinancingTypeDirect {
+		doc.paragraph("**INCISO C) MONTO A FINANCIAR:** La cantidad de " + t("FinancingAmountWords") + " (" + currency +
+			" " + t("FinancingAmount") + ") será financiada por medio de INVERSIONES FAMA S.A. DE C.V. a un plazo de " +
+			t("PaymentTerm") + " meses al 0% de interés. La primera cuota será el " + t("FirstPaymentDate") +
+			" y la última el " + t("LastPaymentDate") + ", haciendo un total de " + t("PaymentTerm") + " pagos por un " +
+			"monto de " + t("MonthlyPaymentWords") + " (" + currency + " " + t("MonthlyPayment") + ").")
+	} else {
+		doc.paragraph("**INCISO C) PAGO TOTAL:** El saldo restante de " + t("FinancingAmountWords") + " (" + currency +
+			" " + t("FinancingAmount") + ") será cancelado en un único pago a más tardar el " + t("MaxPaymentDate") +
+			", depositado a la cuenta 2120545245 de BANCO DE OCCIDENTE.")
+	}
+	doc.paragraph("Lo descrito anteriormente queda sujeto a lo siguiente:")
+	doc.paragraph("**PRIMERO:** En caso de que " + client + " se atrase con el pago en la fecha acordada, se aplicará " +
+		"una tasa de interés moratorio del " + t("InterestRate") + "% mensual sobre el monto adeudado, a partir del día siguiente.")
+	doc.paragraph("**SEGUNDO:** En caso de que " + client + " falte a dos cuotas consecutivas, se considerarán las " +
+		"siguientes alternativas.")
+
+	doc.paragraph("**UNO:** FINANCIAMIENTO BANCARIO: Se otorgará un plazo de 15 días para que una entidad bancaria " +
+		"financie la cantidad restante del terreno.")
+	doc.paragraph("**DOS:** Si no se obtiene financiamiento, se dará un plazo adicional de 15 días para que el agente de " +
+		"ventas de Inversiones FAMA S.A. DE C.V. gestione la venta del derecho adquirido.")
+	doc.paragraph("**TRES:** En un total de 30 días, " + client + " podrá vender su derecho a un amigo o familiar.")
+	doc.paragraph("**CUATRO:** Si se agotan estas opciones, " + client + " perderá el derecho sobre el lote, junto con " +
+		"lo pagado hasta la fecha, sin derecho a devolución.")
+
+	doc.paragraph("**TERCERA:** " + companyRepresentative + " se compromete a entregar el proyecto denominado " +
+		t("ProjectName") + " con las siguientes mejoras: parques de recreación equipados (canchas de fútbol, baloncesto " +
+		"y voleibol), senderos, muro perimetral, calles pavimentadas, casa club (kioscos y piscina de uso común), cámaras " +
+		"de vigilancia, parqueo vehicular en casa club, seguridad privada 24/7, caseta de control de acceso, agua potable " +
+		"y energía eléctrica, a más tardar en " + t("DeliveryDate") + ".")
+	doc.paragraph("**CUARTA:** En caso de incumplimiento de las fechas de entrega, se procederá a la devolución de lo " +
+		"pagado por " + client + " en un plazo de 40 días hábiles, contados a partir de la solicitud de devolución.")
+	doc.paragraph("**QUINTA:** Los costos de escrituración correrán por cuenta del cliente, " + client + ", quien podrá " +
+		"escriturar una vez cancelado en su totalidad el valor del terreno.")
+	doc.paragraph("**SEXTA:** El vendedor se compromete a entregar toda la documentación necesaria para el proceso de " +
+		"escrituración a favor del cliente. Ambas partes aceptan y se comprometen a cumplir fielmente los términos de " +
+		"esta promesa de compra-venta.")
+
+	doc.paragraph("En fe de lo cual, y para los efectos legales correspondientes, se firma el presente documento en la " +
+		"ciudad de Puerto Cortés, Departamento de Cortés, en fecha **" + t("Date") + "**.")
+
+	companySignature, _ := data["CompanySignature"].(template.URL)
+	clientSignature, _ := data["ClientSignature"].(template.URL)
+	doc.signatureBlock(companyRepresentative, "DNI: "+companyRepresentativeIdentity, companySignature)
+	doc.signatureBlock(client, "DNI: "+t("ClientIdentity"), clientSignature)
+	for _, party := range parties {
+		doc.signatureBlock(party.FullName, party.Role+" - DNI: "+party.Identity, "")
+	}
+
+	if page, ok := data["SignaturePage"].(SignedContractPage); ok {
+		signaturePageLayout(doc, page)
+	}
+	return nil
+}
+
+// signaturePageLayout appends the electronic signature page of a signed promise contract
+func signaturePageLayout(doc *pdfDocument, page SignedContractPage) {
+	doc.pdf.AddPage()
+	doc.title("Hoja de Firmas Electrónicas", 14)
+	doc.paragraph(fmt.Sprintf("El presente documento fue firmado electrónicamente por las partes mediante firma manuscrita "+
+		"digitalizada, confirmada con un código de un solo uso enviado al correo electrónico de cada firmante. Solicitud "+
+		"de firma No. **%d** del contrato No. **%d**.", page.RequestID, page.ContractID))
+	doc.paragraph("Huella SHA-256 del documento original sin firmas:")
+	doc.hash(page.DocumentSHA256)
+	for _, signer := range page.Signers {
+		doc.heading(signer.Role)
+		doc.field("Nombre:", signer.Name)
+		doc.field("Correo:", signer.Email)
+		doc.field("Firmado:", "el "+signer.SignedAt+" desde "+signer.IPAddress)
+		doc.field("Firma:", "")
+		doc.hash(signer.SignatureSHA256)
+		doc.field("Eslabón anterior:", "")
+		doc.hash(signer.PrevHash)
+		doc.field("Eslabón:", "")
+		doc.hash(signer.Hash)
+	}
+	doc.paragraph("Cada eslabón es la huella SHA-256 del eslabón anterior, el firmante, su firma y la fecha de firma. " +
+		"La cadena se cierra con la huella de este documento firmado y puede verificarse en el sistema.")
+}
+
+func rescissionLayout(doc *pdfDocument, data map[string]interface{}) error {
+	t := func(key string) string { return dataText(data, key) }
+	parties, _ := data["Parties"].([]contractPartyLine)
+	address := t("ApplicantAddress")
+	if address == "" {
+		address = "_______________________________________"
+	}
+
+	doc.title("RESCISIÓN DE DOCUMENTO PRIVADO DE PROMESA DE COMPRA-VENTA DE UN BIEN INMUEBLE", 14)
+	doc.paragraph("Comparecen personalmente los señores:")
+	doc.paragraph("**" + companyRepresentative + "**, mayor de edad, soltero, Ingeniero en Producción Industrial, con " +
+		"número de Documento Nacional de Identificación: " + companyRepresentativeIdentity + ", domiciliado en " +
+		"Cienaguita, Municipio de Puerto Cortés, Departamento de Cortés.")
+	doc.paragraph("**" + t("ApplicantName") + "**, mayor de edad, hondureño(a), con DNI **" + t("ApplicantIdentity") +
+		"**, domiciliado(a) en " + address + ".")
+	for _, party := range parties {
+		text := "**" + party.FullName + "**, con DNI **" + party.Identity + "**, en su calidad de **" + party.Role + "**"
+		if party.Ownership != "" {
+			text += " con un " + party.Ownership + "% de propiedad sobre el lote"
+		}
+		doc.paragraph(text + ".")
+	}
+	doc.paragraph("Manifiestan que en fecha **" + t("ContractDate") + "**, convinieron suscribir un CONTRATO PRIVADO DE " +
+		"PROMESA DE VENTA DE UN BIEN INMUEBLE, consistente en el lote **" + t("LotName") + "** del proyecto denominado **" +
+		t("ProjectName") + "**, ubicado en " + t("ProjectAddress") + ".")
+	doc.paragraph("El lote se describe así:")
+	doc.paragraph("• AL NORTE: " + t("North") + "\n• AL SUR: " + t("South") + "\n• AL ESTE: " + t("East") +
+		"\n• AL OESTE: " + t("West"))
+	doc.paragraph("Se acuerda la devolución de **L. " + t("RefundAmount") + "**, sancionando **L. " + t("PenaltyAmount") +
+		"** por incumplimiento, con depósito en la cuenta ** _______________________________ ** de " +
+		"_______________________________ a nombre de " + t("ApplicantName") + ".")
+	doc.paragraph("En Puerto Cortés, a los " + t("Day") + " días del mes de " + t("Month") + " del año " + t("Year") + ".")
+
+	doc.pdf.Ln(10)
+	x, y := doc.pdf.GetXY()
+	doc.pdf.Line(x, y, x+doc.width(), y)
+	doc.pdf.Ln(10)
+	doc.paragraph("**" + companyRepresentative + "**\nID: " + companyRepresentativeIdentity + " - HUELLA")
+	doc.pdf.Ln(10)
+	doc.paragraph("**" + t("ApplicantNameUpper") + "**\nID: " + t("ApplicantIdentity") + " - HUELLA")
+	for _, party := range parties {
+		doc.pdf.Ln(10)
+		doc.paragraph("**" + party.FullName + "**\n" + party.Role + " - ID: " + party.Identity + " - HUELLA")
+	}
+	return nil
+}
+
+func customerRecordLayout(doc *pdfDocument, data map[string]interface{}) error {
+	t := func(key string) string { return dataText(data, key) }
+	blank := "_______________________"
+
+	doc.title("INVERSIONES FAMA", 18)
+
+	doc.heading("Información general del Cliente")
+	doc.field("Nombre:", t("ClientName"))
+	doc.field("Identificación:", t("ClientID"))
+	doc.field("Telefono:", t("ClientPhone"))
+	doc.field("Correo:", t("ClientEmail"))
+	doc.field("Dirección:", t("ClientAddress"))
+
+	doc.heading("Información General del Terreno")
+	doc.field("Nombre del Proyecto:", t("ProjectName"))
+	doc.field("No del Lote:", t("LotName"))
+	doc.field("Dimensiones:", t("Dimensions"))
+	doc.field("Medida:", t("Area"))
+	doc.field("Número de Contrato:", t("ContractID"))
+	doc.field("Tipo de Financiamiento:", t("FinancingType"))
+	doc.field("Precio:", t("Price"))
+	if hasOverride, _ := data["HasOverride"].(bool); hasOverride {
+		doc.field("Precio Base:", t("BasePrice"))
+		doc.field("Precio Personalizado:", t("OverridePrice"))
+	}
+	doc.field("Reserva:", t("ReserveAmount"))
+	doc.field("Prima:", t("DownPayment"))
+	doc.field("Cuota:", t("InstallmentAmount"))
+	doc.field("Plazo:", t("Term"))
+	doc.field("Fecha de Inicio:", t("StartDate"))
+	doc.field("Fecha de Finalización:", t("EndDate"))
+
+	doc.heading("Información Adicional (Referencia)")
+	doc.field("Nombre Completo:", blank)
+	doc.field("No. de Indentidad:", blank)
+	doc.field("Celular:", blank)
+	doc.field("Parentezco:", blank)
+
+	doc.signatureColumns("FIRMA DEL CLIENTE", "FIRMA VENDEDOR")
+
+	doc.pdf.Ln(3)
+	doc.pdf.SetFont(pdfFont, "", 8)
+	doc.pdf.SetTextColor(120, 120, 120)
+	doc.pdf.CellFormat(0, 4, doc.tr("Generado automáticamente | Fecha: "+t("GeneratedDate")), "", 1, "C", false, 0, "")
+	doc.pdf.SetTextColor(0, 0, 0)
+	return nil
+}
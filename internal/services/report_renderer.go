@@ -0,0 +1,318 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	wkhtmltopdf "github.com/SebastiaanKlippert/go-wkhtmltopdf"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PDF rendering backends
+const (
+	PDFRendererNative      = "native"
+	PDFRendererWkhtmltopdf = "wkhtmltopdf"
+)
+
+// PDFRenderer turns a report template and its data into a PDF
+type PDFRenderer interface {
+	Render(templateName string, data map[string]interface{}) (*bytes.Buffer, error)
+}
+
+// NewPDFRenderer returns the PDF backend configured: "wkhtmltopdf" renders the HTML templates with
+// wkhtmltopdf; anything else renders natively with gofpdf, falling back to wkhtmltopdf for the
+// templates that have no native layout yet
+func NewPDFRenderer(backend string) PDFRenderer {
+	html := &wkhtmltopdfRenderer{}
+	if backend == PDFRendererWkhtmltopdf {
+		return html
+	}
+	return &nativeRenderer{layouts: nativeLayouts, fallback: html, compress: true, now: time.Now}
+}
+
+// wkhtmltopdfRenderer renders the embedded HTML templates with the wkhtmltopdf binary
+type wkhtmltopdfRenderer struct{}
+
+func (r *wkhtmltopdfRenderer) Render(templateName string, data map[string]interface{}) (*bytes.Buffer, error) {
+	// 1. Parse Template from embedded FS
+	tmplPath := "templates/reports/" + templateName
+	tmpl, err := template.ParseFS(reportTemplates, tmplPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s (path: %s): %w", templateName, tmplPath, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// 2. Convert to PDF using wkhtmltopdf
+	pdfg, err := wkhtmltopdf.NewPDFGenerator()
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "wkhtmltopdf") && (strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "not installed")) {
+			return nil, fmt.Errorf("wkhtmltopdf is required to render %s but is not installed. Install it: macOS: brew install --cask wkhtmltopdf (or download from https://wkhtmltopdf.org); Linux: apt-get install wkhtmltopdf. Original error: %w", templateName, err)
+		}
+		return nil, fmt.Errorf("failed to create pdf generator: %w", err)
+	}
+
+	// Set options
+	pdfg.Dpi.Set(300)
+	pdfg.Orientation.Set(wkhtmltopdf.OrientationPortrait)
+	pdfg.Grayscale.Set(false)
+
+	// Add page from buffer
+	page := wkhtmltopdf.NewPageReader(bytes.NewReader(buf.Bytes()))
+	page.EnableLocalFileAccess.Set(true)
+	pdfg.AddPage(page)
+
+	// Create PDF
+	if err := pdfg.Create(); err != nil {
+		return nil, fmt.Errorf("failed to create pdf: %w", err)
+	}
+
+	return pdfg.Buffer(), nil
+}
+
+// nativeLayout draws a report on a document from the same data its HTML template receives
+type nativeLayout func(doc *pdfDocument, data map[string]interface{}) error
+
+// nativeRenderer draws reports with gofpdf, without external binaries
+type nativeRenderer struct {
+	layouts  map[string]nativeLayout
+	fallback PDFRenderer
+	compress bool
+	now      func() time.Time
+}
+
+func (r *nativeRenderer) Render(templateName string, data map[string]interface{}) (*bytes.Buffer, error) {
+	layout, ok := r.layouts[templateName]
+	if !ok {
+		if r.fallback == nil {
+			return nil, fmt.Errorf("no native layout for template %s", templateName)
+		}
+		return r.fallback.Render(templateName, data)
+	}
+
+	doc := newPDFDocument(r.now(), r.compress)
+	if err := layout(doc, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", templateName, err)
+	}
+	if stamp, ok := data["Verification"].(DocumentStamp); ok {
+		doc.verification(stamp)
+	}
+	var buf bytes.Buffer
+	if err := doc.pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", templateName, err)
+	}
+	return &buf, nil
+}
+
+// pdfDocument is an A4 gofpdf document with the building blocks of the report layouts. Text is
+// UTF-8 and is translated to the code page of the core fonts.
+type pdfDocument struct {
+	pdf    *gofpdf.Fpdf
+	tr     func(string) string
+	images int
+	// written keeps the titles, headings and paragraphs drawn, without bold marks, so layouts can
+	// be checked against their templates
+	written []string
+}
+
+const (
+	pdfFont       = "Helvetica"
+	pdfMargin     = 20.0
+	pdfLineHeight = 5.0
+)
+
+func newPDFDocument(now time.Time, compress bool) *pdfDocument {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetCreationDate(now)
+	pdf.SetModificationDate(now)
+	pdf.SetCompression(compress)
+	pdf.SetCatalogSort(true)
+	pdf.AliasNbPages("")
+	doc := &pdfDocument{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 4, doc.tr(fmt.Sprintf("Página %d/{nb}", pdf.PageNo())), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AddPage()
+	return doc
+}
+
+// width returns the printable width of the page
+func (d *pdfDocument) width() float64 {
+	w, _ := d.pdf.GetPageSize()
+	left, _, right, _ := d.pdf.GetMargins()
+	return w - left - right
+}
+
+// title writes a centered bold title
+func (d *pdfDocument) title(text string, size float64) {
+	d.written = append(d.written, text)
+	d.pdf.SetFont(pdfFont, "B", size)
+	d.pdf.MultiCell(0, size*0.5, d.tr(text), "", "C", false)
+	d.pdf.Ln(4)
+}
+
+// heading writes a section heading with a rule under it
+func (d *pdfDocument) heading(text string) {
+	d.written = append(d.written, text)
+	d.pdf.Ln(2)
+	d.pdf.SetFont(pdfFont, "B", 12)
+	d.pdf.CellFormat(0, 7, d.tr(text), "B", 1, "L", false, 0, "")
+	d.pdf.Ln(2)
+}
+
+// paragraph writes flowing text in which **marked** runs are bold
+func (d *pdfDocument) paragraph(text string) {
+	d.written = append(d.written, strings.ReplaceAll(text, "**", ""))
+	d.pdf.SetFont(pdfFont, "", 10)
+	for i, run := range strings.Split(text, "**") {
+		style := ""
+		if i%2 == 1 {
+			style = "B"
+		}
+		d.pdf.SetFont(pdfFont, style, 10)
+		d.pdf.Write(pdfLineHeight, d.tr(run))
+	}
+	d.pdf.SetFont(pdfFont, "", 10)
+	d.pdf.Ln(pdfLineHeight + 2)
+}
+
+// field writes a label and its value on one line
+func (d *pdfDocument) field(label, value string) {
+	d.pdf.SetFont(pdfFont, "B", 10)
+	d.pdf.CellFormat(55, 5.5, d.tr(label), "", 0, "L", false, 0, "")
+	d.pdf.SetFont(pdfFont, "", 10)
+	d.pdf.MultiCell(0, 5.5, d.tr(value), "", "L", false)
+}
+
+// table writes a table with a shaded header row; widths are fractions of the printable width
+func (d *pdfDocument) table(headers []string, widths []float64, rows [][]string) {
+	total := d.width()
+	d.pdf.SetFont(pdfFont, "B", 9)
+	d.pdf.SetFillColor(240, 240, 240)
+	for i, header := range headers {
+		d.pdf.CellFormat(total*widths[i], 7, d.tr(header), "1", 0, "L", true, 0, "")
+	}
+	d.pdf.Ln(-1)
+	d.pdf.SetFont(pdfFont, "", 9)
+	for _, row := range rows {
+		for i, cell := range row {
+			d.pdf.CellFormat(total*widths[i], 6, d.tr(cell), "1", 0, "L", false, 0, "")
+		}
+		d.pdf.Ln(-1)
+	}
+	d.pdf.Ln(4)
+}
+
+// signatureBlock writes a signer's name and caption, their drawn signature when given, and the line
+// on which they sign and leave their fingerprint
+func (d *pdfDocument) signatureBlock(name, caption string, signature template.URL) {
+	if _, y := d.pdf.GetXY(); y > 225 {
+		d.pdf.AddPage()
+	}
+	d.pdf.Ln(6)
+	d.pdf.SetFont(pdfFont, "B", 10)
+	d.pdf.CellFormat(0, 5, d.tr(name), "", 1, "L", false, 0, "")
+	d.pdf.SetFont(pdfFont, "", 10)
+	d.pdf.CellFormat(0, 5, d.tr(caption), "", 1, "L", false, 0, "")
+	x, y := d.pdf.GetXY()
+	if signature != "" {
+		if err := d.image(string(signature), x, y+1, 50, 20); err == nil {
+			y += 21
+		}
+	}
+	d.pdf.Line(x, y+12, x+70, y+12)
+	d.pdf.SetXY(x, y+13)
+	d.pdf.SetFont(pdfFont, "", 9)
+	d.pdf.CellFormat(70, 5, "HUELLA", "", 1, "C", false, 0, "")
+}
+
+// signatureColumns writes blank signature lines side by side with their labels under them
+func (d *pdfDocument) signatureColumns(labels ...string) {
+	if _, y := d.pdf.GetXY(); y > 245 {
+		d.pdf.AddPage()
+	}
+	d.pdf.Ln(20)
+	x, y := d.pdf.GetXY()
+	gap := 10.0
+	w := (d.width() - gap*float64(len(labels)-1)) / float64(len(labels))
+	d.pdf.SetFont(pdfFont, "B", 9)
+	for i, label := range labels {
+		left := x + float64(i)*(w+gap)
+		d.pdf.Line(left, y, left+w, y)
+		d.pdf.SetXY(left, y+1)
+		d.pdf.CellFormat(w, 5, d.tr(label), "", 0, "C", false, 0, "")
+	}
+	d.pdf.SetXY(x, y+8)
+}
+
+// hash writes a digest in a small monospaced font
+func (d *pdfDocument) hash(value string) {
+	d.pdf.SetFont("Courier", "", 8)
+	d.pdf.MultiCell(0, 4, d.tr(strings.TrimSpace(value)), "", "L", false)
+	d.pdf.Ln(1)
+}
+
+// image draws a PNG data URI
+func (d *pdfDocument) image(dataURI string, x, y, w, h float64) error {
+	encoded := dataURI
+	if i := strings.Index(encoded, ","); i >= 0 {
+		encoded = encoded[i+1:]
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	d.images++
+	name := fmt.Sprintf("image%d", d.images)
+	options := gofpdf.ImageOptions{ImageType: "PNG"}
+	d.pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(raw))
+	if err := d.pdf.Error(); err != nil {
+		return err
+	}
+	d.pdf.ImageOptions(name, x, y, w, h, false, options, 0, "")
+	return d.pdf.Error()
+}
+
+// verification writes the document code and the QR code linking to its public verification
+func (d *pdfDocument) verification(stamp DocumentStamp) {
+	if _, y := d.pdf.GetXY(); y > 242 {
+		d.pdf.AddPage()
+	}
+	d.pdf.Ln(8)
+	x, y := d.pdf.GetXY()
+	d.pdf.SetDrawColor(200, 200, 200)
+	d.pdf.Line(x, y, x+d.width(), y)
+	d.pdf.SetDrawColor(0, 0, 0)
+	textX := x
+	if err := d.image(string(stamp.QRCode), x, y+2, 25, 25); err == nil {
+		textX = x + 28
+	}
+	d.pdf.SetXY(textX, y+4)
+	d.pdf.SetFont(pdfFont, "B", 8)
+	d.pdf.CellFormat(0, 4, d.tr("Documento N° "+stamp.Code), "", 2, "L", false, 0, "")
+	d.pdf.SetFont(pdfFont, "", 8)
+	d.pdf.MultiCell(0, 4, d.tr("Verifique la autenticidad de este documento escaneando el código QR o en "+stamp.URL), "", "L", false)
+}
+
+// dataText returns a value of the template data as text, or "" when it is missing
+func dataText(data map[string]interface{}, key string) string {
+	value, ok := data[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"flag"
+	stdhtml "html"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden PDFs in testdata")
+
+// newGoldenRenderer renders uncompressed PDFs with a fixed creation date so output is reproducible
+func newGoldenRenderer() *nativeRenderer {
+	return &nativeRenderer{
+		layouts: nativeLayouts,
+		now:     func() time.Time { return time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC) },
+	}
+}
+
+func goldenStamp(t *testing.T) DocumentStamp {
+	url := "https://api.example.com/api/v1/verify/AB12-CD34-EF56"
+	qr, err := qrcode.Encode(url, qrcode.Medium, 128)
+	require.NoError(t, err)
+	return DocumentStamp{
+		Code:   "AB12-CD34-EF56",
+		URL:    url,
+		QRCode: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(qr)),
+	}
+}
+
+func goldenContractData() map[string]interface{} {
+	return map[string]interface{}{
+		"ClientName": "María José López", "ClientIdentity": "0501-1985-00123", "ClientAddress": "Barrio El Centro, Puerto Cortés",
+		"ProjectName": "Residencial Las Palmas", "ProjectAddress": "Aldea Chivana", "InterestRate": "2.00",
+		"LotName": "Lote 14", "LotAddress": "Bloque B", "LotWidth": "13.00", "LotLength": "22.00",
+		"LotAreaM2": "286.00", "LotAreaUnit": "410.18", "MeasurementUnit": "VARAS CUADRADAS",
+		"Amount": "250,000.00", "AmountWords": "DOSCIENTOS CINCUENTA MIL LEMPIRAS EXACTOS",
+		"ReserveAmount": "5,000.00", "ReserveAmountWords": "CINCO MIL LEMPIRAS EXACTOS",
+		"DownPayment": "20,000.00", "DownPaymentWords": "VEINTE MIL LEMPIRAS EXACTOS",
+		"FinancingAmount": "225,000.00", "FinancingAmountWords": "DOSCIENTOS VEINTICINCO MIL LEMPIRAS EXACTOS",
+		"PaymentTerm": 60, "Currency": "HNL", "FirstPaymentDate": "15 de noviembre de 2026", "LastPaymentDate": "15 de octubre de 2031",
+		"MonthlyPayment": "3,750.00", "MonthlyPaymentWords": "TRES MIL SETECIENTOS CINCUENTA LEMPIRAS EXACTOS",
+		"Date": "18 de octubre de 2026", "FinancingType": models.FinancingTypeDirect, "MaxPaymentDate": "", "RawDownPayment": 20000.0,
+		"ListPrice": "265,000.00", "ListPriceWords": "DOSCIENTOS SESENTA Y CINCO MIL LEMPIRAS EXACTOS",
+		"Discounts":   []discountLine{{Description: "Promoción de lanzamiento", Amount: "15,000.00", AmountWords: "QUINCE MIL LEMPIRAS EXACTOS"}},
+		"Parties":     []contractPartyLine{{Role: "COMPRADOR", FullName: "Juan Pérez", Identity: "0501-1980-00456", Ownership: "40.00"}},
+		"HasCoBuyers": true, "ApplicantOwnership": "60.00",
+	}
+}
+
+func goldenRescissionData() map[string]interface{} {
+	return map[string]interface{}{
+		"ApplicantName": "María José López", "ApplicantNameUpper": "MARÍA JOSÉ LÓPEZ", "ApplicantIdentity": "0501-1985-00123",
+		"ApplicantAddress": "", "ContractDate": "01/09/2026", "LotName": "Lote 14", "ProjectName": "Residencial Las Palmas",
+		"ProjectAddress": "Aldea Chivana", "LotLength": "22.00", "LotWidth": "13.00",
+		"North": "13.00 metros", "South": "13.00 metros", "East": "22.00 metros", "West": "22.00 metros",
+		"RefundAmount": "12,500.00", "PenaltyAmount": "2,500.00", "Day": 18, "Month": "octubre", "Year": 2026,
+		"Parties": []contractPartyLine{{Role: "FIADOR", FullName: "Juan Pérez", Identity: "0501-1980-00456"}},
+	}
+}
+
+func TestNativeReportsMatchGoldenFiles(t *testing.T) {
+	signature := goldenStamp(t).QRCode
+	signed := goldenContractData()
+	signed["CompanySignature"] = signature
+	signed["ClientSignature"] = signature
+	signed["SignaturePage"] = SignedContractPage{
+		RequestID: 7, ContractID: 42, DocumentSHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Signers: []SignedContractSigner{{
+			Role: "Comprador", Name: "María José López", Email: "maria@example.com", SignedAt: "18/10/2026 09:12", IPAddress: "190.5.10.20",
+			SignatureSHA256: "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			PrevHash:        "0000000000000000000000000000000000000000000000000000000000000000",
+			Hash:            "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9",
+		}},
+	}
+
+	cases := []struct {
+		golden   string
+		template string
+		data     map[string]interface{}
+	}{
+		{"user_balance.pdf", "user_balance.html", map[string]interface{}{
+			"User": &models.User{FullName: "María José López", Identity: "0501-1985-00123"},
+			"Date": "18/10/2026",
+			"Contracts": []balanceContract{{ID: 42, Payments: []balancePayment{
+				{PaymentType: "Reservación", DueDate: "01/09/2026", Amount: "5,000.00", PaidAmount: "5,000.00", Status: models.PaymentStatusPaid},
+				{PaymentType: "Cuota", DueDate: "15/11/2026", Amount: "3,750.00", PaidAmount: "0.00", Status: models.PaymentStatusPending},
+			}}},
+		}},
+		{"contract_promise.pdf", "contract_promise.html", goldenContractData()},
+		{"contract_promise_signed.pdf", "contract_promise.html", signed},
+		{"rescission_contract.pdf", "rescission_contract.html", goldenRescissionData()},
+		{"customer_record.pdf", "customer_record.html", map[string]interface{}{
+			"ClientName": "María José López", "ClientID": "0501-1985-00123", "ClientPhone": "9999-0000", "ClientEmail": "maria@example.com",
+			"ClientAddress": "Barrio El Centro", "ProjectName": "Residencial Las Palmas", "LotName": "Lote 14", "Dimensions": "13.00 x 22.00",
+			"Area": "286.00 m²", "ContractID": 42, "FinancingType": "Directo", "Price": "L 250,000.00", "HasOverride": true,
+			"BasePrice": "L 265,000.00", "OverridePrice": "L 250,000.00", "ReserveAmount": "L 5,000.00", "DownPayment": "L 20,000.00",
+			"InstallmentAmount": "L 3,750.00", "Term": "60 meses", "StartDate": "15/11/2026", "EndDate": "15/10/2031",
+			"GeneratedDate": "18/10/2026", "Verification": goldenStamp(t),
+		}},
+	}
+
+	renderer := newGoldenRenderer()
+	for _, tc := range cases {
+		t.Run(tc.golden, func(t *testing.T) {
+			pdf, err := renderer.Render(tc.template, tc.data)
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(pdf.Bytes(), []byte("%PDF-")))
+
+			path := filepath.Join("testdata", "golden", tc.golden)
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, pdf.Bytes(), 0o644))
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "run go test ./internal/services -run TestNativeReportsMatchGoldenFiles -update")
+			assert.True(t, bytes.Equal(want, pdf.Bytes()), "%s differs from the golden file; rerun with -update if the change is intended", tc.golden)
+		})
+	}
+}
+
+var (
+	htmlInlineTag  = regexp.MustCompile(`</?(strong|b|em|i|u|span)\b[^>]*>`)
+	htmlTag        = regexp.MustCompile(`(?s)<style.*?</style>|<[^>]+>`)
+	htmlWhitespace = regexp.MustCompile(`\s+`)
+)
+
+// plainText collapses the whitespace of text, as a browser lays it out
+func plainText(text string) string {
+	return strings.TrimSpace(htmlWhitespace.ReplaceAllString(text, " "))
+}
+
+// The legal text is written twice, in the HTML templates and in the native layouts: every title and
+// paragraph drawn natively must read the same in the template rendered from the same data
+func TestNativeLegalTextMatchesTemplates(t *testing.T) {
+	cases := []struct {
+		template string
+		data     map[string]interface{}
+	}{
+		{"contract_promise.html", goldenContractData()},
+		{"rescission_contract.html", goldenRescissionData()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.template, func(t *testing.T) {
+			tmpl, err := template.ParseFS(reportTemplates, "templates/reports/"+tc.template)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, tmpl.Execute(&buf, tc.data))
+			html := plainText(stdhtml.UnescapeString(htmlTag.ReplaceAllString(htmlInlineTag.ReplaceAllString(buf.String(), ""), " ")))
+
+			doc := newPDFDocument(time.Now(), false)
+			require.NoError(t, nativeLayouts[tc.template](doc, tc.data))
+			require.NotEmpty(t, doc.written)
+			for _, text := range doc.written {
+				// List bullets are drawn by the template's <li>
+				assert.Contains(t, html, plainText(strings.ReplaceAll(text, "•", "")))
+			}
+		})
+	}
+}
+
+type stubRenderer struct{ rendered []string }
+
+func (s *stubRenderer) Render(templateName string, data map[string]interface{}) (*bytes.Buffer, error) {
+	s.rendered = append(s.rendered, templateName)
+	return bytes.NewBufferString("%PDF-stub"), nil
+}
+
+func TestNativeRendererFallsBackForTemplatesWithoutLayout(t *testing.T) {
+	fallback := &stubRenderer{}
+	renderer := newGoldenRenderer()
+	renderer.fallback = fallback
+
+	pdf, err := renderer.Render("cession_contract.html", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-stub", pdf.String())
+	assert.Equal(t, []string{"cession_contract.html"}, fallback.rendered)
+
+	_, err = renderer.Render("customer_record.html", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Len(t, fallback.rendered, 1)
+
+	// Without a fallback the missing layout is an error, and bad data fails the render
+	renderer.fallback = nil
+	_, err = renderer.Render("cession_contract.html", map[string]interface{}{})
+	assert.Error(t, err)
+	_, err = renderer.Render("user_balance.html", map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestNewPDFRendererSelectsBackend(t *testing.T) {
+	_, ok := NewPDFRenderer(PDFRendererWkhtmltopdf).(*wkhtmltopdfRenderer)
+	assert.True(t, ok)
+
+	native, ok := NewPDFRenderer(PDFRendererNative).(*nativeRenderer)
+	require.True(t, ok)
+	assert.True(t, native.compress)
+	_, ok = native.fallback.(*wkhtmltopdfRenderer)
+	assert.True(t, ok)
+
+	_, ok = NewPDFRenderer("").(*nativeRenderer)
+	assert.True(t, ok)
+}
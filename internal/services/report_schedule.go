@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportSchedule is a parsed cron expression: minute, hour, day of month, month and day of week
+// (0-6, Sunday is 0 or 7). Fields accept *, numbers, ranges (1-5), lists (1,15) and steps (*/15).
+// The shortcuts @hourly, @daily, @weekly and @monthly are accepted too.
+type reportSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	anyDay, anyWeekday                     bool
+}
+
+// reportScheduleHorizon bounds the search for the next run, e.g. for "0 0 31 2 *"
+const reportScheduleHorizon = 5 * 366 * 24 * time.Hour
+
+var reportScheduleShortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+func parseReportSchedule(expr string) (*reportSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if shortcut, ok := reportScheduleShortcuts[expr]; ok {
+		expr = shortcut
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("la programación debe tener 5 campos (minuto hora día mes día-de-semana)")
+	}
+
+	s := &reportSchedule{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	var err error
+	if s.minutes, err = parseScheduleField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minuto inválido: %w", err)
+	}
+	if s.hours, err = parseScheduleField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hora inválida: %w", err)
+	}
+	if s.days, err = parseScheduleField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("día inválido: %w", err)
+	}
+	if s.months, err = parseScheduleField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("mes inválido: %w", err)
+	}
+	if s.weekdays, err = parseScheduleField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("día de la semana inválido: %w", err)
+	}
+	if s.weekdays[7] {
+		s.weekdays[0] = true
+	}
+	return s, nil
+}
+
+// parseScheduleField expands one field of a cron expression into the set of values it matches
+func parseScheduleField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, stepText, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("paso %q", stepText)
+			}
+			part, step = base, n
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			loText, hiText, _ := strings.Cut(part, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(loText)
+			hi, err2 = strconv.Atoi(hiText)
+			if err1 != nil || err2 != nil || lo > hi {
+				return nil, fmt.Errorf("rango %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("valor %q", part)
+			}
+			lo = n
+			if step == 1 {
+				hi = n
+			}
+		}
+		if lo < min || hi > max {
+			return nil, fmt.Errorf("%q fuera de %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// matchesDay follows cron: when both day of month and day of week are restricted, either matches
+func (s *reportSchedule) matchesDay(t time.Time) bool {
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Next returns the first time matching the schedule strictly after t, truncated to the minute.
+// It returns the zero time when nothing matches within the horizon.
+func (s *reportSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(reportScheduleHorizon)
+	for t.Before(limit) {
+		switch {
+		case !s.months[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -4,13 +4,15 @@ import (
 	"bytes"
 	"context"
 	"embed"
+	"encoding/base64"
 	"encoding/csv"
 	"fmt"
 	"html/template"
+	"math"
 	"strings"
 	"time"
 
-	"github.com/SebastiaanKlippert/go-wkhtmltopdf"
+	"github.com/sjperalta/fintera-api/internal/config"
 	"github.com/sjperalta/fintera-api/internal/models"
 	"github.com/sjperalta/fintera-api/internal/repository"
 )
@@ -31,21 +33,46 @@ type CommissionReportItem struct {
 }
 
 type ReportService struct {
-	paymentRepo  repository.PaymentRepository
-	contractRepo repository.ContractRepository
-	userRepo     repository.UserRepository
+	paymentRepo     repository.PaymentRepository
+	contractRepo    repository.ContractRepository
+	userRepo        repository.UserRepository
+	cessionRepo     repository.ContractCessionRepository
+	lotChangeRepo   repository.ContractLotChangeRepository
+	issuedRepo      repository.IssuedDocumentRepository
+	receivablesRepo repository.ReceivablesRepository
+	renderer        PDFRenderer
+	issuerName      string
+	issuerRTN       string
+	apiURL          string
 }
 
 func NewReportService(
 	paymentRepo repository.PaymentRepository,
 	contractRepo repository.ContractRepository,
 	userRepo repository.UserRepository,
+	cessionRepo repository.ContractCessionRepository,
+	lotChangeRepo repository.ContractLotChangeRepository,
+	issuedRepo repository.IssuedDocumentRepository,
+	receivablesRepo repository.ReceivablesRepository,
+	cfg *config.Config,
 ) *ReportService {
-	return &ReportService{
-		paymentRepo:  paymentRepo,
-		contractRepo: contractRepo,
-		userRepo:     userRepo,
-	}
+	s := &ReportService{
+		paymentRepo:     paymentRepo,
+		contractRepo:    contractRepo,
+		userRepo:        userRepo,
+		cessionRepo:     cessionRepo,
+		lotChangeRepo:   lotChangeRepo,
+		issuedRepo:      issuedRepo,
+		receivablesRepo: receivablesRepo,
+		renderer:        NewPDFRenderer(PDFRendererNative),
+	}
+	if cfg != nil {
+		s.renderer = NewPDFRenderer(cfg.PDFRenderer)
+		s.issuerName = cfg.IssuerName
+		s.issuerRTN = cfg.IssuerRTN
+		s.apiURL = strings.TrimRight(cfg.APIURL, "/")
+	}
+	return s
 }
 
 // GenerateCommissions returns a list of commission data. When userID > 0 and filterByCreator
@@ -59,8 +86,8 @@ func (s *ReportService) GenerateCommissions(ctx context.Context, startDate, endD
 		listQuery.Filters["approved_to"] = endDate
 	}
 
-	// Include both Approved and Closed contracts
-	listQuery.Filters["status_in"] = fmt.Sprintf("%s,%s", models.ContractStatusApproved, models.ContractStatusClosed)
+	// Include Approved (signed or not) and Closed contracts
+	listQuery.Filters["status_in"] = fmt.Sprintf("%s,%s,%s", models.ContractStatusApproved, models.ContractStatusSigned, models.ContractStatusClosed)
 
 	query := &repository.ContractQuery{
 		ListQuery: listQuery,
@@ -195,8 +222,15 @@ func (s *ReportService) GenerateCommissionsCSV(ctx context.Context, startDate, e
 	return b, nil
 }
 
+// RevenueReportFilters narrows the revenue report to a project, phase or block (all optional)
+type RevenueReportFilters struct {
+	ProjectID string
+	PhaseID   string
+	BlockID   string
+}
+
 // GenerateRevenueCSV generates a CSV report of revenue
-func (s *ReportService) GenerateRevenueCSV(ctx context.Context) (*bytes.Buffer, error) {
+func (s *ReportService) GenerateRevenueCSV(ctx context.Context, filters RevenueReportFilters) (*bytes.Buffer, error) {
 	b := &bytes.Buffer{}
 	w := csv.NewWriter(b)
 
@@ -204,7 +238,7 @@ func (s *ReportService) GenerateRevenueCSV(ctx context.Context) (*bytes.Buffer,
 	header := []string{
 		"Pago ID", "Contrato", "Tipo", "Monto Pagado", "Fecha Pago",
 		"Cliente", "Identidad", "Proyecto", "Lote",
-		"Financiamiento", "Plazo",
+		"Financiamiento", "Plazo", "Etapa", "Bloque",
 	}
 	if err := w.Write(header); err != nil {
 		return nil, err
@@ -232,6 +266,9 @@ func (s *ReportService) GenerateRevenueCSV(ctx context.Context) (*bytes.Buffer,
 	for {
 		query := repository.NewListQuery()
 		query.Filters["status"] = "paid"
+		query.Filters["project_id"] = filters.ProjectID
+		query.Filters["phase_id"] = filters.PhaseID
+		query.Filters["block_id"] = filters.BlockID
 		query.Page = page
 		query.PerPage = pageSize
 
@@ -262,6 +299,8 @@ func (s *ReportService) GenerateRevenueCSV(ctx context.Context) (*bytes.Buffer,
 			lotName := "N/A"
 			financingType := "N/A"
 			paymentTerm := "N/A"
+			phaseName := ""
+			blockName := ""
 
 			if p.Contract.ID != 0 {
 				if p.Contract.ApplicantUser.ID != 0 {
@@ -274,6 +313,12 @@ func (s *ReportService) GenerateRevenueCSV(ctx context.Context) (*bytes.Buffer,
 					if p.Contract.Lot.Project.ID != 0 {
 						projectName = p.Contract.Lot.Project.Name
 					}
+					if p.Contract.Lot.Phase != nil {
+						phaseName = p.Contract.Lot.Phase.Name
+					}
+					if p.Contract.Lot.Block != nil {
+						blockName = p.Contract.Lot.Block.Name
+					}
 				}
 
 				if val, ok := financingTypeTranslations[p.Contract.FinancingType]; ok {
@@ -302,6 +347,8 @@ func (s *ReportService) GenerateRevenueCSV(ctx context.Context) (*bytes.Buffer,
 				lotName,
 				financingType,
 				paymentTerm,
+				phaseName,
+				blockName,
 			}
 			if err := w.Write(record); err != nil {
 				return nil, err
@@ -367,46 +414,23 @@ func (s *ReportService) GenerateOverduePaymentsCSV(ctx context.Context) (*bytes.
 	return b, nil
 }
 
-// Helper to generate PDF from HTML template (templates are embedded in the binary)
-func (s *ReportService) generatePDF(templateName string, data interface{}) (*bytes.Buffer, error) {
-	// 1. Parse Template from embedded FS
-	tmplPath := "templates/reports/" + templateName
-	tmpl, err := template.ParseFS(reportTemplates, tmplPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template %s (path: %s): %w", templateName, tmplPath, err)
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
-	}
-
-	// 2. Convert to PDF using wkhtmltopdf
-	pdfg, err := wkhtmltopdf.NewPDFGenerator()
-	if err != nil {
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "wkhtmltopdf") && (strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "not installed")) {
-			return nil, fmt.Errorf("wkhtmltopdf is required for PDF generation but is not installed. Install it: macOS: brew install --cask wkhtmltopdf (or download from https://wkhtmltopdf.org); Linux: apt-get install wkhtmltopdf. Original error: %w", err)
-		}
-		return nil, fmt.Errorf("failed to create pdf generator: %w", err)
-	}
-
-	// Set options
-	pdfg.Dpi.Set(300)
-	pdfg.Orientation.Set(wkhtmltopdf.OrientationPortrait)
-	pdfg.Grayscale.Set(false)
-
-	// Add page from buffer
-	page := wkhtmltopdf.NewPageReader(bytes.NewReader(buf.Bytes()))
-	page.EnableLocalFileAccess.Set(true)
-	pdfg.AddPage(page)
+// generatePDF renders a report template to PDF with the configured backend
+func (s *ReportService) generatePDF(templateName string, data map[string]interface{}) (*bytes.Buffer, error) {
+	return s.renderer.Render(templateName, data)
+}
 
-	// Create PDF
-	if err := pdfg.Create(); err != nil {
-		return nil, fmt.Errorf("failed to create pdf: %w", err)
-	}
+// balancePayment and balanceContract are the rows of the statement of account
+type balancePayment struct {
+	PaymentType string
+	DueDate     string
+	Amount      string
+	PaidAmount  string
+	Status      string
+}
 
-	return pdfg.Buffer(), nil
+type balanceContract struct {
+	ID       uint
+	Payments []balancePayment
 }
 
 // GenerateUserBalancePDF generates a PDF statement of account for a user
@@ -422,25 +446,6 @@ func (s *ReportService) GenerateUserBalancePDF(ctx context.Context, userID uint)
 	}
 
 	// Prepare data for template
-	type PaymentData struct {
-		PaymentType string
-		DueDate     string
-		Amount      string
-		PaidAmount  string
-		Status      string
-	}
-
-	type ContractData struct {
-		ID       uint
-		Payments []PaymentData
-	}
-
-	type ReportData struct {
-		User      interface{}
-		Date      string
-		Contracts []ContractData
-	}
-
 	paymentTypeTranslations := map[string]string{
 		models.PaymentTypeReservation:      "Reservación",
 		models.PaymentTypeDownPayment:      "Prima",
@@ -450,21 +455,26 @@ func (s *ReportService) GenerateUserBalancePDF(ctx context.Context, userID uint)
 		models.PaymentTypeCapitalRepayment: "Amortización a Capital",
 	}
 
-	var contractDataList []ContractData
+	var contractDataList []balanceContract
+	var totalPaid, balance float64
 	for _, c := range contracts {
 		cDetails, err := s.contractRepo.FindByIDWithDetails(ctx, c.ID)
 		if err == nil {
-			var payments []PaymentData
+			var payments []balancePayment
 			for _, p := range cDetails.Payments {
 				paid := 0.0
 				if p.PaidAmount != nil {
 					paid = *p.PaidAmount
 				}
+				totalPaid += paid
+				if p.Status != models.PaymentStatusPaid {
+					balance += p.Amount - paid
+				}
 				paymentTypeLabel := p.PaymentType
 				if translated, ok := paymentTypeTranslations[p.PaymentType]; ok {
 					paymentTypeLabel = translated
 				}
-				payments = append(payments, PaymentData{
+				payments = append(payments, balancePayment{
 					PaymentType: paymentTypeLabel,
 					DueDate:     s.formatDateShort(p.DueDate),
 					Amount:      s.formatCurrency(p.Amount),
@@ -472,20 +482,26 @@ func (s *ReportService) GenerateUserBalancePDF(ctx context.Context, userID uint)
 					Status:      p.Status,
 				})
 			}
-			contractDataList = append(contractDataList, ContractData{
+			contractDataList = append(contractDataList, balanceContract{
 				ID:       c.ID,
 				Payments: payments,
 			})
 		}
 	}
 
-	data := ReportData{
-		User:      user,
-		Date:      s.formatDateShort(time.Now()),
-		Contracts: contractDataList,
+	data := map[string]interface{}{
+		"User":      user,
+		"Date":      s.formatDateShort(time.Now()),
+		"Contracts": contractDataList,
+	}
+	figures := []models.DocumentFigure{
+		{Label: "Titular", Value: models.MaskName(user.FullName)},
+		{Label: "Contratos", Value: fmt.Sprintf("%d", len(contractDataList))},
+		{Label: "Total pagado", Value: s.formatCurrencyWithLempiras(totalPaid)},
+		{Label: "Saldo pendiente", Value: s.formatCurrencyWithLempiras(balance)},
 	}
 
-	return s.generatePDF("user_balance.html", data)
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentBalanceStatement, UserID: &user.ID}, figures, "user_balance.html", data)
 }
 
 // GenerateContractPDF generates a PDF for a specific contract
@@ -496,7 +512,42 @@ func (s *ReportService) GenerateContractPDF(ctx context.Context, contractID uint
 	}
 
 	data := s.prepareContractPDFData(contract)
-	return s.generatePDF("contract_promise.html", data)
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentPromiseContract, ContractID: &contract.ID}, s.contractFigures(contract), "contract_promise.html", data)
+}
+
+// SignedContractPage is the electronic signature page appended to a signed promise contract
+type SignedContractPage struct {
+	RequestID      uint
+	ContractID     uint
+	DocumentSHA256 string
+	Signers        []SignedContractSigner
+}
+
+// SignedContractSigner is a signature listed on the signature page
+type SignedContractSigner struct {
+	Role            string
+	Name            string
+	Email           string
+	SignedAt        string
+	IPAddress       string
+	SignatureSHA256 string
+	PrevHash        string
+	Hash            string
+}
+
+// GenerateSignedContractPDF generates the promise contract of a contract with the drawn signatures
+// (PNG) of the company and the buyer embedded and the electronic signature page appended
+func (s *ReportService) GenerateSignedContractPDF(ctx context.Context, contractID uint, companySignature, clientSignature []byte, page SignedContractPage) (*bytes.Buffer, error) {
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := s.prepareContractPDFData(contract)
+	data["CompanySignature"] = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(companySignature))
+	data["ClientSignature"] = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(clientSignature))
+	data["SignaturePage"] = page
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentSignedPromise, ContractID: &contract.ID}, s.contractFigures(contract), "contract_promise.html", data)
 }
 
 // GenerateCustomerRecordPDF generates a PDF report for a customer record
@@ -614,7 +665,7 @@ func (s *ReportService) GenerateCustomerRecordPDF(ctx context.Context, contractI
 		"GeneratedDate":     s.formatDateShort(time.Now()),
 	}
 
-	return s.generatePDF("customer_record.html", data)
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentCustomerRecord, ContractID: &contract.ID}, s.contractFigures(contract), "customer_record.html", data)
 }
 
 // GenerateRescissionContractPDF generates a PDF for contract rescission
@@ -701,9 +752,276 @@ func (s *ReportService) GenerateRescissionContractPDF(ctx context.Context, contr
 		"Day":                dayStr,
 		"Month":              monthStr,
 		"Year":               yearStr,
+		"Parties":            contractPartyLines(contract),
+	}
+
+	figures := append(s.contractFigures(contract),
+		models.DocumentFigure{Label: "Reembolso", Value: s.formatCurrencyWithLempiras(refundAmount)},
+		models.DocumentFigure{Label: "Penalidad", Value: s.formatCurrencyWithLempiras(penaltyAmount)},
+	)
+
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentRescission, ContractID: &contract.ID}, figures, "rescission_contract.html", data)
+}
+
+// GenerateCessionPDF generates the cession of rights document of a contract cession
+func (s *ReportService) GenerateCessionPDF(ctx context.Context, cessionID uint) (*bytes.Buffer, error) {
+	cession, err := s.cessionRepo.FindByID(ctx, cessionID)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, cession.ContractID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := func(u models.User) string {
+		if u.Identity == "" {
+			return "____________________"
+		}
+		return u.Identity
+	}
+	toAddress := ""
+	if cession.ToUser.Address != nil {
+		toAddress = *cession.ToUser.Address
+	}
+	amount := 0.0
+	if contract.Amount != nil {
+		amount = *contract.Amount
+	}
+	// The balance is stored as debt (negative)
+	balance := -cession.BalanceAtCession
+	fee, note := 0.0, ""
+	if cession.TransferFee != nil {
+		fee = *cession.TransferFee
+	}
+	if cession.Note != nil {
+		note = *cession.Note
+	}
+
+	data := map[string]interface{}{
+		"FromName":       cession.FromUser.FullName,
+		"FromIdentity":   identity(cession.FromUser),
+		"ToName":         cession.ToUser.FullName,
+		"ToIdentity":     identity(cession.ToUser),
+		"ToAddress":      toAddress,
+		"ContractDate":   s.formatDateLong(contract.CreatedAt),
+		"LotName":        contract.Lot.Name,
+		"ProjectName":    contract.Lot.Project.Name,
+		"ProjectAddress": contract.Lot.Project.Address,
+		"Currency":       contract.Currency,
+		"Amount":         s.formatCurrency(amount),
+		"AmountWords":    s.formatAmountToWords(amount),
+		"Paid":           s.formatCurrency(cession.PaidAtCession),
+		"PaidWords":      s.formatAmountToWords(cession.PaidAtCession),
+		"Balance":        s.formatCurrency(balance),
+		"BalanceWords":   s.formatAmountToWords(balance),
+		"HasFee":         fee > 0,
+		"Fee":            s.formatCurrency(fee),
+		"FeeWords":       s.formatAmountToWords(fee),
+		"Note":           note,
+		"Day":            fmt.Sprintf("%d", cession.CreatedAt.Day()),
+		"Month":          s.getSpanishMonthFull(cession.CreatedAt.Month()),
+		"Year":           fmt.Sprintf("%d", cession.CreatedAt.Year()),
+	}
+
+	figures := []models.DocumentFigure{
+		{Label: "Contrato", Value: fmt.Sprintf("#%d", contract.ID)},
+		{Label: "Cedente", Value: models.MaskName(cession.FromUser.FullName)},
+		{Label: "Cesionario", Value: models.MaskName(cession.ToUser.FullName)},
+		{Label: "Fecha de cesión", Value: s.formatDateShort(cession.CreatedAt)},
+		{Label: "Saldo cedido", Value: fmt.Sprintf("%s %s", contract.Currency, s.formatCurrency(balance))},
+	}
+
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentCession, ContractID: &contract.ID}, figures, "cession_contract.html", data)
+}
+
+// GenerateLotChangeAddendumPDF generates the contract addendum of a lot change
+func (s *ReportService) GenerateLotChangeAddendumPDF(ctx context.Context, changeID uint) (*bytes.Buffer, error) {
+	change, err := s.lotChangeRepo.FindByID(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, change.ContractID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := contract.ApplicantUser.Identity
+	if identity == "" {
+		identity = "____________________"
+	}
+	difference := math.Abs(change.PriceDifference)
+	reason := ""
+	if change.Reason != nil {
+		reason = *change.Reason
+	}
+	// Remaining schedule as it stands now
+	var schedule []map[string]string
+	for _, p := range contract.Payments {
+		if p.Status != models.PaymentStatusPending && p.Status != models.PaymentStatusSubmitted {
+			continue
+		}
+		desc := ""
+		if p.Description != nil {
+			desc = *p.Description
+		}
+		schedule = append(schedule, map[string]string{
+			"Description": desc,
+			"DueDate":     p.DueDate.Format("02/01/2006"),
+			"Amount":      s.formatCurrency(p.Amount),
+		})
 	}
 
-	return s.generatePDF("rescission_contract.html", data)
+	data := map[string]interface{}{
+		"ApplicantName":     contract.ApplicantUser.FullName,
+		"ApplicantIdentity": identity,
+		"Parties":           contractPartyLines(contract),
+		"ContractID":        contract.ID,
+		"ContractDate":      s.formatDateLong(contract.CreatedAt),
+		"ProjectName":       change.FromLot.Project.Name,
+		"ProjectAddress":    change.FromLot.Project.Address,
+		"FromLotName":       change.FromLot.Name,
+		"FromLotArea":       fmt.Sprintf("%.2f", change.FromLot.Area()),
+		"ToLotName":         change.ToLot.Name,
+		"ToLotArea":         fmt.Sprintf("%.2f", change.ToLot.Area()),
+		"Currency":          contract.Currency,
+		"OldAmount":         s.formatCurrency(change.OldAmount),
+		"OldAmountWords":    s.formatAmountToWords(change.OldAmount),
+		"NewAmount":         s.formatCurrency(change.NewAmount),
+		"NewAmountWords":    s.formatAmountToWords(change.NewAmount),
+		"HasDifference":     change.PriceDifference != 0,
+		"IsIncrease":        change.PriceDifference > 0,
+		"Difference":        s.formatCurrency(difference),
+		"DifferenceWords":   s.formatAmountToWords(difference),
+		"Schedule":          schedule,
+		"Reason":            reason,
+		"Day":               fmt.Sprintf("%d", change.CreatedAt.Day()),
+		"Month":             s.getSpanishMonthFull(change.CreatedAt.Month()),
+		"Year":              fmt.Sprintf("%d", change.CreatedAt.Year()),
+	}
+
+	figures := []models.DocumentFigure{
+		{Label: "Contrato", Value: fmt.Sprintf("#%d", contract.ID)},
+		{Label: "Titular", Value: models.MaskName(contract.ApplicantUser.FullName)},
+		{Label: "Lote anterior", Value: change.FromLot.Name},
+		{Label: "Lote nuevo", Value: change.ToLot.Name},
+		{Label: "Nuevo monto", Value: fmt.Sprintf("%s %s", contract.Currency, s.formatCurrency(change.NewAmount))},
+	}
+
+	return s.issuePDF(ctx, &models.IssuedDocument{DocumentType: models.IssuedDocumentLotChange, ContractID: &contract.ID}, figures, "lot_change_addendum.html", data)
+}
+
+// GenerateReceiptPDF generates the official receipt of an approved payment. replacesNumber is the
+// number of the voided receipt it reissues, if any. Returns the issued document recorded for its
+// public verification (nil when issued documents are not recorded).
+func (s *ReportService) GenerateReceiptPDF(ctx context.Context, receipt *models.PaymentReceipt, replacesNumber string) (*bytes.Buffer, *models.IssuedDocument, error) {
+	payment, err := s.paymentRepo.FindByID(ctx, receipt.PaymentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, receipt.ContractID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	concept := getPaymentTypeDescription(payment.PaymentType)
+	if payment.Description != nil && *payment.Description != "" {
+		concept = fmt.Sprintf("%s (%s)", concept, *payment.Description)
+	}
+	data := map[string]interface{}{
+		"Issuer":         s.issuerName,
+		"Number":         receipt.DisplayNumber(),
+		"IssuedDate":     s.formatDateShort(receipt.IssuedAt),
+		"ClientName":     contract.ApplicantUser.FullName,
+		"ClientIdentity": contract.ApplicantUser.Identity,
+		"Currency":       receipt.Currency,
+		"Total":          s.formatCurrency(receipt.Total),
+		"TotalWords":     NumberToWords(receipt.Total),
+		"Concept":        concept,
+		"ContractID":     contract.ID,
+		"LotName":        contract.Lot.Name,
+		"ProjectName":    contract.Lot.Project.Name,
+		"PaymentDate":    s.formatDateShort(receipt.PaymentDate),
+		"Principal":      s.formatCurrency(receipt.Principal),
+		"Interest":       s.formatCurrency(receipt.Interest),
+		"Prepayment":     s.formatCurrency(receipt.Prepayment),
+		"Replaces":       replacesNumber,
+	}
+	figures := []models.DocumentFigure{
+		{Label: "Recibo", Value: receipt.DisplayNumber()},
+		{Label: "Contrato", Value: fmt.Sprintf("#%d", contract.ID)},
+		{Label: "Titular", Value: models.MaskName(contract.ApplicantUser.FullName)},
+		{Label: "Fecha de pago", Value: s.formatDateShort(receipt.PaymentDate)},
+		{Label: "Total recibido", Value: fmt.Sprintf("%s %s", receipt.Currency, s.formatCurrency(receipt.Total))},
+	}
+
+	document := &models.IssuedDocument{DocumentType: models.IssuedDocumentPaymentReceipt, ContractID: &contract.ID}
+	pdf, err := s.issuePDF(ctx, document, figures, "payment_receipt.html", data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if document.ID == 0 {
+		return pdf, nil, nil
+	}
+	return pdf, document, nil
+}
+
+// GenerateInvoicePDF renders a fiscal invoice (loaded with its range) with its authorization data
+func (s *ReportService) GenerateInvoicePDF(ctx context.Context, invoice *models.FiscalInvoice) (*bytes.Buffer, *models.IssuedDocument, error) {
+	contract, err := s.contractRepo.FindByIDWithDetails(ctx, invoice.ContractID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type invoiceLine struct {
+		Description string
+		Amount      string
+		Taxed       bool
+	}
+	var lines []invoiceLine
+	for _, line := range invoice.InvoiceLines() {
+		lines = append(lines, invoiceLine{Description: line.Description, Amount: s.formatCurrency(line.Amount), Taxed: line.Taxed})
+	}
+	fiscalRange := invoice.FiscalRange
+	data := map[string]interface{}{
+		"Issuer":      s.issuerName,
+		"IssuerRTN":   s.issuerRTN,
+		"CAI":         fiscalRange.CAI,
+		"Number":      invoice.Number,
+		"IssuedDate":  s.formatDateShort(invoice.IssuedAt),
+		"RangeFrom":   fiscalRange.FormatNumber(fiscalRange.RangeStart),
+		"RangeTo":     fiscalRange.FormatNumber(fiscalRange.RangeEnd),
+		"Deadline":    s.formatDateShort(fiscalRange.Deadline),
+		"ClientName":  invoice.CustomerName,
+		"ClientRTN":   invoice.CustomerRTN,
+		"ContractID":  contract.ID,
+		"LotName":     contract.Lot.Name,
+		"ProjectName": contract.Lot.Project.Name,
+		"Currency":    invoice.Currency,
+		"Lines":       lines,
+		"Exempt":      s.formatCurrency(invoice.Exempt),
+		"Taxable":     s.formatCurrency(invoice.Taxable),
+		"Tax":         s.formatCurrency(invoice.Tax),
+		"Total":       s.formatCurrency(invoice.Total),
+		"TotalWords":  NumberToWords(invoice.Total),
+	}
+	figures := []models.DocumentFigure{
+		{Label: "Factura", Value: invoice.Number},
+		{Label: "CAI", Value: fiscalRange.CAI},
+		{Label: "Cliente", Value: models.MaskName(invoice.CustomerName)},
+		{Label: "Fecha de emisión", Value: s.formatDateShort(invoice.IssuedAt)},
+		{Label: "Total", Value: fmt.Sprintf("%s %s", invoice.Currency, s.formatCurrency(invoice.Total))},
+	}
+
+	document := &models.IssuedDocument{DocumentType: models.IssuedDocumentFiscalInvoice, ContractID: &contract.ID}
+	pdf, err := s.issuePDF(ctx, document, figures, "fiscal_invoice.html", data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if document.ID == 0 {
+		return pdf, nil, nil
+	}
+	return pdf, document, nil
 }
 
 // SellerDashboardStats holds aggregated data for the seller dashboard
@@ -758,8 +1076,8 @@ func (s *ReportService) GetSellerDashboardStats(ctx context.Context, userID uint
 	monthSalesQuery := &repository.ContractQuery{
 		ListQuery: repository.NewListQuery(),
 		UserID:    userID,
-		Status:    models.ContractStatusApproved,
 	}
+	monthSalesQuery.Filters["status_in"] = strings.Join(models.ContractInForceStatuses, ",")
 	monthSalesQuery.Filters["approved_from"] = startOfRange.Format("2006-01-02")
 	monthSalesQuery.Filters["approved_to"] = endOfRange.Format("2006-01-02")
 
@@ -837,8 +1155,8 @@ func (s *ReportService) GetSellerDashboardStats(ctx context.Context, userID uint
 		chartQuery := &repository.ContractQuery{
 			ListQuery: repository.NewListQuery(),
 			UserID:    userID,
-			Status:    models.ContractStatusApproved,
 		}
+		chartQuery.Filters["status_in"] = strings.Join(models.ContractInForceStatuses, ",")
 		chartQuery.Filters["approved_from"] = mStart.Format("2006-01-02")
 		chartQuery.Filters["approved_to"] = mEnd.Format("2006-01-02")
 
@@ -1007,6 +1325,22 @@ func (s *ReportService) prepareContractPDFData(contract *models.Contract) map[st
 		maxPaymentDate = s.formatDateLong(*contract.MaxPaymentDate)
 	}
 
+	// Price breakdown (list price and promotions applied at creation)
+	listPrice := amount
+	var discounts []discountLine
+	for _, item := range contract.LineItems {
+		switch item.ItemType {
+		case models.LineItemTypeBasePrice:
+			listPrice = item.Amount
+		case models.LineItemTypeDiscount:
+			discounts = append(discounts, discountLine{
+				Description: item.Description,
+				Amount:      s.formatCurrency(-item.Amount),
+				AmountWords: s.formatAmountToWords(-item.Amount),
+			})
+		}
+	}
+
 	return map[string]interface{}{
 		"ClientName":           clientName,
 		"ClientIdentity":       clientIdentity,
@@ -1039,5 +1373,45 @@ func (s *ReportService) prepareContractPDFData(contract *models.Contract) map[st
 		"FinancingType":        contract.FinancingType,
 		"MaxPaymentDate":       maxPaymentDate,
 		"RawDownPayment":       downPayment,
+		"ListPrice":            s.formatCurrency(listPrice),
+		"ListPriceWords":       s.formatAmountToWords(listPrice),
+		"Discounts":            discounts,
+		"Parties":              contractPartyLines(contract),
+		"HasCoBuyers":          len(contract.PartiesWithRole(models.PartyRoleCoBuyer)) > 0,
+		"ApplicantOwnership":   fmt.Sprintf("%.2f", contract.ApplicantOwnershipPercentage()),
+	}
+}
+
+// discountLine is a promotion applied to the list price as shown in contract documents
+type discountLine struct {
+	Description string
+	Amount      string
+	AmountWords string
+}
+
+// contractPartyLine is a co-buyer, guarantor or legal representative as shown in contract documents
+type contractPartyLine struct {
+	Role      string
+	FullName  string
+	Identity  string
+	Ownership string // co-buyers only
+}
+
+func contractPartyLines(contract *models.Contract) []contractPartyLine {
+	var lines []contractPartyLine
+	for _, p := range models.WithOwnership(contract.Parties) {
+		line := contractPartyLine{
+			Role:     strings.ToUpper(p.RoleLabel()),
+			FullName: p.FullName,
+			Identity: "____________________",
+		}
+		if p.Identity != "" {
+			line.Identity = p.Identity
+		}
+		if p.OwnershipPercentage != nil {
+			line.Ownership = fmt.Sprintf("%.2f", *p.OwnershipPercentage)
+		}
+		lines = append(lines, line)
 	}
+	return lines
 }
@@ -57,7 +57,7 @@ func (m *mockPaymentRepository) GetMonthlyStats(ctx context.Context) (*repositor
 
 func TestGenerateRevenueCSV(t *testing.T) {
 	mockRepo := &mockPaymentRepository{}
-	service := NewReportService(mockRepo, nil, nil) // We only use paymentRepo for this method
+	service := NewReportService(mockRepo, nil, nil, nil, nil, nil, nil, nil) // We only use paymentRepo for this method
 
 	// Setup mock data
 	now := time.Now()
@@ -119,7 +119,7 @@ func TestGenerateRevenueCSV(t *testing.T) {
 	}
 
 	// Execute
-	buf, err := service.GenerateRevenueCSV(context.Background())
+	buf, err := service.GenerateRevenueCSV(context.Background(), RevenueReportFilters{})
 	assert.NoError(t, err)
 	assert.NotNil(t, buf)
 
@@ -132,7 +132,7 @@ func TestGenerateRevenueCSV(t *testing.T) {
 	expectedHeader := []string{
 		"Pago ID", "Contrato", "Tipo", "Monto Pagado", "Fecha Pago",
 		"Cliente", "Identidad", "Proyecto", "Lote",
-		"Financiamiento", "Plazo",
+		"Financiamiento", "Plazo", "Etapa", "Bloque",
 	}
 	assert.Equal(t, expectedHeader, records[0])
 
@@ -200,7 +200,7 @@ func (m *mockContractRepository) FindPendingReservations(ctx context.Context, ol
 
 func TestGenerateCustomerRecordPDF(t *testing.T) {
 	mockRepo := &mockContractRepository{}
-	service := NewReportService(nil, mockRepo, nil)
+	service := NewReportService(nil, mockRepo, nil, nil, nil, nil, nil, nil)
 
 	// Setup mock data
 	mockRepo.mockFindByIDWithDetails = func(ctx context.Context, id uint) (*models.Contract, error) {
@@ -270,7 +270,7 @@ func TestGenerateCustomerRecordPDF(t *testing.T) {
 
 func TestGenerateRescissionContractPDF(t *testing.T) {
 	mockRepo := &mockContractRepository{}
-	service := NewReportService(nil, mockRepo, nil)
+	service := NewReportService(nil, mockRepo, nil, nil, nil, nil, nil, nil)
 
 	// Setup mock data
 	mockRepo.mockFindByIDWithDetails = func(ctx context.Context, id uint) (*models.Contract, error) {
@@ -317,7 +317,7 @@ func TestGenerateRescissionContractPDF(t *testing.T) {
 
 func TestGenerateCommissions(t *testing.T) {
 	mockRepo := &mockContractRepository{}
-	service := NewReportService(nil, mockRepo, nil)
+	service := NewReportService(nil, mockRepo, nil, nil, nil, nil, nil, nil)
 
 	// Setup mock data
 	mockRepo.mockList = func(ctx context.Context, query *repository.ContractQuery) ([]models.Contract, int64, error) {
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidReportSubscription is returned when a subscription fails validation
+var ErrInvalidReportSubscription = errors.New("suscripción de reporte inválida")
+
+// reportSubscriptionRunsLimit is the number of recent runs listed per subscription
+const reportSubscriptionRunsLimit = 50
+
+// Report periods: a subscription's "period" param sets start_date and end_date relative to each run
+const (
+	ReportPeriodPreviousDay   = "previous_day"
+	ReportPeriodPreviousWeek  = "previous_week"
+	ReportPeriodPreviousMonth = "previous_month"
+	ReportPeriodMonthToDate   = "month_to_date"
+)
+
+// ReportSubscriptionRequest creates or replaces a report subscription. Params are those of the
+// report (see SubmitReportJobRequest) plus an optional period.
+type ReportSubscriptionRequest struct {
+	Name       string            `json:"name"`
+	ReportType string            `json:"report_type"`
+	Params     map[string]string `json:"params"`
+	Recipients []string          `json:"recipients"`
+	Schedule   string            `json:"schedule"`
+	Active     *bool             `json:"active"`
+}
+
+// reportMailer delivers the output of a subscription; EmailService in production
+type reportMailer interface {
+	SendScheduledReport(ctx context.Context, recipients []string, report ScheduledReport) error
+}
+
+// ReportSubscriptionService emails reports to their subscribers on a schedule, e.g. the overdue
+// payments CSV every Monday. Subscriptions are run by the worker scheduler and every run is kept
+// in the subscription's history; failures are notified to the admins.
+type ReportSubscriptionService struct {
+	repo            repository.ReportSubscriptionRepository
+	reportSvc       *ReportService
+	mailer          reportMailer
+	notificationSvc *NotificationService
+}
+
+func NewReportSubscriptionService(
+	repo repository.ReportSubscriptionRepository,
+	reportSvc *ReportService,
+	emailSvc *EmailService,
+	notificationSvc *NotificationService,
+) *ReportSubscriptionService {
+	return &ReportSubscriptionService{
+		repo:            repo,
+		reportSvc:       reportSvc,
+		mailer:          emailSvc,
+		notificationSvc: notificationSvc,
+	}
+}
+
+// List returns every subscription
+func (s *ReportSubscriptionService) List(ctx context.Context) ([]models.ReportSubscription, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *ReportSubscriptionService) Get(ctx context.Context, id uint) (*models.ReportSubscription, error) {
+	sub, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Create validates and stores a subscription, scheduling its first run
+func (s *ReportSubscriptionService) Create(ctx context.Context, req ReportSubscriptionRequest, actorID uint) (*models.ReportSubscription, error) {
+	sub := &models.ReportSubscription{Active: true, CreatedByID: actorID}
+	if err := applyReportSubscription(sub, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to create report subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// Update replaces a subscription and reschedules its next run
+func (s *ReportSubscriptionService) Update(ctx context.Context, id uint, req ReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyReportSubscription(sub, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to update report subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription along with its run history
+func (s *ReportSubscriptionService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Runs returns the most recent runs of a subscription
+func (s *ReportSubscriptionService) Runs(ctx context.Context, id uint) ([]models.ReportSubscriptionRun, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRuns(ctx, id, reportSubscriptionRunsLimit)
+}
+
+// RunNow delivers a subscription immediately without changing its schedule
+func (s *ReportSubscriptionService) RunNow(ctx context.Context, id uint) (*models.ReportSubscriptionRun, error) {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ctx, sub, time.Now(), true), nil
+}
+
+// RunDue delivers the subscriptions whose next run has arrived. Each one is claimed by moving its
+// next run forward first, so a slow run is not picked again; runs missed while the server was down
+// are not replayed, the subscription just runs once and resumes its schedule.
+func (s *ReportSubscriptionService) RunDue(ctx context.Context) error {
+	now := time.Now()
+	due, err := s.repo.FindDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		sub := &due[i]
+		schedule, err := parseReportSchedule(sub.Schedule)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ReportSubscriptionService] Invalid schedule of subscription %d: %v", sub.ID, err))
+			continue
+		}
+		claimed, err := s.repo.Claim(ctx, sub.ID, *sub.NextRunAt, schedule.Next(now))
+		if err != nil {
+			logger.Error(fmt.Sprintf("[ReportSubscriptionService] Failed to claim subscription %d: %v", sub.ID, err))
+			continue
+		}
+		if claimed {
+			s.deliver(ctx, sub, now, false)
+		}
+	}
+	return nil
+}
+
+// deliver generates a subscription's report, emails it and records the run. Failures are
+// recorded on the run and notified to the admins.
+func (s *ReportSubscriptionService) deliver(ctx context.Context, sub *models.ReportSubscription, at time.Time, manual bool) *models.ReportSubscriptionRun {
+	run := &models.ReportSubscriptionRun{
+		SubscriptionID: sub.ID,
+		Recipients:     sub.Recipients,
+		Manual:         manual,
+		StartedAt:      at,
+	}
+	err := s.send(ctx, sub, at, run)
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = models.ReportRunSent
+	if err != nil {
+		message := err.Error()
+		run.Status, run.Error = models.ReportRunFailed, &message
+	}
+	if recordErr := s.repo.RecordRun(ctx, run); recordErr != nil {
+		logger.Error(fmt.Sprintf("[ReportSubscriptionService] Failed to record run of subscription %d: %v", sub.ID, recordErr))
+	}
+
+	if err != nil {
+		logger.Error(fmt.Sprintf("[ReportSubscriptionService] Subscription %d failed: %v", sub.ID, err))
+		s.notifyFailure(ctx, sub, err)
+		return run
+	}
+	logger.Info(fmt.Sprintf("[ReportSubscriptionService] Sent %s of subscription %d to %d recipient(s)", run.Filename, sub.ID, len(sub.Recipients)))
+	return run
+}
+
+func (s *ReportSubscriptionService) send(ctx context.Context, sub *models.ReportSubscription, at time.Time, run *models.ReportSubscriptionRun) error {
+	params, period, err := resolveReportPeriod(sub.Params, at)
+	if err != nil {
+		return err
+	}
+	kind, err := validateReportParams(sub.ReportType, params)
+	if err != nil {
+		return err
+	}
+
+	genCtx, cancel := context.WithTimeout(ctx, reportJobTimeout)
+	defer cancel()
+	// Subscriptions are managed by admins, so their reports aren't scoped to a seller
+	buf, err := kind.generate(genCtx, s.reportSvc, params, DocumentActor{UserID: sub.CreatedByID, Role: models.RoleAdmin})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	run.Filename, run.Size = kind.filename(params), int64(buf.Len())
+	return s.mailer.SendScheduledReport(ctx, sub.Recipients, ScheduledReport{
+		Name:        sub.Name,
+		ReportType:  sub.ReportType,
+		Schedule:    sub.Schedule,
+		Period:      period,
+		Filename:    run.Filename,
+		ContentType: kind.contentType,
+		Content:     buf.Bytes(),
+		GeneratedAt: at,
+	})
+}
+
+func (s *ReportSubscriptionService) notifyFailure(ctx context.Context, sub *models.ReportSubscription, cause error) {
+	if s.notificationSvc == nil {
+		return
+	}
+	message := fmt.Sprintf("No se pudo enviar el reporte programado %q (#%d): %v", sub.Name, sub.ID, cause)
+	if err := s.notificationSvc.NotifyAdmins(ctx, "Reporte programado fallido", message, models.NotificationTypeReportSubscription); err != nil {
+		logger.Error(fmt.Sprintf("[ReportSubscriptionService] Failed to notify failure of subscription %d: %v", sub.ID, err))
+	}
+}
+
+// applyReportSubscription validates a request and copies it onto the subscription
+func applyReportSubscription(sub *models.ReportSubscription, req ReportSubscriptionRequest, now time.Time) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrInvalidReportSubscription)
+	}
+	if req.Params == nil {
+		req.Params = map[string]string{}
+	}
+	params, _, err := resolveReportPeriod(req.Params, now)
+	if err != nil {
+		return err
+	}
+	if _, err := validateReportParams(req.ReportType, params); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReportSubscription, err)
+	}
+
+	recipients, err := normalizeRecipients(req.Recipients)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := parseReportSchedule(req.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReportSubscription, err)
+	}
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return fmt.Errorf("%w: la programación nunca se cumple", ErrInvalidReportSubscription)
+	}
+
+	sub.Name = name
+	sub.ReportType = req.ReportType
+	sub.Params = req.Params
+	sub.Recipients = recipients
+	sub.Schedule = strings.Join(strings.Fields(req.Schedule), " ")
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+	sub.NextRunAt = &next
+	return nil
+}
+
+// normalizeRecipients validates the email addresses, dropping duplicates
+func normalizeRecipients(recipients []string) ([]string, error) {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(strings.TrimSpace(recipient))
+		if err != nil {
+			return nil, fmt.Errorf("%w: correo inválido %q", ErrInvalidReportSubscription, recipient)
+		}
+		email := strings.ToLower(address.Address)
+		if !seen[email] {
+			seen[email] = true
+			normalized = append(normalized, email)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: se requiere al menos un destinatario", ErrInvalidReportSubscription)
+	}
+	return normalized, nil
+}
+
+// resolveReportPeriod replaces the "period" param by the start_date and end_date it stands for at
+// the given time, returning a description of the period
+func resolveReportPeriod(params map[string]string, at time.Time) (map[string]string, string, error) {
+	resolved := make(map[string]string, len(params))
+	for key, value := range params {
+		resolved[key] = value
+	}
+	period, ok := resolved["period"]
+	if !ok {
+		return resolved, "", nil
+	}
+	delete(resolved, "period")
+
+	today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	var start, end time.Time
+	switch period {
+	case ReportPeriodPreviousDay:
+		start = today.AddDate(0, 0, -1)
+		end = start
+	case ReportPeriodPreviousWeek:
+		// Monday to Sunday of the week before
+		start = today.AddDate(0, 0, -(int(today.Weekday())+6)%7-7)
+		end = start.AddDate(0, 0, 6)
+	case ReportPeriodPreviousMonth:
+		start = time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, today.Location())
+		end = start.AddDate(0, 1, -1)
+	case ReportPeriodMonthToDate:
+		start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		end = today
+	default:
+		return nil, "", fmt.Errorf("%w: período desconocido %q", ErrInvalidReportSubscription, period)
+	}
+	resolved["start_date"] = start.Format("2006-01-02")
+	resolved["end_date"] = end.Format("2006-01-02")
+	return resolved, start.Format("02/01/2006") + " - " + end.Format("02/01/2006"), nil
+}
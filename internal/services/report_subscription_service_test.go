@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockReportSubscriptionRepository struct {
+	subs []*models.ReportSubscription
+	runs []models.ReportSubscriptionRun
+}
+
+func (m *mockReportSubscriptionRepository) Create(ctx context.Context, sub *models.ReportSubscription) error {
+	sub.ID = uint(len(m.subs) + 1)
+	m.subs = append(m.subs, sub)
+	return nil
+}
+
+func (m *mockReportSubscriptionRepository) FindByID(ctx context.Context, id uint) (*models.ReportSubscription, error) {
+	for _, sub := range m.subs {
+		if sub.ID == id {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockReportSubscriptionRepository) List(ctx context.Context) ([]models.ReportSubscription, error) {
+	var subs []models.ReportSubscription
+	for _, sub := range m.subs {
+		subs = append(subs, *sub)
+	}
+	return subs, nil
+}
+
+func (m *mockReportSubscriptionRepository) Update(ctx context.Context, sub *models.ReportSubscription) error {
+	copied := *sub
+	*m.subs[sub.ID-1] = copied
+	return nil
+}
+
+func (m *mockReportSubscriptionRepository) Delete(ctx context.Context, id uint) error {
+	m.subs[id-1].Active = false
+	return nil
+}
+
+func (m *mockReportSubscriptionRepository) FindDue(ctx context.Context, now time.Time) ([]models.ReportSubscription, error) {
+	var subs []models.ReportSubscription
+	for _, sub := range m.subs {
+		if sub.Active && sub.NextRunAt != nil && !sub.NextRunAt.After(now) {
+			subs = append(subs, *sub)
+		}
+	}
+	return subs, nil
+}
+
+func (m *mockReportSubscriptionRepository) Claim(ctx context.Context, id uint, dueAt, nextRunAt time.Time) (bool, error) {
+	sub := m.subs[id-1]
+	if sub.NextRunAt == nil || !sub.NextRunAt.Equal(dueAt) {
+		return false, nil
+	}
+	sub.NextRunAt = &nextRunAt
+	return true, nil
+}
+
+func (m *mockReportSubscriptionRepository) RecordRun(ctx context.Context, run *models.ReportSubscriptionRun) error {
+	run.ID = uint(len(m.runs) + 1)
+	m.runs = append(m.runs, *run)
+	sub := m.subs[run.SubscriptionID-1]
+	sub.LastRunAt, sub.LastStatus = &run.StartedAt, &run.Status
+	return nil
+}
+
+func (m *mockReportSubscriptionRepository) ListRuns(ctx context.Context, subscriptionID uint, limit int) ([]models.ReportSubscriptionRun, error) {
+	var runs []models.ReportSubscriptionRun
+	for _, run := range m.runs {
+		if run.SubscriptionID == subscriptionID {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+type sentReport struct {
+	recipients []string
+	report     ScheduledReport
+}
+
+type mockReportMailer struct {
+	sent []sentReport
+	err  error
+}
+
+func (m *mockReportMailer) SendScheduledReport(ctx context.Context, recipients []string, report ScheduledReport) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, sentReport{recipients: recipients, report: report})
+	return nil
+}
+
+func TestReportScheduleNext(t *testing.T) {
+	from := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 7 * * 1", time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)},
+		{"0 7 1 * *", time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 14, 9, 45, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)},
+		{"0 8-10 * * 1-5", time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 6 1,15 * 5", time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)}, // day 15 or a Friday
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := parseReportSchedule(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, schedule.Next(from), tt.expr)
+	}
+
+	for _, expr := range []string{"", "0 7 * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := parseReportSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+
+	never, err := parseReportSchedule("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+}
+
+func TestResolveReportPeriod(t *testing.T) {
+	monday := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+
+	params, period, err := resolveReportPeriod(map[string]string{"period": ReportPeriodPreviousWeek}, monday)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"start_date": "2026-10-12", "end_date": "2026-10-18"}, params)
+	assert.Equal(t, "12/10/2026 - 18/10/2026", period)
+
+	params, _, err = resolveReportPeriod(map[string]string{"period": ReportPeriodPreviousMonth}, time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02-01", params["start_date"])
+	assert.Equal(t, "2026-02-28", params["end_date"])
+
+	_, _, err = resolveReportPeriod(map[string]string{"period": "last_decade"}, monday)
+	assert.ErrorIs(t, err, ErrInvalidReportSubscription)
+}
+
+func TestCreateReportSubscriptionValidation(t *testing.T) {
+	svc := NewReportSubscriptionService(&mockReportSubscriptionRepository{}, nil, nil, nil)
+	valid := ReportSubscriptionRequest{
+		Name:       "Pagos vencidos",
+		ReportType: "overdue_payments_csv",
+		Recipients: []string{"Gerencia@Fintera.hn", "gerencia@fintera.hn", "finanzas@fintera.hn"},
+		Schedule:   "0  7 * * 1",
+	}
+
+	sub, err := svc.Create(context.Background(), valid, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gerencia@fintera.hn", "finanzas@fintera.hn"}, sub.Recipients)
+	assert.Equal(t, "0 7 * * 1", sub.Schedule)
+	assert.True(t, sub.Active)
+	require.NotNil(t, sub.NextRunAt)
+	assert.Equal(t, time.Monday, sub.NextRunAt.Weekday())
+
+	invalid := []func(r *ReportSubscriptionRequest){
+		func(r *ReportSubscriptionRequest) { r.Name = " " },
+		func(r *ReportSubscriptionRequest) { r.ReportType = "everything_csv" },
+		func(r *ReportSubscriptionRequest) { r.Recipients = nil },
+		func(r *ReportSubscriptionRequest) { r.Recipients = []string{"gerencia"} },
+		func(r *ReportSubscriptionRequest) { r.Schedule = "every monday" },
+		func(r *ReportSubscriptionRequest) { r.Params = map[string]string{"period": "forever"} },
+		func(r *ReportSubscriptionRequest) { r.ReportType, r.Params = "user_balance_pdf", nil },
+	}
+	for i, mutate := range invalid {
+		req := valid
+		mutate(&req)
+		_, err := svc.Create(context.Background(), req, 1)
+		assert.ErrorIs(t, err, ErrInvalidReportSubscription, "case %d", i)
+	}
+}
+
+func TestReportSubscriptionRunDue(t *testing.T) {
+	logger.Setup("test")
+	ctx := context.Background()
+	repo := &mockReportSubscriptionRepository{}
+	mailer := &mockReportMailer{}
+	paid := 1500.0
+	payments := &mockPaymentRepository{mockList: func(ctx context.Context, query *repository.ListQuery) ([]models.Payment, int64, error) {
+		return []models.Payment{{ID: 1, ContractID: 7, PaymentType: models.PaymentTypeInstallment, PaidAmount: &paid}}, 1, nil
+	}}
+	svc := NewReportSubscriptionService(repo, NewReportService(payments, nil, nil, nil, nil, nil, nil, nil), nil, nil)
+	svc.mailer = mailer
+
+	sub, err := svc.Create(ctx, ReportSubscriptionRequest{
+		Name:       "Ingresos",
+		ReportType: "total_revenue_csv",
+		Recipients: []string{"finanzas@fintera.hn"},
+		Schedule:   "@daily",
+	}, 1)
+	require.NoError(t, err)
+
+	// Not due yet
+	require.NoError(t, svc.RunDue(ctx))
+	assert.Empty(t, mailer.sent)
+
+	due := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	repo.subs[0].NextRunAt = &due
+	require.NoError(t, svc.RunDue(ctx))
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, []string{"finanzas@fintera.hn"}, mailer.sent[0].recipients)
+	assert.Equal(t, "revenue.csv", mailer.sent[0].report.Filename)
+	assert.Equal(t, contentTypeCSV, mailer.sent[0].report.ContentType)
+	assert.True(t, strings.HasPrefix(string(mailer.sent[0].report.Content), "Pago ID,"))
+	assert.True(t, repo.subs[0].NextRunAt.After(time.Now()))
+	assert.Equal(t, models.ReportRunSent, *repo.subs[0].LastStatus)
+
+	// Already claimed: running again sends nothing
+	require.NoError(t, svc.RunDue(ctx))
+	assert.Len(t, mailer.sent, 1)
+
+	// Delivery failures are kept in the run history
+	mailer.err = errors.New("resend unavailable")
+	run, err := svc.RunNow(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportRunFailed, run.Status)
+	assert.True(t, run.Manual)
+	assert.Contains(t, *run.Error, "resend unavailable")
+
+	runs, err := svc.Runs(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, models.ReportRunSent, runs[0].Status)
+	assert.Equal(t, int64(len(mailer.sent[0].report.Content)), runs[0].Size)
+	assert.Equal(t, models.ReportRunFailed, *repo.subs[0].LastStatus)
+
+	_, err = svc.Runs(ctx, 99)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
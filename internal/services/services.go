@@ -10,20 +10,36 @@ import (
 
 // Services holds all service instances
 type Services struct {
-	Auth         *AuthService
-	User         *UserService
-	Project      *ProjectService
-	Lot          *LotService
-	Contract     *ContractService
-	Payment      *PaymentService
-	Notification *NotificationService
-	Report       *ReportService
-	Audit        *AuditService
-	CreditScore  *CreditScoreService
-	Email        *EmailService
-	Analytics    *AnalyticsService
-	Export       *ExportService
-	Job          *JobService
+	Auth               *AuthService
+	User               *UserService
+	Project            *ProjectService
+	Lot                *LotService
+	Import             *ImportService
+	Contract           *ContractService
+	Payment            *PaymentService
+	Notification       *NotificationService
+	Report             *ReportService
+	Audit              *AuditService
+	CreditScore        *CreditScoreService
+	Email              *EmailService
+	Analytics          *AnalyticsService
+	Export             *ExportService
+	Job                *JobService
+	Promotion          *PromotionService
+	PriceList          *PriceListService
+	LotHold            *LotHoldService
+	Waitlist           *WaitlistService
+	Section            *ProjectSectionService
+	ContractParty      *ContractPartyService
+	ApprovalChain      *ApprovalChainService
+	ContractSLA        *ContractSLAService
+	ContractDocument   *ContractDocumentService
+	KYC                *KYCService
+	ContractSignature  *ContractSignatureService
+	Receipt            *ReceiptService
+	Fiscal             *FiscalService
+	ReportJob          *ReportJobService
+	ReportSubscription *ReportSubscriptionService
 }
 
 // NewServices creates all service instances
@@ -37,21 +53,46 @@ func NewServices(repos *repository.Repositories, worker *jobs.Worker, storage *s
 
 	analyticsSvc := NewAnalyticsService(repos.Analytics, repos.Project, notificationSvc, repos.User)
 	jobSvc := NewJobService(worker)
+	promotionSvc := NewPromotionService(repos.Promotion, auditSvc)
+	priceListSvc := NewPriceListService(repos.PriceList, repos.Lot, repos.Project, auditSvc)
+	lotStatusSvc := NewLotStatusService(repos.Lot)
+	lotHoldSvc := NewLotHoldService(repos.Lot, lotStatusSvc, notificationSvc, auditSvc)
+	approvalChainSvc := NewApprovalChainService(repos.ApprovalChain, repos.User, notificationSvc, auditSvc)
+	kycSvc := NewKYCService(repos.KYC, repos.ContractDocument, repos.Contract, auditSvc)
+	reportSvc := NewReportService(repos.Payment, repos.Contract, repos.User, repos.Cession, repos.LotChange, repos.IssuedDocument, repos.Receivables, cfg)
+	receiptSvc := NewReceiptService(repos.PaymentReceipt, repos.Payment, reportSvc, auditSvc, storage, cfg)
+	fiscalSvc := NewFiscalService(repos.Fiscal, repos.Payment, repos.Contract, reportSvc, notificationSvc, auditSvc, storage, cfg)
 
 	return &Services{
-		Auth:         NewAuthService(repos.User, repos.RefreshToken, cfg),
-		User:         NewUserService(repos.User, repos.Contract, worker, emailSvc, auditSvc, imageSvc),
-		Project:      NewProjectService(repos.Project, repos.Lot, auditSvc),
-		Lot:          NewLotService(repos.Lot, repos.Project, auditSvc),
-		Contract:     NewContractService(repos.Contract, repos.Lot, repos.User, repos.Payment, repos.Ledger, notificationSvc, emailSvc, auditSvc, worker),
-		Payment:      NewPaymentService(repos.Payment, repos.Contract, repos.Lot, repos.Ledger, notificationSvc, emailSvc, auditSvc, storage, worker),
-		Notification: notificationSvc,
-		Report:       NewReportService(repos.Payment, repos.Contract, repos.User),
-		Audit:        auditSvc, // Assign AuditService
-		CreditScore:  NewCreditScoreService(repos.User, repos.Contract, repos.Payment),
-		Email:        emailSvc,
-		Analytics:    analyticsSvc,
-		Export:       NewExportService(analyticsSvc), // AnalyticsSvc passed to ExportSvc
-		Job:          jobSvc,
+		Auth:               NewAuthService(repos.User, repos.RefreshToken, cfg),
+		User:               NewUserService(repos.User, repos.Contract, worker, emailSvc, auditSvc, imageSvc),
+		Project:            NewProjectService(repos.Project, repos.Lot, auditSvc, priceListSvc),
+		Lot:                NewLotService(repos.Lot, repos.Project, repos.Section, auditSvc, priceListSvc),
+		Import:             NewImportService(repos.Import, repos.Project, repos.Lot, auditSvc),
+		Contract:           NewContractService(repos.Contract, repos.Lot, repos.User, repos.Payment, repos.Ledger, notificationSvc, emailSvc, auditSvc, promotionSvc, priceListSvc, lotStatusSvc, lotHoldSvc, repos.Cession, repos.LotChange, approvalChainSvc, kycSvc, worker),
+		Payment:            NewPaymentService(repos.Payment, repos.Contract, repos.Lot, repos.Ledger, notificationSvc, emailSvc, auditSvc, lotStatusSvc, receiptSvc, fiscalSvc, storage, worker),
+		Notification:       notificationSvc,
+		Report:             reportSvc,
+		Audit:              auditSvc, // Assign AuditService
+		CreditScore:        NewCreditScoreService(repos.User, repos.Contract, repos.Payment, repos.ContractParty),
+		Email:              emailSvc,
+		Analytics:          analyticsSvc,
+		Export:             NewExportService(analyticsSvc), // AnalyticsSvc passed to ExportSvc
+		Job:                jobSvc,
+		Promotion:          promotionSvc,
+		PriceList:          priceListSvc,
+		LotHold:            lotHoldSvc,
+		Waitlist:           NewWaitlistService(repos.Waitlist, repos.Project, repos.Lot, repos.User, lotStatusSvc, lotHoldSvc, notificationSvc, emailSvc, auditSvc, worker),
+		Section:            NewProjectSectionService(repos.Section, repos.Project, repos.Lot, priceListSvc, auditSvc),
+		ContractParty:      NewContractPartyService(repos.ContractParty, repos.Contract, repos.User, auditSvc),
+		ApprovalChain:      approvalChainSvc,
+		ContractSLA:        NewContractSLAService(repos.ContractSLA, repos.User, notificationSvc, emailSvc, auditSvc),
+		ContractDocument:   NewContractDocumentService(repos.ContractDocument, repos.Contract, storage, auditSvc),
+		KYC:                kycSvc,
+		ContractSignature:  NewContractSignatureService(repos.ContractSignature, repos.Contract, repos.ContractDocument, repos.User, reportSvc, emailSvc, auditSvc, storage),
+		Receipt:            receiptSvc,
+		Fiscal:             fiscalSvc,
+		ReportJob:          NewReportJobService(repos.ReportJob, reportSvc, notificationSvc, storage, worker, cfg),
+		ReportSubscription: NewReportSubscriptionService(repos.ReportSubscription, reportSvc, emailSvc, notificationSvc),
 	}
 }
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/jobs"
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/sjperalta/fintera-api/pkg/logger"
+)
+
+// ErrWaitlistLotAvailable is returned when registering a waitlist entry on a lot that can be held directly
+var ErrWaitlistLotAvailable = errors.New("el lote está disponible; apártalo directamente")
+
+// WaitlistEntryRequest registers a customer on a project's waitlist
+type WaitlistEntryRequest struct {
+	LotID          *uint    `json:"lot_id"`    // specific lot; empty = any lot of the project
+	MaxPrice       *float64 `json:"max_price"` // for any-lot entries
+	CustomerUserID *uint    `json:"customer_user_id"`
+	CustomerName   string   `json:"customer_name"`
+	CustomerEmail  string   `json:"customer_email"`
+	CustomerPhone  string   `json:"customer_phone"`
+	Note           string   `json:"note"`
+}
+
+// WaitlistService keeps customers waiting for taken lots. It listens to lot transitions: when a
+// lot becomes available the first matching entry gets a priority hold for its seller and is
+// notified; if that hold lapses the lot moves on to the next entry.
+type WaitlistService struct {
+	repo            repository.WaitlistRepository
+	projectRepo     repository.ProjectRepository
+	lotRepo         repository.LotRepository
+	userRepo        repository.UserRepository
+	lotHoldSvc      *LotHoldService
+	notificationSvc *NotificationService
+	emailSvc        *EmailService
+	auditSvc        *AuditService
+	worker          *jobs.Worker
+}
+
+func NewWaitlistService(
+	repo repository.WaitlistRepository,
+	projectRepo repository.ProjectRepository,
+	lotRepo repository.LotRepository,
+	userRepo repository.UserRepository,
+	lotStatusSvc *LotStatusService,
+	lotHoldSvc *LotHoldService,
+	notificationSvc *NotificationService,
+	emailSvc *EmailService,
+	auditSvc *AuditService,
+	worker *jobs.Worker,
+) *WaitlistService {
+	s := &WaitlistService{
+		repo:            repo,
+		projectRepo:     projectRepo,
+		lotRepo:         lotRepo,
+		userRepo:        userRepo,
+		lotHoldSvc:      lotHoldSvc,
+		notificationSvc: notificationSvc,
+		emailSvc:        emailSvc,
+		auditSvc:        auditSvc,
+		worker:          worker,
+	}
+	lotStatusSvc.OnTransition(s.handleLotTransition)
+	return s
+}
+
+// Register adds a customer to the waitlist of a taken lot or of any lot of the project
+func (s *WaitlistService) Register(ctx context.Context, projectID uint, req WaitlistEntryRequest, sellerID uint) (*models.LotWaitlistEntry, error) {
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, ErrNotFound
+	}
+
+	entry := &models.LotWaitlistEntry{
+		ProjectID:    projectID,
+		SellerID:     sellerID,
+		CustomerName: strings.TrimSpace(req.CustomerName),
+		Status:       models.WaitlistStatusWaiting,
+	}
+
+	if req.LotID != nil {
+		lot, err := s.lotRepo.FindByID(ctx, *req.LotID)
+		if err != nil || lot.ProjectID != projectID {
+			return nil, errors.New("el lote no pertenece al proyecto")
+		}
+		if lot.IsAvailable() {
+			return nil, ErrWaitlistLotAvailable
+		}
+		entry.LotID = &lot.ID
+	} else if req.MaxPrice != nil {
+		if *req.MaxPrice <= 0 {
+			return nil, errors.New("el precio máximo debe ser mayor a cero")
+		}
+		entry.MaxPrice = req.MaxPrice
+	}
+
+	if req.CustomerUserID != nil {
+		customer, err := s.userRepo.FindByID(ctx, *req.CustomerUserID)
+		if err != nil {
+			return nil, errors.New("cliente no encontrado")
+		}
+		entry.CustomerUserID = &customer.ID
+		if entry.CustomerName == "" {
+			entry.CustomerName = customer.FullName
+		}
+		if req.CustomerEmail == "" {
+			req.CustomerEmail = customer.Email
+		}
+		if req.CustomerPhone == "" {
+			req.CustomerPhone = customer.Phone
+		}
+	}
+	if entry.CustomerName == "" {
+		return nil, errors.New("el nombre del cliente es requerido")
+	}
+	entry.CustomerEmail = optionalString(strings.TrimSpace(req.CustomerEmail))
+	entry.CustomerPhone = optionalString(strings.TrimSpace(req.CustomerPhone))
+	entry.Note = optionalString(strings.TrimSpace(req.Note))
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to create waitlist entry: %w", err)
+	}
+
+	target := "cualquier lote"
+	if entry.LotID != nil {
+		target = fmt.Sprintf("el lote %d", *entry.LotID)
+	} else if entry.MaxPrice != nil {
+		target = fmt.Sprintf("cualquier lote hasta %.2f", *entry.MaxPrice)
+	}
+	s.audit(ctx, sellerID, "CREATE", entry.ID, fmt.Sprintf("Cliente %s agregado a la lista de espera del proyecto %d para %s", entry.CustomerName, projectID, target))
+
+	return s.repo.FindByID(ctx, entry.ID)
+}
+
+// List returns the waitlist of a project in queue order; sellers only see their own entries
+func (s *WaitlistService) List(ctx context.Context, projectID uint, sellerID *uint, status string) ([]models.LotWaitlistEntry, error) {
+	return s.repo.List(ctx, projectID, sellerID, status)
+}
+
+// Cancel removes a waiting entry from the queue. Only its seller or an admin may cancel it.
+func (s *WaitlistService) Cancel(ctx context.Context, id, actorID uint, isAdmin bool) (*models.LotWaitlistEntry, error) {
+	entry, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if entry.SellerID != actorID && !isAdmin {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := s.repo.UpdateStatus(ctx, entry.ID, models.WaitlistStatusWaiting, models.WaitlistStatusCancelled, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel waitlist entry: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: solo se pueden cancelar entradas en espera", ErrInvalidState)
+	}
+	entry.Status = models.WaitlistStatusCancelled
+
+	s.audit(ctx, actorID, "CANCEL", entry.ID, fmt.Sprintf("Cliente %s retirado de la lista de espera", entry.CustomerName))
+	return entry, nil
+}
+
+// handleLotTransition closes the offer of the entry holding the lot (fulfilled when the hold turns
+// into a contract, lapsed when it expires or is released) and offers lots that became available
+func (s *WaitlistService) handleLotTransition(ctx context.Context, lot *models.Lot, from, event string, ec LotEventContext) {
+	switch event {
+	case statemachine.LotEventConvertHold:
+		s.closeOffers(ctx, lot.ID, models.WaitlistStatusFulfilled, ec.ContractID)
+	case statemachine.LotEventReleaseHold:
+		s.closeOffers(ctx, lot.ID, models.WaitlistStatusLapsed, nil)
+	}
+
+	if lot.IsAvailable() {
+		s.offer(ctx, lot)
+	}
+}
+
+func (s *WaitlistService) closeOffers(ctx context.Context, lotID uint, status string, contractID *uint) {
+	entries, err := s.repo.FindOfferedForLot(ctx, lotID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[WaitlistService] Failed to load offers for lot %d: %v", lotID, err))
+		return
+	}
+	for _, entry := range entries {
+		fields := map[string]interface{}{}
+		if contractID != nil {
+			fields["contract_id"] = *contractID
+		}
+		if _, err := s.repo.UpdateStatus(ctx, entry.ID, models.WaitlistStatusNotified, status, fields); err != nil {
+			logger.Error(fmt.Sprintf("[WaitlistService] Failed to close offer of entry %d: %v", entry.ID, err))
+		}
+	}
+}
+
+// offer gives the lot to the first waiting entry: the entry is claimed (compare-and-set), a
+// priority hold is placed for its seller and the seller and customer are notified
+func (s *WaitlistService) offer(ctx context.Context, lot *models.Lot) {
+	entries, err := s.repo.FindWaitingForLot(ctx, lot)
+	if err != nil {
+		logger.Error(fmt.Sprintf("[WaitlistService] Failed to load waitlist for lot %d: %v", lot.ID, err))
+		return
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		now := time.Now()
+		claimed, err := s.repo.UpdateStatus(ctx, entry.ID, models.WaitlistStatusWaiting, models.WaitlistStatusNotified,
+			map[string]interface{}{"offered_lot_id": lot.ID, "notified_at": now})
+		if err != nil {
+			logger.Error(fmt.Sprintf("[WaitlistService] Failed to claim entry %d: %v", entry.ID, err))
+			continue
+		}
+		if !claimed {
+			continue // offered another lot meanwhile
+		}
+
+		note := fmt.Sprintf("Prioridad de lista de espera: %s", entry.CustomerName)
+		hold, err := s.lotHoldSvc.PlacePriority(ctx, lot.ProjectID, lot.ID, entry.SellerID, lot.Project.WaitlistHoldDuration(), note)
+		if err != nil {
+			// The lot was taken meanwhile: put the entry back in the queue
+			if _, err := s.repo.UpdateStatus(ctx, entry.ID, models.WaitlistStatusNotified, models.WaitlistStatusWaiting,
+				map[string]interface{}{"offered_lot_id": nil, "notified_at": nil}); err != nil {
+				logger.Error(fmt.Sprintf("[WaitlistService] Failed to requeue entry %d: %v", entry.ID, err))
+			}
+			return
+		}
+		if _, err := s.repo.UpdateStatus(ctx, entry.ID, models.WaitlistStatusNotified, models.WaitlistStatusNotified,
+			map[string]interface{}{"offered_hold_id": hold.ID, "offer_expires_at": hold.ExpiresAt}); err != nil {
+			logger.Error(fmt.Sprintf("[WaitlistService] Failed to record offer of entry %d: %v", entry.ID, err))
+		}
+		entry.Status = models.WaitlistStatusNotified
+		entry.OfferedLotID = &lot.ID
+		entry.OfferedHoldID = &hold.ID
+		entry.NotifiedAt = &now
+		entry.OfferExpiresAt = &hold.ExpiresAt
+
+		s.notifyOffer(ctx, entry, lot, hold.ExpiresAt)
+		return
+	}
+}
+
+func (s *WaitlistService) notifyOffer(ctx context.Context, entry *models.LotWaitlistEntry, lot *models.Lot, holdUntil time.Time) {
+	msg := fmt.Sprintf("El lote %s del proyecto %s está disponible para %s (lista de espera). Está apartado a tu nombre hasta %s.",
+		lot.Name, lot.Project.Name, entry.CustomerName, holdUntil.Format("02/01/2006 15:04"))
+	if s.notificationSvc != nil {
+		if err := s.notificationSvc.NotifyUser(ctx, entry.SellerID, "Lote disponible en lista de espera", msg, models.NotificationTypeWaitlistLotAvailable); err != nil {
+			logger.Error(fmt.Sprintf("[WaitlistService] Failed to notify seller %d: %v", entry.SellerID, err))
+		}
+		if entry.CustomerUserID != nil {
+			customerMsg := fmt.Sprintf("El lote %s del proyecto %s que esperabas está disponible. Tu asesor lo tiene apartado para ti hasta %s.",
+				lot.Name, lot.Project.Name, holdUntil.Format("02/01/2006 15:04"))
+			if err := s.notificationSvc.NotifyUser(ctx, *entry.CustomerUserID, "Lote disponible", customerMsg, models.NotificationTypeWaitlistLotAvailable); err != nil {
+				logger.Error(fmt.Sprintf("[WaitlistService] Failed to notify customer %d: %v", *entry.CustomerUserID, err))
+			}
+		}
+	}
+
+	if s.emailSvc == nil || s.worker == nil {
+		return
+	}
+	recipients := []*models.User{}
+	if seller, err := s.userRepo.FindByID(ctx, entry.SellerID); err == nil {
+		recipients = append(recipients, seller)
+	}
+	if entry.CustomerEmail != nil && *entry.CustomerEmail != "" {
+		recipients = append(recipients, &models.User{FullName: entry.CustomerName, Email: *entry.CustomerEmail})
+	}
+	entryForEmail, lotForEmail := *entry, *lot
+	for _, recipient := range recipients {
+		recipient := recipient
+		s.worker.EnqueueAsync(func(ctx context.Context) error {
+			return s.emailSvc.SendWaitlistLotAvailable(ctx, recipient, &entryForEmail, &lotForEmail, holdUntil)
+		})
+	}
+}
+
+func (s *WaitlistService) audit(ctx context.Context, userID uint, action string, entryID uint, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+	if err := s.auditSvc.Log(ctx, userID, action, "LotWaitlistEntry", entryID, details, "", ""); err != nil {
+		logger.Error(fmt.Sprintf("[WaitlistService] Failed to audit %s for entry %d: %v", action, entryID, err))
+	}
+}
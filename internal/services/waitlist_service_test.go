@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjperalta/fintera-api/internal/models"
+	"github.com/sjperalta/fintera-api/internal/repository"
+	"github.com/sjperalta/fintera-api/internal/statemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWaitlistRepository struct {
+	repository.WaitlistRepository
+	entries []models.LotWaitlistEntry
+}
+
+func (m *mockWaitlistRepository) FindWaitingForLot(ctx context.Context, lot *models.Lot) ([]models.LotWaitlistEntry, error) {
+	var out []models.LotWaitlistEntry
+	for _, e := range m.entries {
+		if e.Status == models.WaitlistStatusWaiting && e.Matches(lot) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockWaitlistRepository) FindOfferedForLot(ctx context.Context, lotID uint) ([]models.LotWaitlistEntry, error) {
+	var out []models.LotWaitlistEntry
+	for _, e := range m.entries {
+		if e.Status == models.WaitlistStatusNotified && e.OfferedLotID != nil && *e.OfferedLotID == lotID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockWaitlistRepository) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string, fields map[string]interface{}) (bool, error) {
+	e := &m.entries[id-1]
+	if e.Status != fromStatus {
+		return false, nil
+	}
+	e.Status = toStatus
+	if v, ok := fields["offered_lot_id"].(uint); ok {
+		e.OfferedLotID = &v
+	}
+	if v, ok := fields["contract_id"].(uint); ok {
+		e.ContractID = &v
+	}
+	return true, nil
+}
+
+func TestWaitlistService_OffersFreedLotInQueueOrder(t *testing.T) {
+	lotRepo := &mockLotHoldRepository{}
+	lotRepo.lot = models.Lot{ID: 1, ProjectID: 3, Status: models.LotStatusReserved, Price: 90000,
+		Project: models.Project{ID: 3, Status: models.ProjectStatusPublished, WaitlistHoldMinutes: 120}}
+	lotStatusSvc := NewLotStatusService(lotRepo)
+	lotHoldSvc := NewLotHoldService(lotRepo, lotStatusSvc, nil, nil)
+
+	lotID, cheap, enough := uint(1), 50000.0, 100000.0
+	repo := &mockWaitlistRepository{entries: []models.LotWaitlistEntry{
+		{ID: 1, ProjectID: 3, MaxPrice: &cheap, SellerID: 10, CustomerName: "Fuera de presupuesto", Status: models.WaitlistStatusWaiting},
+		{ID: 2, ProjectID: 3, LotID: &lotID, SellerID: 20, CustomerName: "Primero", Status: models.WaitlistStatusWaiting},
+		{ID: 3, ProjectID: 3, MaxPrice: &enough, SellerID: 30, CustomerName: "Segundo", Status: models.WaitlistStatusWaiting},
+	}}
+	NewWaitlistService(repo, nil, lotRepo, nil, lotStatusSvc, lotHoldSvc, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	// The contract is cancelled: the first matching entry gets a priority hold
+	_, err := lotStatusSvc.Fire(ctx, 1, statemachine.LotEventRelease, LotEventContext{})
+	require.NoError(t, err)
+	assert.Equal(t, models.LotStatusHeld, lotRepo.lot.Status)
+	assert.Equal(t, models.WaitlistStatusWaiting, repo.entries[0].Status)
+	assert.Equal(t, models.WaitlistStatusNotified, repo.entries[1].Status)
+	require.Len(t, lotRepo.holds, 1)
+	assert.Equal(t, uint(20), lotRepo.holds[0].SellerID)
+	assert.WithinDuration(t, time.Now().Add(120*time.Minute), lotRepo.holds[0].ExpiresAt, time.Minute)
+
+	// The priority window lapses: the lot moves on to the next entry
+	lotRepo.holds[0].ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, lotHoldSvc.ExpireDue(ctx))
+	assert.Equal(t, models.WaitlistStatusLapsed, repo.entries[1].Status)
+	assert.Equal(t, models.WaitlistStatusNotified, repo.entries[2].Status)
+	require.Len(t, lotRepo.holds, 2)
+	assert.Equal(t, uint(30), lotRepo.holds[1].SellerID)
+	assert.Equal(t, models.LotStatusHeld, lotRepo.lot.Status)
+
+	// The seller submits the contract: the entry is fulfilled
+	contractID := uint(99)
+	_, err = lotStatusSvc.Fire(ctx, 1, statemachine.LotEventConvertHold, LotEventContext{ContractID: &contractID})
+	require.NoError(t, err)
+	assert.Equal(t, models.WaitlistStatusFulfilled, repo.entries[2].Status)
+	assert.Equal(t, &contractID, repo.entries[2].ContractID)
+}
@@ -3,15 +3,22 @@ package statemachine
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/looplab/fsm"
 	"github.com/sjperalta/fintera-api/internal/models"
 )
 
+// ContractTransitionRecorder persists contract status transitions
+type ContractTransitionRecorder interface {
+	CreateStatusTransition(ctx context.Context, transition *models.ContractStatusTransition) error
+}
+
 // ContractFSM wraps a contract with its state machine
 type ContractFSM struct {
-	contract *models.Contract
-	fsm      *fsm.FSM
+	contract   *models.Contract
+	fsm        *fsm.FSM
+	transition *models.ContractStatusTransition
 }
 
 // NewContractFSM creates a new contract state machine
@@ -35,11 +42,15 @@ func NewContractFSM(contract *models.Contract) *ContractFSM {
 			// pending/submitted/rejected → cancelled
 			{Name: "cancel", Src: []string{models.ContractStatusPending, models.ContractStatusSubmitted, models.ContractStatusRejected}, Dst: models.ContractStatusCancelled},
 
-			// approved → closed
-			{Name: "close", Src: []string{models.ContractStatusApproved}, Dst: models.ContractStatusClosed},
+			// approved → signed (electronic signature of the promise contract only)
+			{Name: "sign", Src: []string{models.ContractStatusApproved}, Dst: models.ContractStatusSigned},
 
-			// closed → approved (reopen)
+			// approved/signed → closed
+			{Name: "close", Src: []string{models.ContractStatusApproved, models.ContractStatusSigned}, Dst: models.ContractStatusClosed},
+
+			// closed → approved/signed (reopen, back to the status it was closed from)
 			{Name: "reopen", Src: []string{models.ContractStatusClosed}, Dst: models.ContractStatusApproved},
+			{Name: "reopen_signed", Src: []string{models.ContractStatusClosed}, Dst: models.ContractStatusSigned},
 		},
 		fsm.Callbacks{},
 	)
@@ -57,7 +68,7 @@ func (c *ContractFSM) Submit(ctx context.Context) error {
 		return fmt.Errorf("failed to submit contract: %w", err)
 	}
 
-	c.contract.Status = c.fsm.Current()
+	c.setStatus("submit")
 	return nil
 }
 
@@ -71,7 +82,7 @@ func (c *ContractFSM) Approve(ctx context.Context) error {
 		return fmt.Errorf("failed to approve contract: %w", err)
 	}
 
-	c.contract.Status = c.fsm.Current()
+	c.setStatus("approve")
 	return nil
 }
 
@@ -85,7 +96,7 @@ func (c *ContractFSM) Reject(ctx context.Context) error {
 		return fmt.Errorf("failed to reject contract: %w", err)
 	}
 
-	c.contract.Status = c.fsm.Current()
+	c.setStatus("reject")
 	return nil
 }
 
@@ -99,7 +110,21 @@ func (c *ContractFSM) Cancel(ctx context.Context) error {
 		return fmt.Errorf("failed to cancel contract: %w", err)
 	}
 
-	c.contract.Status = c.fsm.Current()
+	c.setStatus("cancel")
+	return nil
+}
+
+// Sign transitions an approved contract to signed once every signer signed its promise contract
+func (c *ContractFSM) Sign(ctx context.Context) error {
+	if !c.contract.MaySign() {
+		return fmt.Errorf("contract cannot be signed in current state: %s", c.contract.Status)
+	}
+
+	if err := c.fsm.Event(ctx, "sign"); err != nil {
+		return fmt.Errorf("failed to sign contract: %w", err)
+	}
+
+	c.setStatus("sign")
 	return nil
 }
 
@@ -113,24 +138,62 @@ func (c *ContractFSM) Close(ctx context.Context) error {
 		return fmt.Errorf("failed to close contract: %w", err)
 	}
 
-	c.contract.Status = c.fsm.Current()
+	closedFrom := c.contract.Status
+	c.setStatus("close")
+	c.contract.ClosedFromStatus = &closedFrom
 	return nil
 }
 
-// Reopen transitions contract from closed back to approved
+// Reopen transitions contract from closed back to the status it was closed from, so a signed
+// contract stays signed and can't be signed again
 func (c *ContractFSM) Reopen(ctx context.Context) error {
 	if !c.contract.MayReopen() {
 		return fmt.Errorf("contract cannot be reopened in current state: %s", c.contract.Status)
 	}
 
-	if err := c.fsm.Event(ctx, "reopen"); err != nil {
+	event := "reopen"
+	if c.contract.ClosedFromStatus != nil && *c.contract.ClosedFromStatus == models.ContractStatusSigned {
+		event = "reopen_signed"
+	}
+	if err := c.fsm.Event(ctx, event); err != nil {
 		return fmt.Errorf("failed to reopen contract: %w", err)
 	}
 
-	c.contract.Status = c.fsm.Current()
+	c.setStatus("reopen")
+	c.contract.ClosedFromStatus = nil
 	return nil
 }
 
+// setStatus copies the new state into the contract, restarts its time-in-status clock and keeps
+// the transition for Record
+func (c *ContractFSM) setStatus(event string) {
+	now := time.Now()
+	c.transition = &models.ContractStatusTransition{
+		ContractID: c.contract.ID,
+		FromStatus: c.contract.Status,
+		ToStatus:   c.fsm.Current(),
+		Event:      event,
+		CreatedAt:  now,
+	}
+	c.contract.Status = c.fsm.Current()
+	c.contract.StatusChangedAt = &now
+	c.contract.SLAEscalatedAt = nil
+}
+
+// Record writes the last transition with the actor (nil = system) and reason behind it. Call it
+// once the new status is persisted; it does nothing when no transition was made.
+func (c *ContractFSM) Record(ctx context.Context, recorder ContractTransitionRecorder, actorID *uint, reason string) error {
+	if c.transition == nil {
+		return nil
+	}
+	transition := *c.transition
+	transition.ActorID = actorID
+	if reason != "" {
+		transition.Reason = &reason
+	}
+	return recorder.CreateStatusTransition(ctx, &transition)
+}
+
 // Current returns the current state
 func (c *ContractFSM) Current() string {
 	return c.fsm.Current()
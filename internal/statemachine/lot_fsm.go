@@ -0,0 +1,98 @@
+package statemachine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/looplab/fsm"
+	"github.com/sjperalta/fintera-api/internal/models"
+)
+
+// Lot events. Lot status only changes through these, fired by contract and payment events.
+const (
+	LotEventReserve = "reserve" // contract created/approved
+	LotEventFinance = "finance" // first reservation or down payment approved
+	LotEventRevert  = "revert"  // last approved reservation or down payment undone
+	LotEventPayOff  = "pay_off" // contract closed
+	LotEventRelease = "release" // contract rejected or cancelled
+
+	LotEventHold        = "hold"         // seller placed a temporary hold
+	LotEventReleaseHold = "release_hold" // hold released or expired
+	LotEventConvertHold = "convert_hold" // hold owner created the contract
+)
+
+// LotFSM wraps a lot with its state machine
+type LotFSM struct {
+	lot *models.Lot
+	fsm *fsm.FSM
+}
+
+// NewLotFSM creates a new lot state machine
+func NewLotFSM(lot *models.Lot) *LotFSM {
+	lfsm := &LotFSM{
+		lot: lot,
+	}
+
+	lfsm.fsm = fsm.NewFSM(
+		lot.Status,
+		fsm.Events{
+			// available → reserved (active is the legacy name of available). Not idempotent: a lot
+			// can only be reserved once.
+			{Name: LotEventReserve, Src: []string{models.LotStatusAvailable, models.LotStatusActive}, Dst: models.LotStatusReserved},
+
+			// available → held. Not idempotent: a lot can only be held once.
+			{Name: LotEventHold, Src: []string{models.LotStatusAvailable, models.LotStatusActive}, Dst: models.LotStatusHeld},
+
+			// held → available
+			{Name: LotEventReleaseHold, Src: []string{models.LotStatusHeld}, Dst: models.LotStatusAvailable},
+
+			// held → reserved. Only the hold owner's contract fires this; reserve stays strict on
+			// available so nobody else can take a held lot.
+			{Name: LotEventConvertHold, Src: []string{models.LotStatusHeld}, Dst: models.LotStatusReserved},
+
+			// reserved → financed
+			{Name: LotEventFinance, Src: []string{models.LotStatusReserved, models.LotStatusFinanced}, Dst: models.LotStatusFinanced},
+
+			// financed → reserved
+			{Name: LotEventRevert, Src: []string{models.LotStatusFinanced}, Dst: models.LotStatusReserved},
+
+			// reserved/financed → fully_paid
+			{Name: LotEventPayOff, Src: []string{models.LotStatusReserved, models.LotStatusFinanced, models.LotStatusFullyPaid}, Dst: models.LotStatusFullyPaid},
+
+			// reserved/financed → available
+			{Name: LotEventRelease, Src: []string{models.LotStatusReserved, models.LotStatusFinanced, models.LotStatusAvailable, models.LotStatusActive}, Dst: models.LotStatusAvailable},
+		},
+		fsm.Callbacks{},
+	)
+
+	return lfsm
+}
+
+// Fire applies an event to the lot. Except for reserve and hold, firing an event whose destination is the
+// current status is a no-op, so repeated domain events (e.g. several approved payments) are safe.
+func (l *LotFSM) Fire(ctx context.Context, event string) error {
+	if !l.fsm.Can(event) {
+		return fmt.Errorf("lot cannot %s in current state: %s", event, l.lot.Status)
+	}
+
+	if err := l.fsm.Event(ctx, event); err != nil {
+		var noTransition fsm.NoTransitionError
+		if !errors.As(err, &noTransition) {
+			return fmt.Errorf("failed to %s lot: %w", event, err)
+		}
+	}
+
+	l.lot.Status = l.fsm.Current()
+	return nil
+}
+
+// Current returns the current state
+func (l *LotFSM) Current() string {
+	return l.fsm.Current()
+}
+
+// Can checks if a transition is possible
+func (l *LotFSM) Can(event string) bool {
+	return l.fsm.Can(event)
+}
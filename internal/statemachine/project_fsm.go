@@ -0,0 +1,76 @@
+package statemachine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/looplab/fsm"
+	"github.com/sjperalta/fintera-api/internal/models"
+)
+
+// Project events
+const (
+	ProjectEventSubmit  = "submit"  // draft sent for approval
+	ProjectEventApprove = "approve" // approved and published
+	ProjectEventReject  = "reject"  // sent back to draft
+	ProjectEventClose   = "close"   // sales closed
+	ProjectEventReopen  = "reopen"  // closed project published again
+)
+
+// ProjectFSM wraps a project with its lifecycle state machine
+type ProjectFSM struct {
+	project *models.Project
+	fsm     *fsm.FSM
+}
+
+// NewProjectFSM creates a new project state machine
+func NewProjectFSM(project *models.Project) *ProjectFSM {
+	pfsm := &ProjectFSM{
+		project: project,
+	}
+
+	pfsm.fsm = fsm.NewFSM(
+		project.Status,
+		fsm.Events{
+			// draft → pending_approval
+			{Name: ProjectEventSubmit, Src: []string{models.ProjectStatusDraft}, Dst: models.ProjectStatusPendingApproval},
+
+			// pending_approval → published
+			{Name: ProjectEventApprove, Src: []string{models.ProjectStatusPendingApproval}, Dst: models.ProjectStatusPublished},
+
+			// pending_approval → draft
+			{Name: ProjectEventReject, Src: []string{models.ProjectStatusPendingApproval}, Dst: models.ProjectStatusDraft},
+
+			// published → closed
+			{Name: ProjectEventClose, Src: []string{models.ProjectStatusPublished}, Dst: models.ProjectStatusClosed},
+
+			// closed → published
+			{Name: ProjectEventReopen, Src: []string{models.ProjectStatusClosed}, Dst: models.ProjectStatusPublished},
+		},
+		fsm.Callbacks{},
+	)
+
+	return pfsm
+}
+
+// Fire applies an event to the project
+func (p *ProjectFSM) Fire(ctx context.Context, event string) error {
+	if !p.fsm.Can(event) {
+		return fmt.Errorf("project cannot %s in current state: %s", event, p.project.Status)
+	}
+	if err := p.fsm.Event(ctx, event); err != nil {
+		return fmt.Errorf("failed to %s project: %w", event, err)
+	}
+	p.project.Status = p.fsm.Current()
+	return nil
+}
+
+// Current returns the current state
+func (p *ProjectFSM) Current() string {
+	return p.fsm.Current()
+}
+
+// Can checks if a transition is possible
+func (p *ProjectFSM) Can(event string) bool {
+	return p.fsm.Can(event)
+}
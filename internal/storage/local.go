@@ -2,6 +2,7 @@ package storage
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -58,6 +59,32 @@ func (s *LocalStorage) Upload(file multipart.File, header *multipart.FileHeader,
 	return relPath, nil
 }
 
+// UploadHashed saves a file like Upload and also returns its size and SHA-256 checksum (hex)
+func (s *LocalStorage) UploadHashed(file multipart.File, header *multipart.FileHeader, subDir string) (string, string, int64, error) {
+	dir := filepath.Join(s.basePath, subDir, time.Now().Format("2006/01"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("%s%s", generateID(), filepath.Ext(header.Filename))
+	filePath := filepath.Join(dir, filename)
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer dst.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, hash), file)
+	if err != nil {
+		os.Remove(filePath)
+		return "", "", 0, fmt.Errorf("failed to save file: %w", err)
+	}
+
+	relPath, _ := filepath.Rel(s.basePath, filePath)
+	return relPath, hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
 // UploadFromBytes saves bytes to a file and returns its relative path
 func (s *LocalStorage) UploadFromBytes(data []byte, filename string, subDir string) (string, error) {
 	dir := filepath.Join(s.basePath, subDir, time.Now().Format("2006/01"))